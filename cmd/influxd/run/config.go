@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/influxdb/monitor"
 	"github.com/influxdata/influxdb/monitor/diagnostics"
 	"github.com/influxdata/influxdb/pkg/tlsconfig"
+	"github.com/influxdata/influxdb/services/audit"
 	"github.com/influxdata/influxdb/services/collectd"
 	"github.com/influxdata/influxdb/services/continuous_querier"
 	"github.com/influxdata/influxdb/services/graphite"
@@ -24,7 +25,9 @@ import (
 	"github.com/influxdata/influxdb/services/opentsdb"
 	"github.com/influxdata/influxdb/services/precreator"
 	"github.com/influxdata/influxdb/services/retention"
+	"github.com/influxdata/influxdb/services/statsd"
 	"github.com/influxdata/influxdb/services/subscriber"
+	tcpinput "github.com/influxdata/influxdb/services/tcp"
 	"github.com/influxdata/influxdb/services/udp"
 	itoml "github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/influxdb/tsdb"
@@ -45,6 +48,7 @@ type Config struct {
 	Coordinator coordinator.Config `toml:"coordinator"`
 	Retention   retention.Config   `toml:"retention"`
 	Precreator  precreator.Config  `toml:"shard-precreation"`
+	Audit       audit.Config       `toml:"audit"`
 
 	Monitor        monitor.Config    `toml:"monitor"`
 	Subscriber     subscriber.Config `toml:"subscriber"`
@@ -54,6 +58,8 @@ type Config struct {
 	CollectdInputs []collectd.Config `toml:"collectd"`
 	OpenTSDBInputs []opentsdb.Config `toml:"opentsdb"`
 	UDPInputs      []udp.Config      `toml:"udp"`
+	StatsDInputs   []statsd.Config   `toml:"statsd"`
+	TCPInputs      []tcpinput.Config `toml:"tcp"`
 
 	ContinuousQuery continuous_querier.Config `toml:"continuous_queries"`
 
@@ -77,6 +83,7 @@ func NewConfig() *Config {
 	c.Data = tsdb.NewConfig()
 	c.Coordinator = coordinator.NewConfig()
 	c.Precreator = precreator.NewConfig()
+	c.Audit = audit.NewConfig()
 
 	c.Monitor = monitor.NewConfig()
 	c.Subscriber = subscriber.NewConfig()
@@ -87,6 +94,8 @@ func NewConfig() *Config {
 	c.CollectdInputs = []collectd.Config{collectd.NewConfig()}
 	c.OpenTSDBInputs = []opentsdb.Config{opentsdb.NewConfig()}
 	c.UDPInputs = []udp.Config{udp.NewConfig()}
+	c.StatsDInputs = []statsd.Config{statsd.NewConfig()}
+	c.TCPInputs = []tcpinput.Config{tcpinput.NewConfig()}
 
 	c.ContinuousQuery = continuous_querier.NewConfig()
 	c.Retention = retention.NewConfig()
@@ -114,6 +123,7 @@ func NewDemoConfig() (*Config, error) {
 	c.Meta.Dir = filepath.Join(homeDir, ".influxdb/meta")
 	c.Data.Dir = filepath.Join(homeDir, ".influxdb/data")
 	c.Data.WALDir = filepath.Join(homeDir, ".influxdb/wal")
+	c.Audit.Path = filepath.Join(homeDir, ".influxdb/audit.log")
 
 	return c, nil
 }
@@ -174,6 +184,10 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if err := c.Audit.Validate(); err != nil {
+		return err
+	}
+
 	if err := c.Precreator.Validate(); err != nil {
 		return err
 	}
@@ -182,6 +196,14 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if err := c.Coordinator.Validate(); err != nil {
+		return fmt.Errorf("invalid coordinator config: %v", err)
+	}
+
+	if err := c.HTTPD.Validate(); err != nil {
+		return fmt.Errorf("invalid http config: %v", err)
+	}
+
 	for _, graphite := range c.GraphiteInputs {
 		if err := graphite.Validate(); err != nil {
 			return fmt.Errorf("invalid graphite config: %v", err)
@@ -194,6 +216,18 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	for _, statsd := range c.StatsDInputs {
+		if err := statsd.Validate(); err != nil {
+			return fmt.Errorf("invalid statsd config: %v", err)
+		}
+	}
+
+	for _, tcp := range c.TCPInputs {
+		if err := tcp.Validate(); err != nil {
+			return fmt.Errorf("invalid tcp config: %v", err)
+		}
+	}
+
 	if err := c.TLS.Validate(); err != nil {
 		return err
 	}
@@ -225,6 +259,7 @@ func (c *Config) diagnosticsClients() map[string]diagnostics.Client {
 		"config-coordinator": c.Coordinator,
 		"config-retention":   c.Retention,
 		"config-precreator":  c.Precreator,
+		"config-audit":       c.Audit,
 
 		"config-monitor":    c.Monitor,
 		"config-subscriber": c.Subscriber,
@@ -246,6 +281,12 @@ func (c *Config) diagnosticsClients() map[string]diagnostics.Client {
 	if u := udp.Configs(c.UDPInputs); u.Enabled() {
 		m["config-udp"] = u
 	}
+	if sd := statsd.Configs(c.StatsDInputs); sd.Enabled() {
+		m["config-statsd"] = sd
+	}
+	if t := tcpinput.Configs(c.TCPInputs); t.Enabled() {
+		m["config-tcp"] = t
+	}
 
 	return m
 }
@@ -19,7 +19,9 @@ import (
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/monitor"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/audit"
 	"github.com/influxdata/influxdb/services/collectd"
 	"github.com/influxdata/influxdb/services/continuous_querier"
 	"github.com/influxdata/influxdb/services/graphite"
@@ -29,8 +31,10 @@ import (
 	"github.com/influxdata/influxdb/services/precreator"
 	"github.com/influxdata/influxdb/services/retention"
 	"github.com/influxdata/influxdb/services/snapshotter"
+	"github.com/influxdata/influxdb/services/statsd"
 	"github.com/influxdata/influxdb/services/storage"
 	"github.com/influxdata/influxdb/services/subscriber"
+	tcpinput "github.com/influxdata/influxdb/services/tcp"
 	"github.com/influxdata/influxdb/services/udp"
 	"github.com/influxdata/influxdb/storage/reads"
 	"github.com/influxdata/influxdb/tcp"
@@ -76,6 +80,7 @@ type Server struct {
 
 	TSDBStore     *tsdb.Store
 	QueryExecutor *query.Executor
+	QueryCache    *coordinator.QueryCache
 	PointsWriter  *coordinator.PointsWriter
 	Subscriber    *subscriber.Service
 
@@ -86,6 +91,10 @@ type Server struct {
 
 	Monitor *monitor.Monitor
 
+	// Audit records the administrative and data-destructive operations, if
+	// enabled.
+	Audit *audit.Service
+
 	// Server reporting and registration
 	reportingDisabled bool
 
@@ -130,6 +139,9 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 	for i := range c.OpenTSDBInputs {
 		updateTLSConfig(&c.OpenTSDBInputs[i].TLS, tlsConfig)
 	}
+	for i := range c.TCPInputs {
+		updateTLSConfig(&c.TCPInputs[i].TLS, tlsConfig)
+	}
 
 	// We need to ensure that a meta directory always exists even if
 	// we don't start the meta store.  node.json is always stored under
@@ -198,13 +210,72 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 	s.TSDBStore.EngineOptions.EngineVersion = c.Data.Engine
 	s.TSDBStore.EngineOptions.IndexVersion = c.Data.Index
 
+	// Encrypt TSM files and WAL segments at rest.
+	if c.Data.EncryptionKeyFile != "" {
+		keys, err := encryption.NewKeyFile(c.Data.EncryptionKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load encryption keys: %s", err)
+		}
+		s.TSDBStore.EngineOptions.KeyProvider = keys
+		s.TSDBStore.EngineOptions.ChunkCache = encryption.NewChunkCache(int(c.Data.EncryptionCacheSize))
+	}
+
+	// Validate writes against the schemas of the databases.
+	s.TSDBStore.EngineOptions.SchemaFn = func(database string) tsdb.Schema {
+		di := s.MetaClient.Database(database)
+		if di == nil || di.Schema == nil || di.Schema.Mode == meta.SchemaModeOff {
+			return nil
+		}
+		return di.Schema
+	}
+
+	// Limit the series of the databases with a write quota.
+	s.TSDBStore.EngineOptions.MaxSeriesFn = func(database string) int64 {
+		di := s.MetaClient.Database(database)
+		if di == nil || di.WriteQuota == nil {
+			return 0
+		}
+		return di.WriteQuota.MaxSeries
+	}
+
+	// Complete the renames interrupted by a restart if they were committed to
+	// the meta store, that is if the old name no longer exists.
+	s.TSDBStore.RenameCommitted = func(r tsdb.Rename) bool {
+		if r.RetentionPolicy == "" {
+			return s.MetaClient.Database(r.Database) == nil
+		}
+		rpi, err := s.MetaClient.RetentionPolicy(r.Database, r.RetentionPolicy)
+		return err == nil && rpi == nil
+	}
+
 	// Create the Subscriber service
 	s.Subscriber = subscriber.NewService(c.Subscriber)
 
+	// Move the queues of the subscriptions of renamed databases and
+	// retention policies with their data.
+	if c.Subscriber.QueueDir != "" {
+		s.TSDBStore.RenameDirs = []string{c.Subscriber.QueueDir}
+	}
+
 	// Initialize points writer.
 	s.PointsWriter = coordinator.NewPointsWriter()
 	s.PointsWriter.WriteTimeout = time.Duration(c.Coordinator.WriteTimeout)
 	s.PointsWriter.TSDBStore = s.TSDBStore
+	if len(c.Coordinator.Relabel) > 0 {
+		relabeler, err := coordinator.NewRelabeler(c.Coordinator.Relabel)
+		if err != nil {
+			return nil, err
+		}
+		s.PointsWriter.Relabeler = relabeler
+	}
+	s.PointsWriter.WriteQuotas = coordinator.NewWriteQuotas()
+	s.PointsWriter.WriteQuotas.TSDBStore = s.TSDBStore
+
+	// Initialize the query result cache.
+	if c.Coordinator.QueryCacheEnabled {
+		s.QueryCache = coordinator.NewQueryCache(int(c.Coordinator.QueryCacheMaxMemorySize), int(c.Coordinator.QueryCacheMaxEntrySize))
+		s.PointsWriter.QueryCache = s.QueryCache
+	}
 
 	// Initialize query executor.
 	s.QueryExecutor = query.NewExecutor()
@@ -215,6 +286,7 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 		ShardMapper: &coordinator.LocalShardMapper{
 			MetaClient: s.MetaClient,
 			TSDBStore:  coordinator.LocalTSDBStore{Store: s.TSDBStore},
+			QueryCache: s.QueryCache,
 		},
 		StrictErrorHandling: s.TSDBStore.EngineOptions.Config.StrictErrorHandling,
 		Monitor:             s.Monitor,
@@ -222,10 +294,33 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 		MaxSelectPointN:     c.Coordinator.MaxSelectPointN,
 		MaxSelectSeriesN:    c.Coordinator.MaxSelectSeriesN,
 		MaxSelectBucketsN:   c.Coordinator.MaxSelectBucketsN,
+		QueryCache:          s.QueryCache,
+		WriteQuotas:         s.PointsWriter.WriteQuotas,
+		Subscriber:          s.Subscriber,
 	}
 	s.QueryExecutor.TaskManager.QueryTimeout = time.Duration(c.Coordinator.QueryTimeout)
 	s.QueryExecutor.TaskManager.LogQueriesAfter = time.Duration(c.Coordinator.LogQueriesAfter)
 	s.QueryExecutor.TaskManager.MaxConcurrentQueries = c.Coordinator.MaxConcurrentQueries
+	s.QueryExecutor.TaskManager.MaxEnqueuedQueries = c.Coordinator.MaxEnqueuedQueries
+	s.QueryExecutor.TaskManager.EnqueuedQueryTimeout = time.Duration(c.Coordinator.EnqueuedQueryTimeout)
+	s.QueryExecutor.TaskManager.HighPriorityUsers = c.Coordinator.HighPriorityUsers
+	if c.Coordinator.SlowQueryLogThreshold > 0 {
+		slowQueryLog, err := query.NewSlowQueryLog(c.Coordinator.SlowQueryLogPath)
+		if err != nil {
+			return nil, fmt.Errorf("open slow query log: %s", err)
+		}
+		slowQueryLog.Threshold = time.Duration(c.Coordinator.SlowQueryLogThreshold)
+		slowQueryLog.SampleRate = c.Coordinator.SlowQueryLogSampleRate
+		s.QueryExecutor.TaskManager.SlowQueryLog = slowQueryLog
+	}
+	s.QueryExecutor.TaskManager.Quotas = s.MetaClient
+
+	// Initialize the audit log.
+	if c.Audit.Enabled {
+		s.Audit = audit.NewService(c.Audit)
+		s.MetaClient.WithAuditFunc(s.Audit.AuditMetaChange)
+		s.QueryExecutor.StatementExecutor.(*coordinator.StatementExecutor).Auditor = s.Audit
+	}
 
 	// Initialize the monitor
 	s.Monitor.Version = s.buildInfo.Version
@@ -240,6 +335,9 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 func (s *Server) Statistics(tags map[string]string) []models.Statistic {
 	var statistics []models.Statistic
 	statistics = append(statistics, s.QueryExecutor.Statistics(tags)...)
+	if s.QueryCache != nil {
+		statistics = append(statistics, s.QueryCache.Statistics(tags)...)
+	}
 	statistics = append(statistics, s.TSDBStore.Statistics(tags)...)
 	statistics = append(statistics, s.PointsWriter.Statistics(tags)...)
 	statistics = append(statistics, s.Subscriber.Statistics(tags)...)
@@ -265,6 +363,15 @@ func (s *Server) SetLogOutput(w io.Writer) {
 	s.Logger = logger.New(w)
 }
 
+func (s *Server) appendAuditService() {
+	if s.Audit == nil {
+		return
+	}
+	s.Audit.MetaClient = s.MetaClient
+	s.Audit.PointsWriter = (*auditPointsWriter)(s.PointsWriter)
+	s.Services = append(s.Services, s.Audit)
+}
+
 func (s *Server) appendMonitorService() {
 	s.Services = append(s.Services, s.Monitor)
 }
@@ -282,6 +389,9 @@ func (s *Server) appendRetentionPolicyService(c retention.Config) {
 func (s *Server) appendGrpcService(address string) {
 	ss := storage.NewStore(s.TSDBStore, s.MetaClient)
 	srv := httpd.NewGrpcService(address, ss)
+	srv.Server.AuthEnabled = s.config.HTTPD.AuthEnabled
+	srv.Server.MetaClient = s.MetaClient
+	srv.Server.QueryAuthorizer = meta.NewQueryAuthorizer(s.MetaClient)
 	s.Services = append(s.Services, srv)
 }
 
@@ -297,6 +407,13 @@ func (s *Server) appendHTTPDService(c httpd.Config) {
 	srv.Handler.QueryExecutor = s.QueryExecutor
 	srv.Handler.Monitor = s.Monitor
 	srv.Handler.PointsWriter = s.PointsWriter
+	srv.Handler.WriteQuotas = s.PointsWriter.WriteQuotas
+	srv.Handler.Renamer = s.QueryExecutor.StatementExecutor.(*coordinator.StatementExecutor)
+	srv.Handler.Copier = s.QueryExecutor.StatementExecutor.(*coordinator.StatementExecutor)
+	srv.Handler.MetaHistory = s.QueryExecutor.StatementExecutor.(*coordinator.StatementExecutor)
+	if s.Audit != nil {
+		srv.Handler.Auditor = s.Audit
+	}
 	srv.Handler.Version = s.buildInfo.Version
 	srv.Handler.BuildType = "OSS"
 	ss := storage.NewStore(s.TSDBStore, s.MetaClient)
@@ -305,6 +422,14 @@ func (s *Server) appendHTTPDService(c httpd.Config) {
 		srv.Handler.Controller = control.NewController(s.MetaClient, reads.NewReader(ss), authorizer, c.AuthEnabled, s.Logger)
 	}
 
+	// Failed authentications over gRPC lock out client addresses as those
+	// over HTTP do.
+	for _, svc := range s.Services {
+		if grpcSrv, ok := svc.(*httpd.RpcService); ok {
+			grpcSrv.Server.Lockout = srv.Handler
+		}
+	}
+
 	s.Services = append(s.Services, srv)
 }
 
@@ -327,6 +452,10 @@ func (s *Server) appendOpenTSDBService(c opentsdb.Config) error {
 		return err
 	}
 	srv.PointsWriter = s.PointsWriter
+	srv.QueryExecutor = s.QueryExecutor
+	srv.AuthEnabled = s.config.HTTPD.AuthEnabled
+	srv.Authenticator = s.MetaClient
+	srv.QueryAuthorizer = meta.NewQueryAuthorizer(s.MetaClient)
 	srv.MetaClient = s.MetaClient
 	s.Services = append(s.Services, srv)
 	return nil
@@ -368,6 +497,28 @@ func (s *Server) appendUDPService(c udp.Config) {
 	s.Services = append(s.Services, srv)
 }
 
+func (s *Server) appendStatsDService(c statsd.Config) {
+	if !c.Enabled {
+		return
+	}
+	srv := statsd.NewService(c)
+	srv.PointsWriter = s.PointsWriter
+	srv.MetaClient = s.MetaClient
+	s.Services = append(s.Services, srv)
+}
+
+func (s *Server) appendTCPService(c tcpinput.Config) {
+	if !c.Enabled {
+		return
+	}
+	srv := tcpinput.NewService(c)
+	srv.PointsWriter = s.PointsWriter
+	srv.MetaClient = s.MetaClient
+	srv.InternalDatabase = s.config.Monitor.StoreDatabase
+	srv.AuditDatabase = s.config.Audit.StoreDatabase
+	s.Services = append(s.Services, srv)
+}
+
 func (s *Server) appendContinuousQueryService(c continuous_querier.Config) {
 	if !c.Enabled {
 		return
@@ -401,6 +552,7 @@ func (s *Server) Open() error {
 	go mux.Serve(ln)
 
 	// Append services.
+	s.appendAuditService()
 	s.appendMonitorService()
 	s.appendPrecreatorService(s.config.Precreator)
 	s.appendGrpcService(s.config.GrpcAddress)
@@ -424,6 +576,12 @@ func (s *Server) Open() error {
 	for _, i := range s.config.UDPInputs {
 		s.appendUDPService(i)
 	}
+	for _, i := range s.config.StatsDInputs {
+		s.appendStatsDService(i)
+	}
+	for _, i := range s.config.TCPInputs {
+		s.appendTCPService(i)
+	}
 
 	s.Subscriber.MetaClient = s.MetaClient
 	s.PointsWriter.MetaClient = s.MetaClient
@@ -658,6 +816,16 @@ func (pw *monitorPointsWriter) WritePoints(database, retentionPolicy string, poi
 	return (*coordinator.PointsWriter)(pw).WritePointsPrivileged(database, retentionPolicy, models.ConsistencyLevelAny, points)
 }
 
+// auditPointsWriter writes the audit records as points written by the server
+// itself, so that they bypass the relabel rules, the schema and the write
+// quotas of the audit database.
+type auditPointsWriter coordinator.PointsWriter
+
+func (pw *auditPointsWriter) WritePoints(database, retentionPolicy string, points models.Points) error {
+	ctx := context.WithValue(context.Background(), tsdb.SystemWrite, true)
+	return (*coordinator.PointsWriter)(pw).WritePointsPrivilegedWithContext(ctx, database, retentionPolicy, models.ConsistencyLevelAny, points)
+}
+
 func raftDBExists(dir string) error {
 	// Check to see if there is a raft db, if so, error out with a message
 	// to downgrade, export, and then import the meta data
@@ -18,6 +18,10 @@ const (
 	// A value of zero will make the maximum query limit unlimited.
 	DefaultMaxConcurrentQueries = 0
 
+	// DefaultEnqueuedQueryTimeout is the maximum time a query can wait in the
+	// queue for one of the max-concurrent-queries to finish.
+	DefaultEnqueuedQueryTimeout = 30 * time.Second
+
 	// DefaultMaxSelectPointN is the maximum number of points a SELECT can process.
 	// A value of zero will make the maximum point count unlimited.
 	DefaultMaxSelectPointN = 0
@@ -25,17 +29,43 @@ const (
 	// DefaultMaxSelectSeriesN is the maximum number of series a SELECT can run.
 	// A value of zero will make the maximum series count unlimited.
 	DefaultMaxSelectSeriesN = 0
+
+	// DefaultSlowQueryLogSampleRate is the fraction of slow queries written
+	// to the slow query log.
+	DefaultSlowQueryLogSampleRate = query.DefaultSlowQueryLogSampleRate
+
+	// DefaultQueryCacheMaxMemorySize is the maximum size of the query result cache.
+	DefaultQueryCacheMaxMemorySize = 64 * 1024 * 1024 // 64MB
+
+	// DefaultQueryCacheMaxEntrySize is the maximum size of the cached result
+	// of a single shard.
+	DefaultQueryCacheMaxEntrySize = 1024 * 1024 // 1MB
 )
 
 // Config represents the configuration for the coordinator service.
 type Config struct {
 	WriteTimeout         toml.Duration `toml:"write-timeout"`
 	MaxConcurrentQueries int           `toml:"max-concurrent-queries"`
+	MaxEnqueuedQueries   int           `toml:"max-enqueued-queries"`
+	EnqueuedQueryTimeout toml.Duration `toml:"enqueued-query-timeout"`
+	HighPriorityUsers    []string      `toml:"high-priority-users"`
 	QueryTimeout         toml.Duration `toml:"query-timeout"`
 	LogQueriesAfter      toml.Duration `toml:"log-queries-after"`
 	MaxSelectPointN      int           `toml:"max-select-point"`
 	MaxSelectSeriesN     int           `toml:"max-select-series"`
 	MaxSelectBucketsN    int           `toml:"max-select-buckets"`
+
+	SlowQueryLogThreshold  toml.Duration `toml:"slow-query-log-threshold"`
+	SlowQueryLogPath       string        `toml:"slow-query-log-path"`
+	SlowQueryLogSampleRate float64       `toml:"slow-query-log-sample-rate"`
+
+	QueryCacheEnabled       bool      `toml:"query-cache-enabled"`
+	QueryCacheMaxMemorySize toml.Size `toml:"query-cache-max-memory-size"`
+	QueryCacheMaxEntrySize  toml.Size `toml:"query-cache-max-entry-size"`
+
+	// Relabel are the rules applied in order to the points written to the
+	// databases.
+	Relabel []RelabelConfig `toml:"relabel"`
 }
 
 // NewConfig returns an instance of Config with defaults.
@@ -44,9 +74,25 @@ func NewConfig() Config {
 		WriteTimeout:         toml.Duration(DefaultWriteTimeout),
 		QueryTimeout:         toml.Duration(query.DefaultQueryTimeout),
 		MaxConcurrentQueries: DefaultMaxConcurrentQueries,
+		EnqueuedQueryTimeout: toml.Duration(DefaultEnqueuedQueryTimeout),
 		MaxSelectPointN:      DefaultMaxSelectPointN,
 		MaxSelectSeriesN:     DefaultMaxSelectSeriesN,
+
+		SlowQueryLogSampleRate: DefaultSlowQueryLogSampleRate,
+
+		QueryCacheMaxMemorySize: DefaultQueryCacheMaxMemorySize,
+		QueryCacheMaxEntrySize:  DefaultQueryCacheMaxEntrySize,
+	}
+}
+
+// Validate returns an error if the Config is invalid.
+func (c Config) Validate() error {
+	for _, r := range c.Relabel {
+		if err := r.Validate(); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // Diagnostics returns a diagnostics representation of a subset of the Config.
@@ -54,10 +100,23 @@ func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 	return diagnostics.RowFromMap(map[string]interface{}{
 		"write-timeout":          c.WriteTimeout,
 		"max-concurrent-queries": c.MaxConcurrentQueries,
+		"max-enqueued-queries":   c.MaxEnqueuedQueries,
+		"enqueued-query-timeout": c.EnqueuedQueryTimeout,
+		"high-priority-users":    c.HighPriorityUsers,
 		"query-timeout":          c.QueryTimeout,
 		"log-queries-after":      c.LogQueriesAfter,
 		"max-select-point":       c.MaxSelectPointN,
 		"max-select-series":      c.MaxSelectSeriesN,
 		"max-select-buckets":     c.MaxSelectBucketsN,
+
+		"slow-query-log-threshold":   c.SlowQueryLogThreshold,
+		"slow-query-log-path":        c.SlowQueryLogPath,
+		"slow-query-log-sample-rate": c.SlowQueryLogSampleRate,
+
+		"query-cache-enabled":         c.QueryCacheEnabled,
+		"query-cache-max-memory-size": c.QueryCacheMaxMemorySize,
+		"query-cache-max-entry-size":  c.QueryCacheMaxEntrySize,
+
+		"relabel-rules": len(c.Relabel),
 	}), nil
 }
@@ -22,3 +22,31 @@ write-timeout = "20s"
 		t.Fatalf("unexpected write timeout s: %s", c.WriteTimeout)
 	}
 }
+
+func TestConfig_Parse_Relabel(t *testing.T) {
+	var c coordinator.Config
+	if _, err := toml.Decode(`
+[[relabel]]
+  database = "telegraf"
+  action = "labeldrop"
+  regex = "pod_uid"
+
+[[relabel]]
+  action = "hashmod"
+  source-tags = ["host"]
+  target-tag = "shard"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.Relabel) != 2 {
+		t.Fatalf("unexpected number of relabel rules: %d", len(c.Relabel))
+	} else if c.Relabel[0].Database != "telegraf" || c.Relabel[0].Action != "labeldrop" || c.Relabel[0].Regex != "pod_uid" {
+		t.Fatalf("unexpected relabel rule: %+v", c.Relabel[0])
+	}
+
+	// The hashmod rule is missing its modulus.
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error")
+	}
+}
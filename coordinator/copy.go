@@ -0,0 +1,133 @@
+package coordinator
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// ErrShardGroupNotEnded is returned when moving the data of a shard group
+// that may still be written to.
+var ErrShardGroupNotEnded = errors.New("cannot move data of shard groups that have not ended")
+
+// CopyRetentionPolicy copies the data of a retention policy between start,
+// inclusive, and end, exclusive, into another retention policy of the
+// database. The data is copied at the storage level, shard by shard, and
+// bucketed into the shard groups of the destination policy, which are created
+// as needed. Data beyond the duration of the destination policy is not copied.
+//
+// If move is true, the copied data is deleted from the source policy. Shards
+// entirely within the time range are dropped. Moving the data of shard groups
+// that have not ended is rejected with ErrShardGroupNotEnded, since points
+// written to them once copied would be deleted.
+func (e *StatementExecutor) CopyRetentionPolicy(database, src, dst string, start, end time.Time, move bool) error {
+	dbi := e.MetaClient.Database(database)
+	if dbi == nil {
+		return influxdb.ErrDatabaseNotFound(database)
+	} else if dbi.RetentionPolicy(src) == nil {
+		return influxdb.ErrRetentionPolicyNotFound(src)
+	}
+	dstRP := dbi.RetentionPolicy(dst)
+	if dstRP == nil {
+		return influxdb.ErrRetentionPolicyNotFound(dst)
+	} else if src == dst {
+		return errors.New("source and destination retention policies must differ")
+	} else if !start.Before(end) {
+		return errors.New("start time must be before end time")
+	}
+
+	// The destination would drop data beyond its retention. It is neither
+	// copied nor, when moving, deleted from the source.
+	if dstRP.Duration > 0 {
+		if min := time.Now().Add(-dstRP.Duration); start.Before(min) {
+			start = min
+		}
+		if !start.Before(end) {
+			return nil
+		}
+	}
+
+	sgs, err := e.MetaClient.ShardGroupsByTimeRange(database, src, start, end.Add(-1))
+	if err != nil {
+		return err
+	}
+	if move {
+		now := time.Now()
+		for _, sg := range sgs {
+			if !sg.Deleted() && sg.EndTime.After(now) {
+				return ErrShardGroupNotEnded
+			}
+		}
+	}
+
+	defer e.invalidateDatabase(database)
+	for _, sg := range sgs {
+		if sg.Deleted() {
+			continue
+		}
+
+		min, max := sg.StartTime, sg.EndTime
+		if min.Before(start) {
+			min = start
+		}
+		if max.After(end) {
+			max = end
+		}
+
+		for _, sh := range sg.Shards {
+			if copied, err := e.copyShard(database, dst, sh.ID, min, max); err != nil {
+				return fmt.Errorf("copy shard %d: %s", sh.ID, err)
+			} else if !copied || !move {
+				continue
+			}
+
+			if !sg.StartTime.Before(start) && !sg.EndTime.After(end) {
+				// Drop the shard from the meta store first, so that a failure
+				// leaves unreferenced data on disk rather than a shard
+				// without data.
+				if err := e.MetaClient.DropShard(sh.ID); err != nil {
+					return err
+				} else if err := e.TSDBStore.DeleteShard(sh.ID); err != nil {
+					return err
+				}
+			} else if err := e.TSDBStore.DeleteShardRange(sh.ID, min.UnixNano(), max.UnixNano()-1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyShard copies the data of a shard between min, inclusive, and max,
+// exclusive, into the shards of the destination retention policy covering the
+// time range. It returns false if the shard does not exist on this server.
+func (e *StatementExecutor) copyShard(database, dst string, id uint64, min, max time.Time) (bool, error) {
+	for t := min; t.Before(max); {
+		sg, err := e.MetaClient.CreateShardGroup(database, dst, t)
+		if err != nil {
+			return false, err
+		} else if len(sg.Shards) != 1 {
+			return false, fmt.Errorf("shard group %d has %d shards", sg.ID, len(sg.Shards))
+		}
+
+		shardID := sg.Shards[0].ID
+		if err := e.TSDBStore.CreateShard(database, dst, shardID, true); err != nil {
+			return false, err
+		}
+
+		next := sg.EndTime
+		if next.After(max) {
+			next = max
+		}
+		if err := e.TSDBStore.CopyShard(id, shardID, t.UnixNano(), next.UnixNano()-1); err == tsdb.ErrShardNotFound {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		t = next
+	}
+	return true, nil
+}
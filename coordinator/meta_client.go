@@ -13,7 +13,9 @@ type MetaClient interface {
 	CreateDatabase(name string) (*meta.DatabaseInfo, error)
 	CreateDatabaseWithRetentionPolicy(name string, spec *meta.RetentionPolicySpec) (*meta.DatabaseInfo, error)
 	CreateRetentionPolicy(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error)
+	CreateShardGroup(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error)
 	CreateSubscription(database, rp, name, mode string, destinations []string) error
+	CreateToken(username string, scopes []string, expiresAt time.Time, description string) (string, *meta.TokenInfo, error)
 	CreateUser(name, password string, admin bool) (meta.User, error)
 	Database(name string) *meta.DatabaseInfo
 	Databases() []meta.DatabaseInfo
@@ -22,15 +24,22 @@ type MetaClient interface {
 	DropDatabase(name string) error
 	DropRetentionPolicy(database, name string) error
 	DropSubscription(database, rp, name string) error
+	DropToken(id string) error
 	DropUser(name string) error
+	History() []meta.HistoryEntry
+	RenameDatabase(name, newName string) error
+	RenameRetentionPolicy(database, name, newName string) error
 	RetentionPolicy(database, name string) (rpi *meta.RetentionPolicyInfo, err error)
+	Rollback(index uint64, exists func(id uint64) bool) (*meta.RollbackResult, error)
 	SetAdminPrivilege(username string, admin bool) error
 	SetPrivilege(username, database string, p influxql.Privilege) error
 	ShardGroupsByTimeRange(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error)
+	Tokens() []meta.TokenInfo
 	TruncateShardGroups(t time.Time) error
 	UpdateRetentionPolicy(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error
 	UpdateUser(name, password string) error
 	UserPrivilege(username, database string) (*influxql.Privilege, error)
 	UserPrivileges(username string) (map[string]influxql.Privilege, error)
 	Users() []meta.UserInfo
+	UserLocked(name string) bool
 }
@@ -13,7 +13,9 @@ type MetaClient struct {
 	CreateDatabaseFn                    func(name string) (*meta.DatabaseInfo, error)
 	CreateDatabaseWithRetentionPolicyFn func(name string, spec *meta.RetentionPolicySpec) (*meta.DatabaseInfo, error)
 	CreateRetentionPolicyFn             func(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error)
+	CreateShardGroupFn                  func(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error)
 	CreateSubscriptionFn                func(database, rp, name, mode string, destinations []string) error
+	CreateTokenFn                       func(username string, scopes []string, expiresAt time.Time, description string) (string, *meta.TokenInfo, error)
 	CreateUserFn                        func(name, password string, admin bool) (meta.User, error)
 	DatabaseFn                          func(name string) *meta.DatabaseInfo
 	DatabasesFn                         func() []meta.DatabaseInfo
@@ -26,18 +28,25 @@ type MetaClient struct {
 	DropRetentionPolicyFn               func(database, name string) error
 	DropSubscriptionFn                  func(database, rp, name string) error
 	DropShardFn                         func(id uint64) error
+	DropTokenFn                         func(id string) error
 	DropUserFn                          func(name string) error
+	HistoryFn                           func() []meta.HistoryEntry
 	MetaNodesFn                         func() ([]meta.NodeInfo, error)
+	RenameDatabaseFn                    func(name, newName string) error
+	RenameRetentionPolicyFn             func(database, name, newName string) error
 	RetentionPolicyFn                   func(database, name string) (rpi *meta.RetentionPolicyInfo, err error)
+	RollbackFn                          func(index uint64, exists func(id uint64) bool) (*meta.RollbackResult, error)
 	SetAdminPrivilegeFn                 func(username string, admin bool) error
 	SetPrivilegeFn                      func(username, database string, p influxql.Privilege) error
 	ShardGroupsByTimeRangeFn            func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error)
+	TokensFn                            func() []meta.TokenInfo
 	TruncateShardGroupsFn               func(t time.Time) error
 	UpdateRetentionPolicyFn             func(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error
 	UpdateUserFn                        func(name, password string) error
 	UserPrivilegeFn                     func(username, database string) (*influxql.Privilege, error)
 	UserPrivilegesFn                    func(username string) (map[string]influxql.Privilege, error)
 	UsersFn                             func() []meta.UserInfo
+	UserLockedFn                        func(name string) bool
 }
 
 func (c *MetaClient) CreateContinuousQuery(database, name, query string) error {
@@ -60,10 +69,22 @@ func (c *MetaClient) DropShard(id uint64) error {
 	return c.DropShardFn(id)
 }
 
+func (c *MetaClient) DropToken(id string) error {
+	return c.DropTokenFn(id)
+}
+
+func (c *MetaClient) CreateShardGroup(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error) {
+	return c.CreateShardGroupFn(database, policy, timestamp)
+}
+
 func (c *MetaClient) CreateSubscription(database, rp, name, mode string, destinations []string) error {
 	return c.CreateSubscriptionFn(database, rp, name, mode, destinations)
 }
 
+func (c *MetaClient) CreateToken(username string, scopes []string, expiresAt time.Time, description string) (string, *meta.TokenInfo, error) {
+	return c.CreateTokenFn(username, scopes, expiresAt, description)
+}
+
 func (c *MetaClient) CreateUser(name, password string, admin bool) (meta.User, error) {
 	return c.CreateUserFn(name, password, admin)
 }
@@ -116,10 +137,26 @@ func (c *MetaClient) MetaNodes() ([]meta.NodeInfo, error) {
 	return c.MetaNodesFn()
 }
 
+func (c *MetaClient) History() []meta.HistoryEntry {
+	return c.HistoryFn()
+}
+
+func (c *MetaClient) RenameDatabase(name, newName string) error {
+	return c.RenameDatabaseFn(name, newName)
+}
+
+func (c *MetaClient) RenameRetentionPolicy(database, name, newName string) error {
+	return c.RenameRetentionPolicyFn(database, name, newName)
+}
+
 func (c *MetaClient) RetentionPolicy(database, name string) (rpi *meta.RetentionPolicyInfo, err error) {
 	return c.RetentionPolicyFn(database, name)
 }
 
+func (c *MetaClient) Rollback(index uint64, exists func(id uint64) bool) (*meta.RollbackResult, error) {
+	return c.RollbackFn(index, exists)
+}
+
 func (c *MetaClient) SetAdminPrivilege(username string, admin bool) error {
 	return c.SetAdminPrivilegeFn(username, admin)
 }
@@ -132,6 +169,10 @@ func (c *MetaClient) ShardGroupsByTimeRange(database, policy string, min, max ti
 	return c.ShardGroupsByTimeRangeFn(database, policy, min, max)
 }
 
+func (c *MetaClient) Tokens() []meta.TokenInfo {
+	return c.TokensFn()
+}
+
 func (c *MetaClient) TruncateShardGroups(t time.Time) error {
 	return c.TruncateShardGroupsFn(t)
 }
@@ -156,6 +197,10 @@ func (c *MetaClient) Users() []meta.UserInfo {
 	return c.UsersFn()
 }
 
+func (c *MetaClient) UserLocked(name string) bool {
+	return c.UserLockedFn(name)
+}
+
 // DefaultMetaClientDatabaseFn returns a single database (db0) with a retention policy.
 func DefaultMetaClientDatabaseFn(name string) *meta.DatabaseInfo {
 	return &meta.DatabaseInfo{
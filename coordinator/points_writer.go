@@ -61,6 +61,19 @@ type PointsWriter struct {
 		WriteToShard(shardID uint64, points []models.Point) error
 	}
 
+	// QueryCache, if set, is notified of every shard written to so that cached
+	// query results for the shard are invalidated.
+	QueryCache interface {
+		InvalidateShard(id uint64)
+	}
+
+	// Relabeler, if set, changes or drops the points before they are written.
+	Relabeler *Relabeler
+
+	// WriteQuotas, if set, rejects the writes exceeding the disk usage and
+	// write rate quotas of their database.
+	WriteQuotas *WriteQuotas
+
 	subPoints []chan<- *WritePointsRequest
 
 	stats *WriteStatistics
@@ -168,7 +181,7 @@ type WriteStatistics struct {
 
 // Statistics returns statistics for periodic monitoring.
 func (w *PointsWriter) Statistics(tags map[string]string) []models.Statistic {
-	return []models.Statistic{{
+	statistics := []models.Statistic{{
 		Name: "write",
 		Tags: tags,
 		Values: map[string]interface{}{
@@ -183,6 +196,13 @@ func (w *PointsWriter) Statistics(tags map[string]string) []models.Statistic {
 			statSubWriteDrop:       atomic.LoadInt64(&w.stats.SubWriteDrop),
 		},
 	}}
+	if w.Relabeler != nil {
+		statistics = append(statistics, w.Relabeler.Statistics(tags)...)
+	}
+	if w.WriteQuotas != nil {
+		statistics = append(statistics, w.WriteQuotas.Statistics(tags)...)
+	}
+	return statistics
 }
 
 // MapShards maps the points contained in wp to a ShardMapping.  If a point
@@ -365,6 +385,9 @@ func (w *PointsWriter) WritePointsPrivileged(database, retentionPolicy string, c
 // sent via context values, this stores the total points and fields written in
 // the memory pointed to by the associated wth the int64 pointers.
 //
+// Points written with a true tsdb.SystemWrite context value bypass the relabel
+// rules, the schema and the write quotas of the database.
+//
 func (w *PointsWriter) WritePointsPrivilegedWithContext(ctx context.Context, database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
 	atomic.AddInt64(&w.stats.WriteReq, 1)
 	atomic.AddInt64(&w.stats.PointWriteReq, int64(len(points)))
@@ -377,6 +400,22 @@ func (w *PointsWriter) WritePointsPrivilegedWithContext(ctx context.Context, dat
 		retentionPolicy = db.DefaultRetentionPolicy
 	}
 
+	// The points written by the server itself bypass the relabel rules and
+	// the write quotas.
+	system, _ := ctx.Value(tsdb.SystemWrite).(bool)
+
+	if w.Relabeler != nil && !system {
+		points = w.Relabeler.Relabel(database, points)
+	}
+
+	if w.WriteQuotas != nil && !system {
+		if di := w.MetaClient.Database(database); di != nil {
+			if err := w.WriteQuotas.Check(database, di.WriteQuota, len(points)); err != nil {
+				return err
+			}
+		}
+	}
+
 	shardMappings, err := w.MapShards(&WritePointsRequest{Database: database, RetentionPolicy: retentionPolicy, Points: points})
 	if err != nil {
 		return err
@@ -461,6 +500,12 @@ func (w *PointsWriter) writeToShard(shard *meta.ShardInfo, database, retentionPo
 func (w *PointsWriter) writeToShardWithContext(ctx context.Context, shard *meta.ShardInfo, database, retentionPolicy string, points []models.Point) error {
 	atomic.AddInt64(&w.stats.PointWriteReqLocal, int64(len(points)))
 
+	// Invalidate only once the points are visible to queries. A failed write
+	// may still have written some of the points.
+	if w.QueryCache != nil {
+		defer w.QueryCache.InvalidateShard(shard.ID)
+	}
+
 	// This is a small wrapper to make type-switching over w.TSDBStore a little
 	// less verbose.
 	writeToShard := func() error {
@@ -1,6 +1,7 @@
 package coordinator_test
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"sync"
@@ -428,6 +429,63 @@ func TestPointsWriter_WritePoints_Dropped(t *testing.T) {
 	}
 }
 
+func TestPointsWriter_WritePoints_Relabel(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+	ms.NodeIDFn = func() uint64 { return 1 }
+
+	var mu sync.Mutex
+	var written []models.Point
+	store := &fakeStore{
+		WriteFn: func(shardID uint64, points []models.Point) error {
+			mu.Lock()
+			defer mu.Unlock()
+			written = append(written, points...)
+			return nil
+		},
+	}
+
+	relabeler, err := coordinator.NewRelabeler([]coordinator.RelabelConfig{
+		{Database: "mydb", Action: "drop", SourceTags: []string{"_measurement"}, Regex: "disk"},
+		{Database: "mydb", Action: "labeldrop", Regex: "pod_uid"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Relabeler = relabeler
+	c.Node = &influxdb.Node{ID: 1}
+
+	c.Open()
+	defer c.Close()
+
+	now := time.Now()
+	points := []models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a", "pod_uid": "1"}), models.Fields{"value": 1.0}, now),
+		models.MustNewPoint("disk", models.NewTags(map[string]string{"host": "a"}), models.Fields{"value": 1.0}, now),
+	}
+	if err := c.WritePointsPrivileged("mydb", "myrp", models.ConsistencyLevelOne, points); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(written) != 1 {
+		t.Fatalf("got %d points written, expected 1", len(written))
+	} else if got, exp := string(written[0].Key()), "cpu,host=a"; got != exp {
+		t.Fatalf("got key %s, expected %s", got, exp)
+	}
+
+	// Points written by the server itself are not relabeled.
+	written = nil
+	ctx := context.WithValue(context.Background(), tsdb.SystemWrite, true)
+	if err := c.WritePointsPrivilegedWithContext(ctx, "mydb", "myrp", models.ConsistencyLevelOne, points); err != nil {
+		t.Fatal(err)
+	} else if len(written) != 2 {
+		t.Fatalf("got %d points written, expected 2", len(written))
+	}
+}
+
 type fakePointsWriter struct {
 	WritePointsIntoFn func(*coordinator.IntoWriteRequest) error
 }
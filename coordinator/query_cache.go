@@ -0,0 +1,583 @@
+package coordinator
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+// The keys for statistics generated by the "queryCache" module.
+const (
+	statQueryCacheHits          = "hits"          // Number of shard results served from the cache.
+	statQueryCacheMisses        = "misses"        // Number of shard results that had to be computed.
+	statQueryCacheStores        = "stores"        // Number of shard results added to the cache.
+	statQueryCacheEvictions     = "evictions"     // Number of entries evicted to stay within the size limit.
+	statQueryCacheInvalidations = "invalidations" // Number of entries dropped due to writes or deletes.
+	statQueryCacheEntries       = "entries"       // Number of entries currently cached.
+	statQueryCacheSize          = "sizeBytes"     // Approximate memory used by cached entries.
+)
+
+// QueryCache is an LRU cache of partial query results for individual shards.
+//
+// Only shards belonging to shard groups that have ended are cached, since
+// those rarely receive writes. The results for a shard are recorded as the
+// query engine reads them and are stored once the shard's iterator has been
+// fully consumed. Entries for a shard are dropped when the shard is written
+// to or when data is deleted from it.
+type QueryCache struct {
+	mu      sync.RWMutex
+	entries map[string]*list.Element
+	evictor *list.List
+	shards  map[uint64]*queryCacheShard
+	size    int
+
+	maxSize      int
+	maxEntrySize int
+
+	stats *QueryCacheStatistics
+}
+
+// NewQueryCache returns a QueryCache that holds up to maxSize bytes of results
+// and does not cache the results of a single shard larger than maxEntrySize.
+func NewQueryCache(maxSize, maxEntrySize int) *QueryCache {
+	return &QueryCache{
+		entries:      make(map[string]*list.Element),
+		evictor:      list.New(),
+		shards:       make(map[uint64]*queryCacheShard),
+		maxSize:      maxSize,
+		maxEntrySize: maxEntrySize,
+		stats:        &QueryCacheStatistics{},
+	}
+}
+
+// queryCacheShard tracks the cache state of a single shard.
+type queryCacheShard struct {
+	database string
+
+	// epoch is incremented whenever the shard is invalidated. Recordings that
+	// started in an earlier epoch are discarded instead of being stored.
+	epoch uint64
+
+	// keys holds the keys of all entries cached for the shard.
+	keys map[string]struct{}
+
+	// recordings is the number of results for the shard currently being recorded.
+	recordings int
+}
+
+// queryCacheEntry is the cached result of one shard iterator.
+type queryCacheEntry struct {
+	key     string
+	shardID uint64
+	typ     influxql.DataType
+	buf     []byte
+	stats   query.IteratorStats
+}
+
+func (e *queryCacheEntry) size() int { return len(e.key) + len(e.buf) }
+
+// QueryCacheStatistics keeps statistics related to the QueryCache.
+type QueryCacheStatistics struct {
+	Hits          int64
+	Misses        int64
+	Stores        int64
+	Evictions     int64
+	Invalidations int64
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (c *QueryCache) Statistics(tags map[string]string) []models.Statistic {
+	c.mu.RLock()
+	entries, size := len(c.entries), c.size
+	c.mu.RUnlock()
+
+	return []models.Statistic{{
+		Name: "queryCache",
+		Tags: tags,
+		Values: map[string]interface{}{
+			statQueryCacheHits:          atomic.LoadInt64(&c.stats.Hits),
+			statQueryCacheMisses:        atomic.LoadInt64(&c.stats.Misses),
+			statQueryCacheStores:        atomic.LoadInt64(&c.stats.Stores),
+			statQueryCacheEvictions:     atomic.LoadInt64(&c.stats.Evictions),
+			statQueryCacheInvalidations: atomic.LoadInt64(&c.stats.Invalidations),
+			statQueryCacheEntries:       int64(entries),
+			statQueryCacheSize:          int64(size),
+		},
+	}}
+}
+
+// InvalidateShard drops all cached results for the shard and discards any
+// results for it that are currently being recorded.
+func (c *QueryCache) InvalidateShard(id uint64) {
+	// Writes to shards that are not cached are the common case, so avoid
+	// taking the write lock for them.
+	c.mu.RLock()
+	_, ok := c.shards[id]
+	c.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s := c.shards[id]; s != nil {
+		c.invalidate(id, s)
+	}
+}
+
+// InvalidateDatabase drops all cached results for shards of the database.
+func (c *QueryCache) InvalidateDatabase(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, s := range c.shards {
+		if s.database == name {
+			c.invalidate(id, s)
+		}
+	}
+}
+
+func (c *QueryCache) invalidate(id uint64, s *queryCacheShard) {
+	s.epoch++
+	for key := range s.keys {
+		c.remove(c.entries[key])
+		atomic.AddInt64(&c.stats.Invalidations, 1)
+	}
+	c.releaseShard(id, s)
+}
+
+// get returns the entry for key and marks it as the most recently used.
+func (c *QueryCache) get(key string) *queryCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ele, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.evictor.MoveToFront(ele)
+	return ele.Value.(*queryCacheEntry)
+}
+
+// remove removes an entry from the cache. The caller must hold the write lock.
+func (c *QueryCache) remove(ele *list.Element) {
+	e := c.evictor.Remove(ele).(*queryCacheEntry)
+	delete(c.entries, e.key)
+	c.size -= e.size()
+	if s := c.shards[e.shardID]; s != nil {
+		delete(s.keys, e.key)
+	}
+}
+
+// releaseShard forgets the state of a shard once nothing references it.
+func (c *QueryCache) releaseShard(id uint64, s *queryCacheShard) {
+	if len(s.keys) == 0 && s.recordings == 0 {
+		delete(c.shards, id)
+	}
+}
+
+// begin starts recording the result for key on the given shard.
+func (c *QueryCache) begin(id uint64, database, key string) *queryCacheRecorder {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.shards[id]
+	if s == nil {
+		s = &queryCacheShard{database: database, keys: make(map[string]struct{})}
+		c.shards[id] = s
+	}
+	s.recordings++
+	return &queryCacheRecorder{cache: c, shardID: id, shard: s, epoch: s.epoch, key: key}
+}
+
+// finish stores the recorded result, unless the shard was invalidated while
+// it was being recorded, and releases the recording.
+func (c *QueryCache) finish(r *queryCacheRecorder, typ influxql.DataType, stats query.IteratorStats, store bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r.shard.recordings--
+	defer c.releaseShard(r.shardID, r.shard)
+
+	if !store || r.shard.epoch != r.epoch {
+		return
+	} else if _, ok := c.entries[r.key]; ok {
+		return
+	}
+
+	e := &queryCacheEntry{
+		key:     r.key,
+		shardID: r.shardID,
+		typ:     typ,
+		buf:     r.buf.Bytes(),
+		stats:   stats,
+	}
+	c.entries[e.key] = c.evictor.PushFront(e)
+	r.shard.keys[e.key] = struct{}{}
+	c.size += e.size()
+	atomic.AddInt64(&c.stats.Stores, 1)
+
+	// Evict the least recently used entries until we are within our limit.
+	for c.size > c.maxSize && c.evictor.Len() > 0 {
+		c.remove(c.evictor.Back())
+		atomic.AddInt64(&c.stats.Evictions, 1)
+	}
+}
+
+// createIterator returns an iterator for the shard, either replayed from the
+// cache or recorded into the cache as it is read.
+func (c *QueryCache) createIterator(ctx context.Context, sh *cachedShard, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
+	key := queryCacheKey(sh.id, m, opt)
+	if e := c.get(key); e != nil {
+		atomic.AddInt64(&c.stats.Hits, 1)
+		return query.NewReaderIterator(ctx, bytes.NewReader(e.buf), e.typ, e.stats), nil
+	}
+	atomic.AddInt64(&c.stats.Misses, 1)
+
+	r := c.begin(sh.id, sh.database, key)
+	itr, err := sh.CreateIterator(ctx, m, opt)
+	if err != nil || itr == nil {
+		r.abandon()
+		return itr, err
+	}
+
+	switch itr := itr.(type) {
+	case query.FloatIterator:
+		return &floatRecordingIterator{input: itr, enc: query.NewFloatPointEncoder(&r.buf), rec: r}, nil
+	case query.IntegerIterator:
+		return &integerRecordingIterator{input: itr, enc: query.NewIntegerPointEncoder(&r.buf), rec: r}, nil
+	case query.UnsignedIterator:
+		return &unsignedRecordingIterator{input: itr, enc: query.NewUnsignedPointEncoder(&r.buf), rec: r}, nil
+	case query.StringIterator:
+		return &stringRecordingIterator{input: itr, enc: query.NewStringPointEncoder(&r.buf), rec: r}, nil
+	case query.BooleanIterator:
+		return &booleanRecordingIterator{input: itr, enc: query.NewBooleanPointEncoder(&r.buf), rec: r}, nil
+	default:
+		r.abandon()
+		return itr, nil
+	}
+}
+
+// queryCacheKey returns the cache key for the result of a single shard. The
+// key is built from every iterator option that can change the points the
+// shard returns.
+func queryCacheKey(shardID uint64, m *influxql.Measurement, opt query.IteratorOptions) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d\x00%s\x00", shardID, m.String())
+	if opt.Expr != nil {
+		buf.WriteString(opt.Expr.String())
+	}
+	buf.WriteByte(0)
+	for _, ref := range opt.Aux {
+		fmt.Fprintf(&buf, "%s::%s,", ref.Val, ref.Type)
+	}
+	fmt.Fprintf(&buf, "\x00%d/%d\x00%q\x00", opt.Interval.Duration, opt.Interval.Offset, opt.Dimensions)
+
+	groupBy := make([]string, 0, len(opt.GroupBy))
+	for k := range opt.GroupBy {
+		groupBy = append(groupBy, k)
+	}
+	sort.Strings(groupBy)
+	fmt.Fprintf(&buf, "%q\x00", groupBy)
+
+	if opt.Location != nil {
+		buf.WriteString(opt.Location.String())
+	}
+	fmt.Fprintf(&buf, "\x00%d\x00%v\x00", opt.Fill, opt.FillValue)
+	if opt.Condition != nil {
+		buf.WriteString(opt.Condition.String())
+	}
+	fmt.Fprintf(&buf, "\x00%d\x00%d\x00%t\x00%d/%d/%d/%d\x00%t%t%t",
+		opt.StartTime, opt.EndTime, opt.Ascending,
+		opt.Limit, opt.Offset, opt.SLimit, opt.SOffset,
+		opt.StripName, opt.Dedupe, opt.Ordered)
+	return buf.String()
+}
+
+// queryCacheRecorder accumulates the encoded points of a shard iterator.
+type queryCacheRecorder struct {
+	cache   *QueryCache
+	shardID uint64
+	shard   *queryCacheShard
+	epoch   uint64
+	key     string
+	buf     bytes.Buffer
+	done    bool
+}
+
+// recording returns true if points should still be written to the buffer.
+func (r *queryCacheRecorder) recording() bool { return !r.done }
+
+// check abandons the recording if it has grown beyond the maximum entry size.
+func (r *queryCacheRecorder) check(err error) {
+	if err != nil || r.buf.Len() > r.cache.maxEntrySize {
+		r.abandon()
+	}
+}
+
+// commit stores the recorded result in the cache.
+func (r *queryCacheRecorder) commit(typ influxql.DataType, stats query.IteratorStats) {
+	if r.done {
+		return
+	}
+	r.done = true
+	r.cache.finish(r, typ, stats, true)
+}
+
+// abandon discards the recorded result.
+func (r *queryCacheRecorder) abandon() {
+	if r.done {
+		return
+	}
+	r.done = true
+	r.buf = bytes.Buffer{}
+	r.cache.finish(r, influxql.Unknown, query.IteratorStats{}, false)
+}
+
+// floatRecordingIterator records the points of a FloatIterator as they are read.
+type floatRecordingIterator struct {
+	input query.FloatIterator
+	enc   *query.FloatPointEncoder
+	rec   *queryCacheRecorder
+}
+
+func (itr *floatRecordingIterator) Stats() query.IteratorStats { return itr.input.Stats() }
+
+func (itr *floatRecordingIterator) Close() error {
+	itr.rec.abandon()
+	return itr.input.Close()
+}
+
+func (itr *floatRecordingIterator) Next() (*query.FloatPoint, error) {
+	p, err := itr.input.Next()
+	if err != nil {
+		itr.rec.abandon()
+		return nil, err
+	} else if p == nil {
+		itr.rec.commit(influxql.Float, itr.input.Stats())
+		return nil, nil
+	}
+	if itr.rec.recording() {
+		itr.rec.check(itr.enc.EncodeFloatPoint(p))
+	}
+	return p, nil
+}
+
+// integerRecordingIterator records the points of an IntegerIterator as they are read.
+type integerRecordingIterator struct {
+	input query.IntegerIterator
+	enc   *query.IntegerPointEncoder
+	rec   *queryCacheRecorder
+}
+
+func (itr *integerRecordingIterator) Stats() query.IteratorStats { return itr.input.Stats() }
+
+func (itr *integerRecordingIterator) Close() error {
+	itr.rec.abandon()
+	return itr.input.Close()
+}
+
+func (itr *integerRecordingIterator) Next() (*query.IntegerPoint, error) {
+	p, err := itr.input.Next()
+	if err != nil {
+		itr.rec.abandon()
+		return nil, err
+	} else if p == nil {
+		itr.rec.commit(influxql.Integer, itr.input.Stats())
+		return nil, nil
+	}
+	if itr.rec.recording() {
+		itr.rec.check(itr.enc.EncodeIntegerPoint(p))
+	}
+	return p, nil
+}
+
+// unsignedRecordingIterator records the points of an UnsignedIterator as they are read.
+type unsignedRecordingIterator struct {
+	input query.UnsignedIterator
+	enc   *query.UnsignedPointEncoder
+	rec   *queryCacheRecorder
+}
+
+func (itr *unsignedRecordingIterator) Stats() query.IteratorStats { return itr.input.Stats() }
+
+func (itr *unsignedRecordingIterator) Close() error {
+	itr.rec.abandon()
+	return itr.input.Close()
+}
+
+func (itr *unsignedRecordingIterator) Next() (*query.UnsignedPoint, error) {
+	p, err := itr.input.Next()
+	if err != nil {
+		itr.rec.abandon()
+		return nil, err
+	} else if p == nil {
+		itr.rec.commit(influxql.Unsigned, itr.input.Stats())
+		return nil, nil
+	}
+	if itr.rec.recording() {
+		itr.rec.check(itr.enc.EncodeUnsignedPoint(p))
+	}
+	return p, nil
+}
+
+// stringRecordingIterator records the points of a StringIterator as they are read.
+type stringRecordingIterator struct {
+	input query.StringIterator
+	enc   *query.StringPointEncoder
+	rec   *queryCacheRecorder
+}
+
+func (itr *stringRecordingIterator) Stats() query.IteratorStats { return itr.input.Stats() }
+
+func (itr *stringRecordingIterator) Close() error {
+	itr.rec.abandon()
+	return itr.input.Close()
+}
+
+func (itr *stringRecordingIterator) Next() (*query.StringPoint, error) {
+	p, err := itr.input.Next()
+	if err != nil {
+		itr.rec.abandon()
+		return nil, err
+	} else if p == nil {
+		itr.rec.commit(influxql.String, itr.input.Stats())
+		return nil, nil
+	}
+	if itr.rec.recording() {
+		itr.rec.check(itr.enc.EncodeStringPoint(p))
+	}
+	return p, nil
+}
+
+// booleanRecordingIterator records the points of a BooleanIterator as they are read.
+type booleanRecordingIterator struct {
+	input query.BooleanIterator
+	enc   *query.BooleanPointEncoder
+	rec   *queryCacheRecorder
+}
+
+func (itr *booleanRecordingIterator) Stats() query.IteratorStats { return itr.input.Stats() }
+
+func (itr *booleanRecordingIterator) Close() error {
+	itr.rec.abandon()
+	return itr.input.Close()
+}
+
+func (itr *booleanRecordingIterator) Next() (*query.BooleanPoint, error) {
+	p, err := itr.input.Next()
+	if err != nil {
+		itr.rec.abandon()
+		return nil, err
+	} else if p == nil {
+		itr.rec.commit(influxql.Boolean, itr.input.Stats())
+		return nil, nil
+	}
+	if itr.rec.recording() {
+		itr.rec.check(itr.enc.EncodeBooleanPoint(p))
+	}
+	return p, nil
+}
+
+// cachedShard is a single shard whose shard group has ended.
+type cachedShard struct {
+	tsdb.ShardGroup
+	id       uint64
+	database string
+
+	// Time range covered by the shard group, in nanoseconds. end is exclusive.
+	start, end int64
+}
+
+// cachedShardGroup is a tsdb.ShardGroup that serves the results of shards in
+// ended shard groups from a QueryCache. Shards in shard groups that are still
+// current are always read directly.
+type cachedShardGroup struct {
+	// All of the mapped shards. Used for everything except creating iterators.
+	tsdb.ShardGroup
+
+	hot   tsdb.ShardGroup
+	cold  []*cachedShard
+	cache *QueryCache
+}
+
+// CreateIterator creates an iterator over all mapped shards, using the cache
+// for ended shard groups when the result of the query can be cached.
+func (g *cachedShardGroup) CreateIterator(ctx context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
+	// Only aggregate results are cached. Raw queries can return an arbitrary
+	// number of points and are better served by the storage engine directly.
+	// Results filtered by series-level authorization depend on the user.
+	if _, ok := opt.Expr.(*influxql.Call); !ok || m.SystemIterator != "" || !query.AuthorizerIsOpen(opt.Authorizer) {
+		return g.ShardGroup.CreateIterator(ctx, m, opt)
+	}
+
+	itrs := make([]query.Iterator, 0, len(g.cold)+1)
+	add := func(itr query.Iterator) error {
+		itrs = append(itrs, itr)
+
+		// Enforce series limit at creation time.
+		if opt.MaxSeriesN > 0 {
+			if stats := itr.Stats(); stats.SeriesN > opt.MaxSeriesN {
+				return query.ErrMaxSelectSeriesLimitExceeded(stats.SeriesN, opt.MaxSeriesN)
+			}
+		}
+		return nil
+	}
+
+	if g.hot != nil {
+		itr, err := g.hot.CreateIterator(ctx, m, opt)
+		if err != nil {
+			return nil, err
+		} else if itr != nil {
+			if err := add(itr); err != nil {
+				query.Iterators(itrs).Close()
+				return nil, err
+			}
+		}
+	}
+
+	for _, sh := range g.cold {
+		itr, err := g.cache.createIterator(ctx, sh, m, sh.normalize(opt))
+		if err != nil {
+			query.Iterators(itrs).Close()
+			return nil, err
+		} else if itr == nil {
+			continue
+		}
+		if err := add(itr); err != nil {
+			query.Iterators(itrs).Close()
+			return nil, err
+		}
+
+		select {
+		case <-opt.InterruptCh:
+			query.Iterators(itrs).Close()
+			return nil, query.ErrQueryInterrupted
+		default:
+		}
+	}
+	return query.Iterators(itrs).Merge(opt)
+}
+
+// normalize returns opt with its time range set to the range of the shard
+// group when the query covers the whole shard group. This keeps the cache key
+// stable for queries with a relative time range, such as now() - 7d.
+//
+// Without a GROUP BY time interval, aggregates are stamped with the start of
+// the query's time range so the range cannot be changed.
+func (sh *cachedShard) normalize(opt query.IteratorOptions) query.IteratorOptions {
+	if opt.Interval.IsZero() {
+		return opt
+	}
+	if opt.StartTime <= sh.start && opt.EndTime >= sh.end-1 {
+		opt.StartTime, opt.EndTime = sh.start, sh.end-1
+	}
+	return opt
+}
@@ -0,0 +1,194 @@
+package coordinator_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/internal"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+func TestQueryCache_CreateIterator(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Hour)
+	cold := now.Add(-2 * time.Hour)
+
+	var metaClient MetaClient
+	metaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) ([]meta.ShardGroupInfo, error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, StartTime: cold, EndTime: cold.Add(time.Hour), Shards: []meta.ShardInfo{{ID: 1}}},
+			{ID: 2, StartTime: now, EndTime: now.Add(time.Hour), Shards: []meta.ShardInfo{{ID: 2}}},
+		}, nil
+	}
+
+	// Count the number of times each shard is read.
+	reads := make(map[uint64]int)
+	times := map[uint64]int64{1: cold.UnixNano(), 2: now.UnixNano()}
+	tsdbStore := &internal.TSDBStoreMock{}
+	tsdbStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(ctx context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
+			var points []query.FloatPoint
+			for _, id := range ids {
+				reads[id]++
+				points = append(points, query.FloatPoint{Name: "cpu", Time: times[id], Value: float64(id), Aggregated: 2})
+			}
+			return &FloatIterator{Points: points}, nil
+		}
+		return &sh
+	}
+
+	cache := coordinator.NewQueryCache(1024*1024, 1024)
+	shardMapper := &coordinator.LocalShardMapper{
+		MetaClient: &metaClient,
+		TSDBStore:  tsdbStore,
+		QueryCache: cache,
+	}
+
+	measurement := &influxql.Measurement{Database: "db0", RetentionPolicy: "rp0", Name: "cpu"}
+	opt := query.IteratorOptions{
+		Expr:      influxql.MustParseExpr("mean(value)"),
+		Interval:  query.Interval{Duration: time.Minute},
+		StartTime: cold.Add(-time.Hour).UnixNano(),
+		EndTime:   now.Add(time.Hour).UnixNano(),
+	}
+
+	// readAll maps the shards, reads every point and returns the sorted values.
+	readAll := func(sopt query.SelectOptions, opt query.IteratorOptions) []float64 {
+		t.Helper()
+		sg, err := shardMapper.MapShards([]influxql.Source{measurement}, influxql.TimeRange{}, sopt)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		defer sg.Close()
+
+		itr, err := sg.CreateIterator(context.Background(), measurement, opt)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		defer itr.Close()
+
+		var values []float64
+		for {
+			p, err := itr.(query.FloatIterator).Next()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			} else if p == nil {
+				sort.Float64s(values)
+				return values
+			}
+			values = append(values, p.Value)
+		}
+	}
+
+	want := []float64{1, 2}
+	for i := 0; i < 2; i++ {
+		if got := readAll(query.SelectOptions{}, opt); !cmp.Equal(got, want) {
+			t.Fatalf("unexpected values: %s", cmp.Diff(got, want))
+		}
+	}
+	if reads[1] != 1 || reads[2] != 2 {
+		t.Fatalf("unexpected shard reads: %v", reads)
+	}
+
+	// A query with a later relative time range covering the whole cold
+	// shard group is still served from the cache.
+	shifted := opt
+	shifted.StartTime += int64(time.Minute)
+	readAll(query.SelectOptions{}, shifted)
+	if reads[1] != 1 {
+		t.Fatalf("unexpected cold shard reads: %d", reads[1])
+	}
+
+	// Bypassing the cache always reads the shard.
+	readAll(query.SelectOptions{NoCache: true}, opt)
+	if reads[1] != 2 {
+		t.Fatalf("unexpected cold shard reads: %d", reads[1])
+	}
+
+	// Writing to the cold shard invalidates its results.
+	cache.InvalidateShard(1)
+	readAll(query.SelectOptions{}, opt)
+	readAll(query.SelectOptions{}, opt)
+	if reads[1] != 3 {
+		t.Fatalf("unexpected cold shard reads: %d", reads[1])
+	}
+
+	// Raw queries are never cached.
+	raw := opt
+	raw.Expr = &influxql.VarRef{Val: "value"}
+	readAll(query.SelectOptions{}, raw)
+	readAll(query.SelectOptions{}, raw)
+	if reads[1] != 5 {
+		t.Fatalf("unexpected cold shard reads: %d", reads[1])
+	}
+
+	stats := cache.Statistics(nil)[0].Values
+	if stats["hits"] != int64(3) || stats["misses"] != int64(2) || stats["invalidations"] != int64(1) || stats["entries"] != int64(1) {
+		t.Fatalf("unexpected statistics: %v", stats)
+	}
+}
+
+func TestQueryCache_InvalidateDuringRecording(t *testing.T) {
+	var metaClient MetaClient
+	metaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) ([]meta.ShardGroupInfo, error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, StartTime: time.Unix(0, 0), EndTime: time.Unix(3600, 0), Shards: []meta.ShardInfo{{ID: 1}}},
+		}, nil
+	}
+
+	var reads int
+	tsdbStore := &internal.TSDBStoreMock{}
+	tsdbStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(ctx context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
+			reads++
+			return &FloatIterator{Points: []query.FloatPoint{{Name: "cpu", Value: 1}}}, nil
+		}
+		return &sh
+	}
+
+	cache := coordinator.NewQueryCache(1024*1024, 1024)
+	shardMapper := &coordinator.LocalShardMapper{
+		MetaClient: &metaClient,
+		TSDBStore:  tsdbStore,
+		QueryCache: cache,
+	}
+
+	measurement := &influxql.Measurement{Database: "db0", RetentionPolicy: "rp0", Name: "cpu"}
+	opt := query.IteratorOptions{
+		Expr:      influxql.MustParseExpr("max(value)"),
+		StartTime: influxql.MinTime,
+		EndTime:   influxql.MaxTime,
+	}
+
+	for i := 0; i < 2; i++ {
+		sg, err := shardMapper.MapShards([]influxql.Source{measurement}, influxql.TimeRange{}, query.SelectOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		itr, err := sg.CreateIterator(context.Background(), measurement, opt)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		// Data is deleted while the first query is still reading the shard
+		// so its result must not be stored.
+		if i == 0 {
+			cache.InvalidateDatabase("db0")
+		}
+		query.DrainIterator(itr)
+		itr.Close()
+		sg.Close()
+	}
+
+	if reads != 2 {
+		t.Fatalf("unexpected shard reads: %d", reads)
+	}
+}
@@ -0,0 +1,366 @@
+package coordinator
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// The actions of relabel rules.
+const (
+	// RelabelKeep drops the points whose source value does not match the regex.
+	RelabelKeep = "keep"
+
+	// RelabelDrop drops the points whose source value matches the regex.
+	RelabelDrop = "drop"
+
+	// RelabelReplace sets the target tag to the replacement if the source
+	// value matches the regex. An empty result removes the target tag.
+	RelabelReplace = "replace"
+
+	// RelabelLabelDrop removes the tags whose keys match the regex.
+	RelabelLabelDrop = "labeldrop"
+
+	// RelabelLabelKeep removes the tags whose keys do not match the regex.
+	RelabelLabelKeep = "labelkeep"
+
+	// RelabelHashMod sets the target tag to the hash of the source value
+	// modulo the modulus.
+	RelabelHashMod = "hashmod"
+
+	// RelabelRename renames the measurements matching the regex to the
+	// replacement.
+	RelabelRename = "rename"
+
+	// RelabelTruncate truncates the values of the tags whose keys match the
+	// regex to the max length.
+	RelabelTruncate = "truncate"
+)
+
+// MeasurementLabel is the name of the measurement of a point in the source
+// tags and the target tag of relabel rules.
+const MeasurementLabel = "_measurement"
+
+const (
+	// DefaultRelabelSeparator is the default separator of the source values.
+	DefaultRelabelSeparator = ";"
+
+	// DefaultRelabelRegex is the default regex of relabel rules.
+	DefaultRelabelRegex = "(.*)"
+
+	// DefaultRelabelReplacement is the default replacement of relabel rules.
+	DefaultRelabelReplacement = "$1"
+)
+
+// The keys for statistics generated by relabel rules.
+const (
+	statRelabelPointsAffected = "pointsAffected"
+	statRelabelPointsDropped  = "pointsDropped"
+)
+
+// RelabelConfig represents a rule changing or dropping the points written to
+// a database, modeled after the relabel configurations of Prometheus. The
+// regex is anchored at both ends and the source value is the values of the
+// source tags joined by the separator.
+type RelabelConfig struct {
+	Name        string   `toml:"name"`
+	Database    string   `toml:"database"`
+	Action      string   `toml:"action"`
+	SourceTags  []string `toml:"source-tags"`
+	Separator   string   `toml:"separator"`
+	Regex       string   `toml:"regex"`
+	TargetTag   string   `toml:"target-tag"`
+	Replacement string   `toml:"replacement"`
+	Modulus     uint64   `toml:"modulus"`
+	MaxLength   int      `toml:"max-length"`
+}
+
+// Validate returns an error if the rule is invalid.
+func (c RelabelConfig) Validate() error {
+	switch c.Action {
+	case RelabelKeep, RelabelDrop, RelabelHashMod:
+		if len(c.SourceTags) == 0 {
+			return fmt.Errorf("relabel action %s requires source-tags", c.Action)
+		}
+	case RelabelReplace, RelabelLabelDrop, RelabelLabelKeep, RelabelRename, RelabelTruncate:
+	default:
+		return fmt.Errorf("invalid relabel action %q", c.Action)
+	}
+
+	switch c.Action {
+	case RelabelReplace:
+		if c.TargetTag == "" {
+			return errors.New("relabel action replace requires target-tag")
+		}
+	case RelabelHashMod:
+		if c.TargetTag == "" || c.TargetTag == MeasurementLabel {
+			return errors.New("relabel action hashmod requires a target-tag")
+		} else if c.Modulus == 0 {
+			return errors.New("relabel action hashmod requires a modulus")
+		}
+	case RelabelTruncate:
+		if c.MaxLength <= 0 {
+			return errors.New("relabel action truncate requires a positive max-length")
+		}
+	}
+
+	if _, err := compileRelabelRegex(c.Regex); err != nil {
+		return fmt.Errorf("invalid relabel regex: %s", err)
+	}
+	return nil
+}
+
+// compileRelabelRegex returns the anchored regex of a rule.
+func compileRelabelRegex(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		expr = DefaultRelabelRegex
+	}
+	return regexp.Compile("^(?:" + expr + ")$")
+}
+
+// Relabeler applies relabel rules to the points written to databases.
+type Relabeler struct {
+	rules []*relabelRule
+}
+
+type relabelRule struct {
+	RelabelConfig
+	regex *regexp.Regexp
+	stats relabelStatistics
+}
+
+type relabelStatistics struct {
+	PointsAffected int64
+	PointsDropped  int64
+}
+
+// NewRelabeler returns a Relabeler applying the rules in order.
+func NewRelabeler(configs []RelabelConfig) (*Relabeler, error) {
+	r := &Relabeler{}
+	for i, c := range configs {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+		if c.Name == "" {
+			c.Name = strconv.Itoa(i)
+		}
+		if c.Separator == "" {
+			c.Separator = DefaultRelabelSeparator
+		}
+		if c.Replacement == "" {
+			c.Replacement = DefaultRelabelReplacement
+		}
+		regex, err := compileRelabelRegex(c.Regex)
+		if err != nil {
+			return nil, err
+		}
+		r.rules = append(r.rules, &relabelRule{RelabelConfig: c, regex: regex})
+	}
+	return r, nil
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (r *Relabeler) Statistics(tags map[string]string) []models.Statistic {
+	statistics := make([]models.Statistic, 0, len(r.rules))
+	for _, rule := range r.rules {
+		statistics = append(statistics, models.Statistic{
+			Name: "relabel",
+			Tags: models.StatisticTags{"rule": rule.Name, "database": rule.Database}.Merge(tags),
+			Values: map[string]interface{}{
+				statRelabelPointsAffected: atomic.LoadInt64(&rule.stats.PointsAffected),
+				statRelabelPointsDropped:  atomic.LoadInt64(&rule.stats.PointsDropped),
+			},
+		})
+	}
+	return statistics
+}
+
+// Relabel applies the rules of the database to the points and returns the
+// points that are kept. Points that would become invalid are kept unchanged.
+func (r *Relabeler) Relabel(database string, points []models.Point) []models.Point {
+	var rules []*relabelRule
+	for _, rule := range r.rules {
+		if rule.Database == "" || rule.Database == database {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return points
+	}
+
+	kept := make([]models.Point, 0, len(points))
+	for _, p := range points {
+		if p, ok := relabelPoint(rules, p); ok {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// relabelPoint applies the rules to a point. It returns false if the point is
+// dropped.
+func relabelPoint(rules []*relabelRule, p models.Point) (models.Point, bool) {
+	lp := &labeledPoint{name: string(p.Name()), tags: p.Tags()}
+	for _, rule := range rules {
+		keep, changed := rule.apply(lp)
+		if !keep {
+			atomic.AddInt64(&rule.stats.PointsAffected, 1)
+			atomic.AddInt64(&rule.stats.PointsDropped, 1)
+			return nil, false
+		} else if changed {
+			atomic.AddInt64(&rule.stats.PointsAffected, 1)
+		}
+	}
+	if !lp.changed {
+		return p, true
+	}
+
+	fields, err := p.Fields()
+	if err != nil {
+		return p, true
+	}
+	pt, err := models.NewPoint(lp.name, lp.tags, fields, p.Time())
+	if err != nil {
+		return p, true
+	}
+	return pt, true
+}
+
+// labeledPoint holds the measurement and tags of a point being relabeled.
+type labeledPoint struct {
+	name    string
+	tags    models.Tags
+	cloned  bool
+	changed bool
+}
+
+func (p *labeledPoint) get(key string) string {
+	if key == MeasurementLabel {
+		return p.name
+	}
+	return string(p.tags.Get([]byte(key)))
+}
+
+// set sets a tag, or the measurement. An empty value removes the tag.
+func (p *labeledPoint) set(key, value string) bool {
+	if p.get(key) == value {
+		return false
+	}
+	if key == MeasurementLabel {
+		if value == "" {
+			return false
+		}
+		p.name = value
+	} else {
+		p.clone()
+		if value == "" {
+			p.tags.Delete([]byte(key))
+		} else {
+			p.tags.Set([]byte(key), []byte(value))
+		}
+	}
+	p.changed = true
+	return true
+}
+
+// filterTags keeps the tags for which fn returns true.
+func (p *labeledPoint) filterTags(fn func(t models.Tag) bool) bool {
+	var removed bool
+	for _, t := range p.tags {
+		if !fn(t) {
+			removed = true
+			break
+		}
+	}
+	if !removed {
+		return false
+	}
+
+	tags := make(models.Tags, 0, len(p.tags))
+	for _, t := range p.tags {
+		if fn(t) {
+			tags = append(tags, t)
+		}
+	}
+	p.tags, p.cloned, p.changed = tags, true, true
+	return true
+}
+
+// clone copies the tags before they are first modified so that the tags of
+// the original point are unchanged.
+func (p *labeledPoint) clone() {
+	if !p.cloned {
+		p.tags = p.tags.Clone()
+		p.cloned = true
+	}
+}
+
+// apply applies the rule to a point. It returns false if the point is
+// dropped, and whether the point is changed.
+func (r *relabelRule) apply(p *labeledPoint) (keep, changed bool) {
+	switch r.Action {
+	case RelabelKeep:
+		return r.regex.MatchString(r.sourceValue(p)), false
+	case RelabelDrop:
+		return !r.regex.MatchString(r.sourceValue(p)), false
+	case RelabelReplace:
+		return true, r.replace(p, r.sourceValue(p), r.TargetTag)
+	case RelabelRename:
+		return true, r.replace(p, p.name, MeasurementLabel)
+	case RelabelHashMod:
+		sum := md5.Sum([]byte(r.sourceValue(p)))
+		mod := binary.BigEndian.Uint64(sum[8:]) % r.Modulus
+		return true, p.set(r.TargetTag, strconv.FormatUint(mod, 10))
+	case RelabelLabelDrop:
+		return true, p.filterTags(func(t models.Tag) bool { return !r.regex.Match(t.Key) })
+	case RelabelLabelKeep:
+		return true, p.filterTags(func(t models.Tag) bool { return r.regex.Match(t.Key) })
+	case RelabelTruncate:
+		for _, t := range p.tags {
+			if len(t.Value) > r.MaxLength && r.regex.Match(t.Key) {
+				changed = p.set(string(t.Key), truncate(string(t.Value), r.MaxLength)) || changed
+			}
+		}
+		return true, changed
+	}
+	return true, false
+}
+
+// replace sets the target to the expanded replacement if the value matches
+// the regex.
+func (r *relabelRule) replace(p *labeledPoint, value, target string) bool {
+	match := r.regex.FindStringSubmatchIndex(value)
+	if match == nil {
+		return false
+	}
+	res := r.regex.ExpandString(nil, r.Replacement, value, match)
+	return p.set(target, string(res))
+}
+
+// sourceValue returns the values of the source tags joined by the separator.
+func (r *relabelRule) sourceValue(p *labeledPoint) string {
+	if len(r.SourceTags) == 1 {
+		return p.get(r.SourceTags[0])
+	}
+	values := make([]string, len(r.SourceTags))
+	for i, key := range r.SourceTags {
+		values[i] = p.get(key)
+	}
+	return strings.Join(values, r.Separator)
+}
+
+// truncate returns the first n bytes of s without splitting a character.
+func truncate(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,151 @@
+package coordinator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+)
+
+func TestRelabeler_Relabel(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		rules []coordinator.RelabelConfig
+		in    string
+		out   string
+	}{
+		{
+			name:  "keep",
+			rules: []coordinator.RelabelConfig{{Action: "keep", SourceTags: []string{"_measurement"}, Regex: "cpu|mem"}},
+			in:    "cpu,host=a value=1 1\ndisk,host=a value=1 1\nmem value=1 1",
+			out:   "cpu,host=a value=1 1\nmem value=1 1",
+		},
+		{
+			name:  "drop",
+			rules: []coordinator.RelabelConfig{{Action: "drop", SourceTags: []string{"host", "region"}, Regex: "a;us"}},
+			in:    "cpu,host=a,region=us value=1 1\ncpu,host=a,region=eu value=1 1",
+			out:   "cpu,host=a,region=eu value=1 1",
+		},
+		{
+			name:  "replace",
+			rules: []coordinator.RelabelConfig{{Action: "replace", SourceTags: []string{"host"}, Regex: `([^.]+)\..*`, TargetTag: "host"}},
+			in:    "cpu,host=a.example.com value=1 1\ncpu,host=b value=1 1",
+			out:   "cpu,host=a value=1 1\ncpu,host=b value=1 1",
+		},
+		{
+			name:  "replace new tag",
+			rules: []coordinator.RelabelConfig{{Action: "replace", SourceTags: []string{"host", "region"}, Separator: "-", TargetTag: "zone"}},
+			in:    "cpu,host=a,region=us value=1 1",
+			out:   "cpu,host=a,region=us,zone=a-us value=1 1",
+		},
+		{
+			name:  "labeldrop",
+			rules: []coordinator.RelabelConfig{{Action: "labeldrop", Regex: "pod_.*"}},
+			in:    "cpu,host=a,pod_uid=123,pod_ip=10.0.0.1 value=1 1",
+			out:   "cpu,host=a value=1 1",
+		},
+		{
+			name:  "labelkeep",
+			rules: []coordinator.RelabelConfig{{Action: "labelkeep", Regex: "host"}},
+			in:    "cpu,host=a,pod_uid=123 value=1 1",
+			out:   "cpu,host=a value=1 1",
+		},
+		{
+			name:  "hashmod",
+			rules: []coordinator.RelabelConfig{{Action: "hashmod", SourceTags: []string{"host"}, TargetTag: "shard", Modulus: 1}},
+			in:    "cpu,host=a value=1 1",
+			out:   "cpu,host=a,shard=0 value=1 1",
+		},
+		{
+			name:  "rename",
+			rules: []coordinator.RelabelConfig{{Action: "rename", Regex: "win_(.*)", Replacement: "windows_$1"}},
+			in:    "win_cpu value=1 1\ncpu value=1 1",
+			out:   "windows_cpu value=1 1\ncpu value=1 1",
+		},
+		{
+			name:  "truncate",
+			rules: []coordinator.RelabelConfig{{Action: "truncate", Regex: "url", MaxLength: 4}},
+			in:    "http,url=/api/v1,host=abcdef value=1 1",
+			out:   "http,host=abcdef,url=/api value=1 1",
+		},
+		{
+			name:  "other database",
+			rules: []coordinator.RelabelConfig{{Database: "db1", Action: "labeldrop", Regex: "host"}},
+			in:    "cpu,host=a value=1 1",
+			out:   "cpu,host=a value=1 1",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := coordinator.NewRelabeler(tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			points, err := models.ParsePointsString(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lines []string
+			for _, p := range r.Relabel("db0", points) {
+				lines = append(lines, p.String())
+			}
+			if got := strings.Join(lines, "\n"); got != tt.out {
+				t.Fatalf("unexpected points:\ngot:\n%s\nexp:\n%s", got, tt.out)
+			}
+		})
+	}
+}
+
+func TestRelabeler_Statistics(t *testing.T) {
+	r, err := coordinator.NewRelabeler([]coordinator.RelabelConfig{
+		{Name: "drop-disk", Action: "drop", SourceTags: []string{"_measurement"}, Regex: "disk"},
+		{Action: "labeldrop", Regex: "pod_uid"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	points, err := models.ParsePointsString("cpu,pod_uid=1 value=1 1\ncpu value=1 1\ndisk,pod_uid=1 value=1 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Relabel("db0", points); len(got) != 2 {
+		t.Fatalf("got %d points, expected 2", len(got))
+	}
+
+	stats := r.Statistics(nil)
+	if len(stats) != 2 {
+		t.Fatalf("got %d statistics, expected 2", len(stats))
+	}
+	for i, exp := range []struct {
+		rule              string
+		affected, dropped int64
+	}{
+		{rule: "drop-disk", affected: 1, dropped: 1},
+		{rule: "1", affected: 1, dropped: 0},
+	} {
+		if got := stats[i].Tags["rule"]; got != exp.rule {
+			t.Errorf("statistic %d: got rule %s, expected %s", i, got, exp.rule)
+		}
+		if got := stats[i].Values["pointsAffected"]; got != exp.affected {
+			t.Errorf("statistic %d: got %v points affected, expected %d", i, got, exp.affected)
+		}
+		if got := stats[i].Values["pointsDropped"]; got != exp.dropped {
+			t.Errorf("statistic %d: got %v points dropped, expected %d", i, got, exp.dropped)
+		}
+	}
+}
+
+func TestRelabelConfig_Validate(t *testing.T) {
+	for _, c := range []coordinator.RelabelConfig{
+		{Action: "unknown"},
+		{Action: "keep"},
+		{Action: "replace", SourceTags: []string{"host"}},
+		{Action: "hashmod", SourceTags: []string{"host"}, TargetTag: "shard"},
+		{Action: "truncate"},
+		{Action: "labeldrop", Regex: "("},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("expected error for %+v", c)
+		}
+	}
+}
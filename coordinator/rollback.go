@@ -0,0 +1,86 @@
+package coordinator
+
+import (
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+)
+
+// metaChangeClient is implemented by meta clients attributing the changes of
+// the meta data to the users and client addresses making them.
+type metaChangeClient interface {
+	WithClient(user, addr string) *meta.Client
+}
+
+// MetaHistory returns the versions of the meta data kept for rollbacks, from
+// the oldest to the current version.
+func (e *StatementExecutor) MetaHistory() []meta.HistoryEntry {
+	return e.MetaClient.History()
+}
+
+// RollbackMeta commits the version of the meta data with the given index as
+// a new version on behalf of user from the client address addr. Shard groups dropped since the version are
+// restored if their shards still exist on this server; the other shards are
+// reported as unrecoverable.
+func (e *StatementExecutor) RollbackMeta(index uint64, user, addr string) (*meta.RollbackResult, error) {
+	ids := make(map[uint64]struct{})
+	for _, id := range e.TSDBStore.ShardIDs() {
+		ids[id] = struct{}{}
+	}
+	exists := func(id uint64) bool {
+		_, ok := ids[id]
+		return ok
+	}
+
+	// Results cached for databases dropped or restored are stale.
+	dbs := e.MetaClient.Databases()
+	defer func() {
+		for _, di := range append(dbs, e.MetaClient.Databases()...) {
+			e.invalidateDatabase(di.Name)
+		}
+	}()
+
+	return e.WithClient(user, addr).MetaClient.Rollback(index, exists)
+}
+
+// WithClient returns a copy of e whose meta client attributes the meta data
+// it changes to user and the client address addr, or e if the meta client
+// doesn't support it.
+func (e *StatementExecutor) WithClient(user, addr string) *StatementExecutor {
+	c, ok := e.MetaClient.(metaChangeClient)
+	if !ok {
+		return e
+	}
+	other := *e
+	other.MetaClient = c.WithClient(user, addr)
+	return &other
+}
+
+// isMetaChange returns true if stmt changes the meta data.
+func isMetaChange(stmt influxql.Statement) bool {
+	switch stmt.(type) {
+	case *influxql.AlterRetentionPolicyStatement,
+		*influxql.CreateContinuousQueryStatement,
+		*influxql.CreateDatabaseStatement,
+		*influxql.CreateRetentionPolicyStatement,
+		*influxql.CreateSubscriptionStatement,
+		*influxql.CreateUserStatement,
+		*influxql.DropContinuousQueryStatement,
+		*influxql.DropDatabaseStatement,
+		*influxql.DropRetentionPolicyStatement,
+		*influxql.DropShardStatement,
+		*influxql.DropSubscriptionStatement,
+		*influxql.DropUserStatement,
+		*influxql.GrantAdminStatement,
+		*influxql.GrantStatement,
+		*influxql.RevokeAdminStatement,
+		*influxql.RevokeStatement,
+		*influxql.SetPasswordUserStatement,
+		*query.AlterDatabaseRenameStatement,
+		*query.AlterRetentionPolicyRenameStatement,
+		*query.CreateTokenStatement,
+		*query.DropTokenStatement:
+		return true
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package coordinator
 import (
 	"context"
 	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/influxdb/query"
@@ -27,6 +28,10 @@ type LocalShardMapper struct {
 	TSDBStore interface {
 		ShardGroup(ids []uint64) tsdb.ShardGroup
 	}
+
+	// QueryCache, if set, caches the results of shards in shard groups that
+	// have ended.
+	QueryCache *QueryCache
 }
 
 // MapShards maps the sources to the appropriate shards into an IteratorCreator.
@@ -37,14 +42,18 @@ func (e *LocalShardMapper) MapShards(sources influxql.Sources, t influxql.TimeRa
 
 	tmin := time.Unix(0, t.MinTimeNano())
 	tmax := time.Unix(0, t.MaxTimeNano())
-	if err := e.mapShards(a, sources, tmin, tmax); err != nil {
+	cache := e.QueryCache
+	if opt.NoCache {
+		cache = nil
+	}
+	if err := e.mapShards(a, sources, tmin, tmax, cache, opt.ShardN); err != nil {
 		return nil, err
 	}
 	a.MinTime, a.MaxTime = tmin, tmax
 	return a, nil
 }
 
-func (e *LocalShardMapper) mapShards(a *LocalShardMapping, sources influxql.Sources, tmin, tmax time.Time) error {
+func (e *LocalShardMapper) mapShards(a *LocalShardMapping, sources influxql.Sources, tmin, tmax time.Time, cache *QueryCache, shardN *int64) error {
 	for _, s := range sources {
 		switch s := s.(type) {
 		case *influxql.Measurement:
@@ -72,10 +81,17 @@ func (e *LocalShardMapper) mapShards(a *LocalShardMapping, sources influxql.Sour
 						shardIDs = append(shardIDs, si.ID)
 					}
 				}
-				a.ShardMap[source] = e.TSDBStore.ShardGroup(shardIDs)
+				if shardN != nil {
+					atomic.AddInt64(shardN, int64(len(shardIDs)))
+				}
+				if cache != nil {
+					a.ShardMap[source] = e.cachedShardGroup(cache, s.Database, groups, shardIDs)
+				} else {
+					a.ShardMap[source] = e.TSDBStore.ShardGroup(shardIDs)
+				}
 			}
 		case *influxql.SubQuery:
-			if err := e.mapShards(a, s.Statement.Sources, tmin, tmax); err != nil {
+			if err := e.mapShards(a, s.Statement.Sources, tmin, tmax, cache, shardN); err != nil {
 				return err
 			}
 		}
@@ -83,6 +99,40 @@ func (e *LocalShardMapper) mapShards(a *LocalShardMapping, sources influxql.Sour
 	return nil
 }
 
+// cachedShardGroup returns a shard group whose shards in ended shard groups
+// are read through the cache.
+func (e *LocalShardMapper) cachedShardGroup(cache *QueryCache, database string, groups []meta.ShardGroupInfo, shardIDs []uint64) tsdb.ShardGroup {
+	now := time.Now()
+
+	g := &cachedShardGroup{cache: cache}
+	var hotIDs []uint64
+	for _, sgi := range groups {
+		for _, si := range sgi.Shards {
+			if sgi.EndTime.After(now) {
+				hotIDs = append(hotIDs, si.ID)
+				continue
+			}
+			g.cold = append(g.cold, &cachedShard{
+				ShardGroup: e.TSDBStore.ShardGroup([]uint64{si.ID}),
+				id:         si.ID,
+				database:   database,
+				start:      sgi.StartTime.UnixNano(),
+				end:        sgi.EndTime.UnixNano(),
+			})
+		}
+	}
+
+	all := e.TSDBStore.ShardGroup(shardIDs)
+	if len(g.cold) == 0 {
+		return all
+	}
+	g.ShardGroup = all
+	if len(hotIDs) > 0 {
+		g.hot = e.TSDBStore.ShardGroup(hotIDs)
+	}
+	return g
+}
+
 // ShardMapper maps data sources to a list of shard information.
 type LocalShardMapping struct {
 	ShardMap map[Source]tsdb.ShardGroup
@@ -63,9 +63,12 @@ func TestLocalShardMapper(t *testing.T) {
 		RetentionPolicy: "rp0",
 		Name:            "cpu",
 	}
-	ic, err := shardMapper.MapShards([]influxql.Source{measurement}, influxql.TimeRange{}, query.SelectOptions{})
+	var shardN int64
+	ic, err := shardMapper.MapShards([]influxql.Source{measurement}, influxql.TimeRange{}, query.SelectOptions{ShardN: &shardN})
 	if err != nil {
 		t.Fatalf("unexpected error: %s", err)
+	} else if shardN != 4 {
+		t.Fatalf("unexpected number of shards: %d", shardN)
 	}
 
 	// This should be a LocalShardMapping.
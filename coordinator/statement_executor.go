@@ -3,6 +3,7 @@ package coordinator
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -58,13 +59,48 @@ type StatementExecutor struct {
 	MaxSelectPointN   int
 	MaxSelectSeriesN  int
 	MaxSelectBucketsN int
+
+	// QueryCache, if set, is invalidated when data is deleted.
+	QueryCache *QueryCache
+
+	// WriteQuotas, if set, reports the usage of the write quotas for
+	// SHOW QUOTAS.
+	WriteQuotas interface {
+		Usage(database string) (WriteQuotaUsage, error)
+	}
+
+	// Auditor, if set, records the administrative and data-destructive
+	// statements and their outcome.
+	Auditor interface {
+		AuditStatement(ctx *query.ExecutionContext, stmt influxql.Statement, err error)
+	}
+
+	// Subscriber, if set, commits renames while the subscriptions of the
+	// renamed database or retention policy are closed, and moves their
+	// queues to the new name.
+	Subscriber interface {
+		Rename(database, rp, newName string, commit func() error) error
+	}
 }
 
 // ExecuteStatement executes the given statement with the given execution context.
 func (e *StatementExecutor) ExecuteStatement(ctx *query.ExecutionContext, stmt influxql.Statement) error {
+	se := e
+	if isMetaChange(stmt) {
+		se = e.WithClient(ctx.UserID, ctx.ClientAddr)
+	}
+	err := se.executeStatement(ctx, stmt)
+	if e.Auditor != nil {
+		e.Auditor.AuditStatement(ctx, stmt, err)
+	}
+	return err
+}
+
+func (e *StatementExecutor) executeStatement(ctx *query.ExecutionContext, stmt influxql.Statement) error {
 	// Select statements are handled separately so that they can be streamed.
 	if stmt, ok := stmt.(*influxql.SelectStatement); ok {
-		return e.executeSelectStatement(ctx, stmt)
+		err := e.executeSelectStatement(ctx, stmt)
+		return e.selectLimits(&ctx.ExecutionOptions).quotaError(err)
 	}
 
 	var rows models.Rows
@@ -149,6 +185,7 @@ func (e *StatementExecutor) ExecuteStatement(ctx *query.ExecutionContext, stmt i
 		} else {
 			rows, err = e.executeExplainStatement(ctx, stmt)
 		}
+		err = e.selectLimits(&ctx.ExecutionOptions).quotaError(err)
 	case *influxql.GrantStatement:
 		if ctx.ReadOnly {
 			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
@@ -204,6 +241,32 @@ func (e *StatementExecutor) ExecuteStatement(ctx *query.ExecutionContext, stmt i
 			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
 		}
 		err = e.executeSetPasswordUserStatement(stmt)
+	case *query.CreateTokenStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		rows, err = e.executeCreateTokenStatement(stmt)
+	case *query.DropTokenStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.executeDropTokenStatement(stmt)
+	case *query.ShowTokensStatement:
+		rows, err = e.executeShowTokensStatement(stmt)
+	case *query.AlterDatabaseRenameStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.RenameDatabase(stmt.Name, stmt.NewName)
+	case *query.AlterRetentionPolicyRenameStatement:
+		if ctx.ReadOnly {
+			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
+		}
+		err = e.RenameRetentionPolicy(stmt.Database, stmt.Name, stmt.NewName)
+	case *query.ShowQuotasStatement:
+		rows, err = e.executeShowQuotasStatement(stmt)
+	case *query.ShowMetaHistoryStatement:
+		rows = e.executeShowMetaHistoryStatement()
 	case *influxql.ShowQueriesStatement, *influxql.KillQueryStatement:
 		// Send query related statements to the task manager.
 		return e.TaskManager.ExecuteStatement(ctx, stmt)
@@ -328,6 +391,7 @@ func (e *StatementExecutor) executeDeleteSeriesStatement(stmt *influxql.DeleteSe
 	stmt.Condition = influxql.Reduce(stmt.Condition, &influxql.NowValuer{Now: time.Now().UTC()})
 
 	// Locally delete the series.
+	defer e.invalidateDatabase(database)
 	return e.TSDBStore.DeleteSeries(database, stmt.Sources, stmt.Condition)
 }
 
@@ -344,6 +408,7 @@ func (e *StatementExecutor) executeDropDatabaseStatement(stmt *influxql.DropData
 	}
 
 	// Locally delete the datababse.
+	defer e.invalidateDatabase(stmt.Name)
 	if err := e.TSDBStore.DeleteDatabase(stmt.Name); err != nil {
 		return err
 	}
@@ -358,6 +423,7 @@ func (e *StatementExecutor) executeDropMeasurementStatement(stmt *influxql.DropM
 	}
 
 	// Locally drop the measurement
+	defer e.invalidateDatabase(database)
 	return e.TSDBStore.DeleteMeasurement(database, stmt.Name)
 }
 
@@ -372,11 +438,15 @@ func (e *StatementExecutor) executeDropSeriesStatement(stmt *influxql.DropSeries
 	}
 
 	// Locally drop the series.
+	defer e.invalidateDatabase(database)
 	return e.TSDBStore.DeleteSeries(database, stmt.Sources, stmt.Condition)
 }
 
 func (e *StatementExecutor) executeDropShardStatement(stmt *influxql.DropShardStatement) error {
 	// Locally delete the shard.
+	if e.QueryCache != nil {
+		defer e.QueryCache.InvalidateShard(stmt.ID)
+	}
 	if err := e.TSDBStore.DeleteShard(stmt.ID); err != nil {
 		return err
 	}
@@ -396,6 +466,7 @@ func (e *StatementExecutor) executeDropRetentionPolicyStatement(stmt *influxql.D
 	}
 
 	// Locally drop the retention policy.
+	defer e.invalidateDatabase(stmt.Database)
 	if err := e.TSDBStore.DeleteRetentionPolicy(stmt.Database, stmt.Name); err != nil {
 		return err
 	}
@@ -411,12 +482,73 @@ func (e *StatementExecutor) executeDropUserStatement(q *influxql.DropUserStateme
 	return e.MetaClient.DropUser(q.Name)
 }
 
+// RenameDatabase renames a database in the meta store and moves its local
+// data to the new name.
+func (e *StatementExecutor) RenameDatabase(name, newName string) error {
+	if !meta.ValidName(newName) {
+		return meta.ErrInvalidName
+	} else if e.MetaClient.Database(name) == nil {
+		return influxdb.ErrDatabaseNotFound(name)
+	} else if e.MetaClient.Database(newName) != nil {
+		return meta.ErrDatabaseExists
+	}
+
+	defer e.invalidateDatabase(name)
+	return e.TSDBStore.RenameDatabase(name, newName, e.renameCommit(name, "", newName, func() error {
+		return e.MetaClient.RenameDatabase(name, newName)
+	}))
+}
+
+// RenameRetentionPolicy renames a retention policy in the meta store and
+// moves its local data to the new name.
+func (e *StatementExecutor) RenameRetentionPolicy(database, name, newName string) error {
+	if !meta.ValidName(newName) {
+		return meta.ErrInvalidName
+	}
+	if rpi, err := e.MetaClient.RetentionPolicy(database, name); err != nil {
+		return err
+	} else if rpi == nil {
+		return influxdb.ErrRetentionPolicyNotFound(name)
+	}
+	if rpi, err := e.MetaClient.RetentionPolicy(database, newName); err != nil {
+		return err
+	} else if rpi != nil {
+		return meta.ErrRetentionPolicyNameExists
+	}
+
+	defer e.invalidateDatabase(database)
+	return e.TSDBStore.RenameRetentionPolicy(database, name, newName, e.renameCommit(database, name, newName, func() error {
+		return e.MetaClient.RenameRetentionPolicy(database, name, newName)
+	}))
+}
+
+// renameCommit returns the function committing a rename of a database, or of
+// a retention policy if rp is set, through the subscriber if any.
+func (e *StatementExecutor) renameCommit(database, rp, newName string, commit func() error) func() error {
+	if e.Subscriber == nil {
+		return commit
+	}
+	return func() error {
+		return e.Subscriber.Rename(database, rp, newName, commit)
+	}
+}
+
+// invalidateDatabase drops any cached query results for the database after
+// data has been deleted from it.
+func (e *StatementExecutor) invalidateDatabase(name string) {
+	if e.QueryCache != nil {
+		e.QueryCache.InvalidateDatabase(name)
+	}
+}
+
 func (e *StatementExecutor) executeExplainStatement(ctx *query.ExecutionContext, q *influxql.ExplainStatement) (models.Rows, error) {
+	limits := e.selectLimits(&ctx.ExecutionOptions)
 	opt := query.SelectOptions{
 		NodeID:      ctx.ExecutionOptions.NodeID,
-		MaxSeriesN:  e.MaxSelectSeriesN,
-		MaxBucketsN: e.MaxSelectBucketsN,
+		MaxSeriesN:  limits.MaxSeriesN,
+		MaxBucketsN: limits.MaxBucketsN,
 		Authorizer:  ctx.Authorizer,
+		NoCache:     ctx.NoCache,
 	}
 
 	// Prepare the query for execution, but do not actually execute it.
@@ -451,7 +583,7 @@ func (e *StatementExecutor) executeExplainAnalyzeStatement(ectx *query.Execution
 	ctx = query.NewContextWithIterators(ctx, &aux)
 	start := time.Now()
 
-	cur, err := e.createIterators(ctx, stmt, ectx.ExecutionOptions)
+	cur, err := e.createIterators(ctx, stmt, ectx.ExecutionOptions, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -502,6 +634,14 @@ CLEANUP:
 	row := &models.Row{
 		Columns: []string{"EXPLAIN ANALYZE"},
 	}
+	if ectx.ExplainJSON {
+		b, err := json.Marshal(t.Tree())
+		if err != nil {
+			return nil, err
+		}
+		row.Values = append(row.Values, []interface{}{string(b)})
+		return models.Rows{row}, nil
+	}
 	for _, s := range strings.Split(t.Tree().String(), "\n") {
 		row.Values = append(row.Values, []interface{}{s})
 	}
@@ -542,7 +682,8 @@ func (e *StatementExecutor) executeSetPasswordUserStatement(q *influxql.SetPassw
 }
 
 func (e *StatementExecutor) executeSelectStatement(ctx *query.ExecutionContext, stmt *influxql.SelectStatement) error {
-	cur, err := e.createIterators(ctx, stmt, ctx.ExecutionOptions)
+	var shardN int64
+	cur, err := e.createIterators(ctx, stmt, ctx.ExecutionOptions, &shardN)
 	if err != nil {
 		return err
 	}
@@ -551,6 +692,9 @@ func (e *StatementExecutor) executeSelectStatement(ctx *query.ExecutionContext,
 	em := query.NewEmitter(cur, ctx.ChunkSize)
 	defer em.Close()
 
+	// Record what the statement read for the slow query log.
+	defer func() { ctx.AddSelectStats(cur.Stats(), shardN) }()
+
 	// Emit rows to the results channel.
 	var writeN int64
 	var emitted bool
@@ -628,13 +772,16 @@ func (e *StatementExecutor) executeSelectStatement(ctx *query.ExecutionContext,
 	return nil
 }
 
-func (e *StatementExecutor) createIterators(ctx context.Context, stmt *influxql.SelectStatement, opt query.ExecutionOptions) (query.Cursor, error) {
+func (e *StatementExecutor) createIterators(ctx context.Context, stmt *influxql.SelectStatement, opt query.ExecutionOptions, shardN *int64) (query.Cursor, error) {
+	limits := e.selectLimits(&opt)
 	sopt := query.SelectOptions{
 		NodeID:      opt.NodeID,
-		MaxSeriesN:  e.MaxSelectSeriesN,
-		MaxPointN:   e.MaxSelectPointN,
-		MaxBucketsN: e.MaxSelectBucketsN,
+		MaxSeriesN:  limits.MaxSeriesN,
+		MaxPointN:   limits.MaxPointN,
+		MaxBucketsN: limits.MaxBucketsN,
 		Authorizer:  opt.Authorizer,
+		NoCache:     opt.NoCache,
+		ShardN:      shardN,
 	}
 
 	// Create a set of iterators from a selection.
@@ -645,6 +792,59 @@ func (e *StatementExecutor) createIterators(ctx context.Context, stmt *influxql.
 	return cur, nil
 }
 
+// selectLimits are the limits of a SELECT statement once the query quotas of
+// its user and database have been applied.
+type selectLimits struct {
+	MaxPointN   int
+	MaxSeriesN  int
+	MaxBucketsN int
+
+	// The quotas that set each limit, if any.
+	pointQuota   string
+	seriesQuota  string
+	bucketsQuota string
+}
+
+// selectLimits returns the limits of a SELECT statement run with opt.
+func (e *StatementExecutor) selectLimits(opt *query.ExecutionOptions) selectLimits {
+	var l selectLimits
+	var n int64
+	n, l.pointQuota = opt.QuotaLimit(int64(e.MaxSelectPointN), func(q *query.Quota) int64 { return int64(q.MaxSelectPointN) })
+	l.MaxPointN = int(n)
+	n, l.seriesQuota = opt.QuotaLimit(int64(e.MaxSelectSeriesN), func(q *query.Quota) int64 { return int64(q.MaxSelectSeriesN) })
+	l.MaxSeriesN = int(n)
+	n, l.bucketsQuota = opt.QuotaLimit(int64(e.MaxSelectBucketsN), func(q *query.Quota) int64 { return int64(q.MaxSelectBucketsN) })
+	l.MaxBucketsN = int(n)
+	return l
+}
+
+// quotaError annotates the error of an exceeded limit with the query quota
+// that set it. Other errors are returned unchanged.
+func (l selectLimits) quotaError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var lerr *query.LimitError
+	if !errors.As(err, &lerr) {
+		return err
+	}
+
+	var quota string
+	switch lerr.Limit {
+	case query.LimitMaxSelectPoint:
+		quota = l.pointQuota
+	case query.LimitMaxSelectSeries:
+		quota = l.seriesQuota
+	case query.LimitMaxSelectBuckets:
+		quota = l.bucketsQuota
+	}
+	if quota == "" {
+		return err
+	}
+	return query.ErrQuotaLimitExceeded(err, quota)
+}
+
 func (e *StatementExecutor) executeShowContinuousQueriesStatement(stmt *influxql.ShowContinuousQueriesStatement) (models.Rows, error) {
 	dis := e.MetaClient.Databases()
 
@@ -916,10 +1116,18 @@ func (e *StatementExecutor) executeShowSubscriptionsStatement(stmt *influxql.Sho
 
 	rows := []*models.Row{}
 	for _, di := range dis {
-		row := &models.Row{Columns: []string{"retention_policy", "name", "mode", "destinations"}, Name: di.Name}
+		row := &models.Row{Columns: []string{"retention_policy", "name", "mode", "destinations", "filter"}, Name: di.Name}
 		for _, rpi := range di.RetentionPolicies {
 			for _, si := range rpi.Subscriptions {
-				row.Values = append(row.Values, []interface{}{rpi.Name, si.Name, si.Mode, si.Destinations})
+				var filter string
+				if si.Filter != nil {
+					filter = si.Filter.String()
+				}
+				destinations := make([]string, len(si.Destinations))
+				for i, dest := range si.Destinations {
+					destinations[i] = meta.RedactDestination(dest)
+				}
+				row.Values = append(row.Values, []interface{}{rpi.Name, si.Name, si.Mode, destinations, filter})
 			}
 		}
 		if len(row.Values) > 0 {
@@ -1105,13 +1313,121 @@ func (e *StatementExecutor) executeShowTagValues(ctx *query.ExecutionContext, q
 }
 
 func (e *StatementExecutor) executeShowUsersStatement(q *influxql.ShowUsersStatement) (models.Rows, error) {
-	row := &models.Row{Columns: []string{"user", "admin"}}
+	row := &models.Row{Columns: []string{"user", "admin", "locked"}}
 	for _, ui := range e.MetaClient.Users() {
-		row.Values = append(row.Values, []interface{}{ui.Name, ui.Admin})
+		row.Values = append(row.Values, []interface{}{ui.Name, ui.Admin, e.MetaClient.UserLocked(ui.Name)})
+	}
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) executeCreateTokenStatement(stmt *query.CreateTokenStatement) (models.Rows, error) {
+	var expiresAt time.Time
+	if stmt.Expires > 0 {
+		expiresAt = time.Now().UTC().Add(stmt.Expires)
+	} else if stmt.Expires < 0 {
+		return nil, errors.New("token expiration must be positive")
+	}
+
+	token, ti, err := e.MetaClient.CreateToken(stmt.User, stmt.Scopes, expiresAt, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// The token cannot be retrieved later.
+	row := &models.Row{Columns: []string{"id", "user", "token", "expires_at"}}
+	row.Values = append(row.Values, []interface{}{ti.ID, ti.User, token, tokenExpiresAt(ti)})
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) executeDropTokenStatement(stmt *query.DropTokenStatement) error {
+	return e.MetaClient.DropToken(stmt.ID)
+}
+
+func (e *StatementExecutor) executeShowTokensStatement(stmt *query.ShowTokensStatement) (models.Rows, error) {
+	now := time.Now()
+	row := &models.Row{Columns: []string{"id", "user", "scopes", "description", "created_at", "expires_at", "expired"}}
+	for _, ti := range e.MetaClient.Tokens() {
+		if stmt.User != "" && ti.User != stmt.User {
+			continue
+		}
+		row.Values = append(row.Values, []interface{}{
+			ti.ID,
+			ti.User,
+			strings.Join(ti.Scopes, ","),
+			ti.Description,
+			ti.CreatedAt.Format(time.RFC3339),
+			tokenExpiresAt(&ti),
+			ti.Expired(now),
+		})
 	}
 	return []*models.Row{row}, nil
 }
 
+func (e *StatementExecutor) executeShowQuotasStatement(stmt *query.ShowQuotasStatement) (models.Rows, error) {
+	if stmt.Database != "" && e.MetaClient.Database(stmt.Database) == nil {
+		return nil, influxdb.ErrDatabaseNotFound(stmt.Database)
+	}
+
+	row := &models.Row{Columns: []string{
+		"database",
+		"max_series", "series",
+		"max_disk_bytes", "disk_bytes",
+		"max_points_per_second", "points_burst", "points_per_second",
+	}}
+	for _, di := range e.MetaClient.Databases() {
+		if stmt.Database != "" && di.Name != stmt.Database {
+			continue
+		}
+
+		// Limits and usage are nil if the database has no quota or the
+		// usage isn't tracked.
+		values := make([]interface{}, len(row.Columns))
+		values[0] = di.Name
+		if q := di.WriteQuota; q != nil {
+			values[1], values[3] = quotaLimit(q.MaxSeries), quotaLimit(q.MaxDiskBytes)
+			values[5] = quotaLimit(q.MaxPointsPerSecond)
+			if q.MaxPointsPerSecond > 0 {
+				values[6] = q.Burst()
+			}
+		}
+		if e.WriteQuotas != nil {
+			u, err := e.WriteQuotas.Usage(di.Name)
+			if err != nil {
+				return nil, err
+			}
+			values[2], values[4], values[7] = u.SeriesN, u.DiskBytes, u.PointsPerSecond
+		}
+		row.Values = append(row.Values, values)
+	}
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) executeShowMetaHistoryStatement() models.Rows {
+	row := &models.Row{Columns: []string{"index", "time", "user", "rename"}}
+	for _, h := range e.MetaHistory() {
+		row.Values = append(row.Values, []interface{}{h.Index, h.Time.Format(time.RFC3339Nano), h.User, h.Rename})
+	}
+	return []*models.Row{row}
+}
+
+// quotaLimit returns a quota limit as a column value, or nil if the limit is
+// not set.
+func quotaLimit(n int64) interface{} {
+	if n <= 0 {
+		return nil
+	}
+	return n
+}
+
+// tokenExpiresAt returns the expiration time of a token as a column value,
+// or nil if the token never expires.
+func tokenExpiresAt(ti *meta.TokenInfo) interface{} {
+	if ti.ExpiresAt.IsZero() {
+		return nil
+	}
+	return ti.ExpiresAt.Format(time.RFC3339)
+}
+
 // BufferedPointsWriter adds buffering to a pointsWriter so that SELECT INTO queries
 // write their points to the destination in batches.
 type BufferedPointsWriter struct {
@@ -1374,6 +1690,13 @@ type TSDBStore interface {
 	DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error
 	DeleteShard(id uint64) error
 
+	RenameDatabase(name, newName string, commit func() error) error
+	RenameRetentionPolicy(database, name, newName string, commit func() error) error
+
+	CopyShard(srcID, dstID uint64, min, max int64) error
+	DeleteShardRange(id uint64, min, max int64) error
+	ShardIDs() []uint64
+
 	MeasurementNames(ctx context.Context, auth query.FineAuthorizer, database string, cond influxql.Expr) ([][]byte, error)
 	TagKeys(ctx context.Context, auth query.FineAuthorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error)
 	TagValues(ctx context.Context, auth query.FineAuthorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error)
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/coordinator"
 	"github.com/influxdata/influxdb/internal"
 	"github.com/influxdata/influxdb/logger"
@@ -123,13 +124,56 @@ func TestQueryExecutor_ExecuteQuery_MaxSelectBucketsN(t *testing.T) {
 	if a := ReadAllResults(e.ExecuteQuery(`SELECT count(value) FROM cpu WHERE time >= '2000-01-01T00:00:05Z' AND time < '2000-01-01T00:00:35Z' GROUP BY time(10s)`, "db0", 0)); !reflect.DeepEqual(a, []*query.Result{
 		{
 			StatementID: 0,
-			Err:         errors.New("max-select-buckets limit exceeded: (4/3)"),
+			Err:         query.ErrMaxSelectBucketsLimitExceeded(4, 3),
 		},
 	}) {
 		t.Fatalf("unexpected results: %s", spew.Sdump(a))
 	}
 }
 
+// Ensure a database query quota overrides the maximum bucket selection count.
+func TestQueryExecutor_ExecuteQuery_MaxSelectBucketsN_Quota(t *testing.T) {
+	e := DefaultQueryExecutor()
+	e.StatementExecutor.MaxSelectBucketsN = 10
+	e.TaskManager.Quotas = quotaProviderFunc(func(user, database string) (*query.Quota, *query.Quota) {
+		return nil, &query.Quota{MaxSelectBucketsN: 3}
+	})
+
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(_ context.Context, _ *influxql.Measurement, _ query.IteratorOptions) (query.Iterator, error) {
+			return &FloatIterator{}, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			return map[string]influxql.DataType{"value": influxql.Float}, nil, nil
+		}
+		return &sh
+	}
+
+	if a := ReadAllResults(e.ExecuteQuery(`SELECT count(value) FROM cpu WHERE time >= '2000-01-01T00:00:05Z' AND time < '2000-01-01T00:00:35Z' GROUP BY time(10s)`, "db0", 0)); !reflect.DeepEqual(a, []*query.Result{
+		{
+			StatementID: 0,
+			Err:         errors.New(`max-select-buckets limit exceeded: (4/3): set by query quota for database "db0"`),
+		},
+	}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+}
+
+type quotaProviderFunc func(user, database string) (*query.Quota, *query.Quota)
+
+func (fn quotaProviderFunc) QueryQuotas(user, database string) (*query.Quota, *query.Quota) {
+	return fn(user, database)
+}
+
 func TestStatementExecutor_ExecuteQuery_WriteInto(t *testing.T) {
 	for _, tt := range []struct {
 		name    string
@@ -434,6 +478,530 @@ func TestQueryExecutor_ExecuteQuery_ShowDatabases(t *testing.T) {
 	}
 }
 
+// Ensure the auditor is given each statement executed and its outcome.
+func TestStatementExecutor_Auditor(t *testing.T) {
+	var audited []string
+	qe := query.NewExecutor()
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		MetaClient: &internal.MetaClientMock{
+			DatabaseFn: func(name string) *meta.DatabaseInfo { return nil },
+			DatabasesFn: func() []meta.DatabaseInfo {
+				return nil
+			},
+		},
+		Auditor: auditorFunc(func(ctx *query.ExecutionContext, stmt influxql.Statement, err error) {
+			audited = append(audited, fmt.Sprintf("%s %s %v", ctx.UserID, stmt, err))
+		}),
+	}
+
+	q, err := influxql.ParseQuery("DROP DATABASE db0; SHOW DATABASES")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := query.ExecutionOptions{UserID: "admin", CoarseAuthorizer: query.OpenCoarseAuthorizer}
+	ReadAllResults(qe.ExecuteQuery(q, opt, make(chan struct{})))
+
+	exp := []string{"admin DROP DATABASE db0 <nil>", "admin SHOW DATABASES <nil>"}
+	if !reflect.DeepEqual(audited, exp) {
+		t.Fatalf("unexpected audited statements: %q", audited)
+	}
+}
+
+func TestStatementExecutor_ShowUsers(t *testing.T) {
+	qe := query.NewExecutor()
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		MetaClient: &internal.MetaClientMock{
+			UsersFn: func() []meta.UserInfo {
+				return []meta.UserInfo{{Name: "admin", Admin: true}, {Name: "bob"}}
+			},
+			UserLockedFn: func(name string) bool { return name == "bob" },
+		},
+	}
+
+	q, err := influxql.ParseQuery("SHOW USERS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := query.ExecutionOptions{CoarseAuthorizer: query.OpenCoarseAuthorizer}
+	results := ReadAllResults(qe.ExecuteQuery(q, opt, make(chan struct{})))
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+
+	exp := models.Rows{{
+		Columns: []string{"user", "admin", "locked"},
+		Values:  [][]interface{}{{"admin", true, false}, {"bob", false, true}},
+	}}
+	if !reflect.DeepEqual(results[0].Series, exp) {
+		t.Fatalf("unexpected rows: %s", spew.Sdump(results[0].Series))
+	}
+}
+
+func TestStatementExecutor_Tokens(t *testing.T) {
+	var tokens []meta.TokenInfo
+	qe := query.NewExecutor()
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		MetaClient: &internal.MetaClientMock{
+			CreateTokenFn: func(username string, scopes []string, expiresAt time.Time, description string) (string, *meta.TokenInfo, error) {
+				if exp := []string{"read:db0", "write:db1"}; !reflect.DeepEqual(scopes, exp) {
+					t.Errorf("unexpected scopes: %v", scopes)
+				}
+				if d := time.Until(expiresAt); d < 29*24*time.Hour || d > 30*24*time.Hour {
+					t.Errorf("unexpected expiration: %s", expiresAt)
+				}
+				ti := meta.TokenInfo{ID: "0a1b", User: username, Scopes: scopes, ExpiresAt: time.Date(2000, 1, 31, 0, 0, 0, 0, time.UTC)}
+				tokens = append(tokens, ti)
+				return "influx_0a1b.secret", &ti, nil
+			},
+			TokensFn: func() []meta.TokenInfo { return tokens },
+			DropTokenFn: func(id string) error {
+				if id != "0a1b" {
+					return meta.ErrTokenNotFound
+				}
+				tokens = nil
+				return nil
+			},
+		},
+	}
+
+	execute := func(s string) models.Rows {
+		t.Helper()
+		q, err := influxql.ParseQuery(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opt := query.ExecutionOptions{CoarseAuthorizer: query.OpenCoarseAuthorizer}
+		results := ReadAllResults(qe.ExecuteQuery(q, opt, make(chan struct{})))
+		if len(results) != 1 || results[0].Err != nil {
+			t.Fatalf("unexpected results: %#v", results)
+		}
+		return results[0].Series
+	}
+
+	rows := execute("CREATE TOKEN FOR bob WITH SCOPES read:db0, write:db1 EXPIRES 30d")
+	exp := models.Rows{{
+		Columns: []string{"id", "user", "token", "expires_at"},
+		Values:  [][]interface{}{{"0a1b", "bob", "influx_0a1b.secret", "2000-01-31T00:00:00Z"}},
+	}}
+	if !reflect.DeepEqual(rows, exp) {
+		t.Fatalf("unexpected rows: %s", spew.Sdump(rows))
+	}
+
+	rows = execute("SHOW TOKENS FOR bob")
+	if len(rows) != 1 || len(rows[0].Values) != 1 || rows[0].Values[0][0] != "0a1b" || rows[0].Values[0][2] != "read:db0,write:db1" || rows[0].Values[0][6] != true {
+		t.Fatalf("unexpected rows: %s", spew.Sdump(rows))
+	}
+
+	execute("DROP TOKEN '0a1b'")
+	if rows = execute("SHOW TOKENS"); len(rows) != 1 || len(rows[0].Values) != 0 {
+		t.Fatalf("unexpected rows: %s", spew.Sdump(rows))
+	}
+}
+
+func TestStatementExecutor_ShowSubscriptions_Redacted(t *testing.T) {
+	e := &coordinator.StatementExecutor{
+		MetaClient: &internal.MetaClientMock{
+			DatabasesFn: func() []meta.DatabaseInfo {
+				return []meta.DatabaseInfo{{
+					Name: "db0",
+					RetentionPolicies: []meta.RetentionPolicyInfo{{
+						Name: "rp0",
+						Subscriptions: []meta.SubscriptionInfo{{
+							Name:         "s0",
+							Mode:         "ALL",
+							Destinations: []string{"http://influx_0a1b.secret@host:8086", "udp://host:8089"},
+						}},
+					}},
+				}}
+			},
+		},
+	}
+
+	q, err := influxql.ParseQuery("SHOW SUBSCRIPTIONS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	qe := query.NewExecutor()
+	qe.StatementExecutor = e
+	opt := query.ExecutionOptions{CoarseAuthorizer: query.OpenCoarseAuthorizer}
+	results := ReadAllResults(qe.ExecuteQuery(q, opt, make(chan struct{})))
+	if len(results) != 1 || results[0].Err != nil || len(results[0].Series) != 1 {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+	exp := []string{"http://influx_0a1b.xxxxx@host:8086", "udp://host:8089"}
+	if got := results[0].Series[0].Values[0][3]; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got destinations %v, expected %v", got, exp)
+	}
+}
+
+func TestStatementExecutor_ShowQuotas(t *testing.T) {
+	wq := coordinator.NewWriteQuotas()
+	wq.TSDBStore = &fakeQuotaStore{diskSize: 100, seriesN: 7}
+	e := &coordinator.StatementExecutor{
+		MetaClient: &internal.MetaClientMock{
+			DatabaseFn: func(name string) *meta.DatabaseInfo {
+				if name == "db0" {
+					return &meta.DatabaseInfo{Name: name}
+				}
+				return nil
+			},
+			DatabasesFn: func() []meta.DatabaseInfo {
+				return []meta.DatabaseInfo{
+					{Name: "db0", WriteQuota: &meta.WriteQuota{MaxSeries: 10, MaxPointsPerSecond: 50}},
+					{Name: "db1"},
+				}
+			},
+		},
+		WriteQuotas: wq,
+	}
+
+	stmt, err := influxql.ParseStatement("SHOW QUOTAS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := &query.ExecutionContext{Context: context.Background(), Results: make(chan *query.Result, 1)}
+	if err := e.ExecuteStatement(ctx, stmt); err != nil {
+		t.Fatal(err)
+	}
+	exp := [][]interface{}{
+		{"db0", int64(10), int64(7), nil, int64(100), int64(50), int64(50), int64(0)},
+		{"db1", nil, int64(7), nil, int64(100), nil, nil, int64(0)},
+	}
+	if rows := (<-ctx.Results).Series; len(rows) != 1 || !reflect.DeepEqual(rows[0].Values, exp) {
+		t.Fatalf("unexpected rows: %s", spew.Sdump(rows))
+	}
+
+	stmt, err = influxql.ParseStatement("SHOW QUOTAS ON db2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.ExecuteStatement(ctx, stmt); err == nil || err.Error() != influxdb.ErrDatabaseNotFound("db2").Error() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestStatementExecutor_RenameDatabase(t *testing.T) {
+	var renamed []string
+	e := &coordinator.StatementExecutor{
+		MetaClient: &internal.MetaClientMock{
+			DatabaseFn: func(name string) *meta.DatabaseInfo {
+				if name == "db0" || name == "db1" {
+					return &meta.DatabaseInfo{Name: name}
+				}
+				return nil
+			},
+			RenameDatabaseFn: func(name, newName string) error {
+				renamed = append(renamed, name+" "+newName)
+				return nil
+			},
+		},
+		TSDBStore: &internal.TSDBStoreMock{
+			RenameDatabaseFn: func(name, newName string, commit func() error) error {
+				return commit()
+			},
+		},
+	}
+
+	if err := e.RenameDatabase("db0", "db1"); err != meta.ErrDatabaseExists {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := e.RenameDatabase("db2", "db3"); err == nil || err.Error() != influxdb.ErrDatabaseNotFound("db2").Error() {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := e.RenameDatabase("db0", "a/b"); err != meta.ErrInvalidName {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := e.RenameDatabase("db0", "db2"); err != nil {
+		t.Fatal(err)
+	} else if exp := []string{"db0 db2"}; !reflect.DeepEqual(renamed, exp) {
+		t.Fatalf("unexpected renames: %q", renamed)
+	}
+}
+
+func TestStatementExecutor_AlterRename(t *testing.T) {
+	var renamed []string
+	e := &coordinator.StatementExecutor{
+		MetaClient: &internal.MetaClientMock{
+			DatabaseFn: func(name string) *meta.DatabaseInfo {
+				if name == "db0" {
+					return &meta.DatabaseInfo{Name: name}
+				}
+				return nil
+			},
+			RetentionPolicyFn: func(database, name string) (*meta.RetentionPolicyInfo, error) {
+				if database == "db0" && name == "rp0" {
+					return &meta.RetentionPolicyInfo{Name: name}, nil
+				}
+				return nil, nil
+			},
+			RenameDatabaseFn: func(name, newName string) error {
+				renamed = append(renamed, name+" "+newName)
+				return nil
+			},
+			RenameRetentionPolicyFn: func(database, name, newName string) error {
+				renamed = append(renamed, database+"."+name+" "+database+"."+newName)
+				return nil
+			},
+		},
+		TSDBStore: &internal.TSDBStoreMock{
+			RenameDatabaseFn: func(name, newName string, commit func() error) error {
+				return commit()
+			},
+			RenameRetentionPolicyFn: func(database, name, newName string, commit func() error) error {
+				return commit()
+			},
+		},
+	}
+
+	for _, s := range []string{
+		`ALTER RETENTION POLICY rp0 ON db0 RENAME TO rp1`,
+		`ALTER DATABASE db0 RENAME TO db1`,
+	} {
+		stmt, err := influxql.ParseStatement(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := &query.ExecutionContext{Context: context.Background(), Results: make(chan *query.Result, 1)}
+		if err := e.ExecuteStatement(ctx, stmt); err != nil {
+			t.Fatalf("%s: %s", s, err)
+		}
+	}
+	if exp := []string{"db0.rp0 db0.rp1", "db0 db1"}; !reflect.DeepEqual(renamed, exp) {
+		t.Fatalf("unexpected renames: %q", renamed)
+	}
+}
+
+func TestStatementExecutor_CopyRetentionPolicy(t *testing.T) {
+	base := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	hour := func(n int) time.Time { return base.Add(time.Duration(n) * time.Hour) }
+
+	var calls []string
+	e := &coordinator.StatementExecutor{
+		MetaClient: &internal.MetaClientMock{
+			DatabaseFn: func(name string) *meta.DatabaseInfo {
+				if name != "db0" {
+					return nil
+				}
+				return &meta.DatabaseInfo{
+					Name: name,
+					RetentionPolicies: []meta.RetentionPolicyInfo{
+						{Name: "rp0", ShardGroupDuration: 24 * time.Hour},
+						{Name: "rp1", ShardGroupDuration: 12 * time.Hour},
+					},
+				}
+			},
+			ShardGroupsByTimeRangeFn: func(database, policy string, min, max time.Time) ([]meta.ShardGroupInfo, error) {
+				if policy != "rp0" || !min.Equal(hour(12)) || !max.Equal(hour(48).Add(-1)) {
+					t.Fatalf("unexpected shard groups query: %s %s %s", policy, min, max)
+				}
+				return []meta.ShardGroupInfo{
+					{ID: 1, StartTime: hour(0), EndTime: hour(24), Shards: []meta.ShardInfo{{ID: 1}}},
+					{ID: 2, StartTime: hour(24), EndTime: hour(48), Shards: []meta.ShardInfo{{ID: 2}}},
+				}, nil
+			},
+			CreateShardGroupFn: func(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error) {
+				start := timestamp.Truncate(12 * time.Hour)
+				id := 100 + uint64(start.Sub(base)/(12*time.Hour))
+				return &meta.ShardGroupInfo{ID: id, StartTime: start, EndTime: start.Add(12 * time.Hour), Shards: []meta.ShardInfo{{ID: id}}}, nil
+			},
+			DropShardFn: func(id uint64) error {
+				calls = append(calls, fmt.Sprintf("drop %d", id))
+				return nil
+			},
+		},
+		TSDBStore: &internal.TSDBStoreMock{
+			CreateShardFn: func(database, policy string, shardID uint64, enabled bool) error {
+				return nil
+			},
+			CopyShardFn: func(srcID, dstID uint64, min, max int64) error {
+				calls = append(calls, fmt.Sprintf("copy %d %d %d %d", srcID, dstID, min, max))
+				return nil
+			},
+			DeleteShardFn: func(id uint64) error {
+				calls = append(calls, fmt.Sprintf("delete %d", id))
+				return nil
+			},
+			DeleteShardRangeFn: func(id uint64, min, max int64) error {
+				calls = append(calls, fmt.Sprintf("delete %d %d %d", id, min, max))
+				return nil
+			},
+		},
+	}
+
+	if err := e.CopyRetentionPolicy("db0", "rp0", "rp0", hour(12), hour(48), false); err == nil {
+		t.Fatal("expected error copying into the source retention policy")
+	} else if err := e.CopyRetentionPolicy("db0", "rp0", "rp2", hour(12), hour(48), false); err == nil || err.Error() != influxdb.ErrRetentionPolicyNotFound("rp2").Error() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := e.CopyRetentionPolicy("db0", "rp0", "rp1", hour(12), hour(48), true); err != nil {
+		t.Fatal(err)
+	}
+	ns := func(n int) int64 { return hour(n).UnixNano() }
+	if exp := []string{
+		fmt.Sprintf("copy 1 101 %d %d", ns(12), ns(24)-1),
+		fmt.Sprintf("delete 1 %d %d", ns(12), ns(24)-1),
+		fmt.Sprintf("copy 2 102 %d %d", ns(24), ns(36)-1),
+		fmt.Sprintf("copy 2 103 %d %d", ns(36), ns(48)-1),
+		"drop 2",
+		"delete 2",
+	}; !reflect.DeepEqual(calls, exp) {
+		t.Fatalf("unexpected calls:\ngot  %q\nexp  %q", calls, exp)
+	}
+}
+
+// Ensure points written while the data of a retention policy is moved are
+// kept.
+func TestStatementExecutor_CopyRetentionPolicy_MoveWrites(t *testing.T) {
+	now := time.Now().Truncate(time.Hour)
+	sgs := []meta.ShardGroupInfo{
+		{ID: 1, StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Hour), Shards: []meta.ShardInfo{{ID: 1}}},
+		{ID: 2, StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour), Shards: []meta.ShardInfo{{ID: 2}}},
+	}
+
+	// The times of the points of each shard.
+	shards := map[uint64]map[int64]bool{1: {}, 2: {}, 100: {}}
+	write := func(id uint64, t time.Time) { shards[id][t.UnixNano()] = true }
+	write(1, now.Add(-90*time.Minute))
+	write(2, now.Add(-30*time.Minute))
+
+	e := &coordinator.StatementExecutor{
+		MetaClient: &internal.MetaClientMock{
+			DatabaseFn: func(name string) *meta.DatabaseInfo {
+				return &meta.DatabaseInfo{
+					Name: name,
+					RetentionPolicies: []meta.RetentionPolicyInfo{
+						{Name: "rp0", ShardGroupDuration: time.Hour},
+						{Name: "rp1", ShardGroupDuration: 24 * time.Hour},
+					},
+				}
+			},
+			ShardGroupsByTimeRangeFn: func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+				for _, sg := range sgs {
+					if sg.Overlaps(min, max) {
+						a = append(a, sg)
+					}
+				}
+				return a, nil
+			},
+			CreateShardGroupFn: func(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error) {
+				return &meta.ShardGroupInfo{ID: 100, StartTime: now.Add(-24 * time.Hour), EndTime: now.Add(24 * time.Hour), Shards: []meta.ShardInfo{{ID: 100}}}, nil
+			},
+			DropShardFn: func(id uint64) error { return nil },
+		},
+		TSDBStore: &internal.TSDBStoreMock{
+			CreateShardFn: func(database, policy string, shardID uint64, enabled bool) error { return nil },
+			CopyShardFn: func(srcID, dstID uint64, min, max int64) error {
+				for ts := range shards[srcID] {
+					if ts >= min && ts <= max {
+						shards[dstID][ts] = true
+					}
+				}
+				// A point is written to the current shard group once the
+				// shard is copied.
+				write(2, time.Now())
+				return nil
+			},
+			DeleteShardFn: func(id uint64) error {
+				shards[id] = map[int64]bool{}
+				return nil
+			},
+			DeleteShardRangeFn: func(id uint64, min, max int64) error {
+				for ts := range shards[id] {
+					if ts >= min && ts <= max {
+						delete(shards[id], ts)
+					}
+				}
+				return nil
+			},
+		},
+	}
+
+	// The data of the shard group being written to is not moved.
+	if err := e.CopyRetentionPolicy("db0", "rp0", "rp1", now.Add(-2*time.Hour), now.Add(time.Hour), true); err != coordinator.ErrShardGroupNotEnded {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(shards[1]) != 1 || len(shards[2]) != 1 || len(shards[100]) != 0 {
+		t.Fatalf("unexpected shards: %v", shards)
+	}
+
+	// The data of the shard groups that have ended is.
+	if err := e.CopyRetentionPolicy("db0", "rp0", "rp1", now.Add(-2*time.Hour), now.Add(-time.Hour), true); err != nil {
+		t.Fatal(err)
+	} else if len(shards[1]) != 0 || len(shards[2]) != 2 || len(shards[100]) != 1 {
+		t.Fatalf("unexpected shards: %v", shards)
+	}
+}
+
+func TestStatementExecutor_RollbackMeta(t *testing.T) {
+	cfg := meta.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.HistorySize = 10
+	mc := meta.NewClient(cfg)
+	if err := mc.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer mc.Close()
+
+	var ids []uint64
+	e := &coordinator.StatementExecutor{
+		MetaClient: mc,
+		TSDBStore: &internal.TSDBStoreMock{
+			ShardIDsFn: func() []uint64 { return ids },
+		},
+	}
+	index := mc.Data().Index
+
+	// Statements changing the meta data are attributed to their user.
+	ctx := &query.ExecutionContext{
+		Context:          context.Background(),
+		Results:          make(chan *query.Result, 1),
+		ExecutionOptions: query.ExecutionOptions{UserID: "bob"},
+	}
+	if err := e.ExecuteStatement(ctx, influxql.MustParseStatement(`CREATE DATABASE db0`)); err != nil {
+		t.Fatal(err)
+	}
+	<-ctx.Results
+	if history := mc.History(); history[len(history)-1].User != "bob" {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	sg, err := mc.CreateShardGroup("db0", "autogen", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids = []uint64{sg.Shards[0].ID}
+
+	if res, err := e.RollbackMeta(index, "admin", ""); err != nil {
+		t.Fatal(err)
+	} else if res.Index != mc.Data().Index {
+		t.Fatalf("unexpected index: %d", res.Index)
+	} else if len(res.Unrecoverable) != 1 || res.Unrecoverable[0].ShardID != ids[0] {
+		t.Fatalf("unexpected unrecoverable shards: %+v", res.Unrecoverable)
+	} else if history := mc.History(); history[len(history)-1].User != "admin" {
+		t.Fatalf("unexpected history: %+v", history)
+	} else if mc.Database("db0") != nil {
+		t.Fatal("database not dropped")
+	}
+
+	if _, err := e.RollbackMeta(index+100, "admin", ""); err != meta.ErrVersionNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := e.ExecuteStatement(ctx, influxql.MustParseStatement(`SHOW META HISTORY`)); err != nil {
+		t.Fatal(err)
+	}
+	rows := (<-ctx.Results).Series
+	if len(rows) != 1 || len(rows[0].Values) != len(mc.History()) {
+		t.Fatalf("unexpected rows: %s", spew.Sdump(rows))
+	} else if last := rows[0].Values[len(rows[0].Values)-1]; last[0] != mc.Data().Index || last[2] != "admin" {
+		t.Fatalf("unexpected row: %v", last)
+	}
+}
+
+type auditorFunc func(ctx *query.ExecutionContext, stmt influxql.Statement, err error)
+
+func (fn auditorFunc) AuditStatement(ctx *query.ExecutionContext, stmt influxql.Statement, err error) {
+	fn(ctx, stmt, err)
+}
+
 // QueryExecutor is a test wrapper for coordinator.QueryExecutor.
 type QueryExecutor struct {
 	*query.Executor
@@ -0,0 +1,218 @@
+package coordinator
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/meta"
+	"golang.org/x/time/rate"
+)
+
+// The keys for statistics generated by the "writeQuota" module.
+const (
+	statWriteQuotaDiskRejected = "diskRejected"
+	statWriteQuotaRateRejected = "rateRejected"
+)
+
+// diskSizeInterval is the interval at which the disk size of a database is
+// read again while it is written to.
+const diskSizeInterval = time.Second
+
+// QuotaExceededError is returned when a write is rejected because it exceeds
+// a write quota of its database.
+type QuotaExceededError struct {
+	Database string
+	Quota    string
+	Limit    int64
+}
+
+func (e QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s quota exceeded for database %q: (%d)", e.Quota, e.Database, e.Limit)
+}
+
+// WriteQuotas enforces the disk usage and write rate quotas of databases. The
+// series quotas are enforced by the shards.
+type WriteQuotas struct {
+	TSDBStore interface {
+		DatabaseDiskSize(name string) (int64, error)
+		DatabaseSeriesN(name string) int64
+	}
+
+	mu        sync.Mutex
+	databases map[string]*databaseWrites
+
+	stats *WriteQuotaStatistics
+}
+
+// databaseWrites tracks the writes to a database.
+type databaseWrites struct {
+	// The rate limiter of the quota, if it limits the write rate.
+	quota   meta.WriteQuota
+	limiter *rate.Limiter
+
+	// The last read disk size, and whether it is being read again.
+	diskSize    int64
+	diskRead    time.Time
+	diskReading bool
+
+	// The points written in the current and the previous second.
+	second   int64
+	points   int64
+	previous int64
+}
+
+// WriteQuotaUsage is the usage of the write quota of a database.
+type WriteQuotaUsage struct {
+	SeriesN         int64
+	DiskBytes       int64
+	PointsPerSecond int64
+}
+
+// NewWriteQuotas returns a new instance of WriteQuotas.
+func NewWriteQuotas() *WriteQuotas {
+	return &WriteQuotas{
+		databases: make(map[string]*databaseWrites),
+		stats:     &WriteQuotaStatistics{},
+	}
+}
+
+// Check returns a QuotaExceededError if writing n points to the database
+// exceeds its quota. Otherwise the points are counted against the quota. A
+// batch larger than the burst of the quota can never be written, so it is
+// rejected with the "points-burst" quota.
+func (q *WriteQuotas) Check(database string, quota *meta.WriteQuota, n int) error {
+	now := time.Now()
+
+	if quota != nil && quota.MaxDiskBytes > 0 {
+		size, err := q.diskSize(database, now)
+		if err != nil {
+			return err
+		} else if size >= quota.MaxDiskBytes {
+			atomic.AddInt64(&q.stats.DiskRejected, 1)
+			return QuotaExceededError{Database: database, Quota: "max-disk-bytes", Limit: quota.MaxDiskBytes}
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	dw := q.writes(database)
+
+	if quota == nil || quota.MaxPointsPerSecond == 0 {
+		dw.limiter = nil
+	} else {
+		if dw.limiter == nil || dw.quota.MaxPointsPerSecond != quota.MaxPointsPerSecond || dw.quota.Burst() != quota.Burst() {
+			dw.limiter = rate.NewLimiter(rate.Limit(quota.MaxPointsPerSecond), int(quota.Burst()))
+			dw.quota = *quota
+		}
+		if burst := dw.limiter.Burst(); n > burst {
+			atomic.AddInt64(&q.stats.RateRejected, 1)
+			return QuotaExceededError{Database: database, Quota: "points-burst", Limit: int64(burst)}
+		} else if !dw.limiter.AllowN(now, n) {
+			atomic.AddInt64(&q.stats.RateRejected, 1)
+			return QuotaExceededError{Database: database, Quota: "max-points-per-second", Limit: quota.MaxPointsPerSecond}
+		}
+	}
+
+	dw.count(now, int64(n))
+	return nil
+}
+
+// writes returns the writes to the database. q.mu must be locked.
+func (q *WriteQuotas) writes(database string) *databaseWrites {
+	dw := q.databases[database]
+	if dw == nil {
+		dw = &databaseWrites{}
+		q.databases[database] = dw
+	}
+	return dw
+}
+
+// diskSize returns the disk size of the database, read again if it was last
+// read more than diskSizeInterval ago. The size is read without holding q.mu,
+// so that a slow read does not block the writes to every database, and the
+// last read size is returned while another write reads it again.
+func (q *WriteQuotas) diskSize(database string, now time.Time) (int64, error) {
+	q.mu.Lock()
+	dw := q.writes(database)
+	size := dw.diskSize
+	read := now.Sub(dw.diskRead) >= diskSizeInterval && !dw.diskReading
+	dw.diskReading = dw.diskReading || read
+	q.mu.Unlock()
+	if !read {
+		return size, nil
+	}
+
+	size, err := q.TSDBStore.DatabaseDiskSize(database)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	dw.diskReading = false
+	if err != nil {
+		return 0, err
+	}
+	dw.diskSize, dw.diskRead = size, now
+	return size, nil
+}
+
+// count adds n points written at now.
+func (dw *databaseWrites) count(now time.Time, n int64) {
+	dw.rotate(now)
+	dw.points += n
+}
+
+// rotate starts counting the points of the second of now.
+func (dw *databaseWrites) rotate(now time.Time) {
+	second := now.Unix()
+	switch second - dw.second {
+	case 0:
+		return
+	case 1:
+		dw.previous = dw.points
+	default:
+		dw.previous = 0
+	}
+	dw.second, dw.points = second, 0
+}
+
+// Usage returns the usage of the write quota of a database. The write rate
+// is the number of points written in the previous second.
+func (q *WriteQuotas) Usage(database string) (WriteQuotaUsage, error) {
+	size, err := q.TSDBStore.DatabaseDiskSize(database)
+	if err != nil {
+		return WriteQuotaUsage{}, err
+	}
+	u := WriteQuotaUsage{
+		SeriesN:   q.TSDBStore.DatabaseSeriesN(database),
+		DiskBytes: size,
+	}
+
+	q.mu.Lock()
+	if dw := q.databases[database]; dw != nil {
+		dw.rotate(time.Now())
+		u.PointsPerSecond = dw.previous
+	}
+	q.mu.Unlock()
+	return u, nil
+}
+
+// WriteQuotaStatistics keeps statistics related to the write quotas.
+type WriteQuotaStatistics struct {
+	DiskRejected int64
+	RateRejected int64
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (q *WriteQuotas) Statistics(tags map[string]string) []models.Statistic {
+	return []models.Statistic{{
+		Name: "writeQuota",
+		Tags: tags,
+		Values: map[string]interface{}{
+			statWriteQuotaDiskRejected: atomic.LoadInt64(&q.stats.DiskRejected),
+			statWriteQuotaRateRejected: atomic.LoadInt64(&q.stats.RateRejected),
+		},
+	}}
+}
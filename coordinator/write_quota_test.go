@@ -0,0 +1,97 @@
+package coordinator_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+func TestWriteQuotas_Check(t *testing.T) {
+	store := &fakeQuotaStore{diskSize: 100, seriesN: 7}
+	q := coordinator.NewWriteQuotas()
+	q.TSDBStore = store
+
+	// Writes are not limited without a quota.
+	if err := q.Check("db0", nil, 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Check("db0", &meta.WriteQuota{MaxDiskBytes: 100}, 1); err == nil {
+		t.Fatal("expected disk quota error")
+	} else if err, ok := err.(coordinator.QuotaExceededError); !ok || err.Quota != "max-disk-bytes" || err.Limit != 100 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := q.Check("db1", &meta.WriteQuota{MaxDiskBytes: 101}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	quota := &meta.WriteQuota{MaxPointsPerSecond: 1, PointsBurst: 10}
+	if err := q.Check("db2", quota, 6); err != nil {
+		t.Fatal(err)
+	} else if err := q.Check("db2", quota, 6); err == nil {
+		t.Fatal("expected rate quota error")
+	} else if err, ok := err.(coordinator.QuotaExceededError); !ok || err.Quota != "max-points-per-second" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A batch larger than the burst is rejected without using the burst.
+	if err := q.Check("db3", quota, 11); err == nil {
+		t.Fatal("expected burst quota error")
+	} else if err, ok := err.(coordinator.QuotaExceededError); !ok || err.Quota != "points-burst" || err.Limit != 10 {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := q.Check("db3", quota, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing the quota resets the limiter.
+	if err := q.Check("db2", &meta.WriteQuota{MaxPointsPerSecond: 100}, 50); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := q.Usage("db2")
+	if err != nil {
+		t.Fatal(err)
+	} else if u.SeriesN != 7 || u.DiskBytes != 100 {
+		t.Fatalf("unexpected usage: %+v", u)
+	}
+}
+
+// Ensure that reading the disk size of a database does not block the writes
+// to other databases.
+func TestWriteQuotas_Check_SlowDiskSize(t *testing.T) {
+	store := &fakeQuotaStore{diskSize: 100, block: make(chan struct{})}
+	q := coordinator.NewWriteQuotas()
+	q.TSDBStore = store
+
+	done := make(chan error)
+	go func() { done <- q.Check("slow", &meta.WriteQuota{MaxDiskBytes: 1000}, 1) }()
+	<-store.block
+
+	if err := q.Check("db0", &meta.WriteQuota{MaxPointsPerSecond: 10}, 1); err != nil {
+		t.Fatal(err)
+	}
+	store.block <- struct{}{}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+type fakeQuotaStore struct {
+	diskSize int64
+	seriesN  int64
+
+	// block, if set, is sent to and then received from while reading the
+	// disk size of the database "slow".
+	block chan struct{}
+}
+
+func (s *fakeQuotaStore) DatabaseDiskSize(name string) (int64, error) {
+	if name == "slow" && s.block != nil {
+		s.block <- struct{}{}
+		<-s.block
+	}
+	return s.diskSize, nil
+}
+
+func (s *fakeQuotaStore) DatabaseSeriesN(name string) int64 { return s.seriesN }
@@ -3,6 +3,7 @@ package internal
 import (
 	"time"
 
+	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxql"
 )
@@ -30,18 +31,33 @@ type MetaClientMock struct {
 	DropShardFn           func(id uint64) error
 	DropUserFn            func(name string) error
 
+	HistoryFn func() []meta.HistoryEntry
+
 	OpenFn func() error
 
 	PrecreateShardGroupsFn func(from, to time.Time) error
 	PruneShardGroupsFn     func() error
 
-	RetentionPolicyFn func(database, name string) (rpi *meta.RetentionPolicyInfo, err error)
+	RenameDatabaseFn        func(name, newName string) error
+	RenameRetentionPolicyFn func(database, name, newName string) error
+	RetentionPolicyFn       func(database, name string) (rpi *meta.RetentionPolicyInfo, err error)
+	RollbackFn              func(index uint64, exists func(id uint64) bool) (*meta.RollbackResult, error)
 
 	AuthenticateFn           func(username, password string) (ui meta.User, err error)
 	AdminUserExistsFn        func() bool
 	SetAdminPrivilegeFn      func(username string, admin bool) error
 	SetDataFn                func(*meta.Data) error
 	SetPrivilegeFn           func(username, database string, p influxql.Privilege) error
+	SetUserQueryQuotaFn      func(username string, q *query.Quota) error
+	SetDatabaseQueryQuotaFn  func(name string, q *query.Quota) error
+	SetSubscriptionFilterFn  func(database, rp, name string, filter *meta.SubscriptionFilter) error
+	SetDatabaseSchemaFn      func(name string, schema *meta.DatabaseSchema) error
+	SetDatabaseWriteQuotaFn  func(name string, q *meta.WriteQuota) error
+	SetUserReadGrantsFn      func(username, database string, grants []meta.ReadGrant) error
+	AuthenticateTokenFn      func(token string) (meta.User, error)
+	TokensFn                 func() []meta.TokenInfo
+	CreateTokenFn            func(username string, scopes []string, expiresAt time.Time, description string) (string, *meta.TokenInfo, error)
+	DropTokenFn              func(id string) error
 	ShardGroupsByTimeRangeFn func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error)
 	ShardOwnerFn             func(shardID uint64) (database, policy string, sgi *meta.ShardGroupInfo)
 	TruncateShardGroupsFn    func(t time.Time) error
@@ -51,6 +67,8 @@ type MetaClientMock struct {
 	UserPrivilegesFn         func(username string) (map[string]influxql.Privilege, error)
 	UserFn                   func(username string) (meta.User, error)
 	UsersFn                  func() []meta.UserInfo
+	UserLockedFn             func(name string) bool
+	UnlockUserFn             func(name string) error
 }
 
 func (c *MetaClientMock) Close() error {
@@ -121,10 +139,26 @@ func (c *MetaClientMock) DropUser(name string) error {
 	return c.DropUserFn(name)
 }
 
+func (c *MetaClientMock) History() []meta.HistoryEntry {
+	return c.HistoryFn()
+}
+
+func (c *MetaClientMock) RenameDatabase(name, newName string) error {
+	return c.RenameDatabaseFn(name, newName)
+}
+
+func (c *MetaClientMock) RenameRetentionPolicy(database, name, newName string) error {
+	return c.RenameRetentionPolicyFn(database, name, newName)
+}
+
 func (c *MetaClientMock) RetentionPolicy(database, name string) (rpi *meta.RetentionPolicyInfo, err error) {
 	return c.RetentionPolicyFn(database, name)
 }
 
+func (c *MetaClientMock) Rollback(index uint64, exists func(id uint64) bool) (*meta.RollbackResult, error) {
+	return c.RollbackFn(index, exists)
+}
+
 func (c *MetaClientMock) SetAdminPrivilege(username string, admin bool) error {
 	return c.SetAdminPrivilegeFn(username, admin)
 }
@@ -133,6 +167,46 @@ func (c *MetaClientMock) SetPrivilege(username, database string, p influxql.Priv
 	return c.SetPrivilegeFn(username, database, p)
 }
 
+func (c *MetaClientMock) SetUserQueryQuota(username string, q *query.Quota) error {
+	return c.SetUserQueryQuotaFn(username, q)
+}
+
+func (c *MetaClientMock) SetDatabaseQueryQuota(name string, q *query.Quota) error {
+	return c.SetDatabaseQueryQuotaFn(name, q)
+}
+
+func (c *MetaClientMock) SetSubscriptionFilter(database, rp, name string, filter *meta.SubscriptionFilter) error {
+	return c.SetSubscriptionFilterFn(database, rp, name, filter)
+}
+
+func (c *MetaClientMock) SetDatabaseSchema(name string, schema *meta.DatabaseSchema) error {
+	return c.SetDatabaseSchemaFn(name, schema)
+}
+
+func (c *MetaClientMock) SetDatabaseWriteQuota(name string, q *meta.WriteQuota) error {
+	return c.SetDatabaseWriteQuotaFn(name, q)
+}
+
+func (c *MetaClientMock) SetUserReadGrants(username, database string, grants []meta.ReadGrant) error {
+	return c.SetUserReadGrantsFn(username, database, grants)
+}
+
+func (c *MetaClientMock) AuthenticateToken(token string) (meta.User, error) {
+	return c.AuthenticateTokenFn(token)
+}
+
+func (c *MetaClientMock) Tokens() []meta.TokenInfo {
+	return c.TokensFn()
+}
+
+func (c *MetaClientMock) CreateToken(username string, scopes []string, expiresAt time.Time, description string) (string, *meta.TokenInfo, error) {
+	return c.CreateTokenFn(username, scopes, expiresAt, description)
+}
+
+func (c *MetaClientMock) DropToken(id string) error {
+	return c.DropTokenFn(id)
+}
+
 func (c *MetaClientMock) ShardGroupsByTimeRange(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
 	return c.ShardGroupsByTimeRangeFn(database, policy, min, max)
 }
@@ -168,6 +242,8 @@ func (c *MetaClientMock) AdminUserExists() bool { return c.AdminUserExistsFn() }
 
 func (c *MetaClientMock) User(username string) (meta.User, error) { return c.UserFn(username) }
 func (c *MetaClientMock) Users() []meta.UserInfo                  { return c.UsersFn() }
+func (c *MetaClientMock) UserLocked(name string) bool             { return c.UserLockedFn(name) }
+func (c *MetaClientMock) UnlockUser(name string) error            { return c.UnlockUserFn(name) }
 
 func (c *MetaClientMock) Open() error                { return c.OpenFn() }
 func (c *MetaClientMock) Data() meta.Data            { return c.DataFn() }
@@ -18,6 +18,7 @@ type TSDBStoreMock struct {
 	BackupSeriesFileFn        func(database string, w io.Writer) error
 	ExportShardFn             func(id uint64, ExportStart time.Time, ExportEnd time.Time, w io.Writer) error
 	CloseFn                   func() error
+	CopyShardFn               func(srcID, dstID uint64, min, max int64) error
 	CreateShardFn             func(database, policy string, shardID uint64, enabled bool) error
 	CreateShardSnapshotFn     func(id uint64) (string, error)
 	DatabasesFn               func() []string
@@ -26,6 +27,7 @@ type TSDBStoreMock struct {
 	DeleteRetentionPolicyFn   func(database, name string) error
 	DeleteSeriesFn            func(database string, sources []influxql.Source, condition influxql.Expr) error
 	DeleteShardFn             func(id uint64) error
+	DeleteShardRangeFn        func(id uint64, min, max int64) error
 	DiskSizeFn                func() (int64, error)
 	ExpandSourcesFn           func(sources influxql.Sources) (influxql.Sources, error)
 	ImportShardFn             func(id uint64, r io.Reader) error
@@ -34,6 +36,8 @@ type TSDBStoreMock struct {
 	MeasurementNamesFn        func(auth query.FineAuthorizer, database string, cond influxql.Expr) ([][]byte, error)
 	OpenFn                    func() error
 	PathFn                    func() string
+	RenameDatabaseFn          func(name, newName string, commit func() error) error
+	RenameRetentionPolicyFn   func(database, name, newName string, commit func() error) error
 	RestoreShardFn            func(id uint64, r io.Reader) error
 	SeriesCardinalityFn       func(database string) (int64, error)
 	SetShardEnabledFn         func(shardID uint64, enabled bool) error
@@ -60,6 +64,9 @@ func (s *TSDBStoreMock) ExportShard(id uint64, ExportStart time.Time, ExportEnd
 	return s.ExportShardFn(id, ExportStart, ExportEnd, w)
 }
 func (s *TSDBStoreMock) Close() error { return s.CloseFn() }
+func (s *TSDBStoreMock) CopyShard(srcID, dstID uint64, min, max int64) error {
+	return s.CopyShardFn(srcID, dstID, min, max)
+}
 func (s *TSDBStoreMock) CreateShard(database string, retentionPolicy string, shardID uint64, enabled bool) error {
 	return s.CreateShardFn(database, retentionPolicy, shardID, enabled)
 }
@@ -84,6 +91,9 @@ func (s *TSDBStoreMock) DeleteSeries(database string, sources []influxql.Source,
 func (s *TSDBStoreMock) DeleteShard(shardID uint64) error {
 	return s.DeleteShardFn(shardID)
 }
+func (s *TSDBStoreMock) DeleteShardRange(id uint64, min, max int64) error {
+	return s.DeleteShardRangeFn(id, min, max)
+}
 func (s *TSDBStoreMock) DiskSize() (int64, error) {
 	return s.DiskSizeFn()
 }
@@ -108,6 +118,12 @@ func (s *TSDBStoreMock) Open() error {
 func (s *TSDBStoreMock) Path() string {
 	return s.PathFn()
 }
+func (s *TSDBStoreMock) RenameDatabase(name, newName string, commit func() error) error {
+	return s.RenameDatabaseFn(name, newName, commit)
+}
+func (s *TSDBStoreMock) RenameRetentionPolicy(database, name, newName string, commit func() error) error {
+	return s.RenameRetentionPolicyFn(database, name, newName, commit)
+}
 func (s *TSDBStoreMock) RestoreShard(id uint64, r io.Reader) error {
 	return s.RestoreShardFn(id, r)
 }
@@ -0,0 +1,236 @@
+package encryption
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// AppendFile reads and appends to the plaintext of a ModeAppend file. The
+// file is the header followed by records, each prefixed by its size and
+// holding the next part of the plaintext, encrypted with a random nonce and
+// with its offset in the plaintext as additional data. Records are decrypted
+// when read and kept in a ChunkCache.
+//
+// Appended data is buffered until Flush. The data of a single Write is never
+// split across records, so it can be read back with a single call to Slice.
+type AppendFile struct {
+	mu      sync.RWMutex
+	r       io.ReaderAt
+	w       io.Writer
+	key     *FileKey
+	cache   *ChunkCache
+	file    uint64
+	records []appendRecord
+	size    int64  // size of the plaintext of the records
+	end     int64  // offset of the end of the records in the file
+	buf     []byte // plaintext appended after the records
+	sealed  []byte
+}
+
+// appendRecord is the location of a record of an AppendFile.
+type appendRecord struct {
+	pos    int64 // offset of its plaintext in the plaintext of the file
+	offset int64 // offset of the record in the file, after its size
+	n      int64 // size of the record, excluding its size
+}
+
+// WriteAppendFile writes a new AppendFile holding data to w, encrypted with a
+// new data key.
+func WriteAppendFile(w io.Writer, kp KeyProvider, data []byte) error {
+	key, err := NewFileKey(kp, ModeAppend)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(appendRecordTo(append([]byte(nil), key.Header()...), key, 0, data))
+	return err
+}
+
+// OpenAppendFile returns a reader of the AppendFile of the given size read from
+// r, caching the decrypted records in cache. A partially written final record
+// is ignored, and is overwritten if the file is appended to. It returns
+// ErrNotEncrypted if the file does not start with Magic.
+func OpenAppendFile(r io.ReaderAt, size int64, kp KeyProvider, cache *ChunkCache) (*AppendFile, error) {
+	key, err := ReadFileKey(io.NewSectionReader(r, 0, size), kp, ModeAppend)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, ErrCorrupt
+	} else if err != nil {
+		return nil, err
+	}
+
+	f := &AppendFile{
+		r:     r,
+		key:   key,
+		cache: cache,
+		file:  atomic.AddUint64(&cache.nextFile, 1),
+		end:   int64(len(key.Header())),
+	}
+
+	// Only the sizes of the records are read, so the records are located
+	// without being decrypted.
+	overhead := int64(key.Overhead())
+	var hdr [4]byte
+	for f.end+4 <= size {
+		if _, err := r.ReadAt(hdr[:], f.end); err != nil {
+			return nil, err
+		}
+		n := int64(binary.BigEndian.Uint32(hdr[:]))
+		if n < overhead || f.end+4+n > size {
+			break
+		}
+		f.records = append(f.records, appendRecord{pos: f.size, offset: f.end + 4, n: n})
+		f.size += n - overhead
+		f.end += 4 + n
+	}
+
+	// Only the final record can be partially written, so it is checked.
+	if i := len(f.records) - 1; i >= 0 {
+		if _, err := f.record(i); err == ErrCorrupt {
+			rec := f.records[i]
+			f.records, f.size, f.end = f.records[:i], rec.pos, rec.offset-4
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// KeyID returns the ID of the master key encrypting the data key of the file.
+func (f *AppendFile) KeyID() string { return f.key.KeyID() }
+
+// Size returns the size of the plaintext, including buffered data.
+func (f *AppendFile) Size() int64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.size + int64(len(f.buf))
+}
+
+// End returns the offset of the end of the records in the file. Data after it
+// is a partial write.
+func (f *AppendFile) End() int64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.end
+}
+
+// SetWriter sets the writer of the appended records. w must write at the end
+// of the records in the file.
+func (f *AppendFile) SetWriter(w io.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.w = w
+}
+
+// Slice returns the plaintext from off to the end of the record holding off.
+// The returned slice must not be modified.
+func (f *AppendFile) Slice(off int64) ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if off < 0 {
+		return nil, io.ErrUnexpectedEOF
+	} else if off >= f.size {
+		if off-f.size >= int64(len(f.buf)) {
+			return nil, io.EOF
+		}
+		return f.buf[off-f.size:], nil
+	}
+
+	i := sort.Search(len(f.records), func(i int) bool { return f.records[i].pos > off }) - 1
+	b, err := f.record(i)
+	if err != nil {
+		return nil, err
+	}
+	return b[off-f.records[i].pos:], nil
+}
+
+// record returns the plaintext of the record with the given index, decrypting
+// it if it is not cached.
+func (f *AppendFile) record(index int) ([]byte, error) {
+	id := chunkID{file: f.file, index: int64(index)}
+	if b, ok := f.cache.get(id); ok {
+		return b, nil
+	}
+
+	rec := f.records[index]
+	buf := make([]byte, rec.n)
+	if _, err := f.r.ReadAt(buf, rec.offset); err == io.EOF {
+		return nil, ErrCorrupt
+	} else if err != nil {
+		return nil, err
+	}
+
+	b, err := openRandom(f.key.aead, nil, buf, recordAAD(rec.pos))
+	if err != nil {
+		return nil, err
+	}
+	f.cache.add(id, b)
+	return b, nil
+}
+
+// Write appends p to the plaintext. The buffered data is written as a record
+// first if it would grow beyond ChunkSize.
+func (f *AppendFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.buf) > 0 && len(f.buf)+len(p) > ChunkSize {
+		if err := f.flush(); err != nil {
+			return 0, err
+		}
+	}
+	f.buf = append(f.buf, p...)
+	return len(p), nil
+}
+
+// Flush writes the buffered data as a record.
+func (f *AppendFile) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.flush()
+}
+
+func (f *AppendFile) flush() error {
+	if len(f.buf) == 0 {
+		return nil
+	} else if f.w == nil {
+		return io.ErrClosedPipe
+	}
+
+	f.sealed = appendRecordTo(f.sealed[:0], f.key, f.size, f.buf)
+	if _, err := f.w.Write(f.sealed); err != nil {
+		return err
+	}
+	n := int64(len(f.sealed) - 4)
+	f.records = append(f.records, appendRecord{pos: f.size, offset: f.end + 4, n: n})
+	f.size += int64(len(f.buf))
+	f.end += 4 + n
+
+	// Slices of the buffer may still be read, so it is cached as the
+	// plaintext of the record instead of being reused.
+	f.cache.add(chunkID{file: f.file, index: int64(len(f.records) - 1)}, f.buf)
+	f.buf = nil
+	return nil
+}
+
+// Close evicts the records of the file from the cache. The underlying reader
+// and writer are not closed, and buffered data is not flushed.
+func (f *AppendFile) Close() error {
+	f.cache.remove(f.file)
+	return nil
+}
+
+// appendRecordTo appends the size and the encryption of the plaintext at
+// offset pos to dst.
+func appendRecordTo(dst []byte, key *FileKey, pos int64, plaintext []byte) []byte {
+	i := len(dst)
+	dst = sealRandom(key.aead, append(dst, 0, 0, 0, 0), plaintext, recordAAD(pos))
+	binary.BigEndian.PutUint32(dst[i:], uint32(len(dst)-i-4))
+	return dst
+}
+
+func recordAAD(pos int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(pos))
+}
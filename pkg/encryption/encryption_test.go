@@ -0,0 +1,451 @@
+package encryption_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/encryption"
+)
+
+func TestKeyFile(t *testing.T) {
+	path := writeKeyFile(t, "# keys\n", "k1", "", "k2")
+
+	kf, err := encryption.NewKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, key, err := kf.CurrentKey(); err != nil {
+		t.Fatal(err)
+	} else if id != "k2" || len(key) != encryption.KeySize {
+		t.Fatalf("unexpected current key: %q %d", id, len(key))
+	}
+	if _, err := kf.Key("k1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kf.Key("k3"); !errors.Is(err, encryption.ErrKeyNotFound) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestKeyFile_Invalid(t *testing.T) {
+	for _, tt := range []struct {
+		name, content, err string
+	}{
+		{name: "empty", content: "# no keys\n", err: "no keys found"},
+		{name: "missing key", content: "k1\n", err: "expected key id and key"},
+		{name: "short key", content: "k1 " + base64.StdEncoding.EncodeToString([]byte("short")) + "\n", err: "key must be 32 bytes"},
+		{name: "duplicate", content: "k1 " + newKey() + "\nk1 " + newKey() + "\n", err: "duplicate key id"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keys")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := encryption.NewKeyFile(path); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	kf := mustKeyFile(t, "k1")
+
+	for _, n := range []int{0, 1, encryption.ChunkSize - 1, encryption.ChunkSize, encryption.ChunkSize + 1, 3*encryption.ChunkSize + 17} {
+		data := make([]byte, n)
+		rand.Read(data)
+
+		var buf bytes.Buffer
+		w, err := encryption.NewWriter(&buf, kf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Write in uneven pieces to cross chunk boundaries.
+		for p := data; len(p) > 0; {
+			m := 1000
+			if m > len(p) {
+				m = len(p)
+			}
+			if _, err := w.Write(p[:m]); err != nil {
+				t.Fatal(err)
+			}
+			p = p[m:]
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		b := buf.Bytes()
+		if !encryption.IsEncrypted(b) {
+			t.Fatalf("%d: expected encrypted file", n)
+		} else if n > 16 && bytes.Contains(b, data[:16]) {
+			t.Fatalf("%d: plaintext found in encrypted file", n)
+		}
+		got, err := encryption.Decrypt(b, kf)
+		if err != nil {
+			t.Fatalf("%d: %v", n, err)
+		} else if !bytes.Equal(got, data) {
+			t.Fatalf("%d: decrypted data mismatch", n)
+		}
+	}
+}
+
+func TestDecrypt_Corrupt(t *testing.T) {
+	kf := mustKeyFile(t, "k1")
+
+	data := make([]byte, 2*encryption.ChunkSize+100)
+	rand.Read(data)
+	var buf bytes.Buffer
+	w, err := encryption.NewWriter(&buf, kf)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+
+	// Truncating the file at a chunk boundary drops the final chunk.
+	if _, err := encryption.Decrypt(b[:len(b)-116], kf); err != encryption.ErrCorrupt {
+		t.Fatalf("unexpected error on truncated file: %v", err)
+	}
+
+	// Modifying a byte fails authentication.
+	modified := append([]byte(nil), b...)
+	modified[len(modified)/2] ^= 1
+	if _, err := encryption.Decrypt(modified, kf); err != encryption.ErrCorrupt {
+		t.Fatalf("unexpected error on modified file: %v", err)
+	}
+
+	if _, err := encryption.Decrypt(data, kf); err != encryption.ErrNotEncrypted {
+		t.Fatalf("unexpected error on plaintext file: %v", err)
+	}
+}
+
+func TestReaderAt(t *testing.T) {
+	kf := mustKeyFile(t, "k1")
+
+	for _, n := range []int{0, 1, encryption.ChunkSize, 3*encryption.ChunkSize + 17} {
+		data := make([]byte, n)
+		rand.Read(data)
+		b := mustEncrypt(t, kf, data)
+
+		cache := encryption.NewChunkCache(2 * encryption.ChunkSize)
+		r, err := encryption.NewReaderAt(bytes.NewReader(b), int64(len(b)), kf, cache)
+		if err != nil {
+			t.Fatalf("%d: %v", n, err)
+		} else if r.Size() != int64(n) {
+			t.Fatalf("%d: unexpected size: %d", n, r.Size())
+		}
+
+		// Read ranges within and across chunks.
+		for _, rng := range [][2]int{{0, n}, {0, n / 2}, {n / 3, n}, {n - n/5, n}} {
+			p := make([]byte, rng[1]-rng[0])
+			if _, err := r.ReadAt(p, int64(rng[0])); err != nil {
+				t.Fatalf("%d: read %v: %v", n, rng, err)
+			} else if !bytes.Equal(p, data[rng[0]:rng[1]]) {
+				t.Fatalf("%d: read %v: data mismatch", n, rng)
+			}
+		}
+		if size := cache.Size(); size > 2*encryption.ChunkSize {
+			t.Fatalf("%d: cache exceeds its size: %d", n, size)
+		}
+
+		if _, err := r.ReadAt(make([]byte, 1), int64(n)); err != io.EOF {
+			t.Fatalf("%d: unexpected error reading past the end: %v", n, err)
+		}
+
+		// Sections are read whole and cached as they are.
+		for _, rng := range [][2]int{{0, n}, {n / 3, n - n/5}} {
+			p, err := r.Section(int64(rng[0]), int64(rng[1]-rng[0]))
+			if err != nil {
+				t.Fatalf("%d: section %v: %v", n, rng, err)
+			} else if !bytes.Equal(p, data[rng[0]:rng[1]]) {
+				t.Fatalf("%d: section %v: data mismatch", n, rng)
+			} else if q, err := r.Section(int64(rng[0]), int64(rng[1]-rng[0])); err != nil || len(p) > 0 && &q[0] != &p[0] {
+				t.Fatalf("%d: section %v not cached: %v", n, rng, err)
+			}
+		}
+		if _, err := r.Section(int64(n/2), int64(n)+1); err != io.ErrUnexpectedEOF {
+			t.Fatalf("%d: unexpected error reading a section past the end: %v", n, err)
+		}
+
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		} else if size := cache.Size(); size != 0 {
+			t.Fatalf("%d: chunks left in cache after close: %d", n, size)
+		}
+	}
+}
+
+func TestReaderAt_Corrupt(t *testing.T) {
+	kf := mustKeyFile(t, "k1")
+
+	data := make([]byte, 2*encryption.ChunkSize+100)
+	rand.Read(data)
+	b := mustEncrypt(t, kf, data)
+	cache := encryption.NewChunkCache(encryption.ChunkSize)
+
+	// Truncating the file at a chunk boundary turns a chunk into the final
+	// chunk, which fails authentication.
+	truncated := b[:len(b)-116]
+	r, err := encryption.NewReaderAt(bytes.NewReader(truncated), int64(len(truncated)), kf, cache)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := r.ReadAt(make([]byte, 1), encryption.ChunkSize); err != encryption.ErrCorrupt {
+		t.Fatalf("unexpected error on truncated file: %v", err)
+	}
+
+	// Modifying a byte only fails the reads of its chunk.
+	modified := append([]byte(nil), b...)
+	modified[len(modified)-50] ^= 1
+	r, err = encryption.NewReaderAt(bytes.NewReader(modified), int64(len(modified)), kf, cache)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := r.ReadAt(make([]byte, 10), 0); err != nil {
+		t.Fatalf("unexpected error on unmodified chunk: %v", err)
+	} else if _, err := r.ReadAt(make([]byte, 10), int64(len(data)-10)); err != encryption.ErrCorrupt {
+		t.Fatalf("unexpected error on modified chunk: %v", err)
+	}
+
+	if _, err := encryption.NewReaderAt(bytes.NewReader(data), int64(len(data)), kf, cache); err != encryption.ErrNotEncrypted {
+		t.Fatalf("unexpected error on plaintext file: %v", err)
+	}
+}
+
+func TestDecrypt_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys")
+	k1 := "k1 " + newKey() + "\n"
+	if err := os.WriteFile(path, []byte(k1), 0600); err != nil {
+		t.Fatal(err)
+	}
+	kf, err := encryption.NewKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := encryption.NewWriter(&buf, kf)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := w.Write([]byte("data")); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Files encrypted with an old key are readable after rotation.
+	if err := os.WriteFile(path, []byte(k1+"k2 "+newKey()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	} else if err := kf.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := encryption.Decrypt(buf.Bytes(), kf); err != nil {
+		t.Fatal(err)
+	} else if string(got) != "data" {
+		t.Fatalf("unexpected data: %q", got)
+	}
+
+	// But not after the old key was removed.
+	if err := os.WriteFile(path, []byte("k2 "+newKey()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	} else if err := kf.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encryption.Decrypt(buf.Bytes(), kf); !errors.Is(err, encryption.ErrKeyNotFound) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFileKey_Records(t *testing.T) {
+	kf := mustKeyFile(t, "k1")
+
+	key, err := encryption.NewFileKey(kf, encryption.ModeRecords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	buf.Write(key.Header())
+	a := key.Seal(nil, []byte("record a"))
+	b := key.Seal(nil, []byte("record b"))
+	if len(a) != len("record a")+key.Overhead() {
+		t.Fatalf("unexpected record size: %d", len(a))
+	}
+
+	other, err := encryption.ReadFileKey(&buf, kf, encryption.ModeRecords)
+	if err != nil {
+		t.Fatal(err)
+	} else if other.KeyID() != "k1" {
+		t.Fatalf("unexpected key id: %q", other.KeyID())
+	}
+	for exp, ciphertext := range map[string][]byte{"record a": a, "record b": b} {
+		if got, err := other.Open(nil, ciphertext); err != nil {
+			t.Fatal(err)
+		} else if string(got) != exp {
+			t.Fatalf("got %q, exp %q", got, exp)
+		}
+	}
+
+	a[len(a)-1] ^= 1
+	if _, err := other.Open(nil, a); err != encryption.ErrCorrupt {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := encryption.ReadFileKey(bytes.NewReader(key.Header()), kf, encryption.ModeChunked); err == nil {
+		t.Fatal("expected mode mismatch error")
+	}
+	if _, err := encryption.ReadFileKey(strings.NewReader("plaintext"), kf, encryption.ModeRecords); err != encryption.ErrNotEncrypted {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAppendFile(t *testing.T) {
+	kf := mustKeyFile(t, "k1")
+	cache := encryption.NewChunkCache(encryption.ChunkSize)
+
+	var buf bytes.Buffer
+	if err := encryption.WriteAppendFile(&buf, kf, []byte("header")); err != nil {
+		t.Fatal(err)
+	}
+	f, err := encryption.OpenAppendFile(bufferReaderAt{&buf}, int64(buf.Len()), kf, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Appended data is readable before and after it is flushed, and each
+	// write is read back from a single slice.
+	entries := [][]byte{[]byte("foo"), make([]byte, encryption.ChunkSize-1), []byte("bar")}
+	rand.Read(entries[1])
+	f.SetWriter(&buf)
+	offsets := make([]int64, len(entries))
+	for i, e := range entries {
+		offsets[i] = f.Size()
+		if _, err := f.Write(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, e := range entries {
+		if b, err := f.Slice(offsets[i]); err != nil {
+			t.Fatal(err)
+		} else if !bytes.HasPrefix(b, e) {
+			t.Fatalf("unexpected data of entry %d", i)
+		}
+	}
+	if err := f.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening the file reads the same plaintext, ignoring a partially
+	// written record, which is overwritten by the next write.
+	end := f.End()
+	b := append(append([]byte(nil), buf.Bytes()...), 0, 0, 1, 0, 1, 2, 3)
+	other, err := encryption.OpenAppendFile(bytes.NewReader(b), int64(len(b)), kf, cache)
+	if err != nil {
+		t.Fatal(err)
+	} else if other.End() != end || other.Size() != f.Size() {
+		t.Fatalf("unexpected end or size: %d %d", other.End(), other.Size())
+	}
+	for i, e := range entries {
+		if b, err := other.Slice(offsets[i]); err != nil {
+			t.Fatal(err)
+		} else if !bytes.HasPrefix(b, e) {
+			t.Fatalf("unexpected data of reopened entry %d", i)
+		}
+	}
+	if _, err := other.Slice(other.Size()); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A modified final record is ignored as a partial write, and so is a
+	// final record copied from another offset.
+	last := end - int64(len(entries[2])+4+28)
+	modified := append([]byte(nil), buf.Bytes()...)
+	modified[len(modified)-1] ^= 1
+	if other, err = encryption.OpenAppendFile(bytes.NewReader(modified), int64(len(modified)), kf, cache); err != nil {
+		t.Fatal(err)
+	} else if other.Size() != offsets[2] {
+		t.Fatalf("modified final record not ignored: %d", other.Size())
+	}
+	modified = append(buf.Bytes(), buf.Bytes()[last:]...)
+	if other, err = encryption.OpenAppendFile(bytes.NewReader(modified), int64(len(modified)), kf, cache); err != nil {
+		t.Fatal(err)
+	} else if other.Size() != f.Size() {
+		t.Fatalf("copied record not ignored: %d", other.Size())
+	}
+
+	// Other modified records are not decrypted.
+	modified = append([]byte(nil), buf.Bytes()...)
+	modified[last-1] ^= 1
+	if other, err = encryption.OpenAppendFile(bytes.NewReader(modified), int64(len(modified)), kf, cache); err != nil {
+		t.Fatal(err)
+	} else if _, err := other.Slice(offsets[1]); err != encryption.ErrCorrupt {
+		t.Fatalf("unexpected error on modified record: %v", err)
+	} else if b, err := other.Slice(offsets[2]); err != nil || !bytes.Equal(b, entries[2]) {
+		t.Fatalf("unexpected data of unmodified record: %q %v", b, err)
+	}
+}
+
+// bufferReaderAt reads the current content of a buffer.
+type bufferReaderAt struct{ *bytes.Buffer }
+
+func (b bufferReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	return bytes.NewReader(b.Bytes()).ReadAt(p, off)
+}
+
+// mustEncrypt returns data encrypted as a chunked file.
+func mustEncrypt(t *testing.T, kp encryption.KeyProvider, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := encryption.NewWriter(&buf, kp)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// mustKeyFile returns a KeyFile with new keys with the given IDs.
+func mustKeyFile(t *testing.T, ids ...string) *encryption.KeyFile {
+	t.Helper()
+	kf, err := encryption.NewKeyFile(writeKeyFile(t, "", ids...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kf
+}
+
+// writeKeyFile writes a key file with new keys with the given IDs and returns
+// its path. Empty IDs are written as blank lines.
+func writeKeyFile(t *testing.T, prefix string, ids ...string) string {
+	t.Helper()
+	content := prefix
+	for _, id := range ids {
+		if id != "" {
+			content += id + " " + newKey()
+		}
+		content += "\n"
+	}
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newKey() string {
+	key := make([]byte, encryption.KeySize)
+	rand.Read(key)
+	return base64.StdEncoding.EncodeToString(key)
+}
@@ -0,0 +1,359 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// Magic is the magic number at the start of encrypted files. It differs
+	// from the magic number of TSM files and from the entry types of WAL
+	// segments, so encrypted and plaintext files can be told apart.
+	Magic uint32 = 0xE7C1D0A5
+
+	// Version is the version of the format of encrypted files.
+	Version byte = 1
+
+	// ChunkSize is the size of the plaintext chunks of chunked files.
+	ChunkSize = 64 * 1024
+)
+
+// Modes of encrypted files.
+const (
+	// ModeChunked files are a sequence of chunks encrypted with the index of
+	// the chunk as the nonce, so they can only be appended to while written.
+	ModeChunked byte = 1
+
+	// ModeRecords files are a sequence of records encrypted with random
+	// nonces, so they can be reopened and appended to.
+	ModeRecords byte = 2
+
+	// ModeAppend files are a sequence of records holding consecutive parts
+	// of the plaintext, encrypted with random nonces and bound to their
+	// offsets, so they can be reopened, appended to and read at any offset.
+	ModeAppend byte = 3
+)
+
+var (
+	// ErrNotEncrypted is returned when a file does not start with Magic.
+	ErrNotEncrypted = errors.New("file is not encrypted")
+
+	// ErrCorrupt is returned when an encrypted file was modified or
+	// truncated.
+	ErrCorrupt = errors.New("encrypted file is corrupt")
+)
+
+// headerSize is the size of the fixed part of the header: the magic number,
+// the version, the mode and the size of the key ID.
+const headerSize = 7
+
+// IsEncrypted returns true if b is the start of an encrypted file.
+func IsEncrypted(b []byte) bool {
+	return len(b) >= 4 && binary.BigEndian.Uint32(b) == Magic
+}
+
+// FileKey is the data key of an encrypted file.
+type FileKey struct {
+	mode   byte
+	keyID  string
+	aead   cipher.AEAD
+	header []byte
+}
+
+// NewFileKey returns a new random data key, encrypted with the current master
+// key of kp in the header of the file.
+func NewFileKey(kp KeyProvider, mode byte) (*FileKey, error) {
+	keyID, master, err := kp.CurrentKey()
+	if err != nil {
+		return nil, err
+	} else if len(keyID) > 255 {
+		return nil, errors.New("encryption key id too long")
+	}
+	wrap, err := newAEAD(master)
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, headerSize, headerSize+len(keyID)+2+wrap.NonceSize()+KeySize+wrap.Overhead())
+	binary.BigEndian.PutUint32(header, Magic)
+	header[4], header[5], header[6] = Version, mode, byte(len(keyID))
+	header = append(header, keyID...)
+	wrapped := sealRandom(wrap, nil, key, []byte(keyID))
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrapped)))
+	header = append(header, wrapped...)
+
+	return &FileKey{mode: mode, keyID: keyID, aead: aead, header: header}, nil
+}
+
+// ReadFileKey reads the header of an encrypted file from r and returns its
+// data key. It returns ErrNotEncrypted if r does not start with Magic.
+func ReadFileKey(r io.Reader, kp KeyProvider, mode byte) (*FileKey, error) {
+	fixed := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, err
+	} else if !IsEncrypted(fixed) {
+		return nil, ErrNotEncrypted
+	}
+
+	header := append(fixed, make([]byte, int(fixed[6])+2)...)
+	if _, err := io.ReadFull(r, header[headerSize:]); err != nil {
+		return nil, err
+	}
+	n := int(binary.BigEndian.Uint16(header[len(header)-2:]))
+	header = append(header, make([]byte, n)...)
+	if _, err := io.ReadFull(r, header[len(header)-n:]); err != nil {
+		return nil, err
+	}
+
+	return parseFileKey(header, kp, mode)
+}
+
+// parseFileKey returns the data key of a complete header.
+func parseFileKey(header []byte, kp KeyProvider, mode byte) (*FileKey, error) {
+	if header[4] != Version {
+		return nil, fmt.Errorf("unsupported encrypted file version: %d", header[4])
+	} else if header[5] != mode {
+		return nil, fmt.Errorf("unexpected encrypted file mode: %d", header[5])
+	}
+
+	keyID := string(header[headerSize : headerSize+int(header[6])])
+	master, err := kp.Key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	wrap, err := newAEAD(master)
+	if err != nil {
+		return nil, err
+	}
+	key, err := openRandom(wrap, nil, header[headerSize+len(keyID)+2:], []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt data key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileKey{mode: mode, keyID: keyID, aead: aead, header: header}, nil
+}
+
+// headerLen returns the size of the header at the start of b, or 0 if b is
+// too short to hold the header.
+func headerLen(b []byte) int {
+	if len(b) < headerSize {
+		return 0
+	}
+	n := headerSize + int(b[6]) + 2
+	if len(b) < n {
+		return 0
+	}
+	n += int(binary.BigEndian.Uint16(b[n-2:]))
+	if len(b) < n {
+		return 0
+	}
+	return n
+}
+
+// KeyID returns the ID of the master key encrypting the data key.
+func (k *FileKey) KeyID() string { return k.keyID }
+
+// Header returns the header to write at the start of the file.
+func (k *FileKey) Header() []byte { return k.header }
+
+// Overhead returns the number of bytes Seal adds to the plaintext.
+func (k *FileKey) Overhead() int { return k.aead.NonceSize() + k.aead.Overhead() }
+
+// Seal appends the encryption of a record to dst, using a random nonce.
+func (k *FileKey) Seal(dst, plaintext []byte) []byte {
+	return sealRandom(k.aead, dst, plaintext, nil)
+}
+
+// Open appends the decryption of a record encrypted by Seal to dst.
+func (k *FileKey) Open(dst, ciphertext []byte) ([]byte, error) {
+	return openRandom(k.aead, dst, ciphertext, nil)
+}
+
+// Writer encrypts the data written to it as a chunked file. Close must be
+// called to write the final chunk; a file without its final chunk cannot be
+// decrypted.
+type Writer struct {
+	w     io.Writer
+	key   *FileKey
+	buf   []byte
+	out   []byte
+	chunk uint64
+	err   error
+}
+
+// NewWriter returns a Writer encrypting data with a new data key and writing
+// it to w. The header is written to w immediately.
+func NewWriter(w io.Writer, kp KeyProvider) (*Writer, error) {
+	key, err := NewFileKey(kp, ModeChunked)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(key.Header()); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, key: key, buf: make([]byte, 0, ChunkSize)}, nil
+}
+
+// Write encrypts p. Full chunks are written when more data follows them.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	var n int
+	for len(p) > 0 {
+		if len(w.buf) == ChunkSize {
+			if w.err = w.writeChunk(false); w.err != nil {
+				return n, w.err
+			}
+		}
+		m := copy(w.buf[len(w.buf):ChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+m]
+		p = p[m:]
+		n += m
+	}
+	return n, nil
+}
+
+// writeChunk encrypts and writes the buffered chunk.
+func (w *Writer) writeChunk(final bool) error {
+	var nonce [12]byte
+	binary.BigEndian.PutUint64(nonce[4:], w.chunk)
+	w.out = w.key.aead.Seal(w.out[:0], nonce[:], w.buf, chunkAAD(final))
+	if _, err := w.w.Write(w.out); err != nil {
+		return err
+	}
+	w.buf = w.buf[:0]
+	w.chunk++
+	return nil
+}
+
+// Sync syncs the underlying writer if it supports syncing. The buffered data
+// is not synced until Close.
+func (w *Writer) Sync() error {
+	if s, ok := w.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Name returns the name of the underlying writer, if it has one.
+func (w *Writer) Name() string {
+	if n, ok := w.w.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	return ""
+}
+
+// Close writes the final chunk and closes the underlying writer if it is an
+// io.Closer.
+func (w *Writer) Close() error {
+	if w.err == nil {
+		w.err = w.writeChunk(true)
+		if w.err == nil {
+			w.err = w.Sync()
+		}
+	}
+	if c, ok := w.w.(io.Closer); ok {
+		if err := c.Close(); err != nil && w.err == nil {
+			return err
+		}
+	}
+	return w.err
+}
+
+// Decrypt returns the plaintext of a chunked file.
+func Decrypt(b []byte, kp KeyProvider) ([]byte, error) {
+	if !IsEncrypted(b) {
+		return nil, ErrNotEncrypted
+	}
+	n := headerLen(b)
+	if n == 0 {
+		return nil, ErrCorrupt
+	}
+	key, err := parseFileKey(b[:n], kp, ModeChunked)
+	if err != nil {
+		return nil, err
+	}
+	b = b[n:]
+
+	overhead := key.aead.Overhead()
+	chunks := (len(b) + ChunkSize + overhead - 1) / (ChunkSize + overhead)
+	if chunks == 0 {
+		return nil, ErrCorrupt
+	}
+	dst := make([]byte, 0, len(b)-chunks*overhead)
+
+	var nonce [12]byte
+	for i := uint64(0); ; i++ {
+		final := len(b) <= ChunkSize+overhead
+		n := len(b)
+		if !final {
+			n = ChunkSize + overhead
+		}
+		binary.BigEndian.PutUint64(nonce[4:], i)
+		if dst, err = key.aead.Open(dst, nonce[:], b[:n], chunkAAD(final)); err != nil {
+			return nil, ErrCorrupt
+		}
+		if final {
+			return dst, nil
+		}
+		b = b[n:]
+	}
+}
+
+func chunkAAD(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealRandom appends the random nonce and the encryption of plaintext to dst.
+func sealRandom(aead cipher.AEAD, dst, plaintext, aad []byte) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	dst = append(dst, nonce...)
+	return aead.Seal(dst, nonce, plaintext, aad)
+}
+
+// openRandom appends the decryption of a ciphertext sealed by sealRandom to dst.
+func openRandom(aead cipher.AEAD, dst, ciphertext, aad []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrCorrupt
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	b, err := aead.Open(dst, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return b, nil
+}
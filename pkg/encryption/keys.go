@@ -0,0 +1,121 @@
+// Package encryption encrypts files at rest with AES-GCM.
+//
+// Each file is encrypted with its own random data key. The data key is
+// encrypted with a master key of a KeyProvider and stored, with the ID of the
+// master key, in the header of the file. Rotating the master key only
+// requires rewriting the files, which compactions do for the files whose
+// header holds the ID of another key.
+package encryption
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// KeySize is the size of the master and data keys, selecting AES-256.
+const KeySize = 32
+
+// ErrKeyNotFound is returned when a file is encrypted with an unknown key.
+var ErrKeyNotFound = errors.New("encryption key not found")
+
+// KeyProvider provides the master keys encrypting the data keys of files.
+type KeyProvider interface {
+	// CurrentKey returns the ID and the master key encrypting new files.
+	CurrentKey() (id string, key []byte, err error)
+
+	// Key returns the master key with the given ID.
+	Key(id string) ([]byte, error)
+}
+
+// KeyFile is a KeyProvider reading the master keys from a local file. Each
+// line of the file holds the ID of a key and the base64 encoded key, separated
+// by a space. The last key is the current key, so a key is rotated by
+// appending a new key and keeping the older keys to read existing files.
+// Blank lines and lines starting with # are ignored.
+type KeyFile struct {
+	path string
+
+	mu      sync.RWMutex
+	keys    map[string][]byte
+	current string
+}
+
+// NewKeyFile returns a KeyFile with the keys of the file at path.
+func NewKeyFile(path string) (*KeyFile, error) {
+	kf := &KeyFile{path: path}
+	if err := kf.Reload(); err != nil {
+		return nil, err
+	}
+	return kf, nil
+}
+
+// Reload reads the keys of the file again. Keys removed from the file can no
+// longer decrypt files.
+func (kf *KeyFile) Reload() error {
+	f, err := os.Open(kf.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	keys := make(map[string][]byte)
+	var current string
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return fmt.Errorf("%s:%d: expected key id and key", kf.path, n)
+		}
+		id := fields[0]
+		if len(id) > 255 {
+			return fmt.Errorf("%s:%d: key id too long", kf.path, n)
+		} else if _, ok := keys[id]; ok {
+			return fmt.Errorf("%s:%d: duplicate key id %q", kf.path, n, id)
+		}
+		key, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return fmt.Errorf("%s:%d: invalid key: %s", kf.path, n, err)
+		} else if len(key) != KeySize {
+			return fmt.Errorf("%s:%d: key must be %d bytes", kf.path, n, KeySize)
+		}
+		keys[id], current = key, id
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	} else if current == "" {
+		return fmt.Errorf("%s: no keys found", kf.path)
+	}
+
+	kf.mu.Lock()
+	kf.keys, kf.current = keys, current
+	kf.mu.Unlock()
+	return nil
+}
+
+// CurrentKey returns the last key of the file.
+func (kf *KeyFile) CurrentKey() (string, []byte, error) {
+	kf.mu.RLock()
+	defer kf.mu.RUnlock()
+	return kf.current, kf.keys[kf.current], nil
+}
+
+// Key returns the key with the given ID.
+func (kf *KeyFile) Key(id string) ([]byte, error) {
+	kf.mu.RLock()
+	defer kf.mu.RUnlock()
+	key, ok := kf.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, id)
+	}
+	return key, nil
+}
@@ -0,0 +1,253 @@
+package encryption
+
+import (
+	"container/list"
+	"encoding/binary"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// ChunkCache is an LRU cache of the decrypted chunks of chunked files and
+// records of append files, shared by the readers of the files. It is safe for
+// concurrent use.
+type ChunkCache struct {
+	mu      sync.Mutex
+	maxSize int
+	size    int
+	lru     *list.List // of *cachedChunk, most recently used first
+	chunks  map[chunkID]*list.Element
+
+	nextFile uint64
+}
+
+// chunkID identifies a chunk of a file read through the cache.
+type chunkID struct {
+	file    uint64
+	index   int64
+	section int64 // size of a section starting at offset index, or 0 for chunks
+}
+
+type cachedChunk struct {
+	id chunkID
+	b  []byte
+}
+
+// NewChunkCache returns a cache holding up to maxSize bytes of decrypted
+// chunks. The cache holds at least one chunk.
+func NewChunkCache(maxSize int) *ChunkCache {
+	return &ChunkCache{
+		maxSize: maxSize,
+		lru:     list.New(),
+		chunks:  make(map[chunkID]*list.Element),
+	}
+}
+
+// get returns the cached chunk and marks it as the most recently used.
+func (c *ChunkCache) get(id chunkID) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.chunks[id]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*cachedChunk).b, true
+}
+
+// add caches the chunk and evicts the least recently used chunks beyond the
+// size of the cache.
+func (c *ChunkCache) add(id chunkID, b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.chunks[id]; ok {
+		return
+	}
+	c.chunks[id] = c.lru.PushFront(&cachedChunk{id: id, b: b})
+	c.size += len(b)
+
+	for c.size > c.maxSize && c.lru.Len() > 1 {
+		e := c.lru.Back()
+		chunk := c.lru.Remove(e).(*cachedChunk)
+		delete(c.chunks, chunk.id)
+		c.size -= len(chunk.b)
+	}
+}
+
+// remove evicts the chunks of the file.
+func (c *ChunkCache) remove(file uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for e := c.lru.Front(); e != nil; {
+		next := e.Next()
+		if chunk := e.Value.(*cachedChunk); chunk.id.file == file {
+			c.lru.Remove(e)
+			delete(c.chunks, chunk.id)
+			c.size -= len(chunk.b)
+		}
+		e = next
+	}
+}
+
+// Size returns the number of bytes of decrypted chunks held by the cache.
+func (c *ChunkCache) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// ReaderAt reads the plaintext of a chunked file at random offsets. Only the
+// chunks holding the data read are decrypted, and they are kept in a
+// ChunkCache for the following reads.
+type ReaderAt struct {
+	r      io.ReaderAt
+	key    *FileKey
+	cache  *ChunkCache
+	file   uint64
+	offset int64 // offset of the first chunk in r
+	chunks int64 // number of chunks
+	last   int64 // size of the ciphertext of the final chunk
+	size   int64 // size of the plaintext
+}
+
+// NewReaderAt returns a reader of the plaintext of the chunked file of the
+// given size read from r, caching the decrypted chunks in cache. It returns
+// ErrNotEncrypted if the file does not start with Magic.
+func NewReaderAt(r io.ReaderAt, size int64, kp KeyProvider, cache *ChunkCache) (*ReaderAt, error) {
+	key, err := ReadFileKey(io.NewSectionReader(r, 0, size), kp, ModeChunked)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, ErrCorrupt
+	} else if err != nil {
+		return nil, err
+	}
+
+	overhead := int64(key.aead.Overhead())
+	offset := int64(len(key.Header()))
+	n := size - offset
+	chunks := (n + ChunkSize + overhead - 1) / (ChunkSize + overhead)
+	if chunks == 0 {
+		return nil, ErrCorrupt
+	}
+	last := n - (chunks-1)*(ChunkSize+overhead)
+	if last < overhead {
+		return nil, ErrCorrupt
+	}
+
+	return &ReaderAt{
+		r:      r,
+		key:    key,
+		cache:  cache,
+		file:   atomic.AddUint64(&cache.nextFile, 1),
+		offset: offset,
+		chunks: chunks,
+		last:   last,
+		size:   n - chunks*overhead,
+	}, nil
+}
+
+// Size returns the size of the plaintext.
+func (r *ReaderAt) Size() int64 { return r.size }
+
+// KeyID returns the ID of the master key encrypting the data key of the file.
+func (r *ReaderAt) KeyID() string { return r.key.KeyID() }
+
+// ReadAt reads len(p) bytes of the plaintext starting at off.
+func (r *ReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	var n int
+	for n < len(p) {
+		if off >= r.size {
+			return n, io.EOF
+		}
+		chunk, err := r.chunk(off / ChunkSize)
+		if err != nil {
+			return n, err
+		}
+		m := copy(p[n:], chunk[off%ChunkSize:])
+		n += m
+		off += int64(m)
+	}
+	return n, nil
+}
+
+// Section returns the n bytes of the plaintext starting at off. The section is
+// cached as a whole instead of the chunks holding it, so reading it again does
+// not copy it. The returned slice must not be modified.
+func (r *ReaderAt) Section(off, n int64) ([]byte, error) {
+	if off < 0 || n < 0 || off+n > r.size {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	id := chunkID{file: r.file, index: off, section: n}
+	if b, ok := r.cache.get(id); ok {
+		return b, nil
+	}
+
+	b := make([]byte, n)
+	for i := int64(0); i < n; {
+		index := (off + i) / ChunkSize
+		chunk, ok := r.cache.get(chunkID{file: r.file, index: index})
+		if !ok {
+			var err error
+			if chunk, err = r.decrypt(index); err != nil {
+				return nil, err
+			}
+		}
+		i += int64(copy(b[i:], chunk[(off+i)%ChunkSize:]))
+	}
+	r.cache.add(id, b)
+	return b, nil
+}
+
+// chunk returns the plaintext of the chunk with the given index, decrypting
+// it if it is not cached.
+func (r *ReaderAt) chunk(index int64) ([]byte, error) {
+	id := chunkID{file: r.file, index: index}
+	if b, ok := r.cache.get(id); ok {
+		return b, nil
+	}
+
+	b, err := r.decrypt(index)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.add(id, b)
+	return b, nil
+}
+
+// decrypt returns the plaintext of the chunk with the given index.
+func (r *ReaderAt) decrypt(index int64) ([]byte, error) {
+	overhead := int64(r.key.aead.Overhead())
+	final := index == r.chunks-1
+	n := ChunkSize + overhead
+	if final {
+		n = r.last
+	}
+	buf := make([]byte, n)
+	if _, err := r.r.ReadAt(buf, r.offset+index*(ChunkSize+overhead)); err == io.EOF {
+		return nil, ErrCorrupt
+	} else if err != nil {
+		return nil, err
+	}
+
+	var nonce [12]byte
+	binary.BigEndian.PutUint64(nonce[4:], uint64(index))
+	b, err := r.key.aead.Open(buf[:0], nonce[:], buf, chunkAAD(final))
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return b, nil
+}
+
+// Close evicts the chunks of the file from the cache. The underlying reader
+// is not closed.
+func (r *ReaderAt) Close() error {
+	r.cache.remove(r.file)
+	return nil
+}
@@ -1,6 +1,9 @@
 package tracing
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/xlab/treeprint"
 )
 
@@ -26,6 +29,36 @@ func (t *TreeNode) String() string {
 	return tv.root.String()
 }
 
+// MarshalJSON returns the tree as JSON. Each node is an object with the name,
+// start time, labels, fields and children of the span. Durations are encoded
+// as nanoseconds.
+func (t *TreeNode) MarshalJSON() ([]byte, error) {
+	n := struct {
+		Name     string                 `json:"name"`
+		Start    time.Time              `json:"start"`
+		Labels   map[string]string      `json:"labels,omitempty"`
+		Fields   map[string]interface{} `json:"fields,omitempty"`
+		Children []*TreeNode            `json:"children,omitempty"`
+	}{
+		Name:     t.Raw.Name,
+		Start:    t.Raw.Start,
+		Children: t.Children,
+	}
+	if len(t.Raw.Labels) > 0 {
+		n.Labels = make(map[string]string, len(t.Raw.Labels))
+		for _, l := range t.Raw.Labels {
+			n.Labels[l.Key] = l.Value
+		}
+	}
+	if len(t.Raw.Fields) > 0 {
+		n.Fields = make(map[string]interface{}, len(t.Raw.Fields))
+		for _, f := range t.Raw.Fields {
+			n.Fields[f.Key()] = f.Value()
+		}
+	}
+	return json.Marshal(n)
+}
+
 // Walk traverses the graph in a depth-first order, calling v.Visit
 // for each node until completion or v.Visit returns nil.
 func Walk(v Visitor, node *TreeNode) {
@@ -0,0 +1,44 @@
+package tracing_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/pkg/tracing"
+	"github.com/influxdata/influxdb/pkg/tracing/fields"
+)
+
+func TestTreeNode_MarshalJSON(t *testing.T) {
+	start := time.Unix(0, 0).UTC()
+	trace, root := tracing.NewTrace("select", tracing.StartTime(start))
+	child := root.StartSpan("create_iterator", tracing.StartTime(start.Add(time.Second)))
+	child.SetLabels("shard_id", "1")
+	child.MergeFields(fields.Int64("float_blocks_decoded", 2), fields.Duration("planning_time", time.Millisecond))
+	child.Finish()
+	root.Finish()
+
+	b, err := json.Marshal(trace.Tree())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got, want interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	json.Unmarshal([]byte(`{
+		"name": "select",
+		"start": "1970-01-01T00:00:00Z",
+		"children": [{
+			"name": "create_iterator",
+			"start": "1970-01-01T00:00:01Z",
+			"labels": {"shard_id": "1"},
+			"fields": {"float_blocks_decoded": 2, "planning_time": 1000000}
+		}]
+	}`), &want)
+	if !cmp.Equal(got, want) {
+		t.Fatalf("unexpected JSON: %s", cmp.Diff(got, want))
+	}
+}
@@ -1188,7 +1188,7 @@ func (c *compiledStatement) Prepare(shardMapper ShardMapper, sopt SelectOptions)
 			buckets := (last - first + int64(interval)) / int64(interval)
 			if int(buckets) > sopt.MaxBucketsN {
 				shards.Close()
-				return nil, fmt.Errorf("max-select-buckets limit exceeded: (%d/%d)", buckets, sopt.MaxBucketsN)
+				return nil, ErrMaxSelectBucketsLimitExceeded(int(buckets), sopt.MaxBucketsN)
 			}
 		}
 	}
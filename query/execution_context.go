@@ -3,6 +3,7 @@ package query
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 )
 
 // ExecutionContext contains state that the query is currently executing with.
@@ -29,6 +30,17 @@ type ExecutionContext struct {
 	err  error
 }
 
+// AddSelectStats adds the statistics of a SELECT statement and the number of
+// shards it read to the query.
+func (ctx *ExecutionContext) AddSelectStats(stats IteratorStats, shardN int64) {
+	if ctx.task == nil {
+		return
+	}
+	atomic.AddInt64(&ctx.task.pointN, int64(stats.PointN))
+	atomic.AddInt64(&ctx.task.seriesN, int64(stats.SeriesN))
+	atomic.AddInt64(&ctx.task.shardN, shardN)
+}
+
 func (ctx *ExecutionContext) watch() {
 	ctx.done = make(chan struct{})
 	if ctx.err != nil {
@@ -37,6 +37,10 @@ var (
 	// ErrQueryTimeoutLimitExceeded is an error when a query hits the max time allowed to run.
 	ErrQueryTimeoutLimitExceeded = errors.New("query-timeout limit exceeded")
 
+	// ErrEnqueuedQueryTimeoutLimitExceeded is an error when a query waits in
+	// the queue longer than the time allowed.
+	ErrEnqueuedQueryTimeoutLimitExceeded = errors.New("enqueued-query-timeout limit exceeded")
+
 	// ErrAlreadyKilled is returned when attempting to kill a query that has already been killed.
 	ErrAlreadyKilled = errors.New("already killed")
 )
@@ -48,6 +52,12 @@ const (
 	statQueriesFinished        = "queriesFinished" // Number of queries that have finished.
 	statQueryExecutionDuration = "queryDurationNs" // Total (wall) time spent executing queries.
 	statRecoveredPanics        = "recoveredPanics" // Number of panics recovered by Query Executor.
+	statQueriesQueued          = "queriesQueued"   // Number of queries currently waiting in the queue.
+	statQueriesEnqueued        = "queriesEnqueued" // Number of queries that have been queued.
+	statQueueRejected          = "queueRejected"   // Number of queries rejected because the queue was full.
+	statQueueTimeouts          = "queueTimeouts"   // Number of queries that timed out in the queue.
+	statQueueDuration          = "queueDurationNs" // Total (wall) time queries spent in the queue.
+	statSlowQueries            = "slowQueries"     // Number of queries that exceeded the slow query log threshold.
 
 	// PanicCrashEnv is the environment variable that, when set, will prevent
 	// the handler from recovering any panics.
@@ -57,9 +67,37 @@ const (
 // ErrDatabaseNotFound returns a database not found error for the given database name.
 func ErrDatabaseNotFound(name string) error { return fmt.Errorf("database not found: %s", name) }
 
+// Names of the limits of SELECT statements.
+const (
+	LimitMaxSelectPoint   = "max-select-point"
+	LimitMaxSelectSeries  = "max-select-series"
+	LimitMaxSelectBuckets = "max-select-buckets"
+)
+
+// LimitError is an error when a query hits one of the limits of SELECT
+// statements.
+type LimitError struct {
+	// Limit is the name of the limit, such as LimitMaxSelectPoint.
+	Limit string
+
+	msg string
+}
+
+func (e *LimitError) Error() string { return e.msg }
+
 // ErrMaxSelectPointsLimitExceeded is an error when a query hits the maximum number of points.
 func ErrMaxSelectPointsLimitExceeded(n, limit int) error {
-	return fmt.Errorf("max-select-point limit exceeed: (%d/%d)", n, limit)
+	return &LimitError{Limit: LimitMaxSelectPoint, msg: fmt.Sprintf("max-select-point limit exceeed: (%d/%d)", n, limit)}
+}
+
+// ErrMaxSelectSeriesLimitExceeded is an error when a query hits the maximum number of series.
+func ErrMaxSelectSeriesLimitExceeded(n, limit int) error {
+	return &LimitError{Limit: LimitMaxSelectSeries, msg: fmt.Sprintf("max-select-series limit exceeded: (%d/%d)", n, limit)}
+}
+
+// ErrMaxSelectBucketsLimitExceeded is an error when a query hits the maximum number of GROUP BY time buckets.
+func ErrMaxSelectBucketsLimitExceeded(n, limit int) error {
+	return &LimitError{Limit: LimitMaxSelectBuckets, msg: fmt.Sprintf("max-select-buckets limit exceeded: (%d/%d)", n, limit)}
 }
 
 // ErrMaxConcurrentQueriesLimitExceeded is an error when a query cannot be run
@@ -68,6 +106,12 @@ func ErrMaxConcurrentQueriesLimitExceeded(n, limit int) error {
 	return fmt.Errorf("max-concurrent-queries limit exceeded(%d, %d)", n, limit)
 }
 
+// ErrQuotaLimitExceeded returns the error of a limit that was set by a query
+// quota annotated with the name of the quota.
+func ErrQuotaLimitExceeded(err error, quota string) error {
+	return fmt.Errorf("%s: set by query quota for %s", err, quota)
+}
+
 // CoarseAuthorizer determines if certain operations are authorized at the database level.
 //
 // It is supported both in OSS and Enterprise.
@@ -153,10 +197,42 @@ type ExecutionOptions struct {
 	// Quiet suppresses non-essential output from the query executor.
 	Quiet bool
 
+	// NoCache bypasses the query result cache, if one is configured.
+	NoCache bool
+
+	// ExplainJSON returns the plan of EXPLAIN ANALYZE as JSON instead of text.
+	ExplainJSON bool
+
+	// The name of the user running the query, if authentication is enabled.
+	UserID string
+
+	// The address and User-Agent of the client that sent the query.
+	ClientAddr string
+	UserAgent  string
+
+	// The quotas of the user and database of the query. If both are nil,
+	// the TaskManager looks them up when the query is attached.
+	UserQuota     *Quota
+	DatabaseQuota *Quota
+
+	// Priority of the query when it waits in the queue of the TaskManager.
+	Priority QueryPriority
+
 	// AbortCh is a channel that signals when results are no longer desired by the caller.
 	AbortCh <-chan struct{}
 }
 
+// QueryPriority is the order in which queued queries are run.
+type QueryPriority int
+
+const (
+	// NormalPriority is the priority of queries by default.
+	NormalPriority QueryPriority = iota
+
+	// HighPriority queries are run before any queued query with NormalPriority.
+	HighPriority
+)
+
 type (
 	iteratorsContextKey struct{}
 	monitorContextKey   struct{}
@@ -227,6 +303,12 @@ func (e *Executor) Statistics(tags map[string]string) []models.Statistic {
 			statQueriesFinished:        atomic.LoadInt64(&e.stats.FinishedQueries),
 			statQueryExecutionDuration: atomic.LoadInt64(&e.stats.QueryExecutionDuration),
 			statRecoveredPanics:        atomic.LoadInt64(&e.stats.RecoveredPanics),
+			statQueriesQueued:          atomic.LoadInt64(&e.TaskManager.stats.QueuedQueries),
+			statQueriesEnqueued:        atomic.LoadInt64(&e.TaskManager.stats.EnqueuedQueries),
+			statQueueRejected:          atomic.LoadInt64(&e.TaskManager.stats.RejectedQueries),
+			statQueueTimeouts:          atomic.LoadInt64(&e.TaskManager.stats.QueueTimeouts),
+			statQueueDuration:          atomic.LoadInt64(&e.TaskManager.stats.QueueDuration),
+			statSlowQueries:            atomic.LoadInt64(&e.TaskManager.stats.SlowQueries),
 		},
 	}}
 }
@@ -422,14 +504,22 @@ func (e *Executor) recover(query *influxql.Query, results chan *Result) {
 // Task is the internal data structure for managing queries.
 // For the public use data structure that gets returned, see Task.
 type Task struct {
-	query     string
-	database  string
-	status    TaskStatus
-	startTime time.Time
-	closing   chan struct{}
-	monitorCh chan error
-	err       error
-	mu        sync.Mutex
+	query      string
+	database   string
+	user       string
+	clientAddr string
+	userAgent  string
+	status     TaskStatus
+	startTime  time.Time
+	closing    chan struct{}
+	monitorCh  chan error
+	err        error
+	mu         sync.Mutex
+
+	// Statistics of the SELECT statements of the query.
+	pointN  int64
+	seriesN int64
+	shardN  int64
 }
 
 // Monitor starts a new goroutine that will monitor a query. The function
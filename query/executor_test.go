@@ -1,8 +1,12 @@
 package query_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -21,6 +25,12 @@ func (e *StatementExecutor) ExecuteStatement(ctx *query.ExecutionContext, stmt i
 	return e.ExecuteStatementFn(stmt, ctx)
 }
 
+type QuotaProviderFunc func(user, database string) (*query.Quota, *query.Quota)
+
+func (fn QuotaProviderFunc) QueryQuotas(user, database string) (*query.Quota, *query.Quota) {
+	return fn(user, database)
+}
+
 func NewQueryExecutor() *query.Executor {
 	return query.NewExecutor()
 }
@@ -405,6 +415,284 @@ func TestQueryExecutor_Limit_ConcurrentQueries(t *testing.T) {
 	}
 }
 
+func TestQueryExecutor_Limit_EnqueuedQueries(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qid := make(chan uint64)
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx *query.ExecutionContext) error {
+			qid <- ctx.QueryID
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	e.TaskManager.MaxConcurrentQueries = 1
+	e.TaskManager.MaxEnqueuedQueries = 1
+	defer e.Close()
+
+	// Start first query and wait for it to be executing.
+	go discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{}, nil))
+	first := <-qid
+
+	// Start second query and wait for it to be queued.
+	go discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{}, nil))
+	waitForQueuedQueries(t, e, 1)
+
+	// The queue is full so the third query fails.
+	result := <-e.ExecuteQuery(q, query.ExecutionOptions{}, nil)
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "max-concurrent-queries") {
+		t.Errorf("unexpected error: %s", result.Err)
+	}
+
+	// The second query runs once the first one is killed.
+	if err := e.TaskManager.KillQuery(first); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-qid:
+	case <-time.After(time.Second):
+		t.Fatal("queued query was not executed")
+	}
+
+	stats := e.Statistics(nil)[0].Values
+	if stats["queriesQueued"] != int64(0) || stats["queriesEnqueued"] != int64(1) || stats["queueRejected"] != int64(1) {
+		t.Errorf("unexpected statistics: %v", stats)
+	}
+}
+
+func TestQueryExecutor_Limit_EnqueuedQueryTimeout(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qid := make(chan uint64)
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx *query.ExecutionContext) error {
+			qid <- ctx.QueryID
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	e.TaskManager.MaxConcurrentQueries = 1
+	e.TaskManager.MaxEnqueuedQueries = 1
+	e.TaskManager.EnqueuedQueryTimeout = time.Millisecond
+	defer e.Close()
+
+	go discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{}, nil))
+	<-qid
+
+	select {
+	case result := <-e.ExecuteQuery(q, query.ExecutionOptions{}, nil):
+		if result.Err != query.ErrEnqueuedQueryTimeoutLimitExceeded {
+			t.Errorf("unexpected error: %s", result.Err)
+		}
+	case <-qid:
+		t.Errorf("unexpected statement execution for the second query")
+	}
+}
+
+func TestQueryExecutor_Limit_EnqueuedQueriesPriority(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	users := make(chan string)
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx *query.ExecutionContext) error {
+			users <- ctx.UserID
+			<-done
+			return nil
+		},
+	}
+	e.TaskManager.MaxConcurrentQueries = 1
+	e.TaskManager.MaxEnqueuedQueries = 3
+	e.TaskManager.HighPriorityUsers = []string{"admin"}
+	defer e.Close()
+
+	go discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{UserID: "first"}, nil))
+	if user := <-users; user != "first" {
+		t.Fatalf("unexpected user: %s", user)
+	}
+
+	// Queue the queries one at a time so their order is known.
+	for i, opt := range []query.ExecutionOptions{
+		{UserID: "normal"},
+		{UserID: "admin"},
+		{UserID: "cq", Priority: query.HighPriority},
+	} {
+		go discardOutput(e.ExecuteQuery(q, opt, nil))
+		waitForQueuedQueries(t, e, int64(i+1))
+	}
+
+	// High priority queries run first in the order they were queued.
+	for _, want := range []string{"admin", "cq", "normal"} {
+		done <- struct{}{}
+		if user := <-users; user != want {
+			t.Fatalf("unexpected user: got %s, want %s", user, want)
+		}
+	}
+	close(done)
+}
+
+// waitForQueuedQueries waits until n queries are waiting in the queue.
+func waitForQueuedQueries(t *testing.T, e *query.Executor, n int64) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		if e.Statistics(nil)[0].Values["queriesQueued"] == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d queued queries", n)
+}
+
+func TestQueryExecutor_Limit_ConcurrentQueriesQuota(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qid := make(chan uint64)
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx *query.ExecutionContext) error {
+			qid <- ctx.QueryID
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	e.TaskManager.Quotas = QuotaProviderFunc(func(user, database string) (*query.Quota, *query.Quota) {
+		if user == "alice" {
+			return &query.Quota{MaxConcurrentQueries: 1}, nil
+		}
+		return nil, nil
+	})
+	defer e.Close()
+
+	// Start a query for the user and wait for it to be executing.
+	go discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{UserID: "alice"}, nil))
+	<-qid
+
+	// Queries of other users are not limited by the quota.
+	go discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{UserID: "bob"}, nil))
+	<-qid
+
+	// A second query of the user is rejected.
+	results := e.ExecuteQuery(q, query.ExecutionOptions{UserID: "alice"}, nil)
+	select {
+	case result := <-results:
+		if result.Err == nil || !strings.Contains(result.Err.Error(), `max-concurrent-queries limit exceeded(1, 1): set by query quota for user "alice"`) {
+			t.Errorf("unexpected error: %s", result.Err)
+		}
+	case <-qid:
+		t.Errorf("unexpected statement execution for the second query")
+	}
+}
+
+func TestQueryExecutor_Limit_TimeoutQuota(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx *query.ExecutionContext) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+				t.Errorf("timeout has not killed the query")
+				return errUnexpected
+			}
+		},
+	}
+	e.TaskManager.QueryTimeout = time.Hour
+	e.TaskManager.Quotas = QuotaProviderFunc(func(user, database string) (*query.Quota, *query.Quota) {
+		return &query.Quota{QueryTimeout: time.Hour}, &query.Quota{QueryTimeout: time.Nanosecond}
+	})
+
+	results := e.ExecuteQuery(q, query.ExecutionOptions{UserID: "alice", Database: "db0"}, nil)
+	result := <-results
+	if result.Err == nil || result.Err.Error() != `query-timeout limit exceeded: set by query quota for database "db0"` {
+		t.Errorf("unexpected error: %s", result.Err)
+	}
+}
+
+func TestQueryExecutor_SlowQueryLog(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "slow_query_log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "slow.log")
+
+	slowQueryLog, err := query.NewSlowQueryLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slowQueryLog.Threshold = time.Millisecond
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx *query.ExecutionContext) error {
+			if ctx.Database == "slow" {
+				time.Sleep(10 * time.Millisecond)
+			}
+			ctx.AddSelectStats(query.IteratorStats{SeriesN: 2, PointN: 10}, 3)
+			return nil
+		},
+	}
+	e.TaskManager.SlowQueryLog = slowQueryLog
+
+	discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{Database: "fast"}, nil))
+	discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{Database: "slow", UserID: "alice", ClientAddr: "127.0.0.1", UserAgent: "curl"}, nil))
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("unexpected records: %s", b)
+	}
+
+	var rec query.SlowQueryRecord
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Query != q.String() || rec.Database != "slow" || rec.User != "alice" || rec.ClientAddr != "127.0.0.1" || rec.UserAgent != "curl" {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+	if rec.PointN != 10 || rec.SeriesN != 2 || rec.ShardN != 3 || rec.DurationNs < int64(10*time.Millisecond) {
+		t.Errorf("unexpected record statistics: %+v", rec)
+	}
+	if n := e.Statistics(nil)[0].Values["slowQueries"]; n != int64(1) {
+		t.Errorf("unexpected slow queries: %v", n)
+	}
+}
+
 func TestQueryExecutor_Close(t *testing.T) {
 	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
 	if err != nil {
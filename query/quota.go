@@ -0,0 +1,63 @@
+package query
+
+import (
+	"fmt"
+	"time"
+)
+
+// Quota overrides the global query limits for the queries of a single user
+// or database. A limit that is zero is not set by the quota.
+type Quota struct {
+	// MaxConcurrentQueries is the maximum number of queries of the user or
+	// database that may run at the same time. It is enforced in addition
+	// to the global max-concurrent-queries limit.
+	MaxConcurrentQueries int
+
+	// QueryTimeout replaces the global query-timeout limit.
+	QueryTimeout time.Duration
+
+	// MaxSelectPointN, MaxSelectSeriesN and MaxSelectBucketsN replace the
+	// global max-select-point, max-select-series and max-select-buckets
+	// limits.
+	MaxSelectPointN   int
+	MaxSelectSeriesN  int
+	MaxSelectBucketsN int
+}
+
+// IsZero returns true if the quota does not set any limit.
+func (q *Quota) IsZero() bool {
+	return q == nil || *q == Quota{}
+}
+
+// QuotaProvider looks up the query quotas of users and databases.
+type QuotaProvider interface {
+	// QueryQuotas returns the quotas of the named user and database.
+	// Either quota is nil if it has not been set.
+	QueryQuotas(user, database string) (userQuota, databaseQuota *Quota)
+}
+
+// QuotaLimit returns the value of a limit for the query. The limit set by the
+// user or database quota replaces the global limit and the smaller of the two
+// applies if both quotas set it. The name of the quota that set the limit is
+// returned with it and is empty if the global limit applies.
+func (opt *ExecutionOptions) QuotaLimit(global int64, limit func(q *Quota) int64) (int64, string) {
+	n, name := global, ""
+	if opt.UserQuota != nil {
+		if v := limit(opt.UserQuota); v > 0 {
+			n, name = v, fmt.Sprintf("user %q", opt.UserID)
+		}
+	}
+	if opt.DatabaseQuota != nil {
+		if v := limit(opt.DatabaseQuota); v > 0 && (name == "" || v < n) {
+			n, name = v, fmt.Sprintf("database %q", opt.Database)
+		}
+	}
+	return n, name
+}
+
+func (q *Quota) maxConcurrentQueries() int {
+	if q == nil {
+		return 0
+	}
+	return q.MaxConcurrentQueries
+}
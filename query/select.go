@@ -39,6 +39,13 @@ type SelectOptions struct {
 
 	// Maximum number of buckets for a statement.
 	MaxBucketsN int
+
+	// NoCache bypasses the query result cache, if one is configured.
+	NoCache bool
+
+	// ShardN, if set, is incremented by the number of shards that are mapped
+	// for the statement.
+	ShardN *int64
 }
 
 // ShardMapper retrieves and maps shards into an IteratorCreator that can later be
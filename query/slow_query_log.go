@@ -0,0 +1,106 @@
+package query
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultSlowQueryLogSampleRate is the default fraction of slow queries that
+// are written to the slow query log.
+const DefaultSlowQueryLogSampleRate = 1.0
+
+// SlowQueryLog records the queries that run longer than a threshold. Records
+// are written as JSON lines to a file or, if no file is set, to the logger of
+// the TaskManager.
+type SlowQueryLog struct {
+	// Minimum duration of the queries that are recorded.
+	Threshold time.Duration
+
+	// Fraction of the slow queries that are recorded, between 0 and 1.
+	SampleRate float64
+
+	mu sync.Mutex
+	w  io.WriteCloser
+}
+
+// NewSlowQueryLog returns a new SlowQueryLog that appends to the file at
+// path. If path is empty, records are written to the logger.
+func NewSlowQueryLog(path string) (*SlowQueryLog, error) {
+	l := &SlowQueryLog{SampleRate: DefaultSlowQueryLogSampleRate}
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+		if err != nil {
+			return nil, err
+		}
+		l.w = f
+	}
+	return l, nil
+}
+
+// SlowQueryRecord is a record of the slow query log.
+type SlowQueryRecord struct {
+	Time       time.Time `json:"time"`
+	QueryID    uint64    `json:"query_id"`
+	Query      string    `json:"query"`
+	Database   string    `json:"database,omitempty"`
+	User       string    `json:"user,omitempty"`
+	DurationNs int64     `json:"duration_ns"`
+	PointN     int64     `json:"points"`
+	SeriesN    int64     `json:"series"`
+	ShardN     int64     `json:"shards"`
+	ClientAddr string    `json:"client_addr,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+}
+
+// sample returns true if a slow query is recorded.
+func (l *SlowQueryLog) sample() bool {
+	return l.SampleRate >= 1 || rand.Float64() < l.SampleRate
+}
+
+// write writes the record to the file or, if there is none, to logger.
+func (l *SlowQueryLog) write(rec *SlowQueryRecord, logger *zap.Logger) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.w == nil {
+		logger.Warn("Slow query",
+			zap.Uint64("query_id", rec.QueryID),
+			zap.String("query", rec.Query),
+			zap.String("database", rec.Database),
+			zap.String("user", rec.User),
+			zap.Duration("duration", time.Duration(rec.DurationNs)),
+			zap.Int64("points", rec.PointN),
+			zap.Int64("series", rec.SeriesN),
+			zap.Int64("shards", rec.ShardN),
+			zap.String("client_addr", rec.ClientAddr),
+			zap.String("user_agent", rec.UserAgent))
+		return nil
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	_, err = l.w.Write(b)
+	return err
+}
+
+// Close closes the file of the slow query log.
+func (l *SlowQueryLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.w == nil {
+		return nil
+	}
+	err := l.w.Close()
+	l.w = nil
+	return err
+}
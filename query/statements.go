@@ -0,0 +1,530 @@
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxql"
+)
+
+// statement implements the unexported methods of influxql.Statement for the
+// statements defined in this package. Its methods are never called: the
+// statements implement String and RequiredPrivileges themselves.
+type statement struct {
+	influxql.Statement
+}
+
+// identParsers holds the parsers of the statements defined in this package,
+// by the token starting the statement and the keyword following it. The
+// keywords are scanned as identifiers, since influxql doesn't know them.
+var identParsers = make(map[influxql.Token]map[string]func(p *influxql.Parser) (influxql.Statement, error))
+
+// handleIdent registers the parser of the statements starting with tok and
+// keyword.
+func handleIdent(tok influxql.Token, keyword string, fn func(p *influxql.Parser) (influxql.Statement, error)) {
+	parsers, ok := identParsers[tok]
+	if !ok {
+		parsers = make(map[string]func(p *influxql.Parser) (influxql.Statement, error))
+		identParsers[tok] = parsers
+
+		tree := influxql.Language.Group(tok)
+		keys := append([]string(nil), tree.Keys...)
+		tree.Handle(influxql.IDENT, func(p *influxql.Parser) (influxql.Statement, error) {
+			p.Unscan()
+			_, pos, lit := p.ScanIgnoreWhitespace()
+			if fn, ok := parsers[strings.ToUpper(lit)]; ok {
+				return fn(p)
+			}
+
+			expected := append([]string(nil), keys...)
+			for keyword := range parsers {
+				expected = append(expected, keyword)
+			}
+			sort.Strings(expected[len(keys):])
+			return nil, &influxql.ParseError{Found: lit, Expected: expected, Pos: pos}
+		})
+	}
+	parsers[keyword] = fn
+}
+
+// scanKeyword consumes the keyword, which influxql scans as an identifier.
+func scanKeyword(p *influxql.Parser, keyword string) error {
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok != influxql.IDENT || !strings.EqualFold(lit, keyword) {
+		return &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{keyword}, Pos: pos}
+	}
+	return nil
+}
+
+// scanOptionalKeyword consumes the keyword and returns true if it is the
+// next token.
+func scanOptionalKeyword(p *influxql.Parser, keyword string) bool {
+	if tok, _, lit := p.ScanIgnoreWhitespace(); tok == influxql.IDENT && strings.EqualFold(lit, keyword) {
+		return true
+	}
+	p.Unscan()
+	return false
+}
+
+// scanToken consumes the token.
+func scanToken(p *influxql.Parser, expected influxql.Token) error {
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != expected {
+		return &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{expected.String()}, Pos: pos}
+	}
+	return nil
+}
+
+// scanOptionalToken consumes the token and returns true if it is the next
+// token.
+func scanOptionalToken(p *influxql.Parser, expected influxql.Token) bool {
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == expected {
+		return true
+	}
+	p.Unscan()
+	return false
+}
+
+// parseIdentOrString parses an identifier or a string.
+func parseIdentOrString(p *influxql.Parser) (string, error) {
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok != influxql.IDENT && tok != influxql.STRING {
+		return "", &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"identifier", "string"}, Pos: pos}
+	}
+	return lit, nil
+}
+
+func tokstr(tok influxql.Token, lit string) string {
+	if lit != "" {
+		return lit
+	}
+	return tok.String()
+}
+
+// adminPrivileges are the privileges of the statements requiring an admin.
+var adminPrivileges = influxql.ExecutionPrivileges{{Admin: true, Name: "", Privilege: influxql.AllPrivileges}}
+
+// CreateTokenStatement represents a command for creating an API token of a
+// user.
+type CreateTokenStatement struct {
+	statement
+
+	// User is the name of the user of the token.
+	User string
+
+	// Scopes are the scopes of the token, such as "read:db0" or "admin".
+	Scopes []string
+
+	// Expires is the time after which the token expires, or zero if the
+	// token never expires.
+	Expires time.Duration
+}
+
+// String returns a string representation of the create token statement.
+func (s *CreateTokenStatement) String() string {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("CREATE TOKEN FOR ")
+	_, _ = buf.WriteString(influxql.QuoteIdent(s.User))
+	_, _ = buf.WriteString(" WITH SCOPES ")
+	for i, scope := range s.Scopes {
+		if i > 0 {
+			_, _ = buf.WriteString(", ")
+		}
+		if i := strings.IndexByte(scope, ':'); i >= 0 {
+			_, _ = buf.WriteString(scope[:i+1])
+			_, _ = buf.WriteString(influxql.QuoteIdent(scope[i+1:]))
+		} else {
+			_, _ = buf.WriteString(scope)
+		}
+	}
+	if s.Expires != 0 {
+		_, _ = buf.WriteString(" EXPIRES ")
+		_, _ = buf.WriteString(influxql.FormatDuration(s.Expires))
+	}
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute a
+// CreateTokenStatement.
+func (s *CreateTokenStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return adminPrivileges, nil
+}
+
+// parseCreateTokenStatement parses a string and returns a create token
+// statement. This function assumes the "CREATE TOKEN" tokens have already
+// been consumed.
+func parseCreateTokenStatement(p *influxql.Parser) (influxql.Statement, error) {
+	stmt := &CreateTokenStatement{}
+
+	if err := scanToken(p, influxql.FOR); err != nil {
+		return nil, err
+	}
+	user, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.User = user
+
+	if err := scanToken(p, influxql.WITH); err != nil {
+		return nil, err
+	} else if err := scanKeyword(p, "SCOPES"); err != nil {
+		return nil, err
+	}
+	for {
+		scope, err := parseTokenScope(p)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Scopes = append(stmt.Scopes, scope)
+		if !scanOptionalToken(p, influxql.COMMA) {
+			break
+		}
+	}
+
+	if scanOptionalKeyword(p, "EXPIRES") {
+		d, err := p.ParseDuration()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Expires = d
+	}
+	return stmt, nil
+}
+
+// parseTokenScope parses a scope such as read:db0 or admin, or a string
+// holding the scope.
+func parseTokenScope(p *influxql.Parser) (string, error) {
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	switch tok {
+	case influxql.STRING, influxql.IDENT:
+		return lit, nil
+	case influxql.READ, influxql.WRITE:
+		if err := scanToken(p, influxql.COLON); err != nil {
+			return "", err
+		}
+		db, err := p.ParseIdent()
+		if err != nil {
+			return "", err
+		}
+		return strings.ToLower(tok.String()) + ":" + db, nil
+	}
+	return "", &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"scope"}, Pos: pos}
+}
+
+// ShowTokensStatement represents a command for listing the API tokens.
+type ShowTokensStatement struct {
+	statement
+
+	// User limits the tokens to the tokens of the user, if set.
+	User string
+}
+
+// String returns a string representation of the show tokens statement.
+func (s *ShowTokensStatement) String() string {
+	if s.User == "" {
+		return "SHOW TOKENS"
+	}
+	return "SHOW TOKENS FOR " + influxql.QuoteIdent(s.User)
+}
+
+// RequiredPrivileges returns the privilege required to execute a
+// ShowTokensStatement.
+func (s *ShowTokensStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return adminPrivileges, nil
+}
+
+// parseShowTokensStatement parses a string and returns a show tokens
+// statement. This function assumes the "SHOW TOKENS" tokens have already been
+// consumed.
+func parseShowTokensStatement(p *influxql.Parser) (influxql.Statement, error) {
+	stmt := &ShowTokensStatement{}
+	if scanOptionalToken(p, influxql.FOR) {
+		user, err := p.ParseIdent()
+		if err != nil {
+			return nil, err
+		}
+		stmt.User = user
+	}
+	return stmt, nil
+}
+
+// DropTokenStatement represents a command for removing an API token.
+type DropTokenStatement struct {
+	statement
+
+	// ID is the ID of the token.
+	ID string
+}
+
+// String returns a string representation of the drop token statement.
+func (s *DropTokenStatement) String() string {
+	return "DROP TOKEN " + influxql.QuoteString(s.ID)
+}
+
+// RequiredPrivileges returns the privilege required to execute a
+// DropTokenStatement.
+func (s *DropTokenStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return adminPrivileges, nil
+}
+
+// parseDropTokenStatement parses a string and returns a drop token
+// statement. This function assumes the "DROP TOKEN" tokens have already been
+// consumed.
+func parseDropTokenStatement(p *influxql.Parser) (influxql.Statement, error) {
+	id, err := parseIdentOrString(p)
+	if err != nil {
+		return nil, err
+	}
+	return &DropTokenStatement{ID: id}, nil
+}
+
+// ShowQuotasStatement represents a command for listing the write quotas of
+// the databases and their usage.
+type ShowQuotasStatement struct {
+	statement
+
+	// Database limits the quotas to the quota of the database, if set.
+	Database string
+}
+
+// String returns a string representation of the show quotas statement.
+func (s *ShowQuotasStatement) String() string {
+	if s.Database == "" {
+		return "SHOW QUOTAS"
+	}
+	return "SHOW QUOTAS ON " + influxql.QuoteIdent(s.Database)
+}
+
+// RequiredPrivileges returns the privilege required to execute a
+// ShowQuotasStatement.
+func (s *ShowQuotasStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return adminPrivileges, nil
+}
+
+// parseShowQuotasStatement parses a string and returns a show quotas
+// statement. This function assumes the "SHOW QUOTAS" tokens have already been
+// consumed.
+func parseShowQuotasStatement(p *influxql.Parser) (influxql.Statement, error) {
+	stmt := &ShowQuotasStatement{}
+	if scanOptionalToken(p, influxql.ON) {
+		db, err := p.ParseIdent()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Database = db
+	}
+	return stmt, nil
+}
+
+// AlterDatabaseRenameStatement represents a command for renaming a database.
+type AlterDatabaseRenameStatement struct {
+	statement
+
+	// Name is the current name of the database.
+	Name string
+
+	// NewName is the name the database is renamed to.
+	NewName string
+}
+
+// String returns a string representation of the alter database statement.
+func (s *AlterDatabaseRenameStatement) String() string {
+	return "ALTER DATABASE " + influxql.QuoteIdent(s.Name) + " RENAME TO " + influxql.QuoteIdent(s.NewName)
+}
+
+// RequiredPrivileges returns the privilege required to execute an
+// AlterDatabaseRenameStatement.
+func (s *AlterDatabaseRenameStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return adminPrivileges, nil
+}
+
+// parseAlterDatabaseStatement parses a string and returns an alter database
+// statement. This function assumes the "ALTER DATABASE" tokens have already
+// been consumed.
+func parseAlterDatabaseStatement(p *influxql.Parser) (influxql.Statement, error) {
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	newName, err := parseRenameTo(p)
+	if err != nil {
+		return nil, err
+	}
+	return &AlterDatabaseRenameStatement{Name: name, NewName: newName}, nil
+}
+
+// AlterRetentionPolicyRenameStatement represents a command for renaming a
+// retention policy.
+type AlterRetentionPolicyRenameStatement struct {
+	statement
+
+	// Database is the database of the retention policy.
+	Database string
+
+	// Name is the current name of the retention policy.
+	Name string
+
+	// NewName is the name the retention policy is renamed to.
+	NewName string
+}
+
+// String returns a string representation of the alter retention policy
+// statement.
+func (s *AlterRetentionPolicyRenameStatement) String() string {
+	return "ALTER RETENTION POLICY " + influxql.QuoteIdent(s.Name) + " ON " + influxql.QuoteIdent(s.Database) +
+		" RENAME TO " + influxql.QuoteIdent(s.NewName)
+}
+
+// RequiredPrivileges returns the privilege required to execute an
+// AlterRetentionPolicyRenameStatement.
+func (s *AlterRetentionPolicyRenameStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return adminPrivileges, nil
+}
+
+// parseAlterRetentionPolicyStatement parses a string and returns an alter
+// retention policy statement. It replaces the influxql parser to accept
+// RENAME TO in place of the options, and otherwise returns an
+// influxql.AlterRetentionPolicyStatement. This function assumes the "ALTER
+// RETENTION POLICY" tokens have already been consumed.
+func parseAlterRetentionPolicyStatement(p *influxql.Parser) (influxql.Statement, error) {
+	var name string
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok == influxql.DEFAULT {
+		name = "default"
+	} else if tok == influxql.IDENT {
+		name = lit
+	} else {
+		return nil, &influxql.ParseError{Found: tokstr(tok, lit), Expected: []string{"identifier"}, Pos: pos}
+	}
+
+	if err := scanToken(p, influxql.ON); err != nil {
+		return nil, err
+	}
+	database, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok, _, lit := p.ScanIgnoreWhitespace(); tok == influxql.IDENT && strings.EqualFold(lit, "RENAME") {
+		p.Unscan()
+		newName, err := parseRenameTo(p)
+		if err != nil {
+			return nil, err
+		}
+		return &AlterRetentionPolicyRenameStatement{Database: database, Name: name, NewName: newName}, nil
+	}
+	p.Unscan()
+
+	// Parse the options as influxql does.
+	stmt := &influxql.AlterRetentionPolicyStatement{Name: name, Database: database}
+	found := make(map[influxql.Token]struct{})
+Loop:
+	for {
+		tok, pos, lit := p.ScanIgnoreWhitespace()
+		if _, ok := found[tok]; ok {
+			return nil, &influxql.ParseError{
+				Message: fmt.Sprintf("found duplicate %s option", tok),
+				Pos:     pos,
+			}
+		}
+
+		switch tok {
+		case influxql.DURATION:
+			d, err := p.ParseDuration()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Duration = &d
+		case influxql.REPLICATION:
+			n, err := p.ParseInt(1, math.MaxInt32)
+			if err != nil {
+				return nil, err
+			}
+			stmt.Replication = &n
+		case influxql.SHARD:
+			if err := scanToken(p, influxql.DURATION); err != nil {
+				return nil, err
+			}
+			if tok, pos, _ := p.ScanIgnoreWhitespace(); tok == influxql.INF {
+				return nil, &influxql.ParseError{
+					Message: "invalid duration INF for shard duration",
+					Pos:     pos,
+				}
+			}
+			p.Unscan()
+
+			d, err := p.ParseDuration()
+			if err != nil {
+				return nil, err
+			}
+			stmt.ShardGroupDuration = &d
+		case influxql.DEFAULT:
+			stmt.Default = true
+		default:
+			if len(found) == 0 {
+				return nil, &influxql.ParseError{
+					Found:    tokstr(tok, lit),
+					Expected: []string{"DURATION", "REPLICATION", "SHARD", "DEFAULT", "RENAME"},
+					Pos:      pos,
+				}
+			}
+			p.Unscan()
+			break Loop
+		}
+		found[tok] = struct{}{}
+	}
+	return stmt, nil
+}
+
+// parseRenameTo parses the "RENAME TO <name>" clause of the alter statements
+// and returns the new name.
+func parseRenameTo(p *influxql.Parser) (string, error) {
+	if err := scanKeyword(p, "RENAME"); err != nil {
+		return "", err
+	} else if err := scanToken(p, influxql.TO); err != nil {
+		return "", err
+	}
+	return p.ParseIdent()
+}
+
+// ShowMetaHistoryStatement represents a command for listing the versions of
+// the meta data kept for rollbacks.
+type ShowMetaHistoryStatement struct {
+	statement
+}
+
+// String returns a string representation of the show meta history
+// statement.
+func (s *ShowMetaHistoryStatement) String() string {
+	return "SHOW META HISTORY"
+}
+
+// RequiredPrivileges returns the privilege required to execute a
+// ShowMetaHistoryStatement.
+func (s *ShowMetaHistoryStatement) RequiredPrivileges() (influxql.ExecutionPrivileges, error) {
+	return adminPrivileges, nil
+}
+
+// parseShowMetaHistoryStatement parses a string and returns a show meta
+// history statement. This function assumes the "SHOW META" tokens have
+// already been consumed.
+func parseShowMetaHistoryStatement(p *influxql.Parser) (influxql.Statement, error) {
+	if err := scanKeyword(p, "HISTORY"); err != nil {
+		return nil, err
+	}
+	return &ShowMetaHistoryStatement{}, nil
+}
+
+func init() {
+	handleIdent(influxql.CREATE, "TOKEN", parseCreateTokenStatement)
+	handleIdent(influxql.SHOW, "TOKENS", parseShowTokensStatement)
+	handleIdent(influxql.DROP, "TOKEN", parseDropTokenStatement)
+	handleIdent(influxql.SHOW, "QUOTAS", parseShowQuotasStatement)
+	handleIdent(influxql.SHOW, "META", parseShowMetaHistoryStatement)
+
+	influxql.Language.Group(influxql.ALTER).Handle(influxql.DATABASE, parseAlterDatabaseStatement)
+	influxql.Language.Group(influxql.ALTER, influxql.RETENTION).Handlers[influxql.POLICY] = parseAlterRetentionPolicyStatement
+}
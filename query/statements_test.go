@@ -0,0 +1,87 @@
+package query_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxql"
+)
+
+func TestParseStatement(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		stmt influxql.Statement
+		str  string
+	}{
+		{
+			s:    `CREATE TOKEN FOR bob WITH SCOPES read:db0,write:"my-db", admin EXPIRES 30d`,
+			stmt: &query.CreateTokenStatement{User: "bob", Scopes: []string{"read:db0", "write:my-db", "admin"}, Expires: 30 * 24 * time.Hour},
+			str:  `CREATE TOKEN FOR bob WITH SCOPES read:db0, write:"my-db", admin EXPIRES 30d`,
+		},
+		{
+			s:    `create token for bob with scopes 'read:db0'`,
+			stmt: &query.CreateTokenStatement{User: "bob", Scopes: []string{"read:db0"}},
+			str:  `CREATE TOKEN FOR bob WITH SCOPES read:db0`,
+		},
+		{s: `SHOW TOKENS`, stmt: &query.ShowTokensStatement{}},
+		{s: `SHOW TOKENS FOR bob`, stmt: &query.ShowTokensStatement{User: "bob"}},
+		{s: `DROP TOKEN '0a1b'`, stmt: &query.DropTokenStatement{ID: "0a1b"}},
+		{s: `DROP TOKEN "0a1b"`, stmt: &query.DropTokenStatement{ID: "0a1b"}, str: `DROP TOKEN '0a1b'`},
+		{s: `SHOW QUOTAS`, stmt: &query.ShowQuotasStatement{}},
+		{s: `SHOW QUOTAS ON db0`, stmt: &query.ShowQuotasStatement{Database: "db0"}},
+		{s: `SHOW META HISTORY`, stmt: &query.ShowMetaHistoryStatement{}},
+		{s: `ALTER DATABASE db0 RENAME TO "db-1"`, stmt: &query.AlterDatabaseRenameStatement{Name: "db0", NewName: "db-1"}},
+		{
+			s:    `alter retention policy "default" on db0 rename to rp1`,
+			stmt: &query.AlterRetentionPolicyRenameStatement{Database: "db0", Name: "default", NewName: "rp1"},
+			str:  `ALTER RETENTION POLICY "default" ON db0 RENAME TO rp1`,
+		},
+		{
+			s:    `ALTER RETENTION POLICY rp0 ON db0 DURATION 1h SHARD DURATION 30m DEFAULT`,
+			stmt: &influxql.AlterRetentionPolicyStatement{Name: "rp0", Database: "db0", Duration: durationPtr(time.Hour), ShardGroupDuration: durationPtr(30 * time.Minute), Default: true},
+			str:  `ALTER RETENTION POLICY rp0 ON db0 DURATION 1h SHARD DURATION 30m DEFAULT`,
+		},
+	} {
+		stmt, err := influxql.ParseStatement(tt.s)
+		if err != nil {
+			t.Errorf("%s: %s", tt.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(stmt, tt.stmt) {
+			t.Errorf("%s: unexpected statement: %#v", tt.s, stmt)
+		}
+		str := tt.str
+		if str == "" {
+			str = tt.s
+		}
+		if got := stmt.String(); got != str {
+			t.Errorf("%s: unexpected string: %s", tt.s, got)
+		}
+	}
+}
+
+func TestParseStatement_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		s   string
+		err string
+	}{
+		{s: `CREATE TOKEN FOR bob`, err: `found EOF, expected WITH at line 1, char 22`},
+		{s: `CREATE TOKEN FOR bob WITH SCOPES`, err: `found EOF, expected scope at line 1, char 34`},
+		{s: `CREATE TOKEN FOR bob WITH SCOPES read`, err: `found EOF, expected : at line 1, char 39`},
+		{s: `DROP TOKEN`, err: `found EOF, expected identifier, string at line 1, char 12`},
+		{s: `SHOW QUOTAS ON`, err: `found EOF, expected identifier at line 1, char 16`},
+		{s: `SHOW META`, err: `found EOF, expected HISTORY at line 1, char 11`},
+		{s: `ALTER DATABASE db0`, err: `found EOF, expected RENAME at line 1, char 20`},
+		{s: `ALTER DATABASE db0 RENAME db1`, err: `found db1, expected TO at line 1, char 27`},
+		{s: `ALTER RETENTION POLICY rp0 ON db0`, err: `found EOF, expected DURATION, REPLICATION, SHARD, DEFAULT, RENAME at line 1, char 35`},
+		{s: `ALTER RETENTION POLICY rp0 ON db0 DEFAULT DEFAULT`, err: `found duplicate DEFAULT option at line 1, char 43`},
+	} {
+		if _, err := influxql.ParseStatement(tt.s); err == nil || err.Error() != tt.err {
+			t.Errorf("%s: unexpected error: %v", tt.s, err)
+		}
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration { return &d }
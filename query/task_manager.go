@@ -5,7 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/influxdb/models"
@@ -71,6 +73,25 @@ type TaskManager struct {
 	// Maximum number of concurrent queries.
 	MaxConcurrentQueries int
 
+	// Maximum number of queries waiting for one of the MaxConcurrentQueries
+	// to finish. If zero, queries are rejected once MaxConcurrentQueries
+	// are running.
+	MaxEnqueuedQueries int
+
+	// Maximum amount of time a query can wait in the queue.
+	// If zero, queries wait until they can run or are interrupted.
+	EnqueuedQueryTimeout time.Duration
+
+	// Users whose queries run with HighPriority.
+	HighPriorityUsers []string
+
+	// Records the queries that run longer than its threshold, if set.
+	SlowQueryLog *SlowQueryLog
+
+	// Quotas looks up the query quotas of users and databases.
+	// If nil, only the global limits apply.
+	Quotas QuotaProvider
+
 	// Logger to use for all logging.
 	// Defaults to discarding all log output.
 	Logger *zap.Logger
@@ -80,6 +101,30 @@ type TaskManager struct {
 	nextID   uint64
 	mu       sync.RWMutex
 	shutdown bool
+
+	// Queries waiting to run ordered by priority and the number of queries
+	// that have left the queue but have not been attached yet.
+	queue     []*queuedQuery
+	admitting int
+	stats     taskManagerStatistics
+}
+
+// queuedQuery is a query waiting in the queue of the TaskManager.
+type queuedQuery struct {
+	priority QueryPriority
+	ready    chan struct{}
+	admitted bool
+}
+
+// taskManagerStatistics keeps statistics related to the query queue and the
+// slow query log.
+type taskManagerStatistics struct {
+	QueuedQueries   int64
+	EnqueuedQueries int64
+	RejectedQueries int64
+	QueueTimeouts   int64
+	QueueDuration   int64
+	SlowQueries     int64
 }
 
 // NewTaskManager creates a new TaskManager.
@@ -171,6 +216,10 @@ func (t *TaskManager) queryError(qid uint64, err error) {
 //
 // After a query finishes running, the system is free to reuse a query id.
 func (t *TaskManager) AttachQuery(q *influxql.Query, opt ExecutionOptions, interrupt <-chan struct{}) (*ExecutionContext, func(), error) {
+	if t.Quotas != nil && opt.UserQuota == nil && opt.DatabaseQuota == nil {
+		opt.UserQuota, opt.DatabaseQuota = t.Quotas.QueryQuotas(opt.UserID, opt.Database)
+	}
+
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -178,22 +227,40 @@ func (t *TaskManager) AttachQuery(q *influxql.Query, opt ExecutionOptions, inter
 		return nil, nil, ErrQueryEngineShutdown
 	}
 
-	if t.MaxConcurrentQueries > 0 && len(t.queries) >= t.MaxConcurrentQueries {
-		return nil, nil, ErrMaxConcurrentQueriesLimitExceeded(len(t.queries), t.MaxConcurrentQueries)
+	// Wait in the queue if all slots are taken or other queries are already
+	// waiting for one.
+	if t.MaxConcurrentQueries > 0 && (len(t.queries)+t.admitting >= t.MaxConcurrentQueries || len(t.queue) > 0) {
+		if err := t.enqueue(&opt, interrupt); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := t.checkConcurrentQuotas(&opt); err != nil {
+		// Hand the slot of this query to the next query in the queue.
+		t.dequeue()
+		return nil, nil, err
+	}
+
+	timeout, quota := opt.QuotaLimit(int64(t.QueryTimeout), func(q *Quota) int64 { return int64(q.QueryTimeout) })
+	timeoutErr := ErrQueryTimeoutLimitExceeded
+	if quota != "" {
+		timeoutErr = ErrQuotaLimitExceeded(timeoutErr, quota)
 	}
 
 	qid := t.nextID
 	query := &Task{
-		query:     q.String(),
-		database:  opt.Database,
-		status:    RunningTask,
-		startTime: time.Now(),
-		closing:   make(chan struct{}),
-		monitorCh: make(chan error),
+		query:      q.String(),
+		database:   opt.Database,
+		user:       opt.UserID,
+		clientAddr: opt.ClientAddr,
+		userAgent:  opt.UserAgent,
+		status:     RunningTask,
+		startTime:  time.Now(),
+		closing:    make(chan struct{}),
+		monitorCh:  make(chan error),
 	}
 	t.queries[qid] = query
 
-	go t.waitForQuery(qid, query.closing, interrupt, query.monitorCh)
+	go t.waitForQuery(qid, query.closing, interrupt, query.monitorCh, time.Duration(timeout), timeoutErr)
 	if t.LogQueriesAfter != 0 {
 		go query.monitor(func(closing <-chan struct{}) error {
 			timer := time.NewTimer(t.LogQueriesAfter)
@@ -220,6 +287,126 @@ func (t *TaskManager) AttachQuery(q *influxql.Query, opt ExecutionOptions, inter
 	return ctx, func() { t.DetachQuery(qid) }, nil
 }
 
+// enqueue waits in the queue until the query can run. The caller must hold
+// the lock, which is released while waiting.
+func (t *TaskManager) enqueue(opt *ExecutionOptions, interrupt <-chan struct{}) error {
+	if len(t.queue) >= t.MaxEnqueuedQueries {
+		if t.MaxEnqueuedQueries > 0 {
+			atomic.AddInt64(&t.stats.RejectedQueries, 1)
+		}
+		return ErrMaxConcurrentQueriesLimitExceeded(len(t.queries), t.MaxConcurrentQueries)
+	}
+
+	// Queue the query behind all queries with the same or a higher priority.
+	q := &queuedQuery{priority: t.priority(opt), ready: make(chan struct{})}
+	i := sort.Search(len(t.queue), func(i int) bool { return t.queue[i].priority < q.priority })
+	t.queue = append(t.queue, nil)
+	copy(t.queue[i+1:], t.queue[i:])
+	t.queue[i] = q
+
+	atomic.AddInt64(&t.stats.QueuedQueries, 1)
+	atomic.AddInt64(&t.stats.EnqueuedQueries, 1)
+	defer func(start time.Time) {
+		atomic.AddInt64(&t.stats.QueuedQueries, -1)
+		atomic.AddInt64(&t.stats.QueueDuration, time.Since(start).Nanoseconds())
+	}(time.Now())
+
+	t.mu.Unlock()
+	var timerCh <-chan time.Time
+	if t.EnqueuedQueryTimeout > 0 {
+		timer := time.NewTimer(t.EnqueuedQueryTimeout)
+		defer timer.Stop()
+		timerCh = timer.C
+	}
+
+	var err error
+	select {
+	case <-q.ready:
+	case <-timerCh:
+		atomic.AddInt64(&t.stats.QueueTimeouts, 1)
+		err = ErrEnqueuedQueryTimeoutLimitExceeded
+	case <-interrupt:
+		err = ErrQueryInterrupted
+	case <-opt.AbortCh:
+		err = ErrQueryAborted
+	}
+	t.mu.Lock()
+
+	if !q.admitted {
+		for i := range t.queue {
+			if t.queue[i] == q {
+				t.queue = append(t.queue[:i], t.queue[i+1:]...)
+				break
+			}
+		}
+	} else {
+		t.admitting--
+		if err != nil {
+			// The query was admitted, but gave up before it noticed.
+			t.dequeue()
+		}
+	}
+
+	if t.shutdown {
+		return ErrQueryEngineShutdown
+	}
+	return err
+}
+
+// dequeue admits the queries at the front of the queue while there are free
+// slots. The caller must hold the lock.
+func (t *TaskManager) dequeue() {
+	for len(t.queue) > 0 && len(t.queries)+t.admitting < t.MaxConcurrentQueries {
+		q := t.queue[0]
+		t.queue = t.queue[1:]
+		q.admitted = true
+		t.admitting++
+		close(q.ready)
+	}
+}
+
+// priority returns the priority of a query.
+func (t *TaskManager) priority(opt *ExecutionOptions) QueryPriority {
+	if opt.UserID != "" {
+		for _, name := range t.HighPriorityUsers {
+			if name == opt.UserID {
+				return HighPriority
+			}
+		}
+	}
+	return opt.Priority
+}
+
+// checkConcurrentQuotas returns an error if the user or database of a query
+// already has the number of running queries allowed by its quota. The caller
+// must hold the lock.
+func (t *TaskManager) checkConcurrentQuotas(opt *ExecutionOptions) error {
+	if limit := opt.UserQuota.maxConcurrentQueries(); limit > 0 && opt.UserID != "" {
+		var n int
+		for _, query := range t.queries {
+			if query.user == opt.UserID {
+				n++
+			}
+		}
+		if n >= limit {
+			return ErrQuotaLimitExceeded(ErrMaxConcurrentQueriesLimitExceeded(n, limit), fmt.Sprintf("user %q", opt.UserID))
+		}
+	}
+
+	if limit := opt.DatabaseQuota.maxConcurrentQueries(); limit > 0 && opt.Database != "" {
+		var n int
+		for _, query := range t.queries {
+			if query.database == opt.Database {
+				n++
+			}
+		}
+		if n >= limit {
+			return ErrQuotaLimitExceeded(ErrMaxConcurrentQueriesLimitExceeded(n, limit), fmt.Sprintf("database %q", opt.Database))
+		}
+	}
+	return nil
+}
+
 // KillQuery enters a query into the killed state and closes the channel
 // from the TaskManager. This method can be used to forcefully terminate a
 // running query.
@@ -238,18 +425,54 @@ func (t *TaskManager) KillQuery(qid uint64) error {
 // killed state, this will also close the related channel.
 func (t *TaskManager) DetachQuery(qid uint64) error {
 	t.mu.Lock()
-	defer t.mu.Unlock()
-
 	query := t.queries[qid]
 	if query == nil {
+		t.mu.Unlock()
 		return fmt.Errorf("no such query id: %d", qid)
 	}
 
 	query.close()
 	delete(t.queries, qid)
+	t.dequeue()
+	t.mu.Unlock()
+
+	// Write the slow query record once the lock is released.
+	if t.SlowQueryLog != nil {
+		t.logSlowQuery(qid, query)
+	}
 	return nil
 }
 
+// logSlowQuery records the query in the slow query log if it ran longer than
+// the threshold and is sampled.
+func (t *TaskManager) logSlowQuery(qid uint64, query *Task) {
+	d := time.Since(query.startTime)
+	if d < t.SlowQueryLog.Threshold {
+		return
+	}
+	atomic.AddInt64(&t.stats.SlowQueries, 1)
+	if !t.SlowQueryLog.sample() {
+		return
+	}
+
+	rec := &SlowQueryRecord{
+		Time:       query.startTime.UTC(),
+		QueryID:    qid,
+		Query:      query.query,
+		Database:   query.database,
+		User:       query.user,
+		DurationNs: d.Nanoseconds(),
+		PointN:     atomic.LoadInt64(&query.pointN),
+		SeriesN:    atomic.LoadInt64(&query.seriesN),
+		ShardN:     atomic.LoadInt64(&query.shardN),
+		ClientAddr: query.clientAddr,
+		UserAgent:  query.userAgent,
+	}
+	if err := t.SlowQueryLog.write(rec, t.Logger); err != nil {
+		t.Logger.Info("Failed to write to the slow query log", zap.Error(err))
+	}
+}
+
 // QueryInfo represents the information for a query.
 type QueryInfo struct {
 	ID       uint64        `json:"id"`
@@ -278,10 +501,10 @@ func (t *TaskManager) Queries() []QueryInfo {
 	return queries
 }
 
-func (t *TaskManager) waitForQuery(qid uint64, interrupt <-chan struct{}, closing <-chan struct{}, monitorCh <-chan error) {
+func (t *TaskManager) waitForQuery(qid uint64, interrupt <-chan struct{}, closing <-chan struct{}, monitorCh <-chan error, timeout time.Duration, timeoutErr error) {
 	var timerCh <-chan time.Time
-	if t.QueryTimeout != 0 {
-		timer := time.NewTimer(t.QueryTimeout)
+	if timeout != 0 {
+		timer := time.NewTimer(timeout)
 		timerCh = timer.C
 		defer timer.Stop()
 	}
@@ -296,7 +519,7 @@ func (t *TaskManager) waitForQuery(qid uint64, interrupt <-chan struct{}, closin
 
 		t.queryError(qid, err)
 	case <-timerCh:
-		t.queryError(qid, ErrQueryTimeoutLimitExceeded)
+		t.queryError(qid, timeoutErr)
 	case <-interrupt:
 		// Query was manually closed so exit the select.
 		return
@@ -315,5 +538,15 @@ func (t *TaskManager) Close() error {
 		query.close()
 	}
 	t.queries = nil
+
+	// Wake up the queued queries so they return with an error.
+	for _, q := range t.queue {
+		close(q.ready)
+	}
+	t.queue = nil
+
+	if t.SlowQueryLog != nil {
+		return t.SlowQueryLog.Close()
+	}
 	return nil
 }
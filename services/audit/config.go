@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"errors"
+
+	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/toml"
+)
+
+const (
+	// DefaultPath is the default path of the audit log.
+	DefaultPath = "/var/lib/influxdb/audit.log"
+
+	// DefaultMaxSize is the default size of the audit log at which it is
+	// rotated.
+	DefaultMaxSize = 100 * 1024 * 1024
+
+	// DefaultMaxBackups is the default number of rotated audit logs kept.
+	DefaultMaxBackups = 10
+
+	// DefaultStoreDatabase is the default database the records are written to
+	// when storing is enabled.
+	DefaultStoreDatabase = "_audit"
+)
+
+// Config represents the configuration for the audit service.
+type Config struct {
+	Enabled bool   `toml:"enabled"`
+	Path    string `toml:"path"`
+
+	// HMACKey is the key of the HMACs chaining the records.
+	HMACKey string `toml:"hmac-key"`
+
+	// MaxSize is the size of the audit log at which it is rotated.
+	MaxSize toml.Size `toml:"max-size"`
+
+	// MaxBackups is the number of rotated audit logs kept, or 0 to keep all.
+	MaxBackups int `toml:"max-backups"`
+
+	// StoreEnabled also writes the records to StoreDatabase.
+	StoreEnabled  bool   `toml:"store-enabled"`
+	StoreDatabase string `toml:"store-database"`
+}
+
+// NewConfig returns an instance of Config with defaults.
+func NewConfig() Config {
+	return Config{
+		Path:          DefaultPath,
+		MaxSize:       DefaultMaxSize,
+		MaxBackups:    DefaultMaxBackups,
+		StoreDatabase: DefaultStoreDatabase,
+	}
+}
+
+// Validate returns an error if the Config is invalid.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Path == "" {
+		return errors.New("audit path must not be empty")
+	}
+	if c.HMACKey == "" {
+		return errors.New("audit hmac-key must not be empty")
+	}
+	if c.MaxSize <= 0 {
+		return errors.New("audit max-size must be positive")
+	}
+	if c.MaxBackups < 0 {
+		return errors.New("audit max-backups must not be negative")
+	}
+	if c.StoreEnabled && c.StoreDatabase == "" {
+		return errors.New("audit store database name must not be empty")
+	}
+	return nil
+}
+
+// Diagnostics returns a diagnostics representation of a subset of the Config.
+func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
+	if !c.Enabled {
+		return diagnostics.RowFromMap(map[string]interface{}{
+			"enabled": false,
+		}), nil
+	}
+
+	return diagnostics.RowFromMap(map[string]interface{}{
+		"enabled":        true,
+		"path":           c.Path,
+		"max-size":       c.MaxSize,
+		"max-backups":    c.MaxBackups,
+		"store-enabled":  c.StoreEnabled,
+		"store-database": c.StoreDatabase,
+	}), nil
+}
@@ -0,0 +1,71 @@
+package audit_test
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/influxdata/influxdb/services/audit"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	// Parse configuration.
+	var c audit.Config
+	if _, err := toml.Decode(`
+enabled = true
+path = "/tmp/audit.log"
+hmac-key = "secret"
+max-size = "10m"
+max-backups = 3
+store-enabled = true
+store-database = "_audit"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate configuration.
+	if !c.Enabled {
+		t.Fatalf("unexpected enabled state: %v", c.Enabled)
+	} else if c.Path != "/tmp/audit.log" {
+		t.Fatalf("unexpected path: %s", c.Path)
+	} else if c.HMACKey != "secret" {
+		t.Fatalf("unexpected hmac key: %s", c.HMACKey)
+	} else if c.MaxSize != 10<<20 {
+		t.Fatalf("unexpected max size: %d", c.MaxSize)
+	} else if c.MaxBackups != 3 {
+		t.Fatalf("unexpected max backups: %d", c.MaxBackups)
+	} else if !c.StoreEnabled || c.StoreDatabase != "_audit" {
+		t.Fatalf("unexpected store: %v %s", c.StoreEnabled, c.StoreDatabase)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := audit.NewConfig()
+	c.Enabled = true
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for empty hmac-key, got nil")
+	}
+
+	c.HMACKey = "secret"
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validation fail: %s", err)
+	}
+
+	c.MaxSize = 0
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for max-size = 0, got nil")
+	}
+
+	c = audit.NewConfig()
+	c.Enabled = true
+	c.HMACKey = "secret"
+	c.StoreEnabled = true
+	c.StoreDatabase = ""
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for empty store-database, got nil")
+	}
+
+	c.Enabled = false
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validation fail from disabled config: %s", err)
+	}
+}
@@ -0,0 +1,504 @@
+// Package audit records the administrative and data-destructive operations,
+// and the refused requests, in a hash-chained log.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+	"go.uber.org/zap"
+)
+
+const (
+	// KindStatement is the kind of the records of executed statements.
+	KindStatement = "statement"
+
+	// KindMeta is the kind of the records of mutations of the meta data.
+	KindMeta = "meta"
+
+	// KindAccess is the kind of the records of failed authentications and
+	// of requests refused for lack of privileges.
+	KindAccess = "access"
+
+	// OutcomeSuccess and OutcomeFailure are the outcomes of the operations.
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// storeMeasurement is the measurement the records are written to when storing
+// is enabled.
+const storeMeasurement = "audit"
+
+// storeBufferSize is the number of records waiting to be stored before new
+// records are dropped from the store. They are still written to the log.
+const storeBufferSize = 1000
+
+// Record is a record of the audit log. Each record holds the hash of the
+// previous record, so removing or changing a record breaks the chain. The
+// hashes are HMACs keyed with the configured key, so the chain cannot be
+// rebuilt without it.
+type Record struct {
+	Time       time.Time `json:"time"`
+	Kind       string    `json:"kind"`
+	User       string    `json:"user,omitempty"`
+	ClientAddr string    `json:"client_addr,omitempty"`
+	Database   string    `json:"database,omitempty"`
+	Statement  string    `json:"statement,omitempty"`
+	Operation  string    `json:"operation,omitempty"`
+	Objects    []string  `json:"objects,omitempty"`
+	Outcome    string    `json:"outcome"`
+	Error      string    `json:"error,omitempty"`
+	PrevHash   string    `json:"prev_hash"`
+	Hash       string    `json:"hash"`
+}
+
+// hash returns the HMAC of the record chained to the previous record.
+func (rec Record) hash(key []byte) (string, error) {
+	rec.Hash = ""
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return "", err
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(rec.PrevHash))
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Service writes the audit log to a rotating file and, if storing is enabled,
+// to a database. The records are written to the database by the points
+// writer and never go through the query path.
+type Service struct {
+	MetaClient interface {
+		Database(name string) *meta.DatabaseInfo
+		CreateDatabase(name string) (*meta.DatabaseInfo, error)
+	}
+
+	PointsWriter interface {
+		WritePoints(database, retentionPolicy string, points models.Points) error
+	}
+
+	Logger *zap.Logger
+	config Config
+
+	mu       sync.Mutex
+	f        *os.File
+	size     int64
+	lastHash string
+	points   chan models.Point
+
+	wg      sync.WaitGroup
+	closing chan struct{}
+
+	// storeCreated is only accessed by the store goroutine.
+	storeCreated bool
+
+	now func() time.Time
+}
+
+// NewService returns a new audit service.
+func NewService(c Config) *Service {
+	return &Service{
+		config: c,
+		Logger: zap.NewNop(),
+		now:    time.Now,
+	}
+}
+
+// WithLogger sets the logger on the service.
+func (s *Service) WithLogger(log *zap.Logger) {
+	s.Logger = log.With(zap.String("service", "audit"))
+}
+
+// Open opens the audit log and continues the hash chain of its last record.
+func (s *Service) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f != nil {
+		return nil
+	}
+
+	lastHash, err := s.readLastHash()
+	if err != nil {
+		return fmt.Errorf("read audit log: %s", err)
+	}
+	if err := s.openFile(); err != nil {
+		return err
+	}
+	s.lastHash = lastHash
+
+	if s.config.StoreEnabled {
+		s.points = make(chan models.Point, storeBufferSize)
+		s.closing = make(chan struct{})
+		s.wg.Add(1)
+		go s.store(s.points, s.closing)
+	}
+
+	s.Logger.Info("Opened audit log", zap.String("path", s.config.Path))
+	return nil
+}
+
+// Close closes the audit log.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	if s.closing != nil {
+		close(s.closing)
+		s.closing = nil
+		s.points = nil
+	}
+	var err error
+	if s.f != nil {
+		err = s.f.Close()
+		s.f = nil
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+// AuditStatement records an administrative or data-destructive statement and
+// its outcome. Other statements are ignored.
+func (s *Service) AuditStatement(ctx *query.ExecutionContext, stmt influxql.Statement, err error) {
+	objects, ok := statementObjects(stmt, ctx.Database)
+	if !ok {
+		return
+	}
+	s.Log(Record{
+		Kind:       KindStatement,
+		User:       ctx.UserID,
+		ClientAddr: ctx.ClientAddr,
+		Database:   ctx.Database,
+		Statement:  statementString(stmt),
+		Objects:    objects,
+		Outcome:    outcome(err),
+		Error:      errorString(err),
+	})
+}
+
+// AuditMetaChange records a mutation of the meta data by user from the client
+// address addr and its outcome.
+func (s *Service) AuditMetaChange(user, addr, operation string, objects []string, err error) {
+	s.Log(Record{
+		Kind:       KindMeta,
+		User:       user,
+		ClientAddr: addr,
+		Operation:  operation,
+		Objects:    objects,
+		Outcome:    outcome(err),
+		Error:      errorString(err),
+	})
+}
+
+// AuditAccessDenied records a request to an endpoint refused because its
+// authentication failed or user lacks the privileges, with the reason.
+func (s *Service) AuditAccessDenied(user, addr, operation, endpoint, reason string) {
+	s.Log(Record{
+		Kind:       KindAccess,
+		User:       user,
+		ClientAddr: addr,
+		Operation:  operation,
+		Objects:    []string{"endpoint:" + endpoint},
+		Outcome:    OutcomeFailure,
+		Error:      reason,
+	})
+}
+
+// Log chains a record to the previous record and writes it. Records logged
+// while the service is closed are dropped.
+func (s *Service) Log(rec Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return
+	}
+
+	if rec.Time.IsZero() {
+		rec.Time = s.now().UTC()
+	}
+	rec.PrevHash = s.lastHash
+
+	hash, err := rec.hash([]byte(s.config.HMACKey))
+	if err != nil {
+		s.Logger.Error("Failed to hash audit record", zap.Error(err))
+		return
+	}
+	rec.Hash = hash
+	b, err := json.Marshal(rec)
+	if err != nil {
+		s.Logger.Error("Failed to encode audit record", zap.Error(err))
+		return
+	}
+	b = append(b, '\n')
+
+	if s.size > 0 && s.size+int64(len(b)) > int64(s.config.MaxSize) {
+		if err := s.rotate(); err != nil {
+			s.Logger.Error("Failed to rotate audit log", zap.Error(err))
+		}
+		if s.f == nil {
+			return
+		}
+	}
+	n, err := s.f.Write(b)
+	s.size += int64(n)
+	if err != nil {
+		s.Logger.Error("Failed to write audit record", zap.Error(err))
+		return
+	}
+	s.lastHash = rec.Hash
+
+	if s.points != nil {
+		p, err := recordPoint(&rec)
+		if err != nil {
+			s.Logger.Info("Failed to create audit point", zap.Error(err))
+			return
+		}
+		select {
+		case s.points <- p:
+		default:
+			s.Logger.Info("Audit store buffer full, dropping record from store", logger.Database(s.config.StoreDatabase))
+		}
+	}
+}
+
+// openFile opens the audit log for appending.
+func (s *Service) openFile() error {
+	f, err := os.OpenFile(s.config.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.f, s.size = f, fi.Size()
+	return nil
+}
+
+// rotate renames the audit log with the time of the rotation, opens a new
+// log and removes the oldest rotated logs beyond MaxBackups.
+func (s *Service) rotate() error {
+	if err := s.f.Close(); err != nil {
+		return err
+	}
+	s.f = nil
+
+	backup := s.config.Path + "." + s.now().UTC().Format("20060102T150405.000000000")
+	if err := os.Rename(s.config.Path, backup); err != nil {
+		// Keep appending to the current log rather than losing records.
+		if oerr := s.openFile(); oerr != nil {
+			return oerr
+		}
+		return err
+	}
+	if err := s.openFile(); err != nil {
+		return err
+	}
+
+	if s.config.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := s.backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > s.config.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// backups returns the paths of the rotated logs, from the oldest to the
+// newest.
+func (s *Service) backups() ([]string, error) {
+	paths, err := filepath.Glob(s.config.Path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// readLastHash returns the hash of the last record of the audit log, or of the
+// newest rotated log if the audit log is empty.
+func (s *Service) readLastHash() (string, error) {
+	paths, err := s.backups()
+	if err != nil {
+		return "", err
+	}
+	paths = append(paths, s.config.Path)
+	for i := len(paths) - 1; i >= 0; i-- {
+		hash, err := readLastHash(paths[i])
+		if err != nil {
+			return "", err
+		} else if hash != "" {
+			return hash, nil
+		}
+	}
+	return "", nil
+}
+
+// readLastHash returns the hash of the last record of a log, or an empty
+// string if the log is empty or doesn't exist.
+func readLastHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var last []byte
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			last = append(last[:0], line...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	} else if last == nil {
+		return "", nil
+	}
+
+	var rec Record
+	if err := json.Unmarshal(last, &rec); err != nil {
+		return "", fmt.Errorf("%s: invalid last record: %s", path, err)
+	}
+	return rec.Hash, nil
+}
+
+// Verify verifies the hash chain of the records of an audit log with the HMAC
+// key of the log. The first record must be chained to prevHash, which is the
+// hash of the last record of the previous log or empty for the first log. It
+// returns the hash of the last record.
+func Verify(r io.Reader, key []byte, prevHash string) (string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return "", fmt.Errorf("line %d: %s", n, err)
+		} else if rec.PrevHash != prevHash {
+			return "", fmt.Errorf("line %d: record is not chained to the previous record", n)
+		}
+		hash, err := rec.hash(key)
+		if err != nil {
+			return "", fmt.Errorf("line %d: %s", n, err)
+		} else if !hmac.Equal([]byte(hash), []byte(rec.Hash)) {
+			return "", fmt.Errorf("line %d: record hash mismatch", n)
+		}
+		prevHash = rec.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return prevHash, nil
+}
+
+// store writes the records to the store database until closing is closed.
+func (s *Service) store(points <-chan models.Point, closing <-chan struct{}) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-closing:
+			return
+		case p := <-points:
+			batch := models.Points{p}
+		drain:
+			for len(batch) < storeBufferSize {
+				select {
+				case p := <-points:
+					batch = append(batch, p)
+				default:
+					break drain
+				}
+			}
+			s.writePoints(batch)
+		}
+	}
+}
+
+// writePoints writes records to the store database, creating it if needed.
+func (s *Service) writePoints(points models.Points) {
+	if !s.storeCreated {
+		if di := s.MetaClient.Database(s.config.StoreDatabase); di == nil {
+			if _, err := s.MetaClient.CreateDatabase(s.config.StoreDatabase); err != nil {
+				s.Logger.Info("Failed to create storage", logger.Database(s.config.StoreDatabase), zap.Error(err))
+				return
+			}
+		}
+		s.storeCreated = true
+	}
+
+	if err := s.PointsWriter.WritePoints(s.config.StoreDatabase, "", points); err != nil {
+		s.Logger.Info("Failed to store audit records", logger.Database(s.config.StoreDatabase), zap.Error(err))
+	}
+}
+
+// recordPoint returns the point storing a record.
+func recordPoint(rec *Record) (models.Point, error) {
+	tags := map[string]string{"kind": rec.Kind, "outcome": rec.Outcome}
+	if rec.User != "" {
+		tags["user"] = rec.User
+	}
+	fields := map[string]interface{}{
+		"objects":   strings.Join(rec.Objects, ","),
+		"hash":      rec.Hash,
+		"prev_hash": rec.PrevHash,
+	}
+	for k, v := range map[string]string{
+		"client_addr": rec.ClientAddr,
+		"database":    rec.Database,
+		"statement":   rec.Statement,
+		"operation":   rec.Operation,
+		"error":       rec.Error,
+	} {
+		if v != "" {
+			fields[k] = v
+		}
+	}
+	return models.NewPoint(storeMeasurement, models.NewTags(tags), fields, rec.Time)
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
+
+func errorString(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
@@ -0,0 +1,280 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/audit"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+)
+
+func TestService_AuditStatement(t *testing.T) {
+	s := NewService(t, audit.NewConfig())
+	defer s.Close()
+
+	ctx := &query.ExecutionContext{Context: context.Background()}
+	ctx.Database = "db0"
+	ctx.UserID = "admin"
+	ctx.ClientAddr = "10.0.0.1"
+
+	s.AuditStatement(ctx, MustParseStatement(`SELECT * FROM cpu`), nil)
+	s.AuditStatement(ctx, MustParseStatement(`DROP SERIES FROM cpu WHERE host = 'a'`), nil)
+	s.AuditStatement(ctx, MustParseStatement(`CREATE USER bob WITH PASSWORD 'secret'`), errors.New("user already exists"))
+
+	records := s.Records(t)
+	if len(records) != 2 {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+
+	if rec := records[0]; rec.Kind != audit.KindStatement || rec.User != "admin" || rec.ClientAddr != "10.0.0.1" || rec.Database != "db0" {
+		t.Fatalf("unexpected record: %+v", rec)
+	} else if rec.Statement != `DROP SERIES FROM cpu WHERE host = 'a'` {
+		t.Fatalf("unexpected statement: %s", rec.Statement)
+	} else if len(rec.Objects) != 1 || rec.Objects[0] != "measurement:db0.cpu" {
+		t.Fatalf("unexpected objects: %v", rec.Objects)
+	} else if rec.Outcome != audit.OutcomeSuccess || rec.PrevHash != "" {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+
+	if rec := records[1]; strings.Contains(rec.Statement, "secret") {
+		t.Fatalf("password not redacted: %s", rec.Statement)
+	} else if rec.Outcome != audit.OutcomeFailure || rec.Error != "user already exists" {
+		t.Fatalf("unexpected outcome: %+v", rec)
+	} else if rec.PrevHash != records[0].Hash {
+		t.Fatal("record not chained to the previous record")
+	}
+}
+
+// Ensure the hash chain continues across restarts and detects changes.
+func TestService_Verify(t *testing.T) {
+	c := audit.NewConfig()
+	s := NewService(t, c)
+	s.AuditMetaChange("admin", "10.0.0.1", "drop database", []string{"database:db0"}, nil)
+	s.Close()
+
+	s = NewService(t, s.Config)
+	s.AuditMetaChange("admin", "10.0.0.1", "drop user", []string{"user:bob"}, nil)
+	s.Close()
+
+	buf, err := ioutil.ReadFile(s.Config.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := s.Records(t)
+	if len(records) != 2 {
+		t.Fatalf("unexpected records: %+v", records)
+	} else if hash, err := audit.Verify(bytes.NewReader(buf), []byte(s.Config.HMACKey), ""); err != nil {
+		t.Fatal(err)
+	} else if hash != records[1].Hash {
+		t.Fatalf("unexpected last hash: %s", hash)
+	} else if rec := records[0]; rec.User != "admin" || rec.ClientAddr != "10.0.0.1" {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+
+	// The chain cannot be verified, or rebuilt, without the key.
+	if _, err := audit.Verify(bytes.NewReader(buf), []byte("other"), ""); err == nil || err.Error() != "line 1: record hash mismatch" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Change the first record.
+	buf = bytes.Replace(buf, []byte("database:db0"), []byte("database:db1"), 1)
+	if _, err := audit.Verify(bytes.NewReader(buf), []byte(s.Config.HMACKey), ""); err == nil || err.Error() != "line 1: record hash mismatch" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Remove the first record.
+	buf = buf[bytes.IndexByte(buf, '\n')+1:]
+	if _, err := audit.Verify(bytes.NewReader(buf), []byte(s.Config.HMACKey), ""); err == nil || err.Error() != "line 1: record is not chained to the previous record" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestService_AuditAccessDenied(t *testing.T) {
+	s := NewService(t, audit.NewConfig())
+	defer s.Close()
+
+	s.AuditAccessDenied("bob", "10.0.0.1", "authorize", "/query", "error authorizing query")
+
+	records := s.Records(t)
+	if len(records) != 1 {
+		t.Fatalf("unexpected records: %+v", records)
+	} else if rec := records[0]; rec.Kind != audit.KindAccess || rec.User != "bob" || rec.ClientAddr != "10.0.0.1" || rec.Operation != "authorize" {
+		t.Fatalf("unexpected record: %+v", rec)
+	} else if len(rec.Objects) != 1 || rec.Objects[0] != "endpoint:/query" {
+		t.Fatalf("unexpected objects: %v", rec.Objects)
+	} else if rec.Outcome != audit.OutcomeFailure || rec.Error != "error authorizing query" {
+		t.Fatalf("unexpected outcome: %+v", rec)
+	}
+}
+
+func TestService_Rotate(t *testing.T) {
+	c := audit.NewConfig()
+	c.MaxSize = 1
+	c.MaxBackups = 2
+	s := NewService(t, c)
+
+	for i := 0; i < 5; i++ {
+		s.AuditMetaChange("", "", "drop shard", []string{"shard:1"}, nil)
+	}
+	s.Close()
+
+	backups, err := filepath.Glob(s.Config.Path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(backups) != 2 {
+		t.Fatalf("unexpected backups: %v", backups)
+	}
+
+	// The chain continues from the oldest backup into the current log.
+	paths := append(backups, s.Config.Path)
+	var hash string
+	for i, path := range paths {
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			var rec audit.Record
+			if err := json.Unmarshal(buf[:bytes.IndexByte(buf, '\n')], &rec); err != nil {
+				t.Fatal(err)
+			}
+			hash = rec.PrevHash
+		}
+		if hash, err = audit.Verify(bytes.NewReader(buf), []byte(s.Config.HMACKey), hash); err != nil {
+			t.Fatalf("%s: %s", path, err)
+		}
+	}
+}
+
+func TestService_Store(t *testing.T) {
+	c := audit.NewConfig()
+	c.StoreEnabled = true
+	s := NewService(t, c)
+
+	var mu sync.Mutex
+	var created string
+	var written models.Points
+	done := make(chan struct{}, 1)
+	s.MetaClient = &MetaClient{
+		DatabaseFn: func(name string) *meta.DatabaseInfo { return nil },
+		CreateDatabaseFn: func(name string) (*meta.DatabaseInfo, error) {
+			mu.Lock()
+			created = name
+			mu.Unlock()
+			return &meta.DatabaseInfo{Name: name}, nil
+		},
+	}
+	s.PointsWriter = PointsWriterFunc(func(database, rp string, points models.Points) error {
+		mu.Lock()
+		written = append(written, points...)
+		mu.Unlock()
+		done <- struct{}{}
+		return nil
+	})
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.AuditMetaChange("admin", "10.0.0.1", "drop database", []string{"database:db0"}, nil)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the record to be stored")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if created != audit.DefaultStoreDatabase {
+		t.Fatalf("unexpected database created: %q", created)
+	} else if len(written) != 1 || string(written[0].Name()) != "audit" {
+		t.Fatalf("unexpected points: %v", written)
+	} else if v := written[0].Tags().GetString("outcome"); v != audit.OutcomeSuccess {
+		t.Fatalf("unexpected outcome tag: %q", v)
+	}
+}
+
+// Service is a test wrapper for audit.Service.
+type Service struct {
+	*audit.Service
+	Config audit.Config
+}
+
+// NewService returns an opened audit service writing to a temporary
+// directory, unless the service stores records.
+func NewService(t *testing.T, c audit.Config) *Service {
+	c.Enabled = true
+	if c.HMACKey == "" {
+		c.HMACKey = "secret"
+	}
+	if c.Path == audit.DefaultPath {
+		c.Path = filepath.Join(t.TempDir(), "audit.log")
+	}
+	s := &Service{Service: audit.NewService(c), Config: c}
+	if c.StoreEnabled {
+		return s
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// Records returns the records of the current audit log.
+func (s *Service) Records(t *testing.T) []audit.Record {
+	buf, err := ioutil.ReadFile(s.Config.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []audit.Record
+	for _, line := range bytes.Split(bytes.TrimSpace(buf), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var rec audit.Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+// MetaClient is a mock of the meta client of the audit service.
+type MetaClient struct {
+	DatabaseFn       func(name string) *meta.DatabaseInfo
+	CreateDatabaseFn func(name string) (*meta.DatabaseInfo, error)
+}
+
+func (c *MetaClient) Database(name string) *meta.DatabaseInfo { return c.DatabaseFn(name) }
+
+func (c *MetaClient) CreateDatabase(name string) (*meta.DatabaseInfo, error) {
+	return c.CreateDatabaseFn(name)
+}
+
+// PointsWriterFunc is a points writer calling a function.
+type PointsWriterFunc func(database, rp string, points models.Points) error
+
+func (fn PointsWriterFunc) WritePoints(database, rp string, points models.Points) error {
+	return fn(database, rp, points)
+}
+
+// MustParseStatement parses a statement or panics.
+func MustParseStatement(s string) influxql.Statement {
+	stmt, err := influxql.ParseStatement(s)
+	if err != nil {
+		panic(err)
+	}
+	return stmt
+}
@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"strconv"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+)
+
+// statementObjects returns the objects affected by an administrative or
+// data-destructive statement, and false for the statements that aren't
+// audited. The database is the default database of the statement.
+func statementObjects(stmt influxql.Statement, database string) ([]string, bool) {
+	switch stmt := stmt.(type) {
+	case *influxql.AlterRetentionPolicyStatement:
+		return []string{retentionPolicyObject(stmt.Database, stmt.Name)}, true
+	case *influxql.CreateContinuousQueryStatement:
+		return []string{continuousQueryObject(stmt.Database, stmt.Name)}, true
+	case *influxql.CreateDatabaseStatement:
+		return []string{databaseObject(stmt.Name)}, true
+	case *influxql.CreateRetentionPolicyStatement:
+		return []string{retentionPolicyObject(stmt.Database, stmt.Name)}, true
+	case *influxql.CreateSubscriptionStatement:
+		return []string{subscriptionObject(stmt.Database, stmt.RetentionPolicy, stmt.Name)}, true
+	case *influxql.CreateUserStatement:
+		return []string{userObject(stmt.Name)}, true
+	case *influxql.DeleteSeriesStatement:
+		return sourceObjects(stmt.Sources, database), true
+	case *influxql.DropContinuousQueryStatement:
+		return []string{continuousQueryObject(stmt.Database, stmt.Name)}, true
+	case *influxql.DropDatabaseStatement:
+		return []string{databaseObject(stmt.Name)}, true
+	case *influxql.DropMeasurementStatement:
+		return []string{measurementObject(database, stmt.Name)}, true
+	case *influxql.DropRetentionPolicyStatement:
+		return []string{retentionPolicyObject(stmt.Database, stmt.Name)}, true
+	case *influxql.DropSeriesStatement:
+		return sourceObjects(stmt.Sources, database), true
+	case *influxql.DropShardStatement:
+		return []string{shardObject(stmt.ID)}, true
+	case *influxql.DropSubscriptionStatement:
+		return []string{subscriptionObject(stmt.Database, stmt.RetentionPolicy, stmt.Name)}, true
+	case *influxql.DropUserStatement:
+		return []string{userObject(stmt.Name)}, true
+	case *influxql.GrantStatement:
+		return []string{userObject(stmt.User), databaseObject(stmt.On)}, true
+	case *influxql.GrantAdminStatement:
+		return []string{userObject(stmt.User)}, true
+	case *influxql.KillQueryStatement:
+		return []string{"query:" + strconv.FormatUint(stmt.QueryID, 10)}, true
+	case *influxql.RevokeStatement:
+		return []string{userObject(stmt.User), databaseObject(stmt.On)}, true
+	case *influxql.RevokeAdminStatement:
+		return []string{userObject(stmt.User)}, true
+	case *influxql.SetPasswordUserStatement:
+		return []string{userObject(stmt.Name)}, true
+	case *query.AlterDatabaseRenameStatement:
+		return []string{databaseObject(stmt.Name), databaseObject(stmt.NewName)}, true
+	case *query.AlterRetentionPolicyRenameStatement:
+		return []string{retentionPolicyObject(stmt.Database, stmt.Name), retentionPolicyObject(stmt.Database, stmt.NewName)}, true
+	case *query.CreateTokenStatement:
+		return []string{userObject(stmt.User)}, true
+	case *query.DropTokenStatement:
+		return []string{tokenObject(stmt.ID)}, true
+	}
+	return nil, false
+}
+
+// sourceObjects returns the measurements of the sources of a statement, or
+// the database if the statement has no sources.
+// statementString returns the text of a statement with its passwords and the
+// secrets of the API tokens in subscription destinations redacted.
+func statementString(stmt influxql.Statement) string {
+	if stmt, ok := stmt.(*influxql.CreateSubscriptionStatement); ok {
+		other := *stmt
+		other.Destinations = make([]string, len(stmt.Destinations))
+		for i, dest := range stmt.Destinations {
+			other.Destinations[i] = meta.RedactDestination(dest)
+		}
+		return other.String()
+	}
+	return influxql.Sanitize(stmt.String())
+}
+
+func sourceObjects(sources influxql.Sources, database string) []string {
+	var objects []string
+	for _, src := range sources {
+		m, ok := src.(*influxql.Measurement)
+		if !ok {
+			continue
+		}
+		db := database
+		if m.Database != "" {
+			db = m.Database
+		}
+		name := m.Name
+		if m.Regex != nil {
+			name = m.Regex.String()
+		}
+		objects = append(objects, measurementObject(db, name))
+	}
+	if len(objects) == 0 {
+		objects = append(objects, databaseObject(database))
+	}
+	return objects
+}
+
+func databaseObject(name string) string { return "database:" + name }
+
+func retentionPolicyObject(database, name string) string {
+	return "retention-policy:" + database + "." + name
+}
+
+func measurementObject(database, name string) string {
+	return "measurement:" + database + "." + name
+}
+
+func continuousQueryObject(database, name string) string {
+	return "continuous-query:" + database + "." + name
+}
+
+func subscriptionObject(database, rp, name string) string {
+	return "subscription:" + database + "." + rp + "." + name
+}
+
+func userObject(name string) string { return "user:" + name }
+
+func tokenObject(id string) string { return "token:" + id }
+
+func shardObject(id uint64) string { return "shard:" + strconv.FormatUint(id, 10) }
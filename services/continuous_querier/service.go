@@ -431,6 +431,7 @@ func (s *Service) runContinuousQueryAndWriteResult(cq *ContinuousQuery) *query.R
 	// Execute the SELECT.
 	ch := s.QueryExecutor.ExecuteQuery(q, query.ExecutionOptions{
 		Database: cq.Database,
+		Priority: query.HighPriority,
 	}, closing)
 
 	// There is only one statement, so we will only ever receive one result
@@ -103,26 +103,49 @@ func (p *Parser) Parse(line string) (models.Point, error) {
 		return nil, fmt.Errorf("received %q which doesn't have required fields", line)
 	}
 
+	// Parse value.
+	v, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf(`field "%s" value: %s`, fields[0], err)
+	}
+
+	// If no 3rd field, use now as timestamp
+	unixTime := float64(-1)
+	if len(fields) == 3 {
+		// Parse timestamp.
+		unixTime, err = strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf(`field "%s" time: %s`, fields[0], err)
+		}
+	}
+
+	return p.ParseMetric(fields[0], v, unixTime)
+}
+
+// ParseMetric returns the point of a metric with its value and Unix timestamp
+// in seconds. The name of the metric may be followed by tags, such as
+// "cpu.load;host=a;dc=b", which take precedence over the tags extracted by
+// the template.
+func (p *Parser) ParseMetric(name string, v float64, unixTime float64) (models.Point, error) {
+	path, seriesTags, err := parseSeriesTags(name)
+	if err != nil {
+		return nil, err
+	}
+
 	// decode the name and tags
-	template := p.matcher.Match(fields[0])
-	measurement, tags, field, err := template.Apply(fields[0])
+	template := p.matcher.Match(path)
+	measurement, tags, field, err := template.Apply(path)
 	if err != nil {
 		return nil, err
 	}
 
 	// Could not extract measurement, use the raw value
 	if measurement == "" {
-		measurement = fields[0]
-	}
-
-	// Parse value.
-	v, err := strconv.ParseFloat(fields[1], 64)
-	if err != nil {
-		return nil, fmt.Errorf(`field "%s" value: %s`, fields[0], err)
+		measurement = path
 	}
 
 	if math.IsNaN(v) || math.IsInf(v, 0) {
-		return nil, &UnsupportedValueError{Field: fields[0], Value: v}
+		return nil, &UnsupportedValueError{Field: name, Value: v}
 	}
 
 	fieldValues := map[string]interface{}{}
@@ -132,25 +155,20 @@ func (p *Parser) Parse(line string) (models.Point, error) {
 		fieldValues["value"] = v
 	}
 
-	// If no 3rd field, use now as timestamp
 	timestamp := time.Now().UTC()
 
-	if len(fields) == 3 {
-		// Parse timestamp.
-		unixTime, err := strconv.ParseFloat(fields[2], 64)
-		if err != nil {
-			return nil, fmt.Errorf(`field "%s" time: %s`, fields[0], err)
+	// -1 is a special value that gets converted to current UTC time
+	// See https://github.com/graphite-project/carbon/issues/54
+	if unixTime != float64(-1) {
+		// Check if we have fractional seconds
+		timestamp = time.Unix(int64(unixTime), int64((unixTime-math.Floor(unixTime))*float64(time.Second)))
+		if timestamp.Before(MinDate) || timestamp.After(MaxDate) {
+			return nil, fmt.Errorf("timestamp out of range")
 		}
+	}
 
-		// -1 is a special value that gets converted to current UTC time
-		// See https://github.com/graphite-project/carbon/issues/54
-		if unixTime != float64(-1) {
-			// Check if we have fractional seconds
-			timestamp = time.Unix(int64(unixTime), int64((unixTime-math.Floor(unixTime))*float64(time.Second)))
-			if timestamp.Before(MinDate) || timestamp.After(MaxDate) {
-				return nil, fmt.Errorf("timestamp out of range")
-			}
-		}
+	for k, v := range seriesTags {
+		tags[k] = v
 	}
 
 	// Set the default tags on the point if they are not already set
@@ -170,16 +188,47 @@ func (p *Parser) ApplyTemplate(line string) (string, map[string]string, string,
 	if len(fields) == 0 {
 		return "", make(map[string]string), "", nil
 	}
+	path, seriesTags, err := parseSeriesTags(fields[0])
+	if err != nil {
+		return "", nil, "", err
+	}
 	// decode the name and tags
-	template := p.matcher.Match(fields[0])
-	name, tags, field, err := template.Apply(fields[0])
+	template := p.matcher.Match(path)
+	name, tags, field, err := template.Apply(path)
+	if err != nil {
+		return name, tags, field, err
+	}
+	for k, v := range seriesTags {
+		tags[k] = v
+	}
 	// Set the default tags on the point if they are not already set
 	for _, t := range p.tags {
 		if _, ok := tags[string(t.Key)]; !ok {
 			tags[string(t.Key)] = string(t.Value)
 		}
 	}
-	return name, tags, field, err
+	return name, tags, field, nil
+}
+
+// parseSeriesTags splits the name of a tagged series, such as
+// "cpu.load;host=a;dc=b", into its path and tags.
+func parseSeriesTags(name string) (string, map[string]string, error) {
+	i := strings.IndexByte(name, ';')
+	if i < 0 {
+		return name, nil, nil
+	} else if i == 0 {
+		return "", nil, fmt.Errorf("invalid tagged series %q: missing path", name)
+	}
+
+	tags := make(map[string]string)
+	for _, tag := range strings.Split(name[i+1:], ";") {
+		j := strings.IndexByte(tag, '=')
+		if j <= 0 || j == len(tag)-1 {
+			return "", nil, fmt.Errorf("invalid tagged series %q: invalid tag %q", name, tag)
+		}
+		tags[tag[:j]] = tag[j+1:]
+	}
+	return name[:i], tags, nil
 }
 
 // Template represents a pattern and tags to map a graphite metric string to a influxdb Point.
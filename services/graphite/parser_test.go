@@ -242,6 +242,126 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseTaggedSeries(t *testing.T) {
+	var tests = []struct {
+		test     string
+		input    string
+		template string
+		tags     []string
+		exp      string
+		err      string
+	}{
+		{
+			test:     "tags only",
+			input:    `cpu.load;host=a;dc=b 1.5 1435077219`,
+			template: "measurement*",
+			exp:      `cpu.load,dc=b,host=a value=1.5 1435077219000000000`,
+		},
+		{
+			test:     "merged with template tags",
+			input:    `servers.web01.cpu;dc=b;env=prod 1.5 1435077219`,
+			template: "servers.* .host.measurement",
+			exp:      `cpu,dc=b,env=prod,host=web01 value=1.5 1435077219000000000`,
+		},
+		{
+			test:     "overrides template tags",
+			input:    `servers.web01.cpu;host=web02 1.5 1435077219`,
+			template: "servers.* .host.measurement",
+			exp:      `cpu,host=web02 value=1.5 1435077219000000000`,
+		},
+		{
+			test:     "overrides default tags",
+			input:    `cpu;region=us-west 1.5 1435077219`,
+			template: "measurement env=dev",
+			tags:     []string{"region=us-east", "zone=1c"},
+			exp:      `cpu,env=dev,region=us-west,zone=1c value=1.5 1435077219000000000`,
+		},
+		{
+			test:     "field template",
+			input:    `cpu.idle;host=a 99 1435077219`,
+			template: "measurement.field",
+			exp:      `cpu,host=a idle=99 1435077219000000000`,
+		},
+		{
+			test:     "value containing equal sign",
+			input:    `cpu;query=a=b 1 1435077219`,
+			template: "measurement",
+			exp:      `cpu,query=a\=b value=1 1435077219000000000`,
+		},
+		{
+			test:     "missing path",
+			input:    `;host=a 1 1435077219`,
+			template: "measurement",
+			err:      `invalid tagged series ";host=a": missing path`,
+		},
+		{
+			test:     "missing tag value",
+			input:    `cpu;host= 1 1435077219`,
+			template: "measurement",
+			err:      `invalid tagged series "cpu;host=": invalid tag "host="`,
+		},
+		{
+			test:     "missing tag key",
+			input:    `cpu;=a 1 1435077219`,
+			template: "measurement",
+			err:      `invalid tagged series "cpu;=a": invalid tag "=a"`,
+		},
+		{
+			test:     "missing equal sign",
+			input:    `cpu;host;dc=b 1 1435077219`,
+			template: "measurement",
+			err:      `invalid tagged series "cpu;host;dc=b": invalid tag "host"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.test, func(t *testing.T) {
+			c := graphite.Config{Tags: test.tags}
+			p, err := graphite.NewParser([]string{test.template}, c.DefaultTags())
+			if err != nil {
+				t.Fatalf("unexpected error creating graphite parser: %v", err)
+			}
+
+			point, err := p.Parse(test.input)
+			if errstr(err) != test.err {
+				t.Fatalf("err does not match.  expected %v, got %v", test.err, err)
+			} else if err != nil {
+				return
+			}
+			if got := point.String(); got != test.exp {
+				t.Fatalf("unexpected point.  expected %s, got %s", test.exp, got)
+			}
+		})
+	}
+}
+
+func TestParseMetric(t *testing.T) {
+	p, err := graphite.NewParser([]string{"servers.* .host.measurement*"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating parser, got %v", err)
+	}
+
+	pt, err := p.ParseMetric("servers.web01.cpu.load;dc=b", 1.5, 1435077219.5)
+	if err != nil {
+		t.Fatal(err)
+	} else if exp := `cpu.load,dc=b,host=web01 value=1.5 1435077219500000000`; pt.String() != exp {
+		t.Fatalf("unexpected point.  expected %s, got %s", exp, pt.String())
+	}
+
+	// -1 is replaced with the current time.
+	now := time.Now()
+	pt, err = p.ParseMetric("servers.web01.cpu.load", 1.5, -1)
+	if err != nil {
+		t.Fatal(err)
+	} else if pt.Time().Before(now.Add(-time.Minute)) {
+		t.Fatalf("unexpected time: %s", pt.Time())
+	}
+
+	if _, err := p.ParseMetric("servers.web01.cpu.load", 1.5, 99999999999); errstr(err) != "timestamp out of range" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestParseNaN(t *testing.T) {
 	p, err := graphite.NewParser([]string{"measurement*"}, nil)
 	if err != nil {
@@ -722,3 +842,19 @@ func errstr(err error) string {
 	}
 	return ""
 }
+
+func TestApplyTemplateTaggedSeries(t *testing.T) {
+	p, err := graphite.NewParser([]string{"servers.* .host.measurement*"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating parser, got %v", err)
+	}
+
+	measurement, tags, _, err := p.ApplyTemplate("servers.web01.cpu.load;host=web02;dc=b 1.5 1435077219")
+	if err != nil {
+		t.Fatal(err)
+	} else if measurement != "cpu.load" {
+		t.Errorf("Parser.ApplyTemplate unexpected result. got %s, exp %s", measurement, "cpu.load")
+	} else if exp := map[string]string{"host": "web02", "dc": "b"}; !reflect.DeepEqual(tags, exp) {
+		t.Errorf("Parser.ApplyTemplate unexpected tags. got %v, exp %v", tags, exp)
+	}
+}
@@ -0,0 +1,549 @@
+package graphite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxPickleSize is the maximum size of a message of the Carbon pickle
+// protocol.
+const MaxPickleSize = 1 << 20
+
+// Opcodes of the pickle format used by Carbon. Opcodes that create objects
+// other than lists, tuples, strings and numbers are not supported.
+const (
+	opMark           = '('
+	opStop           = '.'
+	opPop            = '0'
+	opPopMark        = '1'
+	opDup            = '2'
+	opFloat          = 'F'
+	opInt            = 'I'
+	opBinInt         = 'J'
+	opBinInt1        = 'K'
+	opLong           = 'L'
+	opBinInt2        = 'M'
+	opNone           = 'N'
+	opString         = 'S'
+	opBinString      = 'T'
+	opShortBinString = 'U'
+	opUnicode        = 'V'
+	opBinUnicode     = 'X'
+	opAppend         = 'a'
+	opAppends        = 'e'
+	opGet            = 'g'
+	opBinGet         = 'h'
+	opLongBinGet     = 'j'
+	opList           = 'l'
+	opEmptyList      = ']'
+	opPut            = 'p'
+	opBinPut         = 'q'
+	opLongBinPut     = 'r'
+	opTuple          = 't'
+	opEmptyTuple     = ')'
+	opBinFloat       = 'G'
+	opBinBytes       = 'B'
+	opShortBinBytes  = 'C'
+
+	opProto           = 0x80
+	opTuple1          = 0x85
+	opTuple2          = 0x86
+	opTuple3          = 0x87
+	opNewTrue         = 0x88
+	opNewFalse        = 0x89
+	opLong1           = 0x8a
+	opLong4           = 0x8b
+	opShortBinUnicode = 0x8c
+	opBinUnicode8     = 0x8d
+	opBinBytes8       = 0x8e
+	opMemoize         = 0x94
+	opFrame           = 0x95
+)
+
+// pickleMetric is a metric of a message of the Carbon pickle protocol.
+type pickleMetric struct {
+	name      string
+	value     float64
+	timestamp float64
+}
+
+// pickleList is a list that can be appended to after it has been memoized.
+type pickleList struct {
+	items []interface{}
+}
+
+// decodePickleMetrics decodes a message of the Carbon pickle protocol, which
+// is a list of (name, (timestamp, value)) tuples.
+func decodePickleMetrics(b []byte) ([]pickleMetric, error) {
+	v, err := unpickle(b)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := v.(*pickleList)
+	if !ok {
+		return nil, errors.New("pickle: message is not a list")
+	}
+
+	metrics := make([]pickleMetric, 0, len(list.items))
+	for _, item := range list.items {
+		metric := pickleSequence(item)
+		if len(metric) != 2 {
+			return nil, errors.New("pickle: metric is not a (name, (timestamp, value)) tuple")
+		}
+		name, ok := pickleString(metric[0])
+		if !ok {
+			return nil, errors.New("pickle: metric name is not a string")
+		}
+		datapoint := pickleSequence(metric[1])
+		if len(datapoint) != 2 {
+			return nil, fmt.Errorf("pickle: datapoint of %q is not a (timestamp, value) tuple", name)
+		}
+		timestamp, err := pickleFloat(datapoint[0])
+		if err != nil {
+			return nil, fmt.Errorf(`field "%s" time: %s`, name, err)
+		}
+		value, err := pickleFloat(datapoint[1])
+		if err != nil {
+			return nil, fmt.Errorf(`field "%s" value: %s`, name, err)
+		}
+		metrics = append(metrics, pickleMetric{name: name, value: value, timestamp: timestamp})
+	}
+	return metrics, nil
+}
+
+func pickleSequence(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		return v
+	case *pickleList:
+		return v.items
+	}
+	return nil
+}
+
+func pickleString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	}
+	return "", false
+}
+
+func pickleFloat(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case []byte:
+		return strconv.ParseFloat(string(v), 64)
+	}
+	return 0, fmt.Errorf("unsupported type %T", v)
+}
+
+// unpickler decodes the objects of the pickle format.
+type unpickler struct {
+	r     *bytes.Reader
+	stack []interface{}
+	marks []int
+	memo  map[int]interface{}
+}
+
+// unpickle returns the object encoded in b.
+func unpickle(b []byte) (interface{}, error) {
+	u := &unpickler{r: bytes.NewReader(b), memo: make(map[int]interface{})}
+	for {
+		op, err := u.r.ReadByte()
+		if err == io.EOF {
+			return nil, errors.New("pickle: missing stop opcode")
+		} else if err != nil {
+			return nil, err
+		}
+		if op == opStop {
+			return u.pop()
+		}
+		if err := u.exec(op); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (u *unpickler) exec(op byte) error {
+	switch op {
+	case opProto:
+		_, err := u.r.ReadByte()
+		return err
+	case opFrame:
+		_, err := u.readN(8)
+		return err
+	case opMark:
+		u.marks = append(u.marks, len(u.stack))
+	case opPop:
+		_, err := u.pop()
+		return err
+	case opPopMark:
+		_, err := u.popMark()
+		return err
+	case opDup:
+		v, err := u.top()
+		if err != nil {
+			return err
+		}
+		u.push(v)
+	case opNone:
+		u.push(nil)
+	case opNewTrue:
+		u.push(true)
+	case opNewFalse:
+		u.push(false)
+	case opInt:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		switch line {
+		case "00":
+			u.push(false)
+		case "01":
+			u.push(true)
+		default:
+			n, err := strconv.ParseInt(line, 10, 64)
+			if err != nil {
+				return err
+			}
+			u.push(n)
+		}
+	case opLong:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		n, err := strconv.ParseInt(strings.TrimSuffix(line, "L"), 10, 64)
+		if err != nil {
+			return err
+		}
+		u.push(n)
+	case opBinInt:
+		b, err := u.readN(4)
+		if err != nil {
+			return err
+		}
+		u.push(int64(int32(binary.LittleEndian.Uint32(b))))
+	case opBinInt1:
+		b, err := u.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		u.push(int64(b))
+	case opBinInt2:
+		b, err := u.readN(2)
+		if err != nil {
+			return err
+		}
+		u.push(int64(binary.LittleEndian.Uint16(b)))
+	case opLong1, opLong4:
+		n, err := u.readLen(op == opLong1, 4)
+		if err != nil {
+			return err
+		}
+		b, err := u.readN(n)
+		if err != nil {
+			return err
+		}
+		v, err := decodeLong(b)
+		if err != nil {
+			return err
+		}
+		u.push(v)
+	case opFloat:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		f, err := strconv.ParseFloat(line, 64)
+		if err != nil {
+			return err
+		}
+		u.push(f)
+	case opBinFloat:
+		b, err := u.readN(8)
+		if err != nil {
+			return err
+		}
+		u.push(math.Float64frombits(binary.BigEndian.Uint64(b)))
+	case opString:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		s, err := unquotePickleString(line)
+		if err != nil {
+			return err
+		}
+		u.push(s)
+	case opUnicode:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		u.push(line)
+	case opBinString, opShortBinString, opBinUnicode, opShortBinUnicode:
+		n, err := u.readLen(op == opShortBinString || op == opShortBinUnicode, 4)
+		if err != nil {
+			return err
+		}
+		b, err := u.readN(n)
+		if err != nil {
+			return err
+		}
+		u.push(string(b))
+	case opBinUnicode8, opBinBytes8:
+		n, err := u.readLen(false, 8)
+		if err != nil {
+			return err
+		}
+		b, err := u.readN(n)
+		if err != nil {
+			return err
+		}
+		u.push(string(b))
+	case opBinBytes, opShortBinBytes:
+		n, err := u.readLen(op == opShortBinBytes, 4)
+		if err != nil {
+			return err
+		}
+		b, err := u.readN(n)
+		if err != nil {
+			return err
+		}
+		u.push(b)
+	case opEmptyList:
+		u.push(&pickleList{})
+	case opList:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		u.push(&pickleList{items: items})
+	case opAppend:
+		v, err := u.pop()
+		if err != nil {
+			return err
+		}
+		return u.appendTo([]interface{}{v})
+	case opAppends:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		return u.appendTo(items)
+	case opEmptyTuple:
+		u.push([]interface{}{})
+	case opTuple:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		u.push(items)
+	case opTuple1, opTuple2, opTuple3:
+		n := int(op-opTuple1) + 1
+		if len(u.stack) < n {
+			return errors.New("pickle: stack underflow")
+		}
+		items := make([]interface{}, n)
+		copy(items, u.stack[len(u.stack)-n:])
+		u.stack = u.stack[:len(u.stack)-n]
+		u.push(items)
+	case opPut, opGet:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		i, err := strconv.Atoi(line)
+		if err != nil {
+			return err
+		}
+		if op == opPut {
+			return u.put(i)
+		}
+		return u.get(i)
+	case opBinPut, opBinGet:
+		b, err := u.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if op == opBinPut {
+			return u.put(int(b))
+		}
+		return u.get(int(b))
+	case opLongBinPut, opLongBinGet:
+		b, err := u.readN(4)
+		if err != nil {
+			return err
+		}
+		i := int(binary.LittleEndian.Uint32(b))
+		if op == opLongBinPut {
+			return u.put(i)
+		}
+		return u.get(i)
+	case opMemoize:
+		return u.put(len(u.memo))
+	default:
+		return fmt.Errorf("pickle: unsupported opcode 0x%02x", op)
+	}
+	return nil
+}
+
+func (u *unpickler) push(v interface{}) {
+	u.stack = append(u.stack, v)
+}
+
+func (u *unpickler) top() (interface{}, error) {
+	if len(u.stack) == 0 || (len(u.marks) > 0 && u.marks[len(u.marks)-1] == len(u.stack)) {
+		return nil, errors.New("pickle: stack underflow")
+	}
+	return u.stack[len(u.stack)-1], nil
+}
+
+func (u *unpickler) pop() (interface{}, error) {
+	v, err := u.top()
+	if err != nil {
+		return nil, err
+	}
+	u.stack = u.stack[:len(u.stack)-1]
+	return v, nil
+}
+
+// popMark removes the objects pushed since the last mark from the stack.
+func (u *unpickler) popMark() ([]interface{}, error) {
+	if len(u.marks) == 0 {
+		return nil, errors.New("pickle: missing mark")
+	}
+	i := u.marks[len(u.marks)-1]
+	u.marks = u.marks[:len(u.marks)-1]
+	items := make([]interface{}, len(u.stack)-i)
+	copy(items, u.stack[i:])
+	u.stack = u.stack[:i]
+	return items, nil
+}
+
+func (u *unpickler) appendTo(items []interface{}) error {
+	v, err := u.top()
+	if err != nil {
+		return err
+	}
+	list, ok := v.(*pickleList)
+	if !ok {
+		return errors.New("pickle: append to an object that is not a list")
+	}
+	list.items = append(list.items, items...)
+	return nil
+}
+
+func (u *unpickler) put(i int) error {
+	v, err := u.top()
+	if err != nil {
+		return err
+	}
+	u.memo[i] = v
+	return nil
+}
+
+func (u *unpickler) get(i int) error {
+	v, ok := u.memo[i]
+	if !ok {
+		return fmt.Errorf("pickle: missing memo %d", i)
+	}
+	u.push(v)
+	return nil
+}
+
+func (u *unpickler) readN(n int) ([]byte, error) {
+	if n < 0 || n > u.r.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(u.r, b)
+	return b, err
+}
+
+// readLen reads a length stored in a single byte if short is true or in size
+// bytes otherwise.
+func (u *unpickler) readLen(short bool, size int) (int, error) {
+	if short {
+		b, err := u.r.ReadByte()
+		return int(b), err
+	}
+	b, err := u.readN(size)
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	if size == 4 {
+		n = uint64(binary.LittleEndian.Uint32(b))
+	} else {
+		n = binary.LittleEndian.Uint64(b)
+	}
+	if n > uint64(u.r.Len()) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return int(n), nil
+}
+
+func (u *unpickler) readLine() (string, error) {
+	var buf []byte
+	for {
+		b, err := u.r.ReadByte()
+		if err == io.EOF {
+			return "", io.ErrUnexpectedEOF
+		} else if err != nil {
+			return "", err
+		}
+		if b == '\n' {
+			return string(buf), nil
+		}
+		buf = append(buf, b)
+	}
+}
+
+// decodeLong decodes a little-endian two's complement integer.
+func decodeLong(b []byte) (int64, error) {
+	if len(b) > 8 {
+		return 0, errors.New("pickle: integer out of range")
+	} else if len(b) == 0 {
+		return 0, nil
+	}
+	var n uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		n = n<<8 | uint64(b[i])
+	}
+	// Extend the sign of negative integers.
+	if shift := uint(64 - 8*len(b)); b[len(b)-1]&0x80 != 0 && shift > 0 {
+		return int64(n<<shift) >> shift, nil
+	}
+	return int64(n), nil
+}
+
+// unquotePickleString decodes the quoted string of the STRING opcode.
+func unquotePickleString(s string) (string, error) {
+	if len(s) < 2 || s[0] != s[len(s)-1] || (s[0] != '\'' && s[0] != '"') {
+		return "", errors.New("pickle: invalid quoted string")
+	}
+	s = strings.Replace(s[1:len(s)-1], `\'`, `'`, -1)
+	return strconv.Unquote(`"` + strings.Replace(s, `"`, `\"`, -1) + `"`)
+}
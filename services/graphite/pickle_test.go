@@ -0,0 +1,73 @@
+package graphite
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodePickleMetrics(t *testing.T) {
+	exp := []pickleMetric{
+		{name: "cpu.load;host=a", value: 1.5, timestamp: 1435077219},
+		{name: "mem.free", value: 2, timestamp: 1435077219.5},
+	}
+	for _, tt := range []struct {
+		name string
+		data string
+		exp  []pickleMetric
+	}{
+		{
+			name: "protocol 0",
+			data: "(lp0\n(Vcpu.load;host=a\np1\n(I1435077219\nF1.5\ntp2\ntp3\na(Vmem.free\np4\n(F1435077219.5\nI2\ntp5\ntp6\na.",
+			exp:  exp,
+		},
+		{
+			name: "protocol 2",
+			data: "\x80\x02]q\x00(X\x0f\x00\x00\x00cpu.load;host=aq\x01Jc\x8a\x89UG?\xf8\x00\x00\x00\x00\x00\x00\x86q\x02\x86q\x03X\x08\x00\x00\x00mem.freeq\x04GA\xd5bb\x98\xe0\x00\x00K\x02\x86q\x05\x86q\x06e.",
+			exp:  exp,
+		},
+		{
+			name: "protocol 4",
+			data: "\x80\x04\x95C\x00\x00\x00\x00\x00\x00\x00]\x94(\x8c\x0fcpu.load;host=a\x94Jc\x8a\x89UG?\xf8\x00\x00\x00\x00\x00\x00\x86\x94\x86\x94\x8c\x08mem.free\x94GA\xd5bb\x98\xe0\x00\x00K\x02\x86\x94\x86\x94e.",
+			exp:  exp,
+		},
+		{
+			// Python 2 relays pickle names as byte strings and may repeat
+			// them through the memo.
+			name: "byte strings and memo",
+			data: "\x80\x02]q\x00(U\x08cpu.loadq\x01Jc\x8a\x89UG?\xf8\x00\x00\x00\x00\x00\x00\x86q\x02\x86q\x03h\x01\x8a\x04c\x5c\x89UK\x02\x86q\x04\x86q\x05e.",
+			exp: []pickleMetric{
+				{name: "cpu.load", value: 1.5, timestamp: 1435077219},
+				{name: "cpu.load", value: 2, timestamp: 1435065443},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics, err := decodePickleMetrics([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			} else if !reflect.DeepEqual(metrics, tt.exp) {
+				t.Fatalf("unexpected metrics: got %+v, exp %+v", metrics, tt.exp)
+			}
+		})
+	}
+}
+
+func TestDecodePickleMetrics_Invalid(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: ""},
+		{name: "missing stop", data: "\x80\x02]q\x00"},
+		{name: "not a list", data: "\x80\x02K\x01."},
+		{name: "missing datapoint", data: "\x80\x02]U\x03cpu\x85a."},
+		{name: "global", data: "\x80\x02cos\nsystem\nq\x00."},
+		{name: "truncated string", data: "\x80\x02]X\xff\x00\x00\x00cpu."},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodePickleMetrics([]byte(tt.data)); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
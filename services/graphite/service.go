@@ -3,7 +3,9 @@ package graphite // import "github.com/influxdata/influxdb/services/graphite"
 
 import (
 	"bufio"
+	"encoding/binary"
 	"fmt"
+	"io"
 	"math"
 	"net"
 	"strings"
@@ -152,7 +154,9 @@ func (s *Service) Open() error {
 
 	var err error
 	if strings.ToLower(s.protocol) == "tcp" {
-		s.addr, err = s.openTCPServer()
+		s.addr, err = s.openTCPServer(s.handleTCPConnection)
+	} else if strings.ToLower(s.protocol) == "pickle" {
+		s.addr, err = s.openTCPServer(s.handlePickleConnection)
 	} else if strings.ToLower(s.protocol) == "udp" {
 		s.addr, err = s.openUDPServer()
 	} else {
@@ -309,8 +313,9 @@ func (s *Service) Addr() net.Addr {
 	return s.addr
 }
 
-// openTCPServer opens the Graphite input in TCP mode and starts processing data.
-func (s *Service) openTCPServer() (net.Addr, error) {
+// openTCPServer opens the Graphite input in TCP mode and starts processing data
+// with handle.
+func (s *Service) openTCPServer(handle func(conn net.Conn)) (net.Addr, error) {
 	ln, err := net.Listen("tcp", s.bindAddress)
 	if err != nil {
 		return nil, err
@@ -332,7 +337,7 @@ func (s *Service) openTCPServer() (net.Addr, error) {
 			}
 
 			s.wg.Add(1)
-			go s.handleTCPConnection(conn)
+			go handle(conn)
 		}
 	}()
 	return ln.Addr(), nil
@@ -366,6 +371,53 @@ func (s *Service) handleTCPConnection(conn net.Conn) {
 	}
 }
 
+// handlePickleConnection services an individual TCP connection for the
+// Graphite input using the Carbon pickle protocol. Each message is a list of
+// metrics in the pickle format, prefixed by its length as a 4 byte big-endian
+// integer.
+func (s *Service) handlePickleConnection(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+	defer atomic.AddInt64(&s.stats.ActiveConnections, -1)
+	defer s.untrackConnection(conn)
+	atomic.AddInt64(&s.stats.ActiveConnections, 1)
+	atomic.AddInt64(&s.stats.HandledConnections, 1)
+	s.trackConnection(conn)
+
+	reader := bufio.NewReader(conn)
+
+	var hdr [4]byte
+	for {
+		if _, err := io.ReadFull(reader, hdr[:]); err != nil {
+			return
+		}
+		n := binary.BigEndian.Uint32(hdr[:])
+		if n > MaxPickleSize {
+			s.logger.Info("Pickle message too large, closing connection",
+				zap.Uint32("size", n), zap.Stringer("remote_addr", conn.RemoteAddr()))
+			return
+		}
+
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return
+		}
+		atomic.AddInt64(&s.stats.BytesReceived, int64(len(hdr)+len(buf)))
+
+		metrics, err := decodePickleMetrics(buf)
+		if err != nil {
+			s.logger.Info("Unable to decode pickle message", zap.Error(err))
+			atomic.AddInt64(&s.stats.PointsParseFail, 1)
+			continue
+		}
+		atomic.AddInt64(&s.stats.PointsReceived, int64(len(metrics)))
+		for _, m := range metrics {
+			point, err := s.parser.ParseMetric(m.name, m.value, m.timestamp)
+			s.handlePoint(point, err, m.name)
+		}
+	}
+}
+
 func (s *Service) trackConnection(c net.Conn) {
 	s.tcpConnectionsMu.Lock()
 	defer s.tcpConnectionsMu.Unlock()
@@ -429,6 +481,12 @@ func (s *Service) handleLine(line string) {
 
 	// Parse it.
 	point, err := s.parser.Parse(line)
+	s.handlePoint(point, err, line)
+}
+
+// handlePoint adds the point parsed from line to the current batch or records
+// the parse error.
+func (s *Service) handlePoint(point models.Point, err error, line string) {
 	if err != nil {
 		switch err := err.(type) {
 		case *UnsupportedValueError:
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -194,6 +195,72 @@ func Test_Service_TCP(t *testing.T) {
 	wg.Wait()
 }
 
+func Test_Service_Pickle(t *testing.T) {
+	t.Parallel()
+
+	config := Config{}
+	config.Database = "graphitedb"
+	config.BatchSize = 0 // No batching.
+	config.BatchTimeout = toml.Duration(time.Second)
+	config.BindAddress = ":0"
+	config.Protocol = "pickle"
+	config.Templates = []string{"servers.* .host.measurement*"}
+
+	service := NewTestService(&config)
+
+	// Allow test to wait until points are written.
+	written := make(chan string, 2)
+	service.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		if database != "graphitedb" {
+			t.Errorf("unexpected database: %s", database)
+		}
+		for _, pt := range points {
+			written <- pt.String()
+		}
+		return nil
+	}
+
+	if err := service.Service.Open(); err != nil {
+		t.Fatalf("failed to open Graphite service: %s", err.Error())
+	}
+	defer service.Service.Close()
+
+	// Connect to the graphite endpoint we just spun up
+	_, port, _ := net.SplitHostPort(service.Service.Addr().String())
+	conn, err := net.Dial("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// [("servers.web01.cpu;dc=b", (1435077219, 1.5)), ("servers.web02.mem", (1435077219, 2))]
+	msg := []byte("\x80\x02]q\x00(X\x16\x00\x00\x00servers.web01.cpu;dc=bq\x01Jc\x8a\x89UG?\xf8\x00\x00\x00\x00\x00\x00\x86q\x02\x86q\x03X\x11\x00\x00\x00servers.web02.memq\x04Jc\x8a\x89UK\x02\x86q\x05\x86q\x06e.")
+	data := []byte{0, 0, 0, byte(len(msg))}
+	data = append(data, msg...)
+	_, err = conn.Write(data)
+	conn.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for len(got) < 2 {
+		select {
+		case pt := <-written:
+			got = append(got, pt)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for points, got %v", got)
+		}
+	}
+
+	exp := []string{
+		"cpu,dc=b,host=web01 value=1.5 1435077219000000000",
+		"mem,host=web02 value=2 1435077219000000000",
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected points: got %v, exp %v", got, exp)
+	}
+}
+
 func Test_Service_UDP(t *testing.T) {
 	t.Parallel()
 
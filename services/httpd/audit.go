@@ -0,0 +1,107 @@
+package httpd
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+// metaWriter is the part of the meta client changing the meta data.
+type metaWriter interface {
+	SetUserQueryQuota(username string, q *query.Quota) error
+	SetDatabaseQueryQuota(name string, q *query.Quota) error
+	SetSubscriptionFilter(database, rp, name string, filter *meta.SubscriptionFilter) error
+	SetDatabaseSchema(name string, schema *meta.DatabaseSchema) error
+	SetDatabaseWriteQuota(name string, q *meta.WriteQuota) error
+	SetUserReadGrants(username, database string, grants []meta.ReadGrant) error
+	UnlockUser(name string) error
+	CreateToken(username string, scopes []string, expiresAt time.Time, description string) (string, *meta.TokenInfo, error)
+	DropToken(id string) error
+}
+
+// renamer renames databases and retention policies.
+type renamer interface {
+	RenameDatabase(name, newName string) error
+	RenameRetentionPolicy(database, name, newName string) error
+}
+
+// copier copies data between retention policies.
+type copier interface {
+	CopyRetentionPolicy(database, src, dst string, start, end time.Time, move bool) error
+}
+
+// clientExecutor is implemented by statement executors attributing the meta
+// data they change to a user and a client address.
+type clientExecutor interface {
+	WithClient(user, addr string) *coordinator.StatementExecutor
+}
+
+// metaClient returns the meta client attributing the meta data it changes to
+// the user and the client address of r, if the meta client supports it.
+func (h *Handler) metaClient(r *http.Request, user meta.User) metaWriter {
+	if c, ok := h.MetaClient.(interface {
+		WithClient(user, addr string) *meta.Client
+	}); ok {
+		return c.WithClient(userID(user), clientAddr(r))
+	}
+	return h.MetaClient
+}
+
+// renamer returns the Renamer attributing the renames to the user and the
+// client address of r, if it supports it.
+func (h *Handler) renamer(r *http.Request, user meta.User) renamer {
+	if e, ok := h.Renamer.(clientExecutor); ok {
+		return e.WithClient(userID(user), clientAddr(r))
+	}
+	return h.Renamer
+}
+
+// copier returns the Copier attributing the shard groups it deletes to the
+// user and the client address of r, if it supports it.
+func (h *Handler) copier(r *http.Request, user meta.User) copier {
+	if e, ok := h.Copier.(clientExecutor); ok {
+		return e.WithClient(userID(user), clientAddr(r))
+	}
+	return h.Copier
+}
+
+// unauthorized records a failed authentication of username and writes the
+// error response.
+func (h *Handler) unauthorized(w http.ResponseWriter, r *http.Request, username, errmsg string) {
+	h.auditAccessDenied(r, username, "authenticate", errmsg)
+	h.httpError(w, errmsg, http.StatusUnauthorized)
+}
+
+// forbidden records a request user is not authorized to make and writes the
+// error response.
+func (h *Handler) forbidden(w http.ResponseWriter, r *http.Request, user meta.User, errmsg string) {
+	h.auditAccessDenied(r, userID(user), "authorize", errmsg)
+	h.httpError(w, errmsg, http.StatusForbidden)
+}
+
+// auditAccessDenied records a refused request with the auditor, if any.
+func (h *Handler) auditAccessDenied(r *http.Request, username, operation, errmsg string) {
+	if h.Auditor != nil {
+		h.Auditor.AuditAccessDenied(username, clientAddr(r), operation, r.URL.Path, errmsg)
+	}
+}
+
+// clientAddr returns the host of the client address of r.
+func clientAddr(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// userID returns the ID of user, or an empty string if user is nil.
+func userID(user meta.User) string {
+	if user == nil {
+		return ""
+	}
+	return user.ID()
+}
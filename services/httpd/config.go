@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb/monitor/diagnostics"
 	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/influxql"
 )
 
 const (
@@ -28,6 +30,22 @@ const (
 
 	// DefaultEnqueuedWriteTimeout is the maximum time a write request can wait to be processed.
 	DefaultEnqueuedWriteTimeout = 30 * time.Second
+
+	// DefaultWriteDedupMaxBatches is the maximum number of write batch IDs remembered.
+	DefaultWriteDedupMaxBatches = 100000
+
+	// DefaultJWTKeysReloadInterval is the default interval between reloads of the JWT public keys.
+	DefaultJWTKeysReloadInterval = time.Minute
+
+	// DefaultJWTUsernameClaim is the default claim of a JWT holding the username.
+	DefaultJWTUsernameClaim = "username"
+
+	// DefaultJWTGroupsClaim is the default claim of a JWT holding the groups of the user.
+	DefaultJWTGroupsClaim = "groups"
+
+	// DefaultAuthLockoutDuration is the default duration a client address is locked out for
+	// after too many failed authentications.
+	DefaultAuthLockoutDuration = 5 * time.Minute
 )
 
 // Config represents a configuration for a HTTP service.
@@ -63,6 +81,18 @@ type Config struct {
 	MaxConcurrentWriteLimit int               `toml:"max-concurrent-write-limit"`
 	MaxEnqueuedWriteLimit   int               `toml:"max-enqueued-write-limit"`
 	EnqueuedWriteTimeout    time.Duration     `toml:"enqueued-write-timeout"`
+	WriteDedupWindow        toml.Duration     `toml:"write-dedup-window"`
+	WriteDedupMaxBatches    int               `toml:"write-dedup-max-batches"`
+	JWTPublicKeysPath       string            `toml:"jwt-public-keys-path"`
+	JWTJWKSPath             string            `toml:"jwt-jwks-path"`
+	JWTKeysReloadInterval   toml.Duration     `toml:"jwt-keys-reload-interval"`
+	JWTIssuer               string            `toml:"jwt-issuer"`
+	JWTAudience             string            `toml:"jwt-audience"`
+	JWTUsernameClaim        string            `toml:"jwt-username-claim"`
+	JWTGroupsClaim          string            `toml:"jwt-groups-claim"`
+	JWTGroups               []JWTGroupConfig  `toml:"jwt-groups"`
+	AuthMaxFailuresPerIP    int               `toml:"auth-max-failures-per-ip"`
+	AuthLockoutDuration     toml.Duration     `toml:"auth-lockout-duration"`
 	TLS                     *tls.Config       `toml:"-"`
 }
 
@@ -87,9 +117,60 @@ func NewConfig() Config {
 		BindSocket:            DefaultBindSocket,
 		MaxBodySize:           DefaultMaxBodySize,
 		EnqueuedWriteTimeout:  DefaultEnqueuedWriteTimeout,
+		WriteDedupMaxBatches:  DefaultWriteDedupMaxBatches,
+		JWTKeysReloadInterval: toml.Duration(DefaultJWTKeysReloadInterval),
+		JWTUsernameClaim:      DefaultJWTUsernameClaim,
+		JWTGroupsClaim:        DefaultJWTGroupsClaim,
+		AuthLockoutDuration:   toml.Duration(DefaultAuthLockoutDuration),
 	}
 }
 
+// JWTGroupConfig grants a privilege to the users whose JWT lists a group, so
+// that the users do not need to be created beforehand.
+type JWTGroupConfig struct {
+	Group     string `toml:"group"`
+	Database  string `toml:"database"`
+	Privilege string `toml:"privilege"`
+	Admin     bool   `toml:"admin"`
+}
+
+// privilege returns the privilege granted on the database.
+func (c JWTGroupConfig) privilege() (influxql.Privilege, error) {
+	switch strings.ToLower(c.Privilege) {
+	case "read":
+		return influxql.ReadPrivilege, nil
+	case "write":
+		return influxql.WritePrivilege, nil
+	case "all":
+		return influxql.AllPrivileges, nil
+	}
+	return influxql.NoPrivileges, fmt.Errorf("invalid privilege %q for jwt group %q: must be one of read, write or all", c.Privilege, c.Group)
+}
+
+// Validate returns an error if the config is invalid.
+func (c Config) Validate() error {
+	if c.JWTKeysReloadInterval < 0 {
+		return errors.New("jwt-keys-reload-interval must not be negative")
+	}
+	if c.AuthMaxFailuresPerIP < 0 {
+		return errors.New("auth-max-failures-per-ip must not be negative")
+	} else if c.AuthMaxFailuresPerIP > 0 && c.AuthLockoutDuration <= 0 {
+		return errors.New("auth-lockout-duration must be positive")
+	}
+	for _, g := range c.JWTGroups {
+		if g.Group == "" {
+			return errors.New("jwt group requires a group")
+		} else if g.Admin {
+			continue
+		} else if g.Database == "" {
+			return fmt.Errorf("jwt group %q requires a database or admin", g.Group)
+		} else if _, err := g.privilege(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Diagnostics returns a diagnostics representation of a subset of the Config.
 func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 	if !c.Enabled {
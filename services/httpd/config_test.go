@@ -248,3 +248,37 @@ access-log-status-filters = ["4xxx"]
 		}
 	}
 }
+
+func TestConfig_JWTGroups(t *testing.T) {
+	var c httpd.Config
+	if _, err := toml.Decode(`
+jwt-jwks-path = "/etc/influxdb/jwks.json"
+jwt-issuer = "idp"
+
+[[jwt-groups]]
+group = "analysts"
+database = "telegraf"
+privilege = "read"
+
+[[jwt-groups]]
+group = "ops"
+admin = true
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.JWTJWKSPath != "/etc/influxdb/jwks.json" {
+		t.Fatalf("unexpected jwks path: %s", c.JWTJWKSPath)
+	} else if c.JWTIssuer != "idp" {
+		t.Fatalf("unexpected issuer: %s", c.JWTIssuer)
+	} else if len(c.JWTGroups) != 2 {
+		t.Fatalf("unexpected jwt groups: %v", c.JWTGroups)
+	} else if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	c.JWTGroups[0].Privilege = "delete"
+	if err := c.Validate(); err == nil || err.Error() != `invalid privilege "delete" for jwt group "analysts": must be one of read, write or all` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
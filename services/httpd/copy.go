@@ -0,0 +1,82 @@
+package httpd
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+// serveCopyRetentionPolicy copies the data of the retention policy given by
+// the "db" and "rp" parameters between the RFC3339 "start" and "end" times
+// into the retention policy given by the "to" parameter. The data is deleted
+// from the source retention policy if the "move" parameter is true.
+func (h *Handler) serveCopyRetentionPolicy(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	q := r.URL.Query()
+	db, rp, to := q.Get("db"), q.Get("rp"), q.Get("to")
+	if db == "" {
+		h.httpError(w, "database is required", http.StatusBadRequest)
+		return
+	} else if rp == "" || to == "" {
+		h.httpError(w, "source and destination retention policies are required", http.StatusBadRequest)
+		return
+	} else if rp == to {
+		h.httpError(w, "source and destination retention policies must differ", http.StatusBadRequest)
+		return
+	}
+
+	start, err := time.Parse(time.RFC3339Nano, q.Get("start"))
+	if err != nil {
+		h.httpError(w, "error parsing start time: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	end, err := time.Parse(time.RFC3339Nano, q.Get("end"))
+	if err != nil {
+		h.httpError(w, "error parsing end time: "+err.Error(), http.StatusBadRequest)
+		return
+	} else if !start.Before(end) {
+		h.httpError(w, "start time must be before end time", http.StatusBadRequest)
+		return
+	}
+
+	var move bool
+	if s := q.Get("move"); s != "" {
+		if move, err = strconv.ParseBool(s); err != nil {
+			h.httpError(w, "error parsing move: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if h.Copier == nil {
+		h.httpError(w, "copying is not supported", http.StatusNotImplemented)
+		return
+	}
+
+	di := h.MetaClient.Database(db)
+	if di == nil {
+		h.httpError(w, influxdb.ErrDatabaseNotFound(db).Error(), http.StatusNotFound)
+		return
+	}
+	for _, name := range []string{rp, to} {
+		if di.RetentionPolicy(name) == nil {
+			h.httpError(w, influxdb.ErrRetentionPolicyNotFound(name).Error(), http.StatusNotFound)
+			return
+		}
+	}
+
+	if err := h.copier(r, user).CopyRetentionPolicy(db, rp, to, start, end, move); err == coordinator.ErrShardGroupNotEnded {
+		h.httpError(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,105 @@
+package httpd
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// batchIDHeader is the header carrying the ID of a write batch.
+	batchIDHeader = "X-Influxdb-Batch-Id"
+
+	// batchIDParam is the query parameter carrying the ID of a write batch.
+	batchIDParam = "batch_id"
+)
+
+// writeBatchID returns the ID of the batch of a write request, or an empty
+// string if the request does not carry one.
+func writeBatchID(r *http.Request) string {
+	if id := r.Header.Get(batchIDHeader); id != "" {
+		return id
+	}
+	return r.URL.Query().Get(batchIDParam)
+}
+
+// writeDeduplicator remembers the batches written to each database and
+// retention policy by each user for a window so that retried batches are not
+// written twice. The points of a batch
+// without a timestamp are given the time the batch was first seen, so that a
+// retry of a batch that failed overwrites the points of the first attempt
+// instead of creating new ones.
+type writeDeduplicator struct {
+	window     time.Duration
+	maxBatches int
+
+	mu      sync.Mutex
+	batches map[batchKey]*batch
+	order   []batchKey // In the order the batches were first seen.
+}
+
+// batchKey identifies a batch. Batch IDs are chosen by clients, so the same
+// ID used by different users or for different retention policies is a
+// different batch.
+type batchKey struct {
+	database        string
+	retentionPolicy string
+	user            string
+	id              string
+}
+
+type batch struct {
+	seen    time.Time
+	applied bool
+}
+
+func newWriteDeduplicator(window time.Duration, maxBatches int) *writeDeduplicator {
+	return &writeDeduplicator{
+		window:     window,
+		maxBatches: maxBatches,
+		batches:    make(map[batchKey]*batch),
+	}
+}
+
+// begin returns the time the batch was first seen, which is now unless the
+// batch is retried, and whether the batch was already applied.
+func (d *writeDeduplicator) begin(key batchKey, now time.Time) (seen time.Time, applied bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.expire(now)
+
+	if b := d.batches[key]; b != nil {
+		return b.seen, b.applied
+	}
+
+	d.batches[key] = &batch{seen: now}
+	d.order = append(d.order, key)
+	return now, false
+}
+
+// done marks the batch as applied.
+func (d *writeDeduplicator) done(key batchKey) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if b := d.batches[key]; b != nil {
+		b.applied = true
+	}
+}
+
+// expire forgets the batches seen before the window and the oldest batches
+// beyond the maximum number of batches.
+func (d *writeDeduplicator) expire(now time.Time) {
+	var n int
+	for _, key := range d.order {
+		if b := d.batches[key]; now.Sub(b.seen) < d.window && (d.maxBatches <= 0 || len(d.order)-n < d.maxBatches) {
+			break
+		}
+		delete(d.batches, key)
+		n++
+	}
+	if n > 0 {
+		d.order = d.order[n:]
+	}
+}
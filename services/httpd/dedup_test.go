@@ -0,0 +1,57 @@
+package httpd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWriteDeduplicator_Expire(t *testing.T) {
+	d := newWriteDeduplicator(time.Minute, 2)
+	now := time.Unix(0, 0)
+
+	if seen, applied := d.begin(batchKey{database: "db0", id: "b1"}, now); !seen.Equal(now) || applied {
+		t.Fatalf("unexpected batch: seen=%s applied=%v", seen, applied)
+	}
+	d.done(batchKey{database: "db0", id: "b1"})
+	if seen, applied := d.begin(batchKey{database: "db0", id: "b1"}, now.Add(time.Second)); !seen.Equal(now) || !applied {
+		t.Fatalf("unexpected batch: seen=%s applied=%v", seen, applied)
+	}
+
+	// The batch is forgotten after the window.
+	later := now.Add(time.Minute)
+	if seen, applied := d.begin(batchKey{database: "db0", id: "b1"}, later); !seen.Equal(later) || applied {
+		t.Fatalf("unexpected batch: seen=%s applied=%v", seen, applied)
+	}
+
+	// The oldest batch is forgotten beyond the maximum number of batches.
+	d.begin(batchKey{database: "db0", id: "b2"}, later)
+	d.begin(batchKey{database: "db0", id: "b3"}, later)
+	if len(d.batches) != 2 || len(d.order) != 2 {
+		t.Fatalf("got %d batches, expected 2", len(d.batches))
+	} else if _, ok := d.batches[batchKey{database: "db0", id: "b1"}]; ok {
+		t.Fatal("expected batch b1 to be forgotten")
+	}
+}
+
+func TestWriteDeduplicator_Key(t *testing.T) {
+	d := newWriteDeduplicator(time.Minute, 0)
+	now := time.Unix(0, 0)
+
+	key := batchKey{database: "db0", retentionPolicy: "rp0", user: "alice", id: "b1"}
+	d.begin(key, now)
+	d.done(key)
+
+	// The same batch ID is a different batch for another retention policy or
+	// user.
+	for _, other := range []batchKey{
+		{database: "db0", retentionPolicy: "rp1", user: "alice", id: "b1"},
+		{database: "db0", retentionPolicy: "rp0", user: "bob", id: "b1"},
+	} {
+		if _, applied := d.begin(other, now); applied {
+			t.Fatalf("unexpected applied batch: %+v", other)
+		}
+	}
+	if _, applied := d.begin(key, now); !applied {
+		t.Fatal("expected batch to be applied")
+	}
+}
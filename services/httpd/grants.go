@@ -0,0 +1,112 @@
+package httpd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/services/storage"
+	"github.com/influxdata/influxql"
+)
+
+// readGrant is the JSON representation of a read grant.
+type readGrant struct {
+	Database    string `json:"database,omitempty"`
+	Measurement string `json:"measurement,omitempty"`
+	Condition   string `json:"condition,omitempty"`
+}
+
+// readGrants is the response of the read grants endpoint.
+type readGrants struct {
+	Users map[string][]readGrant `json:"users"`
+}
+
+// authorizeRead returns a context limiting the series read from the store to
+// the series the user may read. It returns false and writes an error response
+// if authentication is enabled and the user may not read the database.
+func (h *Handler) authorizeRead(w http.ResponseWriter, r *http.Request, user meta.User, database string) (context.Context, bool) {
+	ctx := context.Background()
+	if !h.Config.AuthEnabled {
+		return ctx, true
+	}
+	if user == nil {
+		h.forbidden(w, r, user, fmt.Sprintf("user is required to read from database %q", database))
+		return nil, false
+	}
+	if err := h.QueryAuthorizer.AuthorizeDatabase(user, influxql.ReadPrivilege, database); err != nil {
+		h.forbidden(w, r, user, fmt.Sprintf("%q user is not authorized to read from database %q", user.ID(), database))
+		return nil, false
+	}
+	return storage.NewContextWithAuthorizer(ctx, user), true
+}
+
+// serveReadGrants returns the read grants of all users.
+func (h *Handler) serveReadGrants(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	resp := readGrants{Users: make(map[string][]readGrant)}
+	for _, ui := range h.MetaClient.Users() {
+		for _, g := range ui.ReadGrants {
+			resp.Users[ui.Name] = append(resp.Users[ui.Name], readGrant{
+				Database:    g.Database,
+				Measurement: g.Measurement,
+				Condition:   g.Condition,
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// serveSetReadGrants replaces the read grants of the user named by the "user"
+// parameter on the database named by the "db" parameter. An empty list
+// restores the user's access to all series of the database.
+func (h *Handler) serveSetReadGrants(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	username, db := r.URL.Query().Get("user"), r.URL.Query().Get("db")
+	if username == "" || db == "" {
+		h.httpError(w, "user and db are required", http.StatusBadRequest)
+		return
+	}
+
+	var rgs []readGrant
+	if err := json.NewDecoder(r.Body).Decode(&rgs); err != nil {
+		h.httpError(w, "error parsing read grants: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	grants := make([]meta.ReadGrant, len(rgs))
+	for i, rg := range rgs {
+		if rg.Database != "" && rg.Database != db {
+			h.httpError(w, fmt.Sprintf("read grant on database %q does not match db", rg.Database), http.StatusBadRequest)
+			return
+		}
+		grants[i] = meta.ReadGrant{Database: db, Measurement: rg.Measurement, Condition: rg.Condition}
+		if err := grants[i].Validate(); err != nil {
+			h.httpError(w, "invalid read grant: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if h.MetaClient.Database(db) == nil {
+		h.httpError(w, influxdb.ErrDatabaseNotFound(db).Error(), http.StatusNotFound)
+		return
+	}
+	if err := h.metaClient(r, user).SetUserReadGrants(username, db, grants); err == meta.ErrUserNotFound {
+		h.httpError(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,67 @@
+package httpd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+// serveMetaHistory returns the versions of the meta data which can be rolled
+// back to.
+func (h *Handler) serveMetaHistory(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	} else if h.MetaHistory == nil {
+		h.httpError(w, "meta history is not supported", http.StatusNotImplemented)
+		return
+	}
+
+	versions := h.MetaHistory.MetaHistory()
+	if versions == nil {
+		versions = []meta.HistoryEntry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string][]meta.HistoryEntry{"versions": versions})
+}
+
+// serveMetaRollback rolls the meta data back to the version given by the
+// "index" parameter and returns the shards which could not be restored.
+func (h *Handler) serveMetaRollback(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	index, err := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+	if err != nil {
+		h.httpError(w, "error parsing index: "+err.Error(), http.StatusBadRequest)
+		return
+	} else if h.MetaHistory == nil {
+		h.httpError(w, "meta history is not supported", http.StatusNotImplemented)
+		return
+	}
+
+	res, err := h.MetaHistory.RollbackMeta(index, userID(user), clientAddr(r))
+	switch err {
+	case nil:
+	case meta.ErrVersionNotFound:
+		h.httpError(w, err.Error(), http.StatusNotFound)
+		return
+	case meta.ErrVersionCurrent:
+		h.httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if res.Unrecoverable == nil {
+		res.Unrecoverable = []meta.RollbackShard{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
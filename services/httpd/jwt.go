@@ -0,0 +1,377 @@
+package httpd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go/v4"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+	"go.uber.org/zap"
+)
+
+// jwtKeySet holds the public keys verifying the JWTs signed with RSA or
+// ECDSA. The keys are loaded from a PEM file and from a local JWKS file.
+type jwtKeySet struct {
+	pemPath  string
+	jwksPath string
+
+	mu   sync.RWMutex
+	keys []jwtKey
+}
+
+// jwtKey is a public key and its ID. The keys of the PEM file have no ID.
+type jwtKey struct {
+	id  string
+	key interface{}
+}
+
+func newJWTKeySet(pemPath, jwksPath string) *jwtKeySet {
+	return &jwtKeySet{pemPath: pemPath, jwksPath: jwksPath}
+}
+
+// load replaces the keys with the keys of the files. The keys are unchanged
+// if any file cannot be loaded.
+func (s *jwtKeySet) load() error {
+	var keys []jwtKey
+	if s.pemPath != "" {
+		buf, err := ioutil.ReadFile(s.pemPath)
+		if err != nil {
+			return err
+		}
+		pemKeys, err := parsePEMPublicKeys(buf)
+		if err != nil {
+			return fmt.Errorf("%s: %s", s.pemPath, err)
+		}
+		keys = append(keys, pemKeys...)
+	}
+	if s.jwksPath != "" {
+		buf, err := ioutil.ReadFile(s.jwksPath)
+		if err != nil {
+			return err
+		}
+		jwksKeys, err := parseJWKS(buf)
+		if err != nil {
+			return fmt.Errorf("%s: %s", s.jwksPath, err)
+		}
+		keys = append(keys, jwksKeys...)
+	}
+	if len(keys) == 0 {
+		return errors.New("no jwt public keys found")
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+	return nil
+}
+
+// candidates returns the keys that may verify a token: the key with the ID
+// of the token, or all keys of the type of the signing method of a token
+// without ID.
+func (s *jwtKeySet) candidates(token *jwt.Token) []interface{} {
+	kid, _ := token.Header["kid"].(string)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var keys []interface{}
+	for _, k := range s.keys {
+		if kid != "" && k.id != kid {
+			continue
+		}
+		switch k.key.(type) {
+		case *rsa.PublicKey:
+			switch token.Method.(type) {
+			case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+				keys = append(keys, k.key)
+			}
+		case *ecdsa.PublicKey:
+			if _, ok := token.Method.(*jwt.SigningMethodECDSA); ok {
+				keys = append(keys, k.key)
+			}
+		}
+	}
+	return keys
+}
+
+// parsePEMPublicKeys returns the RSA and ECDSA public keys and certificates
+// of PEM encoded data.
+func parsePEMPublicKeys(buf []byte) ([]jwtKey, error) {
+	var keys []jwtKey
+	for {
+		var block *pem.Block
+		if block, buf = pem.Decode(buf); block == nil {
+			break
+		}
+
+		var key interface{}
+		var err error
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		case "CERTIFICATE":
+			var cert *x509.Certificate
+			if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+				key = cert.PublicKey
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch key.(type) {
+		case *rsa.PublicKey, *ecdsa.PublicKey:
+			keys = append(keys, jwtKey{key: key})
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+	}
+	return keys, nil
+}
+
+// jsonWebKey is a JSON web key, as defined by RFC 7517.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS returns the RSA and ECDSA signature keys of a JSON web key set.
+func parseJWKS(buf []byte) ([]jwtKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(buf, &set); err != nil {
+		return nil, err
+	}
+
+	var keys []jwtKey
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var key interface{}
+		switch jwk.Kty {
+		case "RSA":
+			n, err := decodeJWKInt(jwk.N)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: invalid modulus: %s", jwk.Kid, err)
+			}
+			e, err := decodeJWKInt(jwk.E)
+			if err != nil || !e.IsInt64() {
+				return nil, fmt.Errorf("key %q: invalid exponent", jwk.Kid)
+			}
+			key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch jwk.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				return nil, fmt.Errorf("key %q: unsupported curve %q", jwk.Kid, jwk.Crv)
+			}
+			x, err := decodeJWKInt(jwk.X)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: invalid x coordinate: %s", jwk.Kid, err)
+			}
+			y, err := decodeJWKInt(jwk.Y)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: invalid y coordinate: %s", jwk.Kid, err)
+			}
+			if !curve.IsOnCurve(x, y) {
+				return nil, fmt.Errorf("key %q: point is not on curve %s", jwk.Kid, jwk.Crv)
+			}
+			key = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		default:
+			continue
+		}
+		keys = append(keys, jwtKey{id: jwk.Kid, key: key})
+	}
+	return keys, nil
+}
+
+// decodeJWKInt decodes a base64url encoded big-endian integer.
+func decodeJWKInt(s string) (*big.Int, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	} else if len(buf) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(buf), nil
+}
+
+// parseJWT parses and verifies a JWT signed with the shared secret or with
+// one of the public keys.
+func (h *Handler) parseJWT(s string) (*jwt.Token, error) {
+	var opts []jwt.ParserOption
+	if h.Config.JWTIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(h.Config.JWTIssuer))
+	}
+	if h.Config.JWTAudience != "" {
+		opts = append(opts, jwt.WithAudience(h.Config.JWTAudience))
+	}
+
+	token, err := jwt.Parse(s, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		} else if h.Config.SharedSecret == "" {
+			return nil, errors.New("shared-secret is not configured")
+		}
+		return []byte(h.Config.SharedSecret), nil
+	}, opts...)
+	if token == nil || token.Method == nil || h.jwtKeys == nil {
+		return token, err
+	} else if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		return token, err
+	}
+
+	// Try the public keys that may have signed the token.
+	keys := h.jwtKeys.candidates(token)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public key for signing method %v", token.Header["alg"])
+	}
+	for _, key := range keys {
+		key := key
+		if token, err = jwt.Parse(s, func(*jwt.Token) (interface{}, error) { return key, nil }, opts...); err == nil {
+			break
+		}
+	}
+	return token, err
+}
+
+// jwtUser returns the user named by the claims of a JWT. The privileges
+// granted to the groups of the claims are added to the privileges of the
+// user, and users granted privileges by their groups do not need to exist.
+func (h *Handler) jwtUser(claims jwt.MapClaims) (meta.User, error) {
+	usernameClaim := h.Config.JWTUsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = DefaultJWTUsernameClaim
+	}
+	username, ok := claims[usernameClaim].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s in token must be a string", usernameClaim)
+	} else if username == "" {
+		return nil, fmt.Errorf("token must contain a %s", usernameClaim)
+	}
+
+	admin, privileges := h.jwtGroupPrivileges(claims)
+
+	user, err := h.MetaClient.User(username)
+	if err == meta.ErrUserNotFound && (admin || len(privileges) > 0) {
+		return &meta.UserInfo{Name: username, Admin: admin, Privileges: privileges, External: true}, nil
+	} else if err != nil {
+		return nil, err
+	} else if user == nil {
+		return nil, meta.ErrUserNotFound
+	} else if !admin && len(privileges) == 0 {
+		return user, nil
+	}
+
+	ui, ok := user.(*meta.UserInfo)
+	if !ok {
+		return user, nil
+	}
+	other := *ui
+	other.Admin = ui.Admin || admin
+	other.Privileges = privileges
+	for db, p := range ui.Privileges {
+		other.Privileges[db] = mergePrivileges(other.Privileges[db], p)
+	}
+	other.External = true
+	return &other, nil
+}
+
+// jwtGroupPrivileges returns the privileges granted to the groups of the
+// claims of a JWT.
+func (h *Handler) jwtGroupPrivileges(claims jwt.MapClaims) (admin bool, privileges map[string]influxql.Privilege) {
+	if len(h.Config.JWTGroups) == 0 {
+		return false, nil
+	}
+	groupsClaim := h.Config.JWTGroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = DefaultJWTGroupsClaim
+	}
+
+	groups := make(map[string]bool)
+	switch v := claims[groupsClaim].(type) {
+	case string:
+		groups[v] = true
+	case []interface{}:
+		for _, g := range v {
+			if g, ok := g.(string); ok {
+				groups[g] = true
+			}
+		}
+	}
+
+	privileges = make(map[string]influxql.Privilege)
+	for _, g := range h.Config.JWTGroups {
+		if !groups[g.Group] {
+			continue
+		} else if g.Admin {
+			admin = true
+			continue
+		}
+		p, err := g.privilege()
+		if err != nil {
+			continue
+		}
+		privileges[g.Database] = mergePrivileges(privileges[g.Database], p)
+	}
+	return admin, privileges
+}
+
+// mergePrivileges returns the privilege granting both privileges.
+func mergePrivileges(a, b influxql.Privilege) influxql.Privilege {
+	switch {
+	case a == influxql.NoPrivileges:
+		return b
+	case b == influxql.NoPrivileges, a == b:
+		return a
+	}
+	return influxql.AllPrivileges
+}
+
+// reloadJWTKeys reloads the JWT public keys at every interval until closing
+// is closed, so rotated keys are used without restarting.
+func (h *Handler) reloadJWTKeys(interval time.Duration, closing <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closing:
+			return
+		case <-ticker.C:
+			if err := h.jwtKeys.load(); err != nil {
+				h.Logger.Error("Unable to reload JWT public keys", zap.Error(err))
+			}
+		}
+	}
+}
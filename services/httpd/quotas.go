@@ -0,0 +1,244 @@
+package httpd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+// queryQuota is the JSON representation of a query quota. The names of the
+// limits match the names of the global limits in the [coordinator] section.
+type queryQuota struct {
+	MaxConcurrentQueries int    `json:"max-concurrent-queries,omitempty"`
+	QueryTimeout         string `json:"query-timeout,omitempty"`
+	MaxSelectPointN      int    `json:"max-select-point,omitempty"`
+	MaxSelectSeriesN     int    `json:"max-select-series,omitempty"`
+	MaxSelectBucketsN    int    `json:"max-select-buckets,omitempty"`
+}
+
+func newQueryQuota(q *query.Quota) *queryQuota {
+	qq := &queryQuota{
+		MaxConcurrentQueries: q.MaxConcurrentQueries,
+		MaxSelectPointN:      q.MaxSelectPointN,
+		MaxSelectSeriesN:     q.MaxSelectSeriesN,
+		MaxSelectBucketsN:    q.MaxSelectBucketsN,
+	}
+	if q.QueryTimeout > 0 {
+		qq.QueryTimeout = q.QueryTimeout.String()
+	}
+	return qq
+}
+
+func (qq *queryQuota) quota() (*query.Quota, error) {
+	q := &query.Quota{
+		MaxConcurrentQueries: qq.MaxConcurrentQueries,
+		MaxSelectPointN:      qq.MaxSelectPointN,
+		MaxSelectSeriesN:     qq.MaxSelectSeriesN,
+		MaxSelectBucketsN:    qq.MaxSelectBucketsN,
+	}
+	if qq.QueryTimeout != "" {
+		d, err := time.ParseDuration(qq.QueryTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query-timeout: %s", err)
+		}
+		q.QueryTimeout = d
+	}
+	if q.MaxConcurrentQueries < 0 || q.QueryTimeout < 0 || q.MaxSelectPointN < 0 || q.MaxSelectSeriesN < 0 || q.MaxSelectBucketsN < 0 {
+		return nil, fmt.Errorf("query quota limits must not be negative")
+	}
+	return q, nil
+}
+
+// queryQuotas is the response of the query quotas endpoint.
+type queryQuotas struct {
+	Users     map[string]*queryQuota `json:"users"`
+	Databases map[string]*queryQuota `json:"databases"`
+}
+
+// authorizeAdmin returns false and writes an error response if authentication
+// is enabled and the user is not an admin.
+func (h *Handler) authorizeAdmin(w http.ResponseWriter, r *http.Request, user meta.User) bool {
+	if !h.Config.AuthEnabled {
+		return true
+	}
+	if user == nil || !user.AuthorizeUnrestricted() {
+		h.forbidden(w, r, user, "error authorizing admin access")
+		return false
+	}
+	return true
+}
+
+// serveQueryQuotas returns the query quotas of all users and databases.
+func (h *Handler) serveQueryQuotas(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	resp := queryQuotas{
+		Users:     make(map[string]*queryQuota),
+		Databases: make(map[string]*queryQuota),
+	}
+	for _, ui := range h.MetaClient.Users() {
+		if ui.QueryQuota != nil {
+			resp.Users[ui.Name] = newQueryQuota(ui.QueryQuota)
+		}
+	}
+	for _, di := range h.MetaClient.Databases() {
+		if di.QueryQuota != nil {
+			resp.Databases[di.Name] = newQueryQuota(di.QueryQuota)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// serveSetQueryQuota sets the query quota of the user or database named by
+// the "user" or "db" parameter. A quota without any limits removes it.
+func (h *Handler) serveSetQueryQuota(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	username, db := r.URL.Query().Get("user"), r.URL.Query().Get("db")
+	if (username == "") == (db == "") {
+		h.httpError(w, "exactly one of user or db is required", http.StatusBadRequest)
+		return
+	}
+
+	var qq queryQuota
+	if err := json.NewDecoder(r.Body).Decode(&qq); err != nil {
+		h.httpError(w, "error parsing query quota: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	q, err := qq.quota()
+	if err != nil {
+		h.httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if username != "" {
+		err = h.metaClient(r, user).SetUserQueryQuota(username, q)
+	} else {
+		if h.MetaClient.Database(db) == nil {
+			h.httpError(w, influxdb.ErrDatabaseNotFound(db).Error(), http.StatusNotFound)
+			return
+		}
+		err = h.metaClient(r, user).SetDatabaseQueryQuota(db, q)
+	}
+	if err == meta.ErrUserNotFound {
+		h.httpError(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeQuota is the JSON representation of a write quota.
+type writeQuota struct {
+	MaxSeries          int64 `json:"max-series,omitempty"`
+	MaxDiskBytes       int64 `json:"max-disk-bytes,omitempty"`
+	MaxPointsPerSecond int64 `json:"max-points-per-second,omitempty"`
+	PointsBurst        int64 `json:"points-burst,omitempty"`
+}
+
+// writeQuotaUsage is the JSON representation of the usage of a write quota.
+type writeQuotaUsage struct {
+	Series          int64 `json:"series"`
+	DiskBytes       int64 `json:"disk-bytes"`
+	PointsPerSecond int64 `json:"points-per-second"`
+}
+
+// databaseWriteQuota is the write quota of a database and its usage.
+type databaseWriteQuota struct {
+	Quota *writeQuota      `json:"quota,omitempty"`
+	Usage *writeQuotaUsage `json:"usage,omitempty"`
+}
+
+// serveWriteQuotas returns the write quotas of all databases and their usage
+// on this node. Databases without a quota are returned with their usage.
+func (h *Handler) serveWriteQuotas(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	resp := struct {
+		Databases map[string]*databaseWriteQuota `json:"databases"`
+	}{Databases: make(map[string]*databaseWriteQuota)}
+	for _, di := range h.MetaClient.Databases() {
+		dq := &databaseWriteQuota{}
+		if q := di.WriteQuota; q != nil {
+			dq.Quota = &writeQuota{
+				MaxSeries:          q.MaxSeries,
+				MaxDiskBytes:       q.MaxDiskBytes,
+				MaxPointsPerSecond: q.MaxPointsPerSecond,
+				PointsBurst:        q.PointsBurst,
+			}
+		}
+		if h.WriteQuotas != nil {
+			u, err := h.WriteQuotas.Usage(di.Name)
+			if err != nil {
+				h.httpError(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			dq.Usage = &writeQuotaUsage{
+				Series:          u.SeriesN,
+				DiskBytes:       u.DiskBytes,
+				PointsPerSecond: u.PointsPerSecond,
+			}
+		}
+		resp.Databases[di.Name] = dq
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// serveSetWriteQuota sets the write quota of the database named by the "db"
+// parameter. A quota without any limits removes it.
+func (h *Handler) serveSetWriteQuota(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	db := r.URL.Query().Get("db")
+	if db == "" {
+		h.httpError(w, "database is required", http.StatusBadRequest)
+		return
+	}
+
+	var wq writeQuota
+	if err := json.NewDecoder(r.Body).Decode(&wq); err != nil {
+		h.httpError(w, "error parsing write quota: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := &meta.WriteQuota{
+		MaxSeries:          wq.MaxSeries,
+		MaxDiskBytes:       wq.MaxDiskBytes,
+		MaxPointsPerSecond: wq.MaxPointsPerSecond,
+		PointsBurst:        wq.PointsBurst,
+	}
+	if err := q.Validate(); err != nil {
+		h.httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.MetaClient.Database(db) == nil {
+		h.httpError(w, influxdb.ErrDatabaseNotFound(db).Error(), http.StatusNotFound)
+		return
+	}
+	if err := h.metaClient(r, user).SetDatabaseWriteQuota(db, q); err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -86,7 +86,10 @@ func (h *Handler) serveRawRead(w http.ResponseWriter, r *http.Request, user meta
 		return
 	}
 
-	ctx := context.Background()
+	ctx, ok := h.authorizeRead(w, r, user, db)
+	if !ok {
+		return
+	}
 	rs, err := h.Store.ReadFilter(ctx, readRequest)
 	if err != nil {
 		h.httpError(w, err.Error(), http.StatusInternalServerError)
@@ -0,0 +1,88 @@
+package httpd
+
+import (
+	"net/http"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+// serveRenameDatabase renames the database given by the "db" parameter to
+// the name given by the "to" parameter.
+func (h *Handler) serveRenameDatabase(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	q := r.URL.Query()
+	db, to := q.Get("db"), q.Get("to")
+	if db == "" {
+		h.httpError(w, "database is required", http.StatusBadRequest)
+		return
+	} else if to == "" {
+		h.httpError(w, "new name is required", http.StatusBadRequest)
+		return
+	} else if h.Renamer == nil {
+		h.httpError(w, "renaming is not supported", http.StatusNotImplemented)
+		return
+	}
+
+	if h.MetaClient.Database(db) == nil {
+		h.httpError(w, influxdb.ErrDatabaseNotFound(db).Error(), http.StatusNotFound)
+		return
+	}
+	if err := h.renamer(r, user).RenameDatabase(db, to); err != nil {
+		h.httpError(w, err.Error(), renameErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveRenameRetentionPolicy renames the retention policy given by the "db"
+// and "rp" parameters to the name given by the "to" parameter.
+func (h *Handler) serveRenameRetentionPolicy(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	q := r.URL.Query()
+	db, rp, to := q.Get("db"), q.Get("rp"), q.Get("to")
+	if db == "" {
+		h.httpError(w, "database is required", http.StatusBadRequest)
+		return
+	} else if rp == "" {
+		h.httpError(w, "retention policy is required", http.StatusBadRequest)
+		return
+	} else if to == "" {
+		h.httpError(w, "new name is required", http.StatusBadRequest)
+		return
+	} else if h.Renamer == nil {
+		h.httpError(w, "renaming is not supported", http.StatusNotImplemented)
+		return
+	}
+
+	di := h.MetaClient.Database(db)
+	if di == nil {
+		h.httpError(w, influxdb.ErrDatabaseNotFound(db).Error(), http.StatusNotFound)
+		return
+	} else if di.RetentionPolicy(rp) == nil {
+		h.httpError(w, influxdb.ErrRetentionPolicyNotFound(rp).Error(), http.StatusNotFound)
+		return
+	}
+	if err := h.renamer(r, user).RenameRetentionPolicy(db, rp, to); err != nil {
+		h.httpError(w, err.Error(), renameErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// renameErrorStatus returns the status code of a failed rename.
+func renameErrorStatus(err error) int {
+	switch err {
+	case meta.ErrInvalidName, meta.ErrNameTooLong:
+		return http.StatusBadRequest
+	case meta.ErrDatabaseExists, meta.ErrRetentionPolicyNameExists:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -5,10 +5,17 @@ import (
 	"fmt"
 	"github.com/influxdata/influxdb/prometheus"
 	"github.com/influxdata/influxdb/prometheus/remote"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/services/storage"
 	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 	"net"
 	"strings"
 	"time"
@@ -17,6 +24,82 @@ import (
 type Server struct {
 	remote.UnimplementedQueryTimeSeriesServiceServer
 	Store Store
+
+	// AuthEnabled requires the requests to carry the credentials of a user
+	// in the "authorization" metadata, as "Token <API_TOKEN>" or
+	// "Token <username:password>".
+	AuthEnabled bool
+	MetaClient  interface {
+		AdminUserExists() bool
+		Authenticate(username, password string) (meta.User, error)
+		AuthenticateToken(token string) (meta.User, error)
+	}
+	QueryAuthorizer QueryAuthorizer
+
+	// Lockout locks out the client addresses making too many failed
+	// authentications with a password, as the HTTP handler does. Passwords
+	// are refused if it is not set, so that they cannot be guessed without
+	// limit.
+	Lockout interface {
+		AuthenticationLocked(addr string) bool
+		AuthenticationFailed(addr string)
+		AuthenticationSucceeded(addr string)
+	}
+}
+
+// authorize returns a context limiting the series read from the store to the
+// series the user of the request may read from the database.
+func (s *Server) authorize(ctx context.Context, database string) (context.Context, error) {
+	if !s.AuthEnabled || !s.MetaClient.AdminUserExists() {
+		return context.Background(), nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || !strings.HasPrefix(values[0], "Token ") {
+		return nil, status.Error(codes.Unauthenticated, "unable to parse authentication credentials")
+	}
+
+	var user meta.User
+	var err error
+	if token := strings.TrimPrefix(values[0], "Token "); strings.HasPrefix(token, meta.TokenPrefix) {
+		user, err = s.MetaClient.AuthenticateToken(token)
+	} else if u, p, ok := parseToken(token); ok {
+		if s.Lockout == nil {
+			return nil, status.Error(codes.Unauthenticated, "password authentication is not supported")
+		}
+		addr := peerAddr(ctx)
+		if s.Lockout.AuthenticationLocked(addr) {
+			return nil, status.Error(codes.ResourceExhausted, "too many failed authentication attempts")
+		}
+		if user, err = s.MetaClient.Authenticate(u, p); err != nil {
+			s.Lockout.AuthenticationFailed(addr)
+		} else {
+			s.Lockout.AuthenticationSucceeded(addr)
+		}
+	} else {
+		err = meta.ErrAuthenticate
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "authorization failed")
+	}
+
+	if err := s.QueryAuthorizer.AuthorizeDatabase(user, influxql.ReadPrivilege, database); err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "%q user is not authorized to read from database %q", user.ID(), database)
+	}
+	return storage.NewContextWithAuthorizer(context.Background(), user), nil
+}
+
+// peerAddr returns the host of the client address of a request.
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+	return p.Addr.String()
 }
 
 func (s *Server) Raw(req *remote.FilterRequest, stream remote.QueryTimeSeriesService_RawServer) error {
@@ -25,7 +108,10 @@ func (s *Server) Raw(req *remote.FilterRequest, stream remote.QueryTimeSeriesSer
 		return err
 	}
 
-	ctx := context.Background()
+	ctx, err := s.authorize(stream.Context(), req.GetDb())
+	if err != nil {
+		return err
+	}
 	rs, err := s.Store.ReadFilter(ctx, readRequest)
 	if err != nil {
 		return err
@@ -144,7 +230,7 @@ type RpcService struct {
 	serv   *grpc.Server
 	logger *zap.Logger
 
-	server *Server
+	Server *Server
 
 	err chan error
 }
@@ -155,7 +241,7 @@ func NewGrpcService(address string, store Store) *RpcService {
 		serv:   grpc.NewServer(),
 		err:    make(chan error),
 		logger: zap.NewNop(),
-		server: &Server{
+		Server: &Server{
 			Store: store,
 		},
 	}
@@ -189,7 +275,7 @@ func (s *RpcService) Open() error {
 
 // serve serves the handler from the listener.
 func (s *RpcService) serve() {
-	remote.RegisterQueryTimeSeriesServiceServer(s.serv, s.server)
+	remote.RegisterQueryTimeSeriesServiceServer(s.serv, s.Server)
 	reflection.Register(s.serv)
 
 	if err := s.serv.Serve(s.ln); err != nil && !strings.Contains(err.Error(), "closed") {
@@ -0,0 +1,91 @@
+package httpd
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/influxql"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type rpcMetaClient struct{}
+
+func (rpcMetaClient) AdminUserExists() bool { return true }
+
+func (rpcMetaClient) Authenticate(username, password string) (meta.User, error) {
+	if username != "alice" || password != "secret" {
+		return nil, meta.ErrAuthenticate
+	}
+	return &meta.UserInfo{Name: username}, nil
+}
+
+func (rpcMetaClient) AuthenticateToken(token string) (meta.User, error) {
+	return nil, meta.ErrAuthenticate
+}
+
+type rpcQueryAuthorizer struct{}
+
+func (rpcQueryAuthorizer) AuthorizeQuery(u meta.User, q *influxql.Query, database string) (query.FineAuthorizer, error) {
+	return query.OpenAuthorizer, nil
+}
+
+func (rpcQueryAuthorizer) AuthorizeDatabase(u meta.User, priv influxql.Privilege, database string) error {
+	return nil
+}
+
+// Ensure passwords sent over gRPC count towards the lockout of the client
+// address.
+func TestServer_Authorize_Lockout(t *testing.T) {
+	c := NewConfig()
+	c.AuthMaxFailuresPerIP = 2
+	c.AuthLockoutDuration = toml.Duration(time.Hour)
+	h := NewHandler(c)
+
+	s := &Server{AuthEnabled: true, MetaClient: rpcMetaClient{}, QueryAuthorizer: rpcQueryAuthorizer{}}
+	authorize := func(addr, creds string) codes.Code {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Token "+creds))
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 1234}})
+		_, err := s.authorize(ctx, "db0")
+		return status.Code(err)
+	}
+
+	// Passwords are refused without a lockout.
+	if code := authorize("10.0.0.1", "alice:secret"); code != codes.Unauthenticated {
+		t.Fatalf("unexpected code: %s", code)
+	}
+	s.Lockout = h
+
+	// A successful authentication forgets the failures.
+	for _, tt := range []struct {
+		creds string
+		code  codes.Code
+	}{
+		{creds: "alice:wrong", code: codes.Unauthenticated},
+		{creds: "alice:secret", code: codes.OK},
+		{creds: "alice:wrong", code: codes.Unauthenticated},
+		{creds: "alice:wrong", code: codes.Unauthenticated},
+		{creds: "alice:secret", code: codes.ResourceExhausted},
+	} {
+		if code := authorize("10.0.0.1", tt.creds); code != tt.code {
+			t.Fatalf("%s: unexpected code: got %s, exp %s", tt.creds, code, tt.code)
+		}
+	}
+
+	// Other addresses are not locked out, and the lockout applies to HTTP too.
+	if code := authorize("10.0.0.2", "alice:secret"); code != codes.OK {
+		t.Fatalf("unexpected code: %s", code)
+	} else if !h.AuthenticationLocked("10.0.0.1") {
+		t.Fatal("expected address to be locked out over HTTP")
+	} else if n := atomic.LoadInt64(&h.stats.AuthenticationFailures); n != 3 {
+		t.Fatalf("unexpected authentication failures: %d", n)
+	}
+}
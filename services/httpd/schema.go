@@ -0,0 +1,138 @@
+package httpd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+)
+
+// databaseSchema is the JSON representation of the schema of a database.
+type databaseSchema struct {
+	Mode         string              `json:"mode"`
+	Measurements []measurementSchema `json:"measurements"`
+}
+
+// measurementSchema is the JSON representation of the schema of a
+// measurement. Fields maps the names of the fields to their types.
+type measurementSchema struct {
+	Name         string            `json:"name"`
+	RequiredTags []string          `json:"required-tags,omitempty"`
+	AllowedTags  []string          `json:"allowed-tags,omitempty"`
+	Fields       map[string]string `json:"fields,omitempty"`
+}
+
+func newDatabaseSchema(s *meta.DatabaseSchema) *databaseSchema {
+	ds := &databaseSchema{
+		Mode:         s.Mode,
+		Measurements: make([]measurementSchema, len(s.Measurements)),
+	}
+	for i, m := range s.Measurements {
+		ms := measurementSchema{
+			Name:         m.Name,
+			RequiredTags: m.RequiredTags,
+			AllowedTags:  m.AllowedTags,
+		}
+		if len(m.Fields) > 0 {
+			ms.Fields = make(map[string]string, len(m.Fields))
+			for _, f := range m.Fields {
+				ms.Fields[f.Name] = f.Type.String()
+			}
+		}
+		ds.Measurements[i] = ms
+	}
+	return ds
+}
+
+func (ds *databaseSchema) schema() (*meta.DatabaseSchema, error) {
+	s := &meta.DatabaseSchema{
+		Mode:         ds.Mode,
+		Measurements: make([]meta.MeasurementSchema, len(ds.Measurements)),
+	}
+	for i, ms := range ds.Measurements {
+		m := meta.MeasurementSchema{
+			Name:         ms.Name,
+			RequiredTags: ms.RequiredTags,
+			AllowedTags:  ms.AllowedTags,
+		}
+		for name, typ := range ms.Fields {
+			t := influxql.DataTypeFromString(typ)
+			if t == influxql.Unknown {
+				return nil, fmt.Errorf("invalid type %q of field %q on measurement %q", typ, name, ms.Name)
+			}
+			m.Fields = append(m.Fields, meta.FieldSchema{Name: name, Type: t})
+		}
+		sort.Slice(m.Fields, func(i, j int) bool { return m.Fields[i].Name < m.Fields[j].Name })
+		s.Measurements[i] = m
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// serveSchema returns the schema of the database named by the "db" parameter.
+func (h *Handler) serveSchema(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	db := r.URL.Query().Get("db")
+	if db == "" {
+		h.httpError(w, "database is required", http.StatusBadRequest)
+		return
+	}
+	di := h.MetaClient.Database(db)
+	if di == nil {
+		h.httpError(w, influxdb.ErrDatabaseNotFound(db).Error(), http.StatusNotFound)
+		return
+	} else if di.Schema == nil {
+		h.httpError(w, fmt.Sprintf("database %s has no schema", db), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newDatabaseSchema(di.Schema))
+}
+
+// serveSetSchema sets the schema of the database named by the "db"
+// parameter. An empty body removes it.
+func (h *Handler) serveSetSchema(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	db := r.URL.Query().Get("db")
+	if db == "" {
+		h.httpError(w, "database is required", http.StatusBadRequest)
+		return
+	}
+
+	var schema *meta.DatabaseSchema
+	var ds databaseSchema
+	if err := json.NewDecoder(r.Body).Decode(&ds); err == nil {
+		if schema, err = ds.schema(); err != nil {
+			h.httpError(w, "invalid schema: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else if err != io.EOF {
+		h.httpError(w, "error parsing schema: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.MetaClient.Database(db) == nil {
+		h.httpError(w, influxdb.ErrDatabaseNotFound(db).Error(), http.StatusNotFound)
+		return
+	}
+	if err := h.metaClient(r, user).SetDatabaseSchema(db, schema); err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -32,6 +32,8 @@ const (
 	statPointsWrittenDropped         = "pointsWrittenDropped"   // Number of points dropped by the storage engine.
 	statPointsWrittenFail            = "pointsWrittenFail"      // Number of points that failed to be written.
 	statAuthFail                     = "authFail"               // Number of authentication failures.
+	statAuthLockout                  = "authLockout"            // Number of authentications rejected because the user or client address is locked out.
+	statAuthPasswordExpired          = "authPasswordExpired"    // Number of authentications rejected because the password expired.
 	statRequestDuration              = "reqDurationNs"          // Number of (wall-time) nanoseconds spent inside requests.
 	statQueryRequestDuration         = "queryReqDurationNs"     // Number of (wall-time) nanoseconds spent inside query requests.
 	statWriteRequestDuration         = "writeReqDurationNs"     // Number of (wall-time) nanoseconds spent inside write requests.
@@ -44,6 +46,7 @@ const (
 	statPromReadRequest              = "promReadReq"            // Number of read requests to the prometheus endpoint.
 	statFluxQueryRequests            = "fluxQueryReq"           // Number of flux query requests served.
 	statFluxQueryRequestDuration     = "fluxQueryReqDurationNs" // Number of (wall-time) nanoseconds spent executing Flux query requests.
+	statWriteRequestsDeduplicated    = "writeReqDedup"          // Number of write requests of batches already applied.
 
 )
 
@@ -0,0 +1,70 @@
+package httpd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+// subscriptionFilter is the JSON representation of the filter of a
+// subscription.
+type subscriptionFilter struct {
+	Measurements     []string `json:"measurements,omitempty"`
+	MeasurementRegex string   `json:"measurement-regex,omitempty"`
+	Where            string   `json:"where,omitempty"`
+}
+
+// serveSetSubscriptionFilter sets the filter of the subscription named by the
+// "db", "rp" and "name" parameters. An empty filter removes it.
+func (h *Handler) serveSetSubscriptionFilter(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	q := r.URL.Query()
+	db, rp, name := q.Get("db"), q.Get("rp"), q.Get("name")
+	if db == "" || rp == "" || name == "" {
+		h.httpError(w, "db, rp and name are required", http.StatusBadRequest)
+		return
+	}
+
+	var sf subscriptionFilter
+	if err := json.NewDecoder(r.Body).Decode(&sf); err != nil && err != io.EOF {
+		h.httpError(w, "error parsing subscription filter: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var filter *meta.SubscriptionFilter
+	if len(sf.Measurements) > 0 || sf.MeasurementRegex != "" || sf.Where != "" {
+		filter = &meta.SubscriptionFilter{
+			Measurements:     sf.Measurements,
+			MeasurementRegex: sf.MeasurementRegex,
+			Condition:        sf.Where,
+		}
+		if err := filter.Validate(); err != nil {
+			h.httpError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	di := h.MetaClient.Database(db)
+	if di == nil {
+		h.httpError(w, influxdb.ErrDatabaseNotFound(db).Error(), http.StatusNotFound)
+		return
+	} else if di.RetentionPolicy(rp) == nil {
+		h.httpError(w, influxdb.ErrRetentionPolicyNotFound(rp).Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := h.metaClient(r, user).SetSubscriptionFilter(db, rp, name, filter); err == meta.ErrSubscriptionNotFound {
+		h.httpError(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
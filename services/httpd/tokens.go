@@ -0,0 +1,130 @@
+package httpd
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+)
+
+// apiToken is the JSON representation of an API token. The token itself is
+// only returned when the token is created.
+type apiToken struct {
+	ID          string     `json:"id"`
+	Token       string     `json:"token,omitempty"`
+	User        string     `json:"user"`
+	Scopes      []string   `json:"scopes"`
+	Description string     `json:"description,omitempty"`
+	CreatedAt   time.Time  `json:"created-at"`
+	ExpiresAt   *time.Time `json:"expires-at,omitempty"`
+	Expired     bool       `json:"expired,omitempty"`
+}
+
+func newAPIToken(ti *meta.TokenInfo, now time.Time) apiToken {
+	t := apiToken{
+		ID:          ti.ID,
+		User:        ti.User,
+		Scopes:      ti.Scopes,
+		Description: ti.Description,
+		CreatedAt:   ti.CreatedAt,
+		Expired:     ti.Expired(now),
+	}
+	if !ti.ExpiresAt.IsZero() {
+		expiresAt := ti.ExpiresAt
+		t.ExpiresAt = &expiresAt
+	}
+	return t
+}
+
+// createTokenRequest is the request to create an API token. Expires is a
+// duration such as "30d", or empty for a token that never expires.
+type createTokenRequest struct {
+	User        string   `json:"user"`
+	Scopes      []string `json:"scopes"`
+	Expires     string   `json:"expires,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// serveTokens returns the API tokens of all users, or of the user named by
+// the "user" parameter.
+func (h *Handler) serveTokens(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	username := r.URL.Query().Get("user")
+	now := time.Now()
+	tokens := []apiToken{}
+	for _, ti := range h.MetaClient.Tokens() {
+		if username == "" || ti.User == username {
+			tokens = append(tokens, newAPIToken(&ti, now))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string][]apiToken{"tokens": tokens})
+}
+
+// serveCreateToken creates an API token and returns it.
+func (h *Handler) serveCreateToken(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	var req createTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.httpError(w, "error parsing token: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var expiresAt time.Time
+	if req.Expires != "" {
+		d, err := influxql.ParseDuration(req.Expires)
+		if err != nil || d <= 0 {
+			h.httpError(w, "invalid expires: must be a positive duration", http.StatusBadRequest)
+			return
+		}
+		expiresAt = time.Now().UTC().Add(d)
+	}
+
+	token, ti, err := h.metaClient(r, user).CreateToken(req.User, req.Scopes, expiresAt, req.Description)
+	if err == meta.ErrUserNotFound {
+		h.httpError(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := newAPIToken(ti, time.Now())
+	resp.Token = token
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// serveDropToken removes the API token with the ID given by the "id"
+// parameter.
+func (h *Handler) serveDropToken(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		h.httpError(w, "id is required", http.StatusBadRequest)
+		return
+	}
+	if err := h.metaClient(r, user).DropToken(id); err == meta.ErrTokenNotFound {
+		h.httpError(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,39 @@
+package httpd
+
+import (
+	"net/http"
+
+	"github.com/influxdata/influxdb/services/meta"
+	"go.uber.org/zap"
+)
+
+// serveUnlock unlocks the user given by the "user" parameter and the client
+// address given by the "addr" parameter, locked out after too many failed
+// authentications.
+func (h *Handler) serveUnlock(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeAdmin(w, r, user) {
+		return
+	}
+
+	q := r.URL.Query()
+	username, addr := q.Get("user"), q.Get("addr")
+	if username == "" && addr == "" {
+		h.httpError(w, "user or addr is required", http.StatusBadRequest)
+		return
+	}
+
+	if username != "" {
+		if err := h.metaClient(r, user).UnlockUser(username); err == meta.ErrUserNotFound {
+			h.httpError(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			h.httpError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if addr != "" {
+		h.authFailures.Reset(addr)
+		h.Logger.Info("Client address unlocked", zap.String("addr", addr), zap.String("user", userID(user)))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -6,6 +6,7 @@ import (
 	"bytes"
 	crand "crypto/rand"
 	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"io"
 	"io/ioutil"
@@ -14,12 +15,15 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/pkg/file"
+	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxql"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
@@ -47,6 +51,21 @@ var (
 // Client is used to execute commands on and read data from
 // a meta service cluster.
 type Client struct {
+	*clientState
+
+	// user and addr are the user and the client address the changes made
+	// through the client are attributed to, if set.
+	user string
+	addr string
+
+	// client is true if the changes made through the client are made on
+	// behalf of a client rather than by the server itself.
+	client bool
+}
+
+// clientState is the state of a Client, shared with the clients returned by
+// WithClient.
+type clientState struct {
 	logger *zap.Logger
 
 	mu        sync.RWMutex
@@ -60,8 +79,29 @@ type Client struct {
 	path string
 
 	retentionAutoCreate bool
+
+	// passwordPolicy is the policy of new passwords, which expire after
+	// passwordMaxAge unless it is 0.
+	passwordPolicy PasswordPolicy
+	passwordMaxAge time.Duration
+
+	// logins locks users out after too many failed logins, if set.
+	logins *LoginLimiter
+
+	// auditFn, if set, records the mutations of the meta data.
+	auditFn AuditFunc
+
+	// history lists the last historySize versions of the meta data.
+	history     []HistoryEntry
+	historySize int
 }
 
+// AuditFunc records a mutation of the meta data by a user from a client
+// address, with the name of the operation, the objects it affects, such as
+// "database:db0", and its error. The user and address are empty for the
+// mutations made by the server itself.
+type AuditFunc func(user, addr, operation string, objects []string, err error)
+
 type authUser struct {
 	bhash string
 	salt  []byte
@@ -70,7 +110,12 @@ type authUser struct {
 
 // NewClient returns a new *Client.
 func NewClient(config *Config) *Client {
-	return &Client{
+	var logins *LoginLimiter
+	if config.LoginMaxFailures > 0 {
+		logins = NewLoginLimiter(config.LoginMaxFailures, time.Duration(config.LoginLockoutDuration))
+	}
+
+	return &Client{clientState: &clientState{
 		cacheData: &Data{
 			ClusterID: uint64(rand.Int63()),
 			Index:     1,
@@ -81,7 +126,14 @@ func NewClient(config *Config) *Client {
 		authCache:           make(map[string]authUser),
 		path:                config.Dir,
 		retentionAutoCreate: config.RetentionAutoCreate,
-	}
+		passwordPolicy: PasswordPolicy{
+			MinLength:      config.PasswordMinLength,
+			MinCharClasses: config.PasswordMinCharClasses,
+		},
+		passwordMaxAge: time.Duration(config.PasswordMaxAge),
+		logins:         logins,
+		historySize:    config.HistorySize,
+	}}
 }
 
 // Open a connection to a meta service cluster.
@@ -101,6 +153,15 @@ func (c *Client) Open() error {
 		}
 	}
 
+	// Start the history with the current version if it is not recorded.
+	if err := c.loadHistory(); err != nil {
+		return err
+	} else if n := len(c.history); n == 0 || c.history[n-1].Index != c.cacheData.Index {
+		if err := c.recordHistory(c.cacheData, ""); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -168,7 +229,9 @@ func (c *Client) Databases() []DatabaseInfo {
 }
 
 // CreateDatabase creates a database or returns it if it already exists.
-func (c *Client) CreateDatabase(name string) (*DatabaseInfo, error) {
+func (c *Client) CreateDatabase(name string) (_ *DatabaseInfo, err error) {
+	defer func() { c.audit("create database", err, auditDatabase(name)) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -211,7 +274,9 @@ func (c *Client) CreateDatabase(name string) (*DatabaseInfo, error) {
 // retention policy, and that retention policy is already the default for the
 // database.
 //
-func (c *Client) CreateDatabaseWithRetentionPolicy(name string, spec *RetentionPolicySpec) (*DatabaseInfo, error) {
+func (c *Client) CreateDatabaseWithRetentionPolicy(name string, spec *RetentionPolicySpec) (_ *DatabaseInfo, err error) {
+	defer func() { c.audit("create database", err, auditDatabase(name)) }()
+
 	if spec == nil {
 		return nil, errors.New("CreateDatabaseWithRetentionPolicy called with nil spec")
 	}
@@ -267,7 +332,9 @@ func (c *Client) CreateDatabaseWithRetentionPolicy(name string, spec *RetentionP
 }
 
 // DropDatabase deletes a database.
-func (c *Client) DropDatabase(name string) error {
+func (c *Client) DropDatabase(name string) (err error) {
+	defer func() { c.audit("drop database", err, auditDatabase(name)) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -284,8 +351,30 @@ func (c *Client) DropDatabase(name string) error {
 	return nil
 }
 
+// RenameDatabase renames a database.
+func (c *Client) RenameDatabase(name, newName string) (err error) {
+	defer func() { c.audit("rename database", err, auditDatabase(name), auditDatabase(newName)) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.RenameDatabase(name, newName); err != nil {
+		return err
+	}
+
+	if err := c.commitRename(data, auditDatabase(name)+" to "+auditDatabase(newName)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // CreateRetentionPolicy creates a retention policy on the specified database.
-func (c *Client) CreateRetentionPolicy(database string, spec *RetentionPolicySpec, makeDefault bool) (*RetentionPolicyInfo, error) {
+func (c *Client) CreateRetentionPolicy(database string, spec *RetentionPolicySpec, makeDefault bool) (_ *RetentionPolicyInfo, err error) {
+	defer func() { c.audit("create retention policy", err, auditRetentionPolicy(database, spec.Name)) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -321,7 +410,9 @@ func (c *Client) RetentionPolicy(database, name string) (rpi *RetentionPolicyInf
 }
 
 // DropRetentionPolicy drops a retention policy from a database.
-func (c *Client) DropRetentionPolicy(database, name string) error {
+func (c *Client) DropRetentionPolicy(database, name string) (err error) {
+	defer func() { c.audit("drop retention policy", err, auditRetentionPolicy(database, name)) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -338,8 +429,33 @@ func (c *Client) DropRetentionPolicy(database, name string) error {
 	return nil
 }
 
+// RenameRetentionPolicy renames a retention policy of a database.
+func (c *Client) RenameRetentionPolicy(database, name, newName string) (err error) {
+	defer func() {
+		c.audit("rename retention policy", err, auditRetentionPolicy(database, name), auditRetentionPolicy(database, newName))
+	}()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.RenameRetentionPolicy(database, name, newName); err != nil {
+		return err
+	}
+
+	rename := auditRetentionPolicy(database, name) + " to " + auditRetentionPolicy(database, newName)
+	if err := c.commitRename(data, rename); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // UpdateRetentionPolicy updates a retention policy.
-func (c *Client) UpdateRetentionPolicy(database, name string, rpu *RetentionPolicyUpdate, makeDefault bool) error {
+func (c *Client) UpdateRetentionPolicy(database, name string, rpu *RetentionPolicyUpdate, makeDefault bool) (err error) {
+	defer func() { c.audit("alter retention policy", err, auditRetentionPolicy(database, name)) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -406,7 +522,9 @@ func (c *Client) saltedHash(password string) (salt, hash []byte, err error) {
 }
 
 // CreateUser adds a user with the given name and password and admin status.
-func (c *Client) CreateUser(name, password string, admin bool) (User, error) {
+func (c *Client) CreateUser(name, password string, admin bool) (_ User, err error) {
+	defer func() { c.audit("create user", err, auditUser(name)) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -420,6 +538,10 @@ func (c *Client) CreateUser(name, password string, admin bool) (User, error) {
 		return u, nil
 	}
 
+	if err := c.passwordPolicy.Validate(password); err != nil {
+		return nil, err
+	}
+
 	// Hash the password before serializing it.
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
 	if err != nil {
@@ -431,6 +553,7 @@ func (c *Client) CreateUser(name, password string, admin bool) (User, error) {
 	}
 
 	u := data.user(name)
+	u.PasswordChanged = time.Now().UTC()
 
 	if err := c.commit(data); err != nil {
 		return nil, err
@@ -440,12 +563,18 @@ func (c *Client) CreateUser(name, password string, admin bool) (User, error) {
 }
 
 // UpdateUser updates the password of an existing user.
-func (c *Client) UpdateUser(name, password string) error {
+func (c *Client) UpdateUser(name, password string) (err error) {
+	defer func() { c.audit("set password", err, auditUser(name)) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	data := c.cacheData.Clone()
 
+	if err := c.passwordPolicy.Validate(password); err != nil {
+		return err
+	}
+
 	// Hash the password before serializing it.
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
 	if err != nil {
@@ -455,14 +584,20 @@ func (c *Client) UpdateUser(name, password string) error {
 	if err := data.UpdateUser(name, string(hash)); err != nil {
 		return err
 	}
+	data.user(name).PasswordChanged = time.Now().UTC()
 
 	delete(c.authCache, name)
 
+	// A new password unlocks the user.
+	c.logins.Reset(name)
+
 	return c.commit(data)
 }
 
 // DropUser removes the user with the given name.
-func (c *Client) DropUser(name string) error {
+func (c *Client) DropUser(name string) (err error) {
+	defer func() { c.audit("drop user", err, auditUser(name)) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -480,7 +615,9 @@ func (c *Client) DropUser(name string) error {
 }
 
 // SetPrivilege sets a privilege for the given user on the given database.
-func (c *Client) SetPrivilege(username, database string, p influxql.Privilege) error {
+func (c *Client) SetPrivilege(username, database string, p influxql.Privilege) (err error) {
+	defer func() { c.audit("set privilege", err, auditUser(username), auditDatabase(database)) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -498,7 +635,9 @@ func (c *Client) SetPrivilege(username, database string, p influxql.Privilege) e
 }
 
 // SetAdminPrivilege sets or unsets admin privilege to the given username.
-func (c *Client) SetAdminPrivilege(username string, admin bool) error {
+func (c *Client) SetAdminPrivilege(username string, admin bool) (err error) {
+	defer func() { c.audit("set admin privilege", err, auditUser(username)) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -515,6 +654,127 @@ func (c *Client) SetAdminPrivilege(username string, admin bool) error {
 	return nil
 }
 
+// SetUserQueryQuota sets the query quota of a user. A nil quota removes it.
+func (c *Client) SetUserQueryQuota(username string, q *query.Quota) (err error) {
+	defer func() { c.audit("set query quota", err, auditUser(username)) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetUserQueryQuota(username, q); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetUserReadGrants replaces the read grants of a user on a database.
+func (c *Client) SetUserReadGrants(username, database string, grants []ReadGrant) (err error) {
+	defer func() { c.audit("set read grants", err, auditUser(username), auditDatabase(database)) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetUserReadGrants(username, database, grants); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetDatabaseQueryQuota sets the query quota of a database. A nil quota
+// removes it.
+func (c *Client) SetDatabaseQueryQuota(name string, q *query.Quota) (err error) {
+	defer func() { c.audit("set query quota", err, auditDatabase(name)) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetDatabaseQueryQuota(name, q); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetDatabaseSchema sets the schema of a database. A nil schema removes it.
+func (c *Client) SetDatabaseSchema(name string, schema *DatabaseSchema) (err error) {
+	defer func() { c.audit("set schema", err, auditDatabase(name)) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetDatabaseSchema(name, schema); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetDatabaseWriteQuota sets the write quota of a database. A nil or empty
+// quota removes the database's quota.
+func (c *Client) SetDatabaseWriteQuota(name string, q *WriteQuota) (err error) {
+	defer func() { c.audit("set write quota", err, auditDatabase(name)) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetDatabaseWriteQuota(name, q); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// QueryQuotas returns the query quotas of a user and a database. Either
+// quota is nil if it is not set or the user or database does not exist.
+func (c *Client) QueryQuotas(username, database string) (userQuota, databaseQuota *query.Quota) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if username != "" {
+		if ui := c.cacheData.user(username); ui != nil {
+			userQuota = cloneQueryQuota(ui.QueryQuota)
+		}
+	}
+	if database != "" {
+		if di := c.cacheData.Database(database); di != nil {
+			databaseQuota = cloneQueryQuota(di.QueryQuota)
+		}
+	}
+	return userQuota, databaseQuota
+}
+
 // UserPrivileges returns the privileges for a user mapped by database name.
 func (c *Client) UserPrivileges(username string) (map[string]influxql.Privilege, error) {
 	c.mu.RLock()
@@ -547,6 +807,8 @@ func (c *Client) AdminUserExists() bool {
 }
 
 // Authenticate returns a UserInfo if the username and password match an existing entry.
+// Users are locked out after too many failed attempts, and expired passwords
+// return ErrPasswordExpired.
 func (c *Client) Authenticate(username, password string) (User, error) {
 	// Find user.
 	c.mu.RLock()
@@ -556,6 +818,18 @@ func (c *Client) Authenticate(username, password string) (User, error) {
 		return nil, ErrUserNotFound
 	}
 
+	now := time.Now()
+	if c.logins.Locked(username, now) {
+		return nil, ErrUserLocked
+	}
+
+	// Accept an API token of the user in place of the password.
+	if strings.HasPrefix(password, TokenPrefix) {
+		if u, err := c.AuthenticateToken(password); err == nil && u.ID() == username {
+			return u, nil
+		}
+	}
+
 	// Check the local auth cache first.
 	c.mu.RLock()
 	au, ok := c.authCache[username]
@@ -563,6 +837,10 @@ func (c *Client) Authenticate(username, password string) (User, error) {
 	if ok {
 		// verify the password using the cached salt and hash
 		if bytes.Equal(c.hashWithSalt(au.salt, password), au.hash) {
+			c.logins.Reset(username)
+			if err := c.checkPasswordAge(userInfo, now); err != nil {
+				return nil, err
+			}
 			return userInfo, nil
 		}
 
@@ -571,8 +849,12 @@ func (c *Client) Authenticate(username, password string) (User, error) {
 
 	// Compare password with user hash.
 	if err := bcrypt.CompareHashAndPassword([]byte(userInfo.Hash), []byte(password)); err != nil {
+		if c.logins.Fail(username, now) {
+			c.logger.Warn("User locked after failed logins", zap.String("user", username))
+		}
 		return nil, ErrAuthenticate
 	}
+	c.logins.Reset(username)
 
 	// generate a salt and hash of the password for the cache
 	salt, hashed, err := c.saltedHash(password)
@@ -582,9 +864,151 @@ func (c *Client) Authenticate(username, password string) (User, error) {
 	c.mu.Lock()
 	c.authCache[username] = authUser{salt: salt, hash: hashed, bhash: userInfo.Hash}
 	c.mu.Unlock()
+	if err := c.checkPasswordAge(userInfo, now); err != nil {
+		return nil, err
+	}
 	return userInfo, nil
 }
 
+// checkPasswordAge returns ErrPasswordExpired if the password of the user is
+// older than the maximum age. Passwords set before their time was recorded
+// do not expire until they are changed.
+func (c *Client) checkPasswordAge(u *UserInfo, now time.Time) error {
+	if c.passwordMaxAge > 0 && !u.PasswordChanged.IsZero() && now.Sub(u.PasswordChanged) > c.passwordMaxAge {
+		return ErrPasswordExpired
+	}
+	return nil
+}
+
+// UserLocked returns true if the user is locked out after too many failed
+// logins.
+func (c *Client) UserLocked(name string) bool {
+	return c.logins.Locked(name, time.Now())
+}
+
+// UnlockUser forgets the failed logins of a user, unlocking it if it is locked
+// out.
+func (c *Client) UnlockUser(name string) (err error) {
+	defer func() { c.audit("unlock user", err, auditUser(name)) }()
+
+	c.mu.RLock()
+	u := c.cacheData.User(name)
+	c.mu.RUnlock()
+	if u == nil {
+		return ErrUserNotFound
+	}
+
+	c.logins.Reset(name)
+	return nil
+}
+
+// AuthenticateToken returns the user of an API token, limited to the scopes of
+// the token.
+func (c *Client) AuthenticateToken(token string) (User, error) {
+	id, secret, ok := parseToken(token)
+	if !ok {
+		return nil, ErrAuthenticate
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ti := c.cacheData.Token(id)
+	if ti == nil || !ti.matchSecret(secret) {
+		return nil, ErrAuthenticate
+	} else if ti.Expired(time.Now()) {
+		return nil, ErrTokenExpired
+	}
+
+	u := c.cacheData.user(ti.User)
+	if u == nil {
+		return nil, ErrAuthenticate
+	}
+	return ti.scopedUser(u), nil
+}
+
+// Tokens returns a copy of the API tokens of all users.
+func (c *Client) Tokens() []TokenInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	tokens := make([]TokenInfo, len(c.cacheData.Tokens))
+	for i, ti := range c.cacheData.Tokens {
+		ti.Scopes = append([]string(nil), ti.Scopes...)
+		tokens[i] = ti
+	}
+	return tokens
+}
+
+// CreateToken creates an API token of a user with the given scopes. A zero
+// expiration creates a token that never expires. It returns the token, which
+// cannot be retrieved later.
+func (c *Client) CreateToken(username string, scopes []string, expiresAt time.Time, description string) (_ string, _ *TokenInfo, err error) {
+	defer func() { c.audit("create token", err, auditUser(username)) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	id, err := randomHex(8)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	ti := TokenInfo{
+		ID:          id,
+		User:        username,
+		Hash:        hashTokenSecret(secret),
+		Scopes:      scopes,
+		Description: description,
+		CreatedAt:   time.Now().UTC(),
+		ExpiresAt:   expiresAt,
+	}
+	if err := data.CreateToken(ti); err != nil {
+		return "", nil, err
+	}
+
+	if err := c.commit(data); err != nil {
+		return "", nil, err
+	}
+
+	return formatToken(id, secret), data.Token(id), nil
+}
+
+// DropToken removes an API token by ID.
+func (c *Client) DropToken(id string) (err error) {
+	defer func() { c.audit("drop token", err, "token:"+id) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.DropToken(id); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// randomHex returns n random bytes encoded as a hex string.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(crand.Reader, b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // UserCount returns the number of users stored.
 func (c *Client) UserCount() int {
 	c.mu.RLock()
@@ -659,7 +1083,9 @@ func (c *Client) ShardsByTimeRange(sources influxql.Sources, tmin, tmax time.Tim
 }
 
 // DropShard deletes a shard by ID.
-func (c *Client) DropShard(id uint64) error {
+func (c *Client) DropShard(id uint64) (err error) {
+	defer func() { c.audit("drop shard", err, "shard:"+strconv.FormatUint(id, 10)) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -757,7 +1183,11 @@ func createShardGroup(data *Data, database, policy string, timestamp time.Time)
 }
 
 // DeleteShardGroup removes a shard group from a database and retention policy by id.
-func (c *Client) DeleteShardGroup(database, policy string, id uint64) error {
+func (c *Client) DeleteShardGroup(database, policy string, id uint64) (err error) {
+	defer func() {
+		c.audit("delete shard group", err, "shard-group:"+database+"."+policy+"."+strconv.FormatUint(id, 10))
+	}()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -857,7 +1287,9 @@ func (c *Client) ShardOwner(shardID uint64) (database, policy string, sgi *Shard
 }
 
 // CreateContinuousQuery saves a continuous query with the given name for the given database.
-func (c *Client) CreateContinuousQuery(database, name, query string) error {
+func (c *Client) CreateContinuousQuery(database, name, query string) (err error) {
+	defer func() { c.audit("create continuous query", err, "continuous-query:"+database+"."+name) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -875,7 +1307,9 @@ func (c *Client) CreateContinuousQuery(database, name, query string) error {
 }
 
 // DropContinuousQuery removes the continuous query with the given name on the given database.
-func (c *Client) DropContinuousQuery(database, name string) error {
+func (c *Client) DropContinuousQuery(database, name string) (err error) {
+	defer func() { c.audit("drop continuous query", err, "continuous-query:"+database+"."+name) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -893,7 +1327,9 @@ func (c *Client) DropContinuousQuery(database, name string) error {
 }
 
 // CreateSubscription creates a subscription against the given database and retention policy.
-func (c *Client) CreateSubscription(database, rp, name, mode string, destinations []string) error {
+func (c *Client) CreateSubscription(database, rp, name, mode string, destinations []string) (err error) {
+	defer func() { c.audit("create subscription", err, auditSubscription(database, rp, name)) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -910,8 +1346,30 @@ func (c *Client) CreateSubscription(database, rp, name, mode string, destination
 	return nil
 }
 
+// SetSubscriptionFilter sets the filter of the named subscription.
+func (c *Client) SetSubscriptionFilter(database, rp, name string, filter *SubscriptionFilter) (err error) {
+	defer func() { c.audit("set subscription filter", err, auditSubscription(database, rp, name)) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetSubscriptionFilter(database, rp, name, filter); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // DropSubscription removes the named subscription from the given database and retention policy.
-func (c *Client) DropSubscription(database, rp, name string) error {
+func (c *Client) DropSubscription(database, rp, name string) (err error) {
+	defer func() { c.audit("drop subscription", err, auditSubscription(database, rp, name)) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -962,6 +1420,13 @@ func (c *Client) WaitForDataChanged() chan struct{} {
 // commit writes data to the underlying store.
 // This method assumes c's mutex is already locked.
 func (c *Client) commit(data *Data) error {
+	return c.commitRename(data, "")
+}
+
+// commitRename writes data renaming the database or retention policy
+// described by rename to the underlying store. The rename is recorded in the
+// history of the meta data. This method assumes c's mutex is already locked.
+func (c *Client) commitRename(data *Data, rename string) error {
 	data.Index++
 
 	// try to write to disk before updating in memory
@@ -972,6 +1437,11 @@ func (c *Client) commit(data *Data) error {
 	// update in memory
 	c.cacheData = data
 
+	// the change is committed even if it cannot be kept in the history
+	if err := c.recordHistory(data, rename); err != nil {
+		c.logger.Warn("Failed to record meta data version", zap.Uint64("index", data.Index), zap.Error(err))
+	}
+
 	// close channels to signal changes
 	close(c.changed)
 	c.changed = make(chan struct{})
@@ -993,6 +1463,36 @@ func (c *Client) WithLogger(log *zap.Logger) {
 	c.logger = log.With(zap.String("service", "metaclient"))
 }
 
+// WithAuditFunc sets the function recording the mutations of the meta data.
+// The function is called after the client is unlocked.
+func (c *Client) WithAuditFunc(fn AuditFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.auditFn = fn
+}
+
+// audit records a mutation with the audit function, if any.
+func (c *Client) audit(operation string, err error, objects ...string) {
+	c.mu.RLock()
+	fn := c.auditFn
+	c.mu.RUnlock()
+	if fn != nil {
+		fn(c.user, c.addr, operation, objects, err)
+	}
+}
+
+func auditDatabase(name string) string { return "database:" + name }
+
+func auditRetentionPolicy(database, name string) string {
+	return "retention-policy:" + database + "." + name
+}
+
+func auditUser(name string) string { return "user:" + name }
+
+func auditSubscription(database, rp, name string) string {
+	return "subscription:" + database + "." + rp + "." + name
+}
+
 // snapshot saves the current meta data to disk.
 func snapshot(path string, data *Data) error {
 	filename := filepath.Join(path, metaFile)
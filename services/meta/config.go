@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/toml"
 )
 
 const (
@@ -13,6 +14,14 @@ const (
 
 	// DefaultLoggingEnabled determines if log messages are printed for the meta service.
 	DefaultLoggingEnabled = true
+
+	// DefaultLoginLockoutDuration is the default duration a user is locked
+	// out for after too many failed logins.
+	DefaultLoginLockoutDuration = 5 * time.Minute
+
+	// DefaultHistorySize is the default number of versions of the meta data
+	// kept for rollbacks.
+	DefaultHistorySize = 50
 )
 
 // Config represents the meta configuration.
@@ -21,13 +30,39 @@ type Config struct {
 
 	RetentionAutoCreate bool `toml:"retention-autocreate"`
 	LoggingEnabled      bool `toml:"logging-enabled"`
+
+	// PasswordMinLength is the minimum length of new passwords.
+	PasswordMinLength int `toml:"password-min-length"`
+
+	// PasswordMinCharClasses is the minimum number of character classes,
+	// out of lowercase and uppercase letters, digits and other characters,
+	// of new passwords.
+	PasswordMinCharClasses int `toml:"password-min-char-classes"`
+
+	// PasswordMaxAge is the age at which passwords expire, or 0 if they
+	// never expire.
+	PasswordMaxAge toml.Duration `toml:"password-max-age"`
+
+	// LoginMaxFailures is the number of consecutive failed logins after which
+	// a user is locked out, or 0 to never lock users out.
+	LoginMaxFailures int `toml:"login-max-failures"`
+
+	// LoginLockoutDuration is the duration of the first lockout of a user.
+	// Each further failed login doubles it.
+	LoginLockoutDuration toml.Duration `toml:"login-lockout-duration"`
+
+	// HistorySize is the number of versions of the meta data kept for
+	// rollbacks, or 0 to keep no history.
+	HistorySize int `toml:"history-size"`
 }
 
 // NewConfig builds a new configuration with default values.
 func NewConfig() *Config {
 	return &Config{
-		RetentionAutoCreate: true,
-		LoggingEnabled:      DefaultLoggingEnabled,
+		RetentionAutoCreate:  true,
+		LoggingEnabled:       DefaultLoggingEnabled,
+		LoginLockoutDuration: toml.Duration(DefaultLoginLockoutDuration),
+		HistorySize:          DefaultHistorySize,
 	}
 }
 
@@ -36,12 +71,36 @@ func (c *Config) Validate() error {
 	if c.Dir == "" {
 		return errors.New("Meta.Dir must be specified")
 	}
+	if c.PasswordMinLength < 0 {
+		return errors.New("password-min-length must be non-negative")
+	}
+	if c.PasswordMinCharClasses < 0 || c.PasswordMinCharClasses > 4 {
+		return errors.New("password-min-char-classes must be between 0 and 4")
+	}
+	if c.PasswordMaxAge < 0 {
+		return errors.New("password-max-age must be non-negative")
+	}
+	if c.LoginMaxFailures < 0 {
+		return errors.New("login-max-failures must be non-negative")
+	}
+	if c.LoginMaxFailures > 0 && c.LoginLockoutDuration <= 0 {
+		return errors.New("login-lockout-duration must be positive")
+	}
+	if c.HistorySize < 0 {
+		return errors.New("history-size must be non-negative")
+	}
 	return nil
 }
 
 // Diagnostics returns a diagnostics representation of a subset of the Config.
 func (c *Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 	return diagnostics.RowFromMap(map[string]interface{}{
-		"dir": c.Dir,
+		"dir":                       c.Dir,
+		"password-min-length":       c.PasswordMinLength,
+		"password-min-char-classes": c.PasswordMinCharClasses,
+		"password-max-age":          c.PasswordMaxAge,
+		"login-max-failures":        c.LoginMaxFailures,
+		"login-lockout-duration":    c.LoginLockoutDuration,
+		"history-size":              c.HistorySize,
 	}), nil
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -46,6 +47,7 @@ type Data struct {
 	ClusterID uint64
 	Databases []DatabaseInfo
 	Users     []UserInfo
+	Tokens    []TokenInfo
 
 	// adminUserExists provides a constant time mechanism for determining
 	// if there is at least one admin user.
@@ -101,9 +103,11 @@ func (data *Data) DropDatabase(name string) error {
 		if data.Databases[i].Name == name {
 			data.Databases = append(data.Databases[:i], data.Databases[i+1:]...)
 
-			// Remove all user privileges associated with this database.
+			// Remove all user privileges and read grants associated with
+			// this database.
 			for i := range data.Users {
 				delete(data.Users[i].Privileges, name)
+				data.Users[i].removeReadGrants(name)
 			}
 			break
 		}
@@ -111,6 +115,62 @@ func (data *Data) DropDatabase(name string) error {
 	return nil
 }
 
+// RenameDatabase renames a database. The privileges, read grants and token
+// scopes of the users on the database and the continuous queries referencing
+// it are updated to the new name.
+func (data *Data) RenameDatabase(name, newName string) error {
+	if newName == "" {
+		return ErrDatabaseNameRequired
+	} else if len(newName) > MaxNameLen {
+		return ErrNameTooLong
+	}
+
+	di := data.Database(name)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(name)
+	} else if newName == name {
+		return nil
+	} else if data.Database(newName) != nil {
+		return ErrDatabaseExists
+	}
+	di.Name = newName
+
+	for i := range data.Users {
+		ui := &data.Users[i]
+		if p, ok := ui.Privileges[name]; ok {
+			delete(ui.Privileges, name)
+			ui.Privileges[newName] = p
+		}
+		for j := range ui.ReadGrants {
+			if ui.ReadGrants[j].Database == name {
+				ui.ReadGrants[j].Database = newName
+			}
+		}
+	}
+
+	for i := range data.Tokens {
+		for j, scope := range data.Tokens[i].Scopes {
+			if _, db, err := ParseTokenScope(scope); err == nil && db == name {
+				data.Tokens[i].Scopes[j] = scope[:len(scope)-len(db)] + newName
+			}
+		}
+	}
+
+	return data.renameContinuousQueries(func(m *influxql.Measurement, database string) bool {
+		if m.Database != name {
+			return false
+		}
+		m.Database = newName
+		return true
+	}, func(stmt *influxql.CreateContinuousQueryStatement) bool {
+		if stmt.Database != name {
+			return false
+		}
+		stmt.Database = newName
+		return true
+	})
+}
+
 // RetentionPolicy returns a retention policy for a database by name.
 func (data *Data) RetentionPolicy(database, name string) (*RetentionPolicyInfo, error) {
 	di := data.Database(database)
@@ -196,6 +256,43 @@ func (data *Data) DropRetentionPolicy(database, name string) error {
 	return nil
 }
 
+// RenameRetentionPolicy renames a retention policy of a database. The
+// default retention policy of the database and the continuous queries
+// referencing the policy are updated to the new name.
+func (data *Data) RenameRetentionPolicy(database, name, newName string) error {
+	if newName == "" {
+		return ErrRetentionPolicyNameRequired
+	} else if len(newName) > MaxNameLen {
+		return ErrNameTooLong
+	}
+
+	di := data.Database(database)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(database)
+	}
+	rpi := di.RetentionPolicy(name)
+	if rpi == nil {
+		return influxdb.ErrRetentionPolicyNotFound(name)
+	} else if newName == name {
+		return nil
+	} else if di.RetentionPolicy(newName) != nil {
+		return ErrRetentionPolicyNameExists
+	}
+	rpi.Name = newName
+
+	if di.DefaultRetentionPolicy == name {
+		di.DefaultRetentionPolicy = newName
+	}
+
+	return data.renameContinuousQueries(func(m *influxql.Measurement, db string) bool {
+		if db != database || m.RetentionPolicy != name {
+			return false
+		}
+		m.RetentionPolicy = newName
+		return true
+	}, nil)
+}
+
 // RetentionPolicyUpdate represents retention policy fields to be updated.
 type RetentionPolicyUpdate struct {
 	Name               *string
@@ -460,27 +557,68 @@ func (data *Data) DropContinuousQuery(database, name string) error {
 	return nil
 }
 
-// validateURL returns an error if the URL does not have a port or uses a scheme other than UDP or HTTP.
+// subscriptionSchemes holds the URL schemes allowed for the destinations of
+// subscriptions and the functions validating them.
+var subscriptionSchemes = struct {
+	sync.RWMutex
+	m map[string]func(u *url.URL) error
+}{
+	m: map[string]func(u *url.URL) error{
+		"udp":   ValidateHostPort,
+		"http":  ValidateHostPort,
+		"https": ValidateHostPort,
+	},
+}
+
+// RegisterSubscriptionScheme allows the destinations of subscriptions to use
+// the URL scheme. If validate is not nil, it returns an error if a
+// destination is invalid.
+func RegisterSubscriptionScheme(scheme string, validate func(u *url.URL) error) {
+	subscriptionSchemes.Lock()
+	defer subscriptionSchemes.Unlock()
+	subscriptionSchemes.m[scheme] = validate
+}
+
+// validateURL returns an error if the URL uses a scheme that has not been
+// registered or is not valid for its scheme.
 func validateURL(input string) error {
 	u, err := url.Parse(input)
 	if err != nil {
 		return ErrInvalidSubscriptionURL(input)
 	}
 
-	if u.Scheme != "udp" && u.Scheme != "http" && u.Scheme != "https" {
+	subscriptionSchemes.RLock()
+	validate, ok := subscriptionSchemes.m[u.Scheme]
+	subscriptionSchemes.RUnlock()
+	if !ok {
 		return ErrInvalidSubscriptionURL(input)
 	}
 
-	_, port, err := net.SplitHostPort(u.Host)
-	if err != nil || port == "" {
-		return ErrInvalidSubscriptionURL(input)
+	if validate != nil {
+		if err := validate(u); err != nil {
+			return ErrInvalidSubscriptionURL(input)
+		}
 	}
 
 	return nil
 }
 
+// ValidateHostPort returns an error if the URL does not have a port.
+func ValidateHostPort(u *url.URL) error {
+	_, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return err
+	} else if port == "" {
+		return errors.New("missing port")
+	}
+	return nil
+}
+
 // CreateSubscription adds a named subscription to a database and retention policy.
 func (data *Data) CreateSubscription(database, rp, name, mode string, destinations []string) error {
+	if !ValidName(name) {
+		return ErrInvalidName
+	}
 	for _, d := range destinations {
 		if err := validateURL(d); err != nil {
 			return err
@@ -511,6 +649,31 @@ func (data *Data) CreateSubscription(database, rp, name, mode string, destinatio
 	return nil
 }
 
+// SetSubscriptionFilter sets the filter of a subscription. A nil filter sends
+// all points to the destinations of the subscription.
+func (data *Data) SetSubscriptionFilter(database, rp, name string, filter *SubscriptionFilter) error {
+	if filter != nil {
+		if err := filter.Validate(); err != nil {
+			return err
+		}
+	}
+
+	rpi, err := data.RetentionPolicy(database, rp)
+	if err != nil {
+		return err
+	} else if rpi == nil {
+		return influxdb.ErrRetentionPolicyNotFound(rp)
+	}
+
+	for i := range rpi.Subscriptions {
+		if rpi.Subscriptions[i].Name == name {
+			rpi.Subscriptions[i].Filter = filter.clone()
+			return nil
+		}
+	}
+	return ErrSubscriptionNotFound
+}
+
 // DropSubscription removes a subscription.
 func (data *Data) DropSubscription(database, rp, name string) error {
 	rpi, err := data.RetentionPolicy(database, rp)
@@ -579,6 +742,15 @@ func (data *Data) DropUser(name string) error {
 			wasAdmin := data.Users[i].Admin
 			data.Users = append(data.Users[:i], data.Users[i+1:]...)
 
+			// Remove all tokens of the user.
+			tokens := data.Tokens[:0]
+			for _, ti := range data.Tokens {
+				if ti.User != name {
+					tokens = append(tokens, ti)
+				}
+			}
+			data.Tokens = tokens
+
 			// Maybe we dropped the only admin user?
 			if wasAdmin {
 				data.adminUserExists = data.hasAdminUser()
@@ -648,6 +820,120 @@ func (data *Data) SetAdminPrivilege(name string, admin bool) error {
 	return nil
 }
 
+// SetUserQueryQuota sets the query quota of a user. A nil or empty quota
+// removes the user's quota.
+func (data *Data) SetUserQueryQuota(name string, q *query.Quota) error {
+	ui := data.user(name)
+	if ui == nil {
+		return ErrUserNotFound
+	}
+
+	ui.QueryQuota = cloneQueryQuota(q)
+	return nil
+}
+
+// SetUserReadGrants replaces the read grants of a user on a database. No
+// grants restore the user's access to all series of the database.
+func (data *Data) SetUserReadGrants(name, database string, grants []ReadGrant) error {
+	ui := data.user(name)
+	if ui == nil {
+		return ErrUserNotFound
+	}
+
+	if data.Database(database) == nil {
+		return influxdb.ErrDatabaseNotFound(database)
+	}
+
+	ui.removeReadGrants(database)
+	for _, g := range grants {
+		g.Database = database
+		if err := g.Validate(); err != nil {
+			return err
+		}
+		g.compile()
+		ui.ReadGrants = append(ui.ReadGrants, g)
+	}
+	return nil
+}
+
+// SetDatabaseQueryQuota sets the query quota of a database. A nil or empty
+// quota removes the database's quota.
+func (data *Data) SetDatabaseQueryQuota(name string, q *query.Quota) error {
+	di := data.Database(name)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(name)
+	}
+
+	di.QueryQuota = cloneQueryQuota(q)
+	return nil
+}
+
+// SetDatabaseSchema sets the schema of a database. A nil schema removes it.
+func (data *Data) SetDatabaseSchema(name string, schema *DatabaseSchema) error {
+	di := data.Database(name)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(name)
+	}
+
+	if schema != nil {
+		if err := schema.Validate(); err != nil {
+			return err
+		}
+	}
+	di.Schema = schema.clone()
+	return nil
+}
+
+// SetDatabaseWriteQuota sets the write quota of a database. A nil or empty
+// quota removes the database's quota.
+func (data *Data) SetDatabaseWriteQuota(name string, q *WriteQuota) error {
+	di := data.Database(name)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(name)
+	}
+
+	if err := q.Validate(); err != nil {
+		return err
+	}
+	di.WriteQuota = q.clone()
+	return nil
+}
+
+// Token returns a token by ID.
+func (data *Data) Token(id string) *TokenInfo {
+	for i := range data.Tokens {
+		if data.Tokens[i].ID == id {
+			return &data.Tokens[i]
+		}
+	}
+	return nil
+}
+
+// CreateToken creates a new API token of a user.
+func (data *Data) CreateToken(ti TokenInfo) error {
+	if err := ti.Validate(); err != nil {
+		return err
+	} else if data.user(ti.User) == nil {
+		return ErrUserNotFound
+	} else if data.Token(ti.ID) != nil {
+		return fmt.Errorf("token %s already exists", ti.ID)
+	}
+
+	data.Tokens = append(data.Tokens, ti.clone())
+	return nil
+}
+
+// DropToken removes a token by ID.
+func (data *Data) DropToken(id string) error {
+	for i := range data.Tokens {
+		if data.Tokens[i].ID == id {
+			data.Tokens = append(data.Tokens[:i], data.Tokens[i+1:]...)
+			return nil
+		}
+	}
+	return ErrTokenNotFound
+}
+
 // AdminUserExists returns true if an admin user exists.
 func (data Data) AdminUserExists() bool {
 	return data.adminUserExists
@@ -686,6 +972,13 @@ func (data *Data) Clone() *Data {
 	other.Databases = data.CloneDatabases()
 	other.Users = data.CloneUsers()
 
+	if data.Tokens != nil {
+		other.Tokens = make([]TokenInfo, len(data.Tokens))
+		for i := range data.Tokens {
+			other.Tokens[i] = data.Tokens[i].clone()
+		}
+	}
+
 	return &other
 }
 
@@ -713,6 +1006,10 @@ func (data *Data) marshal() *internal.Data {
 		pb.Users[i] = data.Users[i].marshal()
 	}
 
+	for i := range data.Tokens {
+		pb.Tokens = append(pb.Tokens, data.Tokens[i].marshal())
+	}
+
 	return pb
 }
 
@@ -735,6 +1032,14 @@ func (data *Data) unmarshal(pb *internal.Data) {
 		data.Users[i].unmarshal(x)
 	}
 
+	data.Tokens = nil
+	if len(pb.GetTokens()) > 0 {
+		data.Tokens = make([]TokenInfo, len(pb.GetTokens()))
+		for i, x := range pb.GetTokens() {
+			data.Tokens[i].unmarshal(x)
+		}
+	}
+
 	// Exhaustively determine if there is an admin user. The marshalled cache
 	// value may not be correct.
 	data.adminUserExists = data.hasAdminUser()
@@ -917,6 +1222,16 @@ type DatabaseInfo struct {
 	DefaultRetentionPolicy string
 	RetentionPolicies      []RetentionPolicyInfo
 	ContinuousQueries      []ContinuousQueryInfo
+	QueryQuota             *query.Quota
+
+	// Schema declares the points that may be written to the database. Any
+	// point may be written if it is nil.
+	Schema *DatabaseSchema
+
+	// WriteQuota limits the series, disk usage and write rate of the
+	// database. It is nil if the database is only limited by the global
+	// limits.
+	WriteQuota *WriteQuota
 }
 
 // RetentionPolicy returns a retention policy by name.
@@ -980,6 +1295,10 @@ func (di DatabaseInfo) clone() DatabaseInfo {
 		}
 	}
 
+	other.QueryQuota = cloneQueryQuota(di.QueryQuota)
+	other.Schema = di.Schema.clone()
+	other.WriteQuota = di.WriteQuota.clone()
+
 	return other
 }
 
@@ -998,6 +1317,10 @@ func (di DatabaseInfo) marshal() *internal.DatabaseInfo {
 	for i := range di.ContinuousQueries {
 		pb.ContinuousQueries[i] = di.ContinuousQueries[i].marshal()
 	}
+
+	pb.QueryQuota = marshalQueryQuota(di.QueryQuota)
+	pb.Schema = marshalDatabaseSchema(di.Schema)
+	pb.WriteQuota = marshalWriteQuota(di.WriteQuota)
 	return pb
 }
 
@@ -1019,6 +1342,10 @@ func (di *DatabaseInfo) unmarshal(pb *internal.DatabaseInfo) {
 			di.ContinuousQueries[i].unmarshal(x)
 		}
 	}
+
+	di.QueryQuota = unmarshalQueryQuota(pb.GetQueryQuota())
+	di.Schema = unmarshalDatabaseSchema(pb.GetSchema())
+	di.WriteQuota = unmarshalWriteQuota(pb.GetWriteQuota())
 }
 
 // RetentionPolicySpec represents the specification for a new retention policy.
@@ -1244,6 +1571,13 @@ func (rpi RetentionPolicyInfo) clone() RetentionPolicyInfo {
 		}
 	}
 
+	if rpi.Subscriptions != nil {
+		other.Subscriptions = make([]SubscriptionInfo, len(rpi.Subscriptions))
+		for i := range rpi.Subscriptions {
+			other.Subscriptions[i] = rpi.Subscriptions[i].clone()
+		}
+	}
+
 	return other
 }
 
@@ -1501,6 +1835,21 @@ type SubscriptionInfo struct {
 	Name         string
 	Mode         string
 	Destinations []string
+
+	// Filter selects the points sent to the destinations. All points are
+	// sent if it is nil.
+	Filter *SubscriptionFilter
+}
+
+// clone returns a deep copy of si.
+func (si SubscriptionInfo) clone() SubscriptionInfo {
+	other := si
+	if si.Destinations != nil {
+		other.Destinations = make([]string, len(si.Destinations))
+		copy(other.Destinations, si.Destinations)
+	}
+	other.Filter = si.Filter.clone()
+	return other
 }
 
 // marshal serializes to a protobuf representation.
@@ -1514,6 +1863,14 @@ func (si SubscriptionInfo) marshal() *internal.SubscriptionInfo {
 	for i := range si.Destinations {
 		pb.Destinations[i] = si.Destinations[i]
 	}
+
+	if si.Filter != nil {
+		pb.Filter = &internal.SubscriptionFilter{
+			Measurements:     si.Filter.Measurements,
+			MeasurementRegex: proto.String(si.Filter.MeasurementRegex),
+			Condition:        proto.String(si.Filter.Condition),
+		}
+	}
 	return pb
 }
 
@@ -1526,6 +1883,119 @@ func (si *SubscriptionInfo) unmarshal(pb *internal.SubscriptionInfo) {
 		si.Destinations = make([]string, len(pb.GetDestinations()))
 		copy(si.Destinations, pb.GetDestinations())
 	}
+
+	if f := pb.GetFilter(); f != nil {
+		si.Filter = &SubscriptionFilter{
+			MeasurementRegex: f.GetMeasurementRegex(),
+			Condition:        f.GetCondition(),
+		}
+		if len(f.GetMeasurements()) > 0 {
+			si.Filter.Measurements = make([]string, len(f.GetMeasurements()))
+			copy(si.Filter.Measurements, f.GetMeasurements())
+		}
+	}
+}
+
+// SubscriptionFilter selects the points of a subscription by measurement and
+// tags. A point is selected if its measurement is one of Measurements or
+// matches MeasurementRegex, and its tags match Condition. Empty fields select
+// all points.
+type SubscriptionFilter struct {
+	Measurements     []string
+	MeasurementRegex string
+
+	// Condition is an InfluxQL expression comparing tags to strings or
+	// regular expressions, such as "host = 'a' OR region =~ /^us-/".
+	Condition string
+}
+
+// clone returns a deep copy of f.
+func (f *SubscriptionFilter) clone() *SubscriptionFilter {
+	if f == nil {
+		return nil
+	}
+	other := *f
+	if f.Measurements != nil {
+		other.Measurements = make([]string, len(f.Measurements))
+		copy(other.Measurements, f.Measurements)
+	}
+	return &other
+}
+
+// Validate returns an error if the measurement regex or the condition of the
+// filter is invalid.
+func (f *SubscriptionFilter) Validate() error {
+	if f.MeasurementRegex != "" {
+		if _, err := regexp.Compile(f.MeasurementRegex); err != nil {
+			return fmt.Errorf("invalid measurement regex: %s", err)
+		}
+	}
+	if f.Condition != "" {
+		expr, err := influxql.ParseExpr(f.Condition)
+		if err != nil {
+			return fmt.Errorf("invalid condition: %s", err)
+		}
+		if err := validateTagCondition(expr); err != nil {
+			return fmt.Errorf("invalid condition: %s", err)
+		}
+	}
+	return nil
+}
+
+// validateTagCondition returns an error unless expr only compares tags to
+// strings and regular expressions.
+func validateTagCondition(expr influxql.Expr) error {
+	switch expr := expr.(type) {
+	case *influxql.ParenExpr:
+		return validateTagCondition(expr.Expr)
+	case *influxql.BinaryExpr:
+		switch expr.Op {
+		case influxql.AND, influxql.OR:
+			if err := validateTagCondition(expr.LHS); err != nil {
+				return err
+			}
+			return validateTagCondition(expr.RHS)
+		case influxql.EQ, influxql.NEQ:
+			if _, ok := expr.LHS.(*influxql.VarRef); ok {
+				if _, ok := expr.RHS.(*influxql.StringLiteral); ok {
+					return nil
+				}
+			}
+		case influxql.EQREGEX, influxql.NEQREGEX:
+			if _, ok := expr.LHS.(*influxql.VarRef); ok {
+				if _, ok := expr.RHS.(*influxql.RegexLiteral); ok {
+					return nil
+				}
+			}
+		}
+	}
+	return fmt.Errorf("unsupported expression: %s", expr)
+}
+
+// String returns the filter as the FROM and WHERE clauses of a statement.
+func (f *SubscriptionFilter) String() string {
+	var buf strings.Builder
+	var sources []string
+	for _, name := range f.Measurements {
+		sources = append(sources, influxql.QuoteIdent(name))
+	}
+	if f.MeasurementRegex != "" {
+		if re, err := regexp.Compile(f.MeasurementRegex); err == nil {
+			sources = append(sources, (&influxql.RegexLiteral{Val: re}).String())
+		}
+	}
+	if len(sources) > 0 {
+		buf.WriteString("FROM ")
+		buf.WriteString(strings.Join(sources, ", "))
+	}
+	if f.Condition != "" {
+		if buf.Len() > 0 {
+			buf.WriteString(" ")
+		}
+		buf.WriteString("WHERE ")
+		buf.WriteString(f.Condition)
+	}
+	return buf.String()
 }
 
 // ShardOwner represents a node that owns a shard.
@@ -1573,6 +2043,49 @@ func (cqi *ContinuousQueryInfo) unmarshal(pb *internal.ContinuousQueryInfo) {
 	cqi.Query = pb.GetQuery()
 }
 
+// renameContinuousQueries rewrites the queries of the continuous queries of
+// all databases. fn is called with every measurement of the query and the
+// database the measurement belongs to, and stmtFn, if not nil, with the
+// statement itself. Both return true if they changed the query; only changed
+// queries are rewritten.
+func (data *Data) renameContinuousQueries(fn func(m *influxql.Measurement, database string) bool, stmtFn func(stmt *influxql.CreateContinuousQueryStatement) bool) error {
+	for i := range data.Databases {
+		di := &data.Databases[i]
+		for j := range di.ContinuousQueries {
+			cqi := &di.ContinuousQueries[j]
+			stmt, err := influxql.ParseStatement(cqi.Query)
+			if err != nil {
+				return fmt.Errorf("continuous query %s: %s", cqi.Name, err)
+			}
+			cq, ok := stmt.(*influxql.CreateContinuousQueryStatement)
+			if !ok {
+				return fmt.Errorf("continuous query %s: invalid query", cqi.Name)
+			}
+
+			var changed bool
+			influxql.WalkFunc(cq.Source, func(n influxql.Node) {
+				if m, ok := n.(*influxql.Measurement); ok {
+					db := m.Database
+					if db == "" {
+						db = cq.Database
+					}
+					if fn(m, db) {
+						changed = true
+					}
+				}
+			})
+			if stmtFn != nil && stmtFn(cq) {
+				changed = true
+			}
+
+			if changed {
+				cqi.Query = cq.String()
+			}
+		}
+	}
+	return nil
+}
+
 var _ query.FineAuthorizer = (*UserInfo)(nil)
 
 // UserInfo represents metadata about a user in the system.
@@ -1588,6 +2101,24 @@ type UserInfo struct {
 
 	// Map of database name to granted privilege.
 	Privileges map[string]influxql.Privilege
+
+	// Limits that override the global query limits for the user's queries.
+	QueryQuota *query.Quota
+
+	// Series the user may read, limiting the user's read privileges on the
+	// databases of the grants.
+	ReadGrants []ReadGrant
+
+	// Time the password was last set, or zero if unknown.
+	PasswordChanged time.Time
+
+	// ID of the API token the user authenticated with, limiting the user's
+	// privileges to the scopes of the token. It is not stored.
+	Token string
+
+	// Whether the privileges of the user were granted by an external
+	// identity provider instead of being stored. It is not stored.
+	External bool
 }
 
 type User interface {
@@ -1609,9 +2140,23 @@ func (ui *UserInfo) AuthorizeDatabase(privilege influxql.Privilege, database str
 	return ok && (p == privilege || p == influxql.AllPrivileges)
 }
 
-// AuthorizeSeriesRead is used to limit access per-series (enterprise only)
+// AuthorizeSeriesRead returns true if the user may read a series. A user with
+// read grants on the database may only read the series matching one of them.
 func (u *UserInfo) AuthorizeSeriesRead(database string, measurement []byte, tags models.Tags) bool {
-	return true
+	if u.Admin {
+		return true
+	}
+	var granted bool
+	for i := range u.ReadGrants {
+		g := &u.ReadGrants[i]
+		if g.Database != database {
+			continue
+		} else if g.Match(measurement, tags) {
+			return true
+		}
+		granted = true
+	}
+	return !granted
 }
 
 // AuthorizeSeriesWrite is used to limit access per-series (enterprise only)
@@ -1621,7 +2166,21 @@ func (u *UserInfo) AuthorizeSeriesWrite(database string, measurement []byte, tag
 
 // IsOpen is a method on FineAuthorizer to indicate all fine auth is permitted and short circuit some checks.
 func (u *UserInfo) IsOpen() bool {
-	return true
+	return u.Admin || len(u.ReadGrants) == 0
+}
+
+// removeReadGrants removes the read grants of the user on a database.
+func (u *UserInfo) removeReadGrants(database string) {
+	grants := u.ReadGrants[:0]
+	for _, g := range u.ReadGrants {
+		if g.Database != database {
+			grants = append(grants, g)
+		}
+	}
+	if len(grants) == 0 {
+		grants = nil
+	}
+	u.ReadGrants = grants
 }
 
 // AuthorizeUnrestricted identifies the admin user
@@ -1642,6 +2201,13 @@ func (ui UserInfo) clone() UserInfo {
 		}
 	}
 
+	other.QueryQuota = cloneQueryQuota(ui.QueryQuota)
+
+	if ui.ReadGrants != nil {
+		other.ReadGrants = make([]ReadGrant, len(ui.ReadGrants))
+		copy(other.ReadGrants, ui.ReadGrants)
+	}
+
 	return other
 }
 
@@ -1660,6 +2226,16 @@ func (ui UserInfo) marshal() *internal.UserInfo {
 		})
 	}
 
+	pb.QueryQuota = marshalQueryQuota(ui.QueryQuota)
+
+	for _, g := range ui.ReadGrants {
+		pb.ReadGrants = append(pb.ReadGrants, marshalReadGrant(g))
+	}
+
+	if !ui.PasswordChanged.IsZero() {
+		pb.PasswordChanged = proto.Int64(ui.PasswordChanged.UnixNano())
+	}
+
 	return pb
 }
 
@@ -1673,6 +2249,55 @@ func (ui *UserInfo) unmarshal(pb *internal.UserInfo) {
 	for _, p := range pb.GetPrivileges() {
 		ui.Privileges[p.GetDatabase()] = influxql.Privilege(p.GetPrivilege())
 	}
+
+	ui.QueryQuota = unmarshalQueryQuota(pb.GetQueryQuota())
+
+	ui.ReadGrants = nil
+	for _, g := range pb.GetReadGrants() {
+		ui.ReadGrants = append(ui.ReadGrants, unmarshalReadGrant(g))
+	}
+
+	ui.PasswordChanged = time.Time{}
+	if pb.PasswordChanged != nil {
+		ui.PasswordChanged = time.Unix(0, pb.GetPasswordChanged()).UTC()
+	}
+}
+
+// cloneQueryQuota returns a copy of q, or nil if q does not set any limit.
+func cloneQueryQuota(q *query.Quota) *query.Quota {
+	if q.IsZero() {
+		return nil
+	}
+	other := *q
+	return &other
+}
+
+// marshalQueryQuota serializes a query quota to a protobuf representation.
+func marshalQueryQuota(q *query.Quota) *internal.QueryQuota {
+	if q.IsZero() {
+		return nil
+	}
+	return &internal.QueryQuota{
+		MaxConcurrentQueries: proto.Int64(int64(q.MaxConcurrentQueries)),
+		QueryTimeout:         proto.Int64(int64(q.QueryTimeout)),
+		MaxSelectPointN:      proto.Int64(int64(q.MaxSelectPointN)),
+		MaxSelectSeriesN:     proto.Int64(int64(q.MaxSelectSeriesN)),
+		MaxSelectBucketsN:    proto.Int64(int64(q.MaxSelectBucketsN)),
+	}
+}
+
+// unmarshalQueryQuota deserializes a query quota from a protobuf representation.
+func unmarshalQueryQuota(pb *internal.QueryQuota) *query.Quota {
+	if pb == nil {
+		return nil
+	}
+	return &query.Quota{
+		MaxConcurrentQueries: int(pb.GetMaxConcurrentQueries()),
+		QueryTimeout:         time.Duration(pb.GetQueryTimeout()),
+		MaxSelectPointN:      int(pb.GetMaxSelectPointN()),
+		MaxSelectSeriesN:     int(pb.GetMaxSelectSeriesN()),
+		MaxSelectBucketsN:    int(pb.GetMaxSelectBucketsN()),
+	}
 }
 
 // Lease represents a lease held on a resource.
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/testing/assert"
+	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxql"
 
 	"github.com/influxdata/influxdb/services/meta"
@@ -247,6 +249,261 @@ func TestData_SetPrivilege(t *testing.T) {
 	}
 }
 
+func TestData_SetQueryQuota(t *testing.T) {
+	data := meta.Data{}
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := data.CreateUser("user1", "", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := data.SetUserQueryQuota("not a user", &query.Quota{}), meta.ErrUserNotFound; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+	if got, exp := data.SetDatabaseQueryQuota("db1", &query.Quota{}), influxdb.ErrDatabaseNotFound("db1"); got == nil || got.Error() != exp.Error() {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+
+	uq := &query.Quota{MaxConcurrentQueries: 2, QueryTimeout: time.Minute}
+	dq := &query.Quota{MaxSelectPointN: 10, MaxSelectSeriesN: 20, MaxSelectBucketsN: 30}
+	if err := data.SetUserQueryQuota("user1", uq); err != nil {
+		t.Fatal(err)
+	}
+	if err := data.SetDatabaseQueryQuota("db0", dq); err != nil {
+		t.Fatal(err)
+	}
+
+	// The quotas survive a round trip through the protobuf representation.
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.Data
+	if err := other.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := other.Users[0].QueryQuota; !reflect.DeepEqual(got, uq) {
+		t.Fatalf("unexpected user quota: %+v", got)
+	}
+	if got := other.Database("db0").QueryQuota; !reflect.DeepEqual(got, dq) {
+		t.Fatalf("unexpected database quota: %+v", got)
+	}
+
+	// An empty quota removes the quota.
+	if err := other.SetUserQueryQuota("user1", &query.Quota{}); err != nil {
+		t.Fatal(err)
+	} else if other.Users[0].QueryQuota != nil {
+		t.Fatalf("unexpected user quota: %+v", other.Users[0].QueryQuota)
+	}
+}
+
+func TestData_SetSubscriptionFilter(t *testing.T) {
+	data := meta.Data{}
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+	rpi := meta.DefaultRetentionPolicyInfo()
+	if err := data.CreateRetentionPolicy("db0", rpi, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := data.CreateSubscription("db0", rpi.Name, "s0", "ALL", []string{"udp://h0:9093"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := data.SetSubscriptionFilter("db0", rpi.Name, "s1", nil), meta.ErrSubscriptionNotFound; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+	for _, f := range []*meta.SubscriptionFilter{
+		{MeasurementRegex: "("},
+		{Condition: "host ="},
+		{Condition: "value > 1"},
+	} {
+		if err := data.SetSubscriptionFilter("db0", rpi.Name, "s0", f); err == nil {
+			t.Fatalf("expected error for filter %+v", f)
+		}
+	}
+
+	filter := &meta.SubscriptionFilter{
+		Measurements:     []string{"cpu", "mem"},
+		MeasurementRegex: "^disk",
+		Condition:        "host = 'a' OR region =~ /^us-/",
+	}
+	if err := data.SetSubscriptionFilter("db0", rpi.Name, "s0", filter); err != nil {
+		t.Fatal(err)
+	}
+
+	// The filter survives a round trip through the protobuf representation.
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.Data
+	if err := other.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	got := other.Database("db0").RetentionPolicy(rpi.Name).Subscriptions[0].Filter
+	if !reflect.DeepEqual(got, filter) {
+		t.Fatalf("unexpected filter: %+v", got)
+	}
+	if exp := `FROM cpu, mem, /^disk/ WHERE host = 'a' OR region =~ /^us-/`; got.String() != exp {
+		t.Fatalf("unexpected filter string: got %s, exp %s", got, exp)
+	}
+
+	// Changing the filter of a clone does not change the original.
+	clone := other.Clone()
+	if err := clone.SetSubscriptionFilter("db0", rpi.Name, "s0", nil); err != nil {
+		t.Fatal(err)
+	} else if other.Database("db0").RetentionPolicy(rpi.Name).Subscriptions[0].Filter == nil {
+		t.Fatal("expected filter of the original to be unchanged")
+	}
+}
+
+func TestData_SetDatabaseSchema(t *testing.T) {
+	data := meta.Data{}
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := data.SetDatabaseSchema("db1", nil), influxdb.ErrDatabaseNotFound("db1"); got == nil || got.Error() != exp.Error() {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+	for _, s := range []*meta.DatabaseSchema{
+		{Mode: "lenient", Measurements: []meta.MeasurementSchema{{Name: "cpu"}}},
+		{Mode: meta.SchemaModeStrict},
+		{Mode: meta.SchemaModeStrict, Measurements: []meta.MeasurementSchema{{Name: "cpu"}, {Name: "cpu"}}},
+		{Mode: meta.SchemaModeStrict, Measurements: []meta.MeasurementSchema{{Name: "cpu", RequiredTags: []string{"host"}, AllowedTags: []string{"host"}}}},
+		{Mode: meta.SchemaModeStrict, Measurements: []meta.MeasurementSchema{{Name: "cpu", Fields: []meta.FieldSchema{{Name: "value"}}}}},
+	} {
+		if err := data.SetDatabaseSchema("db0", s); err == nil {
+			t.Fatalf("expected error for schema %+v", s)
+		}
+	}
+
+	schema := &meta.DatabaseSchema{
+		Mode: meta.SchemaModeStrict,
+		Measurements: []meta.MeasurementSchema{
+			{
+				Name:         "cpu",
+				RequiredTags: []string{"host"},
+				AllowedTags:  []string{"region"},
+				Fields:       []meta.FieldSchema{{Name: "value", Type: influxql.Float}},
+			},
+			{Name: "mem"},
+		},
+	}
+	if err := data.SetDatabaseSchema("db0", schema); err != nil {
+		t.Fatal(err)
+	}
+
+	// The schema survives a round trip through the protobuf representation.
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.Data
+	if err := other.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := other.Database("db0").Schema; !reflect.DeepEqual(got, schema) {
+		t.Fatalf("unexpected schema: %+v", got)
+	}
+
+	// Removing the schema of a clone does not change the original.
+	clone := other.Clone()
+	if err := clone.SetDatabaseSchema("db0", nil); err != nil {
+		t.Fatal(err)
+	} else if clone.Database("db0").Schema != nil {
+		t.Fatal("expected schema to be removed")
+	} else if other.Database("db0").Schema == nil {
+		t.Fatal("expected schema of the original to be unchanged")
+	}
+}
+
+func TestData_SetDatabaseWriteQuota(t *testing.T) {
+	data := meta.Data{}
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := data.SetDatabaseWriteQuota("db1", nil), influxdb.ErrDatabaseNotFound("db1"); got == nil || got.Error() != exp.Error() {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+	for _, q := range []*meta.WriteQuota{
+		{MaxSeries: -1},
+		{MaxPointsPerSecond: -1},
+		{PointsBurst: 100},
+	} {
+		if err := data.SetDatabaseWriteQuota("db0", q); err == nil {
+			t.Fatalf("expected error for quota %+v", q)
+		}
+	}
+
+	quota := &meta.WriteQuota{MaxSeries: 1000, MaxDiskBytes: 1 << 30, MaxPointsPerSecond: 500}
+	if err := data.SetDatabaseWriteQuota("db0", quota); err != nil {
+		t.Fatal(err)
+	} else if got := data.Database("db0").WriteQuota.Burst(); got != 500 {
+		t.Fatalf("unexpected burst: %d", got)
+	}
+
+	// The quota survives a round trip through the protobuf representation.
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.Data
+	if err := other.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := other.Database("db0").WriteQuota; !reflect.DeepEqual(got, quota) {
+		t.Fatalf("unexpected quota: %+v", got)
+	}
+
+	// An empty quota removes the quota.
+	if err := other.SetDatabaseWriteQuota("db0", &meta.WriteQuota{}); err != nil {
+		t.Fatal(err)
+	} else if other.Database("db0").WriteQuota != nil {
+		t.Fatal("expected quota to be removed")
+	}
+}
+
+func TestDatabaseSchema_ValidatePoint(t *testing.T) {
+	schema := &meta.DatabaseSchema{
+		Mode: meta.SchemaModeStrict,
+		Measurements: []meta.MeasurementSchema{
+			{
+				Name:         "cpu",
+				RequiredTags: []string{"host"},
+				AllowedTags:  []string{"region"},
+				Fields:       []meta.FieldSchema{{Name: "value", Type: influxql.Float}, {Name: "n", Type: influxql.Integer}},
+			},
+			{Name: "mem"},
+		},
+	}
+
+	for _, tt := range []struct {
+		line   string
+		reason string
+	}{
+		{line: `cpu,host=a value=1`},
+		{line: `cpu,host=a,region=us value=1,n=2i`},
+		{line: `mem,any=tag any="field"`},
+		{line: `cpuu,host=a value=1`, reason: `measurement "cpuu" is not allowed`},
+		{line: `cpu,region=us value=1`, reason: `missing required tag "host" on measurement "cpu"`},
+		{line: `cpu,host=a,zone=b value=1`, reason: `tag "zone" is not allowed on measurement "cpu"`},
+		{line: `cpu,host=a valu=1`, reason: `field "valu" is not allowed on measurement "cpu"`},
+		{line: `cpu,host=a value=1i`, reason: `field "value" on measurement "cpu" is type integer, declared as type float`},
+	} {
+		pt, err := models.ParsePointsString(tt.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := schema.ValidatePoint(pt[0]); got != tt.reason {
+			t.Errorf("%s: got reason %q, expected %q", tt.line, got, tt.reason)
+		}
+	}
+}
+
 func TestData_TruncateShardGroups(t *testing.T) {
 	data := &meta.Data{}
 
@@ -392,3 +649,175 @@ func randString(n int) string {
 	}
 	return string(b)
 }
+
+func TestData_SetUserReadGrants(t *testing.T) {
+	data := meta.Data{}
+	for _, db := range []string{"db0", "db1"} {
+		if err := data.CreateDatabase(db); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := data.CreateUser("susy", "", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := data.SetUserReadGrants("bob", "db0", nil), meta.ErrUserNotFound; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+	if got, exp := data.SetUserReadGrants("susy", "db2", nil), influxdb.ErrDatabaseNotFound("db2"); got == nil || got.Error() != exp.Error() {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+	for _, cond := range []string{"team =", "value > 1", "team = 'a' AND time > now()"} {
+		if err := data.SetUserReadGrants("susy", "db0", []meta.ReadGrant{{Condition: cond}}); err == nil {
+			t.Fatalf("expected error for condition %q", cond)
+		}
+	}
+
+	grants := []meta.ReadGrant{
+		{Measurement: "cpu", Condition: "team = 'a'"},
+		{Measurement: "mem", Condition: "team =~ /^(a|b)$/ OR host = 'shared'"},
+	}
+	if err := data.SetUserReadGrants("susy", "db0", grants); err != nil {
+		t.Fatal(err)
+	}
+
+	// The grants survive a round trip through the protobuf representation.
+	buf, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.Data
+	if err := other.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ui := range []*meta.UserInfo{data.User("susy").(*meta.UserInfo), other.User("susy").(*meta.UserInfo)} {
+		if ui.IsOpen() {
+			t.Fatal("expected user with read grants not to be open")
+		}
+		for _, tt := range []struct {
+			db, series string
+			exp        bool
+		}{
+			{db: "db0", series: "cpu,team=a", exp: true},
+			{db: "db0", series: "cpu,team=b", exp: false},
+			{db: "db0", series: "cpu", exp: false},
+			{db: "db0", series: "mem,team=b", exp: true},
+			{db: "db0", series: "mem,host=shared", exp: true},
+			{db: "db0", series: "mem,team=c", exp: false},
+			{db: "db0", series: "disk,team=a", exp: false},
+			{db: "db1", series: "disk,team=c", exp: true},
+		} {
+			name, tags := models.ParseKeyBytes([]byte(tt.series))
+			if got := ui.AuthorizeSeriesRead(tt.db, name, tags); got != tt.exp {
+				t.Errorf("AuthorizeSeriesRead(%s, %s) = %v, expected %v", tt.db, tt.series, got, tt.exp)
+			}
+		}
+	}
+
+	// Dropping the database removes its grants.
+	if err := data.DropDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+	if ui := data.User("susy").(*meta.UserInfo); len(ui.ReadGrants) != 0 || !ui.IsOpen() {
+		t.Fatalf("unexpected read grants: %+v", ui.ReadGrants)
+	}
+}
+
+func TestData_RenameDatabase(t *testing.T) {
+	data := meta.Data{}
+	for _, name := range []string{"db0", "db1"} {
+		if err := data.CreateDatabase(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := data.CreateRetentionPolicy("db0", &meta.RetentionPolicyInfo{Name: "rp0", ReplicaN: 1}, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, cq := range []struct{ db, name, query string }{
+		{"db0", "cq0", `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM cpu GROUP BY time(1h) END`},
+		{"db1", "cq1", `CREATE CONTINUOUS QUERY cq1 ON db1 BEGIN SELECT mean(value) INTO cpu_1h FROM db0.rp0.cpu GROUP BY time(1h) END`},
+		{"db1", "cq2", `CREATE CONTINUOUS QUERY cq2 ON db1 BEGIN SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(1h) END`},
+	} {
+		if err := data.CreateContinuousQuery(cq.db, cq.name, cq.query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := data.CreateUser("susy", "ABC123", false); err != nil {
+		t.Fatal(err)
+	} else if err := data.SetPrivilege("susy", "db0", influxql.ReadPrivilege); err != nil {
+		t.Fatal(err)
+	} else if err := data.SetUserReadGrants("susy", "db0", []meta.ReadGrant{{Database: "db0", Measurement: "cpu"}}); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateToken(meta.TokenInfo{ID: "t0", User: "susy", Hash: "h", Scopes: []string{"read:db0", "write:db1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := data.RenameDatabase("db2", "db3"), influxdb.ErrDatabaseNotFound("db2"); got == nil || got.Error() != exp.Error() {
+		t.Fatalf("got %v, expected %v", got, exp)
+	} else if got, exp := data.RenameDatabase("db0", "db1"), meta.ErrDatabaseExists; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+
+	if err := data.RenameDatabase("db0", "db2"); err != nil {
+		t.Fatal(err)
+	}
+	if data.Database("db0") != nil {
+		t.Fatal("expected old database to be removed")
+	} else if di := data.Database("db2"); di == nil || di.DefaultRetentionPolicy != "rp0" {
+		t.Fatalf("unexpected database: %+v", di)
+	}
+
+	if got, exp := data.Database("db2").ContinuousQueries[0].Query, `CREATE CONTINUOUS QUERY cq0 ON db2 BEGIN SELECT mean(value) INTO db2.rp0.cpu_1h FROM cpu GROUP BY time(1h) END`; got != exp {
+		t.Fatalf("unexpected query:\ngot=%s\nexp=%s", got, exp)
+	}
+	if got, exp := data.Database("db1").ContinuousQueries[0].Query, `CREATE CONTINUOUS QUERY cq1 ON db1 BEGIN SELECT mean(value) INTO cpu_1h FROM db2.rp0.cpu GROUP BY time(1h) END`; got != exp {
+		t.Fatalf("unexpected query:\ngot=%s\nexp=%s", got, exp)
+	}
+	// Queries not referencing the database are left as written.
+	if got, exp := data.Database("db1").ContinuousQueries[1].Query, `CREATE CONTINUOUS QUERY cq2 ON db1 BEGIN SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(1h) END`; got != exp {
+		t.Fatalf("unexpected query:\ngot=%s\nexp=%s", got, exp)
+	}
+
+	ui := data.User("susy").(*meta.UserInfo)
+	if got, exp := ui.Privileges, map[string]influxql.Privilege{"db2": influxql.ReadPrivilege}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected privileges: %v", got)
+	} else if got, exp := ui.ReadGrants, []meta.ReadGrant{{Database: "db2", Measurement: "cpu"}}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected read grants: %+v", got)
+	} else if got, exp := data.Token("t0").Scopes, []string{"read:db2", "write:db1"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected token scopes: %v", got)
+	}
+}
+
+func TestData_RenameRetentionPolicy(t *testing.T) {
+	data := meta.Data{}
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"rp0", "rp1"} {
+		if err := data.CreateRetentionPolicy("db0", &meta.RetentionPolicyInfo{Name: name, ReplicaN: 1}, name == "rp0"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := data.CreateContinuousQuery("db0", "cq0", `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO rp1.cpu_1h FROM rp0.cpu GROUP BY time(1h) END`); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := data.RenameRetentionPolicy("db0", "rp2", "rp3"), influxdb.ErrRetentionPolicyNotFound("rp2"); got == nil || got.Error() != exp.Error() {
+		t.Fatalf("got %v, expected %v", got, exp)
+	} else if got, exp := data.RenameRetentionPolicy("db0", "rp0", "rp1"), meta.ErrRetentionPolicyNameExists; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+
+	if err := data.RenameRetentionPolicy("db0", "rp0", "rp2"); err != nil {
+		t.Fatal(err)
+	}
+	di := data.Database("db0")
+	if di.RetentionPolicy("rp0") != nil || di.RetentionPolicy("rp2") == nil {
+		t.Fatalf("unexpected retention policies: %+v", di.RetentionPolicies)
+	} else if di.DefaultRetentionPolicy != "rp2" {
+		t.Fatalf("unexpected default retention policy: %s", di.DefaultRetentionPolicy)
+	} else if got, exp := di.ContinuousQueries[0].Query, `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO rp1.cpu_1h FROM rp2.cpu GROUP BY time(1h) END`; got != exp {
+		t.Fatalf("unexpected query:\ngot=%s\nexp=%s", got, exp)
+	}
+}
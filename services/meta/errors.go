@@ -99,6 +99,20 @@ var (
 	ErrSubscriptionNotFound = errors.New("subscription not found")
 )
 
+var (
+	// ErrVersionNotFound is returned when rolling back to a version of the
+	// meta data that is not in the history.
+	ErrVersionNotFound = errors.New("meta data version not found")
+
+	// ErrVersionCurrent is returned when rolling back to the current version
+	// of the meta data.
+	ErrVersionCurrent = errors.New("meta data version is the current version")
+
+	// ErrRollbackRename is returned when rolling back the meta data past the
+	// rename of a database or retention policy.
+	ErrRollbackRename = errors.New("cannot roll back past a rename of a database or retention policy")
+)
+
 // ErrInvalidSubscriptionURL is returned when the subscription's destination URL is invalid.
 func ErrInvalidSubscriptionURL(url string) error {
 	return fmt.Errorf("invalid subscription URL: %s", url)
@@ -116,4 +130,12 @@ var (
 
 	// ErrAuthenticate is returned when authentication fails.
 	ErrAuthenticate = errors.New("authentication failed")
+
+	// ErrUserLocked is returned when authenticating a user locked out after
+	// too many failed logins.
+	ErrUserLocked = errors.New("user locked")
+
+	// ErrPasswordExpired is returned when authenticating with an expired
+	// password.
+	ErrPasswordExpired = errors.New("password expired")
 )
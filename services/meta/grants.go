@@ -0,0 +1,111 @@
+package meta
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/influxdata/influxdb/models"
+	internal "github.com/influxdata/influxdb/services/meta/internal"
+	"github.com/influxdata/influxql"
+)
+
+// ReadGrant allows a user to read the series of a measurement whose tags
+// match a condition. Once a user has a read grant on a database, the user may
+// only read the series of the database matching one of its grants.
+type ReadGrant struct {
+	Database string
+
+	// Measurement is the name of the measurement, or empty for all
+	// measurements.
+	Measurement string
+
+	// Condition compares tags to strings and regular expressions, such as
+	// "team = 'a'". An empty condition matches all series.
+	Condition string
+
+	// cond is the parsed condition, set by compile.
+	cond influxql.Expr
+}
+
+// Validate returns an error if the grant is invalid.
+func (g *ReadGrant) Validate() error {
+	if g.Database == "" {
+		return errors.New("database is required")
+	}
+	if g.Condition == "" {
+		return nil
+	}
+	expr, err := influxql.ParseExpr(g.Condition)
+	if err != nil {
+		return fmt.Errorf("invalid condition: %s", err)
+	}
+	if err := validateTagCondition(expr); err != nil {
+		return fmt.Errorf("invalid condition: %s", err)
+	}
+	return nil
+}
+
+// compile parses the condition of the grant. An invalid condition matches no
+// series.
+func (g *ReadGrant) compile() {
+	g.cond = nil
+	if g.Condition == "" {
+		return
+	}
+	expr, err := influxql.ParseExpr(g.Condition)
+	if err != nil || validateTagCondition(expr) != nil {
+		expr = &influxql.BooleanLiteral{Val: false}
+	}
+	g.cond = expr
+}
+
+// Match returns true if the grant allows reading a series.
+func (g *ReadGrant) Match(measurement []byte, tags models.Tags) bool {
+	if g.Measurement != "" && g.Measurement != string(measurement) {
+		return false
+	}
+	if g.Condition == "" {
+		return true
+	}
+
+	cond := g.cond
+	if cond == nil {
+		other := *g
+		other.compile()
+		cond = other.cond
+	}
+	v := influxql.ValuerEval{Valuer: tagsValuer(tags)}
+	return v.EvalBool(cond)
+}
+
+// tagsValuer returns the values of tags when evaluating a condition. Missing
+// tags have an empty value.
+type tagsValuer models.Tags
+
+func (v tagsValuer) Value(key string) (interface{}, bool) {
+	return string(models.Tags(v).Get([]byte(key))), true
+}
+
+// marshalReadGrant serializes a read grant to a protobuf representation.
+func marshalReadGrant(g ReadGrant) *internal.ReadGrant {
+	pb := &internal.ReadGrant{Database: proto.String(g.Database)}
+	if g.Measurement != "" {
+		pb.Measurement = proto.String(g.Measurement)
+	}
+	if g.Condition != "" {
+		pb.Condition = proto.String(g.Condition)
+	}
+	return pb
+}
+
+// unmarshalReadGrant deserializes a read grant from a protobuf representation.
+func unmarshalReadGrant(pb *internal.ReadGrant) ReadGrant {
+	g := ReadGrant{
+		Database:    pb.GetDatabase(),
+		Measurement: pb.GetMeasurement(),
+		Condition:   pb.GetCondition(),
+	}
+	g.compile()
+	return g
+}
@@ -0,0 +1,378 @@
+package meta
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/file"
+	"go.uber.org/zap"
+)
+
+const (
+	// historyDir is the directory of the meta directory holding the versions
+	// of the meta data.
+	historyDir = "history"
+
+	// historyFile is the file of the history directory listing the versions.
+	historyFile = "history.json"
+)
+
+// HistoryEntry describes a version of the meta data kept in the history.
+type HistoryEntry struct {
+	// Index is the index of the meta data of the version.
+	Index uint64 `json:"index"`
+
+	// Time is the time the version was committed.
+	Time time.Time `json:"time"`
+
+	// User is the user who committed the version, if known.
+	User string `json:"user,omitempty"`
+
+	// System is true if the version was committed by the server itself, such
+	// as when creating shard groups or enforcing retention policies. Runs of
+	// such versions are coalesced into the last of them.
+	System bool `json:"system,omitempty"`
+
+	// Rename describes the database or retention policy renamed by the
+	// version, such as "database:db0 to database:db1", if any. Renames move
+	// the data of the shards, so the meta data cannot be rolled back past
+	// them.
+	Rename string `json:"rename,omitempty"`
+}
+
+// RollbackResult describes a rollback of the meta data.
+type RollbackResult struct {
+	// Index is the index of the meta data after the rollback.
+	Index uint64 `json:"index"`
+
+	// Unrecoverable are the shards of the restored version whose data has
+	// been deleted since, which are not restored, and the shards of the
+	// databases and retention policies created since, whose data is left on
+	// disk without being referenced.
+	Unrecoverable []RollbackShard `json:"unrecoverable"`
+}
+
+// RollbackShard identifies a shard of a version of the meta data.
+type RollbackShard struct {
+	Database        string    `json:"database"`
+	RetentionPolicy string    `json:"retention_policy"`
+	ShardGroupID    uint64    `json:"shard_group_id"`
+	ShardID         uint64    `json:"shard_id"`
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+}
+
+// History returns the versions of the meta data kept in the history, from the
+// oldest to the current version.
+func (c *Client) History() []HistoryEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]HistoryEntry(nil), c.history...)
+}
+
+// WithClient returns a client attributing the versions of the meta data it
+// commits to user, and the changes it audits to user and the client address
+// addr. The client shares the meta data and the state of c.
+func (c *Client) WithClient(user, addr string) *Client {
+	return &Client{clientState: c.clientState, user: user, addr: addr, client: true}
+}
+
+// Rollback commits the version of the meta data with the given index as a
+// new version. Databases, retention policies and continuous queries created
+// since are dropped, and those dropped since are restored. The users, their
+// passwords, privileges and read grants, and the tokens are not rolled back.
+// ErrRollbackRename is returned if a database or retention policy has been
+// renamed since the version.
+//
+// The shard groups of the retention policies are kept as they are, except
+// that shard groups dropped since the version are restored if exists reports
+// that their shards still exist. Shards deleted since cannot be restored and
+// are reported as unrecoverable, unless they expired anyway.
+func (c *Client) Rollback(index uint64, exists func(id uint64) bool) (res *RollbackResult, err error) {
+	defer func() { c.audit("rollback", err, "meta:"+strconv.FormatUint(index, 10)) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if index == c.cacheData.Index {
+		return nil, ErrVersionCurrent
+	}
+
+	var found bool
+	for _, e := range c.history {
+		if found && e.Rename != "" {
+			return nil, ErrRollbackRename
+		}
+		found = found || e.Index == index
+	}
+	if !found {
+		return nil, ErrVersionNotFound
+	}
+
+	buf, err := ioutil.ReadFile(c.historyPath(index))
+	if os.IsNotExist(err) {
+		return nil, ErrVersionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	var old Data
+	if err := old.UnmarshalBinary(buf); err != nil {
+		return nil, err
+	}
+
+	data, lost := rollbackData(c.cacheData, &old, exists, time.Now().UTC())
+	if err := c.commit(data); err != nil {
+		return nil, err
+	}
+	return &RollbackResult{Index: data.Index, Unrecoverable: lost}, nil
+}
+
+// rollbackData returns the version old of the meta data to commit over the
+// current version cur, and the shards of old which cannot be restored.
+func rollbackData(cur, old *Data, exists func(id uint64) bool, now time.Time) (*Data, []RollbackShard) {
+	data := old.Clone()
+	data.Term, data.Index, data.ClusterID = cur.Term, cur.Index, cur.ClusterID
+
+	// IDs are never reused.
+	if cur.MaxShardGroupID > data.MaxShardGroupID {
+		data.MaxShardGroupID = cur.MaxShardGroupID
+	}
+	if cur.MaxShardID > data.MaxShardID {
+		data.MaxShardID = cur.MaxShardID
+	}
+
+	// Credentials are kept as they are, but the privileges and read grants
+	// on the databases dropped by the rollback are removed.
+	other := cur.Clone()
+	data.Users, data.Tokens, data.adminUserExists = other.Users, other.Tokens, other.adminUserExists
+	for _, cdi := range cur.Databases {
+		if data.Database(cdi.Name) == nil {
+			for i := range data.Users {
+				delete(data.Users[i].Privileges, cdi.Name)
+				data.Users[i].removeReadGrants(cdi.Name)
+			}
+		}
+	}
+
+	var lost []RollbackShard
+	for i := range data.Databases {
+		di := &data.Databases[i]
+		for j := range di.RetentionPolicies {
+			rpi := &di.RetentionPolicies[j]
+
+			var groups []ShardGroupInfo
+			if cdi := cur.Database(di.Name); cdi != nil {
+				if crpi := cdi.RetentionPolicy(rpi.Name); crpi != nil {
+					groups = crpi.clone().ShardGroups
+				}
+			}
+			live := make(map[uint64]bool, len(groups))
+			for _, sg := range groups {
+				live[sg.ID] = !sg.Deleted()
+			}
+
+			for _, sg := range rpi.ShardGroups {
+				if sg.Deleted() || live[sg.ID] {
+					continue
+				} else if rpi.Duration != 0 && sg.EndTime.Before(now.Add(-rpi.Duration)) {
+					// The retention policy has expired the shard group.
+					continue
+				}
+
+				var shards []ShardInfo
+				for _, sh := range sg.Shards {
+					if exists(sh.ID) {
+						shards = append(shards, sh)
+						continue
+					}
+					lost = append(lost, RollbackShard{
+						Database:        di.Name,
+						RetentionPolicy: rpi.Name,
+						ShardGroupID:    sg.ID,
+						ShardID:         sh.ID,
+						StartTime:       sg.StartTime,
+						EndTime:         sg.EndTime,
+					})
+				}
+				if len(shards) == 0 {
+					continue
+				}
+
+				sg.Shards = shards
+				for k := range groups {
+					if groups[k].ID == sg.ID {
+						groups = append(groups[:k], groups[k+1:]...)
+						break
+					}
+				}
+				groups = append(groups, sg)
+			}
+
+			sort.Sort(ShardGroupInfos(groups))
+			rpi.ShardGroups = groups
+		}
+	}
+
+	// The shards of the retention policies dropped by the rollback are left
+	// on disk.
+	for _, cdi := range cur.Databases {
+		di := data.Database(cdi.Name)
+		for _, crpi := range cdi.RetentionPolicies {
+			if di != nil && di.RetentionPolicy(crpi.Name) != nil {
+				continue
+			}
+			for _, sg := range crpi.ShardGroups {
+				if sg.Deleted() {
+					continue
+				}
+				for _, sh := range sg.Shards {
+					if !exists(sh.ID) {
+						continue
+					}
+					lost = append(lost, RollbackShard{
+						Database:        cdi.Name,
+						RetentionPolicy: crpi.Name,
+						ShardGroupID:    sg.ID,
+						ShardID:         sh.ID,
+						StartTime:       sg.StartTime,
+						EndTime:         sg.EndTime,
+					})
+				}
+			}
+		}
+	}
+	return data, lost
+}
+
+// historyPath returns the path of the version of the meta data with the
+// given index.
+func (c *Client) historyPath(index uint64) string {
+	return filepath.Join(c.path, historyDir, strconv.FormatUint(index, 10)+".db")
+}
+
+// loadHistory loads the list of versions of the meta data. Versions whose
+// files are missing are ignored.
+func (c *Client) loadHistory() error {
+	buf, err := ioutil.ReadFile(filepath.Join(c.path, historyDir, historyFile))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var entries []HistoryEntry
+	if err := json.Unmarshal(buf, &entries); err != nil {
+		return err
+	}
+	c.history = c.history[:0]
+	for _, e := range entries {
+		if _, err := os.Stat(c.historyPath(e.Index)); err == nil {
+			c.history = append(c.history, e)
+		}
+	}
+
+	// Versions written before the credentials were left out of the history
+	// are rewritten without them.
+	for _, e := range c.history {
+		buf, err := ioutil.ReadFile(c.historyPath(e.Index))
+		if err != nil {
+			return err
+		}
+		var data Data
+		if err := data.UnmarshalBinary(buf); err != nil {
+			return err
+		} else if len(data.Users) == 0 && len(data.Tokens) == 0 {
+			continue
+		}
+		if err := writeHistoryVersion(c.historyPath(e.Index), &data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeHistoryVersion writes a version of the meta data to the history. The
+// users and tokens are left out, since rollbacks keep the current ones.
+func writeHistoryVersion(filename string, data *Data) error {
+	other := *data
+	other.Users, other.Tokens = nil, nil
+	buf, err := other.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return writeFileSync(filename, buf)
+}
+
+// recordHistory adds a committed version of the meta data to the history and
+// removes the versions beyond the size of the history. A version committed by
+// the server itself replaces the previous version if it was too, so that
+// they don't push the versions committed by clients out of the history. It
+// assumes c's mutex is already locked.
+func (c *Client) recordHistory(data *Data, rename string) error {
+	if c.historySize == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Join(c.path, historyDir), 0777); err != nil {
+		return err
+	} else if err := writeHistoryVersion(c.historyPath(data.Index), data); err != nil {
+		return err
+	}
+
+	history := c.history
+	var expired []HistoryEntry
+	if n := len(history); n > 0 && !c.client && history[n-1].System {
+		expired, history = history[n-1:n:n], history[:n-1:n-1]
+	}
+	history = append(history, HistoryEntry{
+		Index:  data.Index,
+		Time:   time.Now().UTC(),
+		User:   c.user,
+		System: !c.client,
+		Rename: rename,
+	})
+	if n := len(history) - c.historySize; n > 0 {
+		expired, history = append(expired, history[:n]...), history[n:]
+	}
+
+	buf, err := json.Marshal(history)
+	if err != nil {
+		return err
+	} else if err := writeFileSync(filepath.Join(c.path, historyDir, historyFile), buf); err != nil {
+		return err
+	}
+	c.history = history
+
+	for _, e := range expired {
+		if err := os.Remove(c.historyPath(e.Index)); err != nil && !os.IsNotExist(err) {
+			c.logger.Warn("Failed to remove meta data version", zap.Uint64("index", e.Index), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// writeFileSync writes a file through a temporary file, so that it is either
+// fully written or not changed.
+func writeFileSync(filename string, buf []byte) error {
+	tmpFile := filename + "tmp"
+
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(buf); err != nil {
+		return err
+	} else if err := f.Sync(); err != nil {
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+	return file.RenameFile(tmpFile, filename)
+}
@@ -138,7 +138,7 @@ func (x *Command_Type) UnmarshalJSON(data []byte) error {
 }
 
 func (Command_Type) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{12, 0}
+	return fileDescriptor_59b0956366e72083, []int{20, 0}
 }
 
 type Data struct {
@@ -152,11 +152,12 @@ type Data struct {
 	MaxShardGroupID *uint64         `protobuf:"varint,8,req,name=MaxShardGroupID" json:"MaxShardGroupID,omitempty"`
 	MaxShardID      *uint64         `protobuf:"varint,9,req,name=MaxShardID" json:"MaxShardID,omitempty"`
 	// added for 0.10.0
-	DataNodes            []*NodeInfo `protobuf:"bytes,10,rep,name=DataNodes" json:"DataNodes,omitempty"`
-	MetaNodes            []*NodeInfo `protobuf:"bytes,11,rep,name=MetaNodes" json:"MetaNodes,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
-	XXX_unrecognized     []byte      `json:"-"`
-	XXX_sizecache        int32       `json:"-"`
+	DataNodes            []*NodeInfo  `protobuf:"bytes,10,rep,name=DataNodes" json:"DataNodes,omitempty"`
+	MetaNodes            []*NodeInfo  `protobuf:"bytes,11,rep,name=MetaNodes" json:"MetaNodes,omitempty"`
+	Tokens               []*TokenInfo `protobuf:"bytes,12,rep,name=Tokens" json:"Tokens,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
 }
 
 func (m *Data) Reset()         { *m = Data{} }
@@ -260,6 +261,13 @@ func (m *Data) GetMetaNodes() []*NodeInfo {
 	return nil
 }
 
+func (m *Data) GetTokens() []*TokenInfo {
+	if m != nil {
+		return m.Tokens
+	}
+	return nil
+}
+
 type NodeInfo struct {
 	ID                   *uint64  `protobuf:"varint,1,req,name=ID" json:"ID,omitempty"`
 	Host                 *string  `protobuf:"bytes,2,req,name=Host" json:"Host,omitempty"`
@@ -319,6 +327,9 @@ type DatabaseInfo struct {
 	DefaultRetentionPolicy *string                `protobuf:"bytes,2,req,name=DefaultRetentionPolicy" json:"DefaultRetentionPolicy,omitempty"`
 	RetentionPolicies      []*RetentionPolicyInfo `protobuf:"bytes,3,rep,name=RetentionPolicies" json:"RetentionPolicies,omitempty"`
 	ContinuousQueries      []*ContinuousQueryInfo `protobuf:"bytes,4,rep,name=ContinuousQueries" json:"ContinuousQueries,omitempty"`
+	QueryQuota             *QueryQuota            `protobuf:"bytes,5,opt,name=QueryQuota" json:"QueryQuota,omitempty"`
+	Schema                 *DatabaseSchema        `protobuf:"bytes,6,opt,name=Schema" json:"Schema,omitempty"`
+	WriteQuota             *WriteQuota            `protobuf:"bytes,7,opt,name=WriteQuota" json:"WriteQuota,omitempty"`
 	XXX_NoUnkeyedLiteral   struct{}               `json:"-"`
 	XXX_unrecognized       []byte                 `json:"-"`
 	XXX_sizecache          int32                  `json:"-"`
@@ -376,6 +387,27 @@ func (m *DatabaseInfo) GetContinuousQueries() []*ContinuousQueryInfo {
 	return nil
 }
 
+func (m *DatabaseInfo) GetQueryQuota() *QueryQuota {
+	if m != nil {
+		return m.QueryQuota
+	}
+	return nil
+}
+
+func (m *DatabaseInfo) GetSchema() *DatabaseSchema {
+	if m != nil {
+		return m.Schema
+	}
+	return nil
+}
+
+func (m *DatabaseInfo) GetWriteQuota() *WriteQuota {
+	if m != nil {
+		return m.WriteQuota
+	}
+	return nil
+}
+
 type RetentionPolicySpec struct {
 	Name                 *string  `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
 	Duration             *int64   `protobuf:"varint,2,opt,name=Duration" json:"Duration,omitempty"`
@@ -650,12 +682,13 @@ func (m *ShardInfo) GetOwners() []*ShardOwner {
 }
 
 type SubscriptionInfo struct {
-	Name                 *string  `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
-	Mode                 *string  `protobuf:"bytes,2,req,name=Mode" json:"Mode,omitempty"`
-	Destinations         []string `protobuf:"bytes,3,rep,name=Destinations" json:"Destinations,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Name                 *string             `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
+	Mode                 *string             `protobuf:"bytes,2,req,name=Mode" json:"Mode,omitempty"`
+	Destinations         []string            `protobuf:"bytes,3,rep,name=Destinations" json:"Destinations,omitempty"`
+	Filter               *SubscriptionFilter `protobuf:"bytes,4,opt,name=Filter" json:"Filter,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
 }
 
 func (m *SubscriptionInfo) Reset()         { *m = SubscriptionInfo{} }
@@ -703,6 +736,67 @@ func (m *SubscriptionInfo) GetDestinations() []string {
 	return nil
 }
 
+func (m *SubscriptionInfo) GetFilter() *SubscriptionFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+type SubscriptionFilter struct {
+	Measurements         []string `protobuf:"bytes,1,rep,name=Measurements" json:"Measurements,omitempty"`
+	MeasurementRegex     *string  `protobuf:"bytes,2,opt,name=MeasurementRegex" json:"MeasurementRegex,omitempty"`
+	Condition            *string  `protobuf:"bytes,3,opt,name=Condition" json:"Condition,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubscriptionFilter) Reset()         { *m = SubscriptionFilter{} }
+func (m *SubscriptionFilter) String() string { return proto.CompactTextString(m) }
+func (*SubscriptionFilter) ProtoMessage()    {}
+func (*SubscriptionFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_59b0956366e72083, []int{8}
+}
+func (m *SubscriptionFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubscriptionFilter.Unmarshal(m, b)
+}
+func (m *SubscriptionFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubscriptionFilter.Marshal(b, m, deterministic)
+}
+func (m *SubscriptionFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscriptionFilter.Merge(m, src)
+}
+func (m *SubscriptionFilter) XXX_Size() int {
+	return xxx_messageInfo_SubscriptionFilter.Size(m)
+}
+func (m *SubscriptionFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscriptionFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscriptionFilter proto.InternalMessageInfo
+
+func (m *SubscriptionFilter) GetMeasurements() []string {
+	if m != nil {
+		return m.Measurements
+	}
+	return nil
+}
+
+func (m *SubscriptionFilter) GetMeasurementRegex() string {
+	if m != nil && m.MeasurementRegex != nil {
+		return *m.MeasurementRegex
+	}
+	return ""
+}
+
+func (m *SubscriptionFilter) GetCondition() string {
+	if m != nil && m.Condition != nil {
+		return *m.Condition
+	}
+	return ""
+}
+
 type ShardOwner struct {
 	NodeID               *uint64  `protobuf:"varint,1,req,name=NodeID" json:"NodeID,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -714,7 +808,7 @@ func (m *ShardOwner) Reset()         { *m = ShardOwner{} }
 func (m *ShardOwner) String() string { return proto.CompactTextString(m) }
 func (*ShardOwner) ProtoMessage()    {}
 func (*ShardOwner) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{8}
+	return fileDescriptor_59b0956366e72083, []int{9}
 }
 func (m *ShardOwner) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ShardOwner.Unmarshal(m, b)
@@ -753,7 +847,7 @@ func (m *ContinuousQueryInfo) Reset()         { *m = ContinuousQueryInfo{} }
 func (m *ContinuousQueryInfo) String() string { return proto.CompactTextString(m) }
 func (*ContinuousQueryInfo) ProtoMessage()    {}
 func (*ContinuousQueryInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{9}
+	return fileDescriptor_59b0956366e72083, []int{10}
 }
 func (m *ContinuousQueryInfo) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ContinuousQueryInfo.Unmarshal(m, b)
@@ -792,6 +886,9 @@ type UserInfo struct {
 	Hash                 *string          `protobuf:"bytes,2,req,name=Hash" json:"Hash,omitempty"`
 	Admin                *bool            `protobuf:"varint,3,req,name=Admin" json:"Admin,omitempty"`
 	Privileges           []*UserPrivilege `protobuf:"bytes,4,rep,name=Privileges" json:"Privileges,omitempty"`
+	QueryQuota           *QueryQuota      `protobuf:"bytes,5,opt,name=QueryQuota" json:"QueryQuota,omitempty"`
+	ReadGrants           []*ReadGrant     `protobuf:"bytes,6,rep,name=ReadGrants" json:"ReadGrants,omitempty"`
+	PasswordChanged      *int64           `protobuf:"varint,7,opt,name=PasswordChanged" json:"PasswordChanged,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
 	XXX_unrecognized     []byte           `json:"-"`
 	XXX_sizecache        int32            `json:"-"`
@@ -801,7 +898,7 @@ func (m *UserInfo) Reset()         { *m = UserInfo{} }
 func (m *UserInfo) String() string { return proto.CompactTextString(m) }
 func (*UserInfo) ProtoMessage()    {}
 func (*UserInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{10}
+	return fileDescriptor_59b0956366e72083, []int{11}
 }
 func (m *UserInfo) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UserInfo.Unmarshal(m, b)
@@ -849,6 +946,27 @@ func (m *UserInfo) GetPrivileges() []*UserPrivilege {
 	return nil
 }
 
+func (m *UserInfo) GetQueryQuota() *QueryQuota {
+	if m != nil {
+		return m.QueryQuota
+	}
+	return nil
+}
+
+func (m *UserInfo) GetReadGrants() []*ReadGrant {
+	if m != nil {
+		return m.ReadGrants
+	}
+	return nil
+}
+
+func (m *UserInfo) GetPasswordChanged() int64 {
+	if m != nil && m.PasswordChanged != nil {
+		return *m.PasswordChanged
+	}
+	return 0
+}
+
 type UserPrivilege struct {
 	Database             *string  `protobuf:"bytes,1,req,name=Database" json:"Database,omitempty"`
 	Privilege            *int32   `protobuf:"varint,2,req,name=Privilege" json:"Privilege,omitempty"`
@@ -861,7 +979,7 @@ func (m *UserPrivilege) Reset()         { *m = UserPrivilege{} }
 func (m *UserPrivilege) String() string { return proto.CompactTextString(m) }
 func (*UserPrivilege) ProtoMessage()    {}
 func (*UserPrivilege) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{11}
+	return fileDescriptor_59b0956366e72083, []int{12}
 }
 func (m *UserPrivilege) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UserPrivilege.Unmarshal(m, b)
@@ -895,6 +1013,432 @@ func (m *UserPrivilege) GetPrivilege() int32 {
 	return 0
 }
 
+type QueryQuota struct {
+	MaxConcurrentQueries *int64   `protobuf:"varint,1,opt,name=MaxConcurrentQueries" json:"MaxConcurrentQueries,omitempty"`
+	QueryTimeout         *int64   `protobuf:"varint,2,opt,name=QueryTimeout" json:"QueryTimeout,omitempty"`
+	MaxSelectPointN      *int64   `protobuf:"varint,3,opt,name=MaxSelectPointN" json:"MaxSelectPointN,omitempty"`
+	MaxSelectSeriesN     *int64   `protobuf:"varint,4,opt,name=MaxSelectSeriesN" json:"MaxSelectSeriesN,omitempty"`
+	MaxSelectBucketsN    *int64   `protobuf:"varint,5,opt,name=MaxSelectBucketsN" json:"MaxSelectBucketsN,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryQuota) Reset()         { *m = QueryQuota{} }
+func (m *QueryQuota) String() string { return proto.CompactTextString(m) }
+func (*QueryQuota) ProtoMessage()    {}
+func (*QueryQuota) Descriptor() ([]byte, []int) {
+	return fileDescriptor_59b0956366e72083, []int{13}
+}
+func (m *QueryQuota) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryQuota.Unmarshal(m, b)
+}
+func (m *QueryQuota) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryQuota.Marshal(b, m, deterministic)
+}
+func (m *QueryQuota) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryQuota.Merge(m, src)
+}
+func (m *QueryQuota) XXX_Size() int {
+	return xxx_messageInfo_QueryQuota.Size(m)
+}
+func (m *QueryQuota) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryQuota.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryQuota proto.InternalMessageInfo
+
+func (m *QueryQuota) GetMaxConcurrentQueries() int64 {
+	if m != nil && m.MaxConcurrentQueries != nil {
+		return *m.MaxConcurrentQueries
+	}
+	return 0
+}
+
+func (m *QueryQuota) GetQueryTimeout() int64 {
+	if m != nil && m.QueryTimeout != nil {
+		return *m.QueryTimeout
+	}
+	return 0
+}
+
+func (m *QueryQuota) GetMaxSelectPointN() int64 {
+	if m != nil && m.MaxSelectPointN != nil {
+		return *m.MaxSelectPointN
+	}
+	return 0
+}
+
+func (m *QueryQuota) GetMaxSelectSeriesN() int64 {
+	if m != nil && m.MaxSelectSeriesN != nil {
+		return *m.MaxSelectSeriesN
+	}
+	return 0
+}
+
+func (m *QueryQuota) GetMaxSelectBucketsN() int64 {
+	if m != nil && m.MaxSelectBucketsN != nil {
+		return *m.MaxSelectBucketsN
+	}
+	return 0
+}
+
+type FieldSchema struct {
+	Name                 *string  `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
+	Type                 *string  `protobuf:"bytes,2,req,name=Type" json:"Type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FieldSchema) Reset()         { *m = FieldSchema{} }
+func (m *FieldSchema) String() string { return proto.CompactTextString(m) }
+func (*FieldSchema) ProtoMessage()    {}
+func (*FieldSchema) Descriptor() ([]byte, []int) {
+	return fileDescriptor_59b0956366e72083, []int{14}
+}
+func (m *FieldSchema) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FieldSchema.Unmarshal(m, b)
+}
+func (m *FieldSchema) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FieldSchema.Marshal(b, m, deterministic)
+}
+func (m *FieldSchema) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldSchema.Merge(m, src)
+}
+func (m *FieldSchema) XXX_Size() int {
+	return xxx_messageInfo_FieldSchema.Size(m)
+}
+func (m *FieldSchema) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldSchema.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldSchema proto.InternalMessageInfo
+
+func (m *FieldSchema) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *FieldSchema) GetType() string {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return ""
+}
+
+type MeasurementSchema struct {
+	Name                 *string        `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
+	RequiredTags         []string       `protobuf:"bytes,2,rep,name=RequiredTags" json:"RequiredTags,omitempty"`
+	AllowedTags          []string       `protobuf:"bytes,3,rep,name=AllowedTags" json:"AllowedTags,omitempty"`
+	Fields               []*FieldSchema `protobuf:"bytes,4,rep,name=Fields" json:"Fields,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *MeasurementSchema) Reset()         { *m = MeasurementSchema{} }
+func (m *MeasurementSchema) String() string { return proto.CompactTextString(m) }
+func (*MeasurementSchema) ProtoMessage()    {}
+func (*MeasurementSchema) Descriptor() ([]byte, []int) {
+	return fileDescriptor_59b0956366e72083, []int{15}
+}
+func (m *MeasurementSchema) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MeasurementSchema.Unmarshal(m, b)
+}
+func (m *MeasurementSchema) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MeasurementSchema.Marshal(b, m, deterministic)
+}
+func (m *MeasurementSchema) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MeasurementSchema.Merge(m, src)
+}
+func (m *MeasurementSchema) XXX_Size() int {
+	return xxx_messageInfo_MeasurementSchema.Size(m)
+}
+func (m *MeasurementSchema) XXX_DiscardUnknown() {
+	xxx_messageInfo_MeasurementSchema.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MeasurementSchema proto.InternalMessageInfo
+
+func (m *MeasurementSchema) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *MeasurementSchema) GetRequiredTags() []string {
+	if m != nil {
+		return m.RequiredTags
+	}
+	return nil
+}
+
+func (m *MeasurementSchema) GetAllowedTags() []string {
+	if m != nil {
+		return m.AllowedTags
+	}
+	return nil
+}
+
+func (m *MeasurementSchema) GetFields() []*FieldSchema {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+type DatabaseSchema struct {
+	Mode                 *string              `protobuf:"bytes,1,req,name=Mode" json:"Mode,omitempty"`
+	Measurements         []*MeasurementSchema `protobuf:"bytes,2,rep,name=Measurements" json:"Measurements,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *DatabaseSchema) Reset()         { *m = DatabaseSchema{} }
+func (m *DatabaseSchema) String() string { return proto.CompactTextString(m) }
+func (*DatabaseSchema) ProtoMessage()    {}
+func (*DatabaseSchema) Descriptor() ([]byte, []int) {
+	return fileDescriptor_59b0956366e72083, []int{16}
+}
+func (m *DatabaseSchema) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DatabaseSchema.Unmarshal(m, b)
+}
+func (m *DatabaseSchema) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DatabaseSchema.Marshal(b, m, deterministic)
+}
+func (m *DatabaseSchema) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DatabaseSchema.Merge(m, src)
+}
+func (m *DatabaseSchema) XXX_Size() int {
+	return xxx_messageInfo_DatabaseSchema.Size(m)
+}
+func (m *DatabaseSchema) XXX_DiscardUnknown() {
+	xxx_messageInfo_DatabaseSchema.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DatabaseSchema proto.InternalMessageInfo
+
+func (m *DatabaseSchema) GetMode() string {
+	if m != nil && m.Mode != nil {
+		return *m.Mode
+	}
+	return ""
+}
+
+func (m *DatabaseSchema) GetMeasurements() []*MeasurementSchema {
+	if m != nil {
+		return m.Measurements
+	}
+	return nil
+}
+
+type ReadGrant struct {
+	Database             *string  `protobuf:"bytes,1,req,name=Database" json:"Database,omitempty"`
+	Measurement          *string  `protobuf:"bytes,2,opt,name=Measurement" json:"Measurement,omitempty"`
+	Condition            *string  `protobuf:"bytes,3,opt,name=Condition" json:"Condition,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadGrant) Reset()         { *m = ReadGrant{} }
+func (m *ReadGrant) String() string { return proto.CompactTextString(m) }
+func (*ReadGrant) ProtoMessage()    {}
+func (*ReadGrant) Descriptor() ([]byte, []int) {
+	return fileDescriptor_59b0956366e72083, []int{17}
+}
+func (m *ReadGrant) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadGrant.Unmarshal(m, b)
+}
+func (m *ReadGrant) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadGrant.Marshal(b, m, deterministic)
+}
+func (m *ReadGrant) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadGrant.Merge(m, src)
+}
+func (m *ReadGrant) XXX_Size() int {
+	return xxx_messageInfo_ReadGrant.Size(m)
+}
+func (m *ReadGrant) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadGrant.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadGrant proto.InternalMessageInfo
+
+func (m *ReadGrant) GetDatabase() string {
+	if m != nil && m.Database != nil {
+		return *m.Database
+	}
+	return ""
+}
+
+func (m *ReadGrant) GetMeasurement() string {
+	if m != nil && m.Measurement != nil {
+		return *m.Measurement
+	}
+	return ""
+}
+
+func (m *ReadGrant) GetCondition() string {
+	if m != nil && m.Condition != nil {
+		return *m.Condition
+	}
+	return ""
+}
+
+type TokenInfo struct {
+	ID                   *string  `protobuf:"bytes,1,req,name=ID" json:"ID,omitempty"`
+	User                 *string  `protobuf:"bytes,2,req,name=User" json:"User,omitempty"`
+	Hash                 *string  `protobuf:"bytes,3,req,name=Hash" json:"Hash,omitempty"`
+	Scopes               []string `protobuf:"bytes,4,rep,name=Scopes" json:"Scopes,omitempty"`
+	Description          *string  `protobuf:"bytes,5,opt,name=Description" json:"Description,omitempty"`
+	CreatedAt            *int64   `protobuf:"varint,6,opt,name=CreatedAt" json:"CreatedAt,omitempty"`
+	ExpiresAt            *int64   `protobuf:"varint,7,opt,name=ExpiresAt" json:"ExpiresAt,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TokenInfo) Reset()         { *m = TokenInfo{} }
+func (m *TokenInfo) String() string { return proto.CompactTextString(m) }
+func (*TokenInfo) ProtoMessage()    {}
+func (*TokenInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_59b0956366e72083, []int{18}
+}
+func (m *TokenInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TokenInfo.Unmarshal(m, b)
+}
+func (m *TokenInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TokenInfo.Marshal(b, m, deterministic)
+}
+func (m *TokenInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TokenInfo.Merge(m, src)
+}
+func (m *TokenInfo) XXX_Size() int {
+	return xxx_messageInfo_TokenInfo.Size(m)
+}
+func (m *TokenInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_TokenInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TokenInfo proto.InternalMessageInfo
+
+func (m *TokenInfo) GetID() string {
+	if m != nil && m.ID != nil {
+		return *m.ID
+	}
+	return ""
+}
+
+func (m *TokenInfo) GetUser() string {
+	if m != nil && m.User != nil {
+		return *m.User
+	}
+	return ""
+}
+
+func (m *TokenInfo) GetHash() string {
+	if m != nil && m.Hash != nil {
+		return *m.Hash
+	}
+	return ""
+}
+
+func (m *TokenInfo) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *TokenInfo) GetDescription() string {
+	if m != nil && m.Description != nil {
+		return *m.Description
+	}
+	return ""
+}
+
+func (m *TokenInfo) GetCreatedAt() int64 {
+	if m != nil && m.CreatedAt != nil {
+		return *m.CreatedAt
+	}
+	return 0
+}
+
+func (m *TokenInfo) GetExpiresAt() int64 {
+	if m != nil && m.ExpiresAt != nil {
+		return *m.ExpiresAt
+	}
+	return 0
+}
+
+type WriteQuota struct {
+	MaxSeries            *int64   `protobuf:"varint,1,opt,name=MaxSeries" json:"MaxSeries,omitempty"`
+	MaxDiskBytes         *int64   `protobuf:"varint,2,opt,name=MaxDiskBytes" json:"MaxDiskBytes,omitempty"`
+	MaxPointsPerSecond   *int64   `protobuf:"varint,3,opt,name=MaxPointsPerSecond" json:"MaxPointsPerSecond,omitempty"`
+	PointsBurst          *int64   `protobuf:"varint,4,opt,name=PointsBurst" json:"PointsBurst,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WriteQuota) Reset()         { *m = WriteQuota{} }
+func (m *WriteQuota) String() string { return proto.CompactTextString(m) }
+func (*WriteQuota) ProtoMessage()    {}
+func (*WriteQuota) Descriptor() ([]byte, []int) {
+	return fileDescriptor_59b0956366e72083, []int{19}
+}
+func (m *WriteQuota) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WriteQuota.Unmarshal(m, b)
+}
+func (m *WriteQuota) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WriteQuota.Marshal(b, m, deterministic)
+}
+func (m *WriteQuota) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WriteQuota.Merge(m, src)
+}
+func (m *WriteQuota) XXX_Size() int {
+	return xxx_messageInfo_WriteQuota.Size(m)
+}
+func (m *WriteQuota) XXX_DiscardUnknown() {
+	xxx_messageInfo_WriteQuota.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WriteQuota proto.InternalMessageInfo
+
+func (m *WriteQuota) GetMaxSeries() int64 {
+	if m != nil && m.MaxSeries != nil {
+		return *m.MaxSeries
+	}
+	return 0
+}
+
+func (m *WriteQuota) GetMaxDiskBytes() int64 {
+	if m != nil && m.MaxDiskBytes != nil {
+		return *m.MaxDiskBytes
+	}
+	return 0
+}
+
+func (m *WriteQuota) GetMaxPointsPerSecond() int64 {
+	if m != nil && m.MaxPointsPerSecond != nil {
+		return *m.MaxPointsPerSecond
+	}
+	return 0
+}
+
+func (m *WriteQuota) GetPointsBurst() int64 {
+	if m != nil && m.PointsBurst != nil {
+		return *m.PointsBurst
+	}
+	return 0
+}
+
 type Command struct {
 	Type                         *Command_Type `protobuf:"varint,1,req,name=type,enum=meta.Command_Type" json:"type,omitempty"`
 	XXX_NoUnkeyedLiteral         struct{}      `json:"-"`
@@ -907,7 +1451,7 @@ func (m *Command) Reset()         { *m = Command{} }
 func (m *Command) String() string { return proto.CompactTextString(m) }
 func (*Command) ProtoMessage()    {}
 func (*Command) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{12}
+	return fileDescriptor_59b0956366e72083, []int{20}
 }
 
 var extRange_Command = []proto.ExtensionRange{
@@ -957,7 +1501,7 @@ func (m *CreateNodeCommand) Reset()         { *m = CreateNodeCommand{} }
 func (m *CreateNodeCommand) String() string { return proto.CompactTextString(m) }
 func (*CreateNodeCommand) ProtoMessage()    {}
 func (*CreateNodeCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{13}
+	return fileDescriptor_59b0956366e72083, []int{21}
 }
 func (m *CreateNodeCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateNodeCommand.Unmarshal(m, b)
@@ -1012,7 +1556,7 @@ func (m *DeleteNodeCommand) Reset()         { *m = DeleteNodeCommand{} }
 func (m *DeleteNodeCommand) String() string { return proto.CompactTextString(m) }
 func (*DeleteNodeCommand) ProtoMessage()    {}
 func (*DeleteNodeCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{14}
+	return fileDescriptor_59b0956366e72083, []int{22}
 }
 func (m *DeleteNodeCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeleteNodeCommand.Unmarshal(m, b)
@@ -1067,7 +1611,7 @@ func (m *CreateDatabaseCommand) Reset()         { *m = CreateDatabaseCommand{} }
 func (m *CreateDatabaseCommand) String() string { return proto.CompactTextString(m) }
 func (*CreateDatabaseCommand) ProtoMessage()    {}
 func (*CreateDatabaseCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{15}
+	return fileDescriptor_59b0956366e72083, []int{23}
 }
 func (m *CreateDatabaseCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateDatabaseCommand.Unmarshal(m, b)
@@ -1121,7 +1665,7 @@ func (m *DropDatabaseCommand) Reset()         { *m = DropDatabaseCommand{} }
 func (m *DropDatabaseCommand) String() string { return proto.CompactTextString(m) }
 func (*DropDatabaseCommand) ProtoMessage()    {}
 func (*DropDatabaseCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{16}
+	return fileDescriptor_59b0956366e72083, []int{24}
 }
 func (m *DropDatabaseCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DropDatabaseCommand.Unmarshal(m, b)
@@ -1169,7 +1713,7 @@ func (m *CreateRetentionPolicyCommand) Reset()         { *m = CreateRetentionPol
 func (m *CreateRetentionPolicyCommand) String() string { return proto.CompactTextString(m) }
 func (*CreateRetentionPolicyCommand) ProtoMessage()    {}
 func (*CreateRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{17}
+	return fileDescriptor_59b0956366e72083, []int{25}
 }
 func (m *CreateRetentionPolicyCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateRetentionPolicyCommand.Unmarshal(m, b)
@@ -1224,7 +1768,7 @@ func (m *DropRetentionPolicyCommand) Reset()         { *m = DropRetentionPolicyC
 func (m *DropRetentionPolicyCommand) String() string { return proto.CompactTextString(m) }
 func (*DropRetentionPolicyCommand) ProtoMessage()    {}
 func (*DropRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{18}
+	return fileDescriptor_59b0956366e72083, []int{26}
 }
 func (m *DropRetentionPolicyCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DropRetentionPolicyCommand.Unmarshal(m, b)
@@ -1279,7 +1823,7 @@ func (m *SetDefaultRetentionPolicyCommand) Reset()         { *m = SetDefaultRete
 func (m *SetDefaultRetentionPolicyCommand) String() string { return proto.CompactTextString(m) }
 func (*SetDefaultRetentionPolicyCommand) ProtoMessage()    {}
 func (*SetDefaultRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{19}
+	return fileDescriptor_59b0956366e72083, []int{27}
 }
 func (m *SetDefaultRetentionPolicyCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SetDefaultRetentionPolicyCommand.Unmarshal(m, b)
@@ -1337,7 +1881,7 @@ func (m *UpdateRetentionPolicyCommand) Reset()         { *m = UpdateRetentionPol
 func (m *UpdateRetentionPolicyCommand) String() string { return proto.CompactTextString(m) }
 func (*UpdateRetentionPolicyCommand) ProtoMessage()    {}
 func (*UpdateRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{20}
+	return fileDescriptor_59b0956366e72083, []int{28}
 }
 func (m *UpdateRetentionPolicyCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateRetentionPolicyCommand.Unmarshal(m, b)
@@ -1414,7 +1958,7 @@ func (m *CreateShardGroupCommand) Reset()         { *m = CreateShardGroupCommand
 func (m *CreateShardGroupCommand) String() string { return proto.CompactTextString(m) }
 func (*CreateShardGroupCommand) ProtoMessage()    {}
 func (*CreateShardGroupCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{21}
+	return fileDescriptor_59b0956366e72083, []int{29}
 }
 func (m *CreateShardGroupCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateShardGroupCommand.Unmarshal(m, b)
@@ -1477,7 +2021,7 @@ func (m *DeleteShardGroupCommand) Reset()         { *m = DeleteShardGroupCommand
 func (m *DeleteShardGroupCommand) String() string { return proto.CompactTextString(m) }
 func (*DeleteShardGroupCommand) ProtoMessage()    {}
 func (*DeleteShardGroupCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{22}
+	return fileDescriptor_59b0956366e72083, []int{30}
 }
 func (m *DeleteShardGroupCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeleteShardGroupCommand.Unmarshal(m, b)
@@ -1540,7 +2084,7 @@ func (m *CreateContinuousQueryCommand) Reset()         { *m = CreateContinuousQu
 func (m *CreateContinuousQueryCommand) String() string { return proto.CompactTextString(m) }
 func (*CreateContinuousQueryCommand) ProtoMessage()    {}
 func (*CreateContinuousQueryCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{23}
+	return fileDescriptor_59b0956366e72083, []int{31}
 }
 func (m *CreateContinuousQueryCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateContinuousQueryCommand.Unmarshal(m, b)
@@ -1602,7 +2146,7 @@ func (m *DropContinuousQueryCommand) Reset()         { *m = DropContinuousQueryC
 func (m *DropContinuousQueryCommand) String() string { return proto.CompactTextString(m) }
 func (*DropContinuousQueryCommand) ProtoMessage()    {}
 func (*DropContinuousQueryCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{24}
+	return fileDescriptor_59b0956366e72083, []int{32}
 }
 func (m *DropContinuousQueryCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DropContinuousQueryCommand.Unmarshal(m, b)
@@ -1658,7 +2202,7 @@ func (m *CreateUserCommand) Reset()         { *m = CreateUserCommand{} }
 func (m *CreateUserCommand) String() string { return proto.CompactTextString(m) }
 func (*CreateUserCommand) ProtoMessage()    {}
 func (*CreateUserCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{25}
+	return fileDescriptor_59b0956366e72083, []int{33}
 }
 func (m *CreateUserCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateUserCommand.Unmarshal(m, b)
@@ -1719,7 +2263,7 @@ func (m *DropUserCommand) Reset()         { *m = DropUserCommand{} }
 func (m *DropUserCommand) String() string { return proto.CompactTextString(m) }
 func (*DropUserCommand) ProtoMessage()    {}
 func (*DropUserCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{26}
+	return fileDescriptor_59b0956366e72083, []int{34}
 }
 func (m *DropUserCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DropUserCommand.Unmarshal(m, b)
@@ -1767,7 +2311,7 @@ func (m *UpdateUserCommand) Reset()         { *m = UpdateUserCommand{} }
 func (m *UpdateUserCommand) String() string { return proto.CompactTextString(m) }
 func (*UpdateUserCommand) ProtoMessage()    {}
 func (*UpdateUserCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{27}
+	return fileDescriptor_59b0956366e72083, []int{35}
 }
 func (m *UpdateUserCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateUserCommand.Unmarshal(m, b)
@@ -1823,7 +2367,7 @@ func (m *SetPrivilegeCommand) Reset()         { *m = SetPrivilegeCommand{} }
 func (m *SetPrivilegeCommand) String() string { return proto.CompactTextString(m) }
 func (*SetPrivilegeCommand) ProtoMessage()    {}
 func (*SetPrivilegeCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{28}
+	return fileDescriptor_59b0956366e72083, []int{36}
 }
 func (m *SetPrivilegeCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SetPrivilegeCommand.Unmarshal(m, b)
@@ -1884,7 +2428,7 @@ func (m *SetDataCommand) Reset()         { *m = SetDataCommand{} }
 func (m *SetDataCommand) String() string { return proto.CompactTextString(m) }
 func (*SetDataCommand) ProtoMessage()    {}
 func (*SetDataCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{29}
+	return fileDescriptor_59b0956366e72083, []int{37}
 }
 func (m *SetDataCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SetDataCommand.Unmarshal(m, b)
@@ -1932,7 +2476,7 @@ func (m *SetAdminPrivilegeCommand) Reset()         { *m = SetAdminPrivilegeComma
 func (m *SetAdminPrivilegeCommand) String() string { return proto.CompactTextString(m) }
 func (*SetAdminPrivilegeCommand) ProtoMessage()    {}
 func (*SetAdminPrivilegeCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{30}
+	return fileDescriptor_59b0956366e72083, []int{38}
 }
 func (m *SetAdminPrivilegeCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SetAdminPrivilegeCommand.Unmarshal(m, b)
@@ -1987,7 +2531,7 @@ func (m *UpdateNodeCommand) Reset()         { *m = UpdateNodeCommand{} }
 func (m *UpdateNodeCommand) String() string { return proto.CompactTextString(m) }
 func (*UpdateNodeCommand) ProtoMessage()    {}
 func (*UpdateNodeCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{31}
+	return fileDescriptor_59b0956366e72083, []int{39}
 }
 func (m *UpdateNodeCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateNodeCommand.Unmarshal(m, b)
@@ -2045,7 +2589,7 @@ func (m *CreateSubscriptionCommand) Reset()         { *m = CreateSubscriptionCom
 func (m *CreateSubscriptionCommand) String() string { return proto.CompactTextString(m) }
 func (*CreateSubscriptionCommand) ProtoMessage()    {}
 func (*CreateSubscriptionCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{32}
+	return fileDescriptor_59b0956366e72083, []int{40}
 }
 func (m *CreateSubscriptionCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateSubscriptionCommand.Unmarshal(m, b)
@@ -2122,7 +2666,7 @@ func (m *DropSubscriptionCommand) Reset()         { *m = DropSubscriptionCommand
 func (m *DropSubscriptionCommand) String() string { return proto.CompactTextString(m) }
 func (*DropSubscriptionCommand) ProtoMessage()    {}
 func (*DropSubscriptionCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{33}
+	return fileDescriptor_59b0956366e72083, []int{41}
 }
 func (m *DropSubscriptionCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DropSubscriptionCommand.Unmarshal(m, b)
@@ -2184,7 +2728,7 @@ func (m *RemovePeerCommand) Reset()         { *m = RemovePeerCommand{} }
 func (m *RemovePeerCommand) String() string { return proto.CompactTextString(m) }
 func (*RemovePeerCommand) ProtoMessage()    {}
 func (*RemovePeerCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{34}
+	return fileDescriptor_59b0956366e72083, []int{42}
 }
 func (m *RemovePeerCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RemovePeerCommand.Unmarshal(m, b)
@@ -2240,7 +2784,7 @@ func (m *CreateMetaNodeCommand) Reset()         { *m = CreateMetaNodeCommand{} }
 func (m *CreateMetaNodeCommand) String() string { return proto.CompactTextString(m) }
 func (*CreateMetaNodeCommand) ProtoMessage()    {}
 func (*CreateMetaNodeCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{35}
+	return fileDescriptor_59b0956366e72083, []int{43}
 }
 func (m *CreateMetaNodeCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateMetaNodeCommand.Unmarshal(m, b)
@@ -2302,7 +2846,7 @@ func (m *CreateDataNodeCommand) Reset()         { *m = CreateDataNodeCommand{} }
 func (m *CreateDataNodeCommand) String() string { return proto.CompactTextString(m) }
 func (*CreateDataNodeCommand) ProtoMessage()    {}
 func (*CreateDataNodeCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{36}
+	return fileDescriptor_59b0956366e72083, []int{44}
 }
 func (m *CreateDataNodeCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateDataNodeCommand.Unmarshal(m, b)
@@ -2358,7 +2902,7 @@ func (m *UpdateDataNodeCommand) Reset()         { *m = UpdateDataNodeCommand{} }
 func (m *UpdateDataNodeCommand) String() string { return proto.CompactTextString(m) }
 func (*UpdateDataNodeCommand) ProtoMessage()    {}
 func (*UpdateDataNodeCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{37}
+	return fileDescriptor_59b0956366e72083, []int{45}
 }
 func (m *UpdateDataNodeCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateDataNodeCommand.Unmarshal(m, b)
@@ -2419,7 +2963,7 @@ func (m *DeleteMetaNodeCommand) Reset()         { *m = DeleteMetaNodeCommand{} }
 func (m *DeleteMetaNodeCommand) String() string { return proto.CompactTextString(m) }
 func (*DeleteMetaNodeCommand) ProtoMessage()    {}
 func (*DeleteMetaNodeCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{38}
+	return fileDescriptor_59b0956366e72083, []int{46}
 }
 func (m *DeleteMetaNodeCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeleteMetaNodeCommand.Unmarshal(m, b)
@@ -2466,7 +3010,7 @@ func (m *DeleteDataNodeCommand) Reset()         { *m = DeleteDataNodeCommand{} }
 func (m *DeleteDataNodeCommand) String() string { return proto.CompactTextString(m) }
 func (*DeleteDataNodeCommand) ProtoMessage()    {}
 func (*DeleteDataNodeCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{39}
+	return fileDescriptor_59b0956366e72083, []int{47}
 }
 func (m *DeleteDataNodeCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeleteDataNodeCommand.Unmarshal(m, b)
@@ -2515,7 +3059,7 @@ func (m *Response) Reset()         { *m = Response{} }
 func (m *Response) String() string { return proto.CompactTextString(m) }
 func (*Response) ProtoMessage()    {}
 func (*Response) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{40}
+	return fileDescriptor_59b0956366e72083, []int{48}
 }
 func (m *Response) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Response.Unmarshal(m, b)
@@ -2571,7 +3115,7 @@ func (m *SetMetaNodeCommand) Reset()         { *m = SetMetaNodeCommand{} }
 func (m *SetMetaNodeCommand) String() string { return proto.CompactTextString(m) }
 func (*SetMetaNodeCommand) ProtoMessage()    {}
 func (*SetMetaNodeCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{41}
+	return fileDescriptor_59b0956366e72083, []int{49}
 }
 func (m *SetMetaNodeCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SetMetaNodeCommand.Unmarshal(m, b)
@@ -2632,7 +3176,7 @@ func (m *DropShardCommand) Reset()         { *m = DropShardCommand{} }
 func (m *DropShardCommand) String() string { return proto.CompactTextString(m) }
 func (*DropShardCommand) ProtoMessage()    {}
 func (*DropShardCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptor_59b0956366e72083, []int{42}
+	return fileDescriptor_59b0956366e72083, []int{50}
 }
 func (m *DropShardCommand) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DropShardCommand.Unmarshal(m, b)
@@ -2678,10 +3222,18 @@ func init() {
 	proto.RegisterType((*ShardGroupInfo)(nil), "meta.ShardGroupInfo")
 	proto.RegisterType((*ShardInfo)(nil), "meta.ShardInfo")
 	proto.RegisterType((*SubscriptionInfo)(nil), "meta.SubscriptionInfo")
+	proto.RegisterType((*SubscriptionFilter)(nil), "meta.SubscriptionFilter")
 	proto.RegisterType((*ShardOwner)(nil), "meta.ShardOwner")
 	proto.RegisterType((*ContinuousQueryInfo)(nil), "meta.ContinuousQueryInfo")
 	proto.RegisterType((*UserInfo)(nil), "meta.UserInfo")
 	proto.RegisterType((*UserPrivilege)(nil), "meta.UserPrivilege")
+	proto.RegisterType((*QueryQuota)(nil), "meta.QueryQuota")
+	proto.RegisterType((*FieldSchema)(nil), "meta.FieldSchema")
+	proto.RegisterType((*MeasurementSchema)(nil), "meta.MeasurementSchema")
+	proto.RegisterType((*DatabaseSchema)(nil), "meta.DatabaseSchema")
+	proto.RegisterType((*ReadGrant)(nil), "meta.ReadGrant")
+	proto.RegisterType((*TokenInfo)(nil), "meta.TokenInfo")
+	proto.RegisterType((*WriteQuota)(nil), "meta.WriteQuota")
 	proto.RegisterType((*Command)(nil), "meta.Command")
 	proto.RegisterExtension(E_CreateNodeCommand_Command)
 	proto.RegisterType((*CreateNodeCommand)(nil), "meta.CreateNodeCommand")
@@ -2747,118 +3299,150 @@ func init() {
 func init() { proto.RegisterFile("internal/meta.proto", fileDescriptor_59b0956366e72083) }
 
 var fileDescriptor_59b0956366e72083 = []byte{
-	// 1808 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x59, 0x4b, 0x6f, 0x1c, 0xc7,
-	0x11, 0x46, 0xcf, 0x3e, 0xb8, 0x5b, 0x7c, 0xaa, 0xf9, 0x1a, 0x4a, 0x14, 0xb3, 0x18, 0x08, 0xca,
-	0x22, 0x08, 0x98, 0x60, 0x03, 0xe8, 0x94, 0x97, 0xc4, 0x95, 0xc4, 0x85, 0xc0, 0x47, 0x66, 0xa9,
-	0x6b, 0x80, 0x11, 0xb7, 0x25, 0x6e, 0xb2, 0x3b, 0xb3, 0x99, 0x99, 0x95, 0xc4, 0x28, 0x4c, 0x18,
-	0x5f, 0x7c, 0xb5, 0x61, 0x18, 0x3e, 0xe8, 0x66, 0x1f, 0x7c, 0x34, 0x0c, 0x03, 0x06, 0x0c, 0x9f,
-	0x7c, 0xf7, 0x1f, 0xf0, 0x7f, 0xb0, 0xcf, 0xbe, 0x1a, 0xdd, 0x3d, 0x3d, 0xdd, 0x33, 0xd3, 0x3d,
-	0x24, 0x65, 0xf9, 0x36, 0x5d, 0x55, 0xdd, 0xf5, 0x55, 0x75, 0x75, 0x75, 0x55, 0x0f, 0x2c, 0x0f,
-	0xfd, 0x98, 0x84, 0xbe, 0x37, 0xfa, 0xdd, 0x98, 0xc4, 0xde, 0xf6, 0x24, 0x0c, 0xe2, 0x00, 0x57,
-	0xe9, 0xb7, 0xf3, 0x5e, 0x05, 0xaa, 0x5d, 0x2f, 0xf6, 0x30, 0x86, 0xea, 0x11, 0x09, 0xc7, 0x36,
-	0x6a, 0x59, 0xed, 0xaa, 0xcb, 0xbe, 0xf1, 0x0a, 0xd4, 0x7a, 0xfe, 0x80, 0xbc, 0xb4, 0x2d, 0x46,
-	0xe4, 0x03, 0xbc, 0x09, 0xcd, 0x9d, 0xd1, 0x34, 0x8a, 0x49, 0xd8, 0xeb, 0xda, 0x15, 0xc6, 0x91,
-	0x04, 0x7c, 0x0b, 0x6a, 0xfb, 0xc1, 0x80, 0x44, 0x76, 0xb5, 0x55, 0x69, 0xcf, 0x76, 0x16, 0xb6,
-	0x99, 0x4a, 0x4a, 0xea, 0xf9, 0x4f, 0x03, 0x97, 0x33, 0xf1, 0xef, 0xa1, 0x49, 0xb5, 0x3e, 0xf1,
-	0x22, 0x12, 0xd9, 0x35, 0x26, 0x89, 0xb9, 0xa4, 0x20, 0x33, 0x69, 0x29, 0x44, 0xd7, 0x7d, 0x1c,
-	0x91, 0x30, 0xb2, 0xeb, 0xea, 0xba, 0x94, 0xc4, 0xd7, 0x65, 0x4c, 0x8a, 0x6d, 0xcf, 0x7b, 0xc9,
-	0xb4, 0x75, 0xed, 0x19, 0x8e, 0x2d, 0x25, 0xe0, 0x36, 0x2c, 0xee, 0x79, 0x2f, 0xfb, 0x27, 0x5e,
-	0x38, 0x78, 0x18, 0x06, 0xd3, 0x49, 0xaf, 0x6b, 0x37, 0x98, 0x4c, 0x9e, 0x8c, 0xb7, 0x00, 0x04,
-	0xa9, 0xd7, 0xb5, 0x9b, 0x4c, 0x48, 0xa1, 0xe0, 0xdf, 0x72, 0xfc, 0xdc, 0x52, 0xd0, 0x5a, 0x2a,
-	0x05, 0xa8, 0xf4, 0x1e, 0x11, 0xd2, 0xb3, 0x7a, 0xe9, 0x54, 0xc0, 0xd9, 0x85, 0x86, 0x20, 0xe3,
-	0x05, 0xb0, 0x7a, 0xdd, 0x64, 0x4f, 0xac, 0x5e, 0x97, 0xee, 0xd2, 0x6e, 0x10, 0xc5, 0x6c, 0x43,
-	0x9a, 0x2e, 0xfb, 0xc6, 0x36, 0xcc, 0x1c, 0xed, 0x1c, 0x32, 0x72, 0xa5, 0x85, 0xda, 0x4d, 0x57,
-	0x0c, 0x9d, 0xef, 0x11, 0xcc, 0xa9, 0xfe, 0xa4, 0xd3, 0xf7, 0xbd, 0x31, 0x61, 0x0b, 0x36, 0x5d,
-	0xf6, 0x8d, 0xef, 0xc0, 0x5a, 0x97, 0x3c, 0xf5, 0xa6, 0xa3, 0xd8, 0x25, 0x31, 0xf1, 0xe3, 0x61,
-	0xe0, 0x1f, 0x06, 0xa3, 0xe1, 0xf1, 0x69, 0xa2, 0xc4, 0xc0, 0xc5, 0x0f, 0xe1, 0x5a, 0x96, 0x34,
-	0x24, 0x91, 0x5d, 0x61, 0xc6, 0x6d, 0x70, 0xe3, 0x72, 0x33, 0x98, 0x9d, 0xc5, 0x39, 0x74, 0xa1,
-	0x9d, 0xc0, 0x8f, 0x87, 0xfe, 0x34, 0x98, 0x46, 0x7f, 0x9b, 0x92, 0x70, 0x98, 0x46, 0x4f, 0xb2,
-	0x50, 0x96, 0x9d, 0x2c, 0x54, 0x98, 0xe3, 0xbc, 0x8f, 0x60, 0x39, 0xa7, 0xb3, 0x3f, 0x21, 0xc7,
-	0x8a, 0xd5, 0x28, 0xb5, 0xfa, 0x3a, 0x34, 0xba, 0xd3, 0xd0, 0xa3, 0x92, 0xb6, 0xd5, 0x42, 0xed,
-	0x8a, 0x9b, 0x8e, 0xf1, 0x36, 0x60, 0x19, 0x0c, 0xa9, 0x54, 0x85, 0x49, 0x69, 0x38, 0x74, 0x2d,
-	0x97, 0x4c, 0x46, 0xc3, 0x63, 0x6f, 0xdf, 0xae, 0xb6, 0x50, 0x7b, 0xde, 0x4d, 0xc7, 0xce, 0xbb,
-	0x56, 0x01, 0x93, 0x71, 0x27, 0xb2, 0x98, 0xac, 0x4b, 0x61, 0xb2, 0x2e, 0x85, 0xc9, 0x52, 0x31,
-	0xe1, 0x3b, 0x30, 0x2b, 0x67, 0x88, 0xe3, 0xb7, 0xc2, 0x5d, 0xad, 0x9c, 0x02, 0xea, 0x65, 0x55,
-	0x10, 0xff, 0x11, 0xe6, 0xfb, 0xd3, 0x27, 0xd1, 0x71, 0x38, 0x9c, 0x50, 0x1d, 0xe2, 0x28, 0xae,
-	0x25, 0x33, 0x15, 0x16, 0x9b, 0x9b, 0x15, 0x76, 0xbe, 0x41, 0xb0, 0x90, 0x5d, 0xbd, 0x10, 0xdd,
-	0x9b, 0xd0, 0xec, 0xc7, 0x5e, 0x18, 0x1f, 0x0d, 0xc7, 0x24, 0xf1, 0x80, 0x24, 0xd0, 0x38, 0xbf,
-	0xef, 0x0f, 0x18, 0x8f, 0xdb, 0x2d, 0x86, 0x74, 0x5e, 0x97, 0x8c, 0x48, 0x4c, 0x06, 0x77, 0x63,
-	0x66, 0x6d, 0xc5, 0x95, 0x04, 0xfc, 0x6b, 0xa8, 0x33, 0xbd, 0xc2, 0xd2, 0x45, 0xc5, 0x52, 0x06,
-	0x34, 0x61, 0xe3, 0x16, 0xcc, 0x1e, 0x85, 0x53, 0xff, 0xd8, 0xe3, 0x0b, 0xd5, 0xd9, 0x86, 0xab,
-	0x24, 0x87, 0x40, 0x33, 0x9d, 0x56, 0x40, 0xbf, 0x05, 0x8d, 0x83, 0x17, 0x3e, 0x4d, 0x82, 0x91,
-	0x6d, 0xb5, 0x2a, 0xed, 0xea, 0x3d, 0xcb, 0x46, 0x6e, 0x4a, 0xc3, 0x6d, 0xa8, 0xb3, 0x6f, 0x71,
-	0x4a, 0x96, 0x14, 0x1c, 0x8c, 0xe1, 0x26, 0x7c, 0xe7, 0xef, 0xb0, 0x94, 0xf7, 0xa6, 0x36, 0x60,
-	0x30, 0x54, 0xf7, 0x82, 0x01, 0x11, 0xd9, 0x80, 0x7e, 0x63, 0x07, 0xe6, 0xba, 0x24, 0x8a, 0x87,
-	0xbe, 0xc7, 0xf7, 0x88, 0xea, 0x6a, 0xba, 0x19, 0x9a, 0x73, 0x0b, 0x40, 0x6a, 0xc5, 0x6b, 0x50,
-	0x4f, 0x12, 0x26, 0xb7, 0x25, 0x19, 0x39, 0x7f, 0x81, 0x65, 0xcd, 0xc1, 0xd3, 0x02, 0x59, 0x81,
-	0x1a, 0x13, 0x48, 0x90, 0xf0, 0x81, 0x73, 0x06, 0x0d, 0x91, 0x9f, 0x4d, 0xf0, 0x77, 0xbd, 0xe8,
-	0x24, 0x4d, 0x66, 0x5e, 0x74, 0x42, 0x57, 0xba, 0x3b, 0x18, 0x0f, 0x79, 0x68, 0x37, 0x5c, 0x3e,
-	0xc0, 0x7f, 0x00, 0x38, 0x0c, 0x87, 0xcf, 0x87, 0x23, 0xf2, 0x2c, 0xcd, 0x0d, 0xcb, 0xf2, 0x06,
-	0x48, 0x79, 0xae, 0x22, 0xe6, 0xf4, 0x60, 0x3e, 0xc3, 0x64, 0xe7, 0x2b, 0xc9, 0x86, 0x09, 0x8e,
-	0x74, 0x4c, 0x43, 0x28, 0x15, 0x64, 0x80, 0x6a, 0xae, 0x24, 0x38, 0xdf, 0xd5, 0x61, 0x66, 0x27,
-	0x18, 0x8f, 0x3d, 0x7f, 0x80, 0x6f, 0x43, 0x35, 0x3e, 0x9d, 0xf0, 0x15, 0x16, 0xc4, 0xad, 0x95,
-	0x30, 0xb7, 0x8f, 0x4e, 0x27, 0xc4, 0x65, 0x7c, 0xe7, 0x75, 0x1d, 0xaa, 0x74, 0x88, 0x57, 0xe1,
-	0xda, 0x4e, 0x48, 0xbc, 0x98, 0x50, 0xbf, 0x26, 0x82, 0x4b, 0x88, 0x92, 0x79, 0x8c, 0xaa, 0x64,
-	0x0b, 0x6f, 0xc0, 0x2a, 0x97, 0x16, 0xd0, 0x04, 0xab, 0x82, 0xd7, 0x61, 0xb9, 0x1b, 0x06, 0x93,
-	0x3c, 0xa3, 0x8a, 0x5b, 0xb0, 0xc9, 0xe7, 0xe4, 0x32, 0x8d, 0x90, 0xa8, 0xe1, 0x2d, 0xb8, 0x4e,
-	0xa7, 0x1a, 0xf8, 0x75, 0x7c, 0x0b, 0x5a, 0x7d, 0x12, 0xeb, 0x33, 0xbd, 0x90, 0x9a, 0xa1, 0x7a,
-	0x1e, 0x4f, 0x06, 0x66, 0x3d, 0x0d, 0x7c, 0x03, 0xd6, 0x39, 0x12, 0x79, 0xd2, 0x05, 0xb3, 0x49,
-	0x99, 0xdc, 0xe2, 0x22, 0x13, 0xa4, 0x0d, 0xb9, 0x98, 0x13, 0x12, 0xb3, 0xc2, 0x06, 0x03, 0x7f,
-	0x4e, 0xfa, 0x99, 0xee, 0xba, 0x20, 0xcf, 0xe3, 0x65, 0x58, 0xa4, 0xd3, 0x54, 0xe2, 0x02, 0x95,
-	0xe5, 0x96, 0xa8, 0xe4, 0x45, 0xea, 0xe1, 0x3e, 0x89, 0xd3, 0x7d, 0x17, 0x8c, 0x25, 0x8c, 0x61,
-	0x81, 0xfa, 0xc7, 0x8b, 0x3d, 0x41, 0xbb, 0x86, 0x37, 0xc1, 0xee, 0x93, 0x98, 0x05, 0x68, 0x61,
-	0x06, 0x96, 0x1a, 0xd4, 0xed, 0x5d, 0xc6, 0x37, 0x61, 0x23, 0x71, 0x90, 0x72, 0xc0, 0x05, 0x7b,
-	0x95, 0xb9, 0x28, 0x0c, 0x26, 0x3a, 0xe6, 0x1a, 0x5d, 0xd2, 0x25, 0xe3, 0xe0, 0x39, 0x39, 0x24,
-	0x12, 0xf4, 0xba, 0x8c, 0x18, 0x51, 0x42, 0x08, 0x96, 0x9d, 0x0d, 0x26, 0x95, 0xb5, 0x41, 0x59,
-	0x1c, 0x5f, 0x9e, 0x75, 0x9d, 0xb2, 0xf8, 0x3e, 0xe5, 0x17, 0xbc, 0x21, 0x59, 0xf9, 0x59, 0x9b,
-	0x78, 0x0d, 0x70, 0x9f, 0xc4, 0xf9, 0x29, 0x37, 0xf1, 0x0a, 0x2c, 0x31, 0x93, 0xe8, 0x9e, 0x0b,
-	0xea, 0xd6, 0x6f, 0x1a, 0x8d, 0xc1, 0xd2, 0xf9, 0xf9, 0xf9, 0xb9, 0xe5, 0x9c, 0x69, 0x8e, 0x47,
-	0x5a, 0xe7, 0x20, 0xa5, 0xce, 0xc1, 0x50, 0x75, 0x3d, 0x7f, 0x90, 0x14, 0xa3, 0xec, 0xbb, 0xf3,
-	0x57, 0x98, 0x39, 0x4e, 0xa6, 0xcc, 0x67, 0x4e, 0xa2, 0x4d, 0x5a, 0xa8, 0x3d, 0xdb, 0x59, 0x4f,
-	0x88, 0x79, 0x05, 0xae, 0x98, 0xe6, 0xbc, 0xd2, 0x1c, 0xc3, 0x42, 0x6a, 0x5f, 0x81, 0xda, 0x83,
-	0x20, 0x3c, 0xe6, 0x99, 0xa1, 0xe1, 0xf2, 0x41, 0x89, 0xf2, 0xa7, 0xaa, 0xf2, 0xc2, 0xf2, 0x52,
-	0xf9, 0x97, 0xc8, 0x70, 0xda, 0xb5, 0xf9, 0x72, 0x07, 0x16, 0x8b, 0x25, 0x1a, 0x2a, 0xaf, 0xb7,
-	0xf2, 0x33, 0x3a, 0x5d, 0x23, 0xe8, 0x67, 0x6c, 0xad, 0x1b, 0xaa, 0xc7, 0x72, 0xa8, 0x24, 0xf0,
-	0xb1, 0x36, 0x15, 0xe9, 0x50, 0x77, 0xee, 0x19, 0x15, 0x9e, 0xa8, 0xe0, 0x35, 0xcb, 0x49, 0x75,
-	0xdf, 0xa2, 0xf2, 0x0c, 0x57, 0x9a, 0xda, 0xb5, 0x6e, 0xb3, 0xae, 0xe8, 0xb6, 0x47, 0x46, 0x2b,
-	0x86, 0xcc, 0x0a, 0x47, 0x75, 0x9b, 0x1e, 0xa4, 0x34, 0xe7, 0x23, 0x54, 0x96, 0x8e, 0x4b, 0x8d,
-	0x11, 0x1e, 0xb6, 0x14, 0x0f, 0xf7, 0x8c, 0xd8, 0xfe, 0xc1, 0xb0, 0xb5, 0xa4, 0x87, 0x2f, 0x42,
-	0xf6, 0x09, 0xba, 0xf8, 0x22, 0xb8, 0x32, 0xbe, 0x03, 0x23, 0xbe, 0x7f, 0x32, 0x7c, 0xb7, 0x93,
-	0x42, 0xe8, 0x02, 0xbd, 0x12, 0xe5, 0x0f, 0xa8, 0xfc, 0x22, 0xba, 0x2a, 0x42, 0x5a, 0x5a, 0xee,
-	0x93, 0x17, 0x8c, 0x9c, 0xb4, 0x50, 0xc9, 0x30, 0x53, 0x93, 0x57, 0x73, 0x7d, 0x82, 0x5a, 0x63,
-	0xd7, 0xb2, 0x75, 0x7f, 0x49, 0xbc, 0x8c, 0xd4, 0x78, 0x29, 0xb3, 0x42, 0xda, 0xfb, 0x05, 0x32,
-	0x5e, 0xab, 0xa5, 0xa6, 0xae, 0x41, 0x3d, 0xd3, 0xca, 0x25, 0x23, 0x5a, 0xec, 0xd0, 0xba, 0x39,
-	0x8a, 0xbd, 0xf1, 0x24, 0xa9, 0xa5, 0x25, 0xa1, 0xf3, 0xc0, 0x08, 0x7d, 0xcc, 0xa0, 0xdf, 0x54,
-	0x43, 0xbd, 0x00, 0x48, 0xa2, 0xfe, 0x0a, 0x19, 0xef, 0xfb, 0x37, 0x42, 0xed, 0xc0, 0x5c, 0xa6,
-	0x75, 0xe7, 0x4f, 0x0f, 0x19, 0x5a, 0x09, 0x76, 0x5f, 0xc5, 0x6e, 0x80, 0x25, 0xb1, 0x7f, 0x8e,
-	0xca, 0xcb, 0x91, 0x2b, 0x47, 0x58, 0x5a, 0x21, 0x57, 0x94, 0x0a, 0xb9, 0x24, 0x4a, 0x82, 0x62,
-	0x56, 0xd1, 0x23, 0x29, 0x66, 0x95, 0xb7, 0x83, 0xb8, 0x24, 0xab, 0x4c, 0xf2, 0x59, 0xe5, 0x22,
-	0x64, 0x1f, 0x20, 0x4d, 0x69, 0xf6, 0xf3, 0x5a, 0x82, 0x92, 0xcb, 0xf7, 0x5f, 0xc5, 0x9b, 0x5f,
-	0x51, 0x2b, 0x51, 0x91, 0x42, 0x61, 0xa8, 0xbd, 0xbf, 0xfe, 0x6c, 0x54, 0x14, 0x32, 0x45, 0xab,
-	0xd2, 0x0f, 0x5a, 0x35, 0x67, 0x9a, 0x52, 0xf3, 0xb2, 0xb6, 0x97, 0x58, 0x19, 0xa9, 0x56, 0x16,
-	0x14, 0x48, 0xf5, 0x9f, 0x21, 0x6d, 0x4d, 0x4b, 0xc3, 0x81, 0xca, 0xfb, 0x12, 0x45, 0x3a, 0xce,
-	0x84, 0x8a, 0x55, 0xd6, 0x28, 0x55, 0x72, 0x8d, 0x52, 0xc9, 0x65, 0x1f, 0xab, 0x97, 0xbd, 0x06,
-	0x90, 0x44, 0x1c, 0xe4, 0x6b, 0x6d, 0xbc, 0xc5, 0xdf, 0x28, 0x19, 0xce, 0xd9, 0x0e, 0xc8, 0x87,
-	0x42, 0x97, 0xd1, 0x3b, 0x7f, 0x32, 0x6a, 0x9d, 0x32, 0xad, 0x2b, 0xf2, 0x82, 0x91, 0xab, 0x4a,
-	0x85, 0x1f, 0x22, 0x73, 0x25, 0x5f, 0xea, 0xa7, 0x34, 0x32, 0x2d, 0x35, 0x32, 0x1f, 0x1a, 0xd1,
-	0x3c, 0x67, 0x68, 0xb6, 0x52, 0x34, 0x5a, 0x8d, 0x12, 0xd7, 0xa9, 0xa6, 0x85, 0xb8, 0xcc, 0x8b,
-	0x60, 0x49, 0xd4, 0xbc, 0x28, 0x46, 0x8d, 0xb6, 0x30, 0xfd, 0x11, 0x95, 0xf4, 0x29, 0xc6, 0xc7,
-	0x2b, 0x53, 0xcc, 0xb4, 0x8b, 0x15, 0x18, 0x4f, 0x83, 0x79, 0x72, 0xfa, 0xa2, 0x51, 0x2d, 0x79,
-	0xd1, 0xa8, 0x15, 0x5f, 0x34, 0x3a, 0xbb, 0x46, 0x8b, 0x4f, 0x99, 0xc5, 0xbf, 0xca, 0xdc, 0x59,
-	0x45, 0x93, 0xa4, 0xe5, 0x5f, 0x23, 0x63, 0x0b, 0xf6, 0xcb, 0xd9, 0x5d, 0x72, 0x6f, 0xfd, 0x3b,
-	0x73, 0x6f, 0xe9, 0x81, 0x65, 0x42, 0xa6, 0xd0, 0x22, 0xa6, 0x21, 0x83, 0x64, 0xc8, 0xdc, 0x1d,
-	0x0c, 0x42, 0x11, 0x32, 0xf4, 0xbb, 0x24, 0x64, 0x5e, 0xa9, 0x21, 0x53, 0x58, 0x5c, 0xaa, 0xfe,
-	0x14, 0x19, 0xfa, 0x50, 0xea, 0xa2, 0xdd, 0xa3, 0xa3, 0x43, 0xa6, 0x33, 0x39, 0x42, 0x62, 0x9c,
-	0x3c, 0x5e, 0x2b, 0x70, 0xc4, 0x30, 0x6d, 0xf7, 0x2a, 0x4a, 0xbb, 0x67, 0x6e, 0x5e, 0xfe, 0x53,
-	0x6c, 0x5e, 0x72, 0x30, 0x32, 0xd7, 0x91, 0xbe, 0x2d, 0x7e, 0x33, 0xa4, 0x25, 0xa8, 0xce, 0xf4,
-	0x2d, 0x95, 0x16, 0xd5, 0x6b, 0x64, 0xe8, 0xc8, 0xaf, 0xfe, 0x13, 0xc0, 0x52, 0x7e, 0x02, 0x94,
-	0xa0, 0xfb, 0xaf, 0x8a, 0x4e, 0xab, 0x5a, 0x6d, 0xf8, 0xf4, 0x6f, 0x02, 0x79, 0x70, 0x25, 0xea,
-	0xfe, 0xa7, 0xaa, 0xd3, 0x2e, 0x26, 0xd5, 0xf9, 0x86, 0x77, 0x86, 0x82, 0xba, 0xfb, 0x46, 0x75,
-	0xe7, 0xa8, 0xa8, 0xcf, 0x68, 0xde, 0x03, 0x5a, 0xca, 0x47, 0x93, 0xc0, 0x8f, 0x08, 0x55, 0x71,
-	0xf0, 0x88, 0xa9, 0x68, 0xb8, 0xd6, 0xc1, 0x23, 0x9a, 0xe5, 0xef, 0x87, 0x61, 0x10, 0xb2, 0x66,
-	0xbb, 0xe9, 0xf2, 0x81, 0xfc, 0x37, 0x56, 0x61, 0xe7, 0x8a, 0x0f, 0x9c, 0x8f, 0x91, 0xee, 0x15,
-	0xe4, 0x2d, 0x9e, 0x00, 0xf3, 0x05, 0xfb, 0x7f, 0x6e, 0xaf, 0x9d, 0xde, 0x2e, 0x46, 0xe7, 0x0e,
-	0x8a, 0x2f, 0x32, 0x05, 0xbf, 0x9a, 0xf3, 0xc1, 0x3b, 0x5c, 0xcf, 0x9a, 0x92, 0x91, 0x94, 0x85,
-	0x52, 0x2d, 0x3f, 0x05, 0x00, 0x00, 0xff, 0xff, 0xc7, 0x04, 0x86, 0xd9, 0x75, 0x1c, 0x00, 0x00,
+	// 2311 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x5a, 0x4b, 0x6f, 0x1c, 0x4b,
+	0xf5, 0x57, 0xf5, 0x3c, 0x3c, 0x73, 0xc6, 0xf1, 0xa3, 0xec, 0x38, 0x9d, 0x97, 0xff, 0xa3, 0x56,
+	0x74, 0xff, 0xc3, 0x55, 0x94, 0x1b, 0x0d, 0xe2, 0x6e, 0x78, 0x26, 0x9e, 0x3c, 0xac, 0xc8, 0x8e,
+	0x6f, 0x8d, 0xaf, 0x58, 0xf7, 0x9d, 0xae, 0xd8, 0x4d, 0x66, 0xba, 0xe7, 0x76, 0xf7, 0xc4, 0x36,
+	0x97, 0x80, 0xb9, 0x1b, 0x24, 0x56, 0x20, 0x84, 0x40, 0xba, 0x1b, 0x04, 0x0b, 0x96, 0x08, 0x21,
+	0x21, 0x45, 0xac, 0xd8, 0xf3, 0x05, 0xf8, 0x06, 0x6c, 0x58, 0xb3, 0x43, 0xa8, 0x5e, 0x5d, 0xd5,
+	0x4f, 0xc7, 0xe1, 0xb2, 0xeb, 0x3a, 0xe7, 0x54, 0x9d, 0xdf, 0x39, 0x75, 0x1e, 0x55, 0x35, 0x03,
+	0x1b, 0x7e, 0x90, 0xd0, 0x28, 0x70, 0xa7, 0x1f, 0xcc, 0x68, 0xe2, 0xde, 0x9b, 0x47, 0x61, 0x12,
+	0xe2, 0x26, 0xfb, 0x76, 0xde, 0x34, 0xa0, 0x39, 0x72, 0x13, 0x17, 0x63, 0x68, 0x1e, 0xd2, 0x68,
+	0x66, 0xa3, 0xbe, 0x35, 0x68, 0x12, 0xfe, 0x8d, 0x37, 0xa1, 0xb5, 0x1b, 0x78, 0xf4, 0xd4, 0xb6,
+	0x38, 0x51, 0x0c, 0xf0, 0x2d, 0xe8, 0xee, 0x4c, 0x17, 0x71, 0x42, 0xa3, 0xdd, 0x91, 0xdd, 0xe0,
+	0x1c, 0x4d, 0xc0, 0x77, 0xa0, 0xb5, 0x1f, 0x7a, 0x34, 0xb6, 0x9b, 0xfd, 0xc6, 0xa0, 0x37, 0x5c,
+	0xb9, 0xc7, 0x55, 0x32, 0xd2, 0x6e, 0xf0, 0x22, 0x24, 0x82, 0x89, 0xef, 0x43, 0x97, 0x69, 0xfd,
+	0xc4, 0x8d, 0x69, 0x6c, 0xb7, 0xb8, 0x24, 0x16, 0x92, 0x8a, 0xcc, 0xa5, 0xb5, 0x10, 0x5b, 0xf7,
+	0xe3, 0x98, 0x46, 0xb1, 0xdd, 0x36, 0xd7, 0x65, 0x24, 0xb1, 0x2e, 0x67, 0x32, 0x6c, 0x7b, 0xee,
+	0x29, 0xd7, 0x36, 0xb2, 0x97, 0x04, 0xb6, 0x94, 0x80, 0x07, 0xb0, 0xba, 0xe7, 0x9e, 0x8e, 0x8f,
+	0xdd, 0xc8, 0x7b, 0x12, 0x85, 0x8b, 0xf9, 0xee, 0xc8, 0xee, 0x70, 0x99, 0x3c, 0x19, 0x6f, 0x03,
+	0x28, 0xd2, 0xee, 0xc8, 0xee, 0x72, 0x21, 0x83, 0x82, 0xef, 0x0a, 0xfc, 0xc2, 0x52, 0x28, 0xb5,
+	0x54, 0x0b, 0x30, 0xe9, 0x3d, 0xaa, 0xa4, 0x7b, 0xe5, 0xd2, 0xa9, 0x00, 0xfe, 0x7f, 0x68, 0x1f,
+	0x86, 0x2f, 0x69, 0x10, 0xdb, 0xcb, 0x5c, 0x74, 0x55, 0x88, 0x72, 0x1a, 0x97, 0x95, 0x6c, 0xe7,
+	0x29, 0x74, 0xd4, 0x7c, 0xbc, 0x02, 0xd6, 0xee, 0x48, 0x6e, 0x9e, 0xb5, 0x3b, 0x62, 0xdb, 0xf9,
+	0x34, 0x8c, 0x13, 0xbe, 0x73, 0x5d, 0xc2, 0xbf, 0xb1, 0x0d, 0x4b, 0x87, 0x3b, 0x07, 0x9c, 0xdc,
+	0xe8, 0xa3, 0x41, 0x97, 0xa8, 0xa1, 0xf3, 0x6f, 0x0b, 0x96, 0x4d, 0xc7, 0xb3, 0xe9, 0xfb, 0xee,
+	0x8c, 0xf2, 0x05, 0xbb, 0x84, 0x7f, 0xe3, 0x0f, 0x61, 0x6b, 0x44, 0x5f, 0xb8, 0x8b, 0x69, 0x42,
+	0x68, 0x42, 0x83, 0xc4, 0x0f, 0x83, 0x83, 0x70, 0xea, 0x4f, 0xce, 0xa4, 0x92, 0x0a, 0x2e, 0x7e,
+	0x02, 0xeb, 0x59, 0x92, 0x4f, 0x63, 0xbb, 0xc1, 0x4d, 0xbb, 0x2e, 0x4c, 0xcb, 0xcd, 0xe0, 0x46,
+	0x16, 0xe7, 0xb0, 0x85, 0x76, 0xc2, 0x20, 0xf1, 0x83, 0x45, 0xb8, 0x88, 0x3f, 0x5a, 0xd0, 0xc8,
+	0x4f, 0xc3, 0x4c, 0x2e, 0x94, 0x65, 0xcb, 0x85, 0x0a, 0x73, 0xf0, 0x7d, 0x00, 0xce, 0xff, 0x68,
+	0x11, 0x26, 0xae, 0xdd, 0xea, 0xa3, 0x41, 0x6f, 0xb8, 0x26, 0x56, 0xd0, 0x74, 0x62, 0xc8, 0xe0,
+	0xbb, 0xd0, 0x1e, 0x4f, 0x8e, 0xe9, 0xcc, 0xb5, 0xdb, 0x5c, 0x7a, 0x33, 0x1b, 0xac, 0x82, 0x47,
+	0xa4, 0x0c, 0x5b, 0xff, 0xbb, 0x91, 0x9f, 0x50, 0xb1, 0xfe, 0x92, 0xb9, 0xbe, 0xa6, 0x13, 0x43,
+	0xc6, 0xf9, 0x39, 0x82, 0x8d, 0x9c, 0x17, 0xc6, 0x73, 0x3a, 0x31, 0xf6, 0x01, 0xa5, 0xfb, 0x70,
+	0x03, 0x3a, 0xa3, 0x45, 0xe4, 0x32, 0x49, 0xdb, 0xea, 0xa3, 0x41, 0x83, 0xa4, 0x63, 0x7c, 0x0f,
+	0xb0, 0x8e, 0xe3, 0x54, 0xaa, 0xc1, 0xa5, 0x4a, 0x38, 0x6c, 0x2d, 0x42, 0xe7, 0x53, 0x7f, 0xe2,
+	0xee, 0xdb, 0xcd, 0x3e, 0x1a, 0x5c, 0x21, 0xe9, 0xd8, 0xf9, 0x89, 0x55, 0xc0, 0x54, 0x19, 0x1b,
+	0x59, 0x4c, 0xd6, 0x5b, 0x61, 0xb2, 0xde, 0x0a, 0x93, 0x65, 0x62, 0xc2, 0x1f, 0x42, 0x4f, 0xcf,
+	0x50, 0x95, 0x43, 0x6e, 0x86, 0x91, 0xc0, 0x6c, 0xdf, 0x4d, 0x41, 0xfc, 0x0d, 0xb8, 0x32, 0x5e,
+	0x7c, 0x12, 0x4f, 0x22, 0x7f, 0xce, 0x74, 0xa8, 0x2a, 0xb2, 0x25, 0x67, 0x1a, 0x2c, 0x3e, 0x37,
+	0x2b, 0xec, 0xfc, 0x15, 0xc1, 0x4a, 0x76, 0xf5, 0x42, 0xbe, 0xdd, 0x82, 0xee, 0x38, 0x71, 0xa3,
+	0xe4, 0xd0, 0x9f, 0x51, 0xe9, 0x01, 0x4d, 0x60, 0x99, 0xf7, 0x28, 0xf0, 0x38, 0x4f, 0xd8, 0xad,
+	0x86, 0x6c, 0xde, 0x88, 0x4e, 0x69, 0x42, 0xbd, 0x07, 0x09, 0xb7, 0xb6, 0x41, 0x34, 0x81, 0x95,
+	0x02, 0xae, 0x57, 0x59, 0xba, 0x6a, 0x58, 0x2a, 0x4a, 0x81, 0x60, 0xe3, 0x3e, 0xf4, 0x0e, 0xa3,
+	0x45, 0x30, 0x71, 0xc5, 0x42, 0x6d, 0xbe, 0xe1, 0x26, 0xc9, 0xa1, 0xd0, 0x4d, 0xa7, 0x15, 0xd0,
+	0x6f, 0x43, 0xe7, 0xf9, 0x49, 0xc0, 0xea, 0x77, 0x6c, 0x5b, 0xfd, 0xc6, 0xa0, 0xf9, 0xd0, 0xb2,
+	0x11, 0x49, 0x69, 0x78, 0x00, 0x6d, 0xfe, 0xad, 0xf2, 0x76, 0xcd, 0xc0, 0xc1, 0x19, 0x44, 0xf2,
+	0x9d, 0x9f, 0x21, 0x58, 0xcb, 0xbb, 0xb3, 0x34, 0x62, 0x30, 0x34, 0xf7, 0x42, 0x8f, 0xaa, 0x02,
+	0xc5, 0xbe, 0xb1, 0x03, 0xcb, 0x23, 0x1a, 0x27, 0x7e, 0xe0, 0x8a, 0x4d, 0x62, 0xca, 0xba, 0x24,
+	0x43, 0xc3, 0xf7, 0xa1, 0xfd, 0xd8, 0x9f, 0x26, 0x34, 0xe2, 0xf1, 0xda, 0x1b, 0xda, 0xc5, 0x2d,
+	0x14, 0x7c, 0x22, 0xe5, 0x9c, 0xcf, 0x11, 0xe0, 0x22, 0x9b, 0x29, 0xdb, 0xa3, 0x6e, 0xbc, 0x88,
+	0xe8, 0x8c, 0x06, 0x49, 0x6c, 0x23, 0xa1, 0xcc, 0xa4, 0xe1, 0xf7, 0x61, 0xcd, 0x18, 0x13, 0x7a,
+	0xc4, 0x7b, 0x21, 0x4b, 0xc5, 0x02, 0x9d, 0xb7, 0xc5, 0x30, 0xf0, 0xfc, 0x34, 0xe3, 0xba, 0x44,
+	0x13, 0x9c, 0x3b, 0x00, 0xda, 0x5b, 0x78, 0x0b, 0xda, 0xb2, 0x47, 0x89, 0x3d, 0x90, 0x23, 0xe7,
+	0xdb, 0xb0, 0x51, 0x52, 0xc2, 0x4a, 0xfd, 0xb7, 0x09, 0x2d, 0x2e, 0x20, 0x1d, 0x28, 0x06, 0xce,
+	0x4f, 0x2d, 0xe8, 0xa8, 0x9e, 0x58, 0xe5, 0xf6, 0xa7, 0x6e, 0x7c, 0x9c, 0xf6, 0x05, 0x37, 0x3e,
+	0x66, 0x4b, 0x3d, 0xf0, 0x66, 0xbe, 0xc8, 0xc9, 0x0e, 0x11, 0x03, 0xfc, 0x55, 0x80, 0x83, 0xc8,
+	0x7f, 0xe5, 0x4f, 0xe9, 0x51, 0x5a, 0x66, 0x37, 0x74, 0xd7, 0x4d, 0x79, 0xc4, 0x10, 0x7b, 0x87,
+	0xca, 0xfa, 0x01, 0x00, 0xa1, 0xae, 0xf7, 0x24, 0x72, 0x83, 0x44, 0xa5, 0xe5, 0xaa, 0x6a, 0x0b,
+	0x92, 0x4e, 0x0c, 0x11, 0xd6, 0xc4, 0x0f, 0xdc, 0x38, 0x3e, 0x09, 0x23, 0x6f, 0xe7, 0xd8, 0x0d,
+	0x8e, 0xa8, 0xc7, 0x2b, 0x6c, 0x83, 0xe4, 0xc9, 0xce, 0x2e, 0x5c, 0xc9, 0x20, 0xe5, 0x55, 0x4a,
+	0x56, 0x6c, 0xe9, 0x94, 0x74, 0xcc, 0xb6, 0x2f, 0x15, 0xe4, 0xde, 0x69, 0x11, 0x4d, 0x70, 0xfe,
+	0x81, 0x4c, 0xc3, 0xf0, 0x10, 0x36, 0xf7, 0xdc, 0xd3, 0x9d, 0x30, 0x98, 0x2c, 0xa2, 0x88, 0x06,
+	0x89, 0x6a, 0x46, 0x88, 0x03, 0x29, 0xe5, 0xb1, 0x78, 0xe3, 0x2b, 0xb0, 0xb4, 0x0f, 0x17, 0x89,
+	0x2c, 0xdd, 0x19, 0x9a, 0x3a, 0xa0, 0xd0, 0x29, 0x9d, 0x24, 0x07, 0xa1, 0x1f, 0x24, 0xfb, 0xb2,
+	0x76, 0xe7, 0xc9, 0x3c, 0x32, 0x15, 0x69, 0xcc, 0x15, 0x88, 0x02, 0xde, 0x20, 0x05, 0x3a, 0xbe,
+	0x0b, 0xeb, 0x29, 0xed, 0xe1, 0x62, 0xf2, 0x92, 0x26, 0xf1, 0x3e, 0xdf, 0x9b, 0x06, 0x29, 0x32,
+	0x9c, 0xaf, 0x41, 0xef, 0xb1, 0x4f, 0xa7, 0x9e, 0xec, 0x65, 0x15, 0x41, 0x74, 0x78, 0x36, 0x4f,
+	0x73, 0x97, 0x7d, 0x3b, 0xbf, 0x46, 0xb0, 0x6e, 0xe4, 0x44, 0xcd, 0x6c, 0x07, 0x96, 0x09, 0xfd,
+	0x74, 0xe1, 0x47, 0xd4, 0x3b, 0x74, 0x8f, 0x44, 0xc1, 0xe9, 0x92, 0x0c, 0x8d, 0xd5, 0xb3, 0x07,
+	0xd3, 0x69, 0x78, 0x22, 0x45, 0x44, 0x21, 0x30, 0x49, 0xf8, 0x2b, 0xac, 0x0e, 0xd0, 0xa9, 0xa7,
+	0x42, 0x73, 0x5d, 0xc4, 0x8c, 0x01, 0x9d, 0x48, 0x01, 0xc7, 0x85, 0x95, 0x6c, 0xa3, 0x4e, 0x8b,
+	0x0f, 0x32, 0x8a, 0xcf, 0xd7, 0x73, 0xf5, 0xc0, 0xe2, 0xcb, 0x5e, 0x13, 0xcb, 0x16, 0x2c, 0xcb,
+	0x16, 0x0a, 0xe7, 0x08, 0xba, 0x69, 0x88, 0xd6, 0x86, 0x59, 0x1f, 0x7a, 0xc6, 0x44, 0x59, 0x4c,
+	0x4c, 0xd2, 0x05, 0x75, 0xe4, 0x0d, 0x82, 0x6e, 0x7a, 0x12, 0x34, 0xea, 0x78, 0x57, 0x9d, 0xfa,
+	0x58, 0xc4, 0xab, 0x8d, 0x61, 0xdf, 0x69, 0xc6, 0x37, 0x8c, 0x8c, 0xdf, 0x62, 0xc7, 0x99, 0x70,
+	0x2e, 0xf3, 0xba, 0x4b, 0xe4, 0x88, 0xa1, 0x1b, 0xd1, 0xb4, 0x50, 0xf2, 0x18, 0xe9, 0x12, 0x93,
+	0xc4, 0xd1, 0x45, 0x34, 0xd3, 0x66, 0x34, 0x81, 0x71, 0x1f, 0x9d, 0xce, 0xfd, 0x88, 0xc6, 0x0f,
+	0x12, 0x99, 0x95, 0x9a, 0xe0, 0xfc, 0x06, 0x99, 0xe7, 0x22, 0x79, 0x56, 0x1f, 0x9b, 0x99, 0xa3,
+	0x09, 0xbc, 0x3c, 0xbb, 0xa7, 0x23, 0x3f, 0x7e, 0xf9, 0xf0, 0x2c, 0xa1, 0xb1, 0x4a, 0x17, 0x93,
+	0xc6, 0x4e, 0x16, 0x7b, 0xee, 0x29, 0xcf, 0x88, 0xf8, 0x80, 0x46, 0x63, 0x3a, 0x09, 0x03, 0x4f,
+	0x9d, 0x76, 0x8a, 0x1c, 0x66, 0x9e, 0x20, 0x3d, 0x5c, 0x44, 0x71, 0x22, 0xf3, 0xc5, 0x24, 0x39,
+	0x7f, 0x6f, 0xc3, 0xd2, 0x4e, 0x38, 0x9b, 0xb9, 0x81, 0x87, 0xdf, 0x83, 0x66, 0xc2, 0xa2, 0x9c,
+	0xb9, 0x77, 0x45, 0x5d, 0x4f, 0x24, 0xf3, 0x1e, 0x8b, 0x79, 0xc2, 0xf9, 0xce, 0x17, 0x6d, 0x91,
+	0x0e, 0xf8, 0x2a, 0xac, 0x0b, 0x57, 0xb0, 0x6a, 0x2e, 0x05, 0xd7, 0x10, 0x23, 0x8b, 0x8e, 0x6e,
+	0x92, 0x2d, 0x7c, 0x1d, 0xae, 0x0a, 0x69, 0x15, 0x1b, 0x8a, 0xd5, 0xc0, 0xd7, 0x60, 0x63, 0x14,
+	0x85, 0xf3, 0x3c, 0xa3, 0x89, 0xfb, 0x70, 0x4b, 0xcc, 0xc9, 0x9d, 0xcb, 0x94, 0x44, 0x0b, 0x6f,
+	0xc3, 0x0d, 0x36, 0xb5, 0x82, 0xdf, 0xc6, 0x77, 0xa0, 0x3f, 0xa6, 0x49, 0xf9, 0x49, 0x5d, 0x49,
+	0x2d, 0x31, 0x3d, 0x1f, 0xcf, 0xbd, 0x6a, 0x3d, 0x1d, 0x7c, 0x13, 0xae, 0x09, 0x24, 0xfa, 0x5c,
+	0xa4, 0x98, 0x5d, 0xc6, 0x14, 0x16, 0x17, 0x99, 0xa0, 0x6d, 0xc8, 0x75, 0x3a, 0x25, 0xd1, 0x53,
+	0x36, 0x54, 0xf0, 0x97, 0xb5, 0x9f, 0x59, 0x7c, 0x2b, 0xf2, 0x15, 0xbc, 0x01, 0xab, 0x6c, 0x9a,
+	0x49, 0x5c, 0x61, 0xb2, 0xc2, 0x12, 0x93, 0xbc, 0xca, 0x3c, 0x3c, 0xa6, 0x49, 0x5a, 0xdf, 0x15,
+	0x63, 0x0d, 0x63, 0x58, 0x61, 0xfe, 0x71, 0x13, 0x57, 0xd1, 0xd6, 0xf1, 0x2d, 0xb0, 0xc7, 0x34,
+	0xe1, 0x5d, 0xb1, 0x30, 0x03, 0x6b, 0x0d, 0xe6, 0xf6, 0x6e, 0xe0, 0xdb, 0x70, 0x5d, 0x3a, 0xc8,
+	0x38, 0x7a, 0x28, 0xf6, 0x55, 0xee, 0xa2, 0x28, 0x9c, 0x97, 0x31, 0xb7, 0xd8, 0x92, 0x84, 0xce,
+	0xc2, 0x57, 0xf4, 0x80, 0x6a, 0xd0, 0xd7, 0x74, 0xc4, 0xa8, 0xbb, 0xa2, 0x62, 0xd9, 0xd9, 0x60,
+	0x32, 0x59, 0xd7, 0x19, 0x4b, 0xe0, 0xcb, 0xb3, 0x6e, 0x30, 0x96, 0xd8, 0xa7, 0xfc, 0x82, 0x37,
+	0x35, 0x2b, 0x3f, 0xeb, 0x16, 0xde, 0x02, 0x3c, 0xa6, 0x49, 0x7e, 0xca, 0x6d, 0xbc, 0x09, 0x6b,
+	0xdc, 0x24, 0xb6, 0xe7, 0x8a, 0xba, 0xfd, 0x7e, 0xa7, 0xe3, 0xad, 0x9d, 0x9f, 0x9f, 0x9f, 0x5b,
+	0xce, 0xeb, 0x92, 0xf4, 0x48, 0xef, 0xa9, 0xc8, 0xb8, 0xa7, 0x62, 0x68, 0x12, 0x37, 0xf0, 0xe4,
+	0xab, 0x03, 0xff, 0x1e, 0x7e, 0x07, 0x96, 0x26, 0x72, 0xca, 0x95, 0x4c, 0x26, 0xda, 0xb4, 0x8f,
+	0x74, 0x9d, 0x2e, 0x28, 0x20, 0x6a, 0x9a, 0xf3, 0x59, 0x49, 0x1a, 0x16, 0x0e, 0xc2, 0x9b, 0xd0,
+	0x7a, 0x1c, 0x46, 0x13, 0xd1, 0xda, 0x3a, 0x44, 0x0c, 0x6a, 0x94, 0xbf, 0x30, 0x95, 0x17, 0x96,
+	0xd7, 0xca, 0xff, 0x8c, 0x2a, 0xb2, 0xbd, 0xb4, 0x43, 0xee, 0xc0, 0x6a, 0xf1, 0x8a, 0x8d, 0xea,
+	0xef, 0xcb, 0xf9, 0x19, 0xc3, 0x51, 0x25, 0xe8, 0x23, 0xbe, 0xd6, 0x4d, 0xd3, 0x63, 0x39, 0x54,
+	0x1a, 0xf8, 0xac, 0xb4, 0x14, 0x95, 0xa1, 0x1e, 0x3e, 0xac, 0x54, 0x78, 0x6c, 0x82, 0x2f, 0x59,
+	0x4e, 0xab, 0xfb, 0x1b, 0xaa, 0xaf, 0x70, 0xb5, 0xbd, 0xb5, 0xd4, 0x6d, 0xd6, 0x25, 0xdd, 0xf6,
+	0xac, 0xd2, 0x0a, 0x9f, 0x5b, 0xe1, 0x98, 0x6e, 0x2b, 0x07, 0xa9, 0xcd, 0xf9, 0x15, 0xaa, 0x2b,
+	0xc7, 0xb5, 0xc6, 0x28, 0x0f, 0x5b, 0x86, 0x87, 0x77, 0x2b, 0xb1, 0x7d, 0x8f, 0x63, 0xeb, 0x6b,
+	0x0f, 0x5f, 0x84, 0xec, 0x77, 0xe8, 0xe2, 0x46, 0x70, 0x69, 0x7c, 0xcf, 0x2b, 0xf1, 0xbd, 0xe4,
+	0xf8, 0xde, 0x13, 0xc4, 0x8b, 0xf4, 0x6a, 0x94, 0xff, 0x44, 0xf5, 0x8d, 0xe8, 0xb2, 0x08, 0xd9,
+	0x45, 0x7c, 0x9f, 0x9e, 0x70, 0xb2, 0x7c, 0x02, 0x93, 0xc3, 0xcc, 0x0b, 0x46, 0x33, 0xf7, 0xaa,
+	0x62, 0xbe, 0x48, 0xb4, 0xb2, 0xaf, 0x24, 0x35, 0xf1, 0x32, 0x35, 0xe3, 0xa5, 0xce, 0x0a, 0x6d,
+	0xef, 0x9f, 0x50, 0x65, 0x5b, 0xad, 0x35, 0x75, 0x0b, 0xda, 0x99, 0xa7, 0x38, 0x39, 0x62, 0x47,
+	0x2c, 0x76, 0xb5, 0x88, 0x13, 0x77, 0x36, 0x97, 0x2f, 0x0f, 0x9a, 0x30, 0x7c, 0x5c, 0x09, 0x7d,
+	0xc6, 0xa1, 0xdf, 0x36, 0x43, 0xbd, 0x00, 0x48, 0xa3, 0x7e, 0x83, 0x2a, 0xfb, 0xfd, 0x3b, 0xa1,
+	0x76, 0x60, 0x39, 0xf3, 0x46, 0x2b, 0xde, 0x98, 0x33, 0xb4, 0x1a, 0xec, 0x81, 0x89, 0xbd, 0x02,
+	0x96, 0xc6, 0xfe, 0x47, 0x54, 0x7f, 0x1c, 0xb9, 0x74, 0x84, 0xa5, 0xf7, 0xf2, 0x86, 0x71, 0x2f,
+	0xaf, 0x89, 0x92, 0xb0, 0x58, 0x55, 0xca, 0x91, 0x14, 0xab, 0xca, 0x97, 0x83, 0xb8, 0xa6, 0xaa,
+	0xcc, 0xf3, 0x55, 0xe5, 0x22, 0x64, 0xbf, 0x40, 0x25, 0x47, 0xb3, 0xff, 0xee, 0x1d, 0xa2, 0xa6,
+	0xf9, 0x7e, 0x5a, 0xec, 0xfc, 0x86, 0x5a, 0x8d, 0x8a, 0x16, 0x0e, 0x86, 0xa5, 0xfd, 0xeb, 0x5b,
+	0x95, 0x8a, 0x22, 0xae, 0xe8, 0xaa, 0xf6, 0x43, 0xa9, 0x9a, 0xd7, 0x25, 0x47, 0xcd, 0xb7, 0xb5,
+	0xbd, 0xc6, 0xca, 0xd8, 0xb4, 0xb2, 0xa0, 0x40, 0xab, 0xff, 0x03, 0x2a, 0x3d, 0xd3, 0xb2, 0x70,
+	0x60, 0xf2, 0x81, 0x46, 0x91, 0x8e, 0x33, 0xa1, 0x62, 0xd5, 0x3d, 0x88, 0x34, 0x72, 0x0f, 0x22,
+	0x35, 0xcd, 0x3e, 0x31, 0x9b, 0x7d, 0x09, 0x20, 0x8d, 0x38, 0xcc, 0x9f, 0xb5, 0xf1, 0xb6, 0xf8,
+	0x31, 0x8a, 0xe3, 0xec, 0x0d, 0x41, 0x3f, 0xb2, 0x13, 0x4e, 0x1f, 0x7e, 0xb3, 0x52, 0xeb, 0xc2,
+	0x7c, 0x96, 0xcf, 0xae, 0xaa, 0x15, 0xfe, 0x12, 0x55, 0x9f, 0xe4, 0x6b, 0xfd, 0x94, 0x46, 0xa6,
+	0x65, 0x46, 0xe6, 0x93, 0x4a, 0x34, 0xaf, 0x38, 0x9a, 0xed, 0x14, 0x4d, 0xa9, 0x46, 0x8d, 0xeb,
+	0xac, 0xe4, 0x0a, 0xf1, 0x36, 0xbf, 0xe8, 0xd4, 0x44, 0xcd, 0x49, 0x31, 0x6a, 0x4a, 0x0f, 0xa6,
+	0xff, 0x42, 0x35, 0xf7, 0x94, 0xca, 0xa7, 0xfe, 0xaa, 0x98, 0x19, 0x14, 0x4f, 0x60, 0xa2, 0x0c,
+	0xe6, 0xc9, 0xe9, 0x0b, 0x4c, 0xb3, 0xe6, 0xf9, 0xb7, 0x55, 0x7c, 0xfe, 0x1d, 0x3e, 0xad, 0xb4,
+	0xf8, 0x8c, 0x5b, 0xfc, 0x7f, 0x99, 0x9e, 0x55, 0x34, 0x49, 0x5b, 0xfe, 0x17, 0x54, 0x79, 0x05,
+	0xfb, 0xdf, 0xd9, 0x5d, 0xd3, 0xb7, 0xbe, 0x9f, 0xe9, 0x5b, 0xe5, 0xc0, 0x32, 0x21, 0x53, 0xb8,
+	0x22, 0xa6, 0x21, 0x83, 0x74, 0xc8, 0x3c, 0xf0, 0xbc, 0xf4, 0x39, 0x88, 0x7d, 0xd7, 0x84, 0xcc,
+	0x67, 0x66, 0xc8, 0x14, 0x16, 0xd7, 0xaa, 0x7f, 0x8f, 0x2a, 0xee, 0xa1, 0xcc, 0x45, 0x4f, 0x0f,
+	0x0f, 0x0f, 0xb8, 0x4e, 0x99, 0x42, 0x6a, 0x2c, 0x7f, 0x7c, 0x34, 0xe0, 0xa8, 0x61, 0x7a, 0xdd,
+	0x6b, 0x18, 0xd7, 0xbd, 0xea, 0xcb, 0xcb, 0x0f, 0x8a, 0x97, 0x97, 0x1c, 0x8c, 0x4c, 0x3b, 0x2a,
+	0xbf, 0x16, 0xbf, 0x1b, 0xd2, 0x1a, 0x54, 0xaf, 0xcb, 0xaf, 0x54, 0xa5, 0xa8, 0xbe, 0x40, 0x15,
+	0x37, 0xf2, 0xcb, 0xff, 0x88, 0x6b, 0x19, 0x3f, 0xe2, 0xd6, 0xa0, 0xfb, 0xa1, 0x89, 0xae, 0x54,
+	0xb5, 0x79, 0xe1, 0x2b, 0x7f, 0x13, 0xc8, 0x83, 0xab, 0x51, 0xf7, 0x23, 0x53, 0x5d, 0xe9, 0x62,
+	0x5a, 0x5d, 0x50, 0xf1, 0xce, 0x50, 0x50, 0xf7, 0xa8, 0x52, 0xdd, 0x39, 0x2a, 0xea, 0xab, 0x34,
+	0xef, 0x31, 0x3b, 0xca, 0xc7, 0xf3, 0x30, 0x88, 0x29, 0x53, 0xf1, 0xfc, 0x19, 0x57, 0xd1, 0x21,
+	0xd6, 0xf3, 0x67, 0xac, 0xca, 0x3f, 0x8a, 0xa2, 0x30, 0x92, 0xaf, 0xb2, 0x62, 0xa0, 0xff, 0x04,
+	0xd1, 0xe0, 0x79, 0x25, 0x06, 0xce, 0x6f, 0x51, 0xd9, 0x2b, 0xc8, 0x97, 0x98, 0x01, 0xd5, 0x0d,
+	0xf6, 0xc7, 0x28, 0xf3, 0xc3, 0x57, 0x01, 0x84, 0x36, 0xd6, 0x2b, 0xbe, 0xc8, 0x14, 0xfc, 0x5a,
+	0x5d, 0x0f, 0x3e, 0x17, 0x7a, 0xb6, 0x8c, 0x8a, 0x64, 0x2c, 0x94, 0x6a, 0xf9, 0xcf, 0x00, 0x12,
+	0xe3, 0xdc, 0x44, 0x5e, 0x22, 0x00, 0x00,
 }
@@ -0,0 +1,176 @@
+package meta
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PasswordPolicy is the policy new passwords must satisfy.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters of a password.
+	MinLength int
+
+	// MinCharClasses is the minimum number of character classes, out of
+	// lowercase and uppercase letters, digits and other characters, of a
+	// password.
+	MinCharClasses int
+}
+
+// Validate returns an error if the password does not satisfy the policy.
+func (p PasswordPolicy) Validate(password string) error {
+	if n := utf8.RuneCountInString(password); n < p.MinLength {
+		return fmt.Errorf("password must be at least %d characters", p.MinLength)
+	}
+
+	if p.MinCharClasses > 0 {
+		var lower, upper, digit, other int
+		for _, r := range password {
+			switch {
+			case unicode.IsLower(r):
+				lower = 1
+			case unicode.IsUpper(r):
+				upper = 1
+			case unicode.IsDigit(r):
+				digit = 1
+			default:
+				other = 1
+			}
+		}
+		if lower+upper+digit+other < p.MinCharClasses {
+			return fmt.Errorf("password must contain at least %d of lowercase letters, uppercase letters, digits and other characters", p.MinCharClasses)
+		}
+	}
+	return nil
+}
+
+// maxLoginLimiterKeys is the number of keys of a LoginLimiter above which
+// the keys whose failures expired are removed, and then the keys whose
+// failures are the oldest if too few expired.
+const maxLoginLimiterKeys = 10000
+
+// LoginLimiter locks out keys, such as usernames or client addresses, after
+// a number of consecutive failed logins. Each further failed login doubles
+// the duration of the lockout. The failures of a key are forgotten when no
+// login failed for the lockout duration after the last failure or lockout.
+//
+// A nil LoginLimiter never locks keys out.
+type LoginLimiter struct {
+	maxFailures int
+	duration    time.Duration
+
+	mu       sync.Mutex
+	failures map[string]*loginFailures
+}
+
+type loginFailures struct {
+	n     int
+	last  time.Time
+	until time.Time
+}
+
+// latest returns the time of the last failure or the end of the lockout,
+// whichever is later.
+func (f *loginFailures) latest() time.Time {
+	if f.until.After(f.last) {
+		return f.until
+	}
+	return f.last
+}
+
+// expired returns true if the failures are forgotten at the given time.
+func (f *loginFailures) expired(now time.Time, d time.Duration) bool {
+	return now.Sub(f.latest()) > d
+}
+
+// NewLoginLimiter returns a LoginLimiter locking keys out for d after
+// maxFailures consecutive failed logins.
+func NewLoginLimiter(maxFailures int, d time.Duration) *LoginLimiter {
+	return &LoginLimiter{
+		maxFailures: maxFailures,
+		duration:    d,
+		failures:    make(map[string]*loginFailures),
+	}
+}
+
+// Locked returns true if the key is locked out at the given time.
+func (l *LoginLimiter) Locked(key string, now time.Time) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f := l.failures[key]
+	return f != nil && now.Before(f.until)
+}
+
+// Fail records a failed login of the key and returns true if the key is now
+// locked out.
+func (l *LoginLimiter) Fail(key string, now time.Time) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f := l.failures[key]
+	if f == nil || f.expired(now, l.duration) {
+		if len(l.failures) >= maxLoginLimiterKeys {
+			l.prune(now)
+		}
+		f = &loginFailures{}
+		l.failures[key] = f
+	}
+	f.n++
+	f.last = now
+
+	if f.n >= l.maxFailures {
+		shift := f.n - l.maxFailures
+		if shift > 10 {
+			shift = 10
+		}
+		f.until = now.Add(l.duration << uint(shift))
+		return true
+	}
+	return false
+}
+
+// Reset forgets the failed logins of the key, after a successful login.
+func (l *LoginLimiter) Reset(key string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	delete(l.failures, key)
+	l.mu.Unlock()
+}
+
+// prune removes the keys whose failures expired. If less than a quarter of
+// the keys are removed, the keys whose last failure or lockout is the oldest
+// are removed too, so that the limiter does not grow without bound with keys
+// such as client addresses that keep changing.
+func (l *LoginLimiter) prune(now time.Time) {
+	for key, f := range l.failures {
+		if f.expired(now, l.duration) {
+			delete(l.failures, key)
+		}
+	}
+
+	n := len(l.failures) - maxLoginLimiterKeys*3/4
+	if n <= 0 {
+		return
+	}
+	keys := make([]string, 0, len(l.failures))
+	for key := range l.failures {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return l.failures[keys[i]].latest().Before(l.failures[keys[j]].latest())
+	})
+	for _, key := range keys[:n] {
+		delete(l.failures, key)
+	}
+}
@@ -0,0 +1,113 @@
+package meta_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	p := meta.PasswordPolicy{MinLength: 8, MinCharClasses: 3}
+	for _, tt := range []struct {
+		password string
+		valid    bool
+	}{
+		{password: "Ab1", valid: false},
+		{password: "abcdefgh", valid: false},
+		{password: "abcdEFGH", valid: false},
+		{password: "abcdEF12", valid: true},
+		{password: "abcd-12!", valid: true},
+		{password: "pässwört-1", valid: true},
+	} {
+		if err := p.Validate(tt.password); (err == nil) != tt.valid {
+			t.Errorf("%q: unexpected error: %v", tt.password, err)
+		}
+	}
+
+	if err := (meta.PasswordPolicy{}).Validate(""); err != nil {
+		t.Fatalf("unexpected error with empty policy: %v", err)
+	}
+}
+
+func TestLoginLimiter(t *testing.T) {
+	l := meta.NewLoginLimiter(3, time.Minute)
+	now := time.Unix(0, 0)
+
+	// The key is locked out after the third consecutive failure.
+	for i := 0; i < 2; i++ {
+		if l.Fail("bob", now) {
+			t.Fatalf("unexpected lockout after %d failures", i+1)
+		}
+	}
+	if !l.Fail("bob", now) {
+		t.Fatal("expected lockout")
+	} else if !l.Locked("bob", now.Add(59*time.Second)) {
+		t.Fatal("expected key to be locked")
+	} else if l.Locked("bob", now.Add(time.Minute)) {
+		t.Fatal("expected lockout to end")
+	} else if l.Locked("alice", now) {
+		t.Fatal("unexpected lockout of other key")
+	}
+
+	// A further failure doubles the lockout.
+	now = now.Add(time.Minute)
+	if !l.Fail("bob", now) {
+		t.Fatal("expected lockout")
+	} else if !l.Locked("bob", now.Add(119*time.Second)) {
+		t.Fatal("expected key to be locked")
+	} else if l.Locked("bob", now.Add(2*time.Minute)) {
+		t.Fatal("expected lockout to end")
+	}
+
+	// Failures are forgotten after the lockout duration without failures.
+	now = now.Add(2*time.Minute + time.Minute + time.Second)
+	if l.Fail("bob", now) {
+		t.Fatal("unexpected lockout after failures expired")
+	}
+
+	// A successful login resets the failures.
+	l.Fail("bob", now)
+	l.Reset("bob")
+	if l.Fail("bob", now) {
+		t.Fatal("unexpected lockout after reset")
+	}
+
+	// A nil limiter never locks out.
+	var nilLimiter *meta.LoginLimiter
+	if nilLimiter.Fail("bob", now) || nilLimiter.Locked("bob", now) {
+		t.Fatal("unexpected lockout by nil limiter")
+	}
+}
+
+// Ensure the limiter forgets the oldest failures once too many keys failed,
+// while keeping the keys locked out.
+func TestLoginLimiter_Evict(t *testing.T) {
+	l := meta.NewLoginLimiter(2, time.Hour)
+	now := time.Unix(0, 0)
+
+	l.Fail("bob", now)
+	if !l.Fail("bob", now) {
+		t.Fatal("expected lockout")
+	}
+
+	// Fail once for more distinct keys than the limiter keeps, none of
+	// which expire.
+	var last string
+	for i := 0; i < 20000; i++ {
+		last = fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+		if l.Fail(last, now.Add(time.Duration(i)*time.Millisecond)) {
+			t.Fatalf("unexpected lockout of %s", last)
+		}
+	}
+
+	now = now.Add(time.Minute)
+	if !l.Locked("bob", now) {
+		t.Fatal("expected key to remain locked")
+	} else if l.Fail("10.0.0.0", now) {
+		t.Fatal("expected oldest failure to be forgotten")
+	} else if !l.Fail(last, now) {
+		t.Fatal("expected latest failure to be kept")
+	}
+}
@@ -98,6 +98,11 @@ func (a *QueryAuthorizer) AuthorizeQuery(u User, q *influxql.Query, database str
 				}
 			}
 		}
+
+		// Limit the series read by the query to the user's read grants.
+		if !user.IsOpen() {
+			return user, nil
+		}
 		return query.OpenAuthorizer, nil
 	default:
 	}
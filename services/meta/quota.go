@@ -0,0 +1,88 @@
+package meta
+
+import (
+	"errors"
+
+	"github.com/gogo/protobuf/proto"
+	internal "github.com/influxdata/influxdb/services/meta/internal"
+)
+
+// WriteQuota limits the series, disk usage and write rate of a database. A
+// limit of 0 is unlimited.
+type WriteQuota struct {
+	// MaxSeries is the maximum number of series of the database. Points of
+	// new series are dropped once it is reached.
+	MaxSeries int64
+
+	// MaxDiskBytes is the maximum size on disk of the shards of the database.
+	// Writes are rejected once it is reached.
+	MaxDiskBytes int64
+
+	// MaxPointsPerSecond is the rate at which points may be written to the
+	// database, and PointsBurst the number of points that may be written at
+	// once above that rate. Larger batches are rejected.
+	MaxPointsPerSecond int64
+	PointsBurst        int64
+}
+
+// IsZero returns true if the quota does not set any limit.
+func (q *WriteQuota) IsZero() bool {
+	return q == nil || *q == WriteQuota{}
+}
+
+// Burst returns the number of points that may be written at once above the
+// rate limit. It defaults to the points written in one second.
+func (q *WriteQuota) Burst() int64 {
+	if q.PointsBurst > 0 {
+		return q.PointsBurst
+	}
+	return q.MaxPointsPerSecond
+}
+
+// Validate returns an error if the quota is invalid.
+func (q *WriteQuota) Validate() error {
+	if q == nil {
+		return nil
+	}
+	if q.MaxSeries < 0 || q.MaxDiskBytes < 0 || q.MaxPointsPerSecond < 0 || q.PointsBurst < 0 {
+		return errors.New("write quota limits must not be negative")
+	} else if q.PointsBurst > 0 && q.MaxPointsPerSecond == 0 {
+		return errors.New("points burst requires a maximum points per second")
+	}
+	return nil
+}
+
+// clone returns a copy of q, or nil if q does not set any limit.
+func (q *WriteQuota) clone() *WriteQuota {
+	if q.IsZero() {
+		return nil
+	}
+	other := *q
+	return &other
+}
+
+// marshalWriteQuota serializes a write quota to a protobuf representation.
+func marshalWriteQuota(q *WriteQuota) *internal.WriteQuota {
+	if q.IsZero() {
+		return nil
+	}
+	return &internal.WriteQuota{
+		MaxSeries:          proto.Int64(q.MaxSeries),
+		MaxDiskBytes:       proto.Int64(q.MaxDiskBytes),
+		MaxPointsPerSecond: proto.Int64(q.MaxPointsPerSecond),
+		PointsBurst:        proto.Int64(q.PointsBurst),
+	}
+}
+
+// unmarshalWriteQuota deserializes a write quota from a protobuf representation.
+func unmarshalWriteQuota(pb *internal.WriteQuota) *WriteQuota {
+	if pb == nil {
+		return nil
+	}
+	return &WriteQuota{
+		MaxSeries:          pb.GetMaxSeries(),
+		MaxDiskBytes:       pb.GetMaxDiskBytes(),
+		MaxPointsPerSecond: pb.GetMaxPointsPerSecond(),
+		PointsBurst:        pb.GetPointsBurst(),
+	}
+}
@@ -0,0 +1,272 @@
+package meta
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/influxdata/influxdb/models"
+	internal "github.com/influxdata/influxdb/services/meta/internal"
+	"github.com/influxdata/influxql"
+)
+
+const (
+	// SchemaModeStrict drops the points that do not match the schema.
+	SchemaModeStrict = "strict"
+
+	// SchemaModeWarn writes the points that do not match the schema and
+	// logs a warning.
+	SchemaModeWarn = "warn"
+
+	// SchemaModeOff disables the schema without removing it.
+	SchemaModeOff = "off"
+)
+
+// DatabaseSchema declares the measurements, tags and fields that may be
+// written to a database.
+type DatabaseSchema struct {
+	Mode         string
+	Measurements []MeasurementSchema
+}
+
+// MeasurementSchema declares the tags and fields of a measurement.
+type MeasurementSchema struct {
+	Name string
+
+	// RequiredTags are the tag keys every point must have.
+	RequiredTags []string
+
+	// AllowedTags are the optional tag keys of the points. Any tag key is
+	// allowed if both AllowedTags and RequiredTags are empty.
+	AllowedTags []string
+
+	// Fields are the fields of the points and their types. Any field is
+	// allowed if Fields is empty.
+	Fields []FieldSchema
+}
+
+// FieldSchema declares the type of a field.
+type FieldSchema struct {
+	Name string
+	Type influxql.DataType
+}
+
+// Validate returns an error if the schema is invalid.
+func (s *DatabaseSchema) Validate() error {
+	switch s.Mode {
+	case SchemaModeStrict, SchemaModeWarn, SchemaModeOff:
+	default:
+		return fmt.Errorf("invalid schema mode %q: must be one of strict, warn or off", s.Mode)
+	}
+	if len(s.Measurements) == 0 {
+		return errors.New("schema must declare at least one measurement")
+	}
+
+	measurements := make(map[string]struct{}, len(s.Measurements))
+	for _, m := range s.Measurements {
+		if m.Name == "" {
+			return errors.New("measurement name is required")
+		} else if _, ok := measurements[m.Name]; ok {
+			return fmt.Errorf("measurement %q is declared more than once", m.Name)
+		}
+		measurements[m.Name] = struct{}{}
+
+		tags := make(map[string]struct{}, len(m.RequiredTags)+len(m.AllowedTags))
+		for _, keys := range [][]string{m.RequiredTags, m.AllowedTags} {
+			for _, k := range keys {
+				if k == "" || k == "time" {
+					return fmt.Errorf("invalid tag key %q on measurement %q", k, m.Name)
+				} else if _, ok := tags[k]; ok {
+					return fmt.Errorf("tag key %q is declared more than once on measurement %q", k, m.Name)
+				}
+				tags[k] = struct{}{}
+			}
+		}
+
+		fields := make(map[string]struct{}, len(m.Fields))
+		for _, f := range m.Fields {
+			if f.Name == "" || f.Name == "time" {
+				return fmt.Errorf("invalid field name %q on measurement %q", f.Name, m.Name)
+			} else if _, ok := fields[f.Name]; ok {
+				return fmt.Errorf("field %q is declared more than once on measurement %q", f.Name, m.Name)
+			}
+			fields[f.Name] = struct{}{}
+
+			switch f.Type {
+			case influxql.Float, influxql.Integer, influxql.Unsigned, influxql.String, influxql.Boolean:
+			default:
+				return fmt.Errorf("invalid type of field %q on measurement %q", f.Name, m.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Strict returns true if the points that do not match the schema are dropped.
+func (s *DatabaseSchema) Strict() bool {
+	return s.Mode == SchemaModeStrict
+}
+
+// Measurement returns the schema of a measurement by name.
+func (s *DatabaseSchema) Measurement(name string) *MeasurementSchema {
+	for i := range s.Measurements {
+		if s.Measurements[i].Name == name {
+			return &s.Measurements[i]
+		}
+	}
+	return nil
+}
+
+// ValidatePoint returns the reason the point does not match the schema, or
+// an empty string if it does.
+func (s *DatabaseSchema) ValidatePoint(p models.Point) string {
+	name := p.Name()
+	m := s.Measurement(string(name))
+	if m == nil {
+		return fmt.Sprintf("measurement \"%s\" is not allowed", name)
+	}
+
+	tags := p.Tags()
+	for _, k := range m.RequiredTags {
+		if tags.Get([]byte(k)) == nil {
+			return fmt.Sprintf("missing required tag \"%s\" on measurement \"%s\"", k, name)
+		}
+	}
+	if len(m.RequiredTags) > 0 || len(m.AllowedTags) > 0 {
+		for _, t := range tags {
+			if !m.hasTag(string(t.Key)) {
+				return fmt.Sprintf("tag \"%s\" is not allowed on measurement \"%s\"", t.Key, name)
+			}
+		}
+	}
+
+	if len(m.Fields) == 0 {
+		return ""
+	}
+	iter := p.FieldIterator()
+	for iter.Next() {
+		f := m.field(string(iter.FieldKey()))
+		if f == nil {
+			return fmt.Sprintf("field \"%s\" is not allowed on measurement \"%s\"", iter.FieldKey(), name)
+		}
+		if typ := dataTypeFromFieldType(iter.Type()); typ != f.Type {
+			return fmt.Sprintf("field \"%s\" on measurement \"%s\" is type %s, declared as type %s", iter.FieldKey(), name, typ, f.Type)
+		}
+	}
+	return ""
+}
+
+func (m *MeasurementSchema) hasTag(key string) bool {
+	for _, keys := range [][]string{m.RequiredTags, m.AllowedTags} {
+		for _, k := range keys {
+			if k == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *MeasurementSchema) field(name string) *FieldSchema {
+	for i := range m.Fields {
+		if m.Fields[i].Name == name {
+			return &m.Fields[i]
+		}
+	}
+	return nil
+}
+
+// dataTypeFromFieldType returns the InfluxQL data type of a field type.
+func dataTypeFromFieldType(typ models.FieldType) influxql.DataType {
+	switch typ {
+	case models.Float:
+		return influxql.Float
+	case models.Integer:
+		return influxql.Integer
+	case models.Unsigned:
+		return influxql.Unsigned
+	case models.String:
+		return influxql.String
+	case models.Boolean:
+		return influxql.Boolean
+	}
+	return influxql.Unknown
+}
+
+// clone returns a deep copy of s.
+func (s *DatabaseSchema) clone() *DatabaseSchema {
+	if s == nil {
+		return nil
+	}
+	other := &DatabaseSchema{Mode: s.Mode}
+	if s.Measurements != nil {
+		other.Measurements = make([]MeasurementSchema, len(s.Measurements))
+		for i, m := range s.Measurements {
+			other.Measurements[i] = MeasurementSchema{
+				Name:         m.Name,
+				RequiredTags: append([]string(nil), m.RequiredTags...),
+				AllowedTags:  append([]string(nil), m.AllowedTags...),
+				Fields:       append([]FieldSchema(nil), m.Fields...),
+			}
+		}
+	}
+	return other
+}
+
+// marshalDatabaseSchema serializes a schema to a protobuf representation.
+func marshalDatabaseSchema(s *DatabaseSchema) *internal.DatabaseSchema {
+	if s == nil {
+		return nil
+	}
+	pb := &internal.DatabaseSchema{
+		Mode:         proto.String(s.Mode),
+		Measurements: make([]*internal.MeasurementSchema, len(s.Measurements)),
+	}
+	for i, m := range s.Measurements {
+		pm := &internal.MeasurementSchema{
+			Name:         proto.String(m.Name),
+			RequiredTags: m.RequiredTags,
+			AllowedTags:  m.AllowedTags,
+			Fields:       make([]*internal.FieldSchema, len(m.Fields)),
+		}
+		for j, f := range m.Fields {
+			pm.Fields[j] = &internal.FieldSchema{
+				Name: proto.String(f.Name),
+				Type: proto.String(f.Type.String()),
+			}
+		}
+		pb.Measurements[i] = pm
+	}
+	return pb
+}
+
+// unmarshalDatabaseSchema deserializes a schema from a protobuf representation.
+func unmarshalDatabaseSchema(pb *internal.DatabaseSchema) *DatabaseSchema {
+	if pb == nil {
+		return nil
+	}
+	s := &DatabaseSchema{Mode: pb.GetMode()}
+	if len(pb.GetMeasurements()) > 0 {
+		s.Measurements = make([]MeasurementSchema, len(pb.GetMeasurements()))
+		for i, pm := range pb.GetMeasurements() {
+			m := &s.Measurements[i]
+			m.Name = pm.GetName()
+			if len(pm.GetRequiredTags()) > 0 {
+				m.RequiredTags = append([]string(nil), pm.GetRequiredTags()...)
+			}
+			if len(pm.GetAllowedTags()) > 0 {
+				m.AllowedTags = append([]string(nil), pm.GetAllowedTags()...)
+			}
+			if len(pm.GetFields()) > 0 {
+				m.Fields = make([]FieldSchema, len(pm.GetFields()))
+				for j, pf := range pm.GetFields() {
+					m.Fields[j] = FieldSchema{
+						Name: pf.GetName(),
+						Type: influxql.DataTypeFromString(pf.GetType()),
+					}
+				}
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,208 @@
+package meta
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	internal "github.com/influxdata/influxdb/services/meta/internal"
+	"github.com/influxdata/influxql"
+)
+
+// TokenPrefix is the prefix of API tokens. It distinguishes tokens from
+// passwords when a token is given as the password of a user.
+const TokenPrefix = "influx_"
+
+// TokenScopeAdmin is the scope allowing a token of an admin user to execute
+// admin statements. The other scopes are "read:<database>" and
+// "write:<database>".
+const TokenScopeAdmin = "admin"
+
+var (
+	// ErrTokenNotFound is returned when dropping a token that doesn't exist.
+	ErrTokenNotFound = errors.New("token not found")
+
+	// ErrTokenExpired is returned when authenticating with an expired token.
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// TokenInfo represents an API token of a user. Only the hash of the secret
+// of the token is stored.
+type TokenInfo struct {
+	ID          string
+	User        string
+	Hash        string
+	Scopes      []string
+	Description string
+	CreatedAt   time.Time
+
+	// ExpiresAt is the time the token expires, or zero if it never expires.
+	ExpiresAt time.Time
+}
+
+// Expired returns true if the token is expired at the given time.
+func (ti *TokenInfo) Expired(now time.Time) bool {
+	return !ti.ExpiresAt.IsZero() && !now.Before(ti.ExpiresAt)
+}
+
+// Validate returns an error if the token is invalid.
+func (ti *TokenInfo) Validate() error {
+	if ti.ID == "" {
+		return errors.New("token id required")
+	} else if ti.User == "" {
+		return ErrUsernameRequired
+	} else if ti.Hash == "" {
+		return errors.New("token hash required")
+	} else if len(ti.Scopes) == 0 {
+		return errors.New("token requires at least one scope")
+	}
+	for _, s := range ti.Scopes {
+		if _, _, err := ParseTokenScope(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseTokenScope returns the privilege and database of a scope. The admin
+// scope returns the AllPrivileges privilege and an empty database.
+func ParseTokenScope(scope string) (influxql.Privilege, string, error) {
+	if scope == TokenScopeAdmin {
+		return influxql.AllPrivileges, "", nil
+	}
+	i := strings.IndexByte(scope, ':')
+	if i < 0 || i == len(scope)-1 {
+		return influxql.NoPrivileges, "", fmt.Errorf("invalid token scope %q", scope)
+	}
+	switch scope[:i] {
+	case "read":
+		return influxql.ReadPrivilege, scope[i+1:], nil
+	case "write":
+		return influxql.WritePrivilege, scope[i+1:], nil
+	}
+	return influxql.NoPrivileges, "", fmt.Errorf("invalid token scope %q", scope)
+}
+
+// formatToken returns the token given to the user, from which the ID and
+// secret of the token are parsed when authenticating.
+func formatToken(id, secret string) string {
+	return TokenPrefix + id + "." + secret
+}
+
+// parseToken returns the ID and secret of a token.
+func parseToken(token string) (id, secret string, ok bool) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return "", "", false
+	}
+	token = token[len(TokenPrefix):]
+	i := strings.IndexByte(token, '.')
+	if i <= 0 || i == len(token)-1 {
+		return "", "", false
+	}
+	return token[:i], token[i+1:], true
+}
+
+// RedactDestination returns a subscription destination with the secret of an
+// API token or the password in its user info replaced by "xxxxx". The token ID
+// is kept so the token can still be identified.
+func RedactDestination(dest string) string {
+	u, err := url.Parse(dest)
+	if err != nil || u.User == nil {
+		return dest
+	}
+	if _, ok := u.User.Password(); ok {
+		return u.Redacted()
+	}
+	if id, _, ok := parseToken(u.User.Username()); ok {
+		u.User = url.User(TokenPrefix + id + ".xxxxx")
+		return u.String()
+	}
+	return dest
+}
+
+// hashTokenSecret returns the hash of the secret of a token. The secrets are
+// random, so they don't need a salted hash.
+func hashTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// matchSecret returns true if the secret matches the hash of the token.
+func (ti *TokenInfo) matchSecret(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashTokenSecret(secret)), []byte(ti.Hash)) == 1
+}
+
+// scopedUser returns a copy of the user of the token limited to the scopes of
+// the token. The privileges of the copy never exceed those of the user.
+func (ti *TokenInfo) scopedUser(u *UserInfo) *UserInfo {
+	scoped := u.clone()
+	scoped.Admin = false
+	scoped.Privileges = make(map[string]influxql.Privilege)
+	scoped.Token = ti.ID
+	for _, s := range ti.Scopes {
+		p, db, err := ParseTokenScope(s)
+		if err != nil {
+			continue
+		} else if s == TokenScopeAdmin {
+			scoped.Admin = u.Admin
+			continue
+		} else if !u.AuthorizeDatabase(p, db) {
+			continue
+		}
+
+		if cur, ok := scoped.Privileges[db]; ok && cur != p {
+			p = influxql.AllPrivileges
+		}
+		scoped.Privileges[db] = p
+	}
+	return &scoped
+}
+
+// clone returns a deep copy of ti.
+func (ti TokenInfo) clone() TokenInfo {
+	other := ti
+	if ti.Scopes != nil {
+		other.Scopes = make([]string, len(ti.Scopes))
+		copy(other.Scopes, ti.Scopes)
+	}
+	return other
+}
+
+// marshal serializes to a protobuf representation.
+func (ti TokenInfo) marshal() *internal.TokenInfo {
+	pb := &internal.TokenInfo{
+		ID:        proto.String(ti.ID),
+		User:      proto.String(ti.User),
+		Hash:      proto.String(ti.Hash),
+		Scopes:    ti.Scopes,
+		CreatedAt: proto.Int64(ti.CreatedAt.UnixNano()),
+	}
+	if ti.Description != "" {
+		pb.Description = proto.String(ti.Description)
+	}
+	if !ti.ExpiresAt.IsZero() {
+		pb.ExpiresAt = proto.Int64(ti.ExpiresAt.UnixNano())
+	}
+	return pb
+}
+
+// unmarshal deserializes from a protobuf representation.
+func (ti *TokenInfo) unmarshal(pb *internal.TokenInfo) {
+	ti.ID = pb.GetID()
+	ti.User = pb.GetUser()
+	ti.Hash = pb.GetHash()
+	if len(pb.GetScopes()) > 0 {
+		ti.Scopes = append([]string(nil), pb.GetScopes()...)
+	}
+	ti.Description = pb.GetDescription()
+	ti.CreatedAt = time.Unix(0, pb.GetCreatedAt()).UTC()
+	if pb.ExpiresAt != nil {
+		ti.ExpiresAt = time.Unix(0, pb.GetExpiresAt()).UTC()
+	}
+}
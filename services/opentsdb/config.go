@@ -48,6 +48,11 @@ type Config struct {
 	BatchTimeout     toml.Duration `toml:"batch-timeout"`
 	LogPointErrors   bool          `toml:"log-point-errors"`
 	TLS              *tls.Config   `toml:"-"`
+
+	// QueryEnabled enables the /api/query and /api/suggest endpoints. If
+	// authentication is enabled, the requests must have the credentials of
+	// a user allowed to read the database.
+	QueryEnabled bool `toml:"query-enabled"`
 }
 
 // NewConfig returns a new config for the service.
@@ -14,6 +14,9 @@ import (
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
 	"go.uber.org/zap"
 )
 
@@ -26,6 +29,22 @@ type Handler struct {
 		WritePointsPrivileged(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error
 	}
 
+	// QueryExecutor runs the queries of the /api/query and /api/suggest
+	// endpoints. If nil, the endpoints are not available.
+	QueryExecutor interface {
+		ExecuteQuery(query *influxql.Query, opt query.ExecutionOptions, closing chan struct{}) <-chan *query.Result
+	}
+
+	// AuthEnabled requires the queries to be authorized for the user whose
+	// credentials are in the basic auth header of the requests.
+	AuthEnabled   bool
+	Authenticator interface {
+		Authenticate(username, password string) (meta.User, error)
+	}
+	QueryAuthorizer interface {
+		AuthorizeQuery(u meta.User, query *influxql.Query, database string) (query.FineAuthorizer, error)
+	}
+
 	Logger *zap.Logger
 
 	stats *Statistics
@@ -38,6 +57,10 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
 	case "/api/put":
 		h.servePut(w, r)
+	case "/api/query":
+		h.serveQuery(w, r)
+	case "/api/suggest":
+		h.serveSuggest(w, r)
 	default:
 		http.NotFound(w, r)
 	}
@@ -85,24 +108,43 @@ func (h *Handler) servePut(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Decode JSON data into slice of points.
-	dps := make([]point, 1)
+	// Decode JSON data into slice of points. Each point is decoded separately
+	// so that invalid points can be reported.
+	raws := make([]json.RawMessage, 1)
 	if dec := json.NewDecoder(br); multi {
-		if err = dec.Decode(&dps); err != nil {
+		if err = dec.Decode(&raws); err != nil {
 			http.Error(w, "json array decode error", http.StatusBadRequest)
 			return
 		}
 	} else {
-		if err = dec.Decode(&dps[0]); err != nil {
+		if err = dec.Decode(&raws[0]); err != nil {
 			http.Error(w, "json object decode error", http.StatusBadRequest)
 			return
 		}
 	}
 
+	// The number of points written and the points that failed are returned
+	// if requested, as OpenTSDB does.
+	q := r.URL.Query()
+	_, details := q["details"]
+	_, summary := q["summary"]
+
 	// Convert points into TSDB points.
-	points := make([]models.Point, 0, len(dps))
-	for i := range dps {
-		p := dps[i]
+	var errs []putError
+	points := make([]models.Point, 0, len(raws))
+	for _, raw := range raws {
+		var p point
+		if err := json.Unmarshal(raw, &p); err != nil {
+			if !details && !summary {
+				http.Error(w, "json object decode error", http.StatusBadRequest)
+				return
+			}
+			errs = append(errs, putError{Datapoint: raw, Error: "json object decode error: " + err.Error()})
+			continue
+		} else if p.Metric == "" {
+			errs = append(errs, putError{Datapoint: raw, Error: "metric name was empty"})
+			continue
+		}
 
 		// Convert timestamp to Go time.
 		// If time value is over a billion then it's microseconds.
@@ -116,13 +158,14 @@ func (h *Handler) servePut(w http.ResponseWriter, r *http.Request) {
 		pt, err := models.NewPoint(p.Metric, models.NewTags(p.Tags), map[string]interface{}{"value": p.Value}, ts)
 		if err != nil {
 			h.Logger.Info("Dropping point", zap.String("name", p.Metric), zap.Error(err))
-			if h.stats != nil {
-				atomic.AddInt64(&h.stats.InvalidDroppedPoints, 1)
-			}
+			errs = append(errs, putError{Datapoint: raw, Error: err.Error()})
 			continue
 		}
 		points = append(points, pt)
 	}
+	if h.stats != nil {
+		atomic.AddInt64(&h.stats.InvalidDroppedPoints, int64(len(errs)))
+	}
 
 	// Write points.
 	if err := h.PointsWriter.WritePointsPrivileged(h.Database, h.RetentionPolicy, models.ConsistencyLevelAny, points); influxdb.IsClientError(err) {
@@ -135,7 +178,39 @@ func (h *Handler) servePut(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	if !details && !summary {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	resp := putResponse{Success: len(points), Failed: len(errs)}
+	if details {
+		resp.Errors = errs
+		if resp.Errors == nil {
+			resp.Errors = []putError{}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// putResponse is the response of /api/put if the summary or details were
+// requested.
+type putResponse struct {
+	Errors  []putError `json:"errors,omitempty"`
+	Failed  int        `json:"failed"`
+	Success int        `json:"success"`
+}
+
+// putError is a point of /api/put that could not be written.
+type putError struct {
+	Datapoint json.RawMessage `json:"datapoint"`
+	Error     string          `json:"error"`
 }
 
 // chanListener represents a listener that receives connections through a channel.
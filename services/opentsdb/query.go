@@ -0,0 +1,776 @@
+package opentsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxql"
+)
+
+// DefaultSuggestMax is the default maximum number of results returned by
+// /api/suggest.
+const DefaultSuggestMax = 25
+
+// tsdbQuery is a request of the /api/query endpoint.
+type tsdbQuery struct {
+	Start        timeParam      `json:"start"`
+	End          timeParam      `json:"end"`
+	MSResolution bool           `json:"msResolution"`
+	Queries      []tsdbSubQuery `json:"queries"`
+}
+
+// tsdbSubQuery is a query of a single metric.
+type tsdbSubQuery struct {
+	Aggregator string            `json:"aggregator"`
+	Metric     string            `json:"metric"`
+	Downsample string            `json:"downsample"`
+	Rate       bool              `json:"rate"`
+	Tags       map[string]string `json:"tags"`
+	Filters    []tsdbFilter      `json:"filters"`
+}
+
+// tsdbFilter is a filter on the values of a tag.
+type tsdbFilter struct {
+	Type    string `json:"type"`
+	TagK    string `json:"tagk"`
+	Filter  string `json:"filter"`
+	GroupBy bool   `json:"groupBy"`
+}
+
+// timeParam is an absolute or relative time. It is decoded from a JSON number
+// or string.
+type timeParam string
+
+// UnmarshalJSON decodes a time from a JSON number or string.
+func (t *timeParam) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = timeParam(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return errors.New("time must be a number or a string")
+	}
+	*t = timeParam(n.String())
+	return nil
+}
+
+// tsdbQueryResult is the result of a query for a group of series.
+type tsdbQueryResult struct {
+	Metric        string                 `json:"metric"`
+	Tags          map[string]string      `json:"tags"`
+	AggregateTags []string               `json:"aggregateTags"`
+	DPS           map[string]interface{} `json:"dps"`
+}
+
+// serveQuery implements OpenTSDB's HTTP /api/query endpoint by translating
+// the queries to InfluxQL.
+func (h *Handler) serveQuery(w http.ResponseWriter, r *http.Request) {
+	if h.QueryExecutor == nil {
+		writeError(w, http.StatusNotImplemented, "queries are not supported")
+		return
+	}
+
+	var q tsdbQuery
+	switch r.Method {
+	case "GET":
+		var err error
+		if q, err = parseQueryParams(r); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	case "POST":
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			writeError(w, http.StatusBadRequest, "json object decode error: "+err.Error())
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		return
+	}
+
+	now := time.Now()
+	start, end, err := q.timeRange(now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(q.Queries) == 0 {
+		writeError(w, http.StatusBadRequest, "missing sub queries")
+		return
+	}
+
+	// Each sub query is run with the tag keys of its metric, which are
+	// needed for the aggregated tags of the results.
+	stmts := make(influxql.Statements, 0, 2*len(q.Queries))
+	for _, sq := range q.Queries {
+		stmt, err := sq.statement(start, end, q.MSResolution)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		stmts = append(stmts, stmt, &influxql.ShowTagKeysStatement{
+			Sources: influxql.Sources{&influxql.Measurement{Name: sq.Metric}},
+		})
+	}
+
+	rows, err := h.executeQuery(r, &influxql.Query{Statements: stmts})
+	if err != nil {
+		writeQueryError(w, err)
+		return
+	}
+
+	results := make([]tsdbQueryResult, 0)
+	for i, sq := range q.Queries {
+		var keys []string
+		for _, row := range rows[2*i+1] {
+			for _, v := range row.Values {
+				if k, ok := v[0].(string); ok {
+					keys = append(keys, k)
+				}
+			}
+		}
+		for _, row := range rows[2*i] {
+			results = append(results, newQueryResult(sq.Metric, row, keys, q.MSResolution))
+		}
+	}
+	writeJSON(w, results)
+}
+
+// newQueryResult converts a row of a SELECT statement to a query result.
+func newQueryResult(metric string, row *models.Row, keys []string, ms bool) tsdbQueryResult {
+	result := tsdbQueryResult{
+		Metric:        metric,
+		Tags:          make(map[string]string, len(row.Tags)),
+		AggregateTags: make([]string, 0),
+		DPS:           make(map[string]interface{}, len(row.Values)),
+	}
+	for k, v := range row.Tags {
+		if v != "" {
+			result.Tags[k] = v
+		}
+	}
+	for _, k := range keys {
+		if _, ok := result.Tags[k]; !ok {
+			result.AggregateTags = append(result.AggregateTags, k)
+		}
+	}
+	sort.Strings(result.AggregateTags)
+
+	for _, v := range row.Values {
+		t, ok := v[0].(time.Time)
+		if !ok {
+			continue
+		}
+		ts := t.Unix()
+		if ms {
+			ts = t.UnixNano() / int64(time.Millisecond)
+		}
+		result.DPS[strconv.FormatInt(ts, 10)] = v[1]
+	}
+	return result
+}
+
+// parseQueryParams parses the parameters of a GET request of /api/query.
+func parseQueryParams(r *http.Request) (tsdbQuery, error) {
+	params := r.URL.Query()
+	q := tsdbQuery{
+		Start: timeParam(params.Get("start")),
+		End:   timeParam(params.Get("end")),
+	}
+	if _, ok := params["ms"]; ok {
+		q.MSResolution = true
+	}
+	if _, ok := params["tsuid"]; ok {
+		return q, errors.New("tsuid queries are not supported")
+	}
+	for _, m := range params["m"] {
+		sq, err := parseMetricQuery(m)
+		if err != nil {
+			return q, err
+		}
+		q.Queries = append(q.Queries, sq)
+	}
+	return q, nil
+}
+
+// parseMetricQuery parses a metric query such as
+// sum:1m-avg:sys.cpu.user{host=*}{dc=literal_or(lga|sjc)}.
+func parseMetricQuery(s string) (tsdbSubQuery, error) {
+	var sq tsdbSubQuery
+
+	braces := strings.IndexByte(s, '{')
+	if braces < 0 {
+		braces = len(s)
+	}
+	parts := strings.Split(s[:braces], ":")
+	if len(parts) < 2 {
+		return sq, fmt.Errorf("invalid metric query: %s", s)
+	}
+	sq.Aggregator = parts[0]
+	sq.Metric = parts[len(parts)-1]
+	for _, p := range parts[1 : len(parts)-1] {
+		switch {
+		case strings.HasPrefix(p, "rate"):
+			sq.Rate = true
+		case strings.Contains(p, "-"):
+			sq.Downsample = p
+		default:
+			return sq, fmt.Errorf("invalid metric query option: %s", p)
+		}
+	}
+
+	// The first braces hold the filters that group the results and the
+	// second braces the filters that do not.
+	for groupBy := true; braces < len(s); groupBy = false {
+		end := strings.IndexByte(s[braces:], '}')
+		if end < 0 {
+			return sq, fmt.Errorf("missing closing brace in metric query: %s", s)
+		}
+		for _, f := range splitFilters(s[braces+1 : braces+end]) {
+			i := strings.IndexByte(f, '=')
+			if i <= 0 {
+				return sq, fmt.Errorf("invalid tag filter: %s", f)
+			}
+			sq.Filters = append(sq.Filters, newFilter(f[:i], f[i+1:], groupBy))
+		}
+		braces += end + 1
+		if braces < len(s) && s[braces] != '{' {
+			return sq, fmt.Errorf("invalid metric query: %s", s)
+		} else if !groupBy && braces < len(s) {
+			return sq, fmt.Errorf("too many filters in metric query: %s", s)
+		}
+	}
+	return sq, nil
+}
+
+// splitFilters splits comma separated tag filters. Commas between the
+// parentheses of a filter function do not separate filters.
+func splitFilters(s string) []string {
+	var filters []string
+	var depth, start int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				filters = append(filters, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(s) {
+		filters = append(filters, s[start:])
+	}
+	return filters
+}
+
+// filterFuncRegex matches a filter function such as literal_or(a|b).
+var filterFuncRegex = regexp.MustCompile(`^(\w+)\((.*)\)$`)
+
+// newFilter returns the filter of a tag from a filter function or from a
+// value of the tags of a query, which is a literal, a list of literals
+// separated by a pipe or a wildcard.
+func newFilter(tagk, v string, groupBy bool) tsdbFilter {
+	if m := filterFuncRegex.FindStringSubmatch(v); m != nil {
+		return tsdbFilter{Type: m[1], TagK: tagk, Filter: m[2], GroupBy: groupBy}
+	} else if strings.Contains(v, "*") {
+		return tsdbFilter{Type: "wildcard", TagK: tagk, Filter: v, GroupBy: groupBy}
+	}
+	return tsdbFilter{Type: "literal_or", TagK: tagk, Filter: v, GroupBy: groupBy}
+}
+
+// timeRange returns the start and end time of the query.
+func (q *tsdbQuery) timeRange(now time.Time) (start, end time.Time, err error) {
+	if q.Start == "" {
+		return start, end, errors.New("missing start time")
+	}
+	if start, err = parseTime(string(q.Start), now); err != nil {
+		return start, end, err
+	}
+	end = now
+	if q.End != "" {
+		if end, err = parseTime(string(q.End), now); err != nil {
+			return start, end, err
+		}
+	}
+	if end.Before(start) {
+		return start, end, errors.New("start time must be before the end time")
+	}
+	return start, end, nil
+}
+
+// parseTime parses an absolute or relative time of a query. A time is either
+// "now", a duration followed by "-ago", a Unix timestamp in seconds or
+// milliseconds, or a date such as 2013/01/02-15:04:05.
+func parseTime(s string, now time.Time) (time.Time, error) {
+	switch {
+	case s == "now":
+		return now, nil
+	case strings.HasSuffix(s, "-ago"):
+		d, err := parseDuration(strings.TrimSuffix(s, "-ago"))
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(-d), nil
+	case strings.Contains(s, "/"):
+		for _, layout := range []string{"2006/01/02-15:04:05", "2006/01/02 15:04:05", "2006/01/02-15:04", "2006/01/02 15:04", "2006/01/02"} {
+			if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid date: %s", s)
+	}
+
+	// Timestamps with more than 10 digits are in milliseconds, as are
+	// timestamps with a fraction of a second.
+	if strings.Contains(s, ".") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || f < 0 {
+			return time.Time{}, fmt.Errorf("invalid time: %s", s)
+		}
+		return time.Unix(0, int64(f*1000)*int64(time.Millisecond)), nil
+	}
+	ts, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || ts < 0 {
+		return time.Time{}, fmt.Errorf("invalid time: %s", s)
+	}
+	if len(s) > 10 {
+		return time.Unix(0, ts*int64(time.Millisecond)), nil
+	}
+	return time.Unix(ts, 0), nil
+}
+
+// parseDuration parses a duration such as 5m or 1d. Months and years are not
+// supported as they do not have a fixed duration.
+func parseDuration(s string) (time.Duration, error) {
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i <= 0 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	n, err := strconv.ParseInt(s[:i], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	var unit time.Duration
+	switch s[i:] {
+	case "ms":
+		unit = time.Millisecond
+	case "s":
+		unit = time.Second
+	case "m":
+		unit = time.Minute
+	case "h":
+		unit = time.Hour
+	case "d":
+		unit = 24 * time.Hour
+	case "w":
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("unsupported duration unit: %s", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// aggregateFunc returns the InfluxQL function of an OpenTSDB aggregator.
+func aggregateFunc(agg string) (string, error) {
+	switch agg {
+	case "sum", "zimsum":
+		return "sum(value)", nil
+	case "avg":
+		return "mean(value)", nil
+	case "min", "mimmin":
+		return "min(value)", nil
+	case "max", "mimmax":
+		return "max(value)", nil
+	case "count":
+		return "count(value)", nil
+	case "dev":
+		return "stddev(value)", nil
+	case "median":
+		return "median(value)", nil
+	case "first":
+		return "first(value)", nil
+	case "last":
+		return "last(value)", nil
+	}
+	if strings.HasPrefix(agg, "p") {
+		if n, err := strconv.ParseFloat(agg[1:], 64); err == nil && n > 0 && n <= 100 {
+			return fmt.Sprintf("percentile(value, %s)", agg[1:]), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported aggregator: %s", agg)
+}
+
+// statement returns the InfluxQL statement of the sub query. A downsampled
+// query is run as a subquery that downsamples each series, which the outer
+// query aggregates.
+func (sq *tsdbSubQuery) statement(start, end time.Time, ms bool) (*influxql.SelectStatement, error) {
+	if sq.Metric == "" {
+		return nil, errors.New("missing metric")
+	} else if sq.Rate {
+		return nil, errors.New("rate is not supported")
+	}
+
+	var agg string
+	if sq.Aggregator != "none" {
+		var err error
+		if agg, err = aggregateFunc(sq.Aggregator); err != nil {
+			return nil, err
+		}
+	}
+
+	var groupBy []string
+	cond := []string{
+		fmt.Sprintf("time >= %d", start.UnixNano()),
+		fmt.Sprintf("time <= %d", end.UnixNano()),
+	}
+	filters := sq.Filters
+	for k, v := range sq.Tags {
+		filters = append(filters, newFilter(k, v, true))
+	}
+	for _, f := range filters {
+		if f.TagK == "" {
+			return nil, errors.New("missing tag key of filter")
+		}
+		expr, err := f.condition()
+		if err != nil {
+			return nil, err
+		} else if expr != "" {
+			cond = append(cond, expr)
+		}
+		if f.GroupBy {
+			groupBy = append(groupBy, influxql.QuoteIdent(f.TagK))
+		}
+	}
+	sort.Strings(groupBy)
+	where := strings.Join(cond, " AND ")
+	from := influxql.QuoteIdent(sq.Metric)
+
+	var s string
+	if sq.Downsample == "" {
+		if agg == "" {
+			s = fmt.Sprintf("SELECT value FROM %s WHERE %s GROUP BY *", from, where)
+		} else {
+			// Values of the same timestamp are aggregated.
+			interval := "time(1s)"
+			if ms {
+				interval = "time(1ms)"
+			}
+			s = fmt.Sprintf("SELECT %s AS value FROM %s WHERE %s%s fill(none)", agg, from, where, groupByClause(interval, groupBy))
+		}
+	} else {
+		interval, dsAgg, fill, err := parseDownsample(sq.Downsample)
+		if err != nil {
+			return nil, err
+		}
+		s = fmt.Sprintf("SELECT %s AS value FROM %s WHERE %s%s", dsAgg, from, where, groupByClause(interval, []string{"*"}))
+		if agg != "" {
+			if interval != "" {
+				s += " fill(none)"
+			}
+			s = fmt.Sprintf("SELECT %s AS value FROM (%s) WHERE %s%s", agg, s, strings.Join(cond[:2], " AND "), groupByClause(interval, groupBy))
+		}
+		if interval != "" {
+			s += " " + fill
+		}
+	}
+
+	stmt, err := influxql.ParseStatement(s)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.(*influxql.SelectStatement), nil
+}
+
+// groupByClause returns the GROUP BY clause of the time dimension, if any,
+// and the tag dimensions.
+func groupByClause(interval string, tags []string) string {
+	if interval != "" {
+		tags = append([]string{interval}, tags...)
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+	return " GROUP BY " + strings.Join(tags, ", ")
+}
+
+// parseDownsample parses a downsample specification such as 1m-avg or
+// 1h-sum-zero and returns the time dimension, the aggregate function and the
+// fill option of the query. The time dimension is empty for 0all.
+func parseDownsample(s string) (interval, agg, fill string, err error) {
+	parts := strings.Split(s, "-")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", "", "", fmt.Errorf("invalid downsample: %s", s)
+	}
+	if parts[0] != "0all" {
+		d, err := parseDuration(parts[0])
+		if err != nil {
+			return "", "", "", err
+		} else if d <= 0 {
+			return "", "", "", fmt.Errorf("invalid downsample interval: %s", parts[0])
+		}
+		interval = "time(" + influxql.FormatDuration(d) + ")"
+	}
+	if agg, err = aggregateFunc(parts[1]); err != nil {
+		return "", "", "", err
+	}
+	fill = "fill(none)"
+	if len(parts) == 3 {
+		switch parts[2] {
+		case "none":
+		case "nan", "null":
+			fill = "fill(null)"
+		case "zero":
+			fill = "fill(0)"
+		default:
+			return "", "", "", fmt.Errorf("unsupported fill policy: %s", parts[2])
+		}
+	}
+	return interval, agg, fill, nil
+}
+
+// condition returns the InfluxQL condition of the filter. It is empty if the
+// filter matches all values.
+func (f *tsdbFilter) condition() (string, error) {
+	key := influxql.QuoteIdent(f.TagK)
+	values := strings.Split(f.Filter, "|")
+	switch f.Type {
+	case "literal_or", "not_literal_or":
+		op, join := "=", " OR "
+		if f.Type == "not_literal_or" {
+			op, join = "!=", " AND "
+		}
+		exprs := make([]string, len(values))
+		for i, v := range values {
+			exprs[i] = fmt.Sprintf("%s %s %s", key, op, influxql.QuoteString(v))
+		}
+		return "(" + strings.Join(exprs, join) + ")", nil
+	case "iliteral_or", "not_iliteral_or":
+		for i, v := range values {
+			values[i] = regexp.QuoteMeta(v)
+		}
+		op := "=~"
+		if f.Type == "not_iliteral_or" {
+			op = "!~"
+		}
+		return regexCondition(key, op, "(?i)^(?:"+strings.Join(values, "|")+")$")
+	case "wildcard", "iwildcard":
+		// A tag matches a wildcard only if it has a value.
+		if f.Filter == "*" {
+			return regexCondition(key, "=~", ".+")
+		}
+		parts := strings.Split(f.Filter, "*")
+		for i, p := range parts {
+			parts[i] = regexp.QuoteMeta(p)
+		}
+		re := "^" + strings.Join(parts, ".*") + "$"
+		if f.Type == "iwildcard" {
+			re = "(?i)" + re
+		}
+		return regexCondition(key, "=~", re)
+	case "regexp":
+		return regexCondition(key, "=~", f.Filter)
+	default:
+		return "", fmt.Errorf("unsupported filter type: %s", f.Type)
+	}
+}
+
+func regexCondition(key, op, re string) (string, error) {
+	r, err := regexp.Compile(re)
+	if err != nil {
+		return "", fmt.Errorf("invalid regular expression: %s", err)
+	}
+	return fmt.Sprintf("%s %s %s", key, op, (&influxql.RegexLiteral{Val: r}).String()), nil
+}
+
+// serveSuggest implements OpenTSDB's HTTP /api/suggest endpoint, which
+// returns the metrics, tag keys or tag values starting with a prefix.
+func (h *Handler) serveSuggest(w http.ResponseWriter, r *http.Request) {
+	if h.QueryExecutor == nil {
+		writeError(w, http.StatusNotImplemented, "queries are not supported")
+		return
+	}
+
+	var req struct {
+		Type string `json:"type"`
+		Q    string `json:"q"`
+		Max  int    `json:"max"`
+	}
+	switch r.Method {
+	case "GET":
+		params := r.URL.Query()
+		req.Type, req.Q = params.Get("type"), params.Get("q")
+		if s := params.Get("max"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid max: "+s)
+				return
+			}
+			req.Max = n
+		}
+	case "POST":
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "json object decode error: "+err.Error())
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		return
+	}
+	if req.Max <= 0 {
+		req.Max = DefaultSuggestMax
+	}
+
+	// The column of the results holding the suggestions.
+	var stmt influxql.Statement
+	var column int
+	switch req.Type {
+	case "metrics":
+		stmt = &influxql.ShowMeasurementsStatement{}
+	case "tagk":
+		stmt = &influxql.ShowTagKeysStatement{}
+	case "tagv":
+		stmt = &influxql.ShowTagValuesStatement{Op: influxql.EQREGEX, TagKeyExpr: &influxql.RegexLiteral{Val: regexp.MustCompile(`.*`)}}
+		column = 1
+	default:
+		writeError(w, http.StatusBadRequest, "invalid type: "+req.Type)
+		return
+	}
+
+	rows, err := h.executeQuery(r, &influxql.Query{Statements: influxql.Statements{stmt}})
+	if err != nil {
+		writeQueryError(w, err)
+		return
+	}
+
+	set := make(map[string]struct{})
+	for _, row := range rows[0] {
+		for _, v := range row.Values {
+			if s, ok := v[column].(string); ok && strings.HasPrefix(s, req.Q) {
+				set[s] = struct{}{}
+			}
+		}
+	}
+	suggestions := make([]string, 0, len(set))
+	for s := range set {
+		suggestions = append(suggestions, s)
+	}
+	sort.Strings(suggestions)
+	if len(suggestions) > req.Max {
+		suggestions = suggestions[:req.Max]
+	}
+	writeJSON(w, suggestions)
+}
+
+// authError is an error authenticating or authorizing a query.
+type authError struct {
+	code int
+	err  error
+}
+
+func (e *authError) Error() string { return e.err.Error() }
+
+// authorize returns the authorizers of the query for the user whose
+// credentials are in the basic auth header of the request. All queries are
+// authorized if authentication is disabled.
+func (h *Handler) authorize(r *http.Request, q *influxql.Query) (query.FineAuthorizer, query.CoarseAuthorizer, error) {
+	if !h.AuthEnabled {
+		return query.OpenAuthorizer, query.OpenCoarseAuthorizer, nil
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, nil, &authError{code: http.StatusUnauthorized, err: errors.New("unable to parse authentication credentials")}
+	}
+	user, err := h.Authenticator.Authenticate(username, password)
+	if err != nil {
+		return nil, nil, &authError{code: http.StatusUnauthorized, err: err}
+	}
+
+	fine, err := h.QueryAuthorizer.AuthorizeQuery(user, q, h.Database)
+	if err != nil {
+		return nil, nil, &authError{code: http.StatusForbidden, err: err}
+	}
+	coarse, ok := user.(query.CoarseAuthorizer)
+	if !ok {
+		// The statements have been authorized for the user.
+		coarse = query.OpenCoarseAuthorizer
+	}
+	return fine, coarse, nil
+}
+
+// executeQuery runs the statements against the database of the service on
+// behalf of the user of the request and returns the rows of each statement.
+func (h *Handler) executeQuery(r *http.Request, q *influxql.Query) ([]models.Rows, error) {
+	fine, coarse, err := h.authorize(r, q)
+	if err != nil {
+		return nil, err
+	}
+
+	closing := make(chan struct{})
+	defer close(closing)
+
+	opts := query.ExecutionOptions{
+		Database:         h.Database,
+		RetentionPolicy:  h.RetentionPolicy,
+		Authorizer:       fine,
+		CoarseAuthorizer: coarse,
+		ReadOnly:         true,
+	}
+	rows := make([]models.Rows, len(q.Statements))
+	for res := range h.QueryExecutor.ExecuteQuery(q, opts, closing) {
+		if res.Err != nil {
+			if err == nil {
+				err = res.Err
+			}
+			continue
+		}
+		if res.StatementID >= 0 && res.StatementID < len(rows) {
+			rows[res.StatementID] = append(rows[res.StatementID], res.Series...)
+		}
+	}
+	return rows, err
+}
+
+// writeJSON writes v as the JSON body of a successful response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeQueryError writes an error returned by executeQuery.
+func writeQueryError(w http.ResponseWriter, err error) {
+	var aerr *authError
+	if errors.As(err, &aerr) {
+		writeError(w, aerr.code, aerr.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
+// writeError writes an error in the format of the OpenTSDB API.
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": msg,
+		},
+	})
+}
@@ -0,0 +1,393 @@
+package opentsdb
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+)
+
+func TestParseMetricQuery(t *testing.T) {
+	sq, err := parseMetricQuery(`sum:1m-avg-zero:sys.cpu.user{host=*,dc=lga|sjc}{env=regexp(prod|stag(e|ing)),rack=r1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := tsdbSubQuery{
+		Aggregator: "sum",
+		Metric:     "sys.cpu.user",
+		Downsample: "1m-avg-zero",
+		Filters: []tsdbFilter{
+			{Type: "wildcard", TagK: "host", Filter: "*", GroupBy: true},
+			{Type: "literal_or", TagK: "dc", Filter: "lga|sjc", GroupBy: true},
+			{Type: "regexp", TagK: "env", Filter: "prod|stag(e|ing)"},
+			{Type: "literal_or", TagK: "rack", Filter: "r1"},
+		},
+	}
+	if !reflect.DeepEqual(sq, exp) {
+		t.Fatalf("unexpected query:\ngot %#v\nexp %#v", sq, exp)
+	}
+
+	for _, s := range []string{
+		"sys.cpu.user",
+		"sum:foo:sys.cpu.user",
+		"sum:sys.cpu.user{host=web01",
+		"sum:sys.cpu.user{host}",
+		"sum:sys.cpu.user{}{}{}",
+	} {
+		if _, err := parseMetricQuery(s); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	for _, tt := range []struct {
+		s   string
+		exp time.Time
+	}{
+		{s: "now", exp: now},
+		{s: "1h-ago", exp: now.Add(-time.Hour)},
+		{s: "2d-ago", exp: now.Add(-48 * time.Hour)},
+		{s: "1356998400", exp: time.Unix(1356998400, 0)},
+		{s: "1356998400123", exp: time.Unix(1356998400, 123000000)},
+		{s: "1356998400.5", exp: time.Unix(1356998400, 500000000)},
+		{s: "2013/01/02-03:04:05", exp: time.Date(2013, 1, 2, 3, 4, 5, 0, time.Local)},
+		{s: "2013/01/02 03:04", exp: time.Date(2013, 1, 2, 3, 4, 0, 0, time.Local)},
+		{s: "2013/01/02", exp: time.Date(2013, 1, 2, 0, 0, 0, 0, time.Local)},
+	} {
+		got, err := parseTime(tt.s, now)
+		if err != nil {
+			t.Errorf("%s: %s", tt.s, err)
+		} else if !got.Equal(tt.exp) {
+			t.Errorf("%s: got %s, exp %s", tt.s, got, tt.exp)
+		}
+	}
+
+	for _, s := range []string{"", "yesterday", "1n-ago", "-5", "2013/13/01"} {
+		if _, err := parseTime(s, now); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestSubQuery_Statement(t *testing.T) {
+	start, end := time.Unix(0, 1000), time.Unix(0, 2000)
+	for _, tt := range []struct {
+		name string
+		sq   tsdbSubQuery
+		ms   bool
+		exp  string
+	}{
+		{
+			name: "raw",
+			sq:   tsdbSubQuery{Aggregator: "none", Metric: "cpu"},
+			exp:  `SELECT value FROM cpu WHERE time >= 1000 AND time <= 2000 GROUP BY *`,
+		},
+		{
+			name: "aggregate",
+			sq:   tsdbSubQuery{Aggregator: "sum", Metric: "sys.cpu", Tags: map[string]string{"host": "*"}},
+			exp:  `SELECT sum(value) AS value FROM "sys.cpu" WHERE time >= 1000 AND time <= 2000 AND host =~ /.+/ GROUP BY time(1s), host fill(none)`,
+		},
+		{
+			name: "aggregate ms",
+			sq:   tsdbSubQuery{Aggregator: "p95", Metric: "cpu"},
+			ms:   true,
+			exp:  `SELECT percentile(value, 95) AS value FROM cpu WHERE time >= 1000 AND time <= 2000 GROUP BY time(1ms) fill(none)`,
+		},
+		{
+			name: "downsample",
+			sq: tsdbSubQuery{Aggregator: "avg", Metric: "cpu", Downsample: "5m-max-zero", Filters: []tsdbFilter{
+				{Type: "literal_or", TagK: "dc", Filter: "lga|sjc", GroupBy: true},
+				{Type: "not_literal_or", TagK: "env", Filter: "dev|test"},
+			}},
+			exp: `SELECT mean(value) AS value FROM (SELECT max(value) AS value FROM cpu WHERE time >= 1000 AND time <= 2000 AND (dc = 'lga' OR dc = 'sjc') AND (env != 'dev' AND env != 'test') GROUP BY time(5m), * fill(none)) WHERE time >= 1000 AND time <= 2000 GROUP BY time(5m), dc fill(0)`,
+		},
+		{
+			name: "downsample all",
+			sq:   tsdbSubQuery{Aggregator: "max", Metric: "cpu", Downsample: "0all-sum"},
+			exp:  `SELECT max(value) AS value FROM (SELECT sum(value) AS value FROM cpu WHERE time >= 1000 AND time <= 2000 GROUP BY *) WHERE time >= 1000 AND time <= 2000`,
+		},
+		{
+			name: "downsample raw",
+			sq:   tsdbSubQuery{Aggregator: "none", Metric: "cpu", Downsample: "1h-count-null"},
+			exp:  `SELECT count(value) AS value FROM cpu WHERE time >= 1000 AND time <= 2000 GROUP BY time(1h), *`,
+		},
+		{
+			name: "filters",
+			sq: tsdbSubQuery{Aggregator: "sum", Metric: "cpu", Filters: []tsdbFilter{
+				{Type: "iliteral_or", TagK: "host", Filter: "web.01"},
+				{Type: "wildcard", TagK: "rack", Filter: "r*/1"},
+				{Type: "regexp", TagK: "env", Filter: "^prod"},
+			}},
+			exp: `SELECT sum(value) AS value FROM cpu WHERE time >= 1000 AND time <= 2000 AND host =~ /(?i)^(?:web\.01)$/ AND rack =~ /^r.*\/1$/ AND env =~ /^prod/ GROUP BY time(1s) fill(none)`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := tt.sq.statement(start, end, tt.ms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stmt.String(); got != tt.exp {
+				t.Fatalf("unexpected statement:\ngot %s\nexp %s", got, tt.exp)
+			}
+		})
+	}
+
+	for _, sq := range []tsdbSubQuery{
+		{Aggregator: "sum"},
+		{Aggregator: "foo", Metric: "cpu"},
+		{Aggregator: "sum", Metric: "cpu", Rate: true},
+		{Aggregator: "sum", Metric: "cpu", Downsample: "1n-avg"},
+		{Aggregator: "sum", Metric: "cpu", Downsample: "1m-avg-linear"},
+		{Aggregator: "sum", Metric: "cpu", Filters: []tsdbFilter{{Type: "regexp", TagK: "host", Filter: "("}}},
+		{Aggregator: "sum", Metric: "cpu", Filters: []tsdbFilter{{Type: "not_key", TagK: "host"}}},
+	} {
+		if _, err := sq.statement(start, end, false); err == nil {
+			t.Errorf("%+v: expected error", sq)
+		}
+	}
+}
+
+func TestService_HTTP_Query(t *testing.T) {
+	t.Parallel()
+
+	s := NewTestService("db0", "127.0.0.1:0")
+	s.Service.queryEnabled = true
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	s.ExecuteQueryFn = func(q *influxql.Query, opt query.ExecutionOptions) []*query.Result {
+		if opt.Database != "db0" || !opt.ReadOnly {
+			t.Errorf("unexpected options: %+v", opt)
+		}
+		if got, exp := q.String(), `SELECT sum(value) AS value FROM cpu WHERE time >= 1356998400000000000 AND time <= 1356998520000000000 AND host =~ /.+/ GROUP BY time(1s), host fill(none);`+"\n"+`SHOW TAG KEYS FROM cpu`; got != exp {
+			t.Errorf("unexpected query:\ngot %s\nexp %s", got, exp)
+		}
+		return []*query.Result{
+			{StatementID: 0, Series: models.Rows{
+				{Name: "cpu", Tags: map[string]string{"host": "web01"}, Columns: []string{"time", "value"}, Values: [][]interface{}{
+					{time.Unix(1356998400, 0), 1.5},
+					{time.Unix(1356998460, 0), 2.0},
+				}},
+				{Name: "cpu", Tags: map[string]string{"host": "web02"}, Columns: []string{"time", "value"}, Values: [][]interface{}{
+					{time.Unix(1356998400, 0), 3.0},
+				}},
+			}},
+			{StatementID: 1, Series: models.Rows{
+				{Name: "cpu", Columns: []string{"tagKey"}, Values: [][]interface{}{{"dc"}, {"host"}}},
+			}},
+		}
+	}
+
+	exp := `[{"metric":"cpu","tags":{"host":"web01"},"aggregateTags":["dc"],"dps":{"1356998400":1.5,"1356998460":2}},` +
+		`{"metric":"cpu","tags":{"host":"web02"},"aggregateTags":["dc"],"dps":{"1356998400":3}}]`
+
+	// Query with the parameters of a GET request.
+	params := url.Values{"start": {"1356998400"}, "end": {"1356998520"}, "m": {"sum:cpu{host=*}"}}
+	resp, err := http.Get("http://" + s.Service.Addr().String() + "/api/query?" + params.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, body := readResponse(t, resp); code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d: %s", code, body)
+	} else if body != exp {
+		t.Fatalf("unexpected body:\ngot %s\nexp %s", body, exp)
+	}
+
+	// Query with the JSON body of a POST request.
+	resp, err = http.Post("http://"+s.Service.Addr().String()+"/api/query", "application/json", strings.NewReader(
+		`{"start":1356998400,"end":"1356998520","queries":[{"aggregator":"sum","metric":"cpu","filters":[{"type":"wildcard","tagk":"host","filter":"*","groupBy":true}]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, body := readResponse(t, resp); code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d: %s", code, body)
+	} else if body != exp {
+		t.Fatalf("unexpected body:\ngot %s\nexp %s", body, exp)
+	}
+
+	// Invalid queries return an error in the OpenTSDB format.
+	resp, err = http.Get("http://" + s.Service.Addr().String() + "/api/query?start=1h-ago&m=foo:cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, body := readResponse(t, resp); code != http.StatusBadRequest {
+		t.Fatalf("unexpected status code: %d", code)
+	} else if exp := `{"error":{"code":400,"message":"unsupported aggregator: foo"}}`; body != exp {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+func TestService_HTTP_Suggest(t *testing.T) {
+	t.Parallel()
+
+	s := NewTestService("db0", "127.0.0.1:0")
+	s.Service.queryEnabled = true
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	s.ExecuteQueryFn = func(q *influxql.Query, opt query.ExecutionOptions) []*query.Result {
+		var series models.Rows
+		switch q.Statements[0].(type) {
+		case *influxql.ShowMeasurementsStatement:
+			series = models.Rows{{Name: "measurements", Columns: []string{"name"}, Values: [][]interface{}{{"mem"}, {"sys.cpu.nice"}, {"sys.cpu.user"}}}}
+		case *influxql.ShowTagValuesStatement:
+			series = models.Rows{
+				{Name: "cpu", Columns: []string{"key", "value"}, Values: [][]interface{}{{"host", "web01"}, {"host", "web02"}}},
+				{Name: "mem", Columns: []string{"key", "value"}, Values: [][]interface{}{{"host", "web02"}, {"dc", "lga"}}},
+			}
+		default:
+			t.Errorf("unexpected query: %s", q)
+		}
+		return []*query.Result{{Series: series}}
+	}
+
+	for _, tt := range []struct {
+		method string
+		params string
+		body   string
+		exp    string
+	}{
+		{method: "GET", params: "type=metrics&q=sys", exp: `["sys.cpu.nice","sys.cpu.user"]`},
+		{method: "GET", params: "type=metrics&max=1", exp: `["mem"]`},
+		{method: "POST", body: `{"type":"tagv","q":"web"}`, exp: `["web01","web02"]`},
+		{method: "GET", params: "type=tagv&q=x", exp: `[]`},
+	} {
+		req, err := http.NewRequest(tt.method, "http://"+s.Service.Addr().String()+"/api/suggest?"+tt.params, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code, body := readResponse(t, resp); code != http.StatusOK {
+			t.Fatalf("unexpected status code: %d: %s", code, body)
+		} else if body != tt.exp {
+			t.Fatalf("%s %s%s: unexpected body: %s", tt.method, tt.params, tt.body, body)
+		}
+	}
+
+	resp, err := http.Get("http://" + s.Service.Addr().String() + "/api/suggest?type=foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := readResponse(t, resp); code != http.StatusBadRequest {
+		t.Fatalf("unexpected status code: %d", code)
+	}
+}
+
+func TestService_HTTP_QueryAuth(t *testing.T) {
+	t.Parallel()
+
+	// The endpoints are disabled by default.
+	s := NewTestService("db0", "127.0.0.1:0")
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get("http://" + s.Service.Addr().String() + "/api/suggest?type=metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := readResponse(t, resp); code != http.StatusNotImplemented {
+		t.Fatalf("unexpected status code: %d", code)
+	}
+	s.Service.Close()
+
+	// The endpoints cannot be enabled with authentication if the credentials
+	// cannot be checked.
+	s = NewTestService("db0", "127.0.0.1:0")
+	s.Service.queryEnabled = true
+	s.Service.AuthEnabled = true
+	if err := s.Service.Open(); err == nil {
+		s.Service.Close()
+		t.Fatal("expected error opening service")
+	}
+
+	s = NewTestService("db0", "127.0.0.1:0")
+	s.Service.queryEnabled = true
+	s.Service.AuthEnabled = true
+	s.Service.Authenticator = authenticator(func(username, password string) (meta.User, error) {
+		if password != "secret" {
+			return nil, meta.ErrAuthenticate
+		}
+		return &meta.UserInfo{Name: username}, nil
+	})
+	s.Service.QueryAuthorizer = queryAuthorizer(func(u meta.User, q *influxql.Query, database string) (query.FineAuthorizer, error) {
+		if u.ID() != "reader" || database != "db0" {
+			return nil, meta.ErrAuthorize{Query: q, User: u.ID(), Database: database}
+		}
+		return u, nil
+	})
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	s.ExecuteQueryFn = func(q *influxql.Query, opt query.ExecutionOptions) []*query.Result {
+		if u, ok := opt.Authorizer.(meta.User); !ok || u.ID() != "reader" {
+			t.Errorf("unexpected authorizer: %v", opt.Authorizer)
+		}
+		return []*query.Result{{Series: models.Rows{{Name: "measurements", Columns: []string{"name"}, Values: [][]interface{}{{"cpu"}}}}}}
+	}
+
+	for _, tt := range []struct {
+		user, password string
+		code           int
+	}{
+		{code: http.StatusUnauthorized},
+		{user: "reader", password: "wrong", code: http.StatusUnauthorized},
+		{user: "writer", password: "secret", code: http.StatusForbidden},
+		{user: "reader", password: "secret", code: http.StatusOK},
+	} {
+		req, err := http.NewRequest("GET", "http://"+s.Service.Addr().String()+"/api/suggest?type=metrics", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.user != "" {
+			req.SetBasicAuth(tt.user, tt.password)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code, body := readResponse(t, resp); code != tt.code {
+			t.Fatalf("%s: unexpected status code: %d: %s", tt.user, code, body)
+		}
+	}
+}
+
+type authenticator func(username, password string) (meta.User, error)
+
+func (f authenticator) Authenticate(username, password string) (meta.User, error) {
+	return f(username, password)
+}
+
+type queryAuthorizer func(u meta.User, q *influxql.Query, database string) (query.FineAuthorizer, error)
+
+func (f queryAuthorizer) AuthorizeQuery(u meta.User, q *influxql.Query, database string) (query.FineAuthorizer, error) {
+	return f(u, q, database)
+}
+
+func readResponse(t *testing.T, resp *http.Response) (int, string) {
+	t.Helper()
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, strings.TrimSpace(string(b))
+}
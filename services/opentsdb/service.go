@@ -5,6 +5,7 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/tls"
+	"errors"
 	"io"
 	"net"
 	"net/http"
@@ -17,8 +18,10 @@ import (
 
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
 	"go.uber.org/zap"
 )
 
@@ -66,6 +69,22 @@ type Service struct {
 	MetaClient interface {
 		CreateDatabase(name string) (*meta.DatabaseInfo, error)
 	}
+	QueryExecutor interface {
+		ExecuteQuery(query *influxql.Query, opt query.ExecutionOptions, closing chan struct{}) <-chan *query.Result
+	}
+
+	// AuthEnabled requires the queries of the /api/query and /api/suggest
+	// endpoints to be authorized for the user whose credentials are in the
+	// requests. The service does not open if the endpoints are enabled but
+	// cannot check the credentials.
+	AuthEnabled   bool
+	Authenticator interface {
+		Authenticate(username, password string) (meta.User, error)
+	}
+	QueryAuthorizer interface {
+		AuthorizeQuery(u meta.User, query *influxql.Query, database string) (query.FineAuthorizer, error)
+	}
+	queryEnabled bool
 
 	// Points received over the telnet protocol are batched.
 	batchSize    int
@@ -97,6 +116,7 @@ func NewService(c Config) (*Service, error) {
 		batchTimeout:    time.Duration(d.BatchTimeout),
 		Logger:          zap.NewNop(),
 		LogPointErrors:  d.LogPointErrors,
+		queryEnabled:    d.QueryEnabled,
 		stats:           &Statistics{},
 		defaultTags:     models.StatisticTags{"bind": d.BindAddress},
 	}
@@ -115,6 +135,9 @@ func (s *Service) Open() error {
 	if s.done != nil {
 		return nil // Already open.
 	}
+	if s.queryEnabled && s.AuthEnabled && (s.Authenticator == nil || s.QueryAuthorizer == nil) {
+		return errors.New("opentsdb: query endpoints cannot check credentials with authentication enabled")
+	}
 	s.done = make(chan struct{})
 
 	s.Logger.Info("Starting OpenTSDB service")
@@ -456,9 +479,15 @@ func (s *Service) serveHTTP() {
 		Database:        s.Database,
 		RetentionPolicy: s.RetentionPolicy,
 		PointsWriter:    s.PointsWriter,
+		AuthEnabled:     s.AuthEnabled,
+		Authenticator:   s.Authenticator,
+		QueryAuthorizer: s.QueryAuthorizer,
 		Logger:          s.Logger,
 		stats:           s.stats,
 	}
+	if s.queryEnabled {
+		handler.QueryExecutor = s.QueryExecutor
+	}
 	srv := &http.Server{Handler: handler}
 	srv.Serve(s.httpln)
 }
@@ -3,6 +3,7 @@ package opentsdb
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"os"
@@ -16,7 +17,9 @@ import (
 	"github.com/influxdata/influxdb/internal"
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
 )
 
 func Test_Service_OpenClose(t *testing.T) {
@@ -248,10 +251,79 @@ func TestService_HTTP(t *testing.T) {
 	}
 }
 
+func TestService_HTTP_PutDetails(t *testing.T) {
+	t.Parallel()
+
+	s := NewTestService("db0", "127.0.0.1:0")
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	var written int
+	s.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		written += len(points)
+		return nil
+	}
+
+	body := `[{"metric":"sys.cpu.nice", "timestamp":1346846400, "value":18, "tags":{"host":"web01"}},` +
+		`{"metric":"", "timestamp":1346846400, "value":1},` +
+		`{"metric":"sys.cpu.nice", "timestamp":"now", "value":1}]`
+
+	for _, tt := range []struct {
+		params string
+		code   int
+		body   string
+	}{
+		{params: "", code: http.StatusBadRequest},
+		{params: "?summary", code: http.StatusBadRequest, body: `{"failed":2,"success":1}`},
+		{params: "?details", code: http.StatusBadRequest, body: `{"errors":[{"datapoint":{"metric":"","timestamp":1346846400,"value":1},"error":"metric name was empty"},{"datapoint":{"metric":"sys.cpu.nice","timestamp":"now","value":1},"error":"json object decode error: json: cannot unmarshal string into Go struct field point.timestamp of type int64"}],"failed":2,"success":1}`},
+	} {
+		resp, err := http.Post("http://"+s.Service.Addr().String()+"/api/put"+tt.params, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.code {
+			t.Fatalf("%q: unexpected status code: %d", tt.params, resp.StatusCode)
+		} else if tt.body != "" && strings.TrimSpace(string(b)) != tt.body {
+			t.Fatalf("%q: unexpected body: %s", tt.params, b)
+		}
+	}
+
+	// Points are only written if the summary or details are requested.
+	if written != 2 {
+		t.Fatalf("unexpected number of points written: %d", written)
+	}
+
+	// The invalid points of the requests that were not rejected are counted
+	// as dropped.
+	if n := s.Service.Statistics(nil)[0].Values["droppedPointsInvalid"]; n != int64(4) {
+		t.Fatalf("unexpected number of points dropped: %v", n)
+	}
+
+	// All points are valid.
+	resp, err := http.Post("http://"+s.Service.Addr().String()+"/api/put?summary", "application/json", strings.NewReader(`{"metric":"sys.cpu.nice", "timestamp":1346846400, "value":18}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", resp.StatusCode)
+	} else if got, exp := strings.TrimSpace(string(b)), `{"failed":0,"success":1}`; got != exp {
+		t.Fatalf("unexpected body: %s", got)
+	}
+}
+
 type TestService struct {
 	Service       *Service
 	MetaClient    *internal.MetaClientMock
 	WritePointsFn func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error
+
+	ExecuteQueryFn func(q *influxql.Query, opt query.ExecutionOptions) []*query.Result
 }
 
 // NewTestService returns a new instance of Service.
@@ -284,9 +356,20 @@ func NewTestService(database string, bind string) *TestService {
 
 	service.Service.MetaClient = service.MetaClient
 	service.Service.PointsWriter = service
+	service.Service.QueryExecutor = service
 	return service
 }
 
 func (s *TestService) WritePointsPrivileged(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
 	return s.WritePointsFn(database, retentionPolicy, consistencyLevel, points)
 }
+
+func (s *TestService) ExecuteQuery(q *influxql.Query, opt query.ExecutionOptions, closing chan struct{}) <-chan *query.Result {
+	results := s.ExecuteQueryFn(q, opt)
+	ch := make(chan *query.Result, len(results))
+	for _, r := range results {
+		ch <- r
+	}
+	close(ch)
+	return ch
+}
@@ -0,0 +1,208 @@
+package statsd
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// metricTypeTag is the tag holding the type of the metric of a point.
+const metricTypeTag = "metric_type"
+
+// series identifies the points of a metric.
+type series struct {
+	name string
+	tags models.Tags
+}
+
+// aggregator aggregates the values of the metrics received between flushes.
+type aggregator struct {
+	percentiles     []float64
+	percentileLimit int
+	deleteGauges    bool
+
+	mu       sync.Mutex
+	counters map[string]*counter
+	gauges   map[string]*gauge
+	sets     map[string]*set
+	timers   map[string]*timer
+	rand     *rand.Rand
+}
+
+type counter struct {
+	series
+	value float64
+}
+
+type gauge struct {
+	series
+	value float64
+}
+
+type set struct {
+	series
+	values map[string]struct{}
+}
+
+// timer keeps the statistics of the values of a timer and a random sample
+// of the values to compute the percentiles.
+type timer struct {
+	series
+	count    float64 // The number of values, scaled by the sample rate.
+	n        int     // The number of values received.
+	sum      float64
+	min, max float64
+	mean, m2 float64 // The running mean and sum of squared differences.
+	samples  []float64
+}
+
+func newAggregator(percentiles []float64, percentileLimit int, deleteGauges bool) *aggregator {
+	return &aggregator{
+		percentiles:     percentiles,
+		percentileLimit: percentileLimit,
+		deleteGauges:    deleteGauges,
+		counters:        make(map[string]*counter),
+		gauges:          make(map[string]*gauge),
+		sets:            make(map[string]*set),
+		timers:          make(map[string]*timer),
+		rand:            rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// add aggregates the value of a metric.
+func (a *aggregator) add(m metric) {
+	tags := models.NewTags(m.tags)
+	key := string(models.MakeKey([]byte(m.name), tags))
+	s := series{name: m.name, tags: tags}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch m.typ {
+	case typeCounter:
+		c := a.counters[key]
+		if c == nil {
+			c = &counter{series: s}
+			a.counters[key] = c
+		}
+		c.value += m.value / m.rate
+	case typeGauge:
+		g := a.gauges[key]
+		if g == nil {
+			g = &gauge{series: s}
+			a.gauges[key] = g
+		}
+		if m.delta {
+			g.value += m.value
+		} else {
+			g.value = m.value
+		}
+	case typeSet:
+		st := a.sets[key]
+		if st == nil {
+			st = &set{series: s, values: make(map[string]struct{})}
+			a.sets[key] = st
+		}
+		st.values[m.setValue] = struct{}{}
+	case typeTimer:
+		t := a.timers[key]
+		if t == nil {
+			t = &timer{series: s, min: m.value, max: m.value}
+			a.timers[key] = t
+		}
+		a.addTimerValue(t, m.value, m.rate)
+	}
+}
+
+func (a *aggregator) addTimerValue(t *timer, v, rate float64) {
+	t.count += 1 / rate
+	t.n++
+	t.sum += v
+	t.min = math.Min(t.min, v)
+	t.max = math.Max(t.max, v)
+	delta := v - t.mean
+	t.mean += delta / float64(t.n)
+	t.m2 += delta * (v - t.mean)
+
+	// Reservoir sampling keeps a uniform sample of the values.
+	if len(t.samples) < a.percentileLimit {
+		t.samples = append(t.samples, v)
+	} else if i := a.rand.Intn(t.n); i < len(t.samples) {
+		t.samples[i] = v
+	}
+}
+
+// flush returns the points of the metrics aggregated since the last flush at
+// time ts and resets the aggregates. Gauges keep their value unless they are
+// deleted at each flush.
+func (a *aggregator) flush(ts time.Time) []models.Point {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	points := make([]models.Point, 0, len(a.counters)+len(a.gauges)+len(a.sets)+len(a.timers))
+	add := func(s series, typ string, fields models.Fields) {
+		tags := s.tags.Clone()
+		tags.Set([]byte(metricTypeTag), []byte(typ))
+		if pt, err := models.NewPoint(s.name, tags, fields, ts); err == nil {
+			points = append(points, pt)
+		}
+	}
+
+	for _, c := range a.counters {
+		add(c.series, "counter", models.Fields{"value": c.value})
+	}
+	for _, g := range a.gauges {
+		add(g.series, "gauge", models.Fields{"value": g.value})
+	}
+	for _, s := range a.sets {
+		add(s.series, "set", models.Fields{"value": int64(len(s.values))})
+	}
+	for _, t := range a.timers {
+		fields := models.Fields{
+			"count":  t.count,
+			"sum":    t.sum,
+			"lower":  t.min,
+			"upper":  t.max,
+			"mean":   t.mean,
+			"stddev": math.Sqrt(t.m2 / float64(t.n)),
+		}
+		sort.Float64s(t.samples)
+		for _, p := range a.percentiles {
+			fields[percentileField(p)] = percentile(t.samples, p)
+		}
+		add(t.series, "timer", fields)
+	}
+
+	a.counters = make(map[string]*counter)
+	a.sets = make(map[string]*set)
+	a.timers = make(map[string]*timer)
+	if a.deleteGauges {
+		a.gauges = make(map[string]*gauge)
+	}
+	return points
+}
+
+// percentileField returns the name of the field of a percentile, such as p90
+// or p99_9.
+func percentileField(p float64) string {
+	return "p" + strings.Replace(strconv.FormatFloat(p, 'f', -1, 64), ".", "_", 1)
+}
+
+// percentile returns the percentile of the sorted values using the nearest
+// rank method.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p/100*float64(len(values)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return values[i]
+}
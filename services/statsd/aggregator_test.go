@@ -0,0 +1,94 @@
+package statsd
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestAggregator_Flush(t *testing.T) {
+	a := newAggregator([]float64{50, 90, 99.9}, 1000, false)
+	for _, line := range []string{
+		"hits:1|c",
+		"hits:2|c|@0.5",
+		"hits,host=a:1|c",
+		"temp:10|g",
+		"temp:-3|g",
+		"users:alice|s",
+		"users:bob|s",
+		"users:alice|s",
+		"latency:1|ms:2|ms:3|ms:4|ms",
+		"latency:10|ms|@0.5",
+	} {
+		metrics, err := parseLine(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range metrics {
+			a.add(m)
+		}
+	}
+
+	exp := []string{
+		"hits,host=a,metric_type=counter value=1 1000000000",
+		"hits,metric_type=counter value=5 1000000000",
+		"latency,metric_type=timer count=6,lower=1,mean=4,p50=3,p90=10,p99_9=10,stddev=3.1622776601683795,sum=20,upper=10 1000000000",
+		"temp,metric_type=gauge value=7 1000000000",
+		"users,metric_type=set value=2i 1000000000",
+	}
+	if got := flushStrings(a, time.Unix(1, 0)); !equalStrings(got, exp) {
+		t.Fatalf("unexpected points:\ngot %v\nexp %v", got, exp)
+	}
+
+	// Only gauges are kept after a flush.
+	exp = []string{"temp,metric_type=gauge value=7 2000000000"}
+	if got := flushStrings(a, time.Unix(2, 0)); !equalStrings(got, exp) {
+		t.Fatalf("unexpected points:\ngot %v\nexp %v", got, exp)
+	}
+
+	// Gauges are deleted at each flush if configured.
+	a = newAggregator(nil, 1000, true)
+	metrics, _ := parseLine("temp:10|g")
+	a.add(metrics[0])
+	if got := flushStrings(a, time.Unix(1, 0)); len(got) != 1 {
+		t.Fatalf("unexpected points: %v", got)
+	}
+	if got := flushStrings(a, time.Unix(2, 0)); len(got) != 0 {
+		t.Fatalf("unexpected points: %v", got)
+	}
+}
+
+func TestAggregator_PercentileLimit(t *testing.T) {
+	a := newAggregator([]float64{100}, 10, false)
+	for i := 0; i < 1000; i++ {
+		a.add(metric{name: "latency", typ: typeTimer, value: float64(i), rate: 1})
+	}
+
+	timer := a.timers["latency"]
+	if len(timer.samples) != 10 {
+		t.Fatalf("unexpected number of samples: %d", len(timer.samples))
+	} else if timer.n != 1000 || timer.sum != 499500 || timer.max != 999 {
+		t.Fatalf("unexpected statistics: n=%d sum=%v max=%v", timer.n, timer.sum, timer.max)
+	}
+}
+
+func flushStrings(a *aggregator, ts time.Time) []string {
+	var lines []string
+	for _, pt := range a.flush(ts) {
+		lines = append(lines, pt.String())
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
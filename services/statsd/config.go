@@ -0,0 +1,157 @@
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/toml"
+)
+
+const (
+	// DefaultBindAddress is the default binding interface if none is specified.
+	DefaultBindAddress = ":8125"
+
+	// DefaultDatabase is the default database for StatsD metrics.
+	DefaultDatabase = "statsd"
+
+	// DefaultRetentionPolicy is the default retention policy used for writes.
+	DefaultRetentionPolicy = ""
+
+	// DefaultFlushInterval is the default interval at which the aggregated
+	// metrics are written.
+	DefaultFlushInterval = 10 * time.Second
+
+	// DefaultPercentileLimit is the default number of values of a timer kept
+	// to compute its percentiles.
+	DefaultPercentileLimit = 1000
+
+	// DefaultBatchSize is the default StatsD batch size.
+	DefaultBatchSize = 5000
+
+	// DefaultBatchPending is the default number of pending StatsD batches.
+	DefaultBatchPending = 10
+
+	// DefaultBatchTimeout is the default StatsD batch timeout.
+	DefaultBatchTimeout = time.Second
+
+	// DefaultReadBuffer is the default buffer size for the UDP listener.
+	// 0 means to use the OS default.
+	DefaultReadBuffer = 0
+)
+
+// DefaultPercentiles are the default percentiles computed for timers.
+var DefaultPercentiles = []float64{90}
+
+// Config holds various configuration settings for the StatsD listener.
+type Config struct {
+	Enabled     bool   `toml:"enabled"`
+	BindAddress string `toml:"bind-address"`
+
+	Database        string        `toml:"database"`
+	RetentionPolicy string        `toml:"retention-policy"`
+	FlushInterval   toml.Duration `toml:"flush-interval"`
+	Percentiles     []float64     `toml:"percentiles"`
+	PercentileLimit int           `toml:"percentile-limit"`
+	DeleteGauges    bool          `toml:"delete-gauges"`
+	BatchSize       int           `toml:"batch-size"`
+	BatchPending    int           `toml:"batch-pending"`
+	BatchTimeout    toml.Duration `toml:"batch-timeout"`
+	ReadBuffer      int           `toml:"read-buffer"`
+}
+
+// NewConfig returns a new instance of Config with defaults.
+func NewConfig() Config {
+	return Config{
+		BindAddress:     DefaultBindAddress,
+		Database:        DefaultDatabase,
+		RetentionPolicy: DefaultRetentionPolicy,
+		FlushInterval:   toml.Duration(DefaultFlushInterval),
+		Percentiles:     append([]float64(nil), DefaultPercentiles...),
+		PercentileLimit: DefaultPercentileLimit,
+		BatchSize:       DefaultBatchSize,
+		BatchPending:    DefaultBatchPending,
+		BatchTimeout:    toml.Duration(DefaultBatchTimeout),
+		ReadBuffer:      DefaultReadBuffer,
+	}
+}
+
+// WithDefaults takes the given config and returns a new config with any required
+// default values set.
+func (c *Config) WithDefaults() *Config {
+	d := *c
+	if d.BindAddress == "" {
+		d.BindAddress = DefaultBindAddress
+	}
+	if d.Database == "" {
+		d.Database = DefaultDatabase
+	}
+	if d.FlushInterval == 0 {
+		d.FlushInterval = toml.Duration(DefaultFlushInterval)
+	}
+	if d.Percentiles == nil {
+		d.Percentiles = append([]float64(nil), DefaultPercentiles...)
+	}
+	if d.PercentileLimit == 0 {
+		d.PercentileLimit = DefaultPercentileLimit
+	}
+	if d.BatchSize == 0 {
+		d.BatchSize = DefaultBatchSize
+	}
+	if d.BatchPending == 0 {
+		d.BatchPending = DefaultBatchPending
+	}
+	if d.BatchTimeout == 0 {
+		d.BatchTimeout = toml.Duration(DefaultBatchTimeout)
+	}
+	return &d
+}
+
+// Validate returns an error if the Config is invalid.
+func (c *Config) Validate() error {
+	if c.FlushInterval < 0 {
+		return errors.New("flush-interval must not be negative")
+	}
+	if c.PercentileLimit < 0 {
+		return errors.New("percentile-limit must not be negative")
+	}
+	for _, p := range c.Percentiles {
+		if p <= 0 || p > 100 {
+			return fmt.Errorf("invalid percentile %v: must be greater than 0 and at most 100", p)
+		}
+	}
+	return nil
+}
+
+// Configs wraps a slice of Config to aggregate diagnostics.
+type Configs []Config
+
+// Diagnostics returns one set of diagnostics for all of the Configs.
+func (c Configs) Diagnostics() (*diagnostics.Diagnostics, error) {
+	d := &diagnostics.Diagnostics{
+		Columns: []string{"enabled", "bind-address", "database", "retention-policy", "flush-interval", "percentiles", "batch-size", "batch-pending", "batch-timeout"},
+	}
+
+	for _, cc := range c {
+		if !cc.Enabled {
+			d.AddRow([]interface{}{false})
+			continue
+		}
+
+		r := []interface{}{true, cc.BindAddress, cc.Database, cc.RetentionPolicy, cc.FlushInterval, fmt.Sprint(cc.Percentiles), cc.BatchSize, cc.BatchPending, cc.BatchTimeout}
+		d.AddRow(r)
+	}
+
+	return d, nil
+}
+
+// Enabled returns true if any underlying Config is Enabled.
+func (c Configs) Enabled() bool {
+	for _, cc := range c {
+		if cc.Enabled {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package statsd_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/influxdata/influxdb/services/statsd"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	// Parse configuration.
+	var c statsd.Config
+	if _, err := toml.Decode(`
+enabled = true
+bind-address = ":4444"
+database = "awesomedb"
+retention-policy = "awesomerp"
+flush-interval = "5s"
+percentiles = [50.0, 99.9]
+percentile-limit = 100
+delete-gauges = true
+batch-size = 100
+batch-pending = 9
+batch-timeout = "10ms"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate configuration.
+	if !c.Enabled {
+		t.Fatalf("unexpected enabled: %v", c.Enabled)
+	} else if c.BindAddress != ":4444" {
+		t.Fatalf("unexpected bind address: %s", c.BindAddress)
+	} else if c.Database != "awesomedb" {
+		t.Fatalf("unexpected database: %s", c.Database)
+	} else if c.RetentionPolicy != "awesomerp" {
+		t.Fatalf("unexpected retention policy: %s", c.RetentionPolicy)
+	} else if time.Duration(c.FlushInterval) != 5*time.Second {
+		t.Fatalf("unexpected flush interval: %v", c.FlushInterval)
+	} else if !reflect.DeepEqual(c.Percentiles, []float64{50, 99.9}) {
+		t.Fatalf("unexpected percentiles: %v", c.Percentiles)
+	} else if c.PercentileLimit != 100 {
+		t.Fatalf("unexpected percentile limit: %d", c.PercentileLimit)
+	} else if !c.DeleteGauges {
+		t.Fatalf("unexpected delete gauges: %v", c.DeleteGauges)
+	} else if c.BatchSize != 100 {
+		t.Fatalf("unexpected batch size: %d", c.BatchSize)
+	} else if c.BatchPending != 9 {
+		t.Fatalf("unexpected batch pending: %d", c.BatchPending)
+	} else if time.Duration(c.BatchTimeout) != (10 * time.Millisecond) {
+		t.Fatalf("unexpected batch timeout: %v", c.BatchTimeout)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := statsd.NewConfig()
+	c.Percentiles = []float64{0}
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for percentile 0")
+	}
+
+	c = statsd.NewConfig()
+	c.Percentiles = []float64{100.5}
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for percentile over 100")
+	}
+}
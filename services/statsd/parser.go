@@ -0,0 +1,169 @@
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Types of StatsD metrics.
+const (
+	typeCounter = "c"
+	typeGauge   = "g"
+	typeSet     = "s"
+	typeTimer   = "ms"
+
+	// Histograms and DogStatsD distributions are aggregated as timers.
+	typeHistogram    = "h"
+	typeDistribution = "d"
+)
+
+// metric is a single value of a StatsD metric.
+type metric struct {
+	name string
+	tags map[string]string
+	typ  string
+
+	value    float64
+	setValue string  // The value of a set.
+	delta    bool    // Is the value of a gauge a change of its current value?
+	rate     float64 // The sample rate of a counter or timer.
+}
+
+// parseLine parses a line of the StatsD protocol, such as
+//
+//	api.requests,region=eu:1|c|@0.5|#env:prod,service:web
+//
+// Tags are either appended to the bucket name as in the InfluxDB line
+// protocol, or appended as DogStatsD tags after a "#". A line may hold several
+// values of the bucket separated by colons, as long as it has no DogStatsD tags.
+func parseLine(line string) ([]metric, error) {
+	i := strings.IndexByte(line, ':')
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid line %q: missing bucket or value", line)
+	}
+	bucket, rest := line[:i], line[i+1:]
+
+	name, tags, err := parseBucket(bucket)
+	if err != nil {
+		return nil, fmt.Errorf("invalid line %q: %s", line, err)
+	}
+
+	values := []string{rest}
+	if !strings.Contains(rest, "|#") {
+		values = strings.Split(rest, ":")
+	}
+
+	metrics := make([]metric, 0, len(values))
+	for _, v := range values {
+		m, err := parseValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %q: %s", line, err)
+		}
+		m.name = name
+		if len(m.tags) == 0 {
+			m.tags = tags
+		} else {
+			for k, v := range tags {
+				if _, ok := m.tags[k]; !ok {
+					m.tags[k] = v
+				}
+			}
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+// parseBucket splits a bucket name such as cpu,host=a into the name and tags.
+func parseBucket(bucket string) (string, map[string]string, error) {
+	parts := strings.Split(bucket, ",")
+	if parts[0] == "" {
+		return "", nil, errors.New("empty bucket name")
+	}
+	var tags map[string]string
+	for _, kv := range parts[1:] {
+		i := strings.IndexByte(kv, '=')
+		if i <= 0 || i == len(kv)-1 {
+			return "", nil, fmt.Errorf("invalid tag %q", kv)
+		}
+		if tags == nil {
+			tags = make(map[string]string, len(parts)-1)
+		}
+		tags[kv[:i]] = kv[i+1:]
+	}
+	return parts[0], tags, nil
+}
+
+// parseValue parses a value such as 1|c|@0.5|#env:prod.
+func parseValue(s string) (metric, error) {
+	m := metric{rate: 1}
+
+	fields := strings.Split(s, "|")
+	if len(fields) < 2 {
+		return m, errors.New("missing metric type")
+	}
+	value, typ := fields[0], fields[1]
+	if value == "" {
+		return m, errors.New("missing value")
+	}
+
+	switch typ {
+	case typeCounter, typeGauge, typeTimer:
+	case typeHistogram, typeDistribution:
+		typ = typeTimer
+	case typeSet:
+		m.setValue = value
+	default:
+		return m, fmt.Errorf("unknown metric type %q", typ)
+	}
+	m.typ = typ
+
+	if typ != typeSet {
+		if typ == typeGauge && (value[0] == '+' || value[0] == '-') {
+			m.delta = true
+		}
+		// Non-finite values cannot be written as fields.
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return m, fmt.Errorf("invalid value %q", value)
+		}
+		m.value = v
+	}
+
+	for _, f := range fields[2:] {
+		switch {
+		case strings.HasPrefix(f, "@"):
+			rate, err := strconv.ParseFloat(f[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return m, fmt.Errorf("invalid sample rate %q", f[1:])
+			}
+			if typ == typeCounter || typ == typeTimer {
+				m.rate = rate
+			}
+		case strings.HasPrefix(f, "#"):
+			m.tags = parseDogStatsDTags(f[1:])
+		}
+		// Other extensions, such as DogStatsD container IDs, are ignored.
+	}
+	return m, nil
+}
+
+// parseDogStatsDTags parses DogStatsD tags such as env:prod,service:web.
+// Tags without a value are set to "true".
+func parseDogStatsDTags(s string) map[string]string {
+	tags := make(map[string]string)
+	for _, tag := range strings.Split(s, ",") {
+		if tag == "" {
+			continue
+		}
+		if i := strings.IndexByte(tag, ':'); i > 0 && i < len(tag)-1 {
+			tags[tag[:i]] = tag[i+1:]
+		} else if i < 0 {
+			tags[tag] = "true"
+		}
+	}
+	return tags
+}
@@ -0,0 +1,80 @@
+package statsd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLine(t *testing.T) {
+	for _, tt := range []struct {
+		line string
+		exp  []metric
+	}{
+		{
+			line: "hits:1|c",
+			exp:  []metric{{name: "hits", typ: typeCounter, value: 1, rate: 1}},
+		},
+		{
+			line: "hits:2|c|@0.1",
+			exp:  []metric{{name: "hits", typ: typeCounter, value: 2, rate: 0.1}},
+		},
+		{
+			line: "temp:-2.5|g",
+			exp:  []metric{{name: "temp", typ: typeGauge, value: -2.5, delta: true, rate: 1}},
+		},
+		{
+			line: "temp:+3|g|@0.5",
+			exp:  []metric{{name: "temp", typ: typeGauge, value: 3, delta: true, rate: 1}},
+		},
+		{
+			line: "users:alice|s",
+			exp:  []metric{{name: "users", typ: typeSet, setValue: "alice", rate: 1}},
+		},
+		{
+			line: "latency:320|ms:12|h",
+			exp: []metric{
+				{name: "latency", typ: typeTimer, value: 320, rate: 1},
+				{name: "latency", typ: typeTimer, value: 12, rate: 1},
+			},
+		},
+		{
+			line: "requests,region=eu:1|c|#env:prod,canary",
+			exp: []metric{{name: "requests", typ: typeCounter, value: 1, rate: 1, tags: map[string]string{
+				"region": "eu",
+				"env":    "prod",
+				"canary": "true",
+			}}},
+		},
+		{
+			line: "requests,env=dev:1|d|@0.5|#env:prod|c:abc123",
+			exp:  []metric{{name: "requests", typ: typeTimer, value: 1, rate: 0.5, tags: map[string]string{"env": "prod"}}},
+		},
+	} {
+		got, err := parseLine(tt.line)
+		if err != nil {
+			t.Errorf("%s: %s", tt.line, err)
+		} else if !reflect.DeepEqual(got, tt.exp) {
+			t.Errorf("%s:\ngot %+v\nexp %+v", tt.line, got, tt.exp)
+		}
+	}
+}
+
+func TestParseLine_Invalid(t *testing.T) {
+	for _, line := range []string{
+		"hits",
+		":1|c",
+		"hits:1",
+		"hits:|c",
+		"hits:1|x",
+		"hits:abc|c",
+		"hits:NaN|c",
+		"load:+Inf|g",
+		"latency:-inf|ms",
+		"hits:1|c|@2",
+		"hits,region:1|c",
+	} {
+		if _, err := parseLine(line); err == nil {
+			t.Errorf("%s: expected error", line)
+		}
+	}
+}
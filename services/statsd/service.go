@@ -0,0 +1,343 @@
+// Package statsd provides a service for InfluxDB to ingest metrics via the
+// StatsD protocol.
+package statsd // import "github.com/influxdata/influxdb/services/statsd"
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// MaxUDPPayload is largest payload size the StatsD service will accept.
+const MaxUDPPayload = 64 * 1024
+
+// statistics gathered by the StatsD package.
+const (
+	statMetricsReceived     = "metricsRx"
+	statBytesReceived       = "bytesRx"
+	statMetricsParseFail    = "metricsParseFail"
+	statReadFail            = "readFail"
+	statBatchesTransmitted  = "batchesTx"
+	statPointsTransmitted   = "pointsTx"
+	statBatchesTransmitFail = "batchesTxFail"
+)
+
+// Service is a UDP service that listens for StatsD metrics, aggregates them
+// and writes the aggregates at each flush interval.
+type Service struct {
+	conn *net.UDPConn
+	addr *net.UDPAddr
+	wg   sync.WaitGroup
+
+	mu    sync.RWMutex
+	ready bool          // Has the required database been created?
+	done  chan struct{} // Is the service closing or closed?
+
+	// The writer runs until the metrics aggregated when the service is
+	// closed are written.
+	writerDone chan struct{}
+	writerWG   sync.WaitGroup
+
+	aggregator *aggregator
+	batcher    *tsdb.PointBatcher
+	config     Config
+
+	PointsWriter interface {
+		WritePointsPrivileged(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error
+	}
+
+	MetaClient interface {
+		CreateDatabase(name string) (*meta.DatabaseInfo, error)
+	}
+
+	Logger      *zap.Logger
+	stats       *Statistics
+	defaultTags models.StatisticTags
+}
+
+// NewService returns a new instance of Service.
+func NewService(c Config) *Service {
+	d := *c.WithDefaults()
+	return &Service{
+		config:      d,
+		Logger:      zap.NewNop(),
+		stats:       &Statistics{},
+		defaultTags: models.StatisticTags{"bind": d.BindAddress},
+	}
+}
+
+// Open starts the service.
+func (s *Service) Open() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed() {
+		return nil // Already open.
+	}
+
+	if err := s.config.Validate(); err != nil {
+		return err
+	}
+	if s.config.Database == "" {
+		return errors.New("database has to be specified in config")
+	}
+
+	s.addr, err = net.ResolveUDPAddr("udp", s.config.BindAddress)
+	if err != nil {
+		s.Logger.Info("Failed to resolve UDP address",
+			zap.String("bind_address", s.config.BindAddress), zap.Error(err))
+		return err
+	}
+
+	s.conn, err = net.ListenUDP("udp", s.addr)
+	if err != nil {
+		s.Logger.Info("Failed to set up UDP listener",
+			zap.Stringer("addr", s.addr), zap.Error(err))
+		return err
+	}
+	s.addr = s.conn.LocalAddr().(*net.UDPAddr)
+
+	if s.config.ReadBuffer != 0 {
+		if err = s.conn.SetReadBuffer(s.config.ReadBuffer); err != nil {
+			s.Logger.Info("Failed to set UDP read buffer",
+				zap.Int("buffer_size", s.config.ReadBuffer), zap.Error(err))
+			s.conn.Close()
+			return err
+		}
+	}
+
+	s.done = make(chan struct{})
+	s.writerDone = make(chan struct{})
+	s.aggregator = newAggregator(s.config.Percentiles, s.config.PercentileLimit, s.config.DeleteGauges)
+	s.batcher = tsdb.NewPointBatcher(s.config.BatchSize, s.config.BatchPending, time.Duration(s.config.BatchTimeout))
+	s.batcher.Start()
+
+	s.Logger.Info("Started listening on UDP", zap.String("addr", s.config.BindAddress))
+
+	s.wg.Add(2)
+	go s.serve()
+	go s.flusher()
+	s.writerWG.Add(1)
+	go s.writer()
+
+	return nil
+}
+
+// Statistics maintains statistics for the StatsD service.
+type Statistics struct {
+	MetricsReceived     int64
+	BytesReceived       int64
+	MetricsParseFail    int64
+	ReadFail            int64
+	BatchesTransmitted  int64
+	PointsTransmitted   int64
+	BatchesTransmitFail int64
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (s *Service) Statistics(tags map[string]string) []models.Statistic {
+	return []models.Statistic{{
+		Name: "statsd",
+		Tags: s.defaultTags.Merge(tags),
+		Values: map[string]interface{}{
+			statMetricsReceived:     atomic.LoadInt64(&s.stats.MetricsReceived),
+			statBytesReceived:       atomic.LoadInt64(&s.stats.BytesReceived),
+			statMetricsParseFail:    atomic.LoadInt64(&s.stats.MetricsParseFail),
+			statReadFail:            atomic.LoadInt64(&s.stats.ReadFail),
+			statBatchesTransmitted:  atomic.LoadInt64(&s.stats.BatchesTransmitted),
+			statPointsTransmitted:   atomic.LoadInt64(&s.stats.PointsTransmitted),
+			statBatchesTransmitFail: atomic.LoadInt64(&s.stats.BatchesTransmitFail),
+		},
+	}}
+}
+
+func (s *Service) serve() {
+	defer s.wg.Done()
+
+	buf := make([]byte, MaxUDPPayload)
+	for {
+		n, _, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-s.done:
+				// We closed the connection, time to go.
+				return
+			default:
+			}
+			atomic.AddInt64(&s.stats.ReadFail, 1)
+			s.Logger.Info("Failed to read UDP message", zap.Error(err))
+			continue
+		}
+		atomic.AddInt64(&s.stats.BytesReceived, int64(n))
+		s.handlePacket(buf[:n])
+	}
+}
+
+// handlePacket aggregates the metrics of the lines of a packet.
+func (s *Service) handlePacket(buf []byte) {
+	for _, line := range bytes.Split(buf, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		metrics, err := parseLine(string(line))
+		if err != nil {
+			atomic.AddInt64(&s.stats.MetricsParseFail, 1)
+			s.Logger.Info("Failed to parse metric", zap.Error(err))
+			continue
+		}
+		for _, m := range metrics {
+			s.aggregator.add(m)
+		}
+		atomic.AddInt64(&s.stats.MetricsReceived, int64(len(metrics)))
+	}
+}
+
+// flusher sends the aggregated metrics to the batcher at each flush interval.
+func (s *Service) flusher() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(s.config.FlushInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			// The writer runs until the batcher is stopped, so sending to
+			// the batcher cannot block forever.
+			for _, pt := range s.aggregator.flush(now) {
+				s.batcher.In() <- pt
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *Service) writer() {
+	defer s.writerWG.Done()
+
+	for {
+		select {
+		case batch := <-s.batcher.Out():
+			// Will attempt to create database if not yet created.
+			if err := s.createInternalStorage(); err != nil {
+				s.Logger.Info("Required database does not yet exist",
+					logger.Database(s.config.Database), zap.Error(err))
+				continue
+			}
+
+			if err := s.PointsWriter.WritePointsPrivileged(s.config.Database, s.config.RetentionPolicy, models.ConsistencyLevelAny, batch); err == nil {
+				atomic.AddInt64(&s.stats.BatchesTransmitted, 1)
+				atomic.AddInt64(&s.stats.PointsTransmitted, int64(len(batch)))
+			} else {
+				s.Logger.Info("Failed to write point batch to database",
+					logger.Database(s.config.Database), zap.Error(err))
+				atomic.AddInt64(&s.stats.BatchesTransmitFail, 1)
+			}
+
+		case <-s.writerDone:
+			return
+		}
+	}
+}
+
+// Close closes the service and the underlying listener. The metrics
+// aggregated since the last flush are written before it returns.
+func (s *Service) Close() error {
+	if wait := func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.closed() {
+			return false // Already closed.
+		}
+		close(s.done)
+
+		if s.conn != nil {
+			s.conn.Close()
+		}
+		return true
+	}(); !wait {
+		return nil
+	}
+	s.wg.Wait()
+
+	// Flush the metrics aggregated since the last flush and stop the
+	// batcher, which emits its last batch, before stopping the writer.
+	for _, pt := range s.aggregator.flush(time.Now()) {
+		s.batcher.In() <- pt
+	}
+	s.batcher.Stop()
+	close(s.writerDone)
+	s.writerWG.Wait()
+
+	// Release all remaining resources.
+	s.mu.Lock()
+	s.done = nil
+	s.writerDone = nil
+	s.conn = nil
+	s.batcher = nil
+	s.aggregator = nil
+	s.mu.Unlock()
+
+	s.Logger.Info("Service closed")
+
+	return nil
+}
+
+// Closed returns true if the service is currently closed.
+func (s *Service) Closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed()
+}
+
+func (s *Service) closed() bool {
+	select {
+	case <-s.done:
+		// Service is closing.
+		return true
+	default:
+	}
+	return s.done == nil
+}
+
+// createInternalStorage ensures that the required database has been created.
+func (s *Service) createInternalStorage() error {
+	s.mu.RLock()
+	ready := s.ready
+	s.mu.RUnlock()
+	if ready {
+		return nil
+	}
+
+	if _, err := s.MetaClient.CreateDatabase(s.config.Database); err != nil {
+		return err
+	}
+
+	// The service is now ready.
+	s.mu.Lock()
+	s.ready = true
+	s.mu.Unlock()
+	return nil
+}
+
+// WithLogger sets the logger on the service.
+func (s *Service) WithLogger(log *zap.Logger) {
+	s.Logger = log.With(zap.String("service", "statsd"))
+}
+
+// Addr returns the listener's address.
+func (s *Service) Addr() net.Addr {
+	return s.addr
+}
@@ -0,0 +1,187 @@
+package statsd
+
+import (
+	"net"
+	"os"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/internal"
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/toml"
+)
+
+func TestService_OpenClose(t *testing.T) {
+	s := NewTestService(nil)
+
+	// Closing a closed service is fine.
+	if err := s.Service.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Opening an already open service is fine.
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening a previously opened service is fine.
+	if err := s.Service.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tidy up.
+	if err := s.Service.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_Flush(t *testing.T) {
+	t.Parallel()
+
+	s := NewTestService(nil)
+
+	written := make(chan []models.Point, 10)
+	s.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		if database != "statsd" {
+			t.Errorf("unexpected database: %s", database)
+		} else if retentionPolicy != "" {
+			t.Errorf("unexpected retention policy: %s", retentionPolicy)
+		}
+		written <- points
+		return nil
+	}
+
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	conn, err := net.Dial("udp", s.Service.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("hits:1|c\nhits:2|c|#host:a\nbad line\nload:0.5|g\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	timeout := time.After(5 * time.Second)
+	for len(got) < 3 {
+		select {
+		case points := <-written:
+			for _, pt := range points {
+				got = append(got, string(pt.Key()))
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for points, got %v", got)
+		}
+	}
+
+	sort.Strings(got)
+	exp := []string{"hits,host=a,metric_type=counter", "hits,metric_type=counter", "load,metric_type=gauge"}
+	if !equalStrings(got, exp) {
+		t.Fatalf("unexpected points:\ngot %v\nexp %v", got, exp)
+	}
+
+	stats := s.Service.Statistics(nil)[0].Values
+	if stats[statMetricsReceived] != int64(3) || stats[statMetricsParseFail] != int64(1) {
+		t.Fatalf("unexpected statistics: %v", stats)
+	}
+}
+
+// Ensure the metrics aggregated since the last flush are written on close.
+func TestService_Close_Flush(t *testing.T) {
+	t.Parallel()
+
+	c := NewConfig()
+	c.BindAddress = "127.0.0.1:0"
+	c.FlushInterval = toml.Duration(time.Hour)
+	c.BatchTimeout = toml.Duration(time.Hour)
+	s := NewTestService(&c)
+
+	var got []string
+	s.WritePointsFn = func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+		for _, pt := range points {
+			got = append(got, string(pt.Key()))
+		}
+		return nil
+	}
+
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.Dial("udp", s.Service.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("hits:1|c\nlatency:20|ms\n")); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; atomic.LoadInt64(&s.Service.stats.MetricsReceived) != 2; i++ {
+		if i == 5000 {
+			t.Fatal("timed out waiting for metrics")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := s.Service.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	if exp := []string{"hits,metric_type=counter", "latency,metric_type=timer"}; !equalStrings(got, exp) {
+		t.Fatalf("unexpected points:\ngot %v\nexp %v", got, exp)
+	}
+}
+
+type TestService struct {
+	Service       *Service
+	MetaClient    *internal.MetaClientMock
+	WritePointsFn func(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error
+}
+
+// NewTestService returns a new instance of Service listening on a random
+// port that flushes every 50ms.
+func NewTestService(c *Config) *TestService {
+	if c == nil {
+		defaultC := NewConfig()
+		defaultC.BindAddress = "127.0.0.1:0"
+		defaultC.FlushInterval = toml.Duration(50 * time.Millisecond)
+		defaultC.BatchTimeout = toml.Duration(10 * time.Millisecond)
+		c = &defaultC
+	}
+
+	service := &TestService{
+		Service:    NewService(*c),
+		MetaClient: &internal.MetaClientMock{},
+	}
+
+	service.MetaClient.CreateDatabaseFn = func(string) (*meta.DatabaseInfo, error) {
+		return nil, nil
+	}
+
+	if testing.Verbose() {
+		service.Service.WithLogger(logger.New(os.Stderr))
+	}
+
+	service.Service.MetaClient = service.MetaClient
+	service.Service.PointsWriter = service
+	return service
+}
+
+func (s *TestService) WritePointsPrivileged(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+	return s.WritePointsFn(database, retentionPolicy, consistencyLevel, points)
+}
@@ -2,6 +2,9 @@ package storage
 
 import (
 	"context"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/storage/reads"
 )
 
 type key int
@@ -27,3 +30,15 @@ func ReadOptionsFromContext(ctx context.Context) *ReadOptions {
 	opts, _ := ctx.Value(readOptionsKey).(*ReadOptions)
 	return opts
 }
+
+// NewContextWithAuthorizer returns a new Context limiting the series read from
+// the store to the series authorized by auth.
+func NewContextWithAuthorizer(ctx context.Context, auth query.FineAuthorizer) context.Context {
+	return reads.NewContextWithAuthorizer(ctx, auth)
+}
+
+// AuthorizerFromContext returns the authorizer associated with the context or
+// query.OpenAuthorizer if none has been specified.
+func AuthorizerFromContext(ctx context.Context) query.FineAuthorizer {
+	return reads.AuthorizerFromContext(ctx)
+}
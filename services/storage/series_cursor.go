@@ -24,6 +24,8 @@ var (
 
 type indexSeriesCursor struct {
 	sqry            tsdb.SeriesCursor
+	database        string
+	auth            query.FineAuthorizer // nil if all series are authorized
 	fields          measurementFields
 	nf              []field
 	field           field
@@ -55,11 +57,14 @@ func newIndexSeriesCursor(ctx context.Context, predicate *datatypes.Predicate, s
 
 	opt := query.IteratorOptions{
 		Aux:        []influxql.VarRef{{Val: "key"}},
-		Authorizer: query.OpenAuthorizer,
+		Authorizer: AuthorizerFromContext(ctx),
 		Ascending:  true,
 		Ordered:    true,
 	}
 	p := &indexSeriesCursor{row: reads.SeriesRow{Query: queries}}
+	if !query.AuthorizerIsOpen(opt.Authorizer) && len(shards) > 0 {
+		p.database, p.auth = shards[0].Database(), opt.Authorizer
+	}
 
 	if root := predicate.GetRoot(); root != nil {
 		if p.cond, err = reads.NodeToExpr(root, measurementRemap); err != nil {
@@ -111,7 +116,7 @@ func newIndexSeriesCursor(ctx context.Context, predicate *datatypes.Predicate, s
 		}
 
 		var mfkeys map[string][]string
-		mfkeys, err = sg.FieldKeysByPredicate(opt.Condition)
+		mfkeys, err = sg.FieldKeysByPredicate(opt.Authorizer, opt.Condition)
 		if err != nil {
 			goto CLEANUP
 		}
@@ -168,6 +173,8 @@ func (c *indexSeriesCursor) Next() *reads.SeriesRow {
 			} else if sr == nil {
 				c.Close()
 				return nil
+			} else if c.auth != nil && !c.auth.AuthorizeSeriesRead(c.database, sr.Name, sr.Tags) {
+				continue
 			}
 
 			c.row.Name = sr.Name
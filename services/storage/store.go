@@ -9,7 +9,6 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/types"
 	"github.com/influxdata/influxdb/models"
-	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/storage/reads"
 	"github.com/influxdata/influxdb/storage/reads/datatypes"
@@ -228,8 +227,7 @@ func (s *Store) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cur
 		}
 	}
 
-	// TODO(jsternberg): Use a real authorizer.
-	auth := query.OpenAuthorizer
+	auth := AuthorizerFromContext(ctx)
 	keys, err := s.TSDBStore.TagKeys(ctx, auth, shardIDs, expr)
 	if err != nil {
 		return nil, err
@@ -324,8 +322,7 @@ func (s *Store) TagValues(ctx context.Context, req *datatypes.TagValuesRequest)
 		expr = tagKeyExpr
 	}
 
-	// TODO(jsternberg): Use a real authorizer.
-	auth := query.OpenAuthorizer
+	auth := AuthorizerFromContext(ctx)
 	values, err := s.TSDBStore.TagValues(ctx, auth, shardIDs, expr)
 	if err != nil {
 		return nil, err
@@ -383,8 +380,7 @@ func (s *Store) MeasurementNames(ctx context.Context, req *MeasurementNamesReque
 		}
 	}
 
-	// TODO(jsternberg): Use a real authorizer.
-	auth := query.OpenAuthorizer
+	auth := AuthorizerFromContext(ctx)
 	values, err := s.TSDBStore.MeasurementNames(ctx, auth, database, expr)
 	if err != nil {
 		return nil, err
@@ -21,6 +21,18 @@ const (
 
 	// DefaultWriteBufferSize is the default write buffer size for a Config.
 	DefaultWriteBufferSize = 1000
+
+	// DefaultQueueMaxSize is the default maximum size of the queue of a
+	// subscription destination.
+	DefaultQueueMaxSize = 1024 * 1024 * 1024 // 1GB
+
+	// DefaultQueueRetryInterval is the default time to wait before retrying
+	// a failed write from the queue.
+	DefaultQueueRetryInterval = time.Second
+
+	// DefaultQueueMaxRetryInterval is the default maximum time to wait before
+	// retrying a failed write from the queue.
+	DefaultQueueMaxRetryInterval = time.Minute
 )
 
 // Config represents a configuration of the subscriber service.
@@ -44,6 +56,28 @@ type Config struct {
 	// The number of in-flight writes buffered in the write channel.
 	WriteBufferSize int `toml:"write-buffer-size"`
 
+	// The directory of the disk-backed queues of the subscription
+	// destinations. If empty, writes that fail are dropped.
+	QueueDir string `toml:"queue-dir"`
+
+	// The maximum size of the queue of each subscription destination.
+	QueueMaxSize toml.Size `toml:"queue-max-size"`
+
+	// The time to wait before retrying a failed write, doubled after each
+	// failure up to QueueMaxRetryInterval.
+	QueueRetryInterval    toml.Duration `toml:"queue-retry-interval"`
+	QueueMaxRetryInterval toml.Duration `toml:"queue-max-retry-interval"`
+
+	// Whether subscriptions may write to files on this host, and the
+	// directories the files must be in.
+	FileDestinationsEnabled bool     `toml:"file-destinations-enabled"`
+	FileDestinationDirs     []string `toml:"file-destination-dirs"`
+
+	// Whether subscriptions may run commands on this host, and the absolute
+	// paths of the commands they may run.
+	ExecDestinationsEnabled bool     `toml:"exec-destinations-enabled"`
+	ExecDestinationCommands []string `toml:"exec-destination-commands"`
+
 	// TLS is a base tls config to use for https clients.
 	TLS *tls.Config `toml:"-"`
 }
@@ -57,6 +91,10 @@ func NewConfig() Config {
 		CaCerts:            "",
 		WriteConcurrency:   DefaultWriteConcurrency,
 		WriteBufferSize:    DefaultWriteBufferSize,
+
+		QueueMaxSize:          DefaultQueueMaxSize,
+		QueueRetryInterval:    toml.Duration(DefaultQueueRetryInterval),
+		QueueMaxRetryInterval: toml.Duration(DefaultQueueMaxRetryInterval),
 	}
 }
 
@@ -82,6 +120,40 @@ func (c Config) Validate() error {
 		return errors.New("write-concurrency must be greater than 0")
 	}
 
+	if c.QueueDir != "" {
+		if c.QueueMaxSize <= 0 {
+			return errors.New("queue-max-size must be greater than 0")
+		}
+		if c.QueueRetryInterval <= 0 {
+			return errors.New("queue-retry-interval must be greater than 0")
+		}
+		if c.QueueMaxRetryInterval < c.QueueRetryInterval {
+			return errors.New("queue-max-retry-interval must not be less than queue-retry-interval")
+		}
+	}
+
+	if c.FileDestinationsEnabled {
+		if len(c.FileDestinationDirs) == 0 {
+			return errors.New("file-destination-dirs must be set if file destinations are enabled")
+		}
+		for _, dir := range c.FileDestinationDirs {
+			if !filepath.IsAbs(dir) {
+				return fmt.Errorf("file-destination-dirs must be absolute paths: %s", dir)
+			}
+		}
+	}
+
+	if c.ExecDestinationsEnabled {
+		if len(c.ExecDestinationCommands) == 0 {
+			return errors.New("exec-destination-commands must be set if exec destinations are enabled")
+		}
+		for _, name := range c.ExecDestinationCommands {
+			if !filepath.IsAbs(name) {
+				return fmt.Errorf("exec-destination-commands must be absolute paths: %s", name)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -103,5 +175,10 @@ func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 		"http-timeout":      c.HTTPTimeout,
 		"write-concurrency": c.WriteConcurrency,
 		"write-buffer-size": c.WriteBufferSize,
+		"queue-dir":         c.QueueDir,
+		"queue-max-size":    c.QueueMaxSize,
+
+		"file-destinations-enabled": c.FileDestinationsEnabled,
+		"exec-destinations-enabled": c.ExecDestinationsEnabled,
 	}), nil
 }
@@ -109,3 +109,27 @@ write-concurrency = 10
 		t.Errorf("Expected Validation to succeed. Instead was: %v", err)
 	}
 }
+
+func TestConfig_LocalDestinations(t *testing.T) {
+	c := subscriber.NewConfig()
+	if _, err := toml.Decode(`
+file-destinations-enabled = true
+exec-destinations-enabled = true
+exec-destination-commands = ["/usr/local/bin/consumer"]
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for file destinations without file-destination-dirs")
+	}
+
+	c.FileDestinationDirs = []string{"relative/dir"}
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for relative file-destination-dirs")
+	}
+
+	c.FileDestinationDirs = []string{"/var/lib/influxdb/subscriptions"}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
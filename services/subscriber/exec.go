@@ -0,0 +1,159 @@
+package subscriber
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+)
+
+// DefaultExecStopTimeout is the time Exec waits for its command to exit
+// after closing its standard input before killing it.
+const DefaultExecStopTimeout = 10 * time.Second
+
+// Exec supports writing points to the standard input of a command using the
+// line protocol. The command is started on the first write and started again
+// if it exits.
+type Exec struct {
+	name string
+	args []string
+
+	// StopTimeout is the time to wait for the command to exit once its
+	// standard input is closed before killing it.
+	StopTimeout time.Duration
+
+	// wmu serializes writes so that the lines of concurrent writes are not
+	// interleaved. It is not held by Close so that a command that stops
+	// reading does not block it.
+	wmu sync.Mutex
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	closed bool
+}
+
+// NewExec returns a new Exec points writer that runs the named command with
+// the arguments.
+func NewExec(name string, args ...string) *Exec {
+	return &Exec{name: name, args: args, StopTimeout: DefaultExecStopTimeout}
+}
+
+// newExecFromURL returns an Exec points writer for a destination such as
+// exec:///usr/local/bin/consumer?arg=-v&arg=--format=lp.
+func newExecFromURL(u url.URL, c Config) (PointsWriter, error) {
+	if err := c.validateExecURL(&u); err != nil {
+		return nil, err
+	}
+	return NewExec(u.Path, u.Query()["arg"]...), nil
+}
+
+// validateExecURL returns an error if exec destinations are not enabled by
+// the config or the command of the destination is not one of the commands
+// they are allowed to run.
+func (c Config) validateExecURL(u *url.URL) error {
+	if !c.ExecDestinationsEnabled {
+		return errors.New("exec destinations are not enabled")
+	} else if u.Host != "" || !filepath.IsAbs(u.Path) {
+		return errors.New("exec destinations must have an absolute path")
+	}
+	for _, name := range c.ExecDestinationCommands {
+		if filepath.Clean(name) == filepath.Clean(u.Path) {
+			return nil
+		}
+	}
+	return fmt.Errorf("exec destination %s is not in exec-destination-commands", u.Path)
+}
+
+func (e *Exec) start() error {
+	cmd := exec.Command(e.name, e.args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	e.cmd, e.stdin = cmd, stdin
+	return nil
+}
+
+// stop closes the standard input of the command and waits for it to exit,
+// killing it if it does not exit within the stop timeout. Closing the
+// standard input unblocks writes to it.
+func (e *Exec) stop() error {
+	if e.cmd == nil {
+		return nil
+	}
+	cmd := e.cmd
+	e.stdin.Close()
+	e.cmd, e.stdin = nil, nil
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(e.StopTimeout):
+		cmd.Process.Kill()
+		<-done
+		return fmt.Errorf("exec: %s did not exit within %s and was killed", e.name, e.StopTimeout)
+	}
+}
+
+// WritePoints writes the points to the standard input of the command.
+func (e *Exec) WritePoints(p *coordinator.WritePointsRequest) error {
+	var buf bytes.Buffer
+	for _, pt := range p.Points {
+		buf.WriteString(pt.String())
+		buf.WriteByte('\n')
+	}
+
+	e.wmu.Lock()
+	defer e.wmu.Unlock()
+
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return os.ErrClosed
+	}
+	if e.cmd == nil {
+		if err := e.start(); err != nil {
+			e.mu.Unlock()
+			return err
+		}
+	}
+	cmd, stdin := e.cmd, e.stdin
+	e.mu.Unlock()
+
+	// Write without holding the lock so that Close is not blocked by a
+	// command that stops reading.
+	if _, err := stdin.Write(buf.Bytes()); err != nil {
+		// The command has exited, or Close stopped it. It is started again
+		// on the next write.
+		e.mu.Lock()
+		if e.cmd == cmd {
+			e.stop()
+		}
+		e.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Close closes the standard input of the command and waits for it to exit.
+func (e *Exec) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	return e.stop()
+}
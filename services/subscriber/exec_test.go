@@ -0,0 +1,118 @@
+package subscriber_test
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/subscriber"
+)
+
+// TestExec_HelperProcess is run as the command of the Exec tests. It copies
+// its standard input to the file named by its last argument, or hangs without
+// reading it if the argument is "hang".
+func TestExec_HelperProcess(t *testing.T) {
+	if os.Getenv("SUBSCRIBER_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	if os.Args[len(os.Args)-1] == "hang" {
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+	f, err := os.OpenFile(os.Args[len(os.Args)-1], os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		os.Exit(1)
+	}
+	io.Copy(f, os.Stdin)
+	f.Close()
+	os.Exit(0)
+}
+
+func TestExec_WritePoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subscriber_exec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "out.lp")
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("SUBSCRIBER_WANT_HELPER_PROCESS", "1")
+	defer os.Unsetenv("SUBSCRIBER_WANT_HELPER_PROCESS")
+
+	w := subscriber.NewExec(exe, "-test.run=TestExec_HelperProcess", "--", path)
+	for _, lines := range []string{
+		"cpu,host=a value=1 10\ncpu,host=b value=2 10",
+		"mem,host=a free=3i 20",
+	} {
+		points, err := models.ParsePointsString(lines)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WritePoints(&coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: points}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	} else if exp := "cpu,host=a value=1 10\ncpu,host=b value=2 10\nmem,host=a free=3i 20\n"; string(b) != exp {
+		t.Fatalf("unexpected output: %q", b)
+	}
+}
+
+// Ensure Close does not wait for a write to a command that stopped reading.
+func TestExec_Close_Hung(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("SUBSCRIBER_WANT_HELPER_PROCESS", "1")
+	defer os.Unsetenv("SUBSCRIBER_WANT_HELPER_PROCESS")
+
+	w := subscriber.NewExec(exe, "-test.run=TestExec_HelperProcess", "--", "hang")
+	w.StopTimeout = 100 * time.Millisecond
+
+	// Write more than fits in the pipe to the command.
+	points, err := models.ParsePointsString(strings.Repeat("cpu,host=a value=1 10\n", 10000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errC := make(chan error, 1)
+	go func() {
+		errC <- w.WritePoints(&coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: points})
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		w.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out closing")
+	}
+	select {
+	case err := <-errC:
+		if err == nil {
+			t.Fatal("expected write error")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out writing")
+	}
+}
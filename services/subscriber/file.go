@@ -0,0 +1,201 @@
+package subscriber
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/toml"
+)
+
+const (
+	// DefaultFileMaxSize is the default size at which the file of a file
+	// destination is rotated.
+	DefaultFileMaxSize = 100 * 1024 * 1024 // 100MB
+
+	// DefaultFileMaxFiles is the default number of rotated files kept for a
+	// file destination.
+	DefaultFileMaxFiles = 5
+)
+
+// File supports writing points to a file using the line protocol. Once the
+// file reaches its maximum size, it is renamed with the suffix ".1" and the
+// older files are renamed with the next suffix, up to the maximum number of
+// files kept.
+type File struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	closed bool
+}
+
+// NewFile returns a new File points writer that appends to the file at path.
+func NewFile(path string, maxSize int64, maxFiles int) (*File, error) {
+	if maxSize <= 0 {
+		return nil, errors.New("file: max size must be greater than 0")
+	} else if maxFiles < 0 {
+		return nil, errors.New("file: max files must not be negative")
+	}
+
+	w := &File{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// newFileFromURL returns a File points writer for a destination such as
+// file:///var/lib/influxdb/sub.lp?max-size=10m&max-files=3.
+func newFileFromURL(u url.URL, c Config) (PointsWriter, error) {
+	if err := c.validateFileURL(&u); err != nil {
+		return nil, err
+	}
+	maxSize, maxFiles, err := parseFileURL(&u)
+	if err != nil {
+		return nil, err
+	}
+	return NewFile(u.Path, maxSize, maxFiles)
+}
+
+// validateFileURL returns an error if file destinations are not enabled by
+// the config or the path of the destination is not in one of the directories
+// they are allowed to write to.
+func (c Config) validateFileURL(u *url.URL) error {
+	if !c.FileDestinationsEnabled {
+		return errors.New("file destinations are not enabled")
+	} else if u.Host != "" || !filepath.IsAbs(u.Path) || filepath.Clean(u.Path) != u.Path {
+		return errors.New("file destinations must have a clean absolute path")
+	}
+
+	var allowed bool
+	for _, dir := range c.FileDestinationDirs {
+		rel, err := filepath.Rel(filepath.Clean(dir), u.Path)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("file destination %s is not in file-destination-dirs", u.Path)
+	}
+
+	_, _, err := parseFileURL(u)
+	return err
+}
+
+func parseFileURL(u *url.URL) (maxSize int64, maxFiles int, err error) {
+	maxSize, maxFiles = DefaultFileMaxSize, DefaultFileMaxFiles
+	q := u.Query()
+	if s := q.Get("max-size"); s != "" {
+		var size toml.Size
+		if err := size.UnmarshalText([]byte(s)); err != nil {
+			return 0, 0, fmt.Errorf("invalid max-size: %s", err)
+		}
+		maxSize = int64(size)
+	}
+	if s := q.Get("max-files"); s != "" {
+		if maxFiles, err = strconv.Atoi(s); err != nil {
+			return 0, 0, fmt.Errorf("invalid max-files: %s", err)
+		}
+	}
+	return maxSize, maxFiles, nil
+}
+
+func (w *File) open() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.f, w.size = f, fi.Size()
+	return nil
+}
+
+// WritePoints appends the points to the file.
+func (w *File) WritePoints(p *coordinator.WritePointsRequest) error {
+	var buf bytes.Buffer
+	for _, pt := range p.Points {
+		buf.WriteString(pt.String())
+		buf.WriteByte('\n')
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	// Reopen the file if it could not be rotated.
+	if w.f == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	if w.size > 0 && w.size+int64(buf.Len()) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := w.f.Write(buf.Bytes())
+	w.size += int64(n)
+	return err
+}
+
+// rotate renames the file and the files rotated before it and opens a new
+// file.
+func (w *File) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	w.f = nil
+
+	if w.maxFiles == 0 {
+		if err := os.Remove(w.path); err != nil {
+			return err
+		}
+		return w.open()
+	}
+
+	for i := w.maxFiles - 1; i > 0; i-- {
+		err := os.Rename(w.rotatedPath(i), w.rotatedPath(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(w.path, w.rotatedPath(1)); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+func (w *File) rotatedPath(i int) string {
+	return w.path + "." + strconv.Itoa(i)
+}
+
+// Close closes the file.
+func (w *File) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
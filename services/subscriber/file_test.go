@@ -0,0 +1,64 @@
+package subscriber_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/subscriber"
+)
+
+func TestFile_WritePoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subscriber_file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sub.lp")
+
+	// Each file holds two requests.
+	w, err := subscriber.NewFile(path, 30, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"cpu value=1 10",
+		"cpu value=2 20",
+		"cpu value=3 30",
+		"cpu value=4 40",
+		"cpu value=5 50",
+		"cpu value=6 60",
+		"cpu value=7 70",
+	} {
+		points, err := models.ParsePointsString(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WritePoints(&coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: points}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The oldest file has been removed.
+	for name, exp := range map[string]string{
+		"sub.lp":   "cpu value=7 70\n",
+		"sub.lp.1": "cpu value=5 50\ncpu value=6 60\n",
+		"sub.lp.2": "cpu value=3 30\ncpu value=4 40\n",
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		} else if string(b) != exp {
+			t.Fatalf("unexpected content of %s: %q", name, b)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected no third rotated file: %v", err)
+	}
+}
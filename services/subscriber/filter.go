@@ -0,0 +1,101 @@
+package subscriber
+
+import (
+	"regexp"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+)
+
+// pointFilter selects the points written to a subscription.
+type pointFilter struct {
+	measurements map[string]struct{}
+	regex        *regexp.Regexp
+	cond         influxql.Expr
+}
+
+// newPointFilter compiles the filter of a subscription. It returns nil if the
+// subscription does not have a filter.
+func newPointFilter(f *meta.SubscriptionFilter) (*pointFilter, error) {
+	if f == nil || (len(f.Measurements) == 0 && f.MeasurementRegex == "" && f.Condition == "") {
+		return nil, nil
+	}
+
+	pf := &pointFilter{}
+	if len(f.Measurements) > 0 {
+		pf.measurements = make(map[string]struct{}, len(f.Measurements))
+		for _, name := range f.Measurements {
+			pf.measurements[name] = struct{}{}
+		}
+	}
+	if f.MeasurementRegex != "" {
+		re, err := regexp.Compile(f.MeasurementRegex)
+		if err != nil {
+			return nil, err
+		}
+		pf.regex = re
+	}
+	if f.Condition != "" {
+		expr, err := influxql.ParseExpr(f.Condition)
+		if err != nil {
+			return nil, err
+		}
+		pf.cond = expr
+	}
+	return pf, nil
+}
+
+// Filter returns the request with only the points selected by the filter.
+// It returns nil if no point is selected.
+func (f *pointFilter) Filter(p *coordinator.WritePointsRequest) *coordinator.WritePointsRequest {
+	var points []models.Point
+	for i, pt := range p.Points {
+		if f.match(pt) {
+			if points != nil {
+				points = append(points, pt)
+			}
+			continue
+		}
+		if points == nil {
+			points = make([]models.Point, i, len(p.Points))
+			copy(points, p.Points[:i])
+		}
+	}
+
+	if points == nil {
+		// All points are selected.
+		return p
+	} else if len(points) == 0 {
+		return nil
+	}
+	return &coordinator.WritePointsRequest{
+		Database:        p.Database,
+		RetentionPolicy: p.RetentionPolicy,
+		Points:          points,
+	}
+}
+
+func (f *pointFilter) match(pt models.Point) bool {
+	if f.measurements != nil || f.regex != nil {
+		name := pt.Name()
+		_, ok := f.measurements[string(name)]
+		if !ok && (f.regex == nil || !f.regex.Match(name)) {
+			return false
+		}
+	}
+	if f.cond != nil {
+		valuer := influxql.ValuerEval{Valuer: tagValuer(pt.Tags())}
+		return valuer.EvalBool(f.cond)
+	}
+	return true
+}
+
+// tagValuer returns the values of tags for evaluating a condition. Missing
+// tags have an empty value.
+type tagValuer models.Tags
+
+func (v tagValuer) Value(key string) (interface{}, bool) {
+	return models.Tags(v).GetString(key), true
+}
@@ -1,18 +1,41 @@
 package subscriber
 
 import (
+	"bytes"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
 	"time"
 
-	"github.com/influxdata/influxdb/client/v2"
 	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/services/meta"
 )
 
 // HTTP supports writing points over HTTP using the line protocol.
 type HTTP struct {
-	c client.Client
+	url    url.URL // URL of the write endpoint, without credentials
+	user   *url.Userinfo
+	client *http.Client
+}
+
+// token returns the API token of the destination. A destination uses a token
+// instead of a username and password if its user info is only a token, such
+// as https://influx_<id>.<secret>@host:8086.
+func (h *HTTP) token() (string, bool) {
+	if h.user == nil {
+		return "", false
+	} else if _, ok := h.user.Password(); ok {
+		return "", false
+	}
+	token := h.user.Username()
+	return token, strings.HasPrefix(token, meta.TokenPrefix)
 }
 
 // NewHTTP returns a new HTTP points writer with default options.
@@ -26,32 +49,80 @@ func NewHTTPS(addr string, timeout time.Duration, unsafeSsl bool, caCerts string
 	if err != nil {
 		return nil, err
 	}
-
-	conf := client.HTTPConfig{
-		Addr:               addr,
-		Timeout:            timeout,
-		InsecureSkipVerify: unsafeSsl,
-		TLSConfig:          tlsConfig,
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
 	}
+	tlsConfig.InsecureSkipVerify = unsafeSsl
 
-	c, err := client.NewHTTPClient(conf)
+	u, err := url.Parse(addr)
 	if err != nil {
 		return nil, err
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported protocol scheme: %s, your address must start with http:// or https://", u.Scheme)
 	}
-	return &HTTP{c: c}, nil
+	user := u.User
+	u.User = nil
+	u.Path = path.Join(u.Path, "write")
+
+	return &HTTP{
+		url:  *u,
+		user: user,
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+	}, nil
 }
 
-// WritePoints writes points over HTTP transport.
-func (h *HTTP) WritePoints(p *coordinator.WritePointsRequest) (err error) {
-	bp, _ := client.NewBatchPoints(client.BatchPointsConfig{
-		Database:        p.Database,
-		RetentionPolicy: p.RetentionPolicy,
-	})
+// WritePoints writes points over HTTP transport. Writes rejected by the
+// destination as invalid fail with a PermanentError.
+func (h *HTTP) WritePoints(p *coordinator.WritePointsRequest) error {
+	var buf bytes.Buffer
 	for _, pt := range p.Points {
-		bp.AddPoint(client.NewPointFrom(pt))
+		buf.WriteString(pt.String())
+		buf.WriteByte('\n')
+	}
+
+	u := h.url
+	params := u.Query()
+	params.Set("db", p.Database)
+	params.Set("rp", p.RetentionPolicy)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest("POST", u.String(), &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "")
+	req.Header.Set("User-Agent", "InfluxDBSubscriber")
+	if token, ok := h.token(); ok {
+		req.Header.Set("Authorization", "Token "+token)
+	} else if h.user != nil {
+		password, _ := h.user.Password()
+		req.SetBasicAuth(h.user.Username(), password)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return &PermanentError{Err: errors.New(string(body))}
 	}
-	err = h.c.Write(bp)
-	return
+	return errors.New(string(body))
 }
 
 func createTLSConfig(caCerts string, tlsConfig *tls.Config) (*tls.Config, error) {
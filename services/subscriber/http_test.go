@@ -0,0 +1,90 @@
+package subscriber_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/subscriber"
+)
+
+func TestHTTP_WritePoints(t *testing.T) {
+	for _, tt := range []struct {
+		status    int
+		permanent bool
+		fails     bool
+	}{
+		{status: http.StatusNoContent},
+		{status: http.StatusBadRequest, fails: true, permanent: true},
+		{status: http.StatusRequestEntityTooLarge, fails: true, permanent: true},
+		{status: http.StatusServiceUnavailable, fails: true},
+	} {
+		var body, db, rp string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buf, _ := ioutil.ReadAll(r.Body)
+			body, db, rp = string(buf), r.URL.Query().Get("db"), r.URL.Query().Get("rp")
+			w.WriteHeader(tt.status)
+		}))
+
+		h, err := subscriber.NewHTTP(ts.URL, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		points, err := models.ParsePointsString("cpu value=1 10")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = h.WritePoints(&coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: points})
+		ts.Close()
+
+		if got := err != nil; got != tt.fails {
+			t.Errorf("%d: unexpected error: %v", tt.status, err)
+		}
+		if got := errors.As(err, new(*subscriber.PermanentError)); got != tt.permanent {
+			t.Errorf("%d: unexpected permanent error: got %v, exp %v", tt.status, got, tt.permanent)
+		}
+		if body != "cpu value=1 10\n" || db != "db0" || rp != "rp0" {
+			t.Errorf("%d: unexpected request: body=%q db=%q rp=%q", tt.status, body, db, rp)
+		}
+	}
+}
+
+func TestHTTP_WritePoints_Credentials(t *testing.T) {
+	for _, tt := range []struct {
+		userinfo string
+		auth     string
+	}{
+		{userinfo: "", auth: ""},
+		{userinfo: "bob:secret@", auth: "Basic Ym9iOnNlY3JldA=="},
+		{userinfo: "influx_0123.abcd@", auth: "Token influx_0123.abcd"},
+	} {
+		var auth string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		h, err := subscriber.NewHTTP("http://"+tt.userinfo+strings.TrimPrefix(ts.URL, "http://"), time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		points, err := models.ParsePointsString("cpu value=1 10")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = h.WritePoints(&coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: points})
+		ts.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if auth != tt.auth {
+			t.Errorf("%q: unexpected authorization: got %q, exp %q", tt.userinfo, auth, tt.auth)
+		}
+	}
+}
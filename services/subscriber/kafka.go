@@ -0,0 +1,147 @@
+package subscriber
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"github.com/segmentio/kafka-go"
+)
+
+// Formats of the messages written to Kafka.
+const (
+	KafkaFormatLine = "line"
+	KafkaFormatJSON = "json"
+)
+
+// newKafkaWriter returns a writer that writes to the topic of the Kafka
+// cluster. Messages are written to the partition of the hash of their key
+// and acknowledged once all in-sync replicas have them.
+func newKafkaWriter(brokers []string, topic string) *kafka.Writer {
+	return kafka.NewWriter(kafka.WriterConfig{
+		Brokers:      brokers,
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: 10 * time.Millisecond,
+		RequiredAcks: -1,
+	})
+}
+
+// Kafka supports writing points to a Kafka topic. Each point is written as a
+// message keyed by its series key, so the points of a series are written to
+// the same partition.
+type Kafka struct {
+	w       *kafka.Writer
+	format  string
+	timeout time.Duration
+}
+
+// NewKafka returns a new Kafka points writer that writes to the topic in
+// the line protocol or JSON format.
+func NewKafka(brokers []string, topic, format string, timeout time.Duration) (*Kafka, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("kafka: no brokers")
+	} else if topic == "" {
+		return nil, errors.New("kafka: no topic")
+	}
+	switch format {
+	case "":
+		format = KafkaFormatLine
+	case KafkaFormatLine, KafkaFormatJSON:
+	default:
+		return nil, fmt.Errorf("kafka: unknown format %s", format)
+	}
+	return &Kafka{
+		w:       newKafkaWriter(brokers, topic),
+		format:  format,
+		timeout: timeout,
+	}, nil
+}
+
+// newKafkaFromURL returns a Kafka points writer for a destination such as
+// kafka://host1:9092,host2:9092/topic?format=json.
+func newKafkaFromURL(u url.URL, c Config) (PointsWriter, error) {
+	return NewKafka(strings.Split(u.Host, ","), strings.Trim(u.Path, "/"), u.Query().Get("format"), time.Duration(c.HTTPTimeout))
+}
+
+func validateKafkaURL(u *url.URL) error {
+	for _, broker := range strings.Split(u.Host, ",") {
+		if _, port, err := net.SplitHostPort(broker); err != nil {
+			return err
+		} else if port == "" {
+			return errors.New("missing port")
+		}
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		return errors.New("missing topic")
+	}
+	switch u.Query().Get("format") {
+	case "", KafkaFormatLine, KafkaFormatJSON:
+		return nil
+	default:
+		return errors.New("unknown format")
+	}
+}
+
+// WritePoints writes the points to the topic.
+func (k *Kafka) WritePoints(p *coordinator.WritePointsRequest) error {
+	msgs := make([]kafka.Message, 0, len(p.Points))
+	for _, pt := range p.Points {
+		var value []byte
+		if k.format == KafkaFormatJSON {
+			b, err := marshalPointJSON(p.Database, p.RetentionPolicy, pt)
+			if err != nil {
+				return &PermanentError{Err: err}
+			}
+			value = b
+		} else {
+			value = []byte(pt.String())
+		}
+		msgs = append(msgs, kafka.Message{Key: pt.Key(), Value: value})
+	}
+
+	ctx := context.Background()
+	if k.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, k.timeout)
+		defer cancel()
+	}
+	return k.w.WriteMessages(ctx, msgs...)
+}
+
+// Close flushes the pending messages and closes the connections to Kafka.
+func (k *Kafka) Close() error {
+	return k.w.Close()
+}
+
+// jsonPoint is the JSON representation of a point written to Kafka.
+type jsonPoint struct {
+	Database        string                 `json:"database"`
+	RetentionPolicy string                 `json:"retention_policy"`
+	Measurement     string                 `json:"measurement"`
+	Tags            map[string]string      `json:"tags"`
+	Fields          map[string]interface{} `json:"fields"`
+	Time            int64                  `json:"time"`
+}
+
+func marshalPointJSON(db, rp string, pt models.Point) ([]byte, error) {
+	fields, err := pt.Fields()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonPoint{
+		Database:        db,
+		RetentionPolicy: rp,
+		Measurement:     string(pt.Name()),
+		Tags:            pt.Tags().Map(),
+		Fields:          fields,
+		Time:            pt.UnixNano(),
+	})
+}
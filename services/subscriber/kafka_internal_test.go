@@ -0,0 +1,481 @@
+package subscriber
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"github.com/segmentio/kafka-go"
+)
+
+// Kafka API keys and error codes served by fakeKafkaBroker.
+const (
+	kafkaProduceRequest  = 0
+	kafkaMetadataRequest = 3
+
+	kafkaNotLeaderForPartition = 6
+)
+
+// fakeKafkaBroker is an in-process Kafka broker that serves the metadata and
+// produce requests of the Kafka writer. It leads every partition of a single
+// topic.
+type fakeKafkaBroker struct {
+	ln         net.Listener
+	topic      string
+	partitions int
+
+	mu       sync.Mutex
+	messages map[int32][]kafka.Message // messages by partition
+	acks     []int16                   // required acks of each produce request
+	failures int                       // produce requests to fail
+	conns    map[net.Conn]struct{}
+
+	wg sync.WaitGroup
+}
+
+func newFakeKafkaBroker(t *testing.T, topic string, partitions int) *fakeKafkaBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &fakeKafkaBroker{
+		ln:         ln,
+		topic:      topic,
+		partitions: partitions,
+		messages:   make(map[int32][]kafka.Message),
+		conns:      make(map[net.Conn]struct{}),
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			b.mu.Lock()
+			b.conns[conn] = struct{}{}
+			b.mu.Unlock()
+
+			b.wg.Add(1)
+			go func() {
+				defer b.wg.Done()
+				b.serve(conn)
+			}()
+		}
+	}()
+	t.Cleanup(b.Close)
+	return b
+}
+
+// Addr returns the address of the broker.
+func (b *fakeKafkaBroker) Addr() string { return b.ln.Addr().String() }
+
+// Fail makes the broker reject the next n produce requests.
+func (b *fakeKafkaBroker) Fail(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = n
+}
+
+// Messages returns the messages written to each partition.
+func (b *fakeKafkaBroker) Messages() map[int32][]kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m := make(map[int32][]kafka.Message, len(b.messages))
+	for p, msgs := range b.messages {
+		m[p] = append([]kafka.Message(nil), msgs...)
+	}
+	return m
+}
+
+// Acks returns the required acks of each produce request.
+func (b *fakeKafkaBroker) Acks() []int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]int16(nil), b.acks...)
+}
+
+func (b *fakeKafkaBroker) Close() {
+	b.ln.Close()
+	b.mu.Lock()
+	for conn := range b.conns {
+		conn.Close()
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+}
+
+func (b *fakeKafkaBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		var size int32
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return
+		}
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return
+		}
+
+		req := &kafkaReader{buf: buf}
+		apiKey, _ := req.int16(), req.int16()
+		correlationID := req.int32()
+		req.string() // client id
+
+		var res kafkaWriter
+		res.int32(correlationID)
+		switch apiKey {
+		case kafkaMetadataRequest:
+			b.metadata(req, &res)
+		case kafkaProduceRequest:
+			b.produce(req, &res)
+		default:
+			return
+		}
+		if req.err != nil {
+			return
+		}
+
+		if err := binary.Write(conn, binary.BigEndian, int32(res.Len())); err != nil {
+			return
+		} else if _, err := conn.Write(res.Bytes()); err != nil {
+			return
+		}
+	}
+}
+
+// metadata answers a metadata request (v1) with the broker as the leader of
+// every partition of its topic.
+func (b *fakeKafkaBroker) metadata(req *kafkaReader, res *kafkaWriter) {
+	n := req.int32()
+	for i := int32(0); i < n; i++ {
+		req.string()
+	}
+
+	host, port, _ := net.SplitHostPort(b.Addr())
+	p, _ := strconv.Atoi(port)
+
+	res.int32(1) // brokers
+	res.int32(0) // node id
+	res.string(host)
+	res.int32(int32(p))
+	res.string("") // rack
+	res.int32(0)   // controller id
+
+	res.int32(1) // topics
+	res.int16(0) // error code
+	res.string(b.topic)
+	res.int8(0) // internal
+	res.int32(int32(b.partitions))
+	for i := 0; i < b.partitions; i++ {
+		res.int16(0)        // error code
+		res.int32(int32(i)) // partition id
+		res.int32(0)        // leader
+		res.int32(1)        // replicas
+		res.int32(0)
+		res.int32(1) // in-sync replicas
+		res.int32(0)
+	}
+}
+
+// produce stores the messages of a produce request (v2) unless the request
+// is made to fail.
+func (b *fakeKafkaBroker) produce(req *kafkaReader, res *kafkaWriter) {
+	acks := req.int16()
+	req.int32() // timeout
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.acks = append(b.acks, acks)
+
+	var errorCode int16
+	if b.failures > 0 {
+		b.failures--
+		errorCode = kafkaNotLeaderForPartition
+	}
+
+	topics := req.int32()
+	res.int32(topics)
+	for i := int32(0); i < topics; i++ {
+		topic := req.string()
+		res.string(topic)
+
+		partitions := req.int32()
+		res.int32(partitions)
+		for j := int32(0); j < partitions; j++ {
+			partition := req.int32()
+			set := &kafkaReader{buf: req.bytes()}
+			for set.err == nil && len(set.buf) > 0 {
+				set.int64() // offset
+				set.int32() // size
+				set.int32() // crc
+				set.int8()  // magic byte
+				set.int8()  // attributes
+				ts := set.int64()
+				msg := kafka.Message{Topic: topic, Partition: int(partition), Key: set.bytes(), Value: set.bytes(), Time: time.Unix(0, ts*int64(time.Millisecond))}
+				if set.err == nil && errorCode == 0 {
+					b.messages[partition] = append(b.messages[partition], msg)
+				}
+			}
+			if set.err != nil {
+				req.err = set.err
+			}
+
+			res.int32(partition)
+			res.int16(errorCode)
+			res.int64(int64(len(b.messages[partition]))) // base offset
+			res.int64(-1)                                // log append time
+		}
+	}
+	res.int32(0) // throttle time
+}
+
+// kafkaReader decodes the primitive types of the Kafka protocol.
+type kafkaReader struct {
+	buf []byte
+	err error
+}
+
+func (r *kafkaReader) next(n int) []byte {
+	if r.err != nil || n < 0 || n > len(r.buf) {
+		if r.err == nil {
+			r.err = fmt.Errorf("short buffer")
+		}
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *kafkaReader) int8() int8 {
+	if b := r.next(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (r *kafkaReader) int16() int16 {
+	if b := r.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) int32() int32 {
+	if b := r.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) int64() int64 {
+	if b := r.next(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) string() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(r.next(int(n)))
+}
+
+func (r *kafkaReader) bytes() []byte {
+	n := r.int32()
+	if n < 0 {
+		return nil
+	}
+	return append([]byte(nil), r.next(int(n))...)
+}
+
+// kafkaWriter encodes the primitive types of the Kafka protocol.
+type kafkaWriter struct {
+	bytes.Buffer
+}
+
+func (w *kafkaWriter) int8(v int8)   { w.WriteByte(byte(v)) }
+func (w *kafkaWriter) int16(v int16) { binary.Write(w, binary.BigEndian, v) }
+func (w *kafkaWriter) int32(v int32) { binary.Write(w, binary.BigEndian, v) }
+func (w *kafkaWriter) int64(v int64) { binary.Write(w, binary.BigEndian, v) }
+
+func (w *kafkaWriter) string(s string) {
+	w.int16(int16(len(s)))
+	w.WriteString(s)
+}
+
+func newKafkaTestWriter(t *testing.T, rawurl string) *Kafka {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := validateKafkaURL(u); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewService(NewConfig()).newPointsWriter(*u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w.(*Kafka)
+}
+
+func TestKafka_WritePoints(t *testing.T) {
+	points, err := models.ParsePointsString("cpu,host=a value=1 10\nmem,host=b free=2i 20")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		query string
+		exp   []string
+	}{
+		{
+			name: "line",
+			exp:  []string{"cpu,host=a value=1 10", "mem,host=b free=2i 20"},
+		},
+		{
+			name:  "json",
+			query: "?format=json",
+			exp: []string{
+				`{"database":"db0","retention_policy":"rp0","measurement":"cpu","tags":{"host":"a"},"fields":{"value":1},"time":10}`,
+				`{"database":"db0","retention_policy":"rp0","measurement":"mem","tags":{"host":"b"},"fields":{"free":2},"time":20}`,
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newFakeKafkaBroker(t, "metrics", 1)
+
+			w := newKafkaTestWriter(t, "kafka://"+b.Addr()+"/metrics"+tt.query)
+			if err := w.WritePoints(&coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: points}); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			msgs := b.Messages()[0]
+			if len(msgs) != len(tt.exp) {
+				t.Fatalf("unexpected number of messages: %d", len(msgs))
+			}
+			for i, msg := range msgs {
+				if got := string(msg.Value); got != tt.exp[i] {
+					t.Fatalf("unexpected message %d: got %s, exp %s", i, got, tt.exp[i])
+				} else if key := string(msg.Key); key != string(points[i].Key()) {
+					t.Fatalf("unexpected key %d: %s", i, key)
+				}
+			}
+		})
+	}
+}
+
+// Ensure the points of a series are written to the same partition and
+// acknowledged by all in-sync replicas.
+func TestKafka_WritePoints_Partitions(t *testing.T) {
+	b := newFakeKafkaBroker(t, "metrics", 3)
+	w := newKafkaTestWriter(t, "kafka://"+b.Addr()+"/metrics")
+	defer w.Close()
+
+	var lines bytes.Buffer
+	for i := 0; i < 3; i++ {
+		for _, host := range []string{"a", "b", "c", "d", "e", "f"} {
+			fmt.Fprintf(&lines, "cpu,host=%s value=%d %d\n", host, i, i)
+		}
+	}
+	points, err := models.ParsePointsString(lines.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WritePoints(&coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: points}); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	partitions := make(map[string]int)
+	for partition, msgs := range b.Messages() {
+		for _, msg := range msgs {
+			n++
+			key := string(msg.Key)
+			if p, ok := partitions[key]; ok && p != int(partition) {
+				t.Fatalf("series %s written to partitions %d and %d", key, p, partition)
+			}
+			partitions[key] = int(partition)
+			if exp := (&kafka.Hash{}).Balance(kafka.Message{Key: msg.Key}, 0, 1, 2); int(partition) != exp {
+				t.Fatalf("series %s written to partition %d, exp %d", key, partition, exp)
+			}
+		}
+	}
+	if n != len(points) {
+		t.Fatalf("unexpected number of messages: %d", n)
+	}
+	for _, acks := range b.Acks() {
+		if acks != -1 {
+			t.Fatalf("unexpected required acks: %d", acks)
+		}
+	}
+}
+
+// Ensure writes rejected by the broker are retried until they succeed or
+// time out.
+func TestKafka_WritePoints_Retry(t *testing.T) {
+	points, err := models.ParsePointsString("cpu,host=a value=1 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: points}
+
+	b := newFakeKafkaBroker(t, "metrics", 1)
+	w := newKafkaTestWriter(t, "kafka://"+b.Addr()+"/metrics")
+	defer w.Close()
+
+	b.Fail(2)
+	if err := w.WritePoints(req); err != nil {
+		t.Fatal(err)
+	}
+	if msgs := b.Messages()[0]; len(msgs) != 1 {
+		t.Fatalf("unexpected number of messages: %d", len(msgs))
+	} else if n := len(b.Acks()); n != 3 {
+		t.Fatalf("unexpected number of produce requests: %d", n)
+	}
+
+	// Writes fail once the timeout expires.
+	w.timeout = 50 * time.Millisecond
+	b.Fail(1000)
+	if err := w.WritePoints(req); err == nil {
+		t.Fatal("expected error")
+	}
+	if msgs := b.Messages()[0]; len(msgs) != 1 {
+		t.Fatalf("unexpected number of messages: %d", len(msgs))
+	}
+}
+
+func TestKafka_ValidateURL(t *testing.T) {
+	for _, s := range []string{
+		"kafka://k1/metrics",
+		"kafka://k1:9092",
+		"kafka://k1,k2:9092/metrics",
+		"kafka://k1:9092/metrics?format=csv",
+	} {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := validateKafkaURL(u); err == nil {
+			t.Fatalf("expected error for %s", s)
+		}
+	}
+}
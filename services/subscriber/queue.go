@@ -0,0 +1,452 @@
+package subscriber
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/file"
+)
+
+// DefaultSegmentSize is the maximum size of a segment file of a queue.
+const DefaultSegmentSize = 10 * 1024 * 1024 // 10MB
+
+var (
+	// ErrQueueFull is returned when appending to a queue that is full.
+	ErrQueueFull = errors.New("queue is full")
+
+	// ErrQueueClosed is returned when using a queue that has been closed.
+	ErrQueueClosed = errors.New("queue is closed")
+
+	// ErrBlockChecksumMismatch is returned when reading a block whose data
+	// does not match its checksum.
+	ErrBlockChecksumMismatch = errors.New("queue block checksum mismatch")
+)
+
+const (
+	// segmentHeaderSize is the size of the header at the start of a segment
+	// file that holds the offset of the first unread block.
+	segmentHeaderSize = 8
+
+	// blockHeaderSize is the size of the length, checksum and timestamp
+	// stored before each block.
+	blockHeaderSize = 20
+)
+
+// queue is a FIFO of blocks stored in segment files in a directory. Blocks
+// that have not been read when the queue is closed are read again once it is
+// reopened.
+type queue struct {
+	dir            string
+	maxSize        int64
+	maxSegmentSize int64
+
+	mu       sync.Mutex
+	segments []*segment
+	size     int64
+	closed   bool
+}
+
+// newQueue opens the queue in dir, creating the directory if it does not
+// exist. The queue holds at most maxSize bytes of unread blocks.
+func newQueue(dir string, maxSize, maxSegmentSize int64) (*queue, error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint64
+	for _, fi := range fis {
+		id, err := strconv.ParseUint(fi.Name(), 10, 64)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	q := &queue{dir: dir, maxSize: maxSize, maxSegmentSize: maxSegmentSize}
+	for _, id := range ids {
+		seg, err := openSegment(filepath.Join(dir, strconv.FormatUint(id, 10)), id)
+		if err != nil {
+			q.closeSegments()
+			return nil, err
+		}
+		q.segments = append(q.segments, seg)
+		q.size += seg.unread()
+	}
+
+	// Remove the segments that have been read entirely, except the last one
+	// which new blocks are appended to.
+	for len(q.segments) > 1 && q.segments[0].unread() == 0 {
+		if err := q.segments[0].remove(); err != nil {
+			q.closeSegments()
+			return nil, err
+		}
+		q.segments = q.segments[1:]
+	}
+	return q, nil
+}
+
+// Append adds a block to the end of the queue.
+func (q *queue) Append(b []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	n := int64(blockHeaderSize + len(b))
+	if q.size+n > q.maxSize {
+		return ErrQueueFull
+	}
+
+	// Start a new segment if the last one is full.
+	var tail *segment
+	if len(q.segments) > 0 {
+		tail = q.segments[len(q.segments)-1]
+	}
+	if tail == nil || (tail.size > segmentHeaderSize && tail.size+n > q.maxSegmentSize) {
+		var id uint64
+		if tail != nil {
+			id = tail.id + 1
+		}
+		seg, err := createSegment(filepath.Join(q.dir, strconv.FormatUint(id, 10)), id)
+		if err != nil {
+			return err
+		}
+		q.segments = append(q.segments, seg)
+		tail = seg
+	}
+
+	if err := tail.append(b, time.Now()); err != nil {
+		return err
+	}
+	q.size += n
+	return nil
+}
+
+// Current returns the block at the front of the queue and the time it was
+// appended. It returns io.EOF if the queue is empty.
+func (q *queue) Current() ([]byte, time.Time, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, time.Time{}, ErrQueueClosed
+	}
+	for _, seg := range q.segments {
+		if seg.unread() > 0 {
+			return seg.current()
+		}
+	}
+	return nil, time.Time{}, io.EOF
+}
+
+// Advance removes the block at the front of the queue.
+func (q *queue) Advance() error {
+	return q.advance((*segment).advance)
+}
+
+// Skip removes the block at the front of the queue if it cannot be read
+// because it is corrupt. The rest of its segment is removed with it if the
+// length of the block is corrupt too.
+func (q *queue) Skip() error {
+	return q.advance((*segment).skip)
+}
+
+// advance removes the block at the front of the queue with fn.
+func (q *queue) advance(fn func(*segment) (int64, error)) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	for len(q.segments) > 0 {
+		seg := q.segments[0]
+		if seg.unread() == 0 {
+			if len(q.segments) == 1 {
+				return nil
+			}
+			if err := seg.remove(); err != nil {
+				return err
+			}
+			q.segments = q.segments[1:]
+			continue
+		}
+
+		n, err := fn(seg)
+		if err != nil {
+			return err
+		}
+		q.size -= n
+
+		// Remove the segment once it has been read, unless new blocks are
+		// still appended to it.
+		if seg.unread() == 0 && len(q.segments) > 1 {
+			if err := seg.remove(); err != nil {
+				return err
+			}
+			q.segments = q.segments[1:]
+		}
+		return nil
+	}
+	return nil
+}
+
+// Size returns the number of bytes of unread blocks in the queue.
+func (q *queue) Size() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// Close closes the segment files of the queue.
+func (q *queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	return q.closeSegments()
+}
+
+func (q *queue) closeSegments() error {
+	var err error
+	for _, seg := range q.segments {
+		if e := seg.f.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	q.segments = nil
+	return err
+}
+
+// segment is a file of blocks. The file starts with the offset of the first
+// unread block followed by the blocks, each stored as its length, the CRC-32
+// checksum of its timestamp and data, the time it was appended and its data.
+type segment struct {
+	id   uint64
+	path string
+	f    *os.File
+	pos  int64 // offset of the first unread block
+	size int64 // size of the file
+}
+
+func createSegment(path string, id uint64) (*segment, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return nil, err
+	}
+	seg := &segment{id: id, path: path, f: f, pos: segmentHeaderSize, size: segmentHeaderSize}
+	if err := seg.writePos(); err != nil {
+		f.Close()
+		return nil, err
+	} else if err := file.SyncDir(filepath.Dir(path)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return seg, nil
+}
+
+func openSegment(path string, id uint64) (*segment, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0666)
+	if err != nil {
+		return nil, err
+	}
+	seg := &segment{id: id, path: path, f: f}
+	if err := seg.load(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("open queue segment %s: %s", path, err)
+	}
+	return seg, nil
+}
+
+// load reads the offset of the first unread block and drops the blocks at
+// the end of the file starting at the first one that was not written
+// completely or does not match its checksum.
+func (s *segment) load() error {
+	fi, err := s.f.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() < segmentHeaderSize {
+		s.pos, s.size = segmentHeaderSize, segmentHeaderSize
+		if err := s.f.Truncate(s.size); err != nil {
+			return err
+		}
+		return s.writePos()
+	}
+
+	var buf [segmentHeaderSize]byte
+	if _, err := s.f.ReadAt(buf[:], 0); err != nil {
+		return err
+	}
+	pos := int64(binary.BigEndian.Uint64(buf[:]))
+
+	// The offset of the first unread block is only valid if a block starts
+	// there or it is the end of the valid blocks.
+	s.pos = segmentHeaderSize
+	end := int64(segmentHeaderSize)
+	for {
+		if end == pos {
+			s.pos = pos
+		}
+		n, err := s.readBlock(end, fi.Size(), nil)
+		if err == io.ErrUnexpectedEOF || err == ErrBlockChecksumMismatch {
+			break
+		} else if err != nil {
+			return err
+		}
+		end += n
+	}
+	if end != fi.Size() {
+		if err := s.f.Truncate(end); err != nil {
+			return err
+		}
+	}
+	s.size = end
+	return nil
+}
+
+// readBlock reads the block at offset off of a file of the given size and
+// returns its size. If b is not nil, the data and timestamp of the block are
+// stored in it. It returns io.ErrUnexpectedEOF if the block does not fit in
+// the file and ErrBlockChecksumMismatch if it is corrupt.
+func (s *segment) readBlock(off, size int64, b *block) (int64, error) {
+	if off+blockHeaderSize > size {
+		return 0, io.ErrUnexpectedEOF
+	}
+	var hdr [blockHeaderSize]byte
+	if _, err := s.f.ReadAt(hdr[:], off); err != nil {
+		return 0, err
+	}
+	n := binary.BigEndian.Uint64(hdr[:8])
+	if n > uint64(size-off-blockHeaderSize) {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	data := make([]byte, n)
+	if _, err := s.f.ReadAt(data, off+blockHeaderSize); err != nil {
+		return 0, err
+	}
+	h := crc32.NewIEEE()
+	h.Write(hdr[12:20])
+	h.Write(data)
+	if h.Sum32() != binary.BigEndian.Uint32(hdr[8:12]) {
+		return 0, ErrBlockChecksumMismatch
+	}
+
+	if b != nil {
+		b.data = data
+		b.time = time.Unix(0, int64(binary.BigEndian.Uint64(hdr[12:20])))
+	}
+	return blockHeaderSize + int64(n), nil
+}
+
+// block is the data of a block and the time it was appended.
+type block struct {
+	data []byte
+	time time.Time
+}
+
+func (s *segment) unread() int64 {
+	return s.size - s.pos
+}
+
+func (s *segment) append(b []byte, t time.Time) error {
+	buf := make([]byte, blockHeaderSize+len(b))
+	binary.BigEndian.PutUint64(buf[:8], uint64(len(b)))
+	binary.BigEndian.PutUint64(buf[12:20], uint64(t.UnixNano()))
+	copy(buf[blockHeaderSize:], b)
+	binary.BigEndian.PutUint32(buf[8:12], crc32.ChecksumIEEE(buf[12:]))
+	_, err := s.f.WriteAt(buf, s.size)
+	if err == nil {
+		err = s.f.Sync()
+	}
+	if err != nil {
+		// Drop the partially written block.
+		s.f.Truncate(s.size)
+		return err
+	}
+	s.size += int64(len(buf))
+	return nil
+}
+
+func (s *segment) current() ([]byte, time.Time, error) {
+	var b block
+	if _, err := s.readBlock(s.pos, s.size, &b); err != nil {
+		return nil, time.Time{}, err
+	}
+	return b.data, b.time, nil
+}
+
+// advance moves past the first unread block and returns its size.
+func (s *segment) advance() (int64, error) {
+	var hdr [8]byte
+	if _, err := s.f.ReadAt(hdr[:], s.pos); err != nil {
+		return 0, err
+	}
+	size := binary.BigEndian.Uint64(hdr[:])
+	if s.unread() < blockHeaderSize || size > uint64(s.unread()-blockHeaderSize) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	n := blockHeaderSize + int64(size)
+	s.pos += n
+	if err := s.writePos(); err != nil {
+		// The block is not advanced past, so that it can be retried.
+		s.pos -= n
+		return 0, err
+	}
+	return n, nil
+}
+
+// skip moves past the first unread block, which is corrupt, and returns the
+// number of bytes skipped. If its length does not fit in the segment, the
+// rest of the segment is skipped.
+func (s *segment) skip() (int64, error) {
+	n, err := s.advance()
+	if err == io.ErrUnexpectedEOF {
+		pos := s.pos
+		n, s.pos = s.unread(), s.size
+		if err := s.writePos(); err != nil {
+			s.pos = pos
+			return 0, err
+		}
+		return n, nil
+	}
+	return n, err
+}
+
+func (s *segment) writePos() error {
+	var buf [segmentHeaderSize]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(s.pos))
+	if _, err := s.f.WriteAt(buf[:], 0); err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+func (s *segment) remove() error {
+	if err := s.f.Close(); err != nil {
+		return err
+	}
+	return os.Remove(s.path)
+}
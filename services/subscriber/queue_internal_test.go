@@ -0,0 +1,233 @@
+package subscriber
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/toml"
+	"go.uber.org/zap"
+)
+
+func TestQueue_Reopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subscriber_queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Each segment holds two blocks.
+	q, err := newQueue(dir, 1024, 2*(blockHeaderSize+1)+segmentHeaderSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := q.Append([]byte(strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Advance(); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Advance(); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first segment has been read and removed.
+	if _, err := os.Stat(filepath.Join(dir, "0")); !os.IsNotExist(err) {
+		t.Fatalf("expected first segment to be removed: %v", err)
+	}
+
+	// Simulate a block that was not written completely.
+	f, err := os.OpenFile(filepath.Join(dir, "2"), os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0})
+	f.Close()
+
+	q, err = newQueue(dir, 1024, 2*(blockHeaderSize+1)+segmentHeaderSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	if n := q.Size(); n != 3*(blockHeaderSize+1) {
+		t.Fatalf("unexpected size: %d", n)
+	}
+	for i := 2; i < 5; i++ {
+		b, _, err := q.Current()
+		if err != nil {
+			t.Fatal(err)
+		} else if string(b) != strconv.Itoa(i) {
+			t.Fatalf("unexpected block: got %s, exp %d", b, i)
+		}
+		if err := q.Advance(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := q.Current(); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+
+	// The queue rejects blocks once it is full.
+	if err := q.Append(make([]byte, 1024)); err != ErrQueueFull {
+		t.Fatalf("expected queue full error, got %v", err)
+	}
+}
+
+func TestQueue_Reopen_Corrupt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subscriber_queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	q, err := newQueue(dir, 1024, DefaultSegmentSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := q.Append([]byte(strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Flip a bit of the data of the second block and append a block whose
+	// length points past the end of the file.
+	f, err := os.OpenFile(filepath.Join(dir, "0"), os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte("x"), segmentHeaderSize+2*blockHeaderSize+1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, segmentHeaderSize+3*(blockHeaderSize+1)); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	q, err = newQueue(dir, 1024, DefaultSegmentSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	// The queue is truncated at the corrupt block.
+	if n := q.Size(); n != blockHeaderSize+1 {
+		t.Fatalf("unexpected size: %d", n)
+	}
+	if b, _, err := q.Current(); err != nil {
+		t.Fatal(err)
+	} else if string(b) != "0" {
+		t.Fatalf("unexpected block: %s", b)
+	}
+	if err := q.Advance(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := q.Current(); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
+
+// writePointsFunc is a PointsWriter calling a function.
+type writePointsFunc func(p *coordinator.WritePointsRequest) error
+
+func (fn writePointsFunc) WritePoints(p *coordinator.WritePointsRequest) error { return fn(p) }
+
+func TestQueueWriter_Corrupt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subscriber_queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The first write blocks until the blocks after it have been corrupted.
+	unblock := make(chan struct{})
+	written := make(chan string, 4)
+	w := writePointsFunc(func(p *coordinator.WritePointsRequest) error {
+		if p.Database == "db0" {
+			<-unblock
+		}
+		written <- p.Database
+		return nil
+	})
+
+	c := NewConfig()
+	c.QueueRetryInterval = toml.Duration(time.Millisecond)
+	qw, err := newQueueWriter(w, dir, c, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer qw.Close()
+
+	var offsets []int64
+	off := int64(segmentHeaderSize)
+	for _, db := range []string{"db0", "db1", "db2", "db3", "db4"} {
+		b := encodeWritePointsRequest(&coordinator.WritePointsRequest{Database: db})
+		if err := qw.WritePoints(&coordinator.WritePointsRequest{Database: db}); err != nil {
+			t.Fatal(err)
+		}
+		offsets = append(offsets, off)
+		off += blockHeaderSize + int64(len(b))
+	}
+
+	// Flip a bit of the data of the second block, then make the length of
+	// the fourth block point past the end of the segment, which drops the
+	// blocks after it.
+	f, err := os.OpenFile(filepath.Join(dir, "0"), os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte("x"), offsets[1]+blockHeaderSize); err != nil {
+		t.Fatal(err)
+	} else if _, err := f.WriteAt([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, offsets[3]); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	close(unblock)
+
+	// The corrupt blocks are dropped and the writes after them delivered.
+	for _, exp := range []string{"db0", "db2"} {
+		select {
+		case db := <-written:
+			if db != exp {
+				t.Fatalf("unexpected write: got %s, exp %s", db, exp)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("expected write to %s", exp)
+		}
+	}
+	for i := 0; atomic.LoadInt64(&qw.rejected) != 2; i++ {
+		if i == 1000 {
+			t.Fatalf("unexpected rejected writes: %d", atomic.LoadInt64(&qw.rejected))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := qw.WritePoints(&coordinator.WritePointsRequest{Database: "db5"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case db := <-written:
+		if db != "db5" {
+			t.Fatalf("unexpected write: %s", db)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected write after the corrupt blocks")
+	}
+	if stats := qw.Statistics(); stats[statQueueBytes].(int64) != 0 {
+		t.Fatalf("unexpected statistics: %v", stats)
+	}
+}
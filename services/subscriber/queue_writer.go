@@ -0,0 +1,238 @@
+package subscriber
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"go.uber.org/zap"
+)
+
+// Statistics for the disk-backed queue of a subscription destination.
+const (
+	statQueueBytes    = "queueBytes"    // Number of bytes waiting in the queue.
+	statQueueAge      = "queueAgeNs"    // Age of the oldest write waiting in the queue.
+	statQueueDropped  = "queueDropped"  // Number of writes dropped because the queue was full.
+	statQueueRetries  = "queueRetries"  // Number of failed writes that were retried.
+	statQueueRejected = "queueRejected" // Number of writes dropped because they cannot succeed.
+)
+
+// PermanentError is returned by a PointsWriter when a write fails in a way
+// that retrying it cannot fix, such as points rejected by the destination.
+// Queued writes that fail with a PermanentError are dropped.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// queueWriter writes points to a PointsWriter through a disk-backed queue.
+// Writes that fail are retried with exponential backoff until they succeed,
+// including after a restart, unless they fail with a PermanentError.
+type queueWriter struct {
+	w      PointsWriter
+	q      *queue
+	logger *zap.Logger
+
+	retryInterval    time.Duration
+	maxRetryInterval time.Duration
+
+	// Time the write at the front of the queue was queued, in nanoseconds.
+	head int64
+
+	dropped  int64
+	retries  int64
+	rejected int64
+
+	notify  chan struct{}
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newQueueWriter returns a queueWriter that stores its queue in dir.
+func newQueueWriter(w PointsWriter, dir string, c Config, logger *zap.Logger) (*queueWriter, error) {
+	q, err := newQueue(dir, int64(c.QueueMaxSize), DefaultSegmentSize)
+	if err != nil {
+		return nil, err
+	}
+
+	qw := &queueWriter{
+		w:                w,
+		q:                q,
+		logger:           logger,
+		retryInterval:    time.Duration(c.QueueRetryInterval),
+		maxRetryInterval: time.Duration(c.QueueMaxRetryInterval),
+		notify:           make(chan struct{}, 1),
+		closing:          make(chan struct{}),
+	}
+	qw.wg.Add(1)
+	go func() {
+		defer qw.wg.Done()
+		qw.run()
+	}()
+	return qw, nil
+}
+
+// WritePoints adds the points to the queue.
+func (w *queueWriter) WritePoints(p *coordinator.WritePointsRequest) error {
+	if err := w.q.Append(encodeWritePointsRequest(p)); err != nil {
+		if err == ErrQueueFull {
+			atomic.AddInt64(&w.dropped, 1)
+		}
+		return err
+	}
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// run writes the queued points to the destination. Corrupt blocks are
+// dropped, and reading or advancing the queue is retried if it fails.
+func (w *queueWriter) run() {
+	interval := w.retryInterval
+	for {
+		b, t, err := w.q.Current()
+		if err == io.EOF {
+			atomic.StoreInt64(&w.head, 0)
+			select {
+			case <-w.notify:
+				continue
+			case <-w.closing:
+				return
+			}
+		} else if err == ErrQueueClosed {
+			return
+		} else if err == ErrBlockChecksumMismatch || err == io.ErrUnexpectedEOF {
+			if err := w.q.Skip(); err == ErrQueueClosed {
+				return
+			} else if err != nil {
+				w.logger.Info("Failed to skip corrupt write in subscription queue, retrying", zap.Duration("retry_interval", interval), zap.Error(err))
+				if !w.backoff(&interval) {
+					return
+				}
+				continue
+			}
+			atomic.AddInt64(&w.rejected, 1)
+			w.logger.Info("Dropping corrupt write from subscription queue", zap.Error(err))
+			continue
+		} else if err != nil {
+			w.logger.Info("Failed to read subscription queue, retrying", zap.Duration("retry_interval", interval), zap.Error(err))
+			if !w.backoff(&interval) {
+				return
+			}
+			continue
+		}
+		atomic.StoreInt64(&w.head, t.UnixNano())
+
+		p, err := decodeWritePointsRequest(b)
+		if err != nil {
+			atomic.AddInt64(&w.rejected, 1)
+			w.logger.Info("Dropping invalid write from subscription queue", zap.Error(err))
+		} else if err := w.w.WritePoints(p); errors.As(err, new(*PermanentError)) {
+			atomic.AddInt64(&w.rejected, 1)
+			w.logger.Info("Dropping write rejected by subscription destination", zap.Error(err))
+		} else if err != nil {
+			// Retry the write once the destination is available again.
+			atomic.AddInt64(&w.retries, 1)
+			w.logger.Info("Subscription write failed, retrying", zap.Duration("retry_interval", interval), zap.Error(err))
+			if !w.backoff(&interval) {
+				return
+			}
+			continue
+		}
+
+		interval = w.retryInterval
+		for {
+			err := w.q.Advance()
+			if err == nil {
+				break
+			} else if err == ErrQueueClosed {
+				return
+			}
+			w.logger.Info("Failed to advance subscription queue, retrying", zap.Duration("retry_interval", interval), zap.Error(err))
+			if !w.backoff(&interval) {
+				return
+			}
+		}
+		interval = w.retryInterval
+	}
+}
+
+// backoff waits for interval and doubles it, up to the maximum retry
+// interval. It returns false if the writer is closed in the meantime.
+func (w *queueWriter) backoff(interval *time.Duration) bool {
+	select {
+	case <-time.After(*interval):
+	case <-w.closing:
+		return false
+	}
+	if *interval *= 2; *interval > w.maxRetryInterval {
+		*interval = w.maxRetryInterval
+	}
+	return true
+}
+
+// Close stops writing to the destination and closes the queue. Queued points
+// are written once the queue is opened again.
+func (w *queueWriter) Close() error {
+	close(w.closing)
+	w.wg.Wait()
+	return w.q.Close()
+}
+
+// Statistics returns the statistics of the queue.
+func (w *queueWriter) Statistics() map[string]interface{} {
+	var age int64
+	if head := atomic.LoadInt64(&w.head); head > 0 {
+		age = time.Now().UnixNano() - head
+	}
+	return map[string]interface{}{
+		statQueueBytes:    w.q.Size(),
+		statQueueAge:      age,
+		statQueueDropped:  atomic.LoadInt64(&w.dropped),
+		statQueueRetries:  atomic.LoadInt64(&w.retries),
+		statQueueRejected: atomic.LoadInt64(&w.rejected),
+	}
+}
+
+// encodeWritePointsRequest encodes the request as its database and retention
+// policy followed by the points in line protocol, each on its own line.
+func encodeWritePointsRequest(p *coordinator.WritePointsRequest) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(p.Database)
+	buf.WriteByte('\n')
+	buf.WriteString(p.RetentionPolicy)
+	buf.WriteByte('\n')
+	for _, pt := range p.Points {
+		buf.WriteString(pt.String())
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func decodeWritePointsRequest(b []byte) (*coordinator.WritePointsRequest, error) {
+	parts := bytes.SplitN(b, []byte("\n"), 3)
+	if len(parts) != 3 {
+		return nil, errors.New("invalid queued write")
+	}
+	points, err := models.ParsePoints(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	return &coordinator.WritePointsRequest{
+		Database:        string(parts[0]),
+		RetentionPolicy: string(parts[1]),
+		Points:          points,
+	}, nil
+}
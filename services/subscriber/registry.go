@@ -0,0 +1,74 @@
+package subscriber
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+// PointsWriterFactory creates the PointsWriters of the subscription
+// destinations that use a URL scheme.
+type PointsWriterFactory struct {
+	// New returns a PointsWriter that writes to the destination.
+	New func(u url.URL, c Config) (PointsWriter, error)
+
+	// Validate returns an error if the destination is invalid. It is called
+	// when a subscription is created.
+	Validate func(u *url.URL) error
+}
+
+var pointsWriters = struct {
+	sync.RWMutex
+	m map[string]PointsWriterFactory
+}{m: make(map[string]PointsWriterFactory)}
+
+func init() {
+	RegisterPointsWriter("udp", PointsWriterFactory{
+		New: func(u url.URL, c Config) (PointsWriter, error) {
+			return NewUDP(u.Host), nil
+		},
+		Validate: meta.ValidateHostPort,
+	})
+	RegisterPointsWriter("http", PointsWriterFactory{
+		New: func(u url.URL, c Config) (PointsWriter, error) {
+			return NewHTTP(u.String(), time.Duration(c.HTTPTimeout))
+		},
+		Validate: meta.ValidateHostPort,
+	})
+	RegisterPointsWriter("https", PointsWriterFactory{
+		New: func(u url.URL, c Config) (PointsWriter, error) {
+			return NewHTTPS(u.String(), time.Duration(c.HTTPTimeout), c.InsecureSkipVerify, c.CaCerts, c.TLS)
+		},
+		Validate: meta.ValidateHostPort,
+	})
+	RegisterPointsWriter("kafka", PointsWriterFactory{New: newKafkaFromURL, Validate: validateKafkaURL})
+
+	// Destinations on the local host are not allowed by default. The service
+	// allows them once it is opened if its config enables them.
+	pointsWriters.m["file"] = PointsWriterFactory{New: newFileFromURL}
+	pointsWriters.m["exec"] = PointsWriterFactory{New: newExecFromURL}
+}
+
+// RegisterPointsWriter registers the factory of the PointsWriters of the
+// destinations that use the URL scheme and allows subscriptions to use it.
+// It panics if the scheme has already been registered.
+func RegisterPointsWriter(scheme string, f PointsWriterFactory) {
+	pointsWriters.Lock()
+	defer pointsWriters.Unlock()
+
+	if _, ok := pointsWriters.m[scheme]; ok {
+		panic(fmt.Sprintf("subscriber: points writer already registered for scheme %s", scheme))
+	}
+	pointsWriters.m[scheme] = f
+	meta.RegisterSubscriptionScheme(scheme, f.Validate)
+}
+
+func lookupPointsWriter(scheme string) (PointsWriterFactory, bool) {
+	pointsWriters.RLock()
+	defer pointsWriters.RUnlock()
+	f, ok := pointsWriters.m[scheme]
+	return f, ok
+}
@@ -3,12 +3,17 @@
 package subscriber // import "github.com/influxdata/influxdb/services/subscriber"
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/url"
+	"os"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
-	"time"
 
 	"github.com/influxdata/influxdb/coordinator"
 	"github.com/influxdata/influxdb/logger"
@@ -50,6 +55,7 @@ type Service struct {
 	NewPointsWriter func(u url.URL) (PointsWriter, error)
 	Logger          *zap.Logger
 	update          chan struct{}
+	renames         chan renameRequest
 	stats           *Statistics
 	points          chan *coordinator.WritePointsRequest
 	wg              sync.WaitGroup
@@ -60,6 +66,10 @@ type Service struct {
 
 	subs  map[subEntry]chanWriter
 	subMu sync.RWMutex
+
+	// removing holds the subscriptions whose queues are being removed. The
+	// channels are closed once the queues are removed.
+	removing map[subEntry]chan struct{}
 }
 
 // NewService returns a subscriber service with given settings
@@ -88,8 +98,18 @@ func (s *Service) Open() error {
 
 	s.closed = false
 
+	// Subscriptions may only be created with destinations on the local host
+	// if they are enabled.
+	if s.conf.FileDestinationsEnabled {
+		meta.RegisterSubscriptionScheme("file", s.conf.validateFileURL)
+	}
+	if s.conf.ExecDestinationsEnabled {
+		meta.RegisterSubscriptionScheme("exec", s.conf.validateExecURL)
+	}
+
 	s.closing = make(chan struct{})
 	s.update = make(chan struct{})
+	s.renames = make(chan renameRequest)
 	s.points = make(chan *coordinator.WritePointsRequest, 100)
 
 	s.wg.Add(2)
@@ -185,6 +205,64 @@ func (s *Service) Update() error {
 	}
 }
 
+// renameRequest is a rename of a database, or of a retention policy if rp is
+// set, committed by commit.
+type renameRequest struct {
+	database, rp, newName string
+	commit                func() error
+	err                   chan error
+}
+
+// Rename commits the rename of a database, or of a retention policy if rp is
+// set, while its subscriptions are closed, and moves their queues to the new
+// name. The subscriptions are opened again on the next update.
+func (s *Service) Rename(database, rp, newName string, commit func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || s.conf.QueueDir == "" {
+		return commit()
+	}
+
+	req := renameRequest{database: database, rp: rp, newName: newName, commit: commit, err: make(chan error, 1)}
+	s.renames <- req
+	return <-req.err
+}
+
+// rename closes the subscriptions of a rename, commits it and moves their
+// queues.
+func (s *Service) rename(req renameRequest, wg *sync.WaitGroup) error {
+	s.subMu.Lock()
+	for se, cw := range s.subs {
+		if se.db == req.database && (req.rp == "" || se.rp == req.rp) {
+			cw.Close()
+			<-cw.closed
+			delete(s.subs, se)
+		}
+	}
+	s.subMu.Unlock()
+
+	if err := req.commit(); err != nil {
+		s.updateSubs(wg)
+		return err
+	}
+
+	from, to := filepath.Join(s.conf.QueueDir, req.database), filepath.Join(s.conf.QueueDir, req.newName)
+	if req.rp != "" {
+		from, to = filepath.Join(from, req.rp), filepath.Join(s.conf.QueueDir, req.database, req.newName)
+	}
+	if _, err := os.Stat(from); os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.Rename(from, to); err != nil {
+		// The queues are then moved with the data of the rename.
+		s.Logger.Info("Failed to move subscription queues", zap.String("path", from), zap.Error(err))
+		return nil
+	}
+	s.Logger.Info("Moved subscription queues", zap.String("path", from), zap.String("new_path", to))
+	return nil
+}
+
 func (s *Service) createSubscription(se subEntry, mode string, destinations []string) (PointsWriter, error) {
 	var bm BalanceMode
 	switch mode {
@@ -199,16 +277,29 @@ func (s *Service) createSubscription(se subEntry, mode string, destinations []st
 	stats := make([]writerStats, 0, len(destinations))
 	// add only valid destinations
 	for _, dest := range destinations {
+		// Tokens and passwords of the destination must not leak into logs,
+		// errors or statistics.
+		redacted := meta.RedactDestination(dest)
 		u, err := url.Parse(dest)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse destination: %s", dest)
+			return nil, fmt.Errorf("failed to parse destination: %s", redacted)
 		}
 		w, err := s.NewPointsWriter(*u)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create writer for destination: %s", dest)
+			return nil, fmt.Errorf("failed to create writer for destination: %s", redacted)
+		}
+		if s.conf.QueueDir != "" {
+			dir := filepath.Join(s.queueDir(se), hashedDir(dest))
+			qw, err := newQueueWriter(w, dir, s.conf, s.Logger.With(zap.String("destination", redacted)))
+			if err != nil {
+				// Close the queues that have been opened already.
+				(&balancewriter{writers: writers}).Close()
+				return nil, fmt.Errorf("failed to open queue for destination %s: %s", redacted, err)
+			}
+			w = qw
 		}
 		writers = append(writers, w)
-		stats = append(stats, writerStats{dest: dest})
+		stats = append(stats, writerStats{dest: redacted})
 	}
 
 	return &balancewriter{
@@ -224,6 +315,20 @@ func (s *Service) createSubscription(se subEntry, mode string, destinations []st
 	}, nil
 }
 
+// queueDir returns the directory of the queues of the subscription. The name
+// of the subscription is hashed so that the directory is always inside the
+// directory of its retention policy.
+func (s *Service) queueDir(se subEntry) string {
+	return filepath.Join(s.conf.QueueDir, se.db, se.rp, hashedDir(se.name))
+}
+
+// hashedDir returns the name of a directory derived from s. Destinations are
+// hashed so that credentials in their URL are not written to the file system.
+func hashedDir(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
 // Points returns a channel into which write point requests can be sent.
 func (s *Service) Points() chan<- *coordinator.WritePointsRequest {
 	return s.points
@@ -233,12 +338,15 @@ func (s *Service) Points() chan<- *coordinator.WritePointsRequest {
 func (s *Service) run() {
 	var wg sync.WaitGroup
 	s.subs = make(map[subEntry]chanWriter)
+	s.removeStaleQueues()
 	// Perform initial update
 	s.updateSubs(&wg)
 	for {
 		select {
 		case <-s.update:
 			s.updateSubs(&wg)
+		case req := <-s.renames:
+			req.err <- s.rename(req, &wg)
 		case p, ok := <-s.points:
 			if !ok {
 				// Close out all chanWriters
@@ -247,8 +355,14 @@ func (s *Service) run() {
 			}
 			for se, cw := range s.subs {
 				if p.Database == se.db && p.RetentionPolicy == se.rp {
+					wr := p
+					if cw.filter != nil {
+						if wr = cw.filter.Filter(p); wr == nil {
+							continue
+						}
+					}
 					select {
-					case cw.writeRequests <- p:
+					case cw.writeRequests <- wr:
 					default:
 						atomic.AddInt64(&s.stats.WriteFailures, 1)
 					}
@@ -278,6 +392,16 @@ func (s *Service) updateSubs(wg *sync.WaitGroup) {
 	if s.subs == nil {
 		s.subs = make(map[subEntry]chanWriter)
 	}
+	if s.removing == nil {
+		s.removing = make(map[subEntry]chan struct{})
+	}
+	for se, done := range s.removing {
+		select {
+		case <-done:
+			delete(s.removing, se)
+		default:
+		}
+	}
 
 	dbis := s.MetaClient.Databases()
 	allEntries := make(map[subEntry]bool)
@@ -291,9 +415,22 @@ func (s *Service) updateSubs(wg *sync.WaitGroup) {
 					name: si.Name,
 				}
 				allEntries[se] = true
-				if _, ok := s.subs[se]; ok {
+				filter, err := newPointFilter(si.Filter)
+				if err != nil {
+					s.Logger.Info("Invalid subscription filter, sending all points", zap.String("name", si.Name), zap.Error(err))
+				}
+				if cw, ok := s.subs[se]; ok {
+					// Apply changes to the filter of existing subscriptions.
+					cw.filter = filter
+					s.subs[se] = cw
 					continue
 				}
+				// Wait for the queues of a dropped subscription of the same
+				// name to be removed before opening its queues again.
+				if done, ok := s.removing[se]; ok {
+					<-done
+					delete(s.removing, se)
+				}
 				sub, err := s.createSubscription(se, si.Mode, si.Destinations)
 				if err != nil {
 					atomic.AddInt64(&s.stats.CreateFailures, 1)
@@ -306,14 +443,32 @@ func (s *Service) updateSubs(wg *sync.WaitGroup) {
 					pointsWritten: &s.stats.PointsWritten,
 					failures:      &s.stats.WriteFailures,
 					logger:        s.Logger,
+					filter:        filter,
+					closed:        make(chan struct{}),
 				}
+				var workers sync.WaitGroup
 				for i := 0; i < s.conf.WriteConcurrency; i++ {
 					wg.Add(1)
+					workers.Add(1)
 					go func() {
 						defer wg.Done()
+						defer workers.Done()
 						cw.Run()
 					}()
 				}
+
+				// Close the subscription once all writes have been handed to it.
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer close(cw.closed)
+					workers.Wait()
+					if c, ok := sub.(io.Closer); ok {
+						if err := c.Close(); err != nil {
+							s.Logger.Info("Failed to close subscription", zap.String("name", se.name), zap.Error(err))
+						}
+					}
+				}()
 				s.subs[se] = cw
 				s.Logger.Info("Added new subscription",
 					logger.Database(se.db),
@@ -328,6 +483,20 @@ func (s *Service) updateSubs(wg *sync.WaitGroup) {
 			// Close the chanWriter
 			s.subs[se].Close()
 
+			// Remove the queues of the subscription once they are closed.
+			if s.conf.QueueDir != "" {
+				closed, dir := s.subs[se].closed, s.queueDir(se)
+				done := make(chan struct{})
+				s.removing[se] = done
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer close(done)
+					<-closed
+					s.removeQueueDir(dir)
+				}()
+			}
+
 			// Remove it from the set
 			delete(s.subs, se)
 			s.Logger.Info("Deleted old subscription",
@@ -337,21 +506,72 @@ func (s *Service) updateSubs(wg *sync.WaitGroup) {
 	}
 }
 
+// removeStaleQueues removes the queues of subscriptions that were dropped
+// while the service was not running.
+func (s *Service) removeStaleQueues() {
+	if s.conf.QueueDir == "" {
+		return
+	}
+
+	keep := make(map[string]bool)
+	for _, dbi := range s.MetaClient.Databases() {
+		for _, rpi := range dbi.RetentionPolicies {
+			for _, si := range rpi.Subscriptions {
+				keep[s.queueDir(subEntry{db: dbi.Name, rp: rpi.Name, name: si.Name})] = true
+			}
+		}
+	}
+
+	// Queues are stored in <queue-dir>/<db>/<rp>/<subscription>.
+	dirs := []string{s.conf.QueueDir}
+	for depth := 0; depth < 3; depth++ {
+		var next []string
+		for _, dir := range dirs {
+			fis, err := ioutil.ReadDir(dir)
+			if err != nil {
+				s.Logger.Info("Failed to read subscription queue directory", zap.String("path", dir), zap.Error(err))
+				continue
+			}
+			for _, fi := range fis {
+				if fi.IsDir() {
+					next = append(next, filepath.Join(dir, fi.Name()))
+				}
+			}
+		}
+		dirs = next
+	}
+
+	for _, dir := range dirs {
+		if !keep[dir] {
+			s.Logger.Info("Removing queue of dropped subscription", zap.String("path", dir))
+			s.removeQueueDir(dir)
+		}
+	}
+}
+
+// removeQueueDir removes the queues of a subscription, and the directories
+// of its database and retention policy if no other queues are left in them.
+func (s *Service) removeQueueDir(dir string) {
+	if err := os.RemoveAll(dir); err != nil {
+		s.Logger.Info("Failed to remove subscription queue", zap.String("path", dir), zap.Error(err))
+		return
+	}
+	rpDir := filepath.Dir(dir)
+	if err := os.Remove(rpDir); err == nil {
+		os.Remove(filepath.Dir(rpDir))
+	}
+}
+
 // newPointsWriter returns a new PointsWriter from the given URL.
 func (s *Service) newPointsWriter(u url.URL) (PointsWriter, error) {
-	switch u.Scheme {
-	case "udp":
-		return NewUDP(u.Host), nil
-	case "http":
-		return NewHTTP(u.String(), time.Duration(s.conf.HTTPTimeout))
-	case "https":
-		if s.conf.InsecureSkipVerify {
-			s.Logger.Warn("'insecure-skip-verify' is true. This will skip all certificate verifications.")
-		}
-		return NewHTTPS(u.String(), time.Duration(s.conf.HTTPTimeout), s.conf.InsecureSkipVerify, s.conf.CaCerts, s.conf.TLS)
-	default:
+	f, ok := lookupPointsWriter(u.Scheme)
+	if !ok {
 		return nil, fmt.Errorf("unknown destination scheme %s", u.Scheme)
 	}
+	if u.Scheme == "https" && s.conf.InsecureSkipVerify {
+		s.Logger.Warn("'insecure-skip-verify' is true. This will skip all certificate verifications.")
+	}
+	return f.New(u, s.conf)
 }
 
 // chanWriter sends WritePointsRequest to a PointsWriter received over a channel.
@@ -361,6 +581,8 @@ type chanWriter struct {
 	pointsWritten *int64
 	failures      *int64
 	logger        *zap.Logger
+	filter        *pointFilter  // selects the points written, if set
+	closed        chan struct{} // closed once pw has been closed
 }
 
 // Close closes the chanWriter.
@@ -437,6 +659,19 @@ func (b *balancewriter) WritePoints(p *coordinator.WritePointsRequest) error {
 	return lastErr
 }
 
+// Close closes the writers of the destinations that need to be closed.
+func (b *balancewriter) Close() error {
+	var err error
+	for _, w := range b.writers {
+		if c, ok := w.(io.Closer); ok {
+			if e := c.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
 // Statistics returns statistics for periodic monitoring.
 func (b *balancewriter) Statistics(tags map[string]string) []models.Statistic {
 	statistics := make([]models.Statistic, len(b.stats))
@@ -451,6 +686,11 @@ func (b *balancewriter) Statistics(tags map[string]string) []models.Statistic {
 				statWriteFailures: atomic.LoadInt64(&b.stats[i].failures),
 			},
 		}
+		if qw, ok := b.writers[i].(*queueWriter); ok {
+			for k, v := range qw.Statistics() {
+				statistics[i].Values[k] = v
+			}
+		}
 	}
 	return statistics
 }
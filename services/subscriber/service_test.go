@@ -1,13 +1,23 @@
 package subscriber_test
 
 import (
+	"errors"
+	"io"
+	"io/ioutil"
 	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/services/subscriber"
+	"github.com/influxdata/influxdb/toml"
 )
 
 const testTimeout = 10 * time.Second
@@ -186,6 +196,436 @@ func TestService_ModeALL(t *testing.T) {
 	close(dataChanged)
 }
 
+func TestService_Queue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subscriber_queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ms := MetaClient{}
+	ms.WaitForDataChangedFn = func() chan struct{} {
+		return make(chan struct{})
+	}
+	ms.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{
+			{
+				Name: "db0",
+				RetentionPolicies: []meta.RetentionPolicyInfo{
+					{
+						Name: "rp0",
+						Subscriptions: []meta.SubscriptionInfo{
+							{Name: "s0", Mode: "ALL", Destinations: []string{"udp://h0:9093"}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	c := subscriber.NewConfig()
+	c.QueueDir = dir
+	c.QueueRetryInterval = toml.Duration(time.Millisecond)
+	c.QueueMaxRetryInterval = toml.Duration(10 * time.Millisecond)
+
+	// The destination is down while the first service is running.
+	var attempts int64
+	s := subscriber.NewService(c)
+	s.MetaClient = ms
+	s.NewPointsWriter = func(u url.URL) (subscriber.PointsWriter, error) {
+		return Subscription{WritePointsFn: func(p *coordinator.WritePointsRequest) error {
+			atomic.AddInt64(&attempts, 1)
+			return errors.New("destination is down")
+		}}, nil
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := models.ParsePointsString("cpu,host=a value=1 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expPR := &coordinator.WritePointsRequest{
+		Database:        "db0",
+		RetentionPolicy: "rp0",
+		Points:          points,
+	}
+	s.Points() <- expPR
+
+	// Wait for the write to be retried.
+	for i := 0; atomic.LoadInt64(&attempts) < 2; i++ {
+		if i == 1000 {
+			t.Fatal("expected write to be retried")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	var values map[string]interface{}
+	for _, stat := range s.Statistics(nil) {
+		if stat.Tags["destination"] == "udp://h0:9093" {
+			values = stat.Values
+		}
+	}
+	if values["queueBytes"].(int64) == 0 || values["queueRetries"].(int64) == 0 {
+		t.Fatalf("unexpected queue statistics: %v", values)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The queued write is sent once the service is restarted and the
+	// destination is up.
+	prs := make(chan *coordinator.WritePointsRequest, 1)
+	s = subscriber.NewService(c)
+	s.MetaClient = ms
+	s.NewPointsWriter = func(u url.URL) (subscriber.PointsWriter, error) {
+		return Subscription{WritePointsFn: func(p *coordinator.WritePointsRequest) error {
+			prs <- p
+			return nil
+		}}, nil
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	select {
+	case pr := <-prs:
+		if pr.Database != expPR.Database || pr.RetentionPolicy != expPR.RetentionPolicy ||
+			len(pr.Points) != 1 || pr.Points[0].String() != expPR.Points[0].String() {
+			t.Errorf("unexpected points request: got %v, exp %v", pr, expPR)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("expected queued points request")
+	}
+}
+
+// Ensure the queued writes of the subscriptions of a renamed database are
+// kept and sent under the new name.
+func TestService_Queue_Rename(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subscriber_queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var mu sync.Mutex
+	name := "db0"
+	ms := MetaClient{}
+	ms.WaitForDataChangedFn = func() chan struct{} {
+		return make(chan struct{})
+	}
+	ms.DatabasesFn = func() []meta.DatabaseInfo {
+		mu.Lock()
+		defer mu.Unlock()
+		return []meta.DatabaseInfo{
+			{
+				Name: name,
+				RetentionPolicies: []meta.RetentionPolicyInfo{
+					{
+						Name: "rp0",
+						Subscriptions: []meta.SubscriptionInfo{
+							{Name: "s0", Mode: "ALL", Destinations: []string{"udp://h0:9093"}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	c := subscriber.NewConfig()
+	c.QueueDir = dir
+	c.QueueRetryInterval = toml.Duration(time.Millisecond)
+	c.QueueMaxRetryInterval = toml.Duration(10 * time.Millisecond)
+
+	// The destination is down while the database is renamed.
+	var attempts int64
+	s := subscriber.NewService(c)
+	s.MetaClient = ms
+	s.NewPointsWriter = func(u url.URL) (subscriber.PointsWriter, error) {
+		return Subscription{WritePointsFn: func(p *coordinator.WritePointsRequest) error {
+			atomic.AddInt64(&attempts, 1)
+			return errors.New("destination is down")
+		}}, nil
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := models.ParsePointsString("cpu,host=a value=1 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Points() <- &coordinator.WritePointsRequest{
+		Database:        "db0",
+		RetentionPolicy: "rp0",
+		Points:          points,
+	}
+	for i := 0; atomic.LoadInt64(&attempts) == 0; i++ {
+		if i == 1000 {
+			t.Fatal("expected write to be queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := s.Rename("db0", "", "db1", func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		name = "db1"
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "db0")); !os.IsNotExist(err) {
+		t.Fatalf("expected queues to be moved: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The queued write is sent under the new name once the destination is up.
+	prs := make(chan *coordinator.WritePointsRequest, 1)
+	s = subscriber.NewService(c)
+	s.MetaClient = ms
+	s.NewPointsWriter = func(u url.URL) (subscriber.PointsWriter, error) {
+		return Subscription{WritePointsFn: func(p *coordinator.WritePointsRequest) error {
+			prs <- p
+			return nil
+		}}, nil
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	select {
+	case pr := <-prs:
+		if len(pr.Points) != 1 || pr.Points[0].String() != points[0].String() {
+			t.Errorf("unexpected points request: %v", pr)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("expected queued points request")
+	}
+}
+
+// Ensure the queues of a dropped subscription are removed without escaping
+// the queue directory, whatever the name of the subscription.
+func TestService_Queue_DropTraversalName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subscriber_queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	victim := filepath.Join(dir, "victim")
+	if err := os.MkdirAll(victim, 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	subs := []meta.SubscriptionInfo{
+		{Name: "../../../victim", Mode: "ALL", Destinations: []string{"udp://h0:9093"}},
+	}
+	dataChanged := make(chan struct{}, 1)
+	ms := MetaClient{}
+	ms.WaitForDataChangedFn = func() chan struct{} {
+		return dataChanged
+	}
+	ms.DatabasesFn = func() []meta.DatabaseInfo {
+		mu.Lock()
+		defer mu.Unlock()
+		return []meta.DatabaseInfo{{
+			Name: "db0",
+			RetentionPolicies: []meta.RetentionPolicyInfo{
+				{Name: "rp0", Subscriptions: subs},
+			},
+		}}
+	}
+
+	c := subscriber.NewConfig()
+	c.QueueDir = filepath.Join(dir, "queue")
+	s := subscriber.NewService(c)
+	s.MetaClient = ms
+	s.NewPointsWriter = func(u url.URL) (subscriber.PointsWriter, error) {
+		return Subscription{WritePointsFn: func(p *coordinator.WritePointsRequest) error {
+			return nil
+		}}, nil
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	rpDir := filepath.Join(c.QueueDir, "db0", "rp0")
+	queues := func() int {
+		fis, err := ioutil.ReadDir(rpDir)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		return len(fis)
+	}
+	for i := 0; queues() == 0; i++ {
+		if i == 1000 {
+			t.Fatal("expected subscription queue")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Drop the subscription.
+	mu.Lock()
+	subs = nil
+	mu.Unlock()
+	dataChanged <- struct{}{}
+	for i := 0; queues() != 0; i++ {
+		if i == 1000 {
+			t.Fatal("expected subscription queue to be removed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := os.Stat(victim); err != nil {
+		t.Fatalf("directory outside the queue directory removed: %v", err)
+	}
+}
+
+// Ensure the queues of subscriptions dropped while the service was not
+// running are removed when it is opened.
+func TestService_Queue_RemoveStale(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subscriber_queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := subscriber.NewConfig()
+	c.QueueDir = dir
+	stale := []string{
+		filepath.Join(dir, "db0", "rp0", "stale"),
+		filepath.Join(dir, "db1", "rp0", "stale"),
+	}
+	for _, path := range stale {
+		if err := os.MkdirAll(path, 0777); err != nil {
+			t.Fatal(err)
+		} else if err := ioutil.WriteFile(filepath.Join(path, "0"), nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ms := MetaClient{}
+	ms.WaitForDataChangedFn = func() chan struct{} {
+		return make(chan struct{})
+	}
+	ms.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{{
+			Name: "db0",
+			RetentionPolicies: []meta.RetentionPolicyInfo{{
+				Name: "rp0",
+				Subscriptions: []meta.SubscriptionInfo{
+					{Name: "s0", Mode: "ALL", Destinations: []string{"udp://h0:9093"}},
+				},
+			}},
+		}}
+	}
+
+	s := subscriber.NewService(c)
+	s.MetaClient = ms
+	s.NewPointsWriter = func(u url.URL) (subscriber.PointsWriter, error) {
+		return Subscription{WritePointsFn: func(p *coordinator.WritePointsRequest) error {
+			return nil
+		}}, nil
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// Only the queue of the existing subscription is left.
+	for i := 0; ; i++ {
+		fis, err := ioutil.ReadDir(filepath.Join(dir, "db0", "rp0"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(fis) == 1 && fis[0].Name() != "stale" {
+			break
+		} else if i == 1000 {
+			t.Fatalf("unexpected queues: %d", len(fis))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "db1")); !os.IsNotExist(err) {
+		t.Fatalf("expected queue directory of database to be removed: %v", err)
+	}
+}
+
+func TestService_Filter(t *testing.T) {
+	ms := MetaClient{}
+	ms.WaitForDataChangedFn = func() chan struct{} {
+		return make(chan struct{})
+	}
+	ms.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{
+			{
+				Name: "db0",
+				RetentionPolicies: []meta.RetentionPolicyInfo{
+					{
+						Name: "rp0",
+						Subscriptions: []meta.SubscriptionInfo{
+							{Name: "s0", Mode: "ALL", Destinations: []string{"udp://h0:9093"}, Filter: &meta.SubscriptionFilter{
+								Measurements:     []string{"cpu"},
+								MeasurementRegex: "^disk",
+								Condition:        "host = 'a' OR region =~ /^us-/",
+							}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	prs := make(chan *coordinator.WritePointsRequest, 2)
+	s := subscriber.NewService(subscriber.NewConfig())
+	s.MetaClient = ms
+	s.NewPointsWriter = func(u url.URL) (subscriber.PointsWriter, error) {
+		return Subscription{WritePointsFn: func(p *coordinator.WritePointsRequest) error {
+			prs <- p
+			return nil
+		}}, nil
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for _, lines := range []string{
+		"mem,host=a value=1 10\ncpu,host=b value=1 10",
+		"cpu,host=a value=1 10\nmem,host=a value=2 10\ncpu,host=b value=3 10\ndisk_io,region=us-west value=4 10\ncpu value=5 10",
+	} {
+		points, err := models.ParsePointsString(lines)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Points() <- &coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: points}
+	}
+
+	// Only the second request has points that match the filter.
+	select {
+	case pr := <-prs:
+		var got []string
+		for _, pt := range pr.Points {
+			got = append(got, pt.String())
+		}
+		if exp := []string{"cpu,host=a value=1 10", "disk_io,region=us-west value=4 10"}; !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected points: got %v, exp %v", got, exp)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("expected points request")
+	}
+}
+
 func TestService_ModeANY(t *testing.T) {
 	dataChanged := make(chan struct{})
 	ms := MetaClient{}
@@ -443,3 +883,164 @@ func TestService_WaitForDataChanged(t *testing.T) {
 
 	close(dataChanged)
 }
+
+func TestService_Queue_Rejected(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subscriber_queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ms := MetaClient{}
+	ms.WaitForDataChangedFn = func() chan struct{} {
+		return make(chan struct{})
+	}
+	ms.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{
+			{
+				Name: "db0",
+				RetentionPolicies: []meta.RetentionPolicyInfo{
+					{
+						Name: "rp0",
+						Subscriptions: []meta.SubscriptionInfo{
+							{Name: "s0", Mode: "ALL", Destinations: []string{"udp://h0:9093"}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	c := subscriber.NewConfig()
+	c.QueueDir = dir
+	c.QueueRetryInterval = toml.Duration(time.Millisecond)
+	c.QueueMaxRetryInterval = toml.Duration(10 * time.Millisecond)
+
+	// The destination rejects the first write and accepts the second one.
+	var attempts int64
+	prs := make(chan *coordinator.WritePointsRequest, 1)
+	s := subscriber.NewService(c)
+	s.MetaClient = ms
+	s.NewPointsWriter = func(u url.URL) (subscriber.PointsWriter, error) {
+		return Subscription{WritePointsFn: func(p *coordinator.WritePointsRequest) error {
+			if atomic.AddInt64(&attempts, 1) == 1 {
+				return &subscriber.PermanentError{Err: errors.New("field type conflict")}
+			}
+			prs <- p
+			return nil
+		}}, nil
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for _, line := range []string{"cpu value=1 10", "cpu value=2 20"} {
+		points, err := models.ParsePointsString(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Points() <- &coordinator.WritePointsRequest{
+			Database:        "db0",
+			RetentionPolicy: "rp0",
+			Points:          points,
+		}
+	}
+
+	select {
+	case pr := <-prs:
+		if exp := "cpu value=2 20"; len(pr.Points) != 1 || pr.Points[0].String() != exp {
+			t.Errorf("unexpected points: got %v, exp %s", pr.Points, exp)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("expected second points request")
+	}
+	if n := atomic.LoadInt64(&attempts); n != 2 {
+		t.Errorf("unexpected write attempts: got %d, exp 2", n)
+	}
+
+	var values map[string]interface{}
+	for _, stat := range s.Statistics(nil) {
+		if stat.Tags["destination"] == "udp://h0:9093" {
+			values = stat.Values
+		}
+	}
+	if values["queueRejected"].(int64) != 1 || values["queueRetries"].(int64) != 0 {
+		t.Fatalf("unexpected queue statistics: %v", values)
+	}
+}
+
+func TestService_NewPointsWriter_LocalDestinations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subscriber_local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		config func(c *subscriber.Config)
+		dest   string
+		err    bool
+	}{
+		{name: "file disabled", dest: "file://" + filepath.Join(dir, "out.lp"), err: true},
+		{name: "exec disabled", dest: "exec://" + exe, err: true},
+		{
+			name: "file allowed",
+			config: func(c *subscriber.Config) {
+				c.FileDestinationsEnabled, c.FileDestinationDirs = true, []string{dir}
+			},
+			dest: "file://" + filepath.Join(dir, "out.lp"),
+		},
+		{
+			name: "file outside dirs",
+			config: func(c *subscriber.Config) {
+				c.FileDestinationsEnabled, c.FileDestinationDirs = true, []string{dir}
+			},
+			dest: "file://" + filepath.Join(dir, "..", "out.lp"),
+			err:  true,
+		},
+		{
+			name: "exec allowed",
+			config: func(c *subscriber.Config) {
+				c.ExecDestinationsEnabled, c.ExecDestinationCommands = true, []string{exe}
+			},
+			dest: "exec://" + exe,
+		},
+		{
+			name: "exec not allowed",
+			config: func(c *subscriber.Config) {
+				c.ExecDestinationsEnabled, c.ExecDestinationCommands = true, []string{exe}
+			},
+			dest: "exec:///bin/sh",
+			err:  true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := subscriber.NewConfig()
+			if tt.config != nil {
+				tt.config(&c)
+			}
+			if err := c.Validate(); err != nil {
+				t.Fatal(err)
+			}
+
+			u, err := url.Parse(tt.dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w, err := subscriber.NewService(c).NewPointsWriter(*u)
+			if got := err != nil; got != tt.err {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c, ok := w.(io.Closer); ok {
+				c.Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package tcp
+
+import (
+	"crypto/tls"
+	"errors"
+	"time"
+
+	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/toml"
+)
+
+const (
+	// DefaultBindAddress is the default binding interface if none is specified.
+	DefaultBindAddress = ":8094"
+
+	// DefaultDatabase is the default database for TCP traffic.
+	DefaultDatabase = "tcp"
+
+	// DefaultRetentionPolicy is the default retention policy used for writes.
+	DefaultRetentionPolicy = ""
+
+	// DefaultPrecision is the default time precision used for TCP services.
+	DefaultPrecision = "n"
+
+	// DefaultBatchSize is the default TCP batch size.
+	DefaultBatchSize = 5000
+
+	// DefaultBatchPending is the default number of pending TCP batches.
+	DefaultBatchPending = 10
+
+	// DefaultBatchTimeout is the default TCP batch timeout.
+	DefaultBatchTimeout = time.Second
+
+	// DefaultMaxLineSize is the default maximum size of a line.
+	DefaultMaxLineSize = 1024 * 1024 // 1MB
+
+	// DefaultCertificate is the default location of the certificate used when TLS is enabled.
+	DefaultCertificate = "/etc/ssl/influxdb.pem"
+)
+
+// Config holds various configuration settings for the TCP listener.
+type Config struct {
+	Enabled     bool   `toml:"enabled"`
+	BindAddress string `toml:"bind-address"`
+
+	Database              string        `toml:"database"`
+	RetentionPolicy       string        `toml:"retention-policy"`
+	AllowDatabaseOverride bool          `toml:"allow-database-override"`
+	Precision             string        `toml:"precision"`
+	BatchSize             int           `toml:"batch-size"`
+	BatchPending          int           `toml:"batch-pending"`
+	BatchTimeout          toml.Duration `toml:"batch-timeout"`
+	MaxLineSize           toml.Size     `toml:"max-line-size"`
+	ReadTimeout           toml.Duration `toml:"read-timeout"`
+
+	TLSEnabled  bool        `toml:"tls-enabled"`
+	Certificate string      `toml:"certificate"`
+	PrivateKey  string      `toml:"private-key"`
+	TLS         *tls.Config `toml:"-"`
+}
+
+// NewConfig returns a new instance of Config with defaults.
+func NewConfig() Config {
+	return Config{
+		BindAddress:     DefaultBindAddress,
+		Database:        DefaultDatabase,
+		RetentionPolicy: DefaultRetentionPolicy,
+		Precision:       DefaultPrecision,
+		BatchSize:       DefaultBatchSize,
+		BatchPending:    DefaultBatchPending,
+		BatchTimeout:    toml.Duration(DefaultBatchTimeout),
+		MaxLineSize:     DefaultMaxLineSize,
+		Certificate:     DefaultCertificate,
+	}
+}
+
+// WithDefaults takes the given config and returns a new config with any required
+// default values set.
+func (c *Config) WithDefaults() *Config {
+	d := *c
+	if d.BindAddress == "" {
+		d.BindAddress = DefaultBindAddress
+	}
+	if d.Database == "" {
+		d.Database = DefaultDatabase
+	}
+	if d.Precision == "" {
+		d.Precision = DefaultPrecision
+	}
+	if d.BatchSize == 0 {
+		d.BatchSize = DefaultBatchSize
+	}
+	if d.BatchPending == 0 {
+		d.BatchPending = DefaultBatchPending
+	}
+	if d.BatchTimeout == 0 {
+		d.BatchTimeout = toml.Duration(DefaultBatchTimeout)
+	}
+	if d.MaxLineSize == 0 {
+		d.MaxLineSize = DefaultMaxLineSize
+	}
+	if d.Certificate == "" {
+		d.Certificate = DefaultCertificate
+	}
+	return &d
+}
+
+// Validate returns an error if the Config is invalid.
+func (c *Config) Validate() error {
+	if !validPrecision(c.Precision) {
+		return errors.New("invalid precision: must be one of n, u, ms, s, m or h")
+	}
+	if c.MaxLineSize < 0 {
+		return errors.New("max-line-size must not be negative")
+	}
+	return nil
+}
+
+// validPrecision returns true if p is a precision of the line protocol.
+func validPrecision(p string) bool {
+	switch p {
+	case "", "n", "u", "ms", "s", "m", "h":
+		return true
+	}
+	return false
+}
+
+// Configs wraps a slice of Config to aggregate diagnostics.
+type Configs []Config
+
+// Diagnostics returns one set of diagnostics for all of the Configs.
+func (c Configs) Diagnostics() (*diagnostics.Diagnostics, error) {
+	d := &diagnostics.Diagnostics{
+		Columns: []string{"enabled", "bind-address", "database", "retention-policy", "allow-database-override", "batch-size", "batch-pending", "batch-timeout", "precision", "tls-enabled"},
+	}
+
+	for _, cc := range c {
+		if !cc.Enabled {
+			d.AddRow([]interface{}{false})
+			continue
+		}
+
+		r := []interface{}{true, cc.BindAddress, cc.Database, cc.RetentionPolicy, cc.AllowDatabaseOverride, cc.BatchSize, cc.BatchPending, cc.BatchTimeout, cc.Precision, cc.TLSEnabled}
+		d.AddRow(r)
+	}
+
+	return d, nil
+}
+
+// Enabled returns true if any underlying Config is Enabled.
+func (c Configs) Enabled() bool {
+	for _, cc := range c {
+		if cc.Enabled {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package tcp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/influxdata/influxdb/services/tcp"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	// Parse configuration.
+	var c tcp.Config
+	if _, err := toml.Decode(`
+enabled = true
+bind-address = ":4444"
+database = "awesomedb"
+retention-policy = "awesomerp"
+allow-database-override = true
+precision = "s"
+batch-size = 100
+batch-pending = 9
+batch-timeout = "10ms"
+max-line-size = "64k"
+read-timeout = "1m"
+tls-enabled = true
+certificate = "/etc/ssl/cert.pem"
+private-key = "/etc/ssl/key.pem"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate configuration.
+	if !c.Enabled {
+		t.Fatalf("unexpected enabled: %v", c.Enabled)
+	} else if c.BindAddress != ":4444" {
+		t.Fatalf("unexpected bind address: %s", c.BindAddress)
+	} else if c.Database != "awesomedb" {
+		t.Fatalf("unexpected database: %s", c.Database)
+	} else if c.RetentionPolicy != "awesomerp" {
+		t.Fatalf("unexpected retention policy: %s", c.RetentionPolicy)
+	} else if !c.AllowDatabaseOverride {
+		t.Fatalf("unexpected allow database override: %v", c.AllowDatabaseOverride)
+	} else if c.Precision != "s" {
+		t.Fatalf("unexpected precision: %s", c.Precision)
+	} else if c.BatchSize != 100 {
+		t.Fatalf("unexpected batch size: %d", c.BatchSize)
+	} else if c.BatchPending != 9 {
+		t.Fatalf("unexpected batch pending: %d", c.BatchPending)
+	} else if time.Duration(c.BatchTimeout) != (10 * time.Millisecond) {
+		t.Fatalf("unexpected batch timeout: %v", c.BatchTimeout)
+	} else if c.MaxLineSize != 64*1024 {
+		t.Fatalf("unexpected max line size: %d", c.MaxLineSize)
+	} else if time.Duration(c.ReadTimeout) != time.Minute {
+		t.Fatalf("unexpected read timeout: %v", c.ReadTimeout)
+	} else if !c.TLSEnabled {
+		t.Fatalf("unexpected tls enabled: %v", c.TLSEnabled)
+	} else if c.Certificate != "/etc/ssl/cert.pem" {
+		t.Fatalf("unexpected certificate: %s", c.Certificate)
+	} else if c.PrivateKey != "/etc/ssl/key.pem" {
+		t.Fatalf("unexpected private key: %s", c.PrivateKey)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := tcp.NewConfig()
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Precision = "d"
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for invalid precision")
+	}
+}
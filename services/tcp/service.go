@@ -0,0 +1,575 @@
+// Package tcp provides the TCP input service for InfluxDB, which accepts
+// newline-delimited line protocol.
+package tcp // import "github.com/influxdata/influxdb/services/tcp"
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/monitor"
+	"github.com/influxdata/influxdb/services/audit"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// statistics gathered by the TCP package.
+const (
+	statConnectionsActive   = "connsActive"
+	statConnectionsHandled  = "connsHandled"
+	statPointsReceived      = "pointsRx"
+	statBytesReceived       = "bytesRx"
+	statPointsParseFail     = "pointsParseFail"
+	statReadFail            = "readFail"
+	statBatchesTransmitted  = "batchesTx"
+	statPointsTransmitted   = "pointsTx"
+	statBatchesTransmitFail = "batchesTxFail"
+)
+
+// destination is the database and retention policy points are written to.
+type destination struct {
+	database        string
+	retentionPolicy string
+}
+
+// batcher batches the points of the connections writing to a destination.
+// It is stopped once no connection writes to the destination.
+type batcher struct {
+	*tsdb.PointBatcher
+	refs int           // number of connections writing to the destination
+	done chan struct{} // closed once the batcher is stopped
+}
+
+// Service is a TCP service that listens for newline-delimited line protocol.
+//
+// The first line of a connection may be a header, a comment setting the
+// database, retention policy or precision of the points of the connection:
+//
+//	# database=mydb retention-policy=autogen precision=s
+//
+// The database and retention policy can only be set if the database override
+// is allowed by the configuration, to an existing database other than the
+// internal database.
+type Service struct {
+	ln   net.Listener
+	addr net.Addr
+	wg   sync.WaitGroup
+
+	mu       sync.RWMutex
+	ready    bool          // Has the required database been created?
+	done     chan struct{} // Is the service closing or closed?
+	batchers map[destination]*batcher
+
+	connsMu sync.Mutex
+	conns   map[net.Conn]struct{}
+
+	config Config
+
+	PointsWriter interface {
+		WritePointsPrivileged(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error
+	}
+
+	MetaClient interface {
+		CreateDatabase(name string) (*meta.DatabaseInfo, error)
+		Database(name string) *meta.DatabaseInfo
+	}
+
+	// InternalDatabase is the database of the monitor, which points cannot
+	// be written to by setting it in a header.
+	InternalDatabase string
+
+	// AuditDatabase is the database of the audit records, which points
+	// cannot be written to by setting it in a header either.
+	AuditDatabase string
+
+	Logger      *zap.Logger
+	stats       *Statistics
+	defaultTags models.StatisticTags
+}
+
+// NewService returns a new instance of Service.
+func NewService(c Config) *Service {
+	d := *c.WithDefaults()
+	return &Service{
+		config:           d,
+		conns:            make(map[net.Conn]struct{}),
+		InternalDatabase: monitor.DefaultStoreDatabase,
+		AuditDatabase:    audit.DefaultStoreDatabase,
+		Logger:           zap.NewNop(),
+		stats:            &Statistics{},
+		defaultTags:      models.StatisticTags{"bind": d.BindAddress},
+	}
+}
+
+// Open starts the service.
+func (s *Service) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed() {
+		return nil // Already open.
+	}
+
+	if err := s.config.Validate(); err != nil {
+		return err
+	}
+
+	ln, err := s.listen()
+	if err != nil {
+		s.Logger.Info("Failed to set up TCP listener",
+			zap.String("bind_address", s.config.BindAddress), zap.Error(err))
+		return err
+	}
+	s.ln, s.addr = ln, ln.Addr()
+	s.done = make(chan struct{})
+	s.batchers = make(map[destination]*batcher)
+
+	s.Logger.Info("Started listening on TCP",
+		zap.Stringer("addr", s.addr),
+		zap.Bool("tls", s.config.TLSEnabled))
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return nil
+}
+
+// listen opens the listener, using TLS if it is enabled.
+func (s *Service) listen() (net.Listener, error) {
+	if !s.config.TLSEnabled {
+		return net.Listen("tcp", s.config.BindAddress)
+	}
+
+	key := s.config.PrivateKey
+	if key == "" {
+		key = s.config.Certificate
+	}
+	cert, err := tls.LoadX509KeyPair(s.config.Certificate, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var tlsConfig *tls.Config
+	if s.config.TLS != nil {
+		tlsConfig = s.config.TLS.Clone()
+	} else {
+		tlsConfig = new(tls.Config)
+	}
+	tlsConfig.Certificates = []tls.Certificate{cert}
+	return tls.Listen("tcp", s.config.BindAddress, tlsConfig)
+}
+
+// Statistics maintains statistics for the TCP service.
+type Statistics struct {
+	ActiveConnections   int64
+	HandledConnections  int64
+	PointsReceived      int64
+	BytesReceived       int64
+	PointsParseFail     int64
+	ReadFail            int64
+	BatchesTransmitted  int64
+	PointsTransmitted   int64
+	BatchesTransmitFail int64
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (s *Service) Statistics(tags map[string]string) []models.Statistic {
+	return []models.Statistic{{
+		Name: "tcp",
+		Tags: s.defaultTags.Merge(tags),
+		Values: map[string]interface{}{
+			statConnectionsActive:   atomic.LoadInt64(&s.stats.ActiveConnections),
+			statConnectionsHandled:  atomic.LoadInt64(&s.stats.HandledConnections),
+			statPointsReceived:      atomic.LoadInt64(&s.stats.PointsReceived),
+			statBytesReceived:       atomic.LoadInt64(&s.stats.BytesReceived),
+			statPointsParseFail:     atomic.LoadInt64(&s.stats.PointsParseFail),
+			statReadFail:            atomic.LoadInt64(&s.stats.ReadFail),
+			statBatchesTransmitted:  atomic.LoadInt64(&s.stats.BatchesTransmitted),
+			statPointsTransmitted:   atomic.LoadInt64(&s.stats.PointsTransmitted),
+			statBatchesTransmitFail: atomic.LoadInt64(&s.stats.BatchesTransmitFail),
+		},
+	}}
+}
+
+func (s *Service) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.ln.Accept()
+		if opErr, ok := err.(*net.OpError); ok && !opErr.Temporary() {
+			s.Logger.Info("TCP listener closed")
+			return
+		}
+		if err != nil {
+			s.Logger.Info("Error accepting TCP connection", zap.Error(err))
+			continue
+		}
+
+		s.wg.Add(1)
+		go s.handleConn(conn)
+	}
+}
+
+// handleConn reads the points of a connection until it is closed. Malformed
+// lines are counted and logged, and do not close the connection.
+func (s *Service) handleConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+	defer atomic.AddInt64(&s.stats.ActiveConnections, -1)
+	defer s.untrackConnection(conn)
+	atomic.AddInt64(&s.stats.ActiveConnections, 1)
+	atomic.AddInt64(&s.stats.HandledConnections, 1)
+	if !s.trackConnection(conn) {
+		return
+	}
+
+	log := s.Logger.With(zap.Stringer("remote_addr", conn.RemoteAddr()))
+	dest := destination{database: s.config.Database, retentionPolicy: s.config.RetentionPolicy}
+	precision := s.config.Precision
+
+	// The batcher of the destination is acquired on the first point.
+	var b *batcher
+	defer func() {
+		if b != nil {
+			s.releaseBatcher(dest)
+		}
+	}()
+
+	r := bufio.NewReader(conn)
+	for first := true; ; first = false {
+		if s.config.ReadTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(time.Duration(s.config.ReadTimeout)))
+		}
+		line, err := readLine(r, int(s.config.MaxLineSize))
+		if err == errLineTooLong {
+			atomic.AddInt64(&s.stats.PointsParseFail, 1)
+			log.Info("Line exceeds the maximum line size", zap.Int("max_line_size", int(s.config.MaxLineSize)))
+			continue
+		} else if err != nil && len(line) == 0 {
+			if err != io.EOF {
+				atomic.AddInt64(&s.stats.ReadFail, 1)
+				log.Info("Failed to read from TCP connection", zap.Error(err))
+			}
+			return
+		}
+		atomic.AddInt64(&s.stats.BytesReceived, int64(len(line)))
+
+		line = bytes.TrimSpace(line)
+		if first && len(line) > 0 && line[0] == '#' {
+			ok, herr := s.applyHeader(string(line[1:]), &dest, &precision)
+			if herr != nil {
+				log.Info("Invalid header, closing connection", zap.Error(herr))
+				return
+			} else if ok {
+				log.Info("Writing points of connection",
+					logger.Database(dest.database),
+					logger.RetentionPolicy(dest.retentionPolicy),
+					zap.String("precision", precision))
+				continue
+			}
+		}
+
+		if len(line) > 0 && line[0] != '#' {
+			if b == nil {
+				if b = s.acquireBatcher(dest); b == nil {
+					return // The service is closing.
+				}
+			}
+			s.handleLine(log, line, b, precision)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleLine parses the point of a line and adds it to the batch of its
+// destination.
+func (s *Service) handleLine(log *zap.Logger, line []byte, b *batcher, precision string) {
+	points, err := models.ParsePointsWithPrecision(line, time.Now().UTC(), precision)
+	if err != nil {
+		atomic.AddInt64(&s.stats.PointsParseFail, 1)
+		log.Info("Failed to parse point", zap.Error(err))
+		return
+	}
+
+	for _, pt := range points {
+		select {
+		case b.In() <- pt:
+		case <-s.done:
+			return
+		}
+	}
+	atomic.AddInt64(&s.stats.PointsReceived, int64(len(points)))
+}
+
+// applyHeader applies the settings of a header line. It returns false if the
+// line is a comment without settings.
+func (s *Service) applyHeader(line string, dest *destination, precision *string) (bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || !strings.Contains(fields[0], "=") {
+		return false, nil
+	}
+
+	d, p := *dest, *precision
+	for _, f := range fields {
+		i := strings.IndexByte(f, '=')
+		if i <= 0 {
+			return true, fmt.Errorf("invalid setting %q", f)
+		}
+		k, v := f[:i], f[i+1:]
+		switch k {
+		case "database":
+			if v == "" {
+				return true, errors.New("empty database")
+			}
+			d.database = v
+		case "retention-policy":
+			d.retentionPolicy = v
+		case "precision":
+			if !validPrecision(v) {
+				return true, fmt.Errorf("invalid precision %q", v)
+			}
+			p = v
+		default:
+			return true, fmt.Errorf("unknown setting %q", k)
+		}
+	}
+
+	if d != *dest {
+		if !s.config.AllowDatabaseOverride {
+			return true, errors.New("database override is not allowed")
+		} else if d.database == s.InternalDatabase || d.database == s.AuditDatabase {
+			return true, fmt.Errorf("cannot write to database %q", d.database)
+		}
+		dbi := s.MetaClient.Database(d.database)
+		if dbi == nil {
+			return true, fmt.Errorf("database not found: %q", d.database)
+		} else if d.retentionPolicy != "" && dbi.RetentionPolicy(d.retentionPolicy) == nil {
+			return true, fmt.Errorf("retention policy not found: %q", d.retentionPolicy)
+		}
+	}
+	*dest, *precision = d, p
+	return true, nil
+}
+
+// errLineTooLong is returned by readLine if a line exceeds the maximum size.
+var errLineTooLong = errors.New("line too long")
+
+// readLine reads a line. If the line exceeds the maximum size, the rest of
+// the line is discarded and errLineTooLong is returned.
+func readLine(r *bufio.Reader, maxSize int) ([]byte, error) {
+	var line []byte
+	for {
+		b, err := r.ReadSlice('\n')
+		if len(line)+len(b) > maxSize {
+			for err == bufio.ErrBufferFull {
+				_, err = r.ReadSlice('\n')
+			}
+			if err != nil {
+				return nil, err
+			}
+			return nil, errLineTooLong
+		}
+		line = append(line, b...)
+		if err != bufio.ErrBufferFull {
+			return line, err
+		}
+	}
+}
+
+// acquireBatcher returns the batcher of the destination, which is started
+// with its writer if needed. The batcher must be released once the
+// connection stops writing to the destination. It returns nil if the service
+// is closed.
+func (s *Service) acquireBatcher(dest destination) *batcher {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed() {
+		return nil
+	} else if b := s.batchers[dest]; b != nil {
+		b.refs++
+		return b
+	}
+
+	b := &batcher{
+		PointBatcher: tsdb.NewPointBatcher(s.config.BatchSize, s.config.BatchPending, time.Duration(s.config.BatchTimeout)),
+		refs:         1,
+		done:         make(chan struct{}),
+	}
+	b.Start()
+	s.batchers[dest] = b
+
+	s.wg.Add(1)
+	go s.writer(b, dest)
+	return b
+}
+
+// releaseBatcher releases the batcher of the destination. The batcher is
+// stopped once it is released by all the connections writing to the
+// destination, and its writer once it has written the last batch.
+func (s *Service) releaseBatcher(dest destination) {
+	s.mu.Lock()
+	b := s.batchers[dest]
+	if b.refs--; b.refs > 0 {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.batchers, dest)
+	s.mu.Unlock()
+
+	b.Stop()
+	close(b.done)
+}
+
+func (s *Service) writer(b *batcher, dest destination) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case batch := <-b.Out():
+			// Will attempt to create database if not yet created.
+			if err := s.createInternalStorage(); err != nil {
+				s.Logger.Info("Required database does not yet exist",
+					logger.Database(s.config.Database), zap.Error(err))
+				continue
+			}
+
+			if err := s.PointsWriter.WritePointsPrivileged(dest.database, dest.retentionPolicy, models.ConsistencyLevelAny, batch); err == nil {
+				atomic.AddInt64(&s.stats.BatchesTransmitted, 1)
+				atomic.AddInt64(&s.stats.PointsTransmitted, int64(len(batch)))
+			} else {
+				s.Logger.Info("Failed to write point batch to database",
+					logger.Database(dest.database), zap.Error(err))
+				atomic.AddInt64(&s.stats.BatchesTransmitFail, 1)
+			}
+
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// trackConnection tracks the connection so that it is closed when the
+// service is closed. It returns false if the service is closing.
+func (s *Service) trackConnection(conn net.Conn) bool {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	if s.conns == nil {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Service) untrackConnection(conn net.Conn) {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	delete(s.conns, conn)
+}
+
+func (s *Service) closeAllConnections() {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+// Close closes the service, the underlying listener and the connections.
+// The points received from the connections are written before it returns.
+func (s *Service) Close() error {
+	if wait := func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.closed() {
+			return false // Already closed.
+		}
+		close(s.done)
+
+		if s.ln != nil {
+			s.ln.Close()
+		}
+		s.closeAllConnections()
+		return true
+	}(); !wait {
+		return nil
+	}
+	s.wg.Wait()
+
+	// Release all remaining resources.
+	s.mu.Lock()
+	s.done = nil
+	s.ln = nil
+	s.batchers = nil
+	s.mu.Unlock()
+
+	s.connsMu.Lock()
+	s.conns = make(map[net.Conn]struct{})
+	s.connsMu.Unlock()
+
+	s.Logger.Info("Service closed")
+
+	return nil
+}
+
+// Closed returns true if the service is currently closed.
+func (s *Service) Closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed()
+}
+
+func (s *Service) closed() bool {
+	select {
+	case <-s.done:
+		// Service is closing.
+		return true
+	default:
+	}
+	return s.done == nil
+}
+
+// createInternalStorage ensures that the required database has been created.
+// Databases set by the header of a connection are not created.
+func (s *Service) createInternalStorage() error {
+	s.mu.RLock()
+	ready := s.ready
+	s.mu.RUnlock()
+	if ready {
+		return nil
+	}
+
+	if _, err := s.MetaClient.CreateDatabase(s.config.Database); err != nil {
+		return err
+	}
+
+	// The service is now ready.
+	s.mu.Lock()
+	s.ready = true
+	s.mu.Unlock()
+	return nil
+}
+
+// WithLogger sets the logger on the service.
+func (s *Service) WithLogger(log *zap.Logger) {
+	s.Logger = log.With(zap.String("service", "tcp"))
+}
+
+// Addr returns the listener's address.
+func (s *Service) Addr() net.Addr {
+	return s.addr
+}
@@ -0,0 +1,396 @@
+package tcp
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/internal"
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/toml"
+)
+
+func TestService_OpenClose(t *testing.T) {
+	s := NewTestService(nil)
+
+	// Closing a closed service is fine.
+	if err := s.Service.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Opening an already open service is fine.
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening a previously opened service is fine.
+	if err := s.Service.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tidy up.
+	if err := s.Service.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_Write(t *testing.T) {
+	t.Parallel()
+
+	c := NewTestConfig()
+	c.Precision = "s"
+	s := NewTestService(&c)
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	conn, err := net.Dial("tcp", s.Service.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Malformed lines do not close the connection.
+	if _, err := conn.Write([]byte("# a comment\ncpu value=1 10\nbad line\n\ncpu,host=a value=2 20\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{"tcp/: cpu value=1 10000000000", "tcp/: cpu,host=a value=2 20000000000"}
+	if got := s.Wait(t, 2); !equalStrings(got, exp) {
+		t.Fatalf("unexpected points:\ngot %v\nexp %v", got, exp)
+	}
+
+	stats := s.Service.Statistics(nil)[0].Values
+	if stats[statPointsReceived] != int64(2) || stats[statPointsParseFail] != int64(1) {
+		t.Fatalf("unexpected statistics: %v", stats)
+	}
+}
+
+func TestService_Header(t *testing.T) {
+	t.Parallel()
+
+	c := NewTestConfig()
+	c.AllowDatabaseOverride = true
+	s := NewTestService(&c)
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	for _, data := range []string{
+		"# database=db0 retention-policy=rp0 precision=ms\ncpu value=1 1000\n",
+		"cpu value=2 2000000000\n",
+	} {
+		conn, err := net.Dial("tcp", s.Service.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}
+
+	exp := []string{"db0/rp0: cpu value=1 1000000000", "tcp/: cpu value=2 2000000000"}
+	if got := s.Wait(t, 2); !equalStrings(got, exp) {
+		t.Fatalf("unexpected points:\ngot %v\nexp %v", got, exp)
+	}
+}
+
+func TestService_Header_OverrideNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	s := NewTestService(nil)
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	s.ExpectClosed(t, "# database=db0\ncpu value=1\n")
+}
+
+// Ensure the header cannot set the internal and audit databases or databases
+// and retention policies that do not exist.
+func TestService_Header_Invalid(t *testing.T) {
+	t.Parallel()
+
+	c := NewTestConfig()
+	c.AllowDatabaseOverride = true
+	s := NewTestService(&c)
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	for _, header := range []string{
+		"# database=_internal",
+		"# database=_audit",
+		"# database=db1",
+		"# database=db0 retention-policy=rp1",
+	} {
+		t.Run(header, func(t *testing.T) {
+			s.ExpectClosed(t, header+"\ncpu value=1\n")
+		})
+	}
+}
+
+// Ensure the batchers of the destinations of the connections are stopped
+// once the connections are closed.
+func TestService_Header_ReleaseBatchers(t *testing.T) {
+	t.Parallel()
+
+	c := NewTestConfig()
+	c.AllowDatabaseOverride = true
+	s := NewTestService(&c)
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	for _, data := range []string{
+		"# database=db0\ncpu value=1 1\n",
+		"# database=db0 retention-policy=rp0\ncpu value=2 2\n",
+		"cpu value=3 3\n",
+	} {
+		conn, err := net.Dial("tcp", s.Service.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}
+
+	exp := []string{"db0/: cpu value=1 1", "db0/rp0: cpu value=2 2", "tcp/: cpu value=3 3"}
+	if got := s.Wait(t, 3); !equalStrings(got, exp) {
+		t.Fatalf("unexpected points:\ngot %v\nexp %v", got, exp)
+	}
+	for i := 0; ; i++ {
+		s.Service.mu.RLock()
+		n := len(s.Service.batchers)
+		s.Service.mu.RUnlock()
+		if n == 0 {
+			break
+		} else if i == 5000 {
+			t.Fatalf("unexpected number of batchers: %d", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestService_TLS(t *testing.T) {
+	t.Parallel()
+
+	certFile, keyFile := writeTestCertificate(t)
+	c := NewTestConfig()
+	c.TLSEnabled = true
+	c.Certificate, c.PrivateKey = certFile, keyFile
+	s := NewTestService(&c)
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	conn, err := tls.Dial("tcp", s.Service.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("cpu value=1 1\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{"tcp/: cpu value=1 1"}
+	if got := s.Wait(t, 1); !equalStrings(got, exp) {
+		t.Fatalf("unexpected points:\ngot %v\nexp %v", got, exp)
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	r := bufio.NewReaderSize(strings.NewReader("short\n"+strings.Repeat("x", 100)+"\nlast"), 16)
+
+	if line, err := readLine(r, 50); err != nil || string(line) != "short\n" {
+		t.Fatalf("unexpected line: %q, %v", line, err)
+	}
+	if _, err := readLine(r, 50); err != errLineTooLong {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if line, err := readLine(r, 50); err == nil || string(line) != "last" {
+		t.Fatalf("unexpected line: %q, %v", line, err)
+	}
+}
+
+type TestService struct {
+	Service    *Service
+	MetaClient *internal.MetaClientMock
+	written    chan string
+}
+
+// NewTestConfig returns the configuration of a service listening on a random
+// port.
+func NewTestConfig() Config {
+	c := NewConfig()
+	c.BindAddress = "127.0.0.1:0"
+	c.BatchTimeout = toml.Duration(10 * time.Millisecond)
+	return c
+}
+
+// NewTestService returns a new instance of Service.
+func NewTestService(c *Config) *TestService {
+	if c == nil {
+		defaultC := NewTestConfig()
+		c = &defaultC
+	}
+
+	service := &TestService{
+		Service:    NewService(*c),
+		MetaClient: &internal.MetaClientMock{},
+		written:    make(chan string, 100),
+	}
+
+	service.MetaClient.CreateDatabaseFn = func(string) (*meta.DatabaseInfo, error) {
+		return nil, nil
+	}
+	service.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		switch name {
+		case "db0", "_internal", "_audit":
+			return &meta.DatabaseInfo{Name: name, RetentionPolicies: []meta.RetentionPolicyInfo{{Name: "rp0"}}}
+		}
+		return nil
+	}
+
+	if testing.Verbose() {
+		service.Service.WithLogger(logger.New(os.Stderr))
+	}
+
+	service.Service.MetaClient = service.MetaClient
+	service.Service.PointsWriter = service
+	return service
+}
+
+func (s *TestService) WritePointsPrivileged(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
+	for _, pt := range points {
+		s.written <- database + "/" + retentionPolicy + ": " + pt.String()
+	}
+	return nil
+}
+
+// ExpectClosed writes data to a new connection and expects the connection
+// to be closed without writing points.
+func (s *TestService) ExpectClosed(t *testing.T, data string) {
+	t.Helper()
+	conn, err := net.Dial("tcp", s.Service.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Fatal("expected connection to be closed")
+	} else if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		t.Fatal("timed out waiting for connection to be closed")
+	}
+	select {
+	case pt := <-s.written:
+		t.Fatalf("unexpected point: %s", pt)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// Wait returns the next n points written, sorted.
+func (s *TestService) Wait(t *testing.T, n int) []string {
+	t.Helper()
+	var got []string
+	timeout := time.After(5 * time.Second)
+	for len(got) < n {
+		select {
+		case pt := <-s.written:
+			got = append(got, pt)
+		case <-timeout:
+			t.Fatalf("timed out waiting for points, got %v", got)
+		}
+	}
+	sort.Strings(got)
+	return got
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// writeTestCertificate writes a self-signed certificate and its key to
+// temporary files.
+func writeTestCertificate(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "tcp-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	} else if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
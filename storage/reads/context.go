@@ -0,0 +1,38 @@
+package reads
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+type key int
+
+const (
+	authorizerKey key = iota
+)
+
+// NewContextWithAuthorizer returns a new Context limiting the series read from
+// the store to the series authorized by auth.
+func NewContextWithAuthorizer(ctx context.Context, auth query.FineAuthorizer) context.Context {
+	return context.WithValue(ctx, authorizerKey, auth)
+}
+
+// AuthorizerFromContext returns the authorizer associated with the context or
+// query.OpenAuthorizer if none has been specified.
+func AuthorizerFromContext(ctx context.Context) query.FineAuthorizer {
+	if auth, _ := ctx.Value(authorizerKey).(query.FineAuthorizer); auth != nil {
+		return auth
+	}
+	return query.OpenAuthorizer
+}
+
+// newContextWithUser returns a new Context limiting the series read from the
+// store to the series the user associated with ctx may read, if any.
+func newContextWithUser(ctx context.Context) context.Context {
+	if user := meta.UserFromContext(ctx); user != nil {
+		return NewContextWithAuthorizer(ctx, user)
+	}
+	return ctx
+}
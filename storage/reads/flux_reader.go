@@ -33,7 +33,7 @@ func NewReader(s Store) influxdb.Reader {
 
 func (r *storeReader) ReadFilter(ctx context.Context, spec influxdb.ReadFilterSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
 	return &filterIterator{
-		ctx:   ctx,
+		ctx:   newContextWithUser(ctx),
 		s:     r.s,
 		spec:  spec,
 		cache: newTagsCache(0),
@@ -43,7 +43,7 @@ func (r *storeReader) ReadFilter(ctx context.Context, spec influxdb.ReadFilterSp
 
 func (r *storeReader) ReadGroup(ctx context.Context, spec influxdb.ReadGroupSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
 	return &groupIterator{
-		ctx:   ctx,
+		ctx:   newContextWithUser(ctx),
 		s:     r.s,
 		spec:  spec,
 		cache: newTagsCache(0),
@@ -62,7 +62,7 @@ func (r *storeReader) ReadTagKeys(ctx context.Context, spec influxdb.ReadTagKeys
 	}
 
 	return &tagKeysIterator{
-		ctx:       ctx,
+		ctx:       newContextWithUser(ctx),
 		bounds:    spec.Bounds,
 		s:         r.s,
 		readSpec:  spec,
@@ -82,7 +82,7 @@ func (r *storeReader) ReadTagValues(ctx context.Context, spec influxdb.ReadTagVa
 	}
 
 	return &tagValuesIterator{
-		ctx:       ctx,
+		ctx:       newContextWithUser(ctx),
 		bounds:    spec.Bounds,
 		s:         r.s,
 		readSpec:  spec,
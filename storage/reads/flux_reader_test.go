@@ -0,0 +1,109 @@
+package reads_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/influxdb/flux/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+	"github.com/influxdata/influxql"
+)
+
+func TestReader_Authorizer(t *testing.T) {
+	// The user may only read the cpu measurement.
+	user := &meta.UserInfo{
+		Name:       "reader",
+		Privileges: map[string]influxql.Privilege{"db0": influxql.ReadPrivilege},
+		ReadGrants: []meta.ReadGrant{{Database: "db0", Measurement: "cpu"}},
+	}
+
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+		exp  query.FineAuthorizer
+	}{
+		{name: "no user", ctx: context.Background(), exp: query.OpenAuthorizer},
+		{name: "restricted user", ctx: meta.NewContextWithUser(context.Background(), user), exp: user},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var auths []query.FineAuthorizer
+			s := &authStore{fn: func(ctx context.Context) {
+				auths = append(auths, reads.AuthorizerFromContext(ctx))
+			}}
+			r := reads.NewReader(s)
+
+			spec := influxdb.ReadFilterSpec{Database: "db0"}
+			var its []influxdb.TableIterator
+			if it, err := r.ReadFilter(tt.ctx, spec, &memory.Allocator{}); err != nil {
+				t.Fatal(err)
+			} else {
+				its = append(its, it)
+			}
+			if it, err := r.ReadGroup(tt.ctx, influxdb.ReadGroupSpec{ReadFilterSpec: spec}, &memory.Allocator{}); err != nil {
+				t.Fatal(err)
+			} else {
+				its = append(its, it)
+			}
+			if it, err := r.ReadTagKeys(tt.ctx, influxdb.ReadTagKeysSpec{ReadFilterSpec: spec}, &memory.Allocator{}); err != nil {
+				t.Fatal(err)
+			} else {
+				its = append(its, it)
+			}
+			if it, err := r.ReadTagValues(tt.ctx, influxdb.ReadTagValuesSpec{ReadFilterSpec: spec, TagKey: "host"}, &memory.Allocator{}); err != nil {
+				t.Fatal(err)
+			} else {
+				its = append(its, it)
+			}
+			for _, it := range its {
+				if err := it.Do(func(flux.Table) error { return nil }); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if len(auths) != 4 {
+				t.Fatalf("unexpected number of reads: %d", len(auths))
+			}
+			for i, auth := range auths {
+				if auth != tt.exp {
+					t.Errorf("read %d: unexpected authorizer: got %v, exp %v", i, auth, tt.exp)
+				}
+			}
+		})
+	}
+}
+
+// authStore is a Store calling fn with the context of each read.
+type authStore struct {
+	fn func(ctx context.Context)
+}
+
+func (s *authStore) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
+	s.fn(ctx)
+	return nil, nil
+}
+
+func (s *authStore) ReadGroup(ctx context.Context, req *datatypes.ReadGroupRequest) (reads.GroupResultSet, error) {
+	s.fn(ctx)
+	return nil, nil
+}
+
+func (s *authStore) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cursors.StringIterator, error) {
+	s.fn(ctx)
+	return cursors.EmptyStringIterator, nil
+}
+
+func (s *authStore) TagValues(ctx context.Context, req *datatypes.TagValuesRequest) (cursors.StringIterator, error) {
+	s.fn(ctx)
+	return cursors.EmptyStringIterator, nil
+}
+
+func (s *authStore) GetSource(db, rp string) proto.Message {
+	return &datatypes.ReadFilterRequest{}
+}
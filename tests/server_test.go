@@ -495,7 +495,7 @@ func TestServer_UserCommands(t *testing.T) {
 			&Query{
 				name:    "show users, no actual users",
 				command: `SHOW USERS`,
-				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["user","admin"]}]}]}`,
+				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["user","admin","locked"]}]}]}`,
 			},
 			&Query{
 				name:    `create user`,
@@ -505,7 +505,7 @@ func TestServer_UserCommands(t *testing.T) {
 			&Query{
 				name:    "show users, 1 existing user",
 				command: `SHOW USERS`,
-				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["user","admin"],"values":[["jdoe",false]]}]}]}`,
+				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["user","admin","locked"],"values":[["jdoe",false,false]]}]}]}`,
 			},
 			&Query{
 				name:    "grant all priviledges to jdoe",
@@ -515,7 +515,7 @@ func TestServer_UserCommands(t *testing.T) {
 			&Query{
 				name:    "show users, existing user as admin",
 				command: `SHOW USERS`,
-				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["user","admin"],"values":[["jdoe",true]]}]}]}`,
+				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["user","admin","locked"],"values":[["jdoe",true,false]]}]}]}`,
 			},
 			&Query{
 				name:    "grant DB privileges to user",
@@ -550,7 +550,7 @@ func TestServer_UserCommands(t *testing.T) {
 			&Query{
 				name:    "make sure user was dropped",
 				command: `SHOW USERS`,
-				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["user","admin"]}]}]}`,
+				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["user","admin","locked"]}]}]}`,
 			},
 			&Query{
 				name:    "delete non existing user",
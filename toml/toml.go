@@ -172,6 +172,17 @@ func applyEnvOverrides(getenv func(string) string, prefix string, spec reflect.V
 
 	value := getenv(prefix)
 
+	// Skip any scalars we don't have a value to set, such as the elements of
+	// a slice without an override.
+	switch element.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Bool, reflect.Float32, reflect.Float64:
+		if len(value) == 0 {
+			return nil
+		}
+	}
+
 	switch element.Kind() {
 	case reflect.String:
 		if len(value) == 0 {
@@ -184,6 +184,7 @@ func TestEnvOverride_Builtins(t *testing.T) {
 		"X_ES":            "an embedded string",
 		"X__":             "-1", // This value should not be applied to the "ignored" field with toml tag -.
 		"X_STRINGS_1":     "c",
+		"X_FLOATS_1":      "99.9",
 	}
 
 	env := func(s string) string {
@@ -215,6 +216,7 @@ func TestEnvOverride_Builtins(t *testing.T) {
 		Float64        float64             `toml:"float64"`
 		Nested         nested              `toml:"nested"`
 		UnmarshalSlice []stringUnmarshaler `toml:"strings"`
+		FloatSlice     []float64           `toml:"floats"`
 
 		Embedded
 
@@ -226,6 +228,7 @@ func TestEnvOverride_Builtins(t *testing.T) {
 		{Text: "a"},
 		{Text: "b"},
 	}
+	got.FloatSlice = []float64{50, 90}
 	if err := itoml.ApplyEnvOverrides(env, "X", &got); err != nil {
 		t.Fatal(err)
 	}
@@ -257,7 +260,8 @@ func TestEnvOverride_Builtins(t *testing.T) {
 			{Text: "a"},
 			{Text: "c"},
 		},
-		Ignored: 0,
+		FloatSlice: []float64{50, 99.9},
+		Ignored:    0,
 	}
 
 	if diff := cmp.Diff(got, exp); diff != "" {
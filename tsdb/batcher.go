@@ -81,30 +81,38 @@ func (b *PointBatcher) Start() {
 	b.wg = &sync.WaitGroup{}
 	b.wg.Add(1)
 
+	add := func(p models.Point) {
+		atomic.AddUint64(&b.stats.PointTotal, 1)
+		if batch == nil {
+			if b.size > 0 {
+				batch = make([]models.Point, 0, b.size)
+			}
+
+			if b.duration > 0 {
+				timer.Reset(b.duration)
+			}
+		}
+
+		batch = append(batch, p)
+		if len(batch) >= b.size { // 0 means send immediately.
+			atomic.AddUint64(&b.stats.SizeTotal, 1)
+			emit()
+		}
+	}
+
 	go func() {
 		defer b.wg.Done()
 		for {
 			select {
 			case <-b.stop:
+				// Batch the points sent before stopping.
+				for len(b.in) > 0 {
+					add(<-b.in)
+				}
 				emit()
 				return
 			case p := <-b.in:
-				atomic.AddUint64(&b.stats.PointTotal, 1)
-				if batch == nil {
-					if b.size > 0 {
-						batch = make([]models.Point, 0, b.size)
-					}
-
-					if b.duration > 0 {
-						timer.Reset(b.duration)
-					}
-				}
-
-				batch = append(batch, p)
-				if len(batch) >= b.size { // 0 means send immediately.
-					atomic.AddUint64(&b.stats.SizeTotal, 1)
-					emit()
-				}
+				add(p)
 
 			case <-b.flush:
 				emit()
@@ -117,8 +125,8 @@ func (b *PointBatcher) Start() {
 	}()
 }
 
-// Stop stops the batching process. Stop waits for the batching routine
-// to stop before returning.
+// Stop stops the batching process. The points sent before Stop is called are
+// emitted. Stop waits for the batching routine to stop before returning.
 func (b *PointBatcher) Stop() {
 	// If not running, nothing to stop.
 	if b.wg == nil {
@@ -144,3 +144,29 @@ func checkPointBatcherStats(t *testing.T, b *tsdb.PointBatcher, batchTotal, poin
 		t.Errorf("timeout total stat is incorrect: %d", stats.TimeoutTotal)
 	}
 }
+
+// TestBatch_Stop ensures that a batcher emits the points sent before it is stopped.
+func TestBatch_Stop(t *testing.T) {
+	batcher := tsdb.NewPointBatcher(10, 1, time.Hour)
+	batcher.Start()
+
+	var p models.Point
+	for i := 0; i < 5; i++ {
+		batcher.In() <- p
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for n := 0; n < 5; {
+			n += len(<-batcher.Out())
+		}
+	}()
+	batcher.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for points")
+	}
+}
@@ -67,6 +67,10 @@ const (
 	// write-ahead log file will compact into an index file.
 	DefaultMaxIndexLogFileSize = 1 * 1024 * 1024 // 1MB
 
+	// DefaultEncryptionCacheSize is the default size of the cache of decrypted
+	// chunks of encrypted TSM, series and index files.
+	DefaultEncryptionCacheSize = 64 * 1024 * 1024 // 64MB
+
 	// DefaultSeriesIDSetCacheSize is the default number of series ID sets to cache in the TSI index.
 	DefaultSeriesIDSetCacheSize = 100
 
@@ -150,6 +154,15 @@ type Config struct {
 	// been found to be problematic in some cases. It may help users who have
 	// slow disks.
 	TSMWillNeed bool `toml:"tsm-use-madv-willneed"`
+
+	// EncryptionKeyFile is the path of the file holding the keys encrypting
+	// TSM files, WAL segments, series files and TSI index files at rest.
+	// Encryption is disabled if empty.
+	EncryptionKeyFile string `toml:"encryption-key-file"`
+
+	// EncryptionCacheSize is the size of the cache of the decrypted chunks of
+	// encrypted TSM, series and index files, shared by all shards.
+	EncryptionCacheSize toml.Size `toml:"encryption-cache-size"`
 }
 
 // NewConfig returns the default configuration for tsdb.
@@ -179,6 +192,8 @@ func NewConfig() Config {
 
 		TraceLoggingEnabled: false,
 		TSMWillNeed:         false,
+
+		EncryptionCacheSize: toml.Size(DefaultEncryptionCacheSize),
 	}
 }
 
@@ -244,5 +259,7 @@ func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 		"max-index-log-file-size":                c.MaxIndexLogFileSize,
 		"series-id-set-cache-size":               c.SeriesIDSetCacheSize,
 		"series-file-max-concurrent-compactions": c.SeriesFileMaxConcurrentSnapshotCompactions,
+		"encryption-enabled":                     c.EncryptionKeyFile != "",
+		"encryption-cache-size":                  c.EncryptionCacheSize,
 	}), nil
 }
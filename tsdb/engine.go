@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/estimator"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/query"
@@ -44,6 +45,7 @@ type Engine interface {
 	CreateSnapshot(skipCacheOk bool) (string, error)
 	Backup(w io.Writer, basePath string, since time.Time) error
 	Export(w io.Writer, basePath string, start time.Time, end time.Time) error
+	ExportRange(w io.Writer, basePath string, min, max int64) error
 	Restore(r io.Reader, basePath string) error
 	Import(r io.Reader, basePath string) error
 	Digest() (io.ReadCloser, int64, error)
@@ -191,9 +193,25 @@ type EngineOptions struct {
 	SeriesIDSets   SeriesIDSets
 	FieldValidator FieldValidator
 
+	// SchemaFn returns the schema of a database, or nil if points written to
+	// the database are not validated against a schema.
+	SchemaFn func(database string) Schema
+
+	// MaxSeriesFn returns the maximum number of series of a database, or 0
+	// if the number of series of the database is not limited by a quota.
+	MaxSeriesFn func(database string) int64
+
 	OnNewEngine func(Engine)
 
 	FileStoreObserver FileStoreObserver
+
+	// KeyProvider encrypts TSM files, WAL segments, series files and TSI
+	// index files at rest, if set.
+	KeyProvider encryption.KeyProvider
+
+	// ChunkCache caches the decrypted chunks of encrypted TSM files, series
+	// file segments and TSI index files.
+	ChunkCache *encryption.ChunkCache
 }
 
 // NewEngineOptions constructs an EngineOptions object with safe default values.
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxql"
 	"go.uber.org/zap"
@@ -686,6 +687,9 @@ func (c *Cache) ApplyEntryFn(f func(key []byte, entry *entry) error) error {
 type CacheLoader struct {
 	files []string
 
+	// KeyProvider decrypts encrypted segments.
+	KeyProvider encryption.KeyProvider
+
 	Logger *zap.Logger
 }
 
@@ -724,12 +728,21 @@ func (cl *CacheLoader) Load(cache *Cache) error {
 				return nil
 			}
 
+			// Fail rather than truncate segments that cannot be decrypted.
+			key, err := readSegmentKey(f, cl.KeyProvider)
+			if err != nil {
+				return err
+			}
+
 			if r == nil {
 				r = NewWALSegmentReader(f)
 				defer r.Close()
 			} else {
 				r.Reset(f)
 			}
+			if err := r.setKey(key); err != nil {
+				return err
+			}
 
 			for r.Next() {
 				entry, err := r.Read()
@@ -16,6 +16,7 @@ import (
 	"testing"
 
 	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/pkg/encryption"
 )
 
 func TestCache_NewCache(t *testing.T) {
@@ -671,6 +672,64 @@ func TestCacheLoader_LoadSingle(t *testing.T) {
 }
 
 // Ensure the CacheLoader can correctly load from two segments, even if one is corrupted.
+func TestCacheLoader_LoadEncrypted(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+	keys := mustKeyFile(t, "k1")
+
+	p1 := NewValue(1, 1.1)
+	p2 := NewValue(2, 2.2)
+
+	// Write to a new encrypted segment, then append to it after reopening.
+	for _, p := range []Value{p1, p2} {
+		w := NewWAL(dir)
+		w.keys = keys
+		if err := w.Open(); err != nil {
+			t.Fatalf("failed to open WAL: %v", err)
+		}
+		if _, err := w.WriteMulti(map[string][]Value{"cpu,host=server01#!~#value": {p}}); err != nil {
+			t.Fatalf("failed to write to WAL: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("failed to close WAL: %v", err)
+		}
+	}
+
+	files, err := segmentFileNames(dir)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) != 1 {
+		t.Fatalf("unexpected segments: %v", files)
+	}
+	b, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	} else if !encryption.IsEncrypted(b) {
+		t.Fatalf("expected encrypted segment")
+	}
+
+	// Segments are not truncated when they cannot be decrypted.
+	loader := NewCacheLoader(files)
+	if err := loader.Load(NewCache(1024)); err == nil || !strings.Contains(err.Error(), "no encryption keys") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stat, err := os.Stat(files[0]); err != nil {
+		t.Fatal(err)
+	} else if stat.Size() != int64(len(b)) {
+		t.Fatalf("segment truncated to %d bytes", stat.Size())
+	}
+
+	cache := NewCache(1024)
+	loader = NewCacheLoader(files)
+	loader.KeyProvider = keys
+	if err := loader.Load(cache); err != nil {
+		t.Fatalf("failed to load cache: %v", err)
+	}
+	if values := cache.Values([]byte("cpu,host=server01#!~#value")); !reflect.DeepEqual(values, Values{p1, p2}) {
+		t.Fatalf("cache values not as expected, got %v, exp %v", values, Values{p1, p2})
+	}
+}
+
 func TestCacheLoader_LoadDouble(t *testing.T) {
 	// Create a WAL segment.
 	dir := mustTempDir()
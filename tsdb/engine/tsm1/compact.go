@@ -26,6 +26,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/tsdb"
 )
@@ -138,6 +139,7 @@ type fileStore interface {
 	LastModified() time.Time
 	BlockCount(path string, idx int) int
 	ParseFileName(path string) (int, int, error)
+	CurrentKeyID() string
 }
 
 func NewDefaultPlanner(fs fileStore, writeColdDuration time.Duration) *DefaultPlanner {
@@ -192,6 +194,16 @@ func (t *tsmGeneration) count() int {
 	return len(t.files)
 }
 
+// encryptedWith returns true if all the files are encrypted with the key.
+func (t *tsmGeneration) encryptedWith(keyID string) bool {
+	for _, f := range t.files {
+		if f.KeyID != keyID {
+			return false
+		}
+	}
+	return true
+}
+
 // hasTombstones returns true if there are keys removed for any of the files.
 func (t *tsmGeneration) hasTombstones() bool {
 	for _, f := range t.files {
@@ -210,10 +222,11 @@ func (c *DefaultPlanner) ParseFileName(path string) (int, int, error) {
 	return c.FileStore.ParseFileName(path)
 }
 
-// FullyCompacted returns true if the shard is fully compacted.
+// FullyCompacted returns true if the shard is fully compacted and its files
+// are encrypted with the current key, if any.
 func (c *DefaultPlanner) FullyCompacted() bool {
 	gens := c.findGenerations(false)
-	return len(gens) <= 1 && !gens.hasTombstones()
+	return len(gens) <= 1 && !gens.hasTombstones() && len(c.planKeyRotation(gens)) == 0
 }
 
 // ForceFull causes the planner to return a full compaction plan the next time
@@ -337,6 +350,15 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 	// split across several files in sequence.
 	generations := c.findGenerations(true)
 
+	// Rewrite the generations not encrypted with the current key first, so
+	// that rotated keys are no longer needed once they are rewritten.
+	if cGroups := c.planKeyRotation(generations); len(cGroups) > 0 {
+		if !c.acquire(cGroups) {
+			return nil
+		}
+		return cGroups
+	}
+
 	// If there is only one generation and no tombstones, then there's nothing to
 	// do.
 	if len(generations) <= 1 && !generations.hasTombstones() {
@@ -411,6 +433,30 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 	return cGroups
 }
 
+// planKeyRotation returns a group for each generation with files that are not
+// encrypted with the current encryption key. It returns nil if new files are
+// not encrypted.
+func (c *DefaultPlanner) planKeyRotation(generations tsmGenerations) []CompactionGroup {
+	keyID := c.FileStore.CurrentKeyID()
+	if keyID == "" {
+		return nil
+	}
+
+	var cGroups []CompactionGroup
+	for _, gen := range generations {
+		if gen.encryptedWith(keyID) {
+			continue
+		}
+
+		var cGroup CompactionGroup
+		for _, file := range gen.files {
+			cGroup = append(cGroup, file.Path)
+		}
+		cGroups = append(cGroups, cGroup)
+	}
+	return cGroups
+}
+
 // Plan returns a set of TSM files to rewrite for level 4 or higher.  The planning returns
 // multiple groups if possible to allow compactions to run concurrently.
 func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
@@ -705,6 +751,10 @@ type Compactor struct {
 	// RateLimit is the limit for disk writes for all concurrent compactions.
 	RateLimit limiter.Rate
 
+	// KeyProvider encrypts the TSM files written, if set. Compactions rewrite
+	// files with the current key, rotating the keys of older files.
+	KeyProvider encryption.KeyProvider
+
 	formatFileName FormatFileNameFunc
 	parseFileName  ParseFileNameFunc
 
@@ -1105,9 +1155,18 @@ func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err err
 		limitWriter = limiter.NewWriterWithRate(fd, c.RateLimit)
 	}
 
+	if c.KeyProvider != nil {
+		if limitWriter, err = encryption.NewWriter(limitWriter, c.KeyProvider); err != nil {
+			fd.Close()
+			os.Remove(path)
+			return err
+		}
+	}
+
 	// Use a disk based TSM buffer if it looks like we might create a big index
-	// in memory.
-	if iter.EstimatedIndexSize() > 64*1024*1024 {
+	// in memory. The index of encrypted files is always buffered in memory,
+	// since the disk buffer would hold it in plaintext.
+	if iter.EstimatedIndexSize() > 64*1024*1024 && c.KeyProvider == nil {
 		w, err = NewTSMWriterWithDiskBuffer(limitWriter)
 		if err != nil {
 			return err
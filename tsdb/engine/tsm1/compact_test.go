@@ -1,15 +1,19 @@
 package tsm1_test
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
 	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
 )
@@ -142,6 +146,66 @@ func TestCompactor_CompactFullLastTimestamp(t *testing.T) {
 }
 
 // Ensures that a compaction will properly merge multiple TSM files
+// Tests compacting a Cache snapshot into an encrypted TSM file
+func TestCompactor_Snapshot_Encrypted(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	keyPath := filepath.Join(dir, "keys")
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, encryption.KeySize))
+	if err := os.WriteFile(keyPath, []byte("k1 "+key+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := encryption.NewKeyFile(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v1 := tsm1.NewValue(1, float64(1))
+	c := tsm1.NewCache(0)
+	if err := c.Write([]byte("cpu,host=A#!~#value"), []tsm1.Value{v1}); err != nil {
+		t.Fatalf("failed to write key foo to cache: %s", err.Error())
+	}
+
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = &fakeFileStore{}
+	compactor.KeyProvider = keys
+	compactor.Open()
+
+	files, err := compactor.WriteSnapshot(c)
+	if err != nil {
+		t.Fatalf("unexpected error writing snapshot: %v", err)
+	} else if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	b, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	} else if !encryption.IsEncrypted(b) {
+		t.Fatalf("expected encrypted file")
+	}
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(f, tsm1.WithKeyProvider(keys))
+	if err != nil {
+		t.Fatalf("unexpected error opening reader: %v", err)
+	}
+	defer r.Close()
+
+	values, err := r.ReadAll([]byte("cpu,host=A#!~#value"))
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	} else if got, exp := len(values), 1; got != exp {
+		t.Fatalf("values length mismatch: got %v, exp %v", got, exp)
+	}
+	assertValueEqual(t, values[0], v1)
+}
+
 func TestCompactor_CompactFull(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
@@ -2176,6 +2240,64 @@ func TestDefaultPlanner_PlanOptimize_NoLevel4(t *testing.T) {
 	}
 }
 
+// Ensure that the planner rewrites the generations not encrypted with the
+// current key, even when they would not otherwise be optimized.
+func TestDefaultPlanner_PlanOptimize_KeyRotation(t *testing.T) {
+	data := []tsm1.FileStat{
+		{
+			Path:  "01-04.tsm1",
+			Size:  251 * 1024 * 1024,
+			KeyID: "k2",
+		},
+		{
+			Path:  "02-04.tsm1",
+			Size:  1 * 1024 * 1024,
+			KeyID: "k2",
+		},
+		{
+			Path:  "02-05.tsm1",
+			Size:  1 * 1024 * 1024,
+			KeyID: "k1",
+		},
+		{
+			Path: "03-02.tsm1",
+			Size: 1 * 1024 * 1024,
+		},
+		{
+			Path:  "04-01.tsm1",
+			Size:  1 * 1024 * 1024,
+			KeyID: "k2",
+		},
+	}
+
+	fs := &fakeFileStore{
+		PathsFn: func() []tsm1.FileStat {
+			return data
+		},
+		keyID: "k2",
+	}
+	cp := tsm1.NewDefaultPlanner(fs, tsdb.DefaultCompactFullWriteColdDuration)
+
+	tsm := cp.PlanOptimize()
+	if exp, got := 2, len(tsm); exp != got {
+		t.Fatalf("group length mismatch: got %v, exp %v", got, exp)
+	}
+	for i, exp := range []tsm1.CompactionGroup{{"02-04.tsm1", "02-05.tsm1"}, {"03-02.tsm1"}} {
+		if !reflect.DeepEqual(tsm[i], exp) {
+			t.Fatalf("group %d mismatch: got %v, exp %v", i, tsm[i], exp)
+		}
+	}
+	cp.Release(tsm)
+
+	// Once every file is encrypted with the current key, nothing is planned.
+	for i := range data {
+		data[i].KeyID = "k2"
+	}
+	if tsm := cp.PlanOptimize(); len(tsm) != 0 {
+		t.Fatalf("unexpected groups: %v", tsm)
+	}
+}
+
 func TestDefaultPlanner_PlanOptimize_Level4(t *testing.T) {
 	data := []tsm1.FileStat{
 		{
@@ -2964,6 +3086,7 @@ type fakeFileStore struct {
 	PathsFn      func() []tsm1.FileStat
 	lastModified time.Time
 	blockCount   int
+	keyID        string
 	readers      []*tsm1.TSMReader
 }
 
@@ -2975,6 +3098,10 @@ func (w *fakeFileStore) NextGeneration() int {
 	return 1
 }
 
+func (w *fakeFileStore) CurrentKeyID() string {
+	return w.keyID
+}
+
 func (w *fakeFileStore) LastModified() time.Time {
 	return w.lastModified
 }
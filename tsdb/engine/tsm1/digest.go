@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"sort"
 	"time"
+
+	"github.com/influxdata/influxdb/pkg/encryption"
 )
 
 const (
@@ -18,6 +20,9 @@ const (
 type DigestOptions struct {
 	MinTime, MaxTime int64
 	MinKey, MaxKey   []byte
+
+	// KeyProvider decrypts encrypted TSM files.
+	KeyProvider encryption.KeyProvider
 }
 
 // DigestWithOptions writes a digest of dir to w using options to filter by
@@ -42,7 +47,7 @@ func DigestWithOptions(dir string, files []string, opts DigestOptions, w io.Writ
 			return err
 		}
 
-		r, err := NewTSMReader(f)
+		r, err := NewTSMReader(f, WithKeyProvider(opts.KeyProvider))
 		if err != nil {
 			return err
 		}
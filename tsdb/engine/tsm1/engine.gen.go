@@ -14,7 +14,7 @@ import (
 // buildFloatCursor creates a cursor for a float field.
 func (e *Engine) buildFloatCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) floatCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues := e.cacheValues(ctx, key)
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
 	return newFloatCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
@@ -22,7 +22,7 @@ func (e *Engine) buildFloatCursor(ctx context.Context, measurement, seriesKey, f
 // buildIntegerCursor creates a cursor for a integer field.
 func (e *Engine) buildIntegerCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) integerCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues := e.cacheValues(ctx, key)
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
 	return newIntegerCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
@@ -30,7 +30,7 @@ func (e *Engine) buildIntegerCursor(ctx context.Context, measurement, seriesKey,
 // buildUnsignedCursor creates a cursor for a unsigned field.
 func (e *Engine) buildUnsignedCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) unsignedCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues := e.cacheValues(ctx, key)
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
 	return newUnsignedCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
@@ -38,7 +38,7 @@ func (e *Engine) buildUnsignedCursor(ctx context.Context, measurement, seriesKey
 // buildStringCursor creates a cursor for a string field.
 func (e *Engine) buildStringCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) stringCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues := e.cacheValues(ctx, key)
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
 	return newStringCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
@@ -46,7 +46,7 @@ func (e *Engine) buildStringCursor(ctx context.Context, measurement, seriesKey,
 // buildBooleanCursor creates a cursor for a boolean field.
 func (e *Engine) buildBooleanCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) booleanCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues := e.cacheValues(ctx, key)
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
 	return newBooleanCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
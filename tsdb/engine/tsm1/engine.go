@@ -23,6 +23,7 @@ import (
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/bytesutil"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/estimator"
 	"github.com/influxdata/influxdb/pkg/file"
 	"github.com/influxdata/influxdb/pkg/limiter"
@@ -65,6 +66,9 @@ var (
 	numberOfAuxCursorsCounter  = metrics.MustRegisterCounter("cursors_aux", metrics.WithGroup(tsmGroup))
 	numberOfCondCursorsCounter = metrics.MustRegisterCounter("cursors_cond", metrics.WithGroup(tsmGroup))
 	planningTimer              = metrics.MustRegisterTimer("planning_time", metrics.WithGroup(tsmGroup))
+	seriesSetTimer             = metrics.MustRegisterTimer("series_set_time", metrics.WithGroup(tsmGroup))
+	cacheHitsCounter           = metrics.MustRegisterCounter("cache_hits", metrics.WithGroup(tsmGroup))
+	cacheValuesCounter         = metrics.MustRegisterCounter("cache_values", metrics.WithGroup(tsmGroup))
 )
 
 // NewContextWithMetricsGroup creates a new context with a tsm1 metrics.Group for tracking
@@ -210,6 +214,7 @@ func NewEngine(id uint64, idx tsdb.Index, path string, walPath string, sfile *ts
 	if opt.WALEnabled {
 		wal = NewWAL(walPath)
 		wal.syncDelay = time.Duration(opt.Config.WALFsyncDelay)
+		wal.keys = opt.KeyProvider
 	}
 
 	fs := NewFileStore(path)
@@ -218,6 +223,8 @@ func NewEngine(id uint64, idx tsdb.Index, path string, walPath string, sfile *ts
 		fs.WithObserver(opt.FileStoreObserver)
 	}
 	fs.tsmMMAPWillNeed = opt.Config.TSMWillNeed
+	fs.keys = opt.KeyProvider
+	fs.chunks = opt.ChunkCache
 
 	cache := NewCache(uint64(opt.Config.CacheMaxMemorySize))
 
@@ -225,6 +232,7 @@ func NewEngine(id uint64, idx tsdb.Index, path string, walPath string, sfile *ts
 	c.Dir = path
 	c.FileStore = fs
 	c.RateLimit = opt.CompactionThroughputLimiter
+	c.KeyProvider = opt.KeyProvider
 
 	var planner CompactionPlanner = NewDefaultPlanner(fs, time.Duration(opt.Config.CompactFullWriteColdDuration))
 	if opt.CompactionPlannerCreator != nil {
@@ -343,7 +351,11 @@ func (e *Engine) Digest() (io.ReadCloser, int64, error) {
 	}
 
 	// Write the new digest to the tmp file.
-	if err := Digest(e.path, tsmfiles, tf); err != nil {
+	if err := DigestWithOptions(e.path, tsmfiles, DigestOptions{
+		MinTime:     math.MinInt64,
+		MaxTime:     math.MaxInt64,
+		KeyProvider: e.FileStore.keys,
+	}, tf); err != nil {
 		log.Info("Digest aborted, problem writing tmp digest", zap.Error(err))
 		tf.Close()
 		os.Remove(tf.Name())
@@ -958,7 +970,7 @@ func (e *Engine) timeStampFilterTarFile(start, end time.Time) func(f os.FileInfo
 		if err != nil {
 			return err
 		}
-		r, err := NewTSMReader(f)
+		r, err := NewTSMReader(f, WithKeyProvider(e.FileStore.keys), WithChunkCache(e.FileStore.chunks))
 		if err != nil {
 			return err
 		}
@@ -1017,49 +1029,130 @@ func (e *Engine) Export(w io.Writer, basePath string, start time.Time, end time.
 }
 
 func (e *Engine) filterFileToBackup(r *TSMReader, fi os.FileInfo, shardRelativePath, fullPath string, start, end int64, tw *tar.Writer) error {
-	path := fullPath + ".tmp"
-	out, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0666)
+	return e.streamFilteredFile(fi, shardRelativePath, fullPath, tw, func(w TSMWriter) (int, error) {
+		var n int
+
+		// implicit else: here we iterate over the blocks and only keep the ones we really want.
+		bi := r.BlockIterator()
+
+		for bi.Next() {
+			// not concerned with typ or checksum since we are just blindly writing back, with no decoding
+			key, minTime, maxTime, _, _, buf, err := bi.Read()
+			if err != nil {
+				return n, err
+			}
+			if minTime >= start && minTime <= end ||
+				maxTime >= start && maxTime <= end ||
+				minTime <= start && maxTime >= end {
+				err := w.WriteBlock(key, minTime, maxTime, buf)
+				if err != nil {
+					return n, err
+				}
+				n++
+			}
+		}
+		return n, bi.Err()
+	})
+}
+
+// exportFileRange writes a copy of a TSM file holding only the data between
+// min and max to the archive. Blocks overlapping the range are trimmed to it
+// and deleted data is left out.
+func (e *Engine) exportFileRange(fi os.FileInfo, shardRelativePath, fullPath string, min, max int64, tw *tar.Writer) error {
+	f, err := os.Open(fullPath)
 	if err != nil {
 		return err
 	}
-	defer os.Remove(path)
-
-	w, err := NewTSMWriter(out)
+	r, err := NewTSMReader(f, WithKeyProvider(e.FileStore.keys), WithChunkCache(e.FileStore.chunks))
 	if err != nil {
 		return err
 	}
-	defer w.Close()
+	defer r.Close()
+
+	if fmin, fmax := r.TimeRange(); fmin > max || fmax < min {
+		return nil
+	}
 
-	// implicit else: here we iterate over the blocks and only keep the ones we really want.
-	bi := r.BlockIterator()
+	return e.streamFilteredFile(fi, shardRelativePath, fullPath, tw, func(w TSMWriter) (int, error) {
+		var n int
+		var values []Value
 
-	for bi.Next() {
-		// not concerned with typ or checksum since we are just blindly writing back, with no decoding
-		key, minTime, maxTime, _, _, buf, err := bi.Read()
-		if err != nil {
-			return err
-		}
-		if minTime >= start && minTime <= end ||
-			maxTime >= start && maxTime <= end ||
-			minTime <= start && maxTime >= end {
-			err := w.WriteBlock(key, minTime, maxTime, buf)
+		bi := r.BlockIterator()
+		for bi.Next() {
+			key, minTime, maxTime, _, _, buf, err := bi.Read()
 			if err != nil {
-				return err
+				return n, err
+			} else if minTime > max || maxTime < min {
+				continue
+			}
+
+			// Blocks within the range without deleted data are copied as is.
+			tombstones := r.TombstoneRange(key)
+			if minTime >= min && maxTime <= max && len(tombstones) == 0 {
+				if err := w.WriteBlock(key, minTime, maxTime, buf); err != nil {
+					return n, err
+				}
+				n++
+				continue
+			}
+
+			if values, err = DecodeBlock(buf, values[:0]); err != nil {
+				return n, err
+			}
+			a := Values(values).Include(min, max)
+			for _, t := range tombstones {
+				a = a.Exclude(t.Min, t.Max)
+			}
+			if len(a) == 0 {
+				continue
 			}
+			if err := w.Write(key, a); err != nil {
+				return n, err
+			}
+			n++
 		}
-	}
+		return n, bi.Err()
+	})
+}
 
-	if err := bi.Err(); err != nil {
+// streamFilteredFile writes the blocks written by fn to a temporary TSM file
+// and streams it to the archive under the name of the file at fullPath.
+// Nothing is streamed if fn writes no blocks.
+func (e *Engine) streamFilteredFile(fi os.FileInfo, shardRelativePath, fullPath string, tw *tar.Writer, fn func(w TSMWriter) (int, error)) error {
+	path := fullPath + ".tmp"
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0666)
+	if err != nil {
 		return err
 	}
+	defer os.Remove(path)
+
+	var wrapped io.Writer = out
+	if e.FileStore.keys != nil {
+		if wrapped, err = encryption.NewWriter(out, e.FileStore.keys); err != nil {
+			out.Close()
+			return err
+		}
+	}
 
-	err = w.WriteIndex()
+	w, err := NewTSMWriter(wrapped)
 	if err != nil {
+		out.Close()
+		return err
+	}
+
+	if n, err := fn(w); err != nil || n == 0 {
+		w.Remove()
+		out.Close()
+		return err
+	}
+
+	if err := w.WriteIndex(); err != nil {
+		w.Close()
 		return err
 	}
 
 	// make sure the whole file is out to disk
-	if err := w.Flush(); err != nil {
+	if err := w.Close(); err != nil {
 		return err
 	}
 
@@ -1071,6 +1164,30 @@ func (e *Engine) filterFileToBackup(r *TSMReader, fi os.FileInfo, shardRelativeP
 	return intar.StreamRenameFile(tmpFi, fi.Name(), shardRelativePath, path, tw)
 }
 
+// ExportRange writes a tar archive of the data between min and max, inclusive,
+// that can be read by Import. Unlike Export, blocks overlapping the range are
+// trimmed to it and deleted data is left out, so that the archive holds
+// exactly the data of the time range.
+func (e *Engine) ExportRange(w io.Writer, basePath string, min, max int64) error {
+	path, err := e.CreateSnapshot(false)
+	if err != nil {
+		return err
+	}
+	// Remove the temporary snapshot dir
+	defer func() {
+		if err := os.RemoveAll(path); err != nil {
+			e.logger.Warn("export could not remove temporary snapshot directory", zap.String("path", path), zap.Error(err))
+		}
+	}()
+
+	return intar.Stream(w, path, basePath, func(fi os.FileInfo, shardRelativePath, fullPath string, tw *tar.Writer) error {
+		if !strings.HasSuffix(fi.Name(), "."+TSMFileExtension) {
+			return nil
+		}
+		return e.exportFileRange(fi, shardRelativePath, fullPath, min, max, tw)
+	})
+}
+
 // Restore reads a tar archive generated by Backup().
 // Only files that match basePath will be copied into the directory. This obtains
 // a write lock so no operations can be performed while restoring.
@@ -1115,6 +1232,15 @@ func (e *Engine) overlay(r io.Reader, basePath string, asNew bool) error {
 			return nil, err
 		}
 
+		// Check the field types before the files are live, since conflicting
+		// blocks could not be read back.
+		if err := e.checkFieldTypes(newFiles); err != nil {
+			for _, f := range newFiles {
+				os.Remove(f)
+			}
+			return nil, err
+		}
+
 		// The filestore will only handle tsm files. Other file types will be ignored.
 		if err := e.FileStore.Replace(nil, newFiles); err != nil {
 			return nil, err
@@ -1149,7 +1275,7 @@ func (e *Engine) overlay(r io.Reader, basePath string, asNew bool) error {
 			return err
 		}
 
-		r, err := NewTSMReader(fd)
+		r, err := NewTSMReader(fd, WithKeyProvider(e.FileStore.keys), WithChunkCache(e.FileStore.chunks))
 		if err != nil {
 			return err
 		}
@@ -1189,6 +1315,64 @@ func (e *Engine) overlay(r io.Reader, basePath string, asNew bool) error {
 			return err
 		}
 	}
+
+	// Save the field set index, which is not rebuilt from the TSM files
+	// when the shard is reopened with a TSI index.
+	return e.fieldset.Save()
+}
+
+// checkFieldTypes returns an error if the type of a field in the TSM files
+// conflicts with its type in the shard or in the other files.
+func (e *Engine) checkFieldTypes(paths []string) error {
+	types := make(map[string]influxql.DataType)
+	for _, path := range paths {
+		if err := e.checkFileFieldTypes(path, types); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkFileFieldTypes checks the field types of a TSM file, adding them to
+// the types of the files already checked.
+func (e *Engine) checkFileFieldTypes(path string, types map[string]influxql.DataType) error {
+	fd, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	r, err := NewTSMReader(fd, WithKeyProvider(e.FileStore.keys), WithChunkCache(e.FileStore.chunks))
+	if err != nil {
+		fd.Close()
+		return err
+	}
+	defer r.Close()
+
+	for i := 0; i < r.KeyCount(); i++ {
+		key, typ := r.KeyAt(i)
+		fieldType := BlockTypeToInfluxQLDataType(typ)
+		if fieldType == influxql.Unknown {
+			return fmt.Errorf("unknown block type: %v", typ)
+		}
+
+		seriesKey, field := SeriesAndFieldFromCompositeKey(key)
+		name := models.ParseName(seriesKey)
+		existing := influxql.Unknown
+		if mf := e.fieldset.Fields(name); mf != nil {
+			if f := mf.FieldBytes(field); f != nil {
+				existing = f.Type
+			}
+		}
+		id := string(name) + "\x00" + string(field)
+		if existing == influxql.Unknown {
+			existing = types[id]
+		}
+
+		if existing != influxql.Unknown && existing != fieldType {
+			return fmt.Errorf("%w: field %q on measurement %q is type %s, already exists as type %s",
+				tsdb.ErrFieldTypeConflict, field, name, fieldType, existing)
+		}
+		types[id] = fieldType
+	}
 	return nil
 }
 
@@ -2404,6 +2588,7 @@ func (e *Engine) reloadCache() error {
 	e.Cache.SetMaxSize(0)
 
 	loader := NewCacheLoader(files)
+	loader.KeyProvider = e.FileStore.keys
 	loader.WithLogger(e.logger)
 	if err := loader.Load(e.Cache); err != nil {
 		return err
@@ -2451,6 +2636,19 @@ func (e *Engine) cleanupTempTSMFiles() error {
 	return nil
 }
 
+// cacheValues returns the cached values for the given key and records the
+// number of values read from the cache.
+func (e *Engine) cacheValues(ctx context.Context, key []byte) Values {
+	values := e.Cache.Values(key)
+	if len(values) > 0 {
+		if group := metrics.GroupFromContext(ctx); group != nil {
+			group.GetCounter(cacheHitsCounter).Add(1)
+			group.GetCounter(cacheValuesCounter).Add(int64(len(values)))
+		}
+	}
+	return values
+}
+
 // KeyCursor returns a KeyCursor for the given key starting at time t.
 func (e *Engine) KeyCursor(ctx context.Context, key []byte, t int64, ascending bool) *KeyCursor {
 	return e.FileStore.KeyCursor(ctx, key, t, ascending)
@@ -2512,6 +2710,21 @@ type indexTagSets interface {
 	TagSets(name []byte, options query.IteratorOptions) ([]*query.TagSet, error)
 }
 
+// tagSets returns the tag sets of the measurement based on the dimensions and
+// filters of opt.
+func (e *Engine) tagSets(ctx context.Context, measurement string, opt query.IteratorOptions) ([]*query.TagSet, error) {
+	if group := metrics.GroupFromContext(ctx); group != nil {
+		defer group.GetTimer(seriesSetTimer).UpdateSince(time.Now())
+	}
+
+	if e.index.Type() == tsdb.InmemIndexName {
+		ts := e.index.(indexTagSets)
+		return ts.TagSets([]byte(measurement), opt)
+	}
+	indexSet := tsdb.IndexSet{Indexes: []tsdb.Index{e.index}, SeriesFile: e.sfile}
+	return indexSet.TagSets(e.sfile, []byte(measurement), opt)
+}
+
 func (e *Engine) createCallIterator(ctx context.Context, measurement string, call *influxql.Call, opt query.IteratorOptions) ([]query.Iterator, error) {
 	ref, _ := call.Args[0].(*influxql.VarRef)
 
@@ -2522,18 +2735,7 @@ func (e *Engine) createCallIterator(ctx context.Context, measurement string, cal
 	}
 
 	// Determine tagsets for this measurement based on dimensions and filters.
-	var (
-		tagSets []*query.TagSet
-		err     error
-	)
-	if e.index.Type() == tsdb.InmemIndexName {
-		ts := e.index.(indexTagSets)
-		tagSets, err = ts.TagSets([]byte(measurement), opt)
-	} else {
-		indexSet := tsdb.IndexSet{Indexes: []tsdb.Index{e.index}, SeriesFile: e.sfile}
-		tagSets, err = indexSet.TagSets(e.sfile, []byte(measurement), opt)
-	}
-
+	tagSets, err := e.tagSets(ctx, measurement, opt)
 	if err != nil {
 		return nil, err
 	}
@@ -2602,18 +2804,7 @@ func (e *Engine) createVarRefIterator(ctx context.Context, measurement string, o
 		return nil, nil
 	}
 
-	var (
-		tagSets []*query.TagSet
-		err     error
-	)
-	if e.index.Type() == tsdb.InmemIndexName {
-		ts := e.index.(indexTagSets)
-		tagSets, err = ts.TagSets([]byte(measurement), opt)
-	} else {
-		indexSet := tsdb.IndexSet{Indexes: []tsdb.Index{e.index}, SeriesFile: e.sfile}
-		tagSets, err = indexSet.TagSets(e.sfile, []byte(measurement), opt)
-	}
-
+	tagSets, err := e.tagSets(ctx, measurement, opt)
 	if err != nil {
 		return nil, err
 	}
@@ -2774,7 +2965,7 @@ func (e *Engine) createTagSetGroupIterators(ctx context.Context, ref *influxql.V
 		// Enforce series limit at creation time.
 		if opt.MaxSeriesN > 0 && len(itrs) > opt.MaxSeriesN {
 			query.Iterators(itrs).Close()
-			return nil, fmt.Errorf("max-select-series limit exceeded: (%d/%d)", len(itrs), opt.MaxSeriesN)
+			return nil, query.ErrMaxSelectSeriesLimitExceeded(len(itrs), opt.MaxSeriesN)
 		}
 
 	}
@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -23,6 +24,7 @@ import (
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/deep"
+	"github.com/influxdata/influxdb/pkg/tracing"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
@@ -724,6 +726,102 @@ func TestEngine_Export(t *testing.T) {
 	}
 }
 
+// Ensure the engine exports exactly the data of a time range.
+func TestEngine_ExportRange(t *testing.T) {
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) {
+			e := MustOpenEngine(index)
+			defer e.Close()
+
+			if err := e.WritePointsString(
+				"cpu,host=A value=1i 1000000000",
+				"cpu,host=A value=2i 2000000000",
+				"cpu,host=A value=3i 3000000000",
+				"cpu,host=A value=4i 4000000000",
+				"cpu,host=A value=5i 5000000000",
+			); err != nil {
+				t.Fatal(err)
+			}
+			e.MustWriteSnapshot()
+
+			// Deleted data is not exported.
+			itr := &seriesIterator{keys: [][]byte{[]byte("cpu,host=A")}}
+			if err := e.DeleteSeriesRange(itr, 3000000000, 3000000000); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			if err := e.ExportRange(&buf, "", 2000000000, 4000000000); err != nil {
+				t.Fatal(err)
+			}
+
+			other := MustOpenEngine(index)
+			defer other.Close()
+			if err := other.Import(&buf, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			cur := other.KeyCursor(context.Background(), []byte("cpu,host=A#!~#value"), 0, true)
+			defer cur.Close()
+			values, err := cur.ReadIntegerBlock(&[]tsm1.IntegerValue{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int64
+			for _, v := range values {
+				got = append(got, v.UnixNano(), v.Value().(int64))
+			}
+			if exp := []int64{2000000000, 2, 4000000000, 4}; !reflect.DeepEqual(got, exp) {
+				t.Fatalf("unexpected values: got %v, exp %v", got, exp)
+			}
+		})
+	}
+}
+
+// Ensure the engine doesn't import files whose field types conflict with
+// its own.
+func TestEngine_Import_FieldTypeConflict(t *testing.T) {
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) {
+			e := MustOpenEngine(index)
+			defer e.Close()
+
+			if err := e.WritePointsString("cpu,host=A value=1i 1000000000"); err != nil {
+				t.Fatal(err)
+			}
+			e.MustWriteSnapshot()
+
+			var buf bytes.Buffer
+			if err := e.ExportRange(&buf, "", 0, 2000000000); err != nil {
+				t.Fatal(err)
+			}
+
+			other := MustOpenEngine(index)
+			defer other.Close()
+			if err := other.WritePointsString("cpu,host=B value=1.5 1000000000"); err != nil {
+				t.Fatal(err)
+			}
+			other.MustWriteSnapshot()
+			if err := other.MeasurementFields([]byte("cpu")).CreateFieldIfNotExists([]byte("value"), influxql.Float); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := other.Import(&buf, ""); !errors.Is(err, tsdb.ErrFieldTypeConflict) {
+				t.Fatalf("unexpected error: %v", err)
+			} else if n := other.FileStore.Count(); n != 1 {
+				t.Fatalf("unexpected file count: %d", n)
+			}
+
+			files, err := filepath.Glob(filepath.Join(other.Path(), "*."+tsm1.TmpTSMFileExtension))
+			if err != nil {
+				t.Fatal(err)
+			} else if len(files) != 0 {
+				t.Fatalf("unexpected temporary files: %v", files)
+			}
+		})
+	}
+}
+
 func equalBuffers(bufA, bufB *bytes.Buffer) bool {
 	for i, v := range bufA.Bytes() {
 		if v != bufB.Bytes()[i] {
@@ -816,6 +914,59 @@ func TestEngine_CreateIterator_Cache_Ascending(t *testing.T) {
 	}
 }
 
+// Ensure the create_iterator span of a traced query records the cache reads.
+func TestEngine_CreateIterator_Trace(t *testing.T) {
+	t.Parallel()
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) {
+			e := MustOpenEngine(index)
+			defer e.Close()
+
+			e.MeasurementFields([]byte("cpu")).CreateFieldIfNotExists([]byte("value"), influxql.Float)
+			e.CreateSeriesIfNotExists([]byte("cpu,host=A"), []byte("cpu"), models.NewTags(map[string]string{"host": "A"}))
+
+			if err := e.WritePointsString(
+				`cpu,host=A value=1.1 1000000000`,
+				`cpu,host=A value=1.2 2000000000`,
+			); err != nil {
+				t.Fatalf("failed to write points: %s", err.Error())
+			}
+
+			trace, span := tracing.NewTrace("select")
+			ctx := tracing.NewContextWithSpan(context.Background(), span)
+			itr, err := e.CreateIterator(ctx, "cpu", query.IteratorOptions{
+				Expr:       influxql.MustParseExpr(`value`),
+				Dimensions: []string{"host"},
+				StartTime:  influxql.MinTime,
+				EndTime:    influxql.MaxTime,
+				Ascending:  true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			query.DrainIterator(itr)
+			itr.Close()
+			span.Finish()
+
+			tree := trace.Tree()
+			if len(tree.Children) != 1 || tree.Children[0].Raw.Name != "create_iterator" {
+				t.Fatalf("unexpected trace:\n%s", tree)
+			}
+			values := make(map[string]interface{})
+			for _, f := range tree.Children[0].Raw.Fields {
+				values[f.Key()] = f.Value()
+			}
+			if values["cache_hits"] != int64(1) || values["cache_values"] != int64(2) {
+				t.Fatalf("unexpected fields: %v", values)
+			}
+			if _, ok := values["series_set_time"]; !ok {
+				t.Fatalf("expected series_set_time field: %v", values)
+			}
+		})
+	}
+}
+
 // Ensure engine can create an descending iterator for cached values.
 func TestEngine_CreateIterator_Cache_Descending(t *testing.T) {
 	t.Parallel()
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/file"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/metrics"
@@ -181,6 +182,9 @@ type FileStore struct {
 	tsmMMAPWillNeed bool          // If true then the kernel will be advised MMAP_WILLNEED for TSM files.
 	openLimiter     limiter.Fixed // limit the number of concurrent opening TSM files.
 
+	keys   encryption.KeyProvider // If set then encrypted TSM files are decrypted with these keys.
+	chunks *encryption.ChunkCache // Caches the decrypted chunks of encrypted TSM files.
+
 	logger       *zap.Logger // Logger to be used for important messages
 	traceLogger  *zap.Logger // Logger to be used when trace-logging is on.
 	traceLogging bool
@@ -203,6 +207,7 @@ type FileStat struct {
 	LastModified     int64
 	MinTime, MaxTime int64
 	MinKey, MaxKey   []byte
+	KeyID            string // ID of the encryption key of the file, if encrypted
 }
 
 // TombstoneStat holds information about a possible tombstone file on disk.
@@ -540,7 +545,7 @@ func (f *FileStore) Open() error {
 			defer f.openLimiter.Release()
 
 			start := time.Now()
-			df, err := NewTSMReader(file, WithMadviseWillNeed(f.tsmMMAPWillNeed))
+			df, err := NewTSMReader(file, WithMadviseWillNeed(f.tsmMMAPWillNeed), WithKeyProvider(f.keys), WithChunkCache(f.chunks))
 			f.logger.Info("Opened file",
 				zap.String("path", file.Name()),
 				zap.Int("id", idx),
@@ -779,7 +784,7 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 			}
 		}
 
-		tsm, err := NewTSMReader(fd, WithMadviseWillNeed(f.tsmMMAPWillNeed))
+		tsm, err := NewTSMReader(fd, WithMadviseWillNeed(f.tsmMMAPWillNeed), WithKeyProvider(f.keys), WithChunkCache(f.chunks))
 		if err != nil {
 			if newName != oldName {
 				if err1 := os.Rename(newName, oldName); err1 != nil {
@@ -911,6 +916,15 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 	return nil
 }
 
+// CurrentKeyID returns the ID of the encryption key of new TSM files, or an
+// empty string if they are not encrypted.
+func (f *FileStore) CurrentKeyID() string {
+	if f.keys == nil {
+		return ""
+	}
+	return currentKeyID(f.keys)
+}
+
 // LastModified returns the last time the file store was updated with new
 // TSM files or a delete.
 func (f *FileStore) LastModified() time.Time {
@@ -282,3 +282,113 @@ func (m *mmapAccessor) readBooleanArrayBlock(entry *IndexEntry, values *tsdb.Boo
 
 	return err
 }
+
+func (m *encryptedAccessor) readFloatBlock(entry *IndexEntry, values *[]FloatValue) ([]FloatValue, error) {
+	m.mu.RLock()
+	_, b, err := m.block(entry)
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeFloatBlock(b, values)
+}
+
+func (m *encryptedAccessor) readFloatArrayBlock(entry *IndexEntry, values *tsdb.FloatArray) error {
+	m.mu.RLock()
+	_, b, err := m.block(entry)
+	m.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return DecodeFloatArrayBlock(b, values)
+}
+
+func (m *encryptedAccessor) readIntegerBlock(entry *IndexEntry, values *[]IntegerValue) ([]IntegerValue, error) {
+	m.mu.RLock()
+	_, b, err := m.block(entry)
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeIntegerBlock(b, values)
+}
+
+func (m *encryptedAccessor) readIntegerArrayBlock(entry *IndexEntry, values *tsdb.IntegerArray) error {
+	m.mu.RLock()
+	_, b, err := m.block(entry)
+	m.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return DecodeIntegerArrayBlock(b, values)
+}
+
+func (m *encryptedAccessor) readUnsignedBlock(entry *IndexEntry, values *[]UnsignedValue) ([]UnsignedValue, error) {
+	m.mu.RLock()
+	_, b, err := m.block(entry)
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeUnsignedBlock(b, values)
+}
+
+func (m *encryptedAccessor) readUnsignedArrayBlock(entry *IndexEntry, values *tsdb.UnsignedArray) error {
+	m.mu.RLock()
+	_, b, err := m.block(entry)
+	m.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return DecodeUnsignedArrayBlock(b, values)
+}
+
+func (m *encryptedAccessor) readStringBlock(entry *IndexEntry, values *[]StringValue) ([]StringValue, error) {
+	m.mu.RLock()
+	_, b, err := m.block(entry)
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeStringBlock(b, values)
+}
+
+func (m *encryptedAccessor) readStringArrayBlock(entry *IndexEntry, values *tsdb.StringArray) error {
+	m.mu.RLock()
+	_, b, err := m.block(entry)
+	m.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return DecodeStringArrayBlock(b, values)
+}
+
+func (m *encryptedAccessor) readBooleanBlock(entry *IndexEntry, values *[]BooleanValue) ([]BooleanValue, error) {
+	m.mu.RLock()
+	_, b, err := m.block(entry)
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeBooleanBlock(b, values)
+}
+
+func (m *encryptedAccessor) readBooleanArrayBlock(entry *IndexEntry, values *tsdb.BooleanArray) error {
+	m.mu.RLock()
+	_, b, err := m.block(entry)
+	m.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return DecodeBooleanArrayBlock(b, values)
+}
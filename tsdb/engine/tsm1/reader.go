@@ -13,6 +13,7 @@ import (
 	"sync/atomic"
 
 	"github.com/influxdata/influxdb/pkg/bytesutil"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/file"
 	"github.com/influxdata/influxdb/tsdb"
 )
@@ -33,6 +34,12 @@ type TSMReader struct {
 	madviseWillNeed bool // Hint to the kernel with MADV_WILLNEED.
 	mu              sync.RWMutex
 
+	// keys decrypts encrypted files, whose decrypted chunks are cached in
+	// chunks. keyID is the ID of the master key encrypting the file.
+	keys   encryption.KeyProvider
+	chunks *encryption.ChunkCache
+	keyID  string
+
 	// accessor provides access and decoding of blocks for the reader.
 	accessor blockAccessor
 
@@ -218,6 +225,22 @@ var WithMadviseWillNeed = func(willNeed bool) tsmReaderOption {
 	}
 }
 
+// WithKeyProvider is an option for specifying the provider of the keys
+// decrypting encrypted files.
+var WithKeyProvider = func(keys encryption.KeyProvider) tsmReaderOption {
+	return func(r *TSMReader) {
+		r.keys = keys
+	}
+}
+
+// WithChunkCache is an option for specifying the cache of the decrypted
+// chunks of encrypted files.
+var WithChunkCache = func(chunks *encryption.ChunkCache) tsmReaderOption {
+	return func(r *TSMReader) {
+		r.chunks = chunks
+	}
+}
+
 // NewTSMReader returns a new TSMReader from the given file.
 func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 	t := &TSMReader{}
@@ -231,15 +254,37 @@ func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 	}
 	t.size = stat.Size()
 	t.lastModified = stat.ModTime().UnixNano()
-	t.accessor = &mmapAccessor{
-		f:            f,
-		mmapWillNeed: t.madviseWillNeed,
+
+	encrypted, err := isEncryptedFile(f)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		if t.keys == nil {
+			return nil, fmt.Errorf("init: %s is encrypted but no encryption keys are configured", f.Name())
+		}
+		if t.chunks == nil {
+			t.chunks = encryption.NewChunkCache(defaultChunkCacheSize)
+		}
+		t.accessor = &encryptedAccessor{
+			f:      f,
+			keys:   t.keys,
+			chunks: t.chunks,
+		}
+	} else {
+		t.accessor = &mmapAccessor{
+			f:            f,
+			mmapWillNeed: t.madviseWillNeed,
+		}
 	}
 
 	index, err := t.accessor.init()
 	if err != nil {
 		return nil, err
 	}
+	if a, ok := t.accessor.(*encryptedAccessor); ok {
+		t.keyID = a.r.KeyID()
+	}
 
 	t.index = index
 	t.tombstoner = NewTombstoner(t.Path(), index.ContainsKey)
@@ -570,6 +615,7 @@ func (t *TSMReader) Stats() FileStat {
 		MinKey:       minKey,
 		MaxKey:       maxKey,
 		HasTombstone: t.tombstoner.HasTombstones(),
+		KeyID:        t.keyID,
 	}
 }
 
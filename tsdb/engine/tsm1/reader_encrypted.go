@@ -0,0 +1,235 @@
+package tsm1
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/pkg/file"
+)
+
+// defaultChunkCacheSize is the size of the cache of decrypted chunks of the
+// readers not given a cache shared with other readers.
+const defaultChunkCacheSize = 16 * encryption.ChunkSize
+
+// isEncryptedFile returns true if f starts with the magic number of encrypted
+// files.
+func isEncryptedFile(f *os.File) (bool, error) {
+	var b [4]byte
+	if _, err := f.ReadAt(b[:], 0); err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return encryption.IsEncrypted(b[:]), nil
+}
+
+// encryptedAccessor is the block accessor of encrypted TSM files. The index
+// is decrypted into memory when the file is opened, but blocks are read by
+// decrypting only the chunks of the file holding them. Decrypted chunks are
+// kept in a cache shared by the readers.
+type encryptedAccessor struct {
+	mu sync.RWMutex
+	f  *os.File
+	r  *encryption.ReaderAt
+
+	keys   encryption.KeyProvider
+	chunks *encryption.ChunkCache
+
+	index *indirectIndex
+}
+
+func (m *encryptedAccessor) init() (*indirectIndex, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.open(); err != nil {
+		return nil, err
+	}
+	if err := verifyVersion(io.NewSectionReader(m.r, 0, m.r.Size())); err != nil {
+		return nil, err
+	}
+
+	size := m.r.Size()
+	if size < 8 {
+		return nil, fmt.Errorf("encryptedAccessor: file too small for indirectIndex")
+	}
+
+	var footer [8]byte
+	if _, err := m.r.ReadAt(footer[:], size-8); err != nil {
+		return nil, err
+	}
+	indexOfsPos := size - 8
+	indexStart := binary.BigEndian.Uint64(footer[:])
+	if indexStart >= uint64(indexOfsPos) {
+		return nil, fmt.Errorf("encryptedAccessor: invalid indexStart")
+	}
+
+	b := make([]byte, indexOfsPos-int64(indexStart))
+	if _, err := m.r.ReadAt(b, int64(indexStart)); err != nil {
+		return nil, err
+	}
+	m.index = NewIndirectIndex()
+	if err := m.index.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+
+	return m.index, nil
+}
+
+// open opens the reader of the plaintext of the file. It assumes m's mutex
+// is already locked.
+func (m *encryptedAccessor) open() error {
+	stat, err := m.f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if m.r, err = encryption.NewReaderAt(m.f, stat.Size(), m.keys, m.chunks); err != nil {
+		return fmt.Errorf("encryptedAccessor: cannot decrypt %s: %w", m.f.Name(), err)
+	}
+	return nil
+}
+
+// block returns the checksum and the data of the block of entry. It assumes
+// m's mutex is already locked.
+func (m *encryptedAccessor) block(entry *IndexEntry) (uint32, []byte, error) {
+	if m.r == nil || m.r.Size() < entry.Offset+int64(entry.Size) {
+		return 0, nil, ErrTSMClosed
+	}
+
+	b := make([]byte, entry.Size)
+	if _, err := m.r.ReadAt(b, entry.Offset); err != nil {
+		return 0, nil, fmt.Errorf("encryptedAccessor: cannot read block of %s: %w", m.f.Name(), err)
+	}
+	return binary.BigEndian.Uint32(b[:4]), b[4:], nil
+}
+
+// free is a no-op: the cached chunks are evicted as other chunks are read.
+func (m *encryptedAccessor) free() error { return nil }
+
+func (m *encryptedAccessor) rename(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.r.Close(); err != nil {
+		return err
+	}
+	m.r = nil
+
+	if err := m.f.Close(); err != nil {
+		return err
+	}
+
+	if err := file.RenameFile(m.f.Name(), path); err != nil {
+		return err
+	}
+
+	var err error
+	m.f, err = os.Open(path)
+	if err != nil {
+		return err
+	}
+	return m.open()
+}
+
+func (m *encryptedAccessor) read(key []byte, timestamp int64) ([]Value, error) {
+	entry := m.index.Entry(key, timestamp)
+	if entry == nil {
+		return nil, nil
+	}
+
+	return m.readBlock(entry, nil)
+}
+
+func (m *encryptedAccessor) readBlock(entry *IndexEntry, values []Value) ([]Value, error) {
+	m.mu.RLock()
+	_, b, err := m.block(entry)
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeBlock(b, values)
+}
+
+func (m *encryptedAccessor) readBytes(entry *IndexEntry, b []byte) (uint32, []byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.block(entry)
+}
+
+// readAll returns all values for a key in all blocks.
+func (m *encryptedAccessor) readAll(key []byte) ([]Value, error) {
+	blocks := m.index.Entries(key)
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+
+	tombstones := m.index.TombstoneRange(key)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var temp []Value
+	var values []Value
+	for i := range blocks {
+		block := &blocks[i]
+
+		var skip bool
+		for _, t := range tombstones {
+			// Should we skip this block because it contains points that have been deleted
+			if t.Min <= block.MinTime && t.Max >= block.MaxTime {
+				skip = true
+				break
+			}
+		}
+
+		if skip {
+			continue
+		}
+
+		_, b, err := m.block(block)
+		if err != nil {
+			return nil, err
+		}
+		temp, err = DecodeBlock(b, temp[:0])
+		if err != nil {
+			return nil, err
+		}
+
+		// Filter out any values that were deleted
+		for _, t := range tombstones {
+			temp = Values(temp).Exclude(t.Min, t.Max)
+		}
+
+		values = append(values, temp...)
+	}
+
+	return values, nil
+}
+
+func (m *encryptedAccessor) path() string {
+	m.mu.RLock()
+	path := m.f.Name()
+	m.mu.RUnlock()
+	return path
+}
+
+func (m *encryptedAccessor) close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.r == nil {
+		return nil
+	}
+
+	if err := m.r.Close(); err != nil {
+		return err
+	}
+	m.r = nil
+	return m.f.Close()
+}
@@ -1,14 +1,20 @@
 package tsm1
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"math"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
 
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/tsdb"
 	"github.com/stretchr/testify/require"
 )
 
@@ -58,6 +64,180 @@ func TestTSMReader_Type(t *testing.T) {
 	}
 }
 
+func TestTSMReader_Encrypted(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+	f := mustTempFile(dir)
+	keys := mustKeyFile(t, "k1")
+
+	ew, err := encryption.NewWriter(f, keys)
+	if err != nil {
+		t.Fatalf("unexpected error creating encrypted writer: %v", err)
+	}
+	w, err := NewTSMWriter(ew)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+
+	values := []Value{NewValue(0, 1.0), NewValue(1, 2.0)}
+	if err := w.Write([]byte("cpu,host=server01#!~#value"), values); err != nil {
+		t.Fatalf("unexpected error writing: %v", err)
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatalf("unexpected error writing index: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error reading file: %v", err)
+	} else if !encryption.IsEncrypted(b) {
+		t.Fatalf("expected encrypted file")
+	} else if bytes.Contains(b, []byte("server01")) {
+		t.Fatalf("series key found in encrypted file")
+	}
+
+	// Encrypted files cannot be read without the keys.
+	f, err = os.Open(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error opening: %v", err)
+	}
+	if _, err := NewTSMReader(f); err == nil || !strings.Contains(err.Error(), "no encryption keys") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f, err = os.Open(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error opening: %v", err)
+	}
+	r, err := NewTSMReader(f, WithKeyProvider(keys))
+	if err != nil {
+		t.Fatalf("unexpected error created reader: %v", err)
+	}
+	defer r.Close()
+
+	// Blocks are still read after the file is renamed.
+	if err := r.Rename(f.Name() + ".renamed"); err != nil {
+		t.Fatalf("unexpected error renaming: %v", err)
+	}
+
+	readValues, err := r.ReadAll([]byte("cpu,host=server01#!~#value"))
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	} else if got, exp := len(readValues), len(values); got != exp {
+		t.Fatalf("read values length mismatch: got %v, exp %v", got, exp)
+	}
+	for i, v := range values {
+		if v.Value() != readValues[i].Value() {
+			t.Fatalf("read value mismatch(%d): got %v, exp %v", i, readValues[i].Value(), v.Value())
+		}
+	}
+}
+
+func TestTSMReader_Encrypted_ChunkCache(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+	f := mustTempFile(dir)
+	keys := mustKeyFile(t, "k1")
+
+	ew, err := encryption.NewWriter(f, keys)
+	if err != nil {
+		t.Fatalf("unexpected error creating encrypted writer: %v", err)
+	}
+	w, err := NewTSMWriter(ew)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+
+	// Random values span the blocks of the keys over many chunks.
+	data := make(map[string][]Value)
+	var names []string
+	for i := 0; i < 200; i++ {
+		name := fmt.Sprintf("cpu,host=server%03d#!~#value", i)
+		values := make([]Value, 1000)
+		for j := range values {
+			values[j] = NewValue(int64(j), rand.Float64())
+		}
+		if err := w.Write([]byte(name), values); err != nil {
+			t.Fatalf("unexpected error writing: %v", err)
+		}
+		data[name] = values
+		names = append(names, name)
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatalf("unexpected error writing index: %v", err)
+	} else if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	f, err = os.Open(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error opening: %v", err)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if stat.Size() < 8*encryption.ChunkSize {
+		t.Fatalf("file too small to span many chunks: %d", stat.Size())
+	}
+
+	cache := encryption.NewChunkCache(8 * encryption.ChunkSize)
+	r, err := NewTSMReader(f, WithKeyProvider(keys), WithChunkCache(cache))
+	if err != nil {
+		t.Fatalf("unexpected error created reader: %v", err)
+	}
+	defer r.Close()
+	opened := cache.Size()
+
+	// Reading a key only decrypts the chunks holding its blocks.
+	name := names[len(names)/2]
+	readValues, err := r.ReadAll([]byte(name))
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	} else if got, exp := len(readValues), len(data[name]); got != exp {
+		t.Fatalf("read values length mismatch: got %v, exp %v", got, exp)
+	}
+	for i, v := range data[name] {
+		if v.Value() != readValues[i].Value() {
+			t.Fatalf("read value mismatch(%d): got %v, exp %v", i, readValues[i].Value(), v.Value())
+		}
+	}
+	if size := cache.Size() - opened; size == 0 || size > 2*encryption.ChunkSize {
+		t.Fatalf("unexpected size of chunks cached by the read: %d", size)
+	}
+
+	// Reading every key evicts the least recently used chunks.
+	for _, name := range names {
+		var a tsdb.FloatArray
+		for _, e := range r.Entries([]byte(name)) {
+			var b tsdb.FloatArray
+			if err := r.ReadFloatArrayBlockAt(&e, &b); err != nil {
+				t.Fatalf("unexpected error reading: %v", err)
+			}
+			a.Values = append(a.Values, b.Values...)
+		}
+		if got, exp := len(a.Values), len(data[name]); got != exp {
+			t.Fatalf("%s: read values length mismatch: got %v, exp %v", name, got, exp)
+		}
+		for i, v := range data[name] {
+			if v.Value() != a.Values[i] {
+				t.Fatalf("%s: read value mismatch(%d): got %v, exp %v", name, i, a.Values[i], v.Value())
+			}
+		}
+	}
+	if size := cache.Size(); size > 8*encryption.ChunkSize {
+		t.Fatalf("cache exceeds its size: %d", size)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	} else if size := cache.Size(); size != 0 {
+		t.Fatalf("chunks left in cache after close: %d", size)
+	}
+}
+
 func TestTSMReader_MMAP_ReadAll(t *testing.T) {
 	dir := mustTempDir()
 	defer os.RemoveAll(dir)
@@ -1935,3 +2115,25 @@ func BenchmarkBlockIterator_Next(b *testing.B) {
 		}
 	}
 }
+
+// mustKeyFile returns an encryption.KeyFile with new keys with the given IDs.
+func mustKeyFile(t *testing.T, ids ...string) *encryption.KeyFile {
+	t.Helper()
+	var content string
+	for _, id := range ids {
+		key := make([]byte, encryption.KeySize)
+		for i := range key {
+			key[i] = byte(len(content) + i)
+		}
+		content += id + " " + base64.StdEncoding.EncodeToString(key) + "\n"
+	}
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	kf, err := encryption.NewKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kf
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/golang/snappy"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/pool"
 	"go.uber.org/zap"
@@ -114,6 +115,9 @@ type WAL struct {
 	// statistics for the WAL
 	stats   *WALStatistics
 	limiter limiter.Fixed
+
+	// keys encrypts new segments, if set.
+	keys encryption.KeyProvider
 }
 
 // NewWAL initializes a new WAL at the given directory.
@@ -220,11 +224,39 @@ func (l *WAL) Open() error {
 			if _, err := fd.Seek(0, io.SeekEnd); err != nil {
 				return err
 			}
-			l.currentSegmentWriter = NewWALSegmentWriter(fd)
 
-			// Set the correct size on the segment writer
-			atomic.StoreInt64(&l.stats.CurrentBytes, stat.Size())
-			l.currentSegmentWriter.size = int(stat.Size())
+			// Entries appended to an encrypted segment are encrypted with the
+			// key of the segment.
+			key, err := readSegmentKey(fd, l.keys)
+			if err != nil {
+				fd.Close()
+				return err
+			}
+
+			// A segment not encrypted with the current key is not appended
+			// to, so that it is removed by the next snapshot and the rotated
+			// key is no longer needed.
+			if l.keys != nil && segmentKeyID(key) != currentKeyID(l.keys) {
+				if err := fd.Close(); err != nil {
+					return err
+				}
+
+				// A segment holding only its header has no entries to
+				// snapshot, so it is removed now.
+				if key != nil && stat.Size() <= int64(len(key.Header())) {
+					if err := os.Remove(lastSegment); err != nil {
+						return err
+					}
+					segments = segments[:len(segments)-1]
+				}
+			} else {
+				l.currentSegmentWriter = NewWALSegmentWriter(fd)
+				l.currentSegmentWriter.key = key
+
+				// Set the correct size on the segment writer
+				atomic.StoreInt64(&l.stats.CurrentBytes, stat.Size())
+				l.currentSegmentWriter.size = int(stat.Size())
+			}
 		}
 	}
 
@@ -574,6 +606,12 @@ func (l *WAL) newSegmentFile() error {
 		return err
 	}
 	l.currentSegmentWriter = NewWALSegmentWriter(fd)
+	if l.keys != nil {
+		if err := l.currentSegmentWriter.encrypt(l.keys); err != nil {
+			fd.Close()
+			return err
+		}
+	}
 
 	// Reset the current segment size stat
 	atomic.StoreInt64(&l.stats.CurrentBytes, 0)
@@ -1041,6 +1079,10 @@ type WALSegmentWriter struct {
 	bw   *bufio.Writer
 	w    io.WriteCloser
 	size int
+
+	// key encrypts the entries of encrypted segments.
+	key *encryption.FileKey
+	buf []byte
 }
 
 // NewWALSegmentWriter returns a new WALSegmentWriter writing to w.
@@ -1051,6 +1093,21 @@ func NewWALSegmentWriter(w io.WriteCloser) *WALSegmentWriter {
 	}
 }
 
+// encrypt writes the header of an encrypted segment with a new data key, which
+// encrypts the entries written after it.
+func (w *WALSegmentWriter) encrypt(keys encryption.KeyProvider) error {
+	key, err := encryption.NewFileKey(keys, encryption.ModeRecords)
+	if err != nil {
+		return err
+	}
+	if _, err := w.bw.Write(key.Header()); err != nil {
+		return err
+	}
+	w.size += len(key.Header())
+	w.key = key
+	return nil
+}
+
 func (w *WALSegmentWriter) path() string {
 	if f, ok := w.w.(*os.File); ok {
 		return f.Name()
@@ -1060,6 +1117,11 @@ func (w *WALSegmentWriter) path() string {
 
 // Write writes entryType and the buffer containing compressed entry data.
 func (w *WALSegmentWriter) Write(entryType WalEntryType, compressed []byte) error {
+	if w.key != nil {
+		w.buf = w.key.Seal(w.buf[:0], compressed)
+		compressed = w.buf
+	}
+
 	var buf [5]byte
 	buf[0] = byte(entryType)
 	binary.BigEndian.PutUint32(buf[1:5], uint32(len(compressed)))
@@ -1108,6 +1170,9 @@ type WALSegmentReader struct {
 	entry WALEntry
 	n     int64
 	err   error
+
+	// key decrypts the entries of encrypted segments.
+	key *encryption.FileKey
 }
 
 // NewWALSegmentReader returns a new WALSegmentReader reading from r.
@@ -1124,6 +1189,19 @@ func (r *WALSegmentReader) Reset(rc io.ReadCloser) {
 	r.entry = nil
 	r.n = 0
 	r.err = nil
+	r.key = nil
+}
+
+// setKey skips the header of an encrypted segment and decrypts the entries
+// after it with key, the data key of the segment read by readSegmentKey.
+func (r *WALSegmentReader) setKey(key *encryption.FileKey) error {
+	r.key = key
+	if key == nil {
+		return nil
+	}
+	n, err := r.r.Discard(len(key.Header()))
+	r.n += int64(n)
+	return err
 }
 
 // Next indicates if there is a value to read.
@@ -1159,7 +1237,18 @@ func (r *WALSegmentReader) Next() bool {
 	}
 	nReadOK += n
 
-	decLen, err := snappy.DecodedLen(b[:length])
+	compressed := b[:length]
+	if r.key != nil {
+		plain := *(getBuf(int(length)))
+		defer putBuf(&plain)
+
+		if compressed, err = r.key.Open(plain[:0], compressed); err != nil {
+			r.err = err
+			return true
+		}
+	}
+
+	decLen, err := snappy.DecodedLen(compressed)
 	if err != nil {
 		r.err = err
 		return true
@@ -1167,7 +1256,7 @@ func (r *WALSegmentReader) Next() bool {
 	decBuf := *(getBuf(decLen))
 	defer putBuf(&decBuf)
 
-	data, err := snappy.Decode(decBuf, b[:length])
+	data, err := snappy.Decode(decBuf, compressed)
 	if err != nil {
 		r.err = err
 		return true
@@ -1226,6 +1315,41 @@ func (r *WALSegmentReader) Close() error {
 	return err
 }
 
+// readSegmentKey returns the data key of an encrypted segment, or nil if the
+// segment is not encrypted.
+func readSegmentKey(f *os.File, keys encryption.KeyProvider) (*encryption.FileKey, error) {
+	if encrypted, err := isEncryptedFile(f); err != nil || !encrypted {
+		return nil, err
+	} else if keys == nil {
+		return nil, fmt.Errorf("%s is encrypted but no encryption keys are configured", f.Name())
+	}
+
+	key, err := encryption.ReadFileKey(io.NewSectionReader(f, 0, math.MaxInt64), keys, encryption.ModeRecords)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name(), err)
+	}
+	return key, nil
+}
+
+// segmentKeyID returns the ID of the key of a segment, or an empty string if
+// the segment is not encrypted.
+func segmentKeyID(key *encryption.FileKey) string {
+	if key == nil {
+		return ""
+	}
+	return key.KeyID()
+}
+
+// currentKeyID returns the ID of the key encrypting new files, or an empty
+// string if it cannot be read.
+func currentKeyID(keys encryption.KeyProvider) string {
+	id, _, err := keys.CurrentKey()
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
 // idFromFileName parses the segment file ID from its name.
 func idFromFileName(name string) (int, error) {
 	parts := strings.Split(filepath.Base(name), ".")
@@ -13,6 +13,17 @@ type FieldValidator interface {
 	Validate(mf *MeasurementFields, point models.Point) error
 }
 
+// Schema declares the points that may be written to a database.
+type Schema interface {
+	// ValidatePoint returns the reason the point does not match the schema,
+	// or an empty string if it does.
+	ValidatePoint(p models.Point) string
+
+	// Strict returns true if the points that do not match the schema should
+	// be dropped. Otherwise they are written and a warning is logged.
+	Strict() bool
+}
+
 // defaultFieldValidator ensures that points do not use different types for fields that already exist.
 type defaultFieldValidator struct{}
 
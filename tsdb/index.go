@@ -2942,7 +2942,7 @@ func (is IndexSet) TagSets(sfile *SeriesFile, name []byte, opt query.IteratorOpt
 		}
 
 		if seriesN > maxSeriesN {
-			return nil, fmt.Errorf("max-select-series limit exceeded: (%d/%d)", seriesN, opt.MaxSeriesN)
+			return nil, query.ErrMaxSelectSeriesLimitExceeded(seriesN, opt.MaxSeriesN)
 		}
 
 		// NOTE - must not escape this loop iteration.
@@ -400,7 +400,7 @@ func (m *measurement) TagSets(shardSeriesIDs *tsdb.SeriesIDSet, opt query.Iterat
 
 		if opt.MaxSeriesN > 0 && seriesN > opt.MaxSeriesN {
 			m.mu.RUnlock()
-			return nil, fmt.Errorf("max-select-series limit exceeded: (%d/%d)", seriesN, opt.MaxSeriesN)
+			return nil, query.ErrMaxSelectSeriesLimitExceeded(seriesN, opt.MaxSeriesN)
 		}
 
 		s := m.seriesByID[id]
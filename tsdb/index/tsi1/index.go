@@ -15,6 +15,7 @@ import (
 
 	"github.com/cespare/xxhash"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/estimator"
 	"github.com/influxdata/influxdb/pkg/estimator/hll"
 	"github.com/influxdata/influxdb/pkg/slices"
@@ -44,6 +45,8 @@ func init() {
 			WithPath(path),
 			WithMaximumLogFileSize(int64(opt.Config.MaxIndexLogFileSize)),
 			WithSeriesIDCacheSize(opt.Config.SeriesIDSetCacheSize),
+			WithKeyProvider(opt.KeyProvider),
+			WithChunkCache(opt.ChunkCache),
 		)
 		return idx
 	})
@@ -121,6 +124,22 @@ var WithSeriesIDCacheSize = func(sz int) IndexOption {
 	}
 }
 
+// WithKeyProvider sets the keys encrypting new log and index files and
+// decrypting encrypted files.
+var WithKeyProvider = func(keys encryption.KeyProvider) IndexOption {
+	return func(i *Index) {
+		i.keys = keys
+	}
+}
+
+// WithChunkCache sets the cache of the decrypted chunks of encrypted index
+// files.
+var WithChunkCache = func(chunks *encryption.ChunkCache) IndexOption {
+	return func(i *Index) {
+		i.chunks = chunks
+	}
+}
+
 // Index represents a collection of layered index files and WAL.
 type Index struct {
 	mu         sync.RWMutex
@@ -131,12 +150,14 @@ type Index struct {
 	tagValueCacheSize int
 
 	// The following may be set when initializing an Index.
-	path               string      // Root directory of the index partitions.
-	disableCompactions bool        // Initially disables compactions on the index.
-	maxLogFileSize     int64       // Maximum size of a LogFile before it's compacted.
-	logfileBufferSize  int         // The size of the buffer used by the LogFile.
-	disableFsync       bool        // Disables flushing buffers and fsyning files. Used when working with indexes offline.
-	logger             *zap.Logger // Index's logger.
+	path               string                 // Root directory of the index partitions.
+	disableCompactions bool                   // Initially disables compactions on the index.
+	maxLogFileSize     int64                  // Maximum size of a LogFile before it's compacted.
+	logfileBufferSize  int                    // The size of the buffer used by the LogFile.
+	disableFsync       bool                   // Disables flushing buffers and fsyning files. Used when working with indexes offline.
+	keys               encryption.KeyProvider // If set then new log and index files are encrypted with these keys.
+	chunks             *encryption.ChunkCache // Caches the decrypted chunks of encrypted index files.
+	logger             *zap.Logger            // Index's logger.
 
 	// The following must be set when initializing an Index.
 	sfile    *tsdb.SeriesFile // series lookup file
@@ -262,6 +283,7 @@ func (i *Index) Open() error {
 		p.MaxLogFileSize = i.maxLogFileSize
 		p.nosync = i.disableFsync
 		p.logbufferSize = i.logfileBufferSize
+		p.keys, p.chunks = i.keys, i.chunks
 		p.logger = i.logger.With(zap.String("tsi1_partition", fmt.Sprint(j+1)))
 		i.partitions[j] = p
 	}
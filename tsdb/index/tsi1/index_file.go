@@ -6,10 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"sync"
 	"unsafe"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/estimator"
 	"github.com/influxdata/influxdb/pkg/estimator/hll"
 	"github.com/influxdata/influxdb/pkg/mmap"
@@ -22,6 +24,10 @@ const IndexFileVersion = 1
 // FileSignature represents a magic number at the header of the index file.
 const FileSignature = "TSI1"
 
+// defaultChunkCacheSize is the size of the cache of decrypted chunks of the
+// encrypted files not given a cache shared with other files.
+const defaultChunkCacheSize = 16 * encryption.ChunkSize
+
 // IndexFile field size constants.
 const (
 	// IndexFile trailer fields
@@ -70,6 +76,15 @@ type IndexFile struct {
 
 	// Path to data file.
 	path string
+
+	// Encrypted files are read through r instead of being mapped. Their
+	// measurement block, sketches and series sets are decrypted into memory
+	// when the file is opened, but tag blocks are decrypted when used and
+	// kept in a cache shared with the other files.
+	keys   encryption.KeyProvider
+	chunks *encryption.ChunkCache
+	file   *os.File
+	r      *encryption.ReaderAt
 }
 
 // NewIndexFile returns a new instance of IndexFile.
@@ -86,6 +101,11 @@ func (f *IndexFile) bytes() int {
 	b += 16 // wg WaitGroup is 16 bytes
 	b += int(unsafe.Sizeof(f.data))
 	// Do not count f.data contents because it is mmap'd
+	if f.r != nil {
+		// Count the decrypted data of encrypted files held in memory
+		b += len(f.sketchData) + len(f.tSketchData) + len(f.seriesIDSetData) + len(f.tombstoneSeriesIDSetData)
+		b += len(f.mblk.data)
+	}
 	b += int(unsafe.Sizeof(f.sfile))
 	// Do not count SeriesFile because it belongs to the code that constructed this IndexFile.
 	b += int(unsafe.Sizeof(f.tblks))
@@ -122,9 +142,115 @@ func (f *IndexFile) Open() error {
 		return err
 	}
 
+	if encryption.IsEncrypted(data) {
+		if err := mmap.Unmap(data); err != nil {
+			return err
+		}
+		return f.openEncrypted()
+	}
+
 	return f.UnmarshalBinary(data)
 }
 
+// openEncrypted opens an encrypted file for reading through f.r.
+func (f *IndexFile) openEncrypted() error {
+	if f.keys == nil {
+		return fmt.Errorf("%s is encrypted but no encryption keys are configured", f.Path())
+	}
+
+	file, err := os.Open(f.Path())
+	if err != nil {
+		return err
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	if f.chunks == nil {
+		f.chunks = encryption.NewChunkCache(defaultChunkCacheSize)
+	}
+	r, err := encryption.NewReaderAt(file, fi.Size(), f.keys, f.chunks)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("cannot decrypt %s: %w", f.Path(), err)
+	}
+
+	f.file, f.r = file, r
+	if err := f.unmarshalEncrypted(); err != nil {
+		f.r.Close()
+		f.file.Close()
+		f.file, f.r = nil, nil
+		return fmt.Errorf("cannot decrypt %s: %w", f.Path(), err)
+	}
+	return nil
+}
+
+// unmarshalEncrypted reads the parts of an encrypted file other than its tag
+// blocks.
+func (f *IndexFile) unmarshalEncrypted() error {
+	size := f.r.Size()
+	if size < int64(len(FileSignature)+IndexFileTrailerSize) {
+		return io.ErrShortBuffer
+	} else if sig, err := f.r.Section(0, int64(len(FileSignature))); err != nil {
+		return err
+	} else if !bytes.Equal(sig, []byte(FileSignature)) {
+		return ErrInvalidIndexFile
+	}
+
+	// Read index file trailer.
+	buf, err := f.r.Section(size-IndexFileTrailerSize, IndexFileTrailerSize)
+	if err != nil {
+		return err
+	}
+	t, err := ReadIndexFileTrailer(buf)
+	if err != nil {
+		return err
+	}
+
+	// Read series sketch and series set data, and the measurement block.
+	for _, r := range []struct {
+		b    *[]byte
+		data struct{ Offset, Size int64 }
+	}{
+		{&f.sketchData, t.SeriesSketch},
+		{&f.tSketchData, t.TombstoneSeriesSketch},
+		{&f.seriesIDSetData, t.SeriesIDSet},
+		{&f.tombstoneSeriesIDSetData, t.TombstoneSeriesIDSet},
+		{&buf, t.MeasurementBlock},
+	} {
+		*r.b = make([]byte, r.data.Size)
+		if _, err := f.r.ReadAt(*r.b, r.data.Offset); err != nil {
+			return err
+		}
+	}
+	return f.mblk.UnmarshalBinary(buf)
+}
+
+// tagBlock returns the tag block of a measurement, or nil if the file does not
+// have the measurement. The tag blocks of encrypted files are decrypted when
+// used, and nil is also returned if they cannot be decrypted.
+func (f *IndexFile) tagBlock(name []byte) *TagBlock {
+	if f.r == nil {
+		return f.tblks[string(name)]
+	}
+
+	e, ok := f.mblk.Elem(name)
+	if !ok {
+		return nil
+	}
+	buf, err := f.r.Section(e.tagBlock.offset, e.tagBlock.size)
+	if err != nil {
+		return nil
+	}
+	var tblk TagBlock
+	if err := tblk.UnmarshalBinary(buf); err != nil {
+		return nil
+	}
+	return &tblk
+}
+
 // Close unmaps the data file.
 func (f *IndexFile) Close() error {
 	// Wait until all references are released.
@@ -133,6 +259,11 @@ func (f *IndexFile) Close() error {
 	f.sfile = nil
 	f.tblks = nil
 	f.mblk = MeasurementBlock{}
+	if f.r != nil {
+		f.r.Close()
+		f.r = nil
+		return f.file.Close()
+	}
 	return mmap.Unmap(f.data)
 }
 
@@ -145,6 +276,15 @@ func (f *IndexFile) Path() string { return f.path }
 // SetPath sets the file's path.
 func (f *IndexFile) SetPath(path string) { f.path = path }
 
+// KeyID returns the ID of the encryption key of the file, or an empty string
+// if it is not encrypted.
+func (f *IndexFile) KeyID() string {
+	if f.r == nil {
+		return ""
+	}
+	return f.r.KeyID()
+}
+
 // Level returns the compaction level for the file.
 func (f *IndexFile) Level() int { return f.level }
 
@@ -155,7 +295,12 @@ func (f *IndexFile) Retain() { f.wg.Add(1) }
 func (f *IndexFile) Release() { f.wg.Done() }
 
 // Size returns the size of the index file, in bytes.
-func (f *IndexFile) Size() int64 { return int64(len(f.data)) }
+func (f *IndexFile) Size() int64 {
+	if f.r != nil {
+		return f.r.Size()
+	}
+	return int64(len(f.data))
+}
 
 // Compacting returns true if the file is being compacted.
 func (f *IndexFile) Compacting() bool {
@@ -274,7 +419,7 @@ func (f *IndexFile) MeasurementHasSeries(ss *tsdb.SeriesIDSet, name []byte) (ok
 // TagValueIterator returns a value iterator for a tag key and a flag
 // indicating if a tombstone exists on the measurement or key.
 func (f *IndexFile) TagValueIterator(name, key []byte) TagValueIterator {
-	tblk := f.tblks[string(name)]
+	tblk := f.tagBlock(name)
 	if tblk == nil {
 		return nil
 	}
@@ -292,7 +437,7 @@ func (f *IndexFile) TagValueIterator(name, key []byte) TagValueIterator {
 // TagKeySeriesIDIterator returns a series iterator for a tag key and a flag
 // indicating if a tombstone exists on the measurement or key.
 func (f *IndexFile) TagKeySeriesIDIterator(name, key []byte) (tsdb.SeriesIDIterator, error) {
-	tblk := f.tblks[string(name)]
+	tblk := f.tagBlock(name)
 	if tblk == nil {
 		return nil, nil
 	}
@@ -325,7 +470,7 @@ func (f *IndexFile) TagKeySeriesIDIterator(name, key []byte) (tsdb.SeriesIDItera
 
 // TagValueSeriesIDSet returns a series id set for a tag value.
 func (f *IndexFile) TagValueSeriesIDSet(name, key, value []byte) (*tsdb.SeriesIDSet, error) {
-	tblk := f.tblks[string(name)]
+	tblk := f.tagBlock(name)
 	if tblk == nil {
 		return nil, nil
 	}
@@ -342,7 +487,7 @@ func (f *IndexFile) TagValueSeriesIDSet(name, key, value []byte) (*tsdb.SeriesID
 
 // TagKey returns a tag key.
 func (f *IndexFile) TagKey(name, key []byte) TagKeyElem {
-	tblk := f.tblks[string(name)]
+	tblk := f.tagBlock(name)
 	if tblk == nil {
 		return nil
 	}
@@ -351,7 +496,7 @@ func (f *IndexFile) TagKey(name, key []byte) TagKeyElem {
 
 // TagValue returns a tag value.
 func (f *IndexFile) TagValue(name, key, value []byte) TagValueElem {
-	tblk := f.tblks[string(name)]
+	tblk := f.tagBlock(name)
 	if tblk == nil {
 		return nil
 	}
@@ -365,8 +510,8 @@ func (f *IndexFile) HasSeries(name []byte, tags models.Tags, buf []byte) (exists
 
 // TagValueElem returns an element for a measurement/tag/value.
 func (f *IndexFile) TagValueElem(name, key, value []byte) TagValueElem {
-	tblk, ok := f.tblks[string(name)]
-	if !ok {
+	tblk := f.tagBlock(name)
+	if tblk == nil {
 		return nil
 	}
 	return tblk.TagValueElem(key, value)
@@ -379,7 +524,7 @@ func (f *IndexFile) MeasurementIterator() MeasurementIterator {
 
 // TagKeyIterator returns an iterator over all tag keys for a measurement.
 func (f *IndexFile) TagKeyIterator(name []byte) TagKeyIterator {
-	blk := f.tblks[string(name)]
+	blk := f.tagBlock(name)
 	if blk == nil {
 		return nil
 	}
@@ -2,6 +2,7 @@ package tsi1_test
 
 import (
 	"bytes"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -101,8 +102,8 @@ func TestGenerateIndexFile(t *testing.T) {
 
 // Ensure index file generated with uvarint encoding can be loaded.
 func TestGenerateIndexFile_Uvarint(t *testing.T) {
-	// Load previously generated series file.
-	sfile := tsdb.NewSeriesFile("testdata/uvarint/_series")
+	// The series of the index file are not needed to load it.
+	sfile := tsdb.NewSeriesFile(filepath.Join(t.TempDir(), "_series"))
 	if err := sfile.Open(); err != nil {
 		t.Fatal(err)
 	}
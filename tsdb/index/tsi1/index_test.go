@@ -1,7 +1,9 @@
 package tsi1_test
 
 import (
+	"bytes"
 	"compress/gzip"
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -9,10 +11,12 @@ import (
 	"reflect"
 	"regexp"
 	"sort"
+	"strings"
 	"sync"
 	"testing"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/index/tsi1"
 )
@@ -270,6 +274,101 @@ func TestIndex_Open(t *testing.T) {
 	}
 }
 
+func TestIndex_Encrypted(t *testing.T) {
+	keys := mustKeyFile(t, "k1")
+	sfile := NewSeriesFile()
+	sfile.WithKeyProvider(keys)
+	if err := sfile.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer sfile.Close()
+	path := MustTempDir()
+	defer os.RemoveAll(path)
+	cache := encryption.NewChunkCache(encryption.ChunkSize)
+
+	open := func(keys encryption.KeyProvider, maxLogFileSize int64) (*tsi1.Index, error) {
+		idx := tsi1.NewIndex(sfile.SeriesFile, "db0", tsi1.WithPath(path), tsi1.WithKeyProvider(keys), tsi1.WithChunkCache(cache), tsi1.WithMaximumLogFileSize(maxLogFileSize))
+		idx.PartitionN = 1
+		return idx, idx.Open()
+	}
+	idx, err := open(keys, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := func(region string) {
+		t.Helper()
+		tags := models.NewTags(map[string]string{"secret": region})
+		if err := idx.CreateSeriesListIfNotExists([][]byte{models.MakeKey([]byte("cpu"), tags)}, [][]byte{[]byte("cpu")}, []models.Tags{tags}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exists := func(region string) {
+		t.Helper()
+		if ok, err := idx.HasTagValue([]byte("cpu"), []byte("secret"), []byte(region)); err != nil {
+			t.Fatal(err)
+		} else if !ok {
+			t.Fatalf("tag value %q not found", region)
+		}
+	}
+
+	// The log file is replayed on reopening.
+	create("east")
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	} else if idx, err = open(keys, 1); err != nil {
+		t.Fatal(err)
+	}
+	exists("east")
+
+	// The log file is compacted into an index file once it exceeds its
+	// maximum size.
+	create("west")
+	idx.Wait()
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	} else if idx, err = open(keys, 1<<20); err != nil {
+		t.Fatal(err)
+	}
+	exists("east")
+	exists("west")
+
+	// The tag blocks of index files are decrypted through the cache, and
+	// evicted when the files are closed.
+	if cache.Size() == 0 {
+		t.Fatal("tag blocks not read through the cache")
+	} else if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	} else if cache.Size() != 0 {
+		t.Fatalf("chunks left in cache after close: %d", cache.Size())
+	}
+
+	// Tag values are not found in the files.
+	var indexFiles int
+	if err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		} else if bytes.Contains(buf, []byte("secret")) {
+			t.Fatalf("tag key found in %s", path)
+		}
+		if filepath.Ext(path) == tsi1.IndexFileExt {
+			indexFiles++
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	} else if indexFiles == 0 {
+		t.Fatal("log file not compacted")
+	}
+
+	if _, err := open(nil, 1<<20); err == nil || !strings.Contains(err.Error(), "no encryption keys") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestIndex_Manifest(t *testing.T) {
 	t.Run("current MANIFEST", func(t *testing.T) {
 		idx := MustOpenIndex(tsi1.DefaultPartitionN)
@@ -923,3 +1022,25 @@ func BenchmarkIndex_ConcurrentWriteQuery(b *testing.B) {
 		})
 	}
 }
+
+// mustKeyFile returns an encryption.KeyFile with new keys with the given IDs.
+func mustKeyFile(t *testing.T, ids ...string) *encryption.KeyFile {
+	t.Helper()
+	var content string
+	for _, id := range ids {
+		key := make([]byte, encryption.KeySize)
+		for i := range key {
+			key[i] = byte(len(content) + i)
+		}
+		content += id + " " + base64.StdEncoding.EncodeToString(key) + "\n"
+	}
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	kf, err := encryption.NewKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kf
+}
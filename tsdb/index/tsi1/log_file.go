@@ -16,6 +16,7 @@ import (
 
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/bloom"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/estimator"
 	"github.com/influxdata/influxdb/pkg/estimator/hll"
 	"github.com/influxdata/influxdb/pkg/mmap"
@@ -58,6 +59,13 @@ type LogFile struct {
 	buf        []byte         // marshaling buffer
 	keyBuf     []byte
 
+	// key encrypts the entries of encrypted files, which start with the
+	// header of the key followed by the entries, each encrypted and prefixed
+	// by its size. New files are encrypted if keys is set.
+	keys   encryption.KeyProvider
+	key    *encryption.FileKey
+	sealed []byte
+
 	sfile   *tsdb.SeriesFile // series lookup
 	size    int64            // tracks current file size
 	modTime time.Time        // tracks last time write occurred
@@ -134,7 +142,7 @@ func (f *LogFile) open() error {
 	if err != nil {
 		return err
 	} else if fi.Size() == 0 {
-		return nil
+		return f.encrypt()
 	}
 	f.size = fi.Size()
 	f.modTime = fi.ModTime()
@@ -148,21 +156,27 @@ func (f *LogFile) open() error {
 
 	// Read log entries from mmap.
 	var n int64
-	for buf := f.data; len(buf) > 0; {
-		// Read next entry. Truncate partial writes.
-		var e LogEntry
-		if err := e.UnmarshalBinary(buf); err == io.ErrShortBuffer || err == ErrLogEntryChecksumMismatch {
-			break
-		} else if err != nil {
+	if encryption.IsEncrypted(f.data) {
+		if n, err = f.replayEncrypted(); err != nil {
 			return err
 		}
+	} else {
+		for buf := f.data; len(buf) > 0; {
+			// Read next entry. Truncate partial writes.
+			var e LogEntry
+			if err := e.UnmarshalBinary(buf); err == io.ErrShortBuffer || err == ErrLogEntryChecksumMismatch {
+				break
+			} else if err != nil {
+				return err
+			}
 
-		// Execute entry against in-memory index.
-		f.execEntry(&e)
+			// Execute entry against in-memory index.
+			f.execEntry(&e)
 
-		// Move buffer forward.
-		n += int64(e.Size)
-		buf = buf[e.Size:]
+			// Move buffer forward.
+			n += int64(e.Size)
+			buf = buf[e.Size:]
+		}
 	}
 
 	// Move to the end of the file.
@@ -171,6 +185,63 @@ func (f *LogFile) open() error {
 	return err
 }
 
+// encrypt writes the header of a new key to an empty file if the file is to be
+// encrypted.
+func (f *LogFile) encrypt() error {
+	if f.keys == nil {
+		return nil
+	}
+
+	key, err := encryption.NewFileKey(f.keys, encryption.ModeRecords)
+	if err != nil {
+		return err
+	} else if _, err := f.w.Write(key.Header()); err != nil {
+		return err
+	}
+	f.key = key
+	f.size = int64(len(key.Header()))
+	return nil
+}
+
+// replayEncrypted executes the entries of an encrypted file against the
+// in-memory index and returns the size of the valid data. Partial writes are
+// truncated as for unencrypted files.
+func (f *LogFile) replayEncrypted() (int64, error) {
+	if f.keys == nil {
+		return 0, fmt.Errorf("%s is encrypted but no encryption keys are configured", f.path)
+	}
+
+	key, err := encryption.ReadFileKey(bytes.NewReader(f.data), f.keys, encryption.ModeRecords)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", f.path, err)
+	}
+	f.key = key
+
+	n := int64(len(key.Header()))
+	for buf := f.data[n:]; len(buf) >= 4; {
+		sz := 4 + int(binary.BigEndian.Uint32(buf))
+		if len(buf) < sz {
+			break
+		}
+		data, err := key.Open(nil, buf[4:sz])
+		if err != nil {
+			break
+		}
+
+		var e LogEntry
+		if err := e.UnmarshalBinary(data); err == io.ErrShortBuffer || err == ErrLogEntryChecksumMismatch {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+		f.execEntry(&e)
+
+		n += int64(sz)
+		buf = buf[sz:]
+	}
+	return n, nil
+}
+
 // Close shuts down the file handle and mmap.
 func (f *LogFile) Close() error {
 	// Wait until the file has no more references.
@@ -252,6 +323,15 @@ func (f *LogFile) TombstoneSeriesIDSet() (*tsdb.SeriesIDSet, error) {
 	return f.tombstoneSeriesIDSet, nil
 }
 
+// KeyID returns the ID of the encryption key of the file, or an empty string
+// if it is not encrypted.
+func (f *LogFile) KeyID() string {
+	if f.key == nil {
+		return ""
+	}
+	return f.key.KeyID()
+}
+
 // Size returns the size of the file, in bytes.
 func (f *LogFile) Size() int64 {
 	f.mu.RLock()
@@ -614,8 +694,16 @@ func (f *LogFile) appendEntry(e *LogEntry) error {
 	// Save the size of the record.
 	e.Size = len(f.buf)
 
+	// Encrypt the record and prefix it with its size.
+	data := f.buf
+	if f.key != nil {
+		f.sealed = f.key.Seal(append(f.sealed[:0], 0, 0, 0, 0), f.buf)
+		binary.BigEndian.PutUint32(f.sealed, uint32(len(f.sealed)-4))
+		data = f.sealed
+	}
+
 	// Write record to file.
-	n, err := f.w.Write(f.buf)
+	n, err := f.w.Write(data)
 	if err != nil {
 		// Move position backwards over partial entry.
 		// Log should be reopened if seeking cannot be completed.
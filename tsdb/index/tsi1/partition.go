@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -17,6 +18,7 @@ import (
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/bytesutil"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/estimator"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxql"
@@ -73,6 +75,9 @@ type Partition struct {
 	nosync         bool // when true, flushing and syncing of LogFile will be disabled.
 	logbufferSize  int  // the LogFile's buffer is set to this value.
 
+	keys   encryption.KeyProvider // If set then new log and index files are encrypted with these keys.
+	chunks *encryption.ChunkCache // Caches the decrypted chunks of encrypted index files.
+
 	// Frequency of compaction checks.
 	compactionInterrupt chan struct{}
 	compactionsDisabled int
@@ -251,6 +256,7 @@ func (p *Partition) openLogFile(path string) (*LogFile, error) {
 	f := NewLogFile(p.sfile, path)
 	f.nosync = p.nosync
 	f.bufferSize = p.logbufferSize
+	f.keys = p.keys
 
 	if err := f.Open(); err != nil {
 		return nil, err
@@ -262,12 +268,31 @@ func (p *Partition) openLogFile(path string) (*LogFile, error) {
 func (p *Partition) openIndexFile(path string) (*IndexFile, error) {
 	f := NewIndexFile(p.sfile)
 	f.SetPath(path)
+	f.keys, f.chunks = p.keys, p.chunks
 	if err := f.Open(); err != nil {
 		return nil, err
 	}
 	return f, nil
 }
 
+// createIndexFile creates an index file to compact files into, which is
+// encrypted if the partition has encryption keys.
+func (p *Partition) createIndexFile(path string) (io.WriteCloser, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	} else if p.keys == nil {
+		return f, nil
+	}
+
+	w, err := encryption.NewWriter(f, p.keys)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
 // deleteNonManifestFiles removes all files not in the manifest.
 func (p *Partition) deleteNonManifestFiles(m *Manifest) error {
 	dir, err := os.Open(p.path)
@@ -937,32 +962,64 @@ func (p *Partition) compact() {
 		// Mark the level as compacting.
 		p.levelCompacting[level] = true
 
-		// Execute in closure to save reference to the group within the loop.
-		func(files []*IndexFile, level int) {
-			// Start compacting in a separate goroutine.
-			p.currentCompactionN++
-			go func() {
+		// Compact to a new level.
+		p.startCompaction(files, level, level+1, interrupt)
+	}
+
+	// Rewrite the files not encrypted with the current key, so that rotated
+	// keys are no longer needed. The active log file is compacted, and index
+	// files are rewritten one at a time into their own level.
+	keyID := p.currentKeyID()
+	if keyID == "" {
+		return
+	} else if err := p.checkLogFile(); err != nil {
+		p.logger.Error("Cannot compact log file", zap.Error(err))
+	}
+	for _, f := range fs.IndexFiles() {
+		if level := f.Level(); !p.levelCompacting[level] && f.KeyID() != keyID {
+			f.Retain()
+			p.levelCompacting[level] = true
+			p.startCompaction([]*IndexFile{f}, level, level, interrupt)
+		}
+	}
+}
+
+// startCompaction compacts files of a level into a new file of dstLevel in a
+// separate goroutine. The files must already be retained and the level marked
+// as compacting.
+func (p *Partition) startCompaction(files []*IndexFile, level, dstLevel int, interrupt <-chan struct{}) {
+	p.currentCompactionN++
+	go func() {
+		p.compactToLevel(files, dstLevel, interrupt)
 
-				// Compact to a new level.
-				p.compactToLevel(files, level+1, interrupt)
+		// Ensure compaction lock for the level is released.
+		p.mu.Lock()
+		p.levelCompacting[level] = false
+		p.currentCompactionN--
+		p.mu.Unlock()
 
-				// Ensure compaction lock for the level is released.
-				p.mu.Lock()
-				p.levelCompacting[level] = false
-				p.currentCompactionN--
-				p.mu.Unlock()
+		// Check for new compactions
+		p.Compact()
+	}()
+}
 
-				// Check for new compactions
-				p.Compact()
-			}()
-		}(files, level)
+// currentKeyID returns the ID of the encryption key of new files, or an empty
+// string if they are not encrypted.
+func (p *Partition) currentKeyID() string {
+	if p.keys == nil {
+		return ""
+	}
+	id, _, err := p.keys.CurrentKey()
+	if err != nil {
+		return ""
 	}
+	return id
 }
 
 // compactToLevel compacts a set of files into a new file. Replaces old files with
 // compacted file on successful completion. This runs in a separate goroutine.
 func (p *Partition) compactToLevel(files []*IndexFile, level int, interrupt <-chan struct{}) {
-	assert(len(files) >= 2, "at least two index files are required for compaction")
+	assert(len(files) >= 1, "at least one index file is required for compaction")
 	assert(level > 0, "cannot compact level zero")
 
 	// Build a logger for this compaction.
@@ -987,7 +1044,7 @@ func (p *Partition) compactToLevel(files []*IndexFile, level int, interrupt <-ch
 
 	// Create new index file.
 	path := filepath.Join(p.path, FormatIndexFileName(p.NextSequence(), level))
-	f, err := os.Create(path)
+	f, err := p.createIndexFile(path)
 	if err != nil {
 		log.Error("Cannot create compaction files", zap.Error(err))
 		return
@@ -1014,9 +1071,8 @@ func (p *Partition) compactToLevel(files []*IndexFile, level int, interrupt <-ch
 	}
 
 	// Reopen as an index file.
-	file := NewIndexFile(p.sfile)
-	file.SetPath(path)
-	if err := file.Open(); err != nil {
+	file, err := p.openIndexFile(path)
+	if err != nil {
 		log.Error("Cannot open new index file", zap.Error(err))
 		return
 	}
@@ -1086,7 +1142,7 @@ func (p *Partition) CheckLogFile() error {
 }
 
 func (p *Partition) checkLogFile() error {
-	if p.activeLogFile.Size() < p.MaxLogFileSize {
+	if p.activeLogFile.Size() < p.MaxLogFileSize && p.activeLogFile.KeyID() == p.currentKeyID() {
 		return nil
 	}
 
@@ -1137,7 +1193,7 @@ func (p *Partition) compactLogFile(logFile *LogFile) {
 
 	// Create new index file.
 	path := filepath.Join(p.path, FormatIndexFileName(id, 1))
-	f, err := os.Create(path)
+	f, err := p.createIndexFile(path)
 	if err != nil {
 		log.Error("Cannot create index file", zap.Error(err))
 		return
@@ -1159,10 +1215,9 @@ func (p *Partition) compactLogFile(logFile *LogFile) {
 	}
 
 	// Reopen as an index file.
-	file := NewIndexFile(p.sfile)
-	file.SetPath(path)
-	if err := file.Open(); err != nil {
-		log.Error("Cannot open compacted index file", zap.Error(err), zap.String("path", file.Path()))
+	file, err := p.openIndexFile(path)
+	if err != nil {
+		log.Error("Cannot open compacted index file", zap.Error(err), zap.String("path", path))
 		return
 	}
 
@@ -14,6 +14,7 @@ import (
 	"github.com/cespare/xxhash"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/binaryutil"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
@@ -30,6 +31,10 @@ const SeriesIDSize = 8
 const (
 	// SeriesFilePartitionN is the number of partitions a series file is split into.
 	SeriesFilePartitionN = 8
+
+	// defaultChunkCacheSize is the size of the cache of decrypted records of
+	// encrypted segments when no cache is set.
+	defaultChunkCacheSize = 16 * encryption.ChunkSize
 )
 
 // SeriesFile represents the section of the index that holds series data.
@@ -39,6 +44,9 @@ type SeriesFile struct {
 
 	maxSnapshotConcurrency int
 
+	keys   encryption.KeyProvider // If set then new segments are encrypted with these keys.
+	chunks *encryption.ChunkCache // Caches the decrypted records of encrypted segments.
+
 	refs sync.RWMutex // RWMutex to track references to the SeriesFile that are in use.
 
 	Logger *zap.Logger
@@ -69,6 +77,18 @@ func (f *SeriesFile) WithMaxCompactionConcurrency(maxCompactionConcurrency int)
 	f.maxSnapshotConcurrency = maxCompactionConcurrency
 }
 
+// WithKeyProvider sets the keys encrypting new segments and decrypting
+// encrypted segments.
+func (f *SeriesFile) WithKeyProvider(keys encryption.KeyProvider) {
+	f.keys = keys
+}
+
+// WithChunkCache sets the cache of the decrypted records of encrypted
+// segments.
+func (f *SeriesFile) WithChunkCache(chunks *encryption.ChunkCache) {
+	f.chunks = chunks
+}
+
 // Open memory maps the data file at the file's path.
 func (f *SeriesFile) Open() error {
 	// Wait for all references to be released and prevent new ones from being acquired.
@@ -80,6 +100,10 @@ func (f *SeriesFile) Open() error {
 		return err
 	}
 
+	if f.keys != nil && f.chunks == nil {
+		f.chunks = encryption.NewChunkCache(defaultChunkCacheSize)
+	}
+
 	// Limit concurrent series file compactions
 	compactionLimiter := limiter.NewFixed(f.maxSnapshotConcurrency)
 
@@ -88,6 +112,7 @@ func (f *SeriesFile) Open() error {
 	for i := 0; i < SeriesFilePartitionN; i++ {
 		p := NewSeriesPartition(i, f.SeriesPartitionPath(i), compactionLimiter)
 		p.Logger = f.Logger.With(zap.Int("partition", p.ID()))
+		p.keys, p.chunks = f.keys, f.chunks
 		if err := p.Open(); err != nil {
 			f.Logger.Error("Unable to open series file",
 				zap.String("path", f.path),
@@ -2,14 +2,18 @@ package tsdb_test
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/tsdb"
 	"golang.org/x/sync/errgroup"
 )
@@ -98,6 +102,95 @@ func TestSeriesFile_Series(t *testing.T) {
 	}
 }
 
+func TestSeriesFile_Encrypted(t *testing.T) {
+	keys := mustKeyFile(t, "k1")
+	sfile := NewSeriesFile()
+	sfile.WithKeyProvider(keys)
+	if err := sfile.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer sfile.Close()
+
+	series := []Series{
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"secret": "east"})},
+		{Name: []byte("mem"), Tags: models.NewTags(map[string]string{"secret": "west"})},
+	}
+	if _, err := sfile.CreateSeriesListIfNotExists([][]byte{series[0].Name}, []models.Tags{series[0].Tags}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Series keys are written to the segments on reopening.
+	open := func(keys encryption.KeyProvider) error {
+		sfile.SeriesFile = tsdb.NewSeriesFile(sfile.SeriesFile.Path())
+		sfile.WithKeyProvider(keys)
+		return sfile.SeriesFile.Open()
+	}
+	reopen := func(keys encryption.KeyProvider) error {
+		if err := sfile.SeriesFile.Close(); err != nil {
+			return err
+		}
+		return open(keys)
+	}
+	if err := sfile.SeriesFile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Partially written records are truncated and overwritten by new records.
+	if err := filepath.Walk(sfile.Path(), func(path string, info os.FileInfo, err error) error {
+		if err != nil || !tsdb.IsValidSeriesSegmentFilename(info.Name()) {
+			return err
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0666)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = f.Write([]byte{0, 0, 0, 40, 1, 2, 3})
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := open(keys); err != nil {
+		t.Fatal(err)
+	} else if _, err := sfile.CreateSeriesListIfNotExists([][]byte{series[1].Name}, []models.Tags{series[1].Tags}); err != nil {
+		t.Fatal(err)
+	} else if err := sfile.ForceCompact(); err != nil {
+		t.Fatal(err)
+	} else if err := reopen(keys); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, s := range series {
+		id := sfile.SeriesID(s.Name, s.Tags, nil)
+		if id == 0 {
+			t.Fatalf("series does not exist: i=%d", i)
+		} else if name, tags := sfile.Series(id); !bytes.Equal(name, s.Name) || !tags.Equal(s.Tags) {
+			t.Fatalf("unexpected series: i=%d, name=%s, tags=%s", i, name, tags)
+		}
+	}
+
+	// Series keys are not found in the files.
+	if err := filepath.Walk(sfile.Path(), func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		} else if bytes.Contains(buf, []byte("secret")) {
+			t.Fatalf("series key found in %s", path)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reopen(nil); err == nil || !strings.Contains(err.Error(), "no encryption keys") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // Ensure series file can be compacted.
 func TestSeriesFileCompactor(t *testing.T) {
 	sfile := MustOpenSeriesFile()
@@ -375,3 +468,25 @@ func (f *SeriesFile) ForceCompact() error {
 	}
 	return nil
 }
+
+// mustKeyFile returns an encryption.KeyFile with new keys with the given IDs.
+func mustKeyFile(t *testing.T, ids ...string) *encryption.KeyFile {
+	t.Helper()
+	var content string
+	for _, id := range ids {
+		key := make([]byte, encryption.KeySize)
+		for i := range key {
+			key[i] = byte(len(content) + i)
+		}
+		content += id + " " + base64.StdEncoding.EncodeToString(key) + "\n"
+	}
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	kf, err := encryption.NewKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kf
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/rhh"
 	"go.uber.org/zap"
@@ -40,6 +41,9 @@ type SeriesPartition struct {
 	index    *SeriesIndex
 	seq      uint64 // series id sequence
 
+	keys   encryption.KeyProvider // If set then new segments are encrypted with these keys.
+	chunks *encryption.ChunkCache // Caches the decrypted records of encrypted segments.
+
 	compacting          bool
 	compactionLimiter   limiter.Fixed
 	compactionsDisabled int
@@ -113,9 +117,18 @@ func (p *SeriesPartition) openSegments() error {
 		}
 
 		segment := NewSeriesSegment(segmentID, filepath.Join(p.path, fi.Name()))
+		segment.keys, segment.chunks = p.keys, p.chunks
 		if err := segment.Open(); err != nil {
 			return err
 		}
+
+		// Rewrite segments not encrypted with the current key, so that
+		// rotated keys are no longer needed.
+		if keyID := p.currentKeyID(); keyID != "" && segment.KeyID() != keyID {
+			if segment, err = p.rotateSegment(segment); err != nil {
+				return err
+			}
+		}
 		p.segments = append(p.segments, segment)
 	}
 
@@ -130,7 +143,7 @@ func (p *SeriesPartition) openSegments() error {
 
 	// Create initial segment if none exist.
 	if len(p.segments) == 0 {
-		segment, err := CreateSeriesSegment(0, filepath.Join(p.path, "0000"))
+		segment, err := createSeriesSegment(0, filepath.Join(p.path, "0000"), p.keys, p.chunks)
 		if err != nil {
 			return err
 		}
@@ -140,6 +153,41 @@ func (p *SeriesPartition) openSegments() error {
 	return nil
 }
 
+// rotateSegment rewrites segment with the current encryption key, replaces it
+// and returns the new segment.
+func (p *SeriesPartition) rotateSegment(segment *SeriesSegment) (*SeriesSegment, error) {
+	path := segment.Path()
+	if err := segment.RewriteToPath(path + ".rotating"); err != nil {
+		return nil, err
+	} else if err := segment.Close(); err != nil {
+		return nil, err
+	} else if err := os.Rename(path+".rotating", path); err != nil {
+		return nil, err
+	}
+
+	p.Logger.Info("Rewrote series segment with current encryption key", zap.String("path", path))
+
+	segment = NewSeriesSegment(segment.ID(), path)
+	segment.keys, segment.chunks = p.keys, p.chunks
+	if err := segment.Open(); err != nil {
+		return nil, err
+	}
+	return segment, nil
+}
+
+// currentKeyID returns the ID of the encryption key of new segments, or an
+// empty string if they are not encrypted.
+func (p *SeriesPartition) currentKeyID() string {
+	if p.keys == nil {
+		return ""
+	}
+	id, _, err := p.keys.CurrentKey()
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
 // Close unmaps the data files.
 func (p *SeriesPartition) Close() (err error) {
 	p.once.Do(func() { close(p.closing) })
@@ -482,7 +530,7 @@ func (p *SeriesPartition) createSegment() (*SeriesSegment, error) {
 	filename := fmt.Sprintf("%04x", id)
 
 	// Generate new empty segment.
-	segment, err := CreateSeriesSegment(id, filepath.Join(p.path, filename))
+	segment, err := createSeriesSegment(id, filepath.Join(p.path, filename), p.keys, p.chunks)
 	if err != nil {
 		return nil, err
 	}
@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strconv"
 
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/mmap"
 )
 
@@ -45,6 +46,15 @@ type SeriesSegment struct {
 	file *os.File      // write file handle
 	w    *bufio.Writer // bufferred file handle
 	size uint32        // current file size
+
+	// Encrypted segments are read and appended to through enc instead of
+	// being mapped, and offsets within the segment are offsets within the
+	// decrypted data. Their records are decrypted on demand and cached in
+	// chunks.
+	keys   encryption.KeyProvider
+	chunks *encryption.ChunkCache
+	enc    *encryption.AppendFile
+	rfile  *os.File // read handle of enc
 }
 
 // NewSeriesSegment returns a new instance of SeriesSegment.
@@ -57,6 +67,12 @@ func NewSeriesSegment(id uint16, path string) *SeriesSegment {
 
 // CreateSeriesSegment generates an empty segment at path.
 func CreateSeriesSegment(id uint16, path string) (*SeriesSegment, error) {
+	return createSeriesSegment(id, path, nil, nil)
+}
+
+// createSeriesSegment generates an empty segment at path, encrypted with a new
+// key if keys is set.
+func createSeriesSegment(id uint16, path string, keys encryption.KeyProvider, chunks *encryption.ChunkCache) (*SeriesSegment, error) {
 	// Generate segment in temp location.
 	f, err := os.Create(path + ".initializing")
 	if err != nil {
@@ -64,11 +80,9 @@ func CreateSeriesSegment(id uint16, path string) (*SeriesSegment, error) {
 	}
 	defer f.Close()
 
-	// Write header to file and close.
-	hdr := NewSeriesSegmentHeader()
-	if _, err := hdr.WriteTo(f); err != nil {
-		return nil, err
-	} else if err := f.Truncate(int64(SeriesSegmentSize(id))); err != nil {
+	// Write header to file and close. Encrypted segments hold the header in
+	// their first record and are not preallocated.
+	if err := writeSeriesSegmentHeader(f, id, keys); err != nil {
 		return nil, err
 	} else if err := f.Sync(); err != nil {
 		return nil, err
@@ -83,22 +97,45 @@ func CreateSeriesSegment(id uint16, path string) (*SeriesSegment, error) {
 
 	// Open segment at new location.
 	segment := NewSeriesSegment(id, path)
+	segment.keys, segment.chunks = keys, chunks
 	if err := segment.Open(); err != nil {
 		return nil, err
 	}
 	return segment, nil
 }
 
+// writeSeriesSegmentHeader writes the header of a new segment to f, encrypted
+// with a new key if keys is set.
+func writeSeriesSegmentHeader(f *os.File, id uint16, keys encryption.KeyProvider) error {
+	var buf bytes.Buffer
+	hdr := NewSeriesSegmentHeader()
+	if _, err := hdr.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	if keys != nil {
+		return encryption.WriteAppendFile(f, keys, buf.Bytes())
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return f.Truncate(int64(SeriesSegmentSize(id)))
+}
+
 // Open memory maps the data file at the file's path.
 func (s *SeriesSegment) Open() error {
 	if err := func() (err error) {
-		// Memory map file data.
-		if s.data, err = mmap.Map(s.path, int64(SeriesSegmentSize(s.id))); err != nil {
+		if err := s.openEncrypted(); err != nil {
 			return err
+		} else if s.enc == nil {
+			// Memory map file data.
+			if s.data, err = mmap.Map(s.path, int64(SeriesSegmentSize(s.id))); err != nil {
+				return err
+			}
 		}
 
 		// Read header.
-		hdr, err := ReadSeriesSegmentHeader(s.data)
+		hdr, err := ReadSeriesSegmentHeader(s.Slice(0))
 		if err != nil {
 			return err
 		} else if hdr.Version != SeriesSegmentVersion {
@@ -114,12 +151,51 @@ func (s *SeriesSegment) Open() error {
 	return nil
 }
 
+// openEncrypted opens the segment for reading through enc if it is encrypted,
+// since encrypted data cannot be mapped.
+func (s *SeriesSegment) openEncrypted() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+
+	var magic [4]byte
+	if _, err := f.ReadAt(magic[:], 0); err != nil && err != io.EOF {
+		f.Close()
+		return err
+	} else if !encryption.IsEncrypted(magic[:]) {
+		return f.Close()
+	} else if s.keys == nil {
+		f.Close()
+		return fmt.Errorf("%s is encrypted but no encryption keys are configured", s.path)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if s.chunks == nil {
+		s.chunks = encryption.NewChunkCache(defaultChunkCacheSize)
+	}
+	if s.enc, err = encryption.OpenAppendFile(f, fi.Size(), s.keys, s.chunks); err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %w", s.path, err)
+	}
+	s.rfile = f
+	return nil
+}
+
 // Path returns the file path to the segment.
 func (s *SeriesSegment) Path() string { return s.path }
 
 // InitForWrite initializes a write handle for the segment.
 // This is only used for the last segment in the series file.
 func (s *SeriesSegment) InitForWrite() (err error) {
+	if s.enc != nil {
+		return s.initForWriteEncrypted()
+	}
+
 	// Only calculcate segment data size if writing.
 	for s.size = uint32(SeriesSegmentHeaderSize); s.size < uint32(len(s.data)); {
 		flag, _, _, sz := ReadSeriesEntry(s.data[s.size:])
@@ -140,6 +216,25 @@ func (s *SeriesSegment) InitForWrite() (err error) {
 	return nil
 }
 
+// initForWriteEncrypted initializes the write handle of an encrypted segment.
+// Partial writes are truncated, since they are not overwritten with zeros.
+func (s *SeriesSegment) initForWriteEncrypted() (err error) {
+	s.size = uint32(s.enc.Size())
+
+	end := s.enc.End()
+	if s.file, err = os.OpenFile(s.path, os.O_WRONLY, 0666); err != nil {
+		return err
+	} else if err := s.file.Truncate(end); err != nil {
+		return err
+	} else if _, err := s.file.Seek(end, io.SeekStart); err != nil {
+		return err
+	}
+	s.w = bufio.NewWriterSize(s.file, 32*1024)
+	s.enc.SetWriter(s.w)
+
+	return nil
+}
+
 // Close unmaps the segment.
 func (s *SeriesSegment) Close() (err error) {
 	if e := s.CloseForWrite(); e != nil && err == nil {
@@ -153,10 +248,27 @@ func (s *SeriesSegment) Close() (err error) {
 		s.data = nil
 	}
 
+	if s.enc != nil {
+		if e := s.enc.Close(); e != nil && err == nil {
+			err = e
+		}
+		if e := s.rfile.Close(); e != nil && err == nil {
+			err = e
+		}
+		s.enc, s.rfile = nil, nil
+	}
+
 	return err
 }
 
 func (s *SeriesSegment) CloseForWrite() (err error) {
+	if s.enc != nil && s.w != nil {
+		if e := s.enc.Flush(); e != nil && err == nil {
+			err = e
+		}
+		s.enc.SetWriter(nil)
+	}
+
 	if s.w != nil {
 		if e := s.w.Flush(); e != nil && err == nil {
 			err = e
@@ -173,18 +285,49 @@ func (s *SeriesSegment) CloseForWrite() (err error) {
 	return err
 }
 
-// Data returns the raw data.
+// Data returns the raw data. It returns nil for encrypted segments, which are
+// not mapped.
 func (s *SeriesSegment) Data() []byte { return s.data }
 
 // ID returns the id the segment was initialized with.
 func (s *SeriesSegment) ID() uint16 { return s.id }
 
+// KeyID returns the ID of the encryption key of the segment, or an empty
+// string if it is not encrypted.
+func (s *SeriesSegment) KeyID() string {
+	if s.enc == nil {
+		return ""
+	}
+	return s.enc.KeyID()
+}
+
 // Size returns the size of the data in the segment.
 // This is only populated once InitForWrite() is called.
 func (s *SeriesSegment) Size() int64 { return int64(s.size) }
 
-// Slice returns a byte slice starting at pos.
-func (s *SeriesSegment) Slice(pos uint32) []byte { return s.data[pos:] }
+// Slice returns a byte slice starting at pos. For encrypted segments, the
+// slice ends at the end of the decrypted record holding pos, which holds the
+// whole entry, and is nil if the record cannot be decrypted.
+func (s *SeriesSegment) Slice(pos uint32) []byte {
+	b, _ := s.slice(pos)
+	return b
+}
+
+func (s *SeriesSegment) slice(pos uint32) ([]byte, error) {
+	if s.enc != nil {
+		return s.enc.Slice(int64(pos))
+	}
+	return s.data[pos:], nil
+}
+
+// dataSize returns the size of the data, including the preallocated space of
+// unencrypted segments.
+func (s *SeriesSegment) dataSize() uint32 {
+	if s.enc != nil {
+		return uint32(s.enc.Size())
+	}
+	return uint32(len(s.data))
+}
 
 // WriteLogEntry writes entry data into the segment.
 // Returns the offset of the beginning of the entry.
@@ -194,7 +337,11 @@ func (s *SeriesSegment) WriteLogEntry(data []byte) (offset int64, err error) {
 	}
 
 	offset = JoinSeriesOffset(s.id, s.size)
-	if _, err := s.w.Write(data); err != nil {
+	if s.enc != nil {
+		if _, err := s.enc.Write(data); err != nil {
+			return 0, err
+		}
+	} else if _, err := s.w.Write(data); err != nil {
 		return 0, err
 	}
 	s.size += uint32(len(data))
@@ -211,6 +358,10 @@ func (s *SeriesSegment) CanWrite(data []byte) bool {
 func (s *SeriesSegment) Flush() error {
 	if s.w == nil {
 		return nil
+	} else if s.enc != nil {
+		if err := s.enc.Flush(); err != nil {
+			return err
+		}
 	}
 	return s.w.Flush()
 }
@@ -240,8 +391,12 @@ func (s *SeriesSegment) MaxSeriesID() uint64 {
 
 // ForEachEntry executes fn for every entry in the segment.
 func (s *SeriesSegment) ForEachEntry(fn func(flag uint8, id uint64, offset int64, key []byte) error) error {
-	for pos := uint32(SeriesSegmentHeaderSize); pos < uint32(len(s.data)); {
-		flag, id, key, sz := ReadSeriesEntry(s.data[pos:])
+	for pos, n := uint32(SeriesSegmentHeaderSize), s.dataSize(); pos < n; {
+		data, err := s.slice(pos)
+		if err != nil {
+			return err
+		}
+		flag, id, key, sz := ReadSeriesEntry(data)
 		if !IsValidSeriesEntryFlag(flag) {
 			break
 		}
@@ -258,16 +413,20 @@ func (s *SeriesSegment) ForEachEntry(fn func(flag uint8, id uint64, offset int64
 // Clone returns a copy of the segment. Excludes the write handler, if set.
 func (s *SeriesSegment) Clone() *SeriesSegment {
 	return &SeriesSegment{
-		id:   s.id,
-		path: s.path,
-		data: s.data,
-		size: s.size,
+		id:     s.id,
+		path:   s.path,
+		data:   s.data,
+		size:   s.size,
+		keys:   s.keys,
+		chunks: s.chunks,
+		enc:    s.enc,
+		rfile:  s.rfile,
 	}
 }
 
 // CompactToPath rewrites the segment to a new file and removes tombstoned entries.
 func (s *SeriesSegment) CompactToPath(path string, index *SeriesIndex) error {
-	dst, err := CreateSeriesSegment(s.id, path)
+	dst, err := createSeriesSegment(s.id, path, s.keys, s.chunks)
 	if err != nil {
 		return err
 	}
@@ -297,16 +456,44 @@ func (s *SeriesSegment) CompactToPath(path string, index *SeriesIndex) error {
 		return err
 	}
 
-	// Close the segment and truncate it to its maximum size.
-	size := dst.size
+	// Close the segment and truncate it to its maximum size. Encrypted
+	// segments are not preallocated.
+	size, encrypted := dst.size, dst.enc != nil
 	if err := dst.Close(); err != nil {
 		return err
+	} else if encrypted {
+		return nil
 	} else if err := os.Truncate(dst.path, int64(size)); err != nil {
 		return err
 	}
 	return nil
 }
 
+// RewriteToPath rewrites every entry of the segment to a new file, encrypted
+// with the current key of the segment's keys. Unlike CompactToPath, tombstoned
+// entries are kept so the offsets of the entries are unchanged.
+func (s *SeriesSegment) RewriteToPath(path string) error {
+	dst, err := createSeriesSegment(s.id, path, s.keys, s.chunks)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	if err = dst.InitForWrite(); err != nil {
+		return err
+	}
+
+	var buf []byte
+	if err = s.ForEachEntry(func(flag uint8, id uint64, _ int64, key []byte) error {
+		buf = AppendSeriesEntry(buf[:0], flag, id, key)
+		_, err := dst.WriteLogEntry(buf)
+		return err
+	}); err != nil {
+		return err
+	}
+	return dst.Close()
+}
+
 // CloneSeriesSegments returns a copy of a slice of segments.
 func CloneSeriesSegments(a []*SeriesSegment) []*SeriesSegment {
 	other := make([]*SeriesSegment, len(a))
@@ -20,6 +20,7 @@ import (
 	"unsafe"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/bytesutil"
 	"github.com/influxdata/influxdb/pkg/estimator"
@@ -53,6 +54,13 @@ var (
 	// ErrFieldTypeConflict is returned when a new field already exists with a different type.
 	ErrFieldTypeConflict = errors.New("field type conflict")
 
+	// ErrSchemaViolation is returned when a point does not match the schema of its database.
+	ErrSchemaViolation = errors.New("schema violation")
+
+	// ErrSeriesQuotaExceeded is returned when the points of new series are
+	// dropped because the database reached its max-series quota.
+	ErrSeriesQuotaExceeded = errors.New("max-series quota exceeded")
+
 	// ErrFieldNotFound is returned when a field cannot be found.
 	ErrFieldNotFound = errors.New("field not found")
 
@@ -114,6 +122,9 @@ type PartialWriteError struct {
 	Reason  string
 	Dropped int
 
+	// Quota is the name of the database quota that dropped points, if any.
+	Quota string
+
 	// A sorted slice of series keys that were dropped.
 	DroppedKeys [][]byte
 }
@@ -506,6 +517,11 @@ type ConetextKey int
 const (
 	StatPointsWritten = ConetextKey(iota)
 	StatValuesWritten
+
+	// SystemWrite marks, with a true value, the points written by the server
+	// itself, such as the audit records. They bypass the schema and the
+	// series quota of the database.
+	SystemWrite
 )
 
 // WritePointsWithContext() will write the raw data points and any new metadata
@@ -528,7 +544,8 @@ func (s *Shard) WritePointsWithContext(ctx context.Context, points []models.Poin
 	var writeError error
 	atomic.AddInt64(&s.stats.WriteReq, 1)
 
-	points, fieldsToCreate, err := s.validateSeriesAndFields(points)
+	system, _ := ctx.Value(SystemWrite).(bool)
+	points, fieldsToCreate, err := s.validateSeriesAndFields(points, system)
 	if err != nil {
 		if _, ok := err.(PartialWriteError); !ok {
 			return err
@@ -587,12 +604,14 @@ func (s *Shard) WritePointsWithContext(ctx context.Context, points []models.Poin
 }
 
 // validateSeriesAndFields checks which series and fields are new and whose metadata should be saved and indexed.
-func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point, []*FieldCreate, error) {
+// The points written by the server itself are not checked against the schema and the series quota.
+func (s *Shard) validateSeriesAndFields(points []models.Point, system bool) ([]models.Point, []*FieldCreate, error) {
 	var (
 		fieldsToCreate []*FieldCreate
 		err            error
 		dropped        int
 		reason         string // only first error reason is set unless returned from CreateSeriesListIfNotExists
+		quota          string
 	)
 
 	// Create all series against the index in bulk.
@@ -603,10 +622,38 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 	// Check if keys should be unicode validated.
 	validateKeys := s.options.Config.ValidateKeys
 
+	// Check if points should be validated against the schema of the database.
+	var schema Schema
+	if s.options.SchemaFn != nil && !system {
+		schema = s.options.SchemaFn(s.database)
+	}
+	var warned int
+	var warning string
+
 	var j int
 	for i, p := range points {
 		tags := p.Tags()
 
+		// Drop or warn about points that do not match the schema. Points are
+		// checked before their series are created so that dropped points do
+		// not create series.
+		if schema != nil {
+			if r := schema.ValidatePoint(p); r != "" {
+				if schema.Strict() {
+					dropped++
+					atomic.AddInt64(&s.stats.WritePointsDropped, 1)
+					if reason == "" {
+						reason = fmt.Sprintf("%s: %s", ErrSchemaViolation, r)
+					}
+					continue
+				}
+				if warned == 0 {
+					warning = r
+				}
+				warned++
+			}
+		}
+
 		// Drop any series w/ a "time" tag, these are illegal
 		if v := tags.Get(timeBytes); v != nil {
 			dropped++
@@ -635,6 +682,27 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 	}
 	points, keys, names, tagsSlice = points[:j], keys[:j], names[:j], tagsSlice[:j]
 
+	if warned > 0 {
+		s.logger.Warn("Points do not match the schema of the database",
+			logger.Database(s.database), zap.String("reason", warning), zap.Int("points", warned))
+	}
+
+	// Drop the points of new series once the database reached its series quota.
+	if s.options.MaxSeriesFn != nil && !system {
+		if max := s.options.MaxSeriesFn(s.database); max > 0 {
+			var n int
+			points, keys, names, tagsSlice, n = s.dropNewSeries(max, points, keys, names, tagsSlice)
+			if n > 0 {
+				dropped += n
+				atomic.AddInt64(&s.stats.WritePointsDropped, int64(n))
+				if reason == "" {
+					reason = fmt.Sprintf("%s: (%d)", ErrSeriesQuotaExceeded, max)
+				}
+				quota = "max-series"
+			}
+		}
+	}
+
 	engine, err := s.engineNoLock()
 	if err != nil {
 		return nil, nil, err
@@ -735,12 +803,37 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 	}
 
 	if dropped > 0 {
-		err = PartialWriteError{Reason: reason, Dropped: dropped}
+		err = PartialWriteError{Reason: reason, Dropped: dropped, Quota: quota}
 	}
 
 	return points[:j], fieldsToCreate, err
 }
 
+// dropNewSeries drops the points of the series that do not exist yet once the
+// series file of the database holds max series, and returns the remaining
+// points and the number of dropped points. Concurrent writes to other shards
+// of the database may exceed max by the series they create.
+func (s *Shard) dropNewSeries(max int64, points []models.Point, keys, names [][]byte, tagsSlice []models.Tags) ([]models.Point, [][]byte, [][]byte, []models.Tags, int) {
+	n := int64(s.sfile.SeriesCount())
+	created := make(map[string]struct{})
+	var buf []byte
+
+	var j int
+	for i := range points {
+		if s.sfile.SeriesID(names[i], tagsSlice[i], buf) == 0 {
+			if _, ok := created[string(keys[i])]; !ok {
+				if n+int64(len(created)) >= max {
+					continue
+				}
+				created[string(keys[i])] = struct{}{}
+			}
+		}
+		points[j], keys[j], names[j], tagsSlice[j] = points[i], keys[i], names[i], tagsSlice[i]
+		j++
+	}
+	return points[:j], keys[:j], names[:j], tagsSlice[:j], len(points) - j
+}
+
 func (s *Shard) createFieldsAndMeasurements(fieldsToCreate []*FieldCreate) error {
 	if len(fieldsToCreate) == 0 {
 		return nil
@@ -848,14 +941,15 @@ func (s *Shard) MeasurementTagKeyValuesByExpr(auth query.FineAuthorizer, name []
 	return indexSet.MeasurementTagKeyValuesByExpr(auth, name, key, expr, keysSorted)
 }
 
-// MeasurementNamesByPredicate returns fields for a measurement filtered by an expression.
-func (s *Shard) MeasurementNamesByPredicate(expr influxql.Expr) ([][]byte, error) {
+// MeasurementNamesByPredicate returns the measurements matching an expression
+// that have at least one series auth may read.
+func (s *Shard) MeasurementNamesByPredicate(auth query.FineAuthorizer, expr influxql.Expr) ([][]byte, error) {
 	index, err := s.Index()
 	if err != nil {
 		return nil, err
 	}
 	indexSet := IndexSet{Indexes: []Index{index}, SeriesFile: s.sfile}
-	return indexSet.MeasurementNamesByPredicate(query.OpenAuthorizer, expr)
+	return indexSet.MeasurementNamesByPredicate(auth, expr)
 }
 
 // MeasurementFields returns fields for a measurement.
@@ -1127,6 +1221,16 @@ func (s *Shard) Export(w io.Writer, basePath string, start time.Time, end time.T
 	return engine.Export(w, basePath, start, end)
 }
 
+// ExportRange writes the data of the shard between min and max to w, trimmed
+// to the time range, as an archive that can be imported with Import.
+func (s *Shard) ExportRange(w io.Writer, basePath string, min, max int64) error {
+	engine, err := s.Engine()
+	if err != nil {
+		return err
+	}
+	return engine.ExportRange(w, basePath, min, max)
+}
+
 // Restore restores data to the underlying engine for the shard.
 // The shard is reopened after restore.
 func (s *Shard) Restore(r io.Reader, basePath string) error {
@@ -1314,15 +1418,16 @@ func (a Shards) FieldKeysByMeasurement(name []byte) []string {
 	return slices.MergeSortedStrings(all...)
 }
 
-// MeasurementNamesByPredicate returns the measurements that match the given predicate.
-func (a Shards) MeasurementNamesByPredicate(expr influxql.Expr) ([][]byte, error) {
+// MeasurementNamesByPredicate returns the measurements that match the given
+// predicate and that auth may read.
+func (a Shards) MeasurementNamesByPredicate(auth query.FineAuthorizer, expr influxql.Expr) ([][]byte, error) {
 	if len(a) == 1 {
-		return a[0].MeasurementNamesByPredicate(expr)
+		return a[0].MeasurementNamesByPredicate(auth, expr)
 	}
 
 	all := make([][][]byte, len(a))
 	for i, shard := range a {
-		names, err := shard.MeasurementNamesByPredicate(expr)
+		names, err := shard.MeasurementNamesByPredicate(auth, expr)
 		if err != nil {
 			return nil, err
 		}
@@ -1333,8 +1438,8 @@ func (a Shards) MeasurementNamesByPredicate(expr influxql.Expr) ([][]byte, error
 
 // FieldKeysByPredicate returns the field keys for series that match
 // the given predicate.
-func (a Shards) FieldKeysByPredicate(expr influxql.Expr) (map[string][]string, error) {
-	names, err := a.MeasurementNamesByPredicate(expr)
+func (a Shards) FieldKeysByPredicate(auth query.FineAuthorizer, expr influxql.Expr) (map[string][]string, error) {
+	names, err := a.MeasurementNamesByPredicate(auth, expr)
 	if err != nil {
 		return nil, err
 	}
@@ -1413,7 +1518,7 @@ func (a Shards) CreateIterator(ctx context.Context, measurement *influxql.Measur
 			stats := itr.Stats()
 			if stats.SeriesN > opt.MaxSeriesN {
 				query.Iterators(itrs).Close()
-				return nil, fmt.Errorf("max-select-series limit exceeded: (%d/%d)", stats.SeriesN, opt.MaxSeriesN)
+				return nil, query.ErrMaxSelectSeriesLimitExceeded(stats.SeriesN, opt.MaxSeriesN)
 			}
 		}
 	}
@@ -1950,11 +2055,10 @@ func NewFieldKeysIterator(sh *Shard, opt query.IteratorOptions) (query.Iterator,
 		return nil, err
 	}
 
-	// Retrieve measurements from shard. Filter if condition specified.
-	//
-	// FGA is currently not supported when retrieving field keys.
+	// Retrieve measurements from shard. Filter if condition specified, and
+	// skip measurements without a series the user may read.
 	indexSet := IndexSet{Indexes: []Index{index}, SeriesFile: sh.sfile}
-	names, err := indexSet.MeasurementNamesByExpr(query.OpenAuthorizer, opt.Condition)
+	names, err := indexSet.MeasurementNamesByExpr(opt.Authorizer, opt.Condition)
 	if err != nil {
 		return nil, err
 	}
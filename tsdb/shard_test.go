@@ -316,6 +316,124 @@ func TestWriteTimeTag(t *testing.T) {
 	}
 }
 
+// testSchema only allows the cpu measurement with a host tag.
+type testSchema struct{ strict bool }
+
+func (s testSchema) ValidatePoint(p models.Point) string {
+	if string(p.Name()) != "cpu" {
+		return fmt.Sprintf("measurement \"%s\" is not allowed", p.Name())
+	} else if p.Tags().Get([]byte("host")) == nil {
+		return "missing required tag \"host\""
+	}
+	return ""
+}
+
+func (s testSchema) Strict() bool { return s.strict }
+
+func TestShard_WritePoints_Schema(t *testing.T) {
+	for _, strict := range []bool{true, false} {
+		t.Run(fmt.Sprintf("strict=%v", strict), func(t *testing.T) {
+			tmpDir, _ := ioutil.TempDir("", "shard_test")
+			defer os.RemoveAll(tmpDir)
+			tmpShard := filepath.Join(tmpDir, "shard")
+			tmpWal := filepath.Join(tmpDir, "wal")
+
+			sfile := MustOpenSeriesFile()
+			defer sfile.Close()
+
+			opts := tsdb.NewEngineOptions()
+			opts.Config.WALDir = filepath.Join(tmpDir, "wal")
+			opts.InmemIndex = inmem.NewIndex(filepath.Base(tmpDir), sfile.SeriesFile)
+			opts.SchemaFn = func(database string) tsdb.Schema { return testSchema{strict: strict} }
+
+			sh := tsdb.NewShard(1, tmpShard, tmpWal, sfile.SeriesFile, opts)
+			if err := sh.Open(); err != nil {
+				t.Fatalf("error opening shard: %s", err.Error())
+			}
+			defer sh.Close()
+
+			points := []models.Point{
+				models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 1.0}, time.Unix(1, 2)),
+				models.MustNewPoint("cpuu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 1.0}, time.Unix(1, 2)),
+				models.MustNewPoint("cpu", models.NewTags(map[string]string{"region": "a"}), map[string]interface{}{"value": 1.0}, time.Unix(1, 2)),
+			}
+			err := sh.WritePoints(points)
+			if !strict {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				} else if got, exp := sh.SeriesN(), int64(3); got != exp {
+					t.Fatalf("got %d series, expected %d", got, exp)
+				}
+				return
+			}
+
+			if err, ok := err.(tsdb.PartialWriteError); !ok {
+				t.Fatalf("expected partial write error, got %v", err)
+			} else if err.Dropped != 2 {
+				t.Fatalf("got %d dropped points, expected 2", err.Dropped)
+			} else if exp := `schema violation: measurement "cpuu" is not allowed`; err.Reason != exp {
+				t.Fatalf("got reason %q, expected %q", err.Reason, exp)
+			}
+
+			// Dropped points do not create series.
+			if got, exp := sh.SeriesN(), int64(1); got != exp {
+				t.Fatalf("got %d series, expected %d", got, exp)
+			}
+
+			// Points written by the server itself are not checked.
+			ctx := context.WithValue(context.Background(), tsdb.SystemWrite, true)
+			if err := sh.WritePointsWithContext(ctx, points); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if got, exp := sh.SeriesN(), int64(3); got != exp {
+				t.Fatalf("got %d series, expected %d", got, exp)
+			}
+		})
+	}
+}
+
+func TestShard_WritePoints_MaxSeriesQuota(t *testing.T) {
+	tmpDir, _ := ioutil.TempDir("", "shard_test")
+	defer os.RemoveAll(tmpDir)
+	tmpShard := filepath.Join(tmpDir, "shard")
+	tmpWal := filepath.Join(tmpDir, "wal")
+
+	sfile := MustOpenSeriesFile()
+	defer sfile.Close()
+
+	opts := tsdb.NewEngineOptions()
+	opts.Config.WALDir = filepath.Join(tmpDir, "wal")
+	opts.InmemIndex = inmem.NewIndex(filepath.Base(tmpDir), sfile.SeriesFile)
+	opts.MaxSeriesFn = func(database string) int64 { return 2 }
+
+	sh := tsdb.NewShard(1, tmpShard, tmpWal, sfile.SeriesFile, opts)
+	if err := sh.Open(); err != nil {
+		t.Fatalf("error opening shard: %s", err.Error())
+	}
+	defer sh.Close()
+
+	point := func(host string) models.Point {
+		return models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": host}), map[string]interface{}{"value": 1.0}, time.Unix(1, 2))
+	}
+	if err := sh.WritePoints([]models.Point{point("a"), point("a")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Points of existing series are written once the quota is reached.
+	err := sh.WritePoints([]models.Point{point("b"), point("c"), point("a"), point("b"), point("d")})
+	if err, ok := err.(tsdb.PartialWriteError); !ok {
+		t.Fatalf("expected partial write error, got %v", err)
+	} else if err.Dropped != 2 {
+		t.Fatalf("got %d dropped points, expected 2", err.Dropped)
+	} else if err.Quota != "max-series" {
+		t.Fatalf("unexpected quota: %q", err.Quota)
+	} else if exp := "max-series quota exceeded: (2)"; err.Reason != exp {
+		t.Fatalf("got reason %q, expected %q", err.Reason, exp)
+	}
+	if got, exp := sh.SeriesN(), int64(2); got != exp {
+		t.Fatalf("got %d series, expected %d", got, exp)
+	}
+}
+
 func TestWriteTimeField(t *testing.T) {
 	tmpDir, _ := ioutil.TempDir("", "shard_test")
 	defer os.RemoveAll(tmpDir)
@@ -1242,6 +1360,57 @@ func TestShards_FieldKeysByMeasurement(t *testing.T) {
 	}
 }
 
+// Ensure SHOW FIELD KEYS only returns the measurements the user may read.
+func TestShard_CreateIterator_FieldKeys_Auth(t *testing.T) {
+	auth := &internal.AuthorizerMock{
+		AuthorizeSeriesReadFn: func(database string, measurement []byte, tags models.Tags) bool {
+			return !bytes.Equal(measurement, []byte("secret")) && tags.GetString("team") != "b"
+		},
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) {
+			sh := MustNewOpenShard(index)
+			defer sh.Close()
+			sh.MustWritePointsString(`
+cpu,team=a value=1 0
+mem,team=b free=1 0
+secret password="x" 0
+`)
+
+			itr, err := sh.CreateIterator(context.Background(), &influxql.Measurement{SystemIterator: "_fieldKeys"}, query.IteratorOptions{
+				Authorizer: auth,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer itr.Close()
+
+			var got []string
+			fitr := itr.(query.FloatIterator)
+			for {
+				p, err := fitr.Next()
+				if err != nil {
+					t.Fatal(err)
+				} else if p == nil {
+					break
+				}
+				got = append(got, p.Name+"."+p.Aux[0].(string))
+			}
+			if exp := []string{"cpu.value"}; !reflect.DeepEqual(got, exp) {
+				t.Fatalf("got field keys %v, expected %v", got, exp)
+			}
+
+			names, err := tsdb.Shards{sh.Shard}.FieldKeysByPredicate(auth, nil)
+			if err != nil {
+				t.Fatal(err)
+			} else if exp := map[string][]string{"cpu": {"value"}}; !reflect.DeepEqual(names, exp) {
+				t.Fatalf("got field keys %v, expected %v", names, exp)
+			}
+		})
+	}
+}
+
 func TestShards_FieldDimensions(t *testing.T) {
 	var shard1, shard2 *Shard
 
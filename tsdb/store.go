@@ -3,6 +3,7 @@ package tsdb // import "github.com/influxdata/influxdb/tsdb"
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -20,6 +21,7 @@ import (
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/estimator"
 	"github.com/influxdata/influxdb/pkg/estimator/hll"
+	"github.com/influxdata/influxdb/pkg/file"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxql"
@@ -95,6 +97,17 @@ type Store struct {
 
 	EngineOptions EngineOptions
 
+	// RenameCommitted returns true if a rename interrupted by the process
+	// stopping was committed to the meta store. Committed renames are
+	// completed when the store is opened and others discarded. If not set,
+	// all interrupted renames are completed.
+	RenameCommitted func(r Rename) bool
+
+	// RenameDirs are other directories holding a directory per database and
+	// retention policy, such as the queues of the subscriptions, moved with
+	// the data directories by renames.
+	RenameDirs []string
+
 	baseLogger *zap.Logger
 	Logger     *zap.Logger
 
@@ -218,6 +231,10 @@ func (s *Store) Open() error {
 		return err
 	}
 
+	if err := s.recoverRename(); err != nil {
+		return err
+	}
+
 	if err := s.loadShards(); err != nil {
 		return err
 	}
@@ -301,7 +318,9 @@ func (s *Store) loadShards() error {
 
 	for _, db := range dbDirs {
 		dbPath := filepath.Join(s.path, db.Name())
-		if !db.IsDir() {
+		if db.Name() == renameJournalFile {
+			continue
+		} else if !db.IsDir() {
 			log.Info("Skipping database dir", zap.String("name", db.Name()), zap.String("reason", "not a directory"))
 			continue
 		}
@@ -512,6 +531,8 @@ func (s *Store) openSeriesFile(database string) (*SeriesFile, error) {
 
 	sfile := NewSeriesFile(filepath.Join(s.path, database, SeriesFileDirectory))
 	sfile.WithMaxCompactionConcurrency(s.EngineOptions.Config.SeriesFileMaxConcurrentSnapshotCompactions)
+	sfile.WithKeyProvider(s.EngineOptions.KeyProvider)
+	sfile.WithChunkCache(s.EngineOptions.ChunkCache)
 	sfile.Logger = s.baseLogger
 	if err := sfile.Open(); err != nil {
 		return nil, err
@@ -940,6 +961,316 @@ func (s *Store) DeleteRetentionPolicy(database, name string) error {
 	return nil
 }
 
+// renameJournalFile is the file in the store directory recording a rename
+// of a database or retention policy in progress.
+const renameJournalFile = ".rename"
+
+// Rename describes a rename of a database or, if RetentionPolicy is set, of a
+// retention policy of a database.
+type Rename struct {
+	Database        string `json:"database"`
+	RetentionPolicy string `json:"retentionPolicy,omitempty"`
+	NewName         string `json:"newName"`
+}
+
+// validate returns an error if a name of r cannot be used as a directory.
+func (r *Rename) validate() error {
+	for _, name := range []string{r.Database, r.RetentionPolicy, r.NewName} {
+		if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("invalid name: %q", name)
+		}
+	}
+	if r.Database == "" || r.NewName == "" {
+		return errors.New("name required")
+	} else if r.RetentionPolicy != "" && r.NewName == SeriesFileDirectory {
+		return fmt.Errorf("invalid name: %q", r.NewName)
+	}
+	return nil
+}
+
+// match returns true if the shard belongs to the renamed database or
+// retention policy.
+func (r *Rename) match(sh *Shard) bool {
+	return sh.database == r.Database && (r.RetentionPolicy == "" || sh.retentionPolicy == r.RetentionPolicy)
+}
+
+// names returns the database and retention policy of a shard after the rename.
+func (r *Rename) names(sh *Shard) (database, retentionPolicy string) {
+	if r.RetentionPolicy == "" {
+		return r.NewName, sh.retentionPolicy
+	}
+	return sh.database, r.NewName
+}
+
+// dirs returns the data, WAL and other directories moved by the rename, and
+// their new locations.
+func (r *Rename) dirs(path, walDir string, others ...string) (from, to []string) {
+	for _, dir := range append([]string{path, walDir}, others...) {
+		if r.RetentionPolicy == "" {
+			from = append(from, filepath.Join(dir, r.Database))
+			to = append(to, filepath.Join(dir, r.NewName))
+		} else {
+			from = append(from, filepath.Join(dir, r.Database, r.RetentionPolicy))
+			to = append(to, filepath.Join(dir, r.Database, r.NewName))
+		}
+	}
+	return from, to
+}
+
+// RenameDatabase renames a database. The shards of the database are closed
+// and commit is called to rename the database in the meta store before its
+// directories, series file included, are moved and its shards reopened.
+//
+// The rename is recorded in the store directory until it is completed. If the
+// process stops before, the rename is completed or discarded the next time
+// the store is opened, depending on RenameCommitted.
+func (s *Store) RenameDatabase(name, newName string, commit func() error) error {
+	return s.rename(Rename{Database: name, NewName: newName}, commit)
+}
+
+// RenameRetentionPolicy renames a retention policy of a database in the same
+// way RenameDatabase renames a database.
+func (s *Store) RenameRetentionPolicy(database, name, newName string, commit func() error) error {
+	return s.rename(Rename{Database: database, RetentionPolicy: name, NewName: newName}, commit)
+}
+
+func (s *Store) rename(r Rename, commit func() error) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	shards := s.filterShards(r.match)
+	epochs := s.epochsForShards(shards)
+	s.mu.RUnlock()
+
+	// Close the shards, waiting for any conflicting deletes.
+	if err := s.walkShards(shards, func(sh *Shard) error {
+		epoch := epochs[sh.id]
+		guards, gen := epoch.StartWrite()
+		defer epoch.EndWrite(gen)
+
+		for _, guard := range guards {
+			guard.Wait()
+		}
+
+		return sh.Close()
+	}); err != nil {
+		s.reopenShards(shards)
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Close shards created in the meantime. No more can be created until
+	// the rename is complete.
+	shards = s.filterShards(r.match)
+	if err := s.walkShards(shards, func(sh *Shard) error { return sh.Close() }); err != nil {
+		s.reopenShards(shards)
+		return err
+	}
+
+	from, to := r.dirs(s.path, s.EngineOptions.Config.WALDir, s.RenameDirs...)
+	for _, dir := range to {
+		if _, err := os.Stat(dir); err == nil {
+			s.reopenShards(shards)
+			return fmt.Errorf("cannot rename to %q: %s already exists", r.NewName, dir)
+		}
+	}
+	if r.RetentionPolicy == "" && filepath.Clean(s.path) != filepath.Dir(filepath.Clean(from[0])) {
+		s.reopenShards(shards)
+		return fmt.Errorf("invalid database directory location for database '%s': %s", r.Database, from[0])
+	}
+
+	if err := s.writeRenameJournal(&r); err != nil {
+		s.reopenShards(shards)
+		return err
+	}
+	if err := commit(); err != nil {
+		if err := os.Remove(filepath.Join(s.path, renameJournalFile)); err != nil {
+			s.Logger.Warn("Failed to remove rename journal", zap.Error(err))
+		}
+		s.reopenShards(shards)
+		return err
+	}
+
+	for _, sh := range shards {
+		delete(s.shards, sh.id)
+		delete(s.epochs, sh.id)
+	}
+	if r.RetentionPolicy == "" {
+		if sfile := s.sfiles[r.Database]; sfile != nil {
+			if err := sfile.Close(); err != nil {
+				return err
+			}
+		}
+		delete(s.sfiles, r.Database)
+		delete(s.indexes, r.Database)
+		delete(s.databases, r.Database)
+	}
+
+	// If moving the directories fails, the rename is completed when the
+	// store is next opened.
+	if err := s.renameDirs(&r); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.path, renameJournalFile)); err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, sh := range shards {
+		sh.mu.RLock()
+		enabled := sh.enabled
+		sh.mu.RUnlock()
+
+		database, rp := r.names(sh)
+		if err := s.openShard(database, rp, sh.id, enabled); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to open shard: %d: %s", sh.id, err)
+		}
+	}
+
+	// Recompute the index types of the database from its open shards.
+	database := r.Database
+	if r.RetentionPolicy == "" {
+		database = r.NewName
+	}
+	if dbShards := s.filterShards(byDatabase(database)); len(dbShards) > 0 {
+		state := new(databaseState)
+		for _, sh := range dbShards {
+			state.addIndexType(sh.IndexType())
+		}
+		s.databases[database] = state
+	}
+
+	return firstErr
+}
+
+// reopenShards reopens shards closed by a failed rename.
+func (s *Store) reopenShards(shards []*Shard) {
+	for _, sh := range shards {
+		if err := sh.Open(); err != nil {
+			s.Logger.Warn("Failed to reopen shard", logger.Shard(sh.id), zap.Error(err))
+		}
+	}
+}
+
+// openShard opens an existing shard after a rename. It must be called under
+// a full lock.
+func (s *Store) openShard(database, retentionPolicy string, shardID uint64, enabled bool) error {
+	sfile, err := s.openSeriesFile(database)
+	if err != nil {
+		return err
+	}
+
+	idx, err := s.createIndexIfNotExists(database)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.path, database, retentionPolicy, strconv.FormatUint(shardID, 10))
+	walPath := filepath.Join(s.EngineOptions.Config.WALDir, database, retentionPolicy, strconv.FormatUint(shardID, 10))
+
+	opt := s.EngineOptions
+	opt.InmemIndex = idx
+	opt.SeriesIDSets = shardSet{store: s, db: database}
+
+	// Existing shards should continue to use inmem index.
+	if _, err := os.Stat(filepath.Join(path, "index")); os.IsNotExist(err) {
+		opt.IndexVersion = InmemIndexName
+	}
+
+	shard := NewShard(shardID, path, walPath, sfile, opt)
+	shard.WithLogger(s.baseLogger)
+	shard.EnableOnOpen = enabled
+	shard.CompactionDisabled = s.EngineOptions.CompactionDisabled
+
+	if err := shard.Open(); err != nil {
+		return err
+	}
+
+	s.shards[shardID] = shard
+	s.epochs[shardID] = newEpochTracker()
+	return nil
+}
+
+// writeRenameJournal records a rename in progress in the store directory.
+func (s *Store) writeRenameJournal(r *Rename) error {
+	buf, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(s.path, renameJournalFile))
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+	return file.SyncDir(s.path)
+}
+
+// renameDirs moves the directories of a rename. Directories already moved are
+// skipped so that an interrupted rename can be completed.
+func (s *Store) renameDirs(r *Rename) error {
+	from, to := r.dirs(s.path, s.EngineOptions.Config.WALDir, s.RenameDirs...)
+	for i := range from {
+		if _, err := os.Stat(from[i]); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if err := os.Rename(from[i], to[i]); err != nil {
+			return err
+		} else if err := file.SyncDir(filepath.Dir(to[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recoverRename completes or discards a rename interrupted by the process
+// stopping. It must be called before the shards are loaded.
+func (s *Store) recoverRename() error {
+	path := filepath.Join(s.path, renameJournalFile)
+	buf, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var r Rename
+	if err := json.Unmarshal(buf, &r); err != nil {
+		return fmt.Errorf("invalid rename journal %s: %s", path, err)
+	} else if err := r.validate(); err != nil {
+		return fmt.Errorf("invalid rename journal %s: %s", path, err)
+	}
+
+	fields := []zapcore.Field{logger.Database(r.Database), zap.String("new_name", r.NewName)}
+	if r.RetentionPolicy != "" {
+		fields = append(fields, logger.RetentionPolicy(r.RetentionPolicy))
+	}
+	if s.RenameCommitted == nil || s.RenameCommitted(r) {
+		s.Logger.Info("Completing interrupted rename", fields...)
+		if err := s.renameDirs(&r); err != nil {
+			return err
+		}
+	} else {
+		s.Logger.Info("Discarding interrupted rename", fields...)
+	}
+	return os.Remove(path)
+}
+
 // DeleteMeasurement removes a measurement and all associated series from a database.
 func (s *Store) DeleteMeasurement(database, name string) error {
 	s.mu.RLock()
@@ -1089,6 +1420,37 @@ func (s *Store) DiskSize() (int64, error) {
 	return size, nil
 }
 
+// DatabaseDiskSize returns the size of the shard files of a database in bytes.
+func (s *Store) DatabaseDiskSize(name string) (int64, error) {
+	var size int64
+
+	s.mu.RLock()
+	shards := s.filterShards(byDatabase(name))
+	s.mu.RUnlock()
+
+	for _, sh := range shards {
+		sz, err := sh.DiskSize()
+		if err != nil {
+			return 0, err
+		}
+		size += sz
+	}
+	return size, nil
+}
+
+// DatabaseSeriesN returns the number of series in the series file of a
+// database, which the max-series quota of the database limits.
+func (s *Store) DatabaseSeriesN(name string) int64 {
+	s.mu.RLock()
+	sfile := s.sfiles[name]
+	s.mu.RUnlock()
+
+	if sfile == nil {
+		return 0
+	}
+	return int64(sfile.SeriesCount())
+}
+
 // sketchesForDatabase returns merged sketches for the provided database, by
 // walking each shard in the database and merging the sketches found there.
 func (s *Store) sketchesForDatabase(dbName string, getSketches func(*Shard) (estimator.Sketch, estimator.Sketch, error)) (estimator.Sketch, estimator.Sketch, error) {
@@ -1287,6 +1649,88 @@ func (s *Store) ImportShard(id uint64, r io.Reader) error {
 	return shard.Import(r, path)
 }
 
+// CopyShard copies the data of a shard between min and max, inclusive, into
+// another shard. It returns ErrShardNotFound if either shard does not exist
+// on this server. The TSM blocks of the time range are streamed from the source
+// shard and imported as new files into the destination shard, so the data
+// keeps its field types.
+func (s *Store) CopyShard(srcID, dstID uint64, min, max int64) error {
+	src, dst := s.Shard(srcID), s.Shard(dstID)
+	if src == nil || dst == nil {
+		return ErrShardNotFound
+	}
+
+	path, err := relativePath(s.path, dst.path)
+	if err != nil {
+		return err
+	}
+
+	// The archive is written with the path of the destination shard so that
+	// the destination imports its files.
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(src.ExportRange(pw, path, min, max))
+	}()
+
+	err = dst.Import(pr, path)
+	pr.Close()
+	<-done
+	return err
+}
+
+// DeleteShardRange removes the data of a shard between min and max, inclusive.
+func (s *Store) DeleteShardRange(id uint64, min, max int64) error {
+	s.mu.RLock()
+	sh := s.shards[id]
+	if sh == nil {
+		s.mu.RUnlock()
+		return ErrShardNotFound
+	}
+	sfile := s.sfiles[sh.database]
+	epoch := s.epochs[id]
+	s.mu.RUnlock()
+
+	if sfile == nil {
+		return nil
+	}
+
+	var names []string
+	if err := sh.ForEachMeasurementName(func(name []byte) error {
+		names = append(names, string(name))
+		return nil
+	}); err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	waiter := epoch.WaitDelete(newGuard(min, max, names, nil))
+	waiter.Wait()
+	defer waiter.Done()
+
+	index, err := sh.Index()
+	if err != nil {
+		return err
+	}
+
+	indexSet := IndexSet{Indexes: []Index{index}, SeriesFile: sfile}
+	for _, name := range names {
+		itr, err := indexSet.MeasurementSeriesByExprIterator([]byte(name), nil)
+		if err != nil {
+			return err
+		} else if itr == nil {
+			continue
+		}
+		err = sh.DeleteSeriesRange(NewSeriesIteratorAdapter(sfile, itr), min, max)
+		itr.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ShardRelativePath will return the relative path to the shard, i.e.,
 // <database>/<retention>/<id>.
 func (s *Store) ShardRelativePath(id uint64) (string, error) {
@@ -23,9 +23,12 @@ import (
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/deep"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/slices"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
 	"github.com/influxdata/influxdb/tsdb/index/inmem"
 	"github.com/influxdata/influxql"
 )
@@ -107,6 +110,259 @@ func TestStore_DeleteRetentionPolicy(t *testing.T) {
 	}
 }
 
+// Ensure the store can rename a database.
+func TestStore_RenameDatabase(t *testing.T) {
+	t.Parallel()
+
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 1, "cpu,host=a value=1 0")
+		s.MustCreateShardWithData("db0", "rp1", 2, "mem,host=a value=1 0")
+		s.MustCreateShardWithData("db1", "rp0", 3, "disk,host=a value=1 0")
+
+		// Other directories of the database are moved with its data.
+		queueDir, err := ioutil.TempDir("", "influxdb-queue-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(queueDir)
+		if err := os.MkdirAll(filepath.Join(queueDir, "db0", "rp0"), 0777); err != nil {
+			t.Fatal(err)
+		}
+		s.RenameDirs = []string{queueDir}
+
+		// A failed commit leaves the database as it was.
+		errCommit := errors.New("commit failed")
+		if err := s.RenameDatabase("db0", "db2", func() error { return errCommit }); err != errCommit {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s.MustWriteToShardString(1, "cpu,host=b value=1 0")
+		if got := dirExists(filepath.Join(s.Path(), "db2")); got {
+			t.Fatal("expected database directory not to be renamed")
+		}
+
+		var committed bool
+		if err := s.RenameDatabase("db0", "db2", func() error { committed = true; return nil }); err != nil {
+			t.Fatal(err)
+		} else if !committed {
+			t.Fatal("expected rename to be committed")
+		}
+
+		for _, dir := range []string{s.Path(), s.EngineOptions.Config.WALDir, queueDir} {
+			if dirExists(filepath.Join(dir, "db0")) || !dirExists(filepath.Join(dir, "db2")) {
+				t.Fatalf("unexpected directories in %s", dir)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(s.Path(), ".rename")); !os.IsNotExist(err) {
+			t.Fatalf("expected rename journal to be removed: %v", err)
+		}
+
+		check := func() {
+			t.Helper()
+			for id, exp := range map[uint64]string{1: "db2", 2: "db2", 3: "db1"} {
+				if sh := s.Shard(id); sh == nil || sh.Database() != exp {
+					t.Fatalf("unexpected shard %d: %v", id, sh)
+				}
+			}
+			names, err := s.MeasurementNames(context.Background(), query.OpenAuthorizer, "db2", nil)
+			if err != nil {
+				t.Fatal(err)
+			} else if got, exp := names, [][]byte{[]byte("cpu"), []byte("mem")}; !reflect.DeepEqual(got, exp) {
+				t.Fatalf("unexpected measurements: %q", got)
+			}
+		}
+		check()
+		s.MustWriteToShardString(1, "cpu,host=c value=1 0")
+
+		if err := s.Reopen(); err != nil {
+			t.Fatal(err)
+		}
+		check()
+
+		// Renaming to an existing database fails.
+		if err := s.RenameDatabase("db2", "db1", func() error { return nil }); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
+// Ensure the store can rename a retention policy.
+func TestStore_RenameRetentionPolicy(t *testing.T) {
+	t.Parallel()
+
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 1, "cpu,host=a value=1 0")
+		s.MustCreateShardWithData("db0", "rp1", 2, "mem,host=a value=1 0")
+
+		if err := s.RenameRetentionPolicy("db0", "rp0", "rp2", func() error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, dir := range []string{s.Path(), s.EngineOptions.Config.WALDir} {
+			if dirExists(filepath.Join(dir, "db0", "rp0")) || !dirExists(filepath.Join(dir, "db0", "rp2")) {
+				t.Fatalf("unexpected directories in %s", dir)
+			}
+		}
+		if sh := s.Shard(1); sh == nil || sh.RetentionPolicy() != "rp2" {
+			t.Fatalf("unexpected shard: %v", sh)
+		} else if sh := s.Shard(2); sh == nil || sh.RetentionPolicy() != "rp1" {
+			t.Fatalf("unexpected shard: %v", sh)
+		}
+		s.MustWriteToShardString(1, "cpu,host=b value=1 0")
+
+		if err := s.Reopen(); err != nil {
+			t.Fatal(err)
+		} else if sh := s.Shard(1); sh == nil || sh.RetentionPolicy() != "rp2" {
+			t.Fatalf("unexpected shard: %v", sh)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
+// Ensure the store completes or discards a rename interrupted by a crash.
+func TestStore_Open_InterruptedRename(t *testing.T) {
+	t.Parallel()
+
+	test := func(index string, committed bool) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 1, "cpu,host=a value=1 0")
+		if err := s.Store.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Simulate a crash after the data directory of the database was
+		// moved but not its WAL directory.
+		if err := ioutil.WriteFile(filepath.Join(s.Path(), ".rename"), []byte(`{"database":"db0","newName":"db2"}`), 0666); err != nil {
+			t.Fatal(err)
+		} else if committed {
+			if err := os.Rename(filepath.Join(s.Path(), "db0"), filepath.Join(s.Path(), "db2")); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		s.Store = tsdb.NewStore(s.Path())
+		s.EngineOptions.IndexVersion = index
+		s.EngineOptions.Config.WALDir = filepath.Join(s.Path(), "wal")
+		s.RenameCommitted = func(r tsdb.Rename) bool {
+			if r.Database != "db0" || r.NewName != "db2" {
+				t.Errorf("unexpected rename: %+v", r)
+			}
+			return committed
+		}
+		if err := s.Store.Open(); err != nil {
+			t.Fatal(err)
+		}
+
+		exp := "db0"
+		if committed {
+			exp = "db2"
+		}
+		if sh := s.Shard(1); sh == nil || sh.Database() != exp {
+			t.Fatalf("unexpected shard: %v", sh)
+		} else if !dirExists(filepath.Join(s.EngineOptions.Config.WALDir, exp)) {
+			t.Fatalf("expected WAL directory %s", exp)
+		} else if _, err := os.Stat(filepath.Join(s.Path(), ".rename")); !os.IsNotExist(err) {
+			t.Fatalf("expected rename journal to be removed: %v", err)
+		}
+		s.MustWriteToShardString(1, "cpu,host=b value=1 0")
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index+"/committed", func(t *testing.T) { test(index, true) })
+		t.Run(index+"/discarded", func(t *testing.T) { test(index, false) })
+	}
+}
+
+// Ensure the store can copy and delete the data of a shard's time range.
+func TestStore_CopyShard(t *testing.T) {
+	t.Parallel()
+
+	// times returns the times of the cpu points in a shard.
+	times := func(sh *tsdb.Shard) []int64 {
+		itr, err := sh.CreateIterator(context.Background(), &influxql.Measurement{Name: "cpu"}, query.IteratorOptions{
+			Expr:      influxql.MustParseExpr(`value`),
+			Ascending: true,
+			StartTime: influxql.MinTime,
+			EndTime:   influxql.MaxTime,
+		})
+		if err != nil {
+			t.Fatal(err)
+		} else if itr == nil {
+			return nil
+		}
+		defer itr.Close()
+
+		var a []int64
+		for {
+			p, err := itr.(query.IntegerIterator).Next()
+			if err != nil {
+				t.Fatal(err)
+			} else if p == nil {
+				return a
+			}
+			a = append(a, p.Time)
+		}
+	}
+
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 1,
+			"cpu,host=a value=1i 10",
+			"cpu,host=a value=2i 20",
+			"cpu,host=a value=3i 30",
+		)
+		if err := s.CreateShard("db0", "rp1", 2, true); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.CopyShard(1, 2, 15000000000, 25000000000); err != nil {
+			t.Fatal(err)
+		} else if got, exp := times(s.Shard(2)), []int64{20000000000}; !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected copied times: got %v, exp %v", got, exp)
+		}
+
+		if err := s.DeleteShardRange(1, 15000000000, 25000000000); err != nil {
+			t.Fatal(err)
+		} else if got, exp := times(s.Shard(1)), []int64{10000000000, 30000000000}; !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected remaining times: got %v, exp %v", got, exp)
+		}
+
+		if err := s.Reopen(); err != nil {
+			t.Fatal(err)
+		} else if f := s.Shard(2).MeasurementFields([]byte("cpu")).Field("value"); f == nil || f.Type != influxql.Integer {
+			t.Fatalf("unexpected field: %v", f)
+		} else if got, exp := times(s.Shard(2)), []int64{20000000000}; !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected copied times after reopen: got %v, exp %v", got, exp)
+		}
+
+		if err := s.CopyShard(1, 3, 0, 1); err != tsdb.ErrShardNotFound {
+			t.Fatalf("unexpected error: %v", err)
+		} else if err := s.DeleteShardRange(3, 0, 1); err != tsdb.ErrShardNotFound {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
 // Ensure the store can create a new shard.
 func TestStore_CreateShard(t *testing.T) {
 	t.Parallel()
@@ -2253,6 +2509,125 @@ func TestStore_TagValues_ConcurrentDropShard(t *testing.T) {
 	}
 }
 
+// Ensure that every file encrypted with a rotated key is rewritten with the
+// current key once the store is reopened with a new key.
+func TestStore_EncryptionKeyRotation(t *testing.T) {
+	path, walPath := t.TempDir(), t.TempDir()
+
+	open := func(keys encryption.KeyProvider) *tsdb.Store {
+		s := tsdb.NewStore(path)
+		s.EngineOptions.IndexVersion = tsdb.TSI1IndexName
+		s.EngineOptions.Config.WALDir = walPath
+		s.EngineOptions.Config.CacheSnapshotWriteColdDuration = toml.Duration(100 * time.Millisecond)
+		s.EngineOptions.KeyProvider = keys
+		if err := s.Open(); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	// keyIDs returns the IDs of the keys of the encrypted files by path.
+	keyIDs := func() map[string]string {
+		ids := make(map[string]string)
+		walk := func(p string, fi os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil
+			} else if err != nil || fi.IsDir() {
+				return err
+			}
+			b, err := ioutil.ReadFile(p)
+			if os.IsNotExist(err) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if encryption.IsEncrypted(b) && len(b) > 7 && len(b) >= 7+int(b[6]) {
+				ids[p] = string(b[7 : 7+int(b[6])])
+			}
+			return nil
+		}
+		for _, root := range []string{path, walPath} {
+			if err := filepath.Walk(root, walk); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return ids
+	}
+
+	// waitFor polls fn until it returns true.
+	waitFor := func(msg string, fn func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(30 * time.Second); !fn(); time.Sleep(50 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s: %v", msg, keyIDs())
+			}
+		}
+	}
+
+	s := open(mustKeyFile(t, "k1"))
+	if err := s.CreateShard("db", "rp", 0, true); err != nil {
+		t.Fatal(err)
+	}
+	points, err := models.ParsePointsString("cpu,host=a value=1 10\ncpu,host=b value=2 20\nmem,host=a value=3 30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteToShard(0, points); err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for the cache to be written to a TSM file.
+	waitFor("TSM file", func() bool {
+		for p := range keyIDs() {
+			if filepath.Ext(p) == "."+tsm1.TSMFileExtension {
+				return true
+			}
+		}
+		return false
+	})
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every file is encrypted with the first key.
+	exts := make(map[string]bool)
+	for p, id := range keyIDs() {
+		if id != "k1" {
+			t.Fatalf("%s encrypted with %q", p, id)
+		}
+		exts[filepath.Ext(p)] = true
+	}
+	for _, ext := range []string{".tsm", ".tsl", ".wal", ""} {
+		if !exts[ext] {
+			t.Fatalf("no encrypted %q file: %v", ext, exts)
+		}
+	}
+
+	// Rotate the key and wait for every file to be rewritten with it.
+	s = open(mustKeyFile(t, "k1", "k2"))
+	defer s.Close()
+	waitFor("key rotation", func() bool {
+		for _, id := range keyIDs() {
+			if id != "k2" {
+				return false
+			}
+		}
+		return true
+	})
+
+	if n, err := s.SeriesCardinality(context.Background(), "db"); err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Fatalf("unexpected series cardinality: %d", n)
+	}
+	names, err := s.MeasurementNames(context.Background(), nil, "db", nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(names, [][]byte{[]byte("cpu"), []byte("mem")}) {
+		t.Fatalf("unexpected measurements: %q", names)
+	}
+}
+
 func BenchmarkStore_SeriesCardinality_100_Shards(b *testing.B) {
 	for _, index := range tsdb.RegisteredIndexes() {
 		store := NewStore(index)